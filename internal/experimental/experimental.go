@@ -0,0 +1,35 @@
+// Package experimental holds oc-mirror commands that are not part of the
+// supported interface. They are only registered when EnvVar is set.
+package experimental
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+// EnvVar is the environment variable enabling the experimental commands.
+const EnvVar = "OC_MIRROR_EXPERIMENTAL"
+
+// Enabled returns true when EnvVar is set to a true value.
+func Enabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(EnvVar))
+	return enabled
+}
+
+func NewExperimentalCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "experimental",
+		Short:  "Experimental commands, subject to change without notice",
+		Hidden: true,
+		Run:    kcmdutil.DefaultSubCommandRun(ro.ErrOut),
+	}
+
+	cmd.AddCommand(NewTestdataCommand(f, ro))
+
+	return cmd
+}
@@ -0,0 +1,353 @@
+package experimental
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/uuid"
+	imgreference "github.com/openshift/library-go/pkg/image/reference"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/archive"
+	"github.com/openshift/oc-mirror/pkg/bundle"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+	"github.com/openshift/oc-mirror/pkg/operator"
+)
+
+const (
+	testPackage       = "test-operator"
+	testBundleVersion = "0.1.0"
+	testBundleImage   = "registry.example.com/oc-mirror/test-operator-bundle"
+	testOperandImage  = "registry.example.com/oc-mirror/test-operator"
+	testReleaseImage  = "quay.io/openshift-release-dev/ocp-release"
+	// the release image is written to disk in the repository the release planner uses
+	testReleaseDiskNamespace = "openshift"
+	testReleaseDiskName      = "release-images"
+	// catalogConfigsLabel is the label of catalog images locating their declarative config
+	catalogConfigsLabel = "operators.operatorframework.io.index.configs.v1"
+	// maxArchiveSize keeps the generated imageset in a single archive
+	maxArchiveSize int64 = 1024 * 1024 * 1024
+)
+
+// imagesetGenerator fabricates the workspace of a mirror to disk run and packs it
+// as an imageset archive, the way Create and Pack do with mirrored content.
+type imagesetGenerator struct {
+	workspace         string
+	sequence          int
+	catalogRepository string
+	releaseVersion    semver.Version
+}
+
+// generate writes the imageset archive to outputDir and returns its path.
+func (g imagesetGenerator) generate(ctx context.Context, outputDir string) (string, error) {
+	srcDir := filepath.Join(g.workspace, config.SourceDir)
+	mappings := image.TypedImageMapping{}
+
+	operand, err := g.addImage(mappings, testOperandImage, "", v1alpha2.TypeOperatorRelatedImage)
+	if err != nil {
+		return "", err
+	}
+	bundleImg, err := g.addImage(mappings, testBundleImage, "", v1alpha2.TypeOperatorBundle)
+	if err != nil {
+		return "", err
+	}
+	releaseTag := fmt.Sprintf("%s-x86_64", g.releaseVersion)
+	if _, err := g.addImage(mappings, testReleaseImage, releaseTag, v1alpha2.TypeOCPRelease); err != nil {
+		return "", err
+	}
+	ctlg, err := g.writeCatalog(bundleImg, operand)
+	if err != nil {
+		return "", err
+	}
+
+	assocs, errs := image.AssociateLocalImageLayers(srcDir, mappings)
+	if errs != nil {
+		return "", errs
+	}
+
+	channel := fmt.Sprintf("stable-%d.%d", g.releaseVersion.Major, g.releaseVersion.Minor)
+	meta := v1alpha2.NewMetadata()
+	meta.Uid = uuid.New()
+	meta.PastMirror = v1alpha2.PastMirror{
+		Timestamp: int(time.Now().Unix()),
+		Sequence:  g.sequence,
+		Mirror: v1alpha2.Mirror{
+			Platform: v1alpha2.Platform{
+				Channels: []v1alpha2.ReleaseChannel{{
+					Name:       channel,
+					MinVersion: g.releaseVersion.String(),
+					MaxVersion: g.releaseVersion.String(),
+				}},
+				Architectures: []string{v1alpha2.DefaultPlatformArchitecture},
+			},
+			Operators: []v1alpha2.Operator{{Catalog: ctlg.Exact()}},
+		},
+	}
+	backend, err := storage.NewLocalBackend(filepath.Join(g.workspace, "backend"))
+	if err != nil {
+		return "", err
+	}
+	// The catalog is pinned, so resolving the operator metadata stays offline.
+	streamed := map[string]image.AssociationIterator{
+		image.PastAssociationsField:     assocs,
+		image.MirroredAssociationsField: assocs,
+	}
+	if err := metadata.UpdateMetadata(ctx, backend, &meta, streamed, srcDir, false, false); err != nil {
+		return "", err
+	}
+
+	v2Dir := filepath.Join(srcDir, config.V2Dir)
+	manifests, blobs, err := bundle.ReconcileV2Dir(image.AssociationSet{}, map[string]string{v2Dir: config.V2Dir})
+	if err != nil {
+		return "", err
+	}
+	output, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "", err
+	}
+
+	// Archive paths are relative to the source directory, as in prepareArchive
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chdir(srcDir); err != nil {
+		return "", err
+	}
+	defer os.Chdir(cwd)
+
+	packager := archive.NewPackager(manifests, blobs)
+	prefix := fmt.Sprintf("mirror_seq%d", g.sequence)
+	if err := packager.CreateSplitArchive(ctx, backend, maxArchiveSize, output, ".", prefix, false); err != nil {
+		return "", fmt.Errorf("failed to create archive: %v", err)
+	}
+	return filepath.Join(output, fmt.Sprintf("%s_%06d.%s", prefix, 0, packager.String())), nil
+}
+
+// addImage fabricates a single layer image, writes it to the v2 directory of the
+// workspace and adds it to mappings. Images without tag are referenced by digest.
+func (g imagesetGenerator) addImage(mappings image.TypedImageMapping, repository, tag string, typ v1alpha2.ImageType) (imgreference.DockerImageReference, error) {
+	img, err := crane.Image(map[string][]byte{
+		"testdata": []byte(fmt.Sprintf("%s %s %s\n", typ, repository, tag)),
+	})
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	dgst, err := img.Digest()
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+
+	srcRef, err := imgreference.Parse(repository)
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	srcRef.Tag, srcRef.ID = tag, dgst.String()
+	if tag != "" {
+		srcRef.ID = ""
+	}
+	dstRef := srcRef
+	dstRef.Registry = ""
+	if typ == v1alpha2.TypeOCPRelease {
+		dstRef.Namespace, dstRef.Name = testReleaseDiskNamespace, testReleaseDiskName
+	}
+
+	src, err := image.ParseTypedImage(srcRef.Exact(), typ)
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	dst, err := image.ParseTypedImage("file://"+dstRef.Exact(), typ)
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	if err := writeV2Image(filepath.Join(g.workspace, config.SourceDir, config.V2Dir), dst.Ref, img); err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	mappings[src] = dst
+
+	pinned := srcRef
+	pinned.Tag, pinned.ID = "", dgst.String()
+	return pinned, nil
+}
+
+// writeCatalog writes the declarative config, include config and OCI layout of a
+// catalog holding the bundle, and returns the catalog reference pinned by digest.
+func (g imagesetGenerator) writeCatalog(bundleImg, operand imgreference.DockerImageReference) (imgreference.DockerImageReference, error) {
+	ctlgImg, err := crane.Image(map[string][]byte{
+		"configs/.keep": nil,
+	})
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	ctlgImg, err = mutate.Config(ctlgImg, v1.Config{Labels: map[string]string{catalogConfigsLabel: "/configs"}})
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	dgst, err := ctlgImg.Digest()
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	ctlgRef, err := imgreference.Parse(g.catalogRepository)
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	ctlgRef.Tag, ctlgRef.ID = "", dgst.String()
+
+	ctlgDir, err := operator.GenerateCatalogDir(ctlgRef)
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	catalogBasePath := filepath.Join(g.workspace, config.SourceDir, config.CatalogsDir, ctlgDir)
+
+	lp, err := layout.Write(filepath.Join(catalogBasePath, config.LayoutsDir), empty.Index)
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	if err := lp.AppendImage(ctlgImg); err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+
+	dc := testDeclarativeConfig(bundleImg, operand)
+	indexDir := filepath.Join(catalogBasePath, config.IndexDir)
+	if err := os.MkdirAll(indexDir, 0750); err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	indexFile, err := os.Create(filepath.Join(indexDir, "index.json"))
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	defer indexFile.Close()
+	if err := declcfg.WriteJSON(dc, indexFile); err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+
+	ic, err := operator.NewCatalogStrategy().ConvertDCToIncludeConfig(dc)
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	includeFile, err := os.Create(filepath.Join(catalogBasePath, config.IncludeConfigFile))
+	if err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+	defer includeFile.Close()
+	if err := ic.Encode(includeFile); err != nil {
+		return imgreference.DockerImageReference{}, err
+	}
+
+	return ctlgRef, nil
+}
+
+// testDeclarativeConfig returns a catalog with a single package, channel and bundle.
+func testDeclarativeConfig(bundleImg, operand imgreference.DockerImageReference) declcfg.DeclarativeConfig {
+	bundleName := fmt.Sprintf("%s.v%s", testPackage, testBundleVersion)
+	return declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{{
+			Schema:         declcfg.SchemaPackage,
+			Name:           testPackage,
+			DefaultChannel: "stable",
+		}},
+		Channels: []declcfg.Channel{{
+			Schema:  declcfg.SchemaChannel,
+			Package: testPackage,
+			Name:    "stable",
+			Entries: []declcfg.ChannelEntry{{Name: bundleName}},
+		}},
+		Bundles: []declcfg.Bundle{{
+			Schema:  declcfg.SchemaBundle,
+			Name:    bundleName,
+			Package: testPackage,
+			Image:   bundleImg.Exact(),
+			Properties: []property.Property{
+				property.MustBuildPackage(testPackage, testBundleVersion),
+			},
+			RelatedImages: []declcfg.RelatedImage{
+				{Name: "operator", Image: operand.Exact()},
+			},
+		}},
+	}
+}
+
+// writeV2Image writes img in the layout of the file destinations of oc image mirror:
+// <repository>/manifests/<digest>, a <repository>/manifests/<tag> symlink to it,
+// and <repository>/blobs/<digest> for the config and layers.
+func writeV2Image(v2Dir string, ref imgreference.DockerImageReference, img v1.Image) error {
+	repoDir := filepath.Join(v2Dir, filepath.FromSlash(ref.AsRepository().String()))
+	manifestsDir := filepath.Join(repoDir, "manifests")
+	blobsDir := filepath.Join(repoDir, config.BlobDir)
+	for _, dir := range []string{manifestsDir, blobsDir} {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := img.RawManifest()
+	if err != nil {
+		return err
+	}
+	dgst, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(manifestsDir, dgst.String()), manifest, 0600); err != nil {
+		return err
+	}
+	if ref.Tag != "" {
+		if err := os.Symlink(dgst.String(), filepath.Join(manifestsDir, ref.Tag)); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := img.RawConfigFile()
+	if err != nil {
+		return err
+	}
+	cfgName, err := img.ConfigName()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(blobsDir, cfgName.String()), cfg, 0600); err != nil {
+		return err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return err
+	}
+	for _, layer := range layers {
+		layerDigest, err := layer.Digest()
+		if err != nil {
+			return err
+		}
+		data, err := layer.Compressed()
+		if err != nil {
+			return err
+		}
+		f, err := os.Create(filepath.Join(blobsDir, layerDigest.String()))
+		if err != nil {
+			data.Close()
+			return err
+		}
+		_, err = f.ReadFrom(data)
+		data.Close()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package experimental
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blang/semver/v4"
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+const (
+	defaultCatalogRepository = "registry.example.com/oc-mirror/test-catalog"
+	defaultReleaseVersion    = "4.14.0"
+)
+
+type TestdataOptions struct {
+	*cli.RootOptions
+	OutputDir         string
+	Sequence          int
+	CatalogRepository string
+	ReleaseVersion    string
+}
+
+func NewTestdataCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := TestdataOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "testdata <output directory>",
+		Short: "Generate a tiny imageset archive to test publishing offline",
+		Long: templates.LongDesc(`
+			Generate a tiny but structurally complete imageset archive: metadata,
+			an operator catalog with one bundle and its related image, and a release
+			image stub. Every image is fabricated locally, so the archive can be
+			published by CI jobs that have no access to the source registries.
+		`),
+		Example: templates.Examples(`
+			# Generate mirror_seq1_000000.tar in the testdata directory
+			OC_MIRROR_EXPERIMENTAL=true oc-mirror experimental testdata ./testdata
+
+			# Generate the second imageset of a sequence
+			OC_MIRROR_EXPERIMENTAL=true oc-mirror experimental testdata ./testdata --seq 2
+		`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.IntVar(&o.Sequence, "seq", 1, "Sequence number of the imageset")
+	fs.StringVar(&o.CatalogRepository, "catalog", defaultCatalogRepository, "Repository of the fabricated operator catalog")
+	fs.StringVar(&o.ReleaseVersion, "release-version", defaultReleaseVersion, "Version of the fabricated release image")
+
+	return cmd
+}
+
+func (o *TestdataOptions) Complete(args []string) error {
+	if len(args) == 1 {
+		o.OutputDir = args[0]
+	}
+	return nil
+}
+
+func (o *TestdataOptions) Validate() error {
+	switch {
+	case len(o.OutputDir) == 0:
+		return errors.New("must specify an output directory")
+	case o.Sequence < 1:
+		return fmt.Errorf("invalid --seq %d, must be at least 1", o.Sequence)
+	}
+	if _, err := semver.Parse(o.ReleaseVersion); err != nil {
+		return fmt.Errorf("invalid --release-version %q, expected x.y.z: %v", o.ReleaseVersion, err)
+	}
+	return nil
+}
+
+func (o *TestdataOptions) Run(ctx context.Context) error {
+	if err := os.MkdirAll(o.OutputDir, 0750); err != nil {
+		return err
+	}
+	workspace, err := os.MkdirTemp("", "oc-mirror-testdata-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workspace)
+
+	gen := imagesetGenerator{
+		workspace:         workspace,
+		sequence:          o.Sequence,
+		catalogRepository: o.CatalogRepository,
+		releaseVersion:    semver.MustParse(o.ReleaseVersion),
+	}
+	archive, err := gen.generate(ctx, o.OutputDir)
+	if err != nil {
+		return fmt.Errorf("error generating imageset: %v", err)
+	}
+	fmt.Fprintf(o.IOStreams.Out, "Imageset written to %s\n", filepath.Clean(archive))
+	return nil
+}
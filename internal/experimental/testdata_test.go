@@ -0,0 +1,109 @@
+package experimental
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/bundle"
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+func TestTestdataValidate(t *testing.T) {
+	type spec struct {
+		name     string
+		opts     *TestdataOptions
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name: "Valid/Defaults",
+			opts: &TestdataOptions{OutputDir: "testdata", Sequence: 1, ReleaseVersion: defaultReleaseVersion},
+		},
+		{
+			name:     "Invalid/NoOutputDir",
+			opts:     &TestdataOptions{Sequence: 1, ReleaseVersion: defaultReleaseVersion},
+			expError: "must specify an output directory",
+		},
+		{
+			name:     "Invalid/Sequence",
+			opts:     &TestdataOptions{OutputDir: "testdata", ReleaseVersion: defaultReleaseVersion},
+			expError: "invalid --seq 0, must be at least 1",
+		},
+		{
+			name:     "Invalid/ReleaseVersion",
+			opts:     &TestdataOptions{OutputDir: "testdata", Sequence: 1, ReleaseVersion: "4.14"},
+			expError: `invalid --release-version "4.14", expected x.y.z: No Major.Minor.Patch elements found`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.opts.Validate()
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTestdataRun(t *testing.T) {
+	out := t.TempDir()
+	var stdout bytes.Buffer
+	o := &TestdataOptions{
+		RootOptions: &cli.RootOptions{
+			IOStreams: genericclioptions.IOStreams{Out: &stdout, ErrOut: os.Stderr},
+		},
+		OutputDir:         out,
+		Sequence:          2,
+		CatalogRepository: defaultCatalogRepository,
+		ReleaseVersion:    defaultReleaseVersion,
+	}
+	require.NoError(t, o.Validate())
+	require.NoError(t, o.Run(context.Background()))
+
+	archivePath := filepath.Join(out, "mirror_seq2_000000.tar")
+	require.Contains(t, stdout.String(), archivePath)
+
+	meta, err := bundle.ReadMetadataFromFile(context.Background(), archivePath)
+	require.NoError(t, err)
+	require.Equal(t, 2, meta.PastMirror.Sequence)
+	require.Len(t, meta.PastMirror.Operators, 1)
+	require.Len(t, meta.PastMirror.Operators[0].IncludeConfig.Packages, 1)
+	require.Equal(t, []v1alpha2.PlatformMetadata{{ReleaseChannel: "stable-4.14", MinVersion: defaultReleaseVersion}}, meta.PastMirror.Platforms)
+
+	types := map[v1alpha2.ImageType]int{}
+	for _, assoc := range meta.PastMirror.Associations {
+		require.NoError(t, assoc.Validate())
+		types[assoc.Type]++
+	}
+	require.Equal(t, map[v1alpha2.ImageType]int{
+		v1alpha2.TypeOCPRelease:           1,
+		v1alpha2.TypeOperatorBundle:       1,
+		v1alpha2.TypeOperatorRelatedImage: 1,
+	}, types)
+
+	files, err := bundle.ReadImageSet(archivePath)
+	require.NoError(t, err)
+	var catalogIndex, blobs int
+	for name := range files {
+		switch {
+		case filepath.Base(name) == "index.json" && filepath.Base(filepath.Dir(name)) == "index":
+			catalogIndex++
+		case filepath.Dir(name) == "blobs":
+			blobs++
+		}
+	}
+	require.Equal(t, 1, catalogIndex)
+	// a config and a layer for each image
+	require.Equal(t, 6, blobs)
+}
@@ -11,6 +11,7 @@ const (
 	// ImageSetConfiguration object kind.
 	ImageSetConfigurationKind = "ImageSetConfiguration"
 	OCITransportPrefix        = "oci:"
+	DirTransportPrefix        = "dir:"
 )
 
 // ImageSetConfiguration configures image set creation.
@@ -26,8 +27,97 @@ type ImageSetConfigurationSpec struct {
 	Mirror Mirror `json:"mirror"`
 	// ArchiveSize is the size of the segmented archive in GB
 	ArchiveSize int64 `json:"archiveSize,omitempty"`
+	// ArchiveCompression defines the compression of the imageset
+	// archives. The archives are plain tar archives when unset.
+	ArchiveCompression *ArchiveCompression `json:"archiveCompression,omitempty"`
 	// StorageConfig for reading/writing metadata and files.
 	StorageConfig StorageConfig `json:"storageConfig"`
+	// Profiles defines named sets of content that are merged
+	// onto Mirror when selected with --profile. This allows
+	// sites that differ slightly (e.g. edge and datacenter)
+	// to share a single base configuration.
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	// Notifications defines the endpoints notified with a
+	// summary of the run when create or publish finishes.
+	Notifications *Notifications `json:"notifications,omitempty"`
+	// Proxy defines the proxy and the certificate authorities
+	// used by every HTTP client of oc-mirror.
+	Proxy *Proxy `json:"proxy,omitempty"`
+}
+
+// Proxy defines the proxy used to reach registries and
+// update servers, and the certificate authorities trusted
+// in addition to the system ones. Environment variables
+// referenced as $VAR or ${VAR} in the proxy URLs are expanded,
+// so proxy credentials do not have to be stored in the configuration.
+type Proxy struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests.
+	HTTPProxy string `json:"httpProxy,omitempty"`
+	// HTTPSProxy is the URL of the proxy for HTTPS requests.
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	// NoProxy is a comma-separated list of hosts, domains
+	// and CIDRs requested without proxy.
+	NoProxy string `json:"noProxy,omitempty"`
+	// TrustedCA is the path to a PEM bundle of certificate
+	// authorities trusted in addition to the system ones.
+	TrustedCA string `json:"trustedCA,omitempty"`
+}
+
+// IsSet will determine whether any proxy
+// setting is set
+func (p *Proxy) IsSet() bool {
+	return p != nil && (p.HTTPProxy != "" || p.HTTPSProxy != "" || p.NoProxy != "" || p.TrustedCA != "")
+}
+
+// ArchiveCompression defines the algorithm and the level
+// the imageset archives are compressed with.
+type ArchiveCompression struct {
+	// Algorithm is the compression algorithm, CompressionZstd or CompressionGzip.
+	Algorithm string `json:"algorithm"`
+	// Level is the compression level, 1 (fastest) to 22 (best) for zstd
+	// and 1 to 9 for gzip. The default level of the algorithm when unset.
+	Level int `json:"level,omitempty"`
+}
+
+const (
+	// CompressionZstd compresses the archives with Zstandard.
+	CompressionZstd = "zstd"
+	// CompressionGzip compresses the archives with gzip.
+	CompressionGzip = "gzip"
+)
+
+// IsSet will determine whether the archives are compressed
+func (c *ArchiveCompression) IsSet() bool {
+	return c != nil && c.Algorithm != ""
+}
+
+// Notifications defines the endpoints a summary of the
+// run is posted to when create or publish finishes.
+type Notifications struct {
+	// Webhook is the URL the summary is posted to as JSON.
+	Webhook string `json:"webhook,omitempty"`
+	// Slack is the URL of a Slack incoming webhook the
+	// summary is posted to as text.
+	Slack string `json:"slack,omitempty"`
+}
+
+// IsSet will determine whether any notification
+// endpoint is set
+func (n *Notifications) IsSet() bool {
+	return n != nil && (n.Webhook != "" || n.Slack != "")
+}
+
+// Profile defines content that is merged onto the base
+// Mirror configuration when the profile is selected.
+type Profile struct {
+	// Operators are merged with the base operators by catalog.
+	// Packages of a catalog found in the base configuration
+	// replace the base packages with the same name.
+	Operators []Operator `json:"operators,omitempty"`
+	// AdditionalImages are appended to the base additional images.
+	AdditionalImages []Image `json:"additionalImages,omitempty"`
+	// BlockedImages are appended to the base blocked images.
+	BlockedImages []BlockedImage `json:"blockedImages,omitempty"`
 }
 
 // Mirror defines the configuration for content types within the imageset.
@@ -44,10 +134,39 @@ type Mirror struct {
 	// BlockedImages define a list of images that will be blocked
 	// from the mirroring process if they exist in other content
 	// types in the configuration.
-	BlockedImages []Image `json:"blockedImages,omitempty"`
+	BlockedImages []BlockedImage `json:"blockedImages,omitempty"`
 	// Samples defines the configuration for Sample content types.
 	// This is currently not implemented.
 	Samples []SampleImages `json:"samples,omitempty"`
+	// Artifacts defines the configuration for a list of OCI artifacts,
+	// such as Helm charts pushed to registries, WASM modules or files
+	// pushed with ORAS.
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// NamespaceMappings define the namespace of the mirror registry
+	// the images are mirrored under, by source registry or namespace.
+	// The first mapping matching the source of an image applies,
+	// images matching none are mirrored under the user namespace only.
+	NamespaceMappings []NamespaceMapping `json:"namespaceMappings,omitempty"`
+}
+
+// NamespaceMapping defines the namespace the images
+// of a source registry or namespace are mirrored under.
+type NamespaceMapping struct {
+	// Source is a glob matched against the registry of the source
+	// of an image, and against its registry followed by each level
+	// of its namespace, such as registry.redhat.io or quay.io/*.
+	Source string `json:"source"`
+	// Namespace is the namespace of the mirror registry, under
+	// the user namespace, the matching images are mirrored under.
+	Namespace string `json:"namespace"`
+}
+
+// Artifact defines the configuration for an OCI artifact. Artifacts are
+// copied manifest by manifest and blob by blob whatever their media types,
+// so they keep their digests, artifact type and annotations.
+type Artifact struct {
+	// Name of the artifact, a reference tagged or pinned by digest.
+	Name string `json:"name"`
 }
 
 // Platform defines the configuration for OpenShift and OKD platform types.
@@ -62,6 +181,27 @@ type Platform struct {
 	// to mirror for the release image. This is defined at the
 	// platform level to enable cross-channel upgrades.
 	Architectures []string `json:"architectures,omitempty"`
+	// GraphDataURL is the location of the Cincinnati graph data
+	// archive published in the graph image, an http(s) URL or a
+	// file:// path to a previously downloaded archive.
+	// Defaults to the graph data of api.openshift.com.
+	GraphDataURL string `json:"graphDataURL,omitempty"`
+	// Samples mirrors the images of the imagestreams the cluster
+	// samples operator installs for a release, populating the
+	// developer catalog of disconnected clusters.
+	Samples *PlatformSamples `json:"samples,omitempty"`
+}
+
+// PlatformSamples defines the imagestreams of the cluster
+// samples operator of a release to mirror.
+type PlatformSamples struct {
+	// Release is the version of the mirrored release the
+	// imagestreams are read from, such as 4.14.1.
+	// Defaults to the latest release mirrored.
+	Release string `json:"release,omitempty"`
+	// ImageStreams are the names of the imagestreams to mirror,
+	// such as ruby or nodejs. Defaults to every imagestream.
+	ImageStreams []string `json:"imageStreams,omitempty"`
 }
 
 // ReleaseChannel defines the configuration for individual
@@ -84,6 +224,17 @@ type ReleaseChannel struct {
 	// first release in the channel and the MaxVersion
 	// to the last release in the channel.
 	Full bool `json:"full,omitempty"`
+	// GraphURL is the Cincinnati update graph endpoint the
+	// channel is resolved with, such as an OpenShift Update
+	// Service instance, or a file:// path to a graph snapshot
+	// for offline planning: a graph file, or a directory of
+	// <channel>-<arch>.json graph files. Defaults to the
+	// endpoint of the platform type.
+	GraphURL string `json:"graphURL,omitempty"`
+	// PromotedIn restricts the releases mirrored from the
+	// channel to the versions also in the channel PromotedIn,
+	// such as the fast-4.14 releases that reached stable-4.14.
+	PromotedIn string `json:"promotedIn,omitempty"`
 }
 
 // IsHeadsOnly determine if the mode set mirrors only channel head.
@@ -102,7 +253,19 @@ type Operator struct {
 	// pulls on later mirrors.
 	// This image should be an exact image pin (registry/namespace/name@sha256:<hash>)
 	// but is not required to be.
+	// Catalog can also be an OCI layout (oci://<path>) or a file-based catalog
+	// directory (dir://<path>) on disk.
 	Catalog string `json:"catalog"`
+	// Bundles are operator bundle images that are not published in a catalog.
+	// When set, the bundles are rendered into a catalog generated by oc-mirror
+	// instead of mirroring the packages of Catalog, and Catalog is only the base
+	// image the generated catalog is built on. Channels are generated from the
+	// channels annotations of the bundles, ordered by bundle version.
+	Bundles []string `json:"bundles,omitempty"`
+	// BaseImage is the image a catalog read from a file-based catalog
+	// directory (dir://) is built on, typically an opm image. It is
+	// required with, and only used by, dir:// catalogs.
+	BaseImage string `json:"baseImage,omitempty"`
 	// TargetName is the target image name the catalog will be built with. If unset,
 	// the catalog will be published with the provided name in the Catalog
 	// field.
@@ -132,6 +295,28 @@ type Operator struct {
 	// SkipDependencies will not include dependencies
 	// of bundles included in the diff if true.
 	SkipDependencies bool `json:"skipDependencies,omitempty"`
+	// IncludeSuccessors will add the successor of any requested package
+	// that is marked as deprecated in the catalog (olm.deprecations) to
+	// the packages to mirror, so renamed or replaced operators keep being
+	// updated by incremental mirrors.
+	IncludeSuccessors bool `json:"includeSuccessors,omitempty"`
+	// InspectBundles adds the images declared by the ClusterServiceVersion of
+	// each mirrored bundle (its related images and the containers of its
+	// deployments) to the images to mirror, for bundles whose related images
+	// are missing from the catalog. Bundle images are pulled when the catalog
+	// does not hold the objects of the bundles.
+	InspectBundles bool `json:"inspectBundles,omitempty"`
+	// ExcludeIncompatible excludes the bundles whose olm.maxOpenShiftVersion
+	// property is below the lowest OpenShift version mirrored by the platform
+	// section, as they cannot be installed on the mirrored releases. Bundles
+	// that do not declare the property are kept with a warning.
+	ExcludeIncompatible bool `json:"excludeIncompatible,omitempty"`
+	// PullSecret is a docker or podman auth file holding the credentials
+	// of the registries of the catalog. It is merged with the default auth
+	// file and the --authfile files into the credentials of the run, its
+	// entries taking precedence over theirs and over the pull secrets of
+	// the catalogs before it.
+	PullSecret string `json:"pullSecret,omitempty"`
 	// OriginalRef is used when the Catalog is an OCI FBC (File Based Catalog) location.
 	// It contains the reference to the original repo on a remote registry
 	// Deprecated in oc-mirror 4.13, and will no longer be used.
@@ -143,7 +328,7 @@ type Operator struct {
 // are set between Catalog, TargetCatalog (and soon deprecated
 // TargetName), and TargetTag.
 func (o Operator) GetUniqueName() (string, error) {
-	ctlgRef := o.Catalog
+	ctlgRef := o.CatalogImage()
 	if o.TargetCatalog == "" && o.TargetName == "" && o.TargetTag == "" {
 		return TrimProtocol(ctlgRef), nil
 	}
@@ -221,10 +406,11 @@ func ParseImageReference(imageName string) (string, string, string, string, stri
 	return registry, namespace, repo, tag, sha
 }
 
-// trimProtocol removes oci://, file:// or docker:// from
+// trimProtocol removes oci://, dir://, file:// or docker:// from
 // the parameter imageName
 func TrimProtocol(imageName string) string {
 	imageName = strings.TrimPrefix(imageName, OCITransportPrefix)
+	imageName = strings.TrimPrefix(imageName, DirTransportPrefix)
 	imageName = strings.TrimPrefix(imageName, "file:")
 	imageName = strings.TrimPrefix(imageName, "docker:")
 	imageName = strings.TrimPrefix(imageName, "//")
@@ -239,10 +425,31 @@ func (o Operator) IsHeadsOnly() bool {
 	return !o.Full
 }
 
+// IsBundleList determines whether the catalog is generated
+// from Bundles instead of mirrored from Catalog.
+func (o Operator) IsBundleList() bool {
+	return len(o.Bundles) != 0
+}
+
 func (o Operator) IsFBCOCI() bool {
 	return strings.HasPrefix(o.Catalog, OCITransportPrefix)
 }
 
+// IsFBCDir determines whether the catalog is read from
+// a file-based catalog directory instead of an image.
+func (o Operator) IsFBCDir() bool {
+	return strings.HasPrefix(o.Catalog, DirTransportPrefix)
+}
+
+// CatalogImage returns the image the catalog is mirrored from,
+// or the base image of a catalog read from a directory.
+func (o Operator) CatalogImage() string {
+	if o.IsFBCDir() {
+		return o.BaseImage
+	}
+	return o.Catalog
+}
+
 // Helm defines the configuration for Helm chart download
 // and image mirroring
 type Helm struct {
@@ -286,6 +493,32 @@ type Image struct {
 	Name string `json:"name"`
 }
 
+// BlockedImage defines images blocked from the mirroring process.
+// An image is blocked when it matches every field set.
+type BlockedImage struct {
+	// Name is a regular expression matched against the full reference
+	// of the image, such as registry/namespace/name:tag.
+	Name string `json:"name,omitempty"`
+	// Registry is a glob pattern matched against the registry of the image.
+	Registry string `json:"registry,omitempty"`
+	// Namespace is a glob pattern matched against the namespace of the image,
+	// such as openshift4 or the nested path of the repository before its name.
+	Namespace string `json:"namespace,omitempty"`
+	// Repository is a glob pattern matched against the name of the image,
+	// the last component of its repository.
+	Repository string `json:"repository,omitempty"`
+	// Tags are glob patterns matched against the tag of the image.
+	// Images pulled by digest only have no tag and do not match.
+	Tags []string `json:"tags,omitempty"`
+	// Digests are the digests of the images to block.
+	Digests []string `json:"digests,omitempty"`
+}
+
+// IsPattern returns whether b matches images by more than its name.
+func (b BlockedImage) IsPattern() bool {
+	return b.Registry != "" || b.Namespace != "" || b.Repository != "" || len(b.Tags) != 0 || len(b.Digests) != 0
+}
+
 // SampleImages define the configuration
 // for Sameple content types.
 // Not implemented.
@@ -114,6 +114,17 @@ func TestGetUniqueName(t *testing.T) {
 			exp:      "def/redhat-operator-index:v4.12",
 			expError: "",
 		},
+		{
+			desc: "FBC directory flow",
+			ref: Operator{
+				Catalog:       "dir:///home/myuser/git/catalog",
+				BaseImage:     "registry.redhat.io/openshift4/ose-operator-registry:v4.14",
+				TargetCatalog: "internal/operators",
+				TargetTag:     "v1",
+			},
+			exp:      "registry.redhat.io/internal/operators:v1",
+			expError: "",
+		},
 	}
 
 	for _, s := range specs {
@@ -48,6 +48,16 @@ func (it ImageType) String() string {
 	return imageTypeStrings[it]
 }
 
+// ParseImageType returns the ImageType
+// represented by s, such as generic.
+func ParseImageType(s string) (ImageType, error) {
+	it, found := imageStringsType[s]
+	if !found {
+		return TypeInvalid, fmt.Errorf("unknown image type %q", s)
+	}
+	return it, nil
+}
+
 // MarshalJSON marshals the ImageType as a quoted json string
 func (it ImageType) MarshalJSON() ([]byte, error) {
 	if err := it.validate(); err != nil {
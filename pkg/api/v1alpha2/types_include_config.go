@@ -34,6 +34,22 @@ type IncludePackage struct {
 	// - CASE03676821
 	// Ability to override default channel.
 	DefaultChannel string `json:"defaultChannel,omitempty"`
+
+	// CLIDownloads downloads the client binaries declared by the
+	// ConsoleCLIDownload objects of the mirrored bundles of the package
+	// (e.g. tkn for OpenShift Pipelines) to the results directory.
+	CLIDownloads bool `json:"cliDownloads,omitempty"`
+
+	// SelectedBundles are the only bundles of the package to mirror, by name.
+	// Their dependency closure is recorded in the metadata, so later runs keep
+	// mirroring the same dependencies even if the catalog drops them.
+	SelectedBundles []SelectedBundle `json:"selectedBundles,omitempty" yaml:"selectedBundles,omitempty"`
+}
+
+// SelectedBundle is a bundle of a package selected by name.
+type SelectedBundle struct {
+	// Name of the bundle.
+	Name string `json:"name" yaml:"name"`
 }
 
 // IncludeChannel contains a name (required) and versions (optional)
@@ -75,6 +91,12 @@ func (ic *IncludeConfig) ConvertToDiffIncludeConfig() (dic diff.DiffIncludeConfi
 
 		dpkg := diff.DiffIncludePackage{Name: pkg.Name, DefaultChannel: pkg.DefaultChannel}
 		switch {
+		case len(pkg.SelectedBundles) != 0:
+			// The upgrade graphs from the selected bundles are
+			// included, then filtered down to the selected bundles.
+			for _, b := range pkg.SelectedBundles {
+				dpkg.Bundles = append(dpkg.Bundles, b.Name)
+			}
 		case pkg.MinVersion != "" && pkg.MaxVersion != "":
 			dpkg.Range = fmt.Sprintf(">=%s <=%s", pkg.MinVersion, pkg.MaxVersion)
 		case pkg.MinVersion != "":
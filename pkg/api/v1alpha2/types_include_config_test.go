@@ -214,6 +214,28 @@ func TestConvertToDiffIncludeConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Valid/WithSelectedBundles",
+			cfg: IncludeConfig{
+				Packages: []IncludePackage{
+					{
+						Name:            "bar",
+						SelectedBundles: []SelectedBundle{{Name: "bundle-0.1.0"}, {Name: "bundle-0.3.0"}},
+					},
+				},
+			},
+			exp: diff.DiffIncludeConfig{
+				Packages: []diff.DiffIncludePackage{
+					{
+						Name: "bar",
+						Bundles: []string{
+							"bundle-0.1.0",
+							"bundle-0.3.0",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Invalid/NoPackageName",
 			cfg: IncludeConfig{
@@ -31,6 +31,26 @@ type MetadataSpec struct {
 	// PastAssociations define the history about the set of mirrored images including
 	// child manifest and layer digest information
 	PastAssociations []Association `json:"pastAssociations,omitempty"`
+	// OCIDigests are the digests of the images converted to OCI media types
+	// when published, keyed by the digest of the original image.
+	OCIDigests map[string]string `json:"ociDigests,omitempty"`
+	// Interrupted records the last run interrupted by a signal since PastMirror,
+	// cleared by the next run.
+	Interrupted *InterruptedRun `json:"interrupted,omitempty"`
+}
+
+// InterruptedRun records a run stopped by a signal before it completed.
+type InterruptedRun struct {
+	// Timestamp is the Unix time of the interruption.
+	Timestamp int `json:"timestamp"`
+	// Sequence is the sequence the run was creating or publishing.
+	Sequence int `json:"sequence"`
+	// Phase is the phase the run was in, such as mirror or publish.
+	Phase string `json:"phase"`
+	// ImagesMirrored is the number of images mirrored before the interruption.
+	ImagesMirrored int `json:"imagesMirrored"`
+	// ImagesPlanned is the number of images the run planned to mirror.
+	ImagesPlanned int `json:"imagesPlanned"`
 }
 
 // PastMirror defines the specification for previously mirrored content.
@@ -51,6 +71,95 @@ type PastMirror struct {
 	// Associations are metadata about the set of mirrored images including
 	// child manifest and layer digest information
 	Associations []Association `json:"associations,omitempty"`
+	// Stats records which layers were already present in the mirror
+	// registry when this imageset was published.
+	Stats *MirrorStats `json:"stats,omitempty"`
+	// Annotations are user provided key/value pairs describing the context
+	// of the mirror operation, e.g. a ticket ID or the site mirrored to.
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// ToolVersion is the version of oc-mirror that processed the mirror.
+	ToolVersion string `json:"toolVersion,omitempty"`
+	// ResolvedTags maps the additional images referenced by a tag
+	// to the digest the tag resolved to in the mirror operation.
+	ResolvedTags map[string]string `json:"resolvedTags,omitempty"`
+	// Artifacts are the OCI artifacts of the mirror operation
+	// with the digest their reference resolved to.
+	Artifacts []ArtifactMetadata `json:"artifacts,omitempty"`
+	// Fingerprint is the digest of the imageset configuration and of
+	// the catalogs it references, compared by runs with --lock to skip
+	// mirror operations with an unchanged configuration.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Samples are the sample imagestreams mirrored in the mirror
+	// operation, from which the samples operator configuration
+	// is generated when the imageset is published.
+	Samples *SamplesMetadata `json:"samples,omitempty"`
+}
+
+// SamplesMetadata holds the sample imagestreams of a release
+// mirrored in a mirror operation.
+type SamplesMetadata struct {
+	// Release is the version of the release the imagestreams were read from.
+	Release string `json:"release"`
+	// ImageStreams are the names of the imagestreams mirrored.
+	ImageStreams []string `json:"imageStreams,omitempty"`
+	// SkippedImageStreams are the names of the imagestreams of
+	// the release that were not mirrored.
+	SkippedImageStreams []string `json:"skippedImageStreams,omitempty"`
+}
+
+// ArtifactMetadata holds the digest an artifact resolved to in a mirror operation.
+type ArtifactMetadata struct {
+	// Name is the reference of the artifact in the imageset configuration.
+	Name string `json:"name"`
+	// Digest of the manifest of the artifact.
+	Digest string `json:"digest"`
+}
+
+// MirrorStats holds layer statistics recorded while publishing an imageset.
+// Layers are "reused" when they were left out of the imageset because a
+// previous publish already pushed them to the mirror registry.
+type MirrorStats struct {
+	// LayersPublished is the number of layers pushed from the imageset.
+	LayersPublished int `json:"layersPublished"`
+	// LayersReused is the number of layers found in the mirror registry.
+	LayersReused int `json:"layersReused"`
+	// ReusedByImage is the number of reused layers per image.
+	ReusedByImage map[string]int `json:"reusedByImage,omitempty"`
+	// ImagesSkipped is the number of images found in the mirror
+	// registry, left by a previous publish, and not published again.
+	ImagesSkipped int `json:"imagesSkipped,omitempty"`
+}
+
+// Record adds a layer of image to the statistics.
+func (s *MirrorStats) Record(image string, reused bool) {
+	if !reused {
+		s.LayersPublished++
+		return
+	}
+	s.LayersReused++
+	if s.ReusedByImage == nil {
+		s.ReusedByImage = map[string]int{}
+	}
+	s.ReusedByImage[image]++
+}
+
+// RecordSkipped adds an image found in the mirror registry, and
+// its layers, reused, to the statistics.
+func (s *MirrorStats) RecordSkipped(image string, layers int) {
+	s.ImagesSkipped++
+	for i := 0; i < layers; i++ {
+		s.Record(image, true)
+	}
+}
+
+// ReuseRatio returns the fraction of layers that were
+// already present in the mirror registry.
+func (s *MirrorStats) ReuseRatio() float64 {
+	total := s.LayersPublished + s.LayersReused
+	if total == 0 {
+		return 0
+	}
+	return float64(s.LayersReused) / float64(total)
 }
 
 // OperatorMetadata holds an Operator's post-mirror metadata.
@@ -66,6 +175,41 @@ type OperatorMetadata struct {
 	// be validated against the current catalog during each run
 	// and updated.
 	IncludeConfig `json:",inline"`
+	// ChannelHeads are the heads of the channels of the
+	// mirrored heads-only catalog. They are compared to the
+	// current channel heads to compute diffs by channel head
+	// movement.
+	ChannelHeads []ChannelHead `json:"channelHeads,omitempty"`
+	// PinnedBundles are the selected bundles of the catalog and the
+	// bundles of their dependency closure. They are mirrored again by
+	// the next runs selecting the same bundles, even if the catalog no
+	// longer has them.
+	PinnedBundles []PinnedBundle `json:"pinnedBundles,omitempty"`
+}
+
+// PinnedBundle holds a bundle of the dependency closure
+// of the selected bundles of a mirrored catalog.
+type PinnedBundle struct {
+	// Package is the name of the package.
+	Package string `json:"package"`
+	// Channel is the name of the channel the bundle was mirrored in.
+	Channel string `json:"channel"`
+	// Name is the name of the bundle.
+	Name string `json:"name"`
+	// Image is the bundle image, by digest.
+	Image string `json:"image"`
+	// Selected is set for the selected bundles, the roots of the closure.
+	Selected bool `json:"selected,omitempty"`
+}
+
+// ChannelHead holds the head bundle of a mirrored package channel.
+type ChannelHead struct {
+	// Package is the name of the package.
+	Package string `json:"package"`
+	// Channel is the name of the channel.
+	Channel string `json:"channel"`
+	// Bundle is the name of the channel head bundle.
+	Bundle string `json:"bundle"`
 }
 
 // PlatformMetadata holds a Platform's post-mirror metadata.
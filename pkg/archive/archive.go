@@ -37,11 +37,25 @@ type packager struct {
 	manifest    map[string]struct{}
 	blobs       map[string]struct{}
 	packedBlobs map[string]struct{}
+	// MaxFiles is the maximum number of files written to an archive,
+	// in addition to its size limit. 0 means no limit.
+	MaxFiles int
+	// ArchivesPerDir groups the archives into numbered subdirectories
+	// of the destination directory holding ArchivesPerDir archives each.
+	// 0 writes every archive to the destination directory.
+	ArchivesPerDir int
+	// Format is the format version recorded in the first archive.
+	// Defaults to CurrentFormat.
+	Format Format
 	Archiver
 }
 
 // NewArchiver creates a new archiver for tar archive manipultation
 func NewArchiver() Archiver {
+	return newTar()
+}
+
+func newTar() *archiver.Tar {
 	return &archiver.Tar{
 		OverwriteExisting:      true,
 		MkdirAll:               true,
@@ -78,7 +92,11 @@ func (p *packager) CreateSplitArchive(ctx context.Context, backend storage.Backe
 	// Declare split variables
 	splitNum := 0
 	splitSize := int64(0)
-	splitPath := filepath.Join(destDir, fmt.Sprintf("%s_%06d.%s", prefix, splitNum, p.String()))
+	splitFiles := 0
+	splitPath, err := p.splitPath(destDir, prefix, splitNum)
+	if err != nil {
+		return err
+	}
 
 	splitFile, err := p.createArchive(splitPath)
 
@@ -96,6 +114,13 @@ func (p *packager) CreateSplitArchive(ctx context.Context, backend storage.Backe
 	if err := packMetadata(ctx, p, backend); err != nil {
 		return fmt.Errorf("writing metadata to archive %s failed: %v", splitPath, err)
 	}
+	format := p.Format
+	if format == "" {
+		format = CurrentFormat
+	}
+	if err := packFormat(p, format); err != nil {
+		return fmt.Errorf("writing format to archive %s failed: %v", splitPath, err)
+	}
 
 	walkErr := filepath.Walk(sourceDir, func(fpath string, info os.FileInfo, err error) error {
 
@@ -145,8 +170,9 @@ func (p *packager) CreateSplitArchive(ctx context.Context, backend storage.Backe
 			ReadCloser: file,
 		}
 
-		// If the file is too large create a new one
-		if info.Size()+splitSize > maxSplitSize {
+		// If the file is too large or the archive holds too many files create a new one
+		tooManyFiles := p.MaxFiles > 0 && !info.IsDir() && splitFiles >= p.MaxFiles
+		if info.Size()+splitSize > maxSplitSize || tooManyFiles {
 
 			// Close current tar archive
 			if err := p.Close(); err != nil {
@@ -159,7 +185,11 @@ func (p *packager) CreateSplitArchive(ctx context.Context, backend storage.Backe
 			// Increment split number and reset splitSize
 			splitNum += 1
 			splitSize = int64(0)
-			splitPath = filepath.Join(destDir, fmt.Sprintf("%s_%06d.%s", prefix, splitNum, p.String()))
+			splitFiles = 0
+			splitPath, err = p.splitPath(destDir, prefix, splitNum)
+			if err != nil {
+				return err
+			}
 
 			// Create a new tar archive for writing
 			splitFile, err = p.createArchive(splitPath)
@@ -184,6 +214,9 @@ func (p *packager) CreateSplitArchive(ctx context.Context, backend storage.Backe
 		klog.V(1).Infof("File %s added to archive", fpath)
 
 		splitSize += info.Size()
+		if !info.IsDir() {
+			splitFiles++
+		}
 
 		return nil
 	})
@@ -230,6 +263,20 @@ func Unarchive(a Archiver, source, destination string, excludePaths []string) er
 	return nil
 }
 
+// splitPath returns the path of the split archive splitNum, creating
+// its subdirectory of destDir when the archives are grouped.
+func (p *packager) splitPath(destDir, prefix string, splitNum int) (string, error) {
+	name := fmt.Sprintf("%s_%06d.%s", prefix, splitNum, p.String())
+	if p.ArchivesPerDir <= 0 {
+		return filepath.Join(destDir, name), nil
+	}
+	dir := filepath.Join(destDir, fmt.Sprintf("%s_dir%04d", prefix, splitNum/p.ArchivesPerDir))
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("creating %s: %v", dir, err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
 // createArchive is a helper function that prepares a new split archive
 func (p *packager) createArchive(splitPath string) (splitFile *os.File, err error) {
 
@@ -262,6 +309,8 @@ func includeFile(fpath string) bool {
 		config.InternalDir:         {},
 		config.CatalogsDir:         {},
 		config.HelmDir:             {},
+		config.CLIDownloadsDir:     {},
+		config.ArtifactsDir:        {},
 		config.ReleaseSignatureDir: {},
 		config.GraphDataDir:        {},
 	}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/mholt/archiver/v3"
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
 	"github.com/openshift/oc-mirror/pkg/config"
 	"github.com/openshift/oc-mirror/pkg/metadata/storage"
@@ -100,6 +101,78 @@ func TestSplitArchive(t *testing.T) {
 	}
 }
 
+func TestSplitArchiveLayout(t *testing.T) {
+	sourceDir := t.TempDir()
+	destDir := t.TempDir()
+
+	var blobs []string
+	for i := 0; i < 5; i++ {
+		blob := fmt.Sprintf("sha256:%d", i)
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, blob), []byte("blob"), 0644))
+		blobs = append(blobs, blob)
+	}
+
+	backend, err := storage.NewLocalBackend(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, backend.WriteMetadata(context.Background(), &v1alpha2.Metadata{}, config.MetadataBasePath))
+
+	packager := NewPackager(nil, blobs)
+	packager.MaxFiles = 2
+	packager.ArchivesPerDir = 2
+	require.NoError(t, packager.CreateSplitArchive(context.Background(), backend, 5*1024*1024, destDir, sourceDir, "testbundle", true))
+
+	var archives []string
+	require.NoError(t, filepath.Walk(destDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, err := filepath.Rel(destDir, path)
+			archives = append(archives, rel)
+			return err
+		}
+		return err
+	}))
+	require.Equal(t, []string{
+		filepath.Join("testbundle_dir0000", "testbundle_000000.tar"),
+		filepath.Join("testbundle_dir0000", "testbundle_000001.tar"),
+		filepath.Join("testbundle_dir0001", "testbundle_000002.tar"),
+	}, archives)
+
+	// Every blob is packed once, at most two per archive.
+	packed := map[string]int{}
+	for _, archive := range archives {
+		count := 0
+		require.NoError(t, NewArchiver().Walk(filepath.Join(destDir, archive), func(f archiver.File) error {
+			if strings.HasPrefix(f.Name(), "sha256:") {
+				packed[f.Name()]++
+				count++
+			}
+			return nil
+		}))
+		require.LessOrEqual(t, count, 2)
+	}
+	require.Len(t, packed, 5)
+
+	// The format is recorded in the first archive.
+	formatDir := t.TempDir()
+	require.NoError(t, NewArchiver().Extract(filepath.Join(destDir, archives[0]), config.FormatBasePath, formatDir))
+	f, err := os.Open(filepath.Join(formatDir, config.FormatBasePath))
+	require.NoError(t, err)
+	defer f.Close()
+	format, err := ReadFormat(f)
+	require.NoError(t, err)
+	require.Equal(t, CurrentFormat, format)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("v1")
+	require.NoError(t, err)
+	require.Equal(t, FormatV1, format)
+	require.True(t, format.Supported())
+
+	_, err = ParseFormat("v0")
+	require.EqualError(t, err, `unknown imageset format "v0", supported formats are v1, v2`)
+	require.False(t, Format("v0").Supported())
+}
+
 // writeFiles write out testfiles to be archived
 func writeFiles() error {
 	d1 := []byte("hello\ngo\n")
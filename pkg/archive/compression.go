@@ -0,0 +1,115 @@
+package archive
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/mholt/archiver/v3"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// Extensions of the imageset archives, compressed or not.
+const (
+	tarExtension     = ".tar"
+	tarZstdExtension = ".tar.zst"
+	tarGzExtension   = ".tar.gz"
+)
+
+// NewCompressedArchiver creates a new archiver for tar archives compressed
+// with algorithm at level, the default level of the algorithm if level is 0.
+// The archives are not compressed if algorithm is empty.
+func NewCompressedArchiver(algorithm string, level int) (Archiver, error) {
+	switch algorithm {
+	case "":
+		return NewArchiver(), nil
+	case v1alpha2.CompressionZstd:
+		encoderLevel := zstd.SpeedDefault
+		if level != 0 {
+			encoderLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		return &tarZstd{TarZstd: &archiver.TarZstd{Tar: newTar()}, level: encoderLevel}, nil
+	case v1alpha2.CompressionGzip:
+		tgz := &archiver.TarGz{Tar: newTar(), CompressionLevel: gzip.DefaultCompression}
+		if level != 0 {
+			tgz.CompressionLevel = level
+		}
+		return tgz, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive compression %q", algorithm)
+	}
+}
+
+// ArchiverFor creates a new archiver reading the imageset archive at path,
+// decompressing it according to its extension.
+func ArchiverFor(path string) Archiver {
+	switch {
+	case strings.HasSuffix(path, tarZstdExtension):
+		return &archiver.TarZstd{Tar: newTar()}
+	case strings.HasSuffix(path, tarGzExtension):
+		return &archiver.TarGz{Tar: newTar()}
+	default:
+		return NewArchiver()
+	}
+}
+
+// IsArchive returns true if path has the extension of an imageset archive.
+func IsArchive(path string) bool {
+	for _, ext := range []string{tarExtension, tarZstdExtension, tarGzExtension} {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Decompress returns the tar stream of the imageset archive at path read from r,
+// decompressed according to the extension of path.
+func Decompress(path string, r io.Reader) (io.ReadCloser, error) {
+	switch {
+	case strings.HasSuffix(path, tarZstdExtension):
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	case strings.HasSuffix(path, tarGzExtension):
+		return gzip.NewReader(r)
+	default:
+		return io.NopCloser(r), nil
+	}
+}
+
+// tarZstd writes tar archives compressed with Zstandard at level,
+// archiver.TarZstd only compressing at the default level.
+type tarZstd struct {
+	*archiver.TarZstd
+	level   zstd.EncoderLevel
+	encoder *zstd.Encoder
+}
+
+// Create opens the archive for writing, compressing it to out.
+func (t *tarZstd) Create(out io.Writer) error {
+	encoder, err := zstd.NewWriter(out, zstd.WithEncoderLevel(t.level))
+	if err != nil {
+		return fmt.Errorf("creating zstd encoder: %v", err)
+	}
+	t.encoder = encoder
+	return t.Tar.Create(encoder)
+}
+
+// Close closes the archive opened for writing, flushing the compressed stream.
+func (t *tarZstd) Close() error {
+	err := t.Tar.Close()
+	if t.encoder != nil {
+		encoder := t.encoder
+		t.encoder = nil
+		if cerr := encoder.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package archive
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mholt/archiver/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+func TestCompressedSplitArchive(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		level     int
+		want      string
+	}{
+		{name: "Valid/Uncompressed", want: "testbundle_000000.tar"},
+		{name: "Valid/Zstd", algorithm: v1alpha2.CompressionZstd, want: "testbundle_000000.tar.zst"},
+		{name: "Valid/ZstdLevel", algorithm: v1alpha2.CompressionZstd, level: 19, want: "testbundle_000000.tar.zst"},
+		{name: "Valid/Gzip", algorithm: v1alpha2.CompressionGzip, level: 1, want: "testbundle_000000.tar.gz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sourceDir := t.TempDir()
+			destDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "sha256:1"), []byte("blob"), 0644))
+
+			backend, err := storage.NewLocalBackend(t.TempDir())
+			require.NoError(t, err)
+			require.NoError(t, backend.WriteMetadata(context.Background(), &v1alpha2.Metadata{}, config.MetadataBasePath))
+
+			packager := NewPackager(nil, []string{"sha256:1"})
+			packager.Archiver, err = NewCompressedArchiver(tt.algorithm, tt.level)
+			require.NoError(t, err)
+			require.NoError(t, packager.CreateSplitArchive(context.Background(), backend, 5*1024*1024, destDir, sourceDir, "testbundle", true))
+
+			path := filepath.Join(destDir, tt.want)
+			require.True(t, IsArchive(path))
+
+			// The archive is decompressed by the archiver chosen by its extension.
+			var names []string
+			require.NoError(t, ArchiverFor(path).Walk(path, func(f archiver.File) error {
+				names = append(names, f.Name())
+				return nil
+			}))
+			require.Contains(t, names, "sha256:1")
+
+			blobDir := t.TempDir()
+			require.NoError(t, ArchiverFor(path).Extract(path, "blobs/sha256:1", blobDir))
+			data, err := os.ReadFile(filepath.Join(blobDir, "blobs", "sha256:1"))
+			require.NoError(t, err)
+			require.Equal(t, "blob", string(data))
+
+			// The tar stream is read by Decompress.
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			defer f.Close()
+			stream, err := Decompress(path, f)
+			require.NoError(t, err)
+			defer stream.Close()
+			header, err := tar.NewReader(stream).Next()
+			require.NoError(t, err)
+			require.Equal(t, config.MetadataBasePath, header.Name)
+			_, err = io.Copy(io.Discard, stream)
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("Invalid/Algorithm", func(t *testing.T) {
+		_, err := NewCompressedArchiver("xz", 0)
+		require.EqualError(t, err, `unsupported archive compression "xz"`)
+	})
+}
+
+func TestIsArchive(t *testing.T) {
+	require.True(t, IsArchive("mirror_seq1_000000.tar"))
+	require.True(t, IsArchive("mirror_seq1_000000.tar.zst"))
+	require.True(t, IsArchive("mirror_seq1_000000.tar.gz"))
+	require.False(t, IsArchive("mirror_seq1_000000.zst"))
+	require.False(t, IsArchive("mirror_seq1_dir0000"))
+}
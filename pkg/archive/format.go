@@ -0,0 +1,90 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/mholt/archiver/v3"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+// Format is the version of the layout and of the metadata of
+// the imageset archives, recorded in every imageset.
+type Format string
+
+const (
+	// FormatV1 is the format read by every oc-mirror publish:
+	// the metadata only holds the fields known to the first
+	// releases and the archives are written to a single directory.
+	FormatV1 Format = "v1"
+	// FormatV2 adds the metadata fields recording the layer
+	// statistics, annotations and channel heads of the mirrors,
+	// and archives grouped into subdirectories.
+	FormatV2 Format = "v2"
+	// CurrentFormat is the format of the imagesets created
+	// without a compatibility format.
+	CurrentFormat = FormatV2
+)
+
+var formats = []Format{FormatV1, FormatV2}
+
+// ParseFormat returns the format named s.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range formats {
+		if Format(s) == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown imageset format %q, supported formats are %s", s, formatNames())
+}
+
+// Supported returns true if this version of oc-mirror can read imagesets of format f.
+func (f Format) Supported() bool {
+	_, err := ParseFormat(string(f))
+	return err == nil
+}
+
+func formatNames() string {
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, string(f))
+	}
+	return strings.Join(names, ", ")
+}
+
+// ReadFormat returns the format recorded in r.
+func ReadFormat(r io.Reader) (Format, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return Format(strings.TrimSpace(string(data))), nil
+}
+
+// formatFileInfo describes the format file written to the first archive.
+type formatFileInfo struct {
+	size int64
+}
+
+func (i formatFileInfo) Name() string       { return config.FormatFile }
+func (i formatFileInfo) Size() int64        { return i.size }
+func (i formatFileInfo) Mode() fs.FileMode  { return 0644 }
+func (i formatFileInfo) ModTime() time.Time { return time.Now() }
+func (i formatFileInfo) IsDir() bool        { return false }
+func (i formatFileInfo) Sys() interface{}   { return nil }
+
+func packFormat(arc Archiver, format Format) error {
+	data := string(format) + "\n"
+	f := archiver.File{
+		FileInfo: archiver.FileInfo{
+			FileInfo:   formatFileInfo{size: int64(len(data))},
+			CustomName: config.FormatBasePath,
+		},
+		ReadCloser: io.NopCloser(strings.NewReader(data)),
+	}
+	return arc.Write(f)
+}
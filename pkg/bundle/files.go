@@ -23,7 +23,7 @@ import (
 // ReconcileV2Dir gathers all manifests and blobs that were collected during a run
 // and checks against the current list.
 // This function is used to prepare a list of files that need to added to the Imageset.
-func ReconcileV2Dir(assocs image.AssociationSet, filenames map[string]string) (manifests []string, blobs []string, err error) {
+func ReconcileV2Dir(assocs image.AssociationIterator, filenames map[string]string) (manifests []string, blobs []string, err error) {
 
 	foundFiles := map[string]struct{}{}
 
@@ -32,7 +32,11 @@ func ReconcileV2Dir(assocs image.AssociationSet, filenames map[string]string) (m
 	// directory
 	// TODO(jpower432): Investigate why this happens.
 	// Happens with oc image mirror as well.
-	for _, digest := range assocs.GetDigests() {
+	digests, err := image.GetDigests(assocs)
+	if err != nil {
+		return manifests, blobs, err
+	}
+	for _, digest := range digests {
 		foundFiles[digest] = struct{}{}
 	}
 
@@ -91,8 +95,9 @@ func ReconcileV2Dir(assocs image.AssociationSet, filenames map[string]string) (m
 	return manifests, blobs, err
 }
 
-// ReadImageSet set will create a map with all the files located in the archives
-func ReadImageSet(a archive.Archiver, from string) (map[string]string, error) {
+// ReadImageSet set will create a map with all the files located in the archives,
+// compressed or not
+func ReadImageSet(from string) (map[string]string, error) {
 
 	filesinArchive := make(map[string]string)
 
@@ -115,11 +120,9 @@ func ReadImageSet(a archive.Archiver, from string) (map[string]string, error) {
 				return fmt.Errorf("no file info")
 			}
 
-			extension := filepath.Ext(path)
-			extension = strings.TrimPrefix(extension, ".")
-			if extension == a.String() {
+			if archive.IsArchive(path) {
 				klog.V(1).Infof("Found archive %s", path)
-				return a.Walk(path, func(f archiver.File) error {
+				return archive.ArchiverFor(path).Walk(path, func(f archiver.File) error {
 					switch t := f.Header.(type) {
 					case *tar.Header:
 						name := filepath.Clean(t.Name)
@@ -142,7 +145,7 @@ func ReadImageSet(a archive.Archiver, from string) (map[string]string, error) {
 
 	} else {
 		// Walk the archive and load the file names into the map
-		err = a.Walk(from, func(f archiver.File) error {
+		err = archive.ArchiverFor(from).Walk(from, func(f archiver.File) error {
 			switch t := f.Header.(type) {
 			case *tar.Header:
 				name := filepath.Clean(t.Name)
@@ -159,11 +162,10 @@ func ReadImageSet(a archive.Archiver, from string) (map[string]string, error) {
 
 // ReadMetadataFromFile will return the metadata from a given imageset
 func ReadMetadataFromFile(ctx context.Context, archivePath string) (v1alpha2.Metadata, error) {
-	a := archive.NewArchiver()
 	meta := v1alpha2.NewMetadata()
 
 	// Get archive with metadata
-	filesInArchive, err := ReadImageSet(a, archivePath)
+	filesInArchive, err := ReadImageSet(archivePath)
 	if err != nil {
 		return meta, err
 	}
@@ -175,13 +177,13 @@ func ReadMetadataFromFile(ctx context.Context, archivePath string) (v1alpha2.Met
 	}
 	defer os.RemoveAll(tmpdir)
 
-	archive, ok := filesInArchive[config.MetadataBasePath]
+	metadataArchive, ok := filesInArchive[config.MetadataBasePath]
 	if !ok {
 		return meta, errors.New("metadata is not in archive")
 	}
 
 	klog.V(2).Infof("Extracting incoming metadata")
-	if err := a.Extract(archive, config.MetadataBasePath, tmpdir); err != nil {
+	if err := archive.ArchiverFor(metadataArchive).Extract(metadataArchive, config.MetadataBasePath, tmpdir); err != nil {
 		return meta, err
 	}
 
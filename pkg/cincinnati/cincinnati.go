@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
@@ -470,6 +472,9 @@ func GetUpdatesInRange(ctx context.Context, c Client, channel, arch string, upda
 func getGraphData(ctx context.Context, c Client) (graph graph, err error) {
 	transport := c.GetTransport()
 	uri := c.GetURL()
+	if uri.Scheme == "file" {
+		return readGraphSnapshot(uri)
+	}
 	// Download the update graph.
 	req, err := http.NewRequest("GET", uri.String(), nil)
 	if err != nil {
@@ -555,3 +560,33 @@ func (e *edge) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// readGraphSnapshot reads the update graph from the snapshot at the path of the
+// file:// URI uri: a graph file, or a directory holding a <channel>-<arch>.json
+// graph file per channel and architecture, previously downloaded from a
+// Cincinnati endpoint, for planning offline.
+func readGraphSnapshot(uri *url.URL) (graph graph, err error) {
+	fpath := uri.Path
+	info, err := os.Stat(fpath)
+	if err != nil {
+		return graph, &Error{Reason: "SnapshotFailed", Message: err.Error(), cause: err}
+	}
+	if info.IsDir() {
+		// Query parameters are added by each query, the last ones are current.
+		query := uri.Query()
+		channels, archs := query["channel"], query["arch"]
+		if len(channels) == 0 || len(archs) == 0 {
+			return graph, &Error{Reason: "SnapshotFailed", Message: fmt.Sprintf("graph snapshot directory %s requires a channel and an architecture", fpath)}
+		}
+		fpath = filepath.Join(fpath, fmt.Sprintf("%s-%s.json", channels[len(channels)-1], archs[len(archs)-1]))
+	}
+	klog.V(2).Infof("Reading update graph snapshot %s", fpath)
+	body, err := os.ReadFile(fpath)
+	if err != nil {
+		return graph, &Error{Reason: "SnapshotFailed", Message: err.Error(), cause: err}
+	}
+	if err = json.Unmarshal(body, &graph); err != nil {
+		return graph, &Error{Reason: "ResponseInvalid", Message: fmt.Sprintf("graph snapshot %s: %v", fpath, err), cause: err}
+	}
+	return graph, nil
+}
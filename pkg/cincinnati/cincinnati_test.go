@@ -7,12 +7,16 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/blang/semver/v4"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	_ "k8s.io/klog/v2" // integration tests set glog flags.
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
 )
 
 func TestGetUpdates(t *testing.T) {
@@ -818,3 +822,55 @@ func getHandlerMulti(t *testing.T, requestQuery chan<- string) http.HandlerFunc
 		}
 	}
 }
+
+func TestGraphSnapshot(t *testing.T) {
+	arch := "test-arch"
+	channelName := "stable-4.0"
+	data := []byte(`{
+		"nodes": [
+		  {"version": "4.0.0-4", "payload": "quay.io/openshift-release-dev/ocp-release:4.0.0-4"},
+		  {"version": "4.0.0-5", "payload": "quay.io/openshift-release-dev/ocp-release:4.0.0-5"},
+		  {"version": "4.0.0-6", "payload": "quay.io/openshift-release-dev/ocp-release:4.0.0-6"}
+		],
+		"edges": [[0,1],[1,2]]
+	  }`)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, channelName+"-"+arch+".json"), data, 0600))
+	file := filepath.Join(t.TempDir(), "graph.json")
+	require.NoError(t, os.WriteFile(file, data, 0600))
+
+	type spec struct {
+		name     string
+		graphURL string
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name:     "Valid/File",
+			graphURL: "file://" + file,
+		},
+		{
+			name:     "Valid/Directory",
+			graphURL: "file://" + dir,
+		},
+		{
+			name:     "Invalid/Missing",
+			graphURL: "file://" + filepath.Join(dir, "missing.json"),
+			expError: "missing.json: no such file or directory",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, err := NewClient(uuid.MustParse("01234567-0123-0123-0123-0123456789ab"), v1alpha2.ReleaseChannel{Name: channelName, GraphURL: c.graphURL})
+			require.NoError(t, err)
+			version, err := GetChannelMinOrMax(context.Background(), client, arch, channelName, false)
+			if c.expError != "" {
+				require.ErrorContains(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, semver.MustParse("4.0.0-6"), version)
+		})
+	}
+}
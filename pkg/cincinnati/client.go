@@ -1,14 +1,16 @@
 package cincinnati
 
 import (
-	"crypto/tls"
-	"crypto/x509"
+	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 
 	"github.com/google/uuid"
 	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/network"
 )
 
 // Client is a Cincinnati client which can be used to fetch update graphs from
@@ -28,6 +30,25 @@ type ocpClient struct {
 	url       url.URL
 }
 
+// NewClient creates a new Cincinnati client for the release channel ch with the
+// given client identifier. The client queries the GraphURL of ch when set, and
+// the endpoint of the platform type of ch otherwise.
+func NewClient(id uuid.UUID, ch v1alpha2.ReleaseChannel) (Client, error) {
+	switch ch.Type {
+	case v1alpha2.TypeOCP:
+		if ch.GraphURL != "" {
+			return newOCPClient(id, ch.GraphURL)
+		}
+		return NewOCPClient(id)
+	case v1alpha2.TypeOKD:
+		if ch.GraphURL != "" {
+			return newOKDClient(id, ch.GraphURL)
+		}
+		return NewOKDClient(id)
+	}
+	return nil, fmt.Errorf("invalid platform type %v", ch.Type)
+}
+
 // NewOCPClient creates a new OCP Cincinnati client with the given client identifier.
 func NewOCPClient(id uuid.UUID) (Client, error) {
 	var updateGraphURL string
@@ -37,19 +58,18 @@ func NewOCPClient(id uuid.UUID) (Client, error) {
 	} else {
 		updateGraphURL = UpdateURL
 	}
-	upstream, err := url.Parse(updateGraphURL)
-	if err != nil {
-		return &ocpClient{}, err
-	}
+	return newOCPClient(id, updateGraphURL)
+}
 
-	tls, err := getTLSConfig()
+func newOCPClient(id uuid.UUID, updateGraphURL string) (Client, error) {
+	upstream, err := url.Parse(updateGraphURL)
 	if err != nil {
 		return &ocpClient{}, err
 	}
 
 	transport := &http.Transport{
-		TLSClientConfig: tls,
-		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: network.TLSConfig(false),
+		Proxy:           network.Proxy,
 	}
 	return &ocpClient{id: id, transport: transport, url: *upstream}, nil
 }
@@ -99,20 +119,18 @@ func NewOKDClient(id uuid.UUID) (Client, error) {
 	} else {
 		updateGraphURL = OkdUpdateURL
 	}
+	return newOKDClient(id, updateGraphURL)
+}
 
+func newOKDClient(id uuid.UUID, updateGraphURL string) (Client, error) {
 	upstream, err := url.Parse(updateGraphURL)
 	if err != nil {
 		return &okdClient{}, err
 	}
 
-	tls, err := getTLSConfig()
-	if err != nil {
-		return &okdClient{}, err
-	}
-
 	transport := &http.Transport{
-		TLSClientConfig: tls,
-		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: network.TLSConfig(false),
+		Proxy:           network.Proxy,
 	}
 	return &okdClient{id: id, transport: transport, url: *upstream}, nil
 }
@@ -132,15 +150,3 @@ func (c *okdClient) GetTransport() *http.Transport {
 func (c *okdClient) SetQueryParams(_, _, _ string) {
 	// Do nothing
 }
-
-func getTLSConfig() (*tls.Config, error) {
-	certPool, err := x509.SystemCertPool()
-	if err != nil {
-		return nil, err
-	}
-	config := &tls.Config{
-		RootCAs:    certPool,
-		MinVersion: tls.VersionTLS12,
-	}
-	return config, nil
-}
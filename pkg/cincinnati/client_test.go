@@ -6,6 +6,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
 )
 
 func TestOCPClient(t *testing.T) {
@@ -42,3 +44,54 @@ func TestOKDClient(t *testing.T) {
 	client.SetQueryParams("arch", "channel", "version")
 	require.Equal(t, "", client.GetURL().RawQuery)
 }
+
+func TestNewClient(t *testing.T) {
+	id := uuid.MustParse("01234567-0123-0123-0123-0123456789ab")
+
+	type spec struct {
+		name     string
+		channel  v1alpha2.ReleaseChannel
+		expURL   string
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name:    "Valid/OCPDefault",
+			channel: v1alpha2.ReleaseChannel{Name: "stable-4.14", Type: v1alpha2.TypeOCP},
+			expURL:  UpdateURL,
+		},
+		{
+			name:    "Valid/OKDDefault",
+			channel: v1alpha2.ReleaseChannel{Name: "stable-4", Type: v1alpha2.TypeOKD},
+			expURL:  OkdUpdateURL,
+		},
+		{
+			name:    "Valid/GraphURL",
+			channel: v1alpha2.ReleaseChannel{Name: "stable-4.14", Type: v1alpha2.TypeOCP, GraphURL: "https://osus.example.com/api/upgrades_info/v1/graph"},
+			expURL:  "https://osus.example.com/api/upgrades_info/v1/graph",
+		},
+		{
+			name:    "Valid/GraphSnapshot",
+			channel: v1alpha2.ReleaseChannel{Name: "stable-4.14", Type: v1alpha2.TypeOCP, GraphURL: "file:///srv/graph"},
+			expURL:  "file:///srv/graph",
+		},
+		{
+			name:     "Invalid/PlatformType",
+			channel:  v1alpha2.ReleaseChannel{Name: "stable-4.14", Type: v1alpha2.PlatformType(5)},
+			expError: "invalid platform type",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client, err := NewClient(id, c.channel)
+			if c.expError != "" {
+				require.ErrorContains(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			u := client.GetURL()
+			require.Equal(t, c.expURL, u.String())
+		})
+	}
+}
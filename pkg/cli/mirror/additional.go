@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/containerd/containerd/errdefs"
 	"github.com/openshift/oc/pkg/cli/image/imagesource"
@@ -62,6 +63,7 @@ func (o *AdditionalOptions) Plan(ctx context.Context, imageList []v1alpha2.Image
 				return mmappings, fmt.Errorf("error parsing source image %s: %v", img.Name, err)
 			}
 			srcRef.Ref.ID = pinnedRef.Ref.ID
+			o.recordResolvedTag(ref, srcRef.Ref.ID)
 		}
 
 		// Set destination image information as file by default
@@ -76,3 +78,35 @@ func (o *AdditionalOptions) Plan(ctx context.Context, imageList []v1alpha2.Image
 	klog.Infof("error image list %s", errorImageList)
 	return mmappings, nil
 }
+
+// recordResolvedTag records the digest the tag of the additional image ref resolved to.
+func (o *AdditionalOptions) recordResolvedTag(ref, digest string) {
+	if o.resolvedTags == nil {
+		o.resolvedTags = map[string]string{}
+	}
+	o.resolvedTags[ref] = digest
+}
+
+// reportTagDrifts reports the additional images whose tag resolved to another
+// digest than in the previous mirror, recorded in previous. The images of
+// a new digest are mirrored again, the others were already mirrored.
+func (o *MirrorOptions) reportTagDrifts(previous, current map[string]string) {
+	refs := make([]string, 0, len(current))
+	for ref := range current {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		prevDigest, found := previous[ref]
+		switch {
+		case !found:
+			continue
+		case prevDigest == current[ref]:
+			klog.V(1).Infof("Tag of %s still resolves to %s", ref, prevDigest)
+		default:
+			drift := fmt.Sprintf("%s: drift detected, new digest %s mirrored (previously %s)", ref, current[ref], prevDigest)
+			klog.Info(drift)
+			o.summary.Drifts = append(o.summary.Drifts, drift)
+		}
+	}
+}
@@ -106,3 +106,26 @@ func TestPlan_Additional(t *testing.T) {
 		})
 	}
 }
+
+func TestReportTagDrifts(t *testing.T) {
+	previous := map[string]string{
+		"quay.io/foo/bar:latest":  "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		"quay.io/foo/same:latest": "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+		"quay.io/foo/gone:latest": "sha256:3333333333333333333333333333333333333333333333333333333333333333",
+	}
+	current := map[string]string{
+		"quay.io/foo/bar:latest":  "sha256:4444444444444444444444444444444444444444444444444444444444444444",
+		"quay.io/foo/same:latest": "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+		"quay.io/foo/new:latest":  "sha256:5555555555555555555555555555555555555555555555555555555555555555",
+	}
+
+	opts := &AdditionalOptions{MirrorOptions: &MirrorOptions{}}
+	opts.recordResolvedTag("quay.io/foo/bar:latest", current["quay.io/foo/bar:latest"])
+	require.Equal(t, map[string]string{"quay.io/foo/bar:latest": current["quay.io/foo/bar:latest"]}, opts.resolvedTags)
+
+	opts.reportTagDrifts(previous, current)
+	require.Equal(t, []string{
+		"quay.io/foo/bar:latest: drift detected, new digest sha256:4444444444444444444444444444444444444444444444444444444444444444 mirrored " +
+			"(previously sha256:1111111111111111111111111111111111111111111111111111111111111111)",
+	}, opts.summary.Drifts)
+}
@@ -0,0 +1,83 @@
+package mirror
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// egressAllowlistFile lists the source registries contacted
+// during a run, to configure the outbound firewall rules
+// of the connected host.
+const egressAllowlistFile = "egress-allowlist.txt"
+
+// egressAllowlist returns the deduplicated and sorted registry hosts
+// and repositories the source images of mapping are pulled from.
+// Images read from disk (oci:// or file://) are not listed.
+func egressAllowlist(mapping image.TypedImageMapping) (hosts, repos []string) {
+	hostSet := map[string]struct{}{}
+	repoSet := map[string]struct{}{}
+	for src := range mapping {
+		if src.Type != imagesource.DestinationRegistry {
+			continue
+		}
+		ref := src.Ref.DockerClientDefaults()
+		hostSet[ref.Registry] = struct{}{}
+		repoSet[ref.AsRepository().Exact()] = struct{}{}
+	}
+	for host := range hostSet {
+		hosts = append(hosts, host)
+	}
+	for repo := range repoSet {
+		repos = append(repos, repo)
+	}
+	sort.Strings(hosts)
+	sort.Strings(repos)
+	return hosts, repos
+}
+
+// writeEgressAllowlist writes the source registry hosts, then the
+// source repositories of mapping, one per line.
+func writeEgressAllowlist(w io.Writer, mapping image.TypedImageMapping) error {
+	hosts, repos := egressAllowlist(mapping)
+	if _, err := fmt.Fprintln(w, "# Source registry hosts"); err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		if _, err := fmt.Fprintln(w, host); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintln(w, "# Source repositories"); err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		if _, err := fmt.Fprintln(w, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEgressAllowlistFile writes the egress allowlist of mapping
+// to the workspace.
+func (o *MirrorOptions) writeEgressAllowlistFile(mapping image.TypedImageMapping) error {
+	allowlistPath := filepath.Clean(filepath.Join(o.Dir, egressAllowlistFile))
+	f, err := os.Create(allowlistPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	klog.Infof("Writing egress allowlist to %s", allowlistPath)
+	if err := writeEgressAllowlist(f, mapping); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,46 @@
+package mirror
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestWriteEgressAllowlist(t *testing.T) {
+	typed := func(typ imagesource.DestinationType, registry, namespace, name, tag string) image.TypedImage {
+		return image.TypedImage{
+			TypedImageReference: image.TypedImageReference{
+				Type: typ,
+				Ref:  reference.DockerImageReference{Registry: registry, Namespace: namespace, Name: name, Tag: tag},
+			},
+		}
+	}
+	dest := typed(imagesource.DestinationFile, "", "ns", "dest", "latest")
+
+	mapping := image.TypedImageMapping{
+		typed(imagesource.DestinationRegistry, "quay.io", "openshift-release-dev", "ocp-release", "4.14.1-x86_64"): dest,
+		typed(imagesource.DestinationRegistry, "quay.io", "openshift-release-dev", "ocp-release", "4.14.2-x86_64"): dest,
+		typed(imagesource.DestinationRegistry, "quay.io", "openshift-release-dev", "ocp-v4.0-art-dev", "v1"):       dest,
+		typed(imagesource.DestinationRegistry, "registry.redhat.io", "ubi9", "ubi", "latest"):                      dest,
+		typed(imagesource.DestinationRegistry, "", "", "busybox", "latest"):                                        dest,
+		typed(image.DestinationOCI, "", "local", "catalog", "v1"):                                                  dest,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeEgressAllowlist(&buf, mapping))
+	require.Equal(t, `# Source registry hosts
+docker.io
+quay.io
+registry.redhat.io
+# Source repositories
+docker.io/library/busybox
+quay.io/openshift-release-dev/ocp-release
+quay.io/openshift-release-dev/ocp-v4.0-art-dev
+registry.redhat.io/ubi9/ubi
+`, buf.String())
+}
@@ -0,0 +1,153 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/bundle"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+// NewMirrorOptions returns MirrorOptions with the defaults of the
+// command line flags, for use outside of the oc-mirror command.
+func NewMirrorOptions(ro *cli.RootOptions) *MirrorOptions {
+	return &MirrorOptions{
+		RootOptions:                       ro,
+		MaxPerRegistry:                    6,
+		RebuildCatalogs:                   true,
+		operatorCatalogToFullArtifactPath: map[string]string{},
+	}
+}
+
+// hasConfig returns true if an imageset configuration was provided,
+// either as a file or as a struct.
+func (o *MirrorOptions) hasConfig() bool {
+	return len(o.ConfigPath) > 0 || o.ImageSetConfig != nil
+}
+
+// readConfig returns the imageset configuration set with ImageSetConfig
+// or read from ConfigPath.
+func (o *MirrorOptions) readConfig() (v1alpha2.ImageSetConfiguration, error) {
+	if o.ImageSetConfig != nil {
+		cfg := *o.ImageSetConfig
+		if err := config.ApplyOverlays(&cfg, o.Overlays); err != nil {
+			return cfg, err
+		}
+		if err := config.ApplyProfile(&cfg, o.Profile); err != nil {
+			return cfg, err
+		}
+		config.Complete(&cfg)
+		return cfg, config.Validate(&cfg)
+	}
+	return config.ReadConfigWithOverlays(o.ConfigPath, o.Overlays, o.Profile)
+}
+
+// Plan returns the images the imageset configuration resolves to, mapped to the
+// destination of the options, without mirroring them.
+func (o *MirrorOptions) Plan(ctx context.Context) (image.TypedImageMapping, error) {
+	cfg, err := o.readConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := bundle.MakeWorkspaceDirs(o.Dir); err != nil {
+		return nil, err
+	}
+	_, mapping, err := o.Create(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer o.prevAssociations.Close()
+	if len(o.ToMirror) > 0 {
+		o.namespaceMappings = cfg.Mirror.NamespaceMappings
+		o.applyNamespaceMappings(mapping)
+		mapping.ToRegistry(o.ToMirror, o.UserNamespace)
+		if err := o.applyTagTemplates(mapping); err != nil {
+			return nil, err
+		}
+	}
+	return mapping, nil
+}
+
+/*
+DeleteImageSet removes every image recorded in the metadata of the imageset
+configuration from the destination registry, along with the metadata itself,
+so that the next mirror operation starts from scratch. With DryRun set, the
+images that would be deleted are only listed.
+
+# Arguments
+
+• ctx: a cancellation context
+
+# Returns
+
+• error: non-nil if an error occurs, nil otherwise
+*/
+func (o *MirrorOptions) DeleteImageSet(ctx context.Context) error {
+	if len(o.ToMirror) == 0 {
+		return errors.New("must specify a registry destination")
+	}
+	cfg, err := o.readConfig()
+	if err != nil {
+		return err
+	}
+	if !cfg.StorageConfig.IsSet() {
+		return errors.New("deleting an imageset requires the storageConfig of the imageset configuration")
+	}
+
+	sourceBackend, err := storage.ByConfig(filepath.Join(o.Dir, config.SourceDir), cfg.StorageConfig)
+	if err != nil {
+		return fmt.Errorf("error opening backend: %v", err)
+	}
+	var meta v1alpha2.Metadata
+	prev, err := o.readPastAssociations(ctx, sourceBackend, &meta)
+	if err != nil {
+		if errors.Is(err, storage.ErrMetadataNotExist) {
+			klog.Infof("No metadata detected, nothing to delete")
+			return nil
+		}
+		return err
+	}
+	defer prev.Close()
+
+	targetBackend, err := storage.NewRegistryBackend(&v1alpha2.RegistryConfig{
+		ImageURL: o.newMetadataImage(meta.Uid.String()),
+		SkipTLS:  o.DestPlainHTTP || o.DestSkipTLS,
+	}, o.Dir)
+	if err != nil {
+		return err
+	}
+
+	if err := o.deleteRecordedImages(ctx, prev); err != nil || o.DryRun {
+		return err
+	}
+
+	if err := targetBackend.Cleanup(ctx, config.MetadataBasePath); err != nil {
+		return fmt.Errorf("error deleting metadata from registry %q: %v", o.ToMirror, err)
+	}
+	return sourceBackend.Cleanup(ctx, config.MetadataBasePath)
+}
+
+// deleteRecordedImages deletes every image recorded in prev, the past associations
+// of a metadata, from the destination registry. With DryRun set, the pruning plan
+// is written instead.
+func (o *MirrorOptions) deleteRecordedImages(ctx context.Context, prev *image.AssociationDB) error {
+	if o.DryRun {
+		return o.outputPruneImagePlan(ctx, prev, image.AssociationSet{})
+	}
+	deleter, toRemove, err := o.planImagePruning(ctx, image.AssociationSet{}, prev)
+	if err != nil {
+		return err
+	}
+	if err := o.pruneImages(deleter, toRemove, o.MaxPerRegistry); err != nil {
+		return fmt.Errorf("error deleting images from registry %q: %v", o.ToMirror, err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// ArtifactOptions pulls the OCI artifacts of the imageset configuration.
+// Artifacts are copied manifest by manifest and blob by blob, whatever their
+// media types, so they keep their digests, artifact type and annotations.
+type ArtifactOptions struct {
+	*MirrorOptions
+}
+
+func NewArtifactOptions(mo *MirrorOptions) *ArtifactOptions {
+	opts := &ArtifactOptions{MirrorOptions: mo}
+	return opts
+}
+
+// PullArtifacts resolves the artifacts to their digests and pulls the ones not
+// mirrored by the previous run, recorded in lastRun, to the OCI layout of the
+// artifacts directory of the workspace. The source reference of each artifact
+// is recorded in the ref name annotation of its descriptor in the layout.
+// It returns the artifacts of the run with their digests.
+func (o *ArtifactOptions) PullArtifacts(ctx context.Context, artifacts []v1alpha2.Artifact, lastRun v1alpha2.PastMirror) ([]v1alpha2.ArtifactMetadata, error) {
+	// The layout only holds the artifacts of this run.
+	dir := filepath.Join(o.Dir, config.SourceDir, config.ArtifactsDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if len(artifacts) == 0 {
+		return nil, nil
+	}
+	layoutPath, err := layout.Write(dir, empty.Index)
+	if err != nil {
+		return nil, fmt.Errorf("error creating artifacts layout: %v", err)
+	}
+
+	previous := make(map[string]string, len(lastRun.Artifacts))
+	if !o.ForceFull && !o.IgnoreHistory {
+		for _, artifact := range lastRun.Artifacts {
+			previous[artifact.Name] = artifact.Digest
+		}
+	}
+	insecure := o.SourcePlainHTTP || o.SourceSkipTLS
+	remoteOpts := getRemoteOpts(ctx, insecure)
+
+	// Instead of returning an error, just log it.
+	isSkipErr := func(err error) bool {
+		var terr *transport.Error
+		return o.ContinueOnError || (o.SkipMissing && errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound)
+	}
+
+	var resolved []v1alpha2.ArtifactMetadata
+	for _, artifact := range artifacts {
+		ref, err := name.ParseReference(artifact.Name, getNameOpts(insecure)...)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing artifact %s: %v", artifact.Name, err)
+		}
+		desc, err := remote.Get(ref, remoteOpts...)
+		if err != nil {
+			err = fmt.Errorf("error fetching artifact %s: %w", artifact.Name, err)
+			if !isSkipErr(err) {
+				return nil, err
+			}
+			klog.Warning(err)
+			continue
+		}
+		digest := desc.Digest.String()
+		resolved = append(resolved, v1alpha2.ArtifactMetadata{Name: artifact.Name, Digest: digest})
+		if previous[artifact.Name] == digest {
+			klog.V(1).Infof("Skipping previously mirrored artifact %s", artifact.Name)
+			continue
+		}
+
+		klog.Infof("Pulling artifact %s (%s)", artifact.Name, digest)
+		annotations := layout.WithAnnotations(map[string]string{imgspecv1.AnnotationRefName: ref.Name()})
+		if desc.MediaType.IsIndex() {
+			idx, err := desc.ImageIndex()
+			if err == nil {
+				err = layoutPath.AppendIndex(idx, annotations)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error pulling artifact %s: %v", artifact.Name, err)
+			}
+		} else {
+			img, err := desc.Image()
+			if err == nil {
+				err = layoutPath.AppendImage(img, annotations)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error pulling artifact %s: %v", artifact.Name, err)
+			}
+		}
+		o.pulledArtifacts++
+	}
+	return resolved, nil
+}
+
+// pushArtifacts pushes the artifacts of the OCI layout at dir to the destination
+// registry, under the user namespace like images, and returns their mappings.
+// Artifacts referenced by a tag are pushed with that tag, the others by digest.
+func (o *MirrorOptions) pushArtifacts(ctx context.Context, dir string) (image.TypedImageMapping, error) {
+	mappings := image.TypedImageMapping{}
+	layoutPath, err := layout.FromPath(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return mappings, nil
+		}
+		return mappings, err
+	}
+	idx, err := layoutPath.ImageIndex()
+	if err != nil {
+		return mappings, err
+	}
+	idxManifest, err := idx.IndexManifest()
+	if err != nil {
+		return mappings, err
+	}
+
+	insecure := o.DestPlainHTTP || o.DestSkipTLS
+	remoteOpts := getRemoteOpts(ctx, insecure)
+	for _, desc := range idxManifest.Manifests {
+		source := desc.Annotations[imgspecv1.AnnotationRefName]
+		srcRef, err := image.ParseReference(source)
+		if err != nil {
+			return mappings, fmt.Errorf("error parsing source of artifact %s: %v", desc.Digest, err)
+		}
+		srcRef.Ref.ID = desc.Digest.String()
+		dstRef := srcRef
+		dstRef.Type = imagesource.DestinationRegistry
+		dstRef.Ref.Registry = o.ToMirror
+		dstRef.Ref.Namespace = path.Join(o.UserNamespace, mappedNamespace(o.namespaceMappings, srcRef.Ref), srcRef.Ref.Namespace)
+		mappings.Add(srcRef, dstRef, v1alpha2.TypeGeneric)
+
+		if o.DryRun {
+			continue
+		}
+		pushRef := dstRef.Ref
+		if pushRef.Tag != "" {
+			pushRef.ID = ""
+		}
+		ref, err := name.ParseReference(pushRef.Exact(), getNameOpts(insecure)...)
+		if err != nil {
+			return mappings, err
+		}
+		if err := o.ensureRepositories(ctx, dstRef.Ref); err != nil {
+			return mappings, err
+		}
+		klog.Infof("Pushing artifact %s to %s", source, ref)
+		if desc.MediaType.IsIndex() {
+			artifactIdx, err := idx.ImageIndex(desc.Digest)
+			if err == nil {
+				err = remote.WriteIndex(ref, artifactIdx, remoteOpts...)
+			}
+			if err != nil {
+				return mappings, diagnoseQuotaError(fmt.Errorf("error pushing artifact %s: %v", source, err))
+			}
+			continue
+		}
+		img, err := idx.Image(desc.Digest)
+		if err == nil {
+			err = remote.Write(ref, img, remoteOpts...)
+		}
+		if err != nil {
+			return mappings, diagnoseQuotaError(fmt.Errorf("error pushing artifact %s: %v", source, err))
+		}
+	}
+	return mappings, nil
+}
+
+// publishArtifacts unpacks the artifacts of the imageset to dir
+// and pushes them to the destination registry.
+func (o *MirrorOptions) publishArtifacts(ctx context.Context, dir string, filesInArchive map[string]string) (image.TypedImageMapping, error) {
+	if err := unpack(config.ArtifactsDir, dir, filesInArchive); err != nil {
+		nferr := &ErrArchiveFileNotFound{}
+		if errors.As(err, &nferr) || errors.Is(err, os.ErrNotExist) {
+			klog.V(2).Infof("No artifacts found in archive, skipping")
+			return image.TypedImageMapping{}, nil
+		}
+		return nil, err
+	}
+	mappings, err := o.pushArtifacts(ctx, filepath.Join(dir, config.ArtifactsDir))
+	if err != nil {
+		return mappings, fmt.Errorf("error pushing artifacts: %v", err)
+	}
+	return mappings, nil
+}
@@ -0,0 +1,94 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+func TestArtifacts(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// A Helm chart pushed as an OCI artifact.
+	chart := mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), "application/vnd.cncf.helm.config.v1+json")
+	chart, err = mutate.Append(chart, mutate.Addendum{
+		Layer: static.NewLayer([]byte("chart"), "application/vnd.cncf.helm.chart.content.v1.tar+gzip"),
+	})
+	require.NoError(t, err)
+	chart = mutate.Annotations(chart, map[string]string{"org.opencontainers.image.title": "chart"}).(v1.Image)
+	chartDigest, err := chart.Digest()
+	require.NoError(t, err)
+	src := u.Host + "/charts/chart:1.0.0"
+	srcRef, err := name.ParseReference(src, name.Insecure)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(srcRef, chart))
+
+	o := &MirrorOptions{
+		RootOptions:     &cli.RootOptions{Dir: t.TempDir()},
+		SourcePlainHTTP: true,
+		DestPlainHTTP:   true,
+		ToMirror:        u.Host,
+		UserNamespace:   "mirror",
+	}
+	artifacts := []v1alpha2.Artifact{{Name: src}}
+	resolved, err := NewArtifactOptions(o).PullArtifacts(ctx, artifacts, v1alpha2.PastMirror{})
+	require.NoError(t, err)
+	require.Equal(t, []v1alpha2.ArtifactMetadata{{Name: src, Digest: chartDigest.String()}}, resolved)
+	require.Equal(t, 1, o.pulledArtifacts)
+
+	mappings, err := o.pushArtifacts(ctx, filepath.Join(o.Dir, config.SourceDir, config.ArtifactsDir))
+	require.NoError(t, err)
+	require.Len(t, mappings, 1)
+	for _, dst := range mappings {
+		require.Equal(t, u.Host+"/mirror/charts/chart@"+chartDigest.String(), dst.Ref.Exact())
+		require.Equal(t, "1.0.0", dst.Ref.Tag)
+	}
+
+	// The artifact is copied as is.
+	dstRef, err := name.ParseReference(u.Host+"/mirror/charts/chart:1.0.0", name.Insecure)
+	require.NoError(t, err)
+	desc, err := remote.Get(dstRef)
+	require.NoError(t, err)
+	require.Equal(t, chartDigest, desc.Digest)
+	m, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+	require.NoError(t, err)
+	require.Equal(t, types.MediaType("application/vnd.cncf.helm.config.v1+json"), m.Config.MediaType)
+	require.Equal(t, "chart", m.Annotations["org.opencontainers.image.title"])
+
+	// Artifacts mirrored by the previous run are not pulled again.
+	o.pulledArtifacts = 0
+	resolved, err = NewArtifactOptions(o).PullArtifacts(ctx, artifacts, v1alpha2.PastMirror{Artifacts: resolved})
+	require.NoError(t, err)
+	require.Len(t, resolved, 1)
+	require.Zero(t, o.pulledArtifacts)
+	mappings, err = o.pushArtifacts(ctx, filepath.Join(o.Dir, config.SourceDir, config.ArtifactsDir))
+	require.NoError(t, err)
+	require.Empty(t, mappings)
+
+	// Missing artifacts are skipped with --skip-missing.
+	o.SkipMissing = true
+	resolved, err = NewArtifactOptions(o).PullArtifacts(ctx, []v1alpha2.Artifact{{Name: u.Host + "/charts/missing:1.0.0"}}, v1alpha2.PastMirror{})
+	require.NoError(t, err)
+	require.Empty(t, resolved)
+}
@@ -0,0 +1,60 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+// Names of the association databases of the workspace.
+const (
+	previousAssociationsDB         = "previous"
+	incomingAssociationsDB         = "incoming"
+	incomingMirroredAssociationsDB = "incoming-mirrored"
+	currentAssociationsDB          = "current"
+	prunedAssociationsDB           = "pruned"
+	mirroredAssociationsDB         = "mirrored"
+	interruptedAssociationsDB      = "interrupted"
+	interruptedMirroredDB          = "interrupted-mirrored"
+)
+
+// associationsDBPath returns the path of the association database name of the workspace.
+func (o *MirrorOptions) associationsDBPath(name string) string {
+	return filepath.Join(o.Dir, config.AssociationsDir, name+".db")
+}
+
+// destinationAssociationsDB returns the name of the association database name
+// of the current destination, so that the databases of the destinations of a
+// mirror to mirror run do not replace each other.
+func (o *MirrorOptions) destinationAssociationsDB(name string) string {
+	sum := sha256.Sum256([]byte(o.ToMirror))
+	return name + "-" + hex.EncodeToString(sum[:8])
+}
+
+// loadAssociations loads assocs, the associations recorded in a metadata,
+// into the association database name of the workspace, so that they are
+// iterated from disk instead of held in memory. Closing the returned
+// database removes it.
+func (o *MirrorOptions) loadAssociations(name string, assocs []v1alpha2.Association) (*image.AssociationDB, error) {
+	return image.ConvertToAssociationDB(o.associationsDBPath(name), assocs)
+}
+
+// readPastAssociations reads the metadata of backend into meta, streaming its past
+// associations into the previous association database of the workspace instead of
+// decoding them in memory. Closing the returned database removes it.
+func (o *MirrorOptions) readPastAssociations(ctx context.Context, backend storage.Backend, meta *v1alpha2.Metadata) (*image.AssociationDB, error) {
+	assocs, err := storage.ReadMetadataStream(ctx, backend, meta, config.MetadataBasePath, map[string]string{
+		image.PastAssociationsField:     o.associationsDBPath(previousAssociationsDB),
+		image.MirroredAssociationsField: "",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assocs[image.PastAssociationsField], nil
+}
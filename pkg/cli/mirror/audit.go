@@ -0,0 +1,108 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+// auditRecord is an entry of the audit log of the workspace, a manifest
+// pushed, a tag updated, a manifest deleted or a manifest not pushed again.
+type auditRecord struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Host    string    `json:"host"`
+	Command string    `json:"command"`
+	// Action is push, tag, delete or skip.
+	Action string `json:"action"`
+	// Image is the pull spec of the manifest changed, by tag or digest.
+	Image string `json:"image"`
+	// DigestBefore is the digest the reference resolved to before the change.
+	DigestBefore string `json:"digestBefore,omitempty"`
+	// DigestAfter is the digest the reference resolves to after a push.
+	DigestAfter string `json:"digestAfter,omitempty"`
+}
+
+// auditLog appends the manifest changes of a run to the audit log of the workspace.
+type auditLog struct {
+	mu      sync.Mutex
+	f       *os.File
+	user    string
+	host    string
+	command string
+}
+
+// auditLogPath returns the path of the audit log of the workspace.
+func (o *MirrorOptions) auditLogPath() string {
+	return filepath.Join(o.Dir, config.AuditLogFile)
+}
+
+// startAuditLog records the manifests pushed and deleted by the command in the
+// audit log of the workspace until the returned function is called.
+func (o *MirrorOptions) startAuditLog(command string) (func(), error) {
+	if err := os.MkdirAll(o.Dir, 0750); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(o.auditLogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log: %v", err)
+	}
+	log := &auditLog{f: f, user: currentUser(), command: command}
+	log.host, _ = os.Hostname()
+	network.SetManifestAuditor(log.record)
+	return func() {
+		network.SetManifestAuditor(nil)
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		if err := log.f.Close(); err != nil {
+			klog.Warningf("error closing audit log: %v", err)
+		}
+	}, nil
+}
+
+// currentUser returns the name of the user running oc-mirror.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return fmt.Sprint(os.Getuid())
+}
+
+// record appends change to the audit log. Each record is written
+// with a single write, so records are never interleaved.
+func (l *auditLog) record(change network.ManifestChange) {
+	// Tags cannot contain colons, digests always do.
+	sep := ":"
+	if strings.Contains(change.Reference, ":") {
+		sep = "@"
+	}
+	data, err := json.Marshal(auditRecord{
+		Time:         time.Now().UTC(),
+		User:         l.user,
+		Host:         l.host,
+		Command:      l.command,
+		Action:       change.Action,
+		Image:        change.Registry + "/" + change.Repository + sep + change.Reference,
+		DigestBefore: change.DigestBefore,
+		DigestAfter:  change.DigestAfter,
+	})
+	if err != nil {
+		klog.Warningf("error recording %s of %s in the audit log: %v", change.Action, change.Reference, err)
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.Write(append(data, '\n')); err != nil {
+		klog.Warningf("error recording %s of %s in the audit log: %v", change.Action, change.Reference, err)
+	}
+}
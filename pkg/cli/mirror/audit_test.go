@@ -0,0 +1,67 @@
+package mirror
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+func TestAuditLog(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	opt := remote.WithTransport(network.AuditManifests(http.DefaultTransport))
+
+	tag, err := name.NewTag(u.Host + "/ns/repo:v1")
+	require.NoError(t, err)
+	img, err := random.Image(100, 1)
+	require.NoError(t, err)
+	d, err := img.Digest()
+	require.NoError(t, err)
+
+	o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+
+	// Records are appended to the log across runs.
+	stop, err := o.startAuditLog("mirror")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag, img, opt))
+	stop()
+	stop, err = o.startAuditLog("delete")
+	require.NoError(t, err)
+	require.NoError(t, remote.Delete(tag.Context().Digest(d.String()), opt))
+	stop()
+	// Changes after the runs are not recorded.
+	require.NoError(t, remote.Write(tag, img, opt))
+
+	f, err := os.Open(o.auditLogPath())
+	require.NoError(t, err)
+	defer f.Close()
+	var records []auditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r auditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		require.False(t, r.Time.IsZero())
+		require.NotEmpty(t, r.User)
+		records = append(records, auditRecord{Command: r.Command, Action: r.Action, Image: r.Image, DigestBefore: r.DigestBefore, DigestAfter: r.DigestAfter})
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []auditRecord{
+		{Command: "mirror", Action: "tag", Image: u.Host + "/ns/repo:v1", DigestAfter: d.String()},
+		{Command: "delete", Action: "delete", Image: u.Host + "/ns/repo@" + d.String(), DigestBefore: d.String()},
+	}, records)
+}
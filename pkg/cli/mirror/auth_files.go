@@ -0,0 +1,132 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli/mirror/list"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// mergedAuthDir is the directory of the workspace holding
+// the merged auth file, named as a docker config file.
+const mergedAuthDir = "auth"
+
+// authFiles returns the auth files merged over the default auth file:
+// the --authfile files, then the pull secrets of the operator catalogs
+// of cfg, in order of precedence.
+func (o *MirrorOptions) authFiles(cfg v1alpha2.ImageSetConfiguration) []image.AuthFile {
+	var files []image.AuthFile
+	for _, path := range o.AuthFiles {
+		files = append(files, image.AuthFile{Path: path, Source: "--authfile " + path})
+	}
+	for _, ctlg := range cfg.Mirror.Operators {
+		if ctlg.PullSecret == "" {
+			continue
+		}
+		files = append(files, image.AuthFile{
+			Path:   ctlg.PullSecret,
+			Source: fmt.Sprintf("pull secret %s of catalog %s", ctlg.PullSecret, ctlg.Catalog),
+		})
+	}
+	return files
+}
+
+// registryHosts returns the registries the images of cfg and the
+// destinations of the run are read from or written to, as known before
+// the run, for which the credential providers are asked for credentials.
+func (o *MirrorOptions) registryHosts(cfg v1alpha2.ImageSetConfiguration) []string {
+	names := []string{}
+	for _, ctlg := range cfg.Mirror.Operators {
+		if !ctlg.IsFBCOCI() && !ctlg.IsFBCDir() {
+			names = append(names, ctlg.Catalog)
+		}
+	}
+	for _, img := range cfg.Mirror.AdditionalImages {
+		names = append(names, img.Name)
+	}
+	for _, artifact := range cfg.Mirror.Artifacts {
+		names = append(names, artifact.Name)
+	}
+	if len(cfg.Mirror.Platform.Channels) != 0 {
+		names = append(names, list.OCPReleaseRepo)
+	}
+	var hosts []string
+	for _, name := range names {
+		if ref, err := reference.Parse(name); err == nil {
+			hosts = append(hosts, ref.DockerClientDefaults().Registry)
+		}
+	}
+	for _, d := range o.mirrorDestinations() {
+		if d.Registry != "" {
+			hosts = append(hosts, d.Registry)
+		}
+	}
+	return hosts
+}
+
+// configureAuth merges the default auth file, the --authfile files, the
+// pull secrets of the catalogs of cfg and the credentials of the credential
+// providers for the registries of cfg into the auth file of the workspace,
+// and warns of the registries whose credentials conflict. The merged auth
+// file is read by the registry clients and the containers/image system
+// contexts of the run; images of other registries, such as the related
+// images of a catalog, only get the credentials of the providers through
+// the registry clients. The default auth file is read as is when no other
+// credentials are set. The merged auth file holds the credentials in plain
+// text, it is removed by removeMergedAuth.
+func (o *MirrorOptions) configureAuth(ctx context.Context, cfg v1alpha2.ImageSetConfiguration) error {
+	files := o.authFiles(cfg)
+	if len(files) == 0 && len(o.CredentialProviders) == 0 {
+		image.SetAuthFile("")
+		o.authConfigDir = ""
+		return nil
+	}
+	defaultFile, err := image.DefaultAuthFile()
+	if err != nil {
+		return err
+	}
+	if defaultFile != "" {
+		files = append([]image.AuthFile{{Path: defaultFile, Source: "default auth file " + defaultFile}}, files...)
+	}
+
+	auths, conflicts, err := image.MergeAuthFiles(files)
+	if err != nil {
+		return err
+	}
+	providerConflicts, err := image.MergeProviderCredentials(ctx, auths, o.registryHosts(cfg))
+	if err != nil {
+		return err
+	}
+	for _, c := range append(conflicts, providerConflicts...) {
+		klog.Warningf("%s", c)
+	}
+	dir := filepath.Join(o.Dir, mergedAuthDir)
+	path := filepath.Join(dir, "config.json")
+	if err := image.WriteAuthFile(path, auths); err != nil {
+		return fmt.Errorf("error writing merged auth file: %v", err)
+	}
+	klog.V(1).Infof("Merged the credentials of %d registries from %d auth files into %s", len(auths), len(files), path)
+	image.SetAuthFile(path)
+	o.authConfigDir = dir
+	return nil
+}
+
+// removeMergedAuth removes the merged auth file of the workspace
+// written by configureAuth, if any, once the run ends.
+func (o *MirrorOptions) removeMergedAuth() {
+	if o.authConfigDir == "" {
+		return
+	}
+	if err := os.RemoveAll(o.authConfigDir); err != nil {
+		klog.Warningf("error removing the merged auth file: %v", err)
+	}
+	image.SetAuthFile("")
+	o.authConfigDir = ""
+}
@@ -0,0 +1,154 @@
+package mirror
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestConfigureAuth(t *testing.T) {
+	t.Cleanup(func() { image.SetAuthFile("") })
+	t.Setenv(image.RegistryAuthFileEnv, "")
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	writeAuth := func(creds string) string {
+		path := filepath.Join(t.TempDir(), "auth.json")
+		data := `{"auths": {"quay.io": {"auth": "` + base64.StdEncoding.EncodeToString([]byte(creds)) + `"}}}`
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		return path
+	}
+	runAuth := writeAuth("run:run-password")
+	catalogAuth := writeAuth("catalog:catalog-password")
+
+	opts := &MirrorOptions{
+		RootOptions: &cli.RootOptions{Dir: t.TempDir()},
+		AuthFiles:   []string{runAuth},
+	}
+	cfg := v1alpha2.ImageSetConfiguration{}
+	cfg.Mirror.Operators = []v1alpha2.Operator{
+		{Catalog: "quay.io/team/catalog:v1", PullSecret: catalogAuth},
+		{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14"},
+	}
+	require.Equal(t, []image.AuthFile{
+		{Path: runAuth, Source: "--authfile " + runAuth},
+		{Path: catalogAuth, Source: "pull secret " + catalogAuth + " of catalog quay.io/team/catalog:v1"},
+	}, opts.authFiles(cfg))
+
+	require.NoError(t, opts.configureAuth(context.Background(), cfg))
+	require.Equal(t, filepath.Join(opts.Dir, mergedAuthDir), opts.authConfigDir)
+	require.FileExists(t, filepath.Join(opts.authConfigDir, "config.json"))
+
+	repo, err := name.NewRepository("quay.io/team/catalog")
+	require.NoError(t, err)
+	auth, err := image.Keychain().Resolve(repo)
+	require.NoError(t, err)
+	cfgAuth, err := auth.Authorization()
+	require.NoError(t, err)
+	require.Equal(t, &authn.AuthConfig{Username: "catalog", Password: "catalog-password"}, cfgAuth)
+
+	// The plain text credentials do not outlive the run.
+	authDir := opts.authConfigDir
+	opts.removeMergedAuth()
+	require.NoDirExists(t, authDir)
+	require.Empty(t, opts.authConfigDir)
+
+	t.Run("Valid/NoAuthFiles", func(t *testing.T) {
+		opts := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+		require.NoError(t, opts.configureAuth(context.Background(), v1alpha2.ImageSetConfiguration{}))
+		require.Empty(t, opts.authConfigDir)
+		require.NoDirExists(t, filepath.Join(opts.Dir, mergedAuthDir))
+	})
+	t.Run("Invalid/MissingPullSecret", func(t *testing.T) {
+		cfg := v1alpha2.ImageSetConfiguration{}
+		cfg.Mirror.Operators = []v1alpha2.Operator{{Catalog: "quay.io/team/catalog:v1", PullSecret: filepath.Join(t.TempDir(), "missing.json")}}
+		opts := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+		err := opts.configureAuth(context.Background(), cfg)
+		require.ErrorContains(t, err, "error reading auth file")
+		require.ErrorContains(t, err, "pull secret")
+	})
+}
+
+func TestConfigureAuthPrivateCatalog(t *testing.T) {
+	t.Cleanup(func() {
+		image.SetAuthFile("")
+		image.SetCredentialProviders()
+	})
+	t.Setenv(image.RegistryAuthFileEnv, "")
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	// A registry only serving requests with the credentials of the catalog.
+	reg := registry.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "catalog" || password != "catalog-password" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	catalog := u.Host + "/team/catalog:v1"
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(catalog, name.Insecure)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img, remote.WithAuth(&authn.Basic{Username: "catalog", Password: "catalog-password"})))
+	digest, err := img.Digest()
+	require.NoError(t, err)
+
+	resolve := func() (string, error) {
+		return image.ResolveToPin(context.Background(), image.NewSystemContext(true, ""), catalog)
+	}
+	cfg := v1alpha2.ImageSetConfiguration{}
+	cfg.Mirror.Operators = []v1alpha2.Operator{{Catalog: catalog}}
+
+	t.Run("Invalid/NoCredentials", func(t *testing.T) {
+		opts := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+		require.NoError(t, opts.configureAuth(context.Background(), cfg))
+		_, err := resolve()
+		require.Error(t, err)
+	})
+	t.Run("Valid/PullSecret", func(t *testing.T) {
+		pullSecret := filepath.Join(t.TempDir(), "pull-secret.json")
+		data := `{"auths": {"` + u.Host + `": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("catalog:catalog-password")) + `"}}}`
+		require.NoError(t, os.WriteFile(pullSecret, []byte(data), 0600))
+		cfg := v1alpha2.ImageSetConfiguration{}
+		cfg.Mirror.Operators = []v1alpha2.Operator{{Catalog: catalog, PullSecret: pullSecret}}
+		opts := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+		require.NoError(t, opts.configureAuth(context.Background(), cfg))
+		defer opts.removeMergedAuth()
+		pin, err := resolve()
+		require.NoError(t, err)
+		require.Equal(t, u.Host+"/team/catalog@"+digest.String(), pin)
+	})
+	t.Run("Valid/CredentialProvider", func(t *testing.T) {
+		t.Setenv(image.EnvCredentialKey(u.Host), "catalog:catalog-password")
+		image.SetCredentialProviders(image.NewEnvCredentialProvider())
+		defer image.SetCredentialProviders()
+		opts := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}, CredentialProviders: []string{"env"}}
+		require.NoError(t, opts.configureAuth(context.Background(), cfg))
+		defer opts.removeMergedAuth()
+		pin, err := resolve()
+		require.NoError(t, err)
+		require.Equal(t, u.Host+"/team/catalog@"+digest.String(), pin)
+	})
+}
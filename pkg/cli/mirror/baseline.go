@@ -0,0 +1,115 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+
+	imgreference "github.com/openshift/library-go/pkg/image/reference"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/operator"
+)
+
+// parseBaselineCatalogs parses the --baseline-catalog references, which must be
+// pinned by digest, and returns them keyed by catalog repository.
+func parseBaselineCatalogs(refs []string) (map[string]imgreference.DockerImageReference, error) {
+	baselines := make(map[string]imgreference.DockerImageReference, len(refs))
+	for _, ref := range refs {
+		baseline, err := imgreference.Parse(ref)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --baseline-catalog %q: %v", ref, err)
+		}
+		if baseline.ID == "" {
+			return nil, fmt.Errorf("invalid --baseline-catalog %q: the catalog image must be pinned by digest", ref)
+		}
+		repo := baseline.AsRepository().Exact()
+		if _, found := baselines[repo]; found {
+			return nil, fmt.Errorf("--baseline-catalog is specified more than once for catalog %s", repo)
+		}
+		baselines[repo] = baseline
+	}
+	return baselines, nil
+}
+
+// baselineCatalog returns the baseline catalog image of the catalog ctlgRef, if any.
+func (o *MirrorOptions) baselineCatalog(ctlgRef imgreference.DockerImageReference) (imgreference.DockerImageReference, bool) {
+	baseline, found := o.baselineCatalogs[ctlgRef.AsRepository().Exact()]
+	return baseline, found
+}
+
+// baselineImages renders the baseline catalog image with the include config of
+// ctlg and returns the bundle and related images it references, i.e. the images
+// a mirror of ctlg pinned to the baseline would have mirrored. When the include
+// config does not apply to the baseline, e.g. it selects a package or bundle the
+// baseline does not have, no image is returned so that everything is mirrored.
+func (o *OperatorOptions) baselineImages(ctx context.Context, reg *containerdregistry.Registry, ctlg v1alpha2.Operator, baselineRef string) (map[string]struct{}, error) {
+	baselineCtlg := ctlg
+	baselineCtlg.Catalog = baselineRef
+	dc, _, err := o.renderDCFull(ctx, reg, baselineCtlg)
+	if err == nil {
+		err = operator.SelectBundles(dc, ctlg.IncludeConfig)
+	}
+	if err != nil {
+		klog.Warningf("catalog %s: mirroring all images, baseline catalog %s cannot be rendered with its include config: %v", ctlg.Catalog, baselineRef, err)
+		return nil, nil
+	}
+	relatedImages, err := getRelatedImages(*dc)
+	if err != nil {
+		return nil, err
+	}
+	images := make(map[string]struct{}, len(relatedImages))
+	for _, ri := range relatedImages {
+		ref, err := imgreference.Parse(ri.Image)
+		if err != nil {
+			klog.V(2).Infof("ignoring image %q of baseline catalog %s: %v", ri.Image, baselineRef, err)
+			continue
+		}
+		images[baselineKey(ref)] = struct{}{}
+	}
+	return images, nil
+}
+
+// withoutBaselineImages removes from mappings the images already referenced by
+// the baseline catalog, so that only the bundles and related images that changed
+// since are mirrored. It also returns the source references of the removed images.
+func withoutBaselineImages(mappings image.TypedImageMapping, baseline map[string]struct{}) (image.TypedImageMapping, []string) {
+	result := make(image.TypedImageMapping, len(mappings))
+	var excluded []string
+	for src, dst := range mappings {
+		if _, found := baseline[baselineKey(src.Ref)]; found {
+			excluded = append(excluded, src.Ref.String())
+			continue
+		}
+		result[src] = dst
+	}
+	return result, excluded
+}
+
+// previouslyMirroredBaselineImages returns the images excluded by a baseline
+// catalog that prev has associations for. These images are not mirrored again
+// but are still in the mirror registry, so their history must not be pruned.
+func (o *MirrorOptions) previouslyMirroredBaselineImages(prev *image.AssociationDB) ([]string, error) {
+	var keep []string
+	for _, ref := range o.baselineExcluded {
+		found, err := prev.SetContainsKey(ref)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			keep = append(keep, ref)
+		}
+	}
+	return keep, nil
+}
+
+// baselineKey identifies an image by digest when it is pinned, by its exact
+// reference otherwise.
+func baselineKey(ref imgreference.DockerImageReference) string {
+	if ref.ID != "" {
+		return ref.AsRepository().Exact() + "@" + ref.ID
+	}
+	return ref.Exact()
+}
@@ -0,0 +1,162 @@
+package mirror
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+const (
+	baselineDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	bundleDigest   = "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+)
+
+func TestParseBaselineCatalogs(t *testing.T) {
+	type spec struct {
+		name     string
+		refs     []string
+		expRepos []string
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name:     "Valid/Digest",
+			refs:     []string{"registry.redhat.io/redhat/redhat-operator-index@" + baselineDigest},
+			expRepos: []string{"registry.redhat.io/redhat/redhat-operator-index"},
+		},
+		{
+			name:     "Invalid/Tag",
+			refs:     []string{"registry.redhat.io/redhat/redhat-operator-index:v4.14"},
+			expError: `invalid --baseline-catalog "registry.redhat.io/redhat/redhat-operator-index:v4.14": the catalog image must be pinned by digest`,
+		},
+		{
+			name: "Invalid/Duplicate",
+			refs: []string{
+				"registry.redhat.io/redhat/redhat-operator-index@" + baselineDigest,
+				"registry.redhat.io/redhat/redhat-operator-index@" + bundleDigest,
+			},
+			expError: "--baseline-catalog is specified more than once for catalog registry.redhat.io/redhat/redhat-operator-index",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			baselines, err := parseBaselineCatalogs(c.refs)
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			for _, repo := range c.expRepos {
+				require.Contains(t, baselines, repo)
+			}
+		})
+	}
+}
+
+func TestWithoutBaselineImages(t *testing.T) {
+	unchanged, err := image.ParseTypedImage("quay.io/example/bundle@"+baselineDigest, v1alpha2.TypeOperatorBundle)
+	require.NoError(t, err)
+	changed, err := image.ParseTypedImage("quay.io/example/bundle@"+bundleDigest, v1alpha2.TypeOperatorBundle)
+	require.NoError(t, err)
+	dst, err := image.ParseTypedImage("file://example/bundle", v1alpha2.TypeOperatorBundle)
+	require.NoError(t, err)
+
+	mappings := image.TypedImageMapping{unchanged: dst, changed: dst}
+	baseline := map[string]struct{}{baselineKey(unchanged.Ref): {}}
+
+	result, excluded := withoutBaselineImages(mappings, baseline)
+	require.Equal(t, image.TypedImageMapping{changed: dst}, result)
+	require.Equal(t, []string{unchanged.Ref.String()}, excluded)
+}
+
+func TestBaselineImages(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.yaml"), []byte(baselineFBC), 0600))
+
+	type spec struct {
+		name      string
+		ic        v1alpha2.IncludeConfig
+		expImages []string
+	}
+
+	cases := []spec{
+		{
+			name:      "Valid/Unfiltered",
+			expImages: []string{"quay.io/example/foo-bundle:v0.1.0", "quay.io/example/bar-bundle:v0.1.0"},
+		},
+		{
+			name:      "Valid/IncludedPackage",
+			ic:        v1alpha2.IncludeConfig{Packages: []v1alpha2.IncludePackage{{Name: "foo"}}},
+			expImages: []string{"quay.io/example/foo-bundle:v0.1.0"},
+		},
+		{
+			name: "Valid/PackageNotInBaseline",
+			ic:   v1alpha2.IncludeConfig{Packages: []v1alpha2.IncludePackage{{Name: "baz"}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			o := &OperatorOptions{MirrorOptions: &MirrorOptions{}, Logger: logrus.NewEntry(logrus.New())}
+			ctlg := v1alpha2.Operator{Catalog: "quay.io/example/index:latest", IncludeConfig: c.ic}
+			images, err := o.baselineImages(context.Background(), nil, ctlg, dir)
+			require.NoError(t, err)
+			var keys []string
+			for key := range images {
+				keys = append(keys, key)
+			}
+			require.ElementsMatch(t, c.expImages, keys)
+		})
+	}
+}
+
+const baselineFBC = `---
+schema: olm.package
+name: foo
+defaultChannel: stable
+---
+schema: olm.channel
+package: foo
+name: stable
+entries:
+- name: foo.v0.1.0
+---
+schema: olm.bundle
+name: foo.v0.1.0
+package: foo
+image: quay.io/example/foo-bundle:v0.1.0
+properties:
+- type: olm.package
+  value:
+    packageName: foo
+    version: 0.1.0
+---
+schema: olm.package
+name: bar
+defaultChannel: stable
+---
+schema: olm.channel
+package: bar
+name: stable
+entries:
+- name: bar.v0.1.0
+---
+schema: olm.bundle
+name: bar.v0.1.0
+package: bar
+image: quay.io/example/bar-bundle:v0.1.0
+properties:
+- type: olm.package
+  value:
+    packageName: bar
+    version: 0.1.0
+`
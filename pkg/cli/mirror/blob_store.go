@@ -0,0 +1,173 @@
+package mirror
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// blobStore holds a single copy of each blob unpacked from the imageset or
+// fetched from the mirror registry during publish. The blobs are placed in the
+// unpack directory of each image using them with a hard link, or a reflink
+// where hard links are not supported, so that blobs shared by many images do
+// not multiply disk usage. A copy is only made when neither is supported.
+// Each blob is removed from the store once the last image using it is
+// published, so that the store only holds the blobs of the images left.
+type blobStore struct {
+	dir string
+	// refs counts the images left to publish using each blob, by blob path.
+	refs map[string]int
+}
+
+// newBlobStore creates an empty blobStore in dir.
+func newBlobStore(dir string) (*blobStore, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("error creating blob store: %v", err)
+	}
+	return &blobStore{dir: dir, refs: map[string]int{}}, nil
+}
+
+// path returns the path of the blob at blobPath, blobs/<digest>, in the store.
+func (s *blobStore) path(blobPath string) string {
+	return filepath.Join(s.dir, blobPath)
+}
+
+// has returns whether the store holds the blob at blobPath.
+func (s *blobStore) has(blobPath string) bool {
+	_, err := os.Stat(s.path(blobPath))
+	return err == nil
+}
+
+// unpack places the blob at blobPath in the archives of filesInArchive
+// at dstPath, unpacking it to the store the first time.
+func (s *blobStore) unpack(blobPath, dstPath string, filesInArchive map[string]string) error {
+	if !s.has(blobPath) {
+		if err := unpack(blobPath, s.dir, filesInArchive); err != nil {
+			return err
+		}
+	}
+	return placeBlob(s.path(blobPath), dstPath)
+}
+
+// fetch places the blob at blobPath read from src at each of dstPaths,
+// writing it to the store the first time.
+func (s *blobStore) fetch(src io.Reader, blobPath string, dstPaths []string) error {
+	if !s.has(blobPath) {
+		if err := s.write(src, blobPath); err != nil {
+			return err
+		}
+	}
+	for _, dstPath := range dstPaths {
+		if err := placeBlob(s.path(blobPath), dstPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write writes the blob at blobPath in the store from src,
+// so that a partially written blob is never found in the store.
+func (s *blobStore) write(src io.Reader, blobPath string) error {
+	storePath := s.path(blobPath)
+	if err := os.MkdirAll(filepath.Dir(storePath), 0750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(storePath), ".blob-*")
+	if err != nil {
+		return fmt.Errorf("error creating blob file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error copying blob %q: %v", filepath.Base(storePath), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), storePath)
+}
+
+// retain records an image left to publish using each blob of blobPaths.
+func (s *blobStore) retain(blobPaths []string) {
+	for _, blobPath := range blobPaths {
+		s.refs[blobPath]++
+	}
+}
+
+// release records that an image using each blob of blobPaths is published,
+// and removes the blobs of the store no image left to publish uses. The
+// blobs placed with hard links or reflinks stay at their locations.
+func (s *blobStore) release(blobPaths []string) error {
+	for _, blobPath := range blobPaths {
+		if s.refs[blobPath] > 1 {
+			s.refs[blobPath]--
+			continue
+		}
+		delete(s.refs, blobPath)
+		if err := os.Remove(s.path(blobPath)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error evicting blob %q: %v", filepath.Base(blobPath), err)
+		}
+	}
+	return nil
+}
+
+// imageBlobPaths returns the path, blobs/<digest>, of each layer of
+// the associations of an image, once for the layers shared by its manifests.
+func imageBlobPaths(assocs []v1alpha2.Association) []string {
+	seen := map[string]bool{}
+	var blobPaths []string
+	for _, assoc := range assocs {
+		for _, layerDigest := range assoc.LayerDigests {
+			if !seen[layerDigest] {
+				seen[layerDigest] = true
+				blobPaths = append(blobPaths, filepath.Join("blobs", layerDigest))
+			}
+		}
+	}
+	return blobPaths
+}
+
+// Close removes the store. The blobs placed with hard links or
+// reflinks stay at their locations.
+func (s *blobStore) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+// placeBlob places the blob at storePath at dstPath with a hard link,
+// else a reflink, else a copy.
+func placeBlob(storePath, dstPath string) error {
+	if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
+		return err
+	}
+	// A blob written at dstPath before is replaced rather than written
+	// to, which would modify the blob of the store it may be linked to.
+	if err := os.Remove(dstPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	err := os.Link(storePath, dstPath)
+	if err == nil {
+		klog.V(4).Infof("linked blob to %s", dstPath)
+		return nil
+	}
+	klog.V(4).Infof("cannot hard link blob to %s: %v", dstPath, err)
+	if err := reflinkBlob(storePath, dstPath); err == nil {
+		klog.V(4).Infof("reflinked blob to %s", dstPath)
+		return nil
+	}
+	src, err := os.Open(storePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return copyBlobFile(src, dstPath)
+}
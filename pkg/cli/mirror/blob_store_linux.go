@@ -0,0 +1,30 @@
+//go:build linux
+
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// reflinkBlob clones the blob at storePath to dstPath, sharing its
+// extents on file systems supporting it, such as XFS and Btrfs.
+func reflinkBlob(storePath, dstPath string) error {
+	src, err := os.Open(storePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(filepath.Clean(dstPath), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := unix.IoctlFileClone(int(dst.Fd()), int(src.Fd())); err != nil {
+		dst.Close()
+		os.Remove(dstPath)
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package mirror
+
+import "errors"
+
+// reflinkBlob is only supported on Linux, placeBlob copies
+// the blobs it cannot hard link on the other platforms.
+func reflinkBlob(storePath, dstPath string) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,82 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestBlobStoreFetch(t *testing.T) {
+	const blobPath = "blobs/sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	tmp := t.TempDir()
+	store, err := newBlobStore(filepath.Join(tmp, "blob-store"))
+	require.NoError(t, err)
+
+	dstPaths := []string{
+		filepath.Join(tmp, "images.1", "v2", "foo", "bar", blobPath),
+		filepath.Join(tmp, "images.1", "v2", "foo", "baz", blobPath),
+	}
+	// A blob left at a destination is replaced.
+	require.NoError(t, os.MkdirAll(filepath.Dir(dstPaths[1]), 0750))
+	require.NoError(t, os.WriteFile(dstPaths[1], []byte("stale layer content"), 0600))
+
+	require.NoError(t, store.fetch(strings.NewReader("layer"), blobPath, dstPaths))
+	stored, err := os.Stat(store.path(blobPath))
+	require.NoError(t, err)
+	for _, dstPath := range dstPaths {
+		data, err := os.ReadFile(dstPath)
+		require.NoError(t, err)
+		require.Equal(t, "layer", string(data))
+		placed, err := os.Stat(dstPath)
+		require.NoError(t, err)
+		require.True(t, os.SameFile(stored, placed), "blob %s is not linked to the store", dstPath)
+	}
+
+	// The stored blob is placed without reading it again.
+	other := filepath.Join(tmp, "images.2", "v2", "foo", "bar", blobPath)
+	require.NoError(t, store.fetch(strings.NewReader(""), blobPath, []string{other}))
+	data, err := os.ReadFile(other)
+	require.NoError(t, err)
+	require.Equal(t, "layer", string(data))
+
+	// The placed blobs outlive the store.
+	require.NoError(t, store.Close())
+	require.NoDirExists(t, filepath.Join(tmp, "blob-store"))
+	require.FileExists(t, dstPaths[0])
+}
+
+func TestBlobStoreRelease(t *testing.T) {
+	const shared = "blobs/sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	const single = "blobs/sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	tmp := t.TempDir()
+	store, err := newBlobStore(filepath.Join(tmp, "blob-store"))
+	require.NoError(t, err)
+
+	first := []v1alpha2.Association{
+		{Name: "quay.io/foo/bar:v1", LayerDigests: []string{filepath.Base(shared), filepath.Base(single)}},
+		{Name: "sha256:3333", LayerDigests: []string{filepath.Base(shared)}},
+	}
+	second := []v1alpha2.Association{{Name: "quay.io/foo/baz:v1", LayerDigests: []string{filepath.Base(shared)}}}
+	require.Equal(t, []string{shared, single}, imageBlobPaths(first))
+	store.retain(imageBlobPaths(first))
+	store.retain(imageBlobPaths(second))
+
+	dst := filepath.Join(tmp, "images.1", "v2", "foo", "bar")
+	require.NoError(t, store.fetch(strings.NewReader("shared"), shared, []string{filepath.Join(dst, shared)}))
+	require.NoError(t, store.fetch(strings.NewReader("single"), single, []string{filepath.Join(dst, single)}))
+
+	// The blobs of the first image are evicted unless the second image uses them.
+	require.NoError(t, store.release(imageBlobPaths(first)))
+	require.True(t, store.has(shared))
+	require.False(t, store.has(single))
+	require.FileExists(t, filepath.Join(dst, single))
+
+	require.NoError(t, store.release(imageBlobPaths(second)))
+	require.False(t, store.has(shared))
+	require.Empty(t, store.refs)
+}
@@ -1,12 +1,26 @@
 package mirror
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"k8s.io/klog/v2"
 
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
 )
 
+// blockedImagesFile is the report of the images blocked
+// by the last run, written to the workspace.
+const blockedImagesFile = "blocked-images.json"
+
 type ErrBlocked struct {
 	image string
 }
@@ -15,18 +29,188 @@ func (e ErrBlocked) Error() string {
 	return fmt.Sprintf("image %s blocked", e.image)
 }
 
+// blockedMatcher matches the images of a blocked image of the configuration.
+type blockedMatcher struct {
+	blocked v1alpha2.BlockedImage
+	name    *regexp.Regexp
+	digests map[string]bool
+}
+
+// newBlockedMatchers returns the matchers of the blocked images.
+func newBlockedMatchers(blocked []v1alpha2.BlockedImage) ([]blockedMatcher, error) {
+	matchers := make([]blockedMatcher, 0, len(blocked))
+	for _, b := range blocked {
+		m := blockedMatcher{blocked: b}
+		if b.Name != "" {
+			matcher, err := regexp.Compile(b.Name)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing blocked image regular expression %s: %v", b.Name, err)
+			}
+			m.name = matcher
+		}
+		if len(b.Digests) != 0 {
+			m.digests = make(map[string]bool, len(b.Digests))
+			for _, d := range b.Digests {
+				m.digests[d] = true
+			}
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// matches returns whether ref matches every field of the blocked image.
+// Invalid glob patterns do not match, they are rejected by config.Validate.
+func (m blockedMatcher) matches(ref reference.DockerImageReference) bool {
+	if m.name != nil && !m.name.MatchString(ref.Exact()) {
+		return false
+	}
+	// The default registry and namespace are set, not the default tag.
+	defaults := ref.DockerClientDefaults()
+	globs := []struct{ pattern, value string }{
+		{m.blocked.Registry, defaults.Registry},
+		{m.blocked.Namespace, defaults.Namespace},
+		{m.blocked.Repository, defaults.Name},
+	}
+	for _, g := range globs {
+		if g.pattern == "" {
+			continue
+		}
+		if ok, _ := path.Match(g.pattern, g.value); !ok {
+			return false
+		}
+	}
+	if len(m.blocked.Tags) != 0 && !matchesAnyGlob(m.blocked.Tags, ref.Tag) {
+		return false
+	}
+	if m.digests != nil && !m.digests[ref.ID] {
+		return false
+	}
+	return true
+}
+
+// String describes the blocked image in the logs and the report.
+func (m blockedMatcher) String() string {
+	var fields []string
+	if m.blocked.Name != "" {
+		fields = append(fields, "name="+m.blocked.Name)
+	}
+	if m.blocked.Registry != "" {
+		fields = append(fields, "registry="+m.blocked.Registry)
+	}
+	if m.blocked.Namespace != "" {
+		fields = append(fields, "namespace="+m.blocked.Namespace)
+	}
+	if m.blocked.Repository != "" {
+		fields = append(fields, "repository="+m.blocked.Repository)
+	}
+	if len(m.blocked.Tags) != 0 {
+		fields = append(fields, "tags="+strings.Join(m.blocked.Tags, ","))
+	}
+	if len(m.blocked.Digests) != 0 {
+		fields = append(fields, "digests="+strings.Join(m.blocked.Digests, ","))
+	}
+	return strings.Join(fields, " ")
+}
+
+func matchesAnyGlob(patterns []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedBy returns the first matcher matching ref, or false if none does.
+func blockedBy(matchers []blockedMatcher, ref reference.DockerImageReference) (blockedMatcher, bool) {
+	for _, m := range matchers {
+		if m.matches(ref) {
+			return m, true
+		}
+	}
+	return blockedMatcher{}, false
+}
+
 // IsBlocked will return a boolean value on whether an image
 // is specified as blocked in the ImageSetConfigSpec
-func isBlocked(blocked []v1alpha2.Image, imgRef string) (bool, error) {
-	for _, img := range blocked {
-		matcher, err := regexp.Compile(img.Name)
-		if err != nil {
-			return false, fmt.Errorf("error parsing blocked image regular expression %s: %v", img.Name, err)
+func isBlocked(blocked []v1alpha2.BlockedImage, imgRef string) (bool, error) {
+	matchers, err := newBlockedMatchers(blocked)
+	if err != nil {
+		return false, err
+	}
+	ref, err := reference.Parse(imgRef)
+	if err != nil {
+		return false, fmt.Errorf("invalid image reference %s: %v", imgRef, err)
+	}
+	_, ok := blockedBy(matchers, ref)
+	return ok, nil
+}
+
+// blockedImage is an image of the report of the blocked images.
+type blockedImage struct {
+	Image     string             `json:"image"`
+	Source    string             `json:"source"` // Content the image was collected for: release, samples, operator, additional or helm
+	Type      v1alpha2.ImageType `json:"type"`
+	BlockedBy string             `json:"blockedBy"` // Fields of the blocked image of the configuration matching the image
+}
+
+// removeBlocked removes the images of mapping matching the blocked images
+// of cfg, and records them in o.blocked as collected for source.
+func (o *MirrorOptions) removeBlocked(cfg v1alpha2.ImageSetConfiguration, source string, mapping image.TypedImageMapping) error {
+	if len(cfg.Mirror.BlockedImages) == 0 {
+		return nil
+	}
+	matchers, err := newBlockedMatchers(cfg.Mirror.BlockedImages)
+	if err != nil {
+		return err
+	}
+	for src := range mapping {
+		m, ok := blockedBy(matchers, src.Ref)
+		if !ok {
+			continue
 		}
+		klog.Warningf("skipping blocked %s image %s, blocked by %s", source, src.Ref.Exact(), m)
+		mapping.Remove(src)
+		o.blocked = append(o.blocked, blockedImage{
+			Image:     src.Ref.Exact(),
+			Source:    source,
+			Type:      src.Category,
+			BlockedBy: m.String(),
+		})
+	}
+	return nil
+}
 
-		if matcher.MatchString(imgRef) {
-			return true, nil
+// writeBlockedReport writes the images blocked by the run to the workspace,
+// sorted by image, or removes the report of a previous run when none was.
+func (o *MirrorOptions) writeBlockedReport() error {
+	reportPath := filepath.Join(o.Dir, blockedImagesFile)
+	if len(o.blocked) == 0 {
+		if err := os.Remove(reportPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	sort.Slice(o.blocked, func(i, j int) bool {
+		if o.blocked[i].Image != o.blocked[j].Image {
+			return o.blocked[i].Image < o.blocked[j].Image
 		}
+		return o.blocked[i].Source < o.blocked[j].Source
+	})
+	data, err := json.MarshalIndent(o.blocked, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.Dir, 0750); err != nil {
+		return err
+	}
+	if err := os.WriteFile(reportPath, data, 0640); err != nil {
+		return fmt.Errorf("error writing blocked images report: %v", err)
 	}
-	return false, nil
+	klog.Infof("Blocked %d images, listed in %s", len(o.blocked), reportPath)
+	return nil
 }
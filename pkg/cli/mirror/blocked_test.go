@@ -1,53 +1,109 @@
 package mirror
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/image"
 	"github.com/stretchr/testify/require"
 )
 
 func TestImageBlocking(t *testing.T) {
 	tests := []struct {
 		name          string
-		blockedImages []v1alpha2.Image
+		blockedImages []v1alpha2.BlockedImage
 		ref           string
 		want          bool
 		err           string
 	}{
 		{
 			name:          "Success/ImageBlocked",
-			blockedImages: []v1alpha2.Image{{Name: "alpine"}},
+			blockedImages: []v1alpha2.BlockedImage{{Name: "alpine"}},
 			ref:           "docker.io/library/alpine:latest",
 			want:          true,
 		},
 		{
 			name:          "Success/ImageNotBlocked",
-			blockedImages: []v1alpha2.Image{{Name: "alpine"}},
+			blockedImages: []v1alpha2.BlockedImage{{Name: "alpine"}},
 			ref:           "registry.redhat.io/ubi8/ubi:latest",
 			want:          false,
 		},
 		{
 			name:          "Success/ImageNotBlockedContainsKeyword",
-			blockedImages: []v1alpha2.Image{{Name: "^alpine"}},
+			blockedImages: []v1alpha2.BlockedImage{{Name: "^alpine"}},
 			ref:           "docker.io/library/notalpine:latest",
 			want:          false,
 		},
 		{
 			name:          "Success/ImageBlockedWildCard",
-			blockedImages: []v1alpha2.Image{{Name: "ub*"}},
+			blockedImages: []v1alpha2.BlockedImage{{Name: "ub*"}},
 			ref:           "registry.redhat.io/ubi8/ubi:latest",
 			want:          true,
 		},
 		{
 			name:          "Success/ImageBlockedNoTag",
-			blockedImages: []v1alpha2.Image{{Name: "registry.redhat.io/rhmtc/openshift-migration-velero-restic-restore-helper-rhel8:latest"}},
+			blockedImages: []v1alpha2.BlockedImage{{Name: "registry.redhat.io/rhmtc/openshift-migration-velero-restic-restore-helper-rhel8:latest"}},
 			ref:           "registry.redhat.io/rhmtc/openshift-migration-velero-restic-restore-helper-rhel8:latest",
 			want:          true,
 		},
+		{
+			name:          "Success/ImageBlockedRegistryGlob",
+			blockedImages: []v1alpha2.BlockedImage{{Registry: "*.example.com"}},
+			ref:           "quay.example.com/ns/img:v1",
+			want:          true,
+		},
+		{
+			name:          "Success/ImageBlockedDefaultRegistry",
+			blockedImages: []v1alpha2.BlockedImage{{Registry: "docker.io", Namespace: "library"}},
+			ref:           "alpine:latest",
+			want:          true,
+		},
+		{
+			name:          "Success/ImageBlockedNamespaceAndRepository",
+			blockedImages: []v1alpha2.BlockedImage{{Namespace: "rhmtc", Repository: "openshift-migration-*"}},
+			ref:           "registry.redhat.io/rhmtc/openshift-migration-velero-rhel8:1.7",
+			want:          true,
+		},
+		{
+			name:          "Success/ImageNotBlockedAllFieldsMustMatch",
+			blockedImages: []v1alpha2.BlockedImage{{Namespace: "rhmtc", Repository: "openshift-migration-*", Tags: []string{"1.6*"}}},
+			ref:           "registry.redhat.io/rhmtc/openshift-migration-velero-rhel8:1.7",
+			want:          false,
+		},
+		{
+			name:          "Success/ImageBlockedTagGlob",
+			blockedImages: []v1alpha2.BlockedImage{{Repository: "ubi", Tags: []string{"7*", "8.[0-4]"}}},
+			ref:           "registry.redhat.io/ubi8/ubi:8.2",
+			want:          true,
+		},
+		{
+			name:          "Success/ImageByDigestNotBlockedByTag",
+			blockedImages: []v1alpha2.BlockedImage{{Tags: []string{"*"}}},
+			ref:           "registry.redhat.io/ubi8/ubi@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			want:          false,
+		},
+		{
+			name: "Success/ImageBlockedDigest",
+			blockedImages: []v1alpha2.BlockedImage{{Digests: []string{
+				"sha256:1111111111111111111111111111111111111111111111111111111111111111",
+				"sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			}}},
+			ref:  "registry.redhat.io/ubi8/ubi@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			want: true,
+		},
+		{
+			name:          "Success/ImageNotBlockedDigest",
+			blockedImages: []v1alpha2.BlockedImage{{Digests: []string{"sha256:1111111111111111111111111111111111111111111111111111111111111111"}}},
+			ref:           "registry.redhat.io/ubi8/ubi:latest",
+			want:          false,
+		},
 		{
 			name:          "Failure/InvalidRegexp",
-			blockedImages: []v1alpha2.Image{{Name: "a(b"}},
+			blockedImages: []v1alpha2.BlockedImage{{Name: "a(b"}},
 			ref:           "registry.redhat.io/rhmtc/openshift-migration-velero-restic-restore-helper-rhel8",
 			err:           "error parsing blocked image regular expression a(b: error parsing regexp: missing closing ): `a(b`",
 		},
@@ -66,8 +122,54 @@ func TestImageBlocking(t *testing.T) {
 	}
 }
 
+func TestRemoveBlocked(t *testing.T) {
+	typed := func(ref string, typ v1alpha2.ImageType) image.TypedImage {
+		img, err := image.ParseTypedImage(ref, typ)
+		require.NoError(t, err)
+		return img
+	}
+	cfg := v1alpha2.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v1alpha2.ImageSetConfigurationSpec{
+			Mirror: v1alpha2.Mirror{
+				BlockedImages: []v1alpha2.BlockedImage{
+					{Name: "alpine"},
+					{Registry: "quay.io", Namespace: "blocked"},
+				},
+			},
+		},
+	}
+	opts := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+
+	additional := image.TypedImageMapping{
+		typed("docker.io/library/alpine:latest", v1alpha2.TypeGeneric): typed("localhost:5000/library/alpine:latest", v1alpha2.TypeGeneric),
+		typed("quay.io/ns/img:v1", v1alpha2.TypeGeneric):               typed("localhost:5000/ns/img:v1", v1alpha2.TypeGeneric),
+	}
+	require.NoError(t, opts.removeBlocked(cfg, "additional", additional))
+	require.Len(t, additional, 1)
+	operator := image.TypedImageMapping{
+		typed("quay.io/blocked/related:v1", v1alpha2.TypeOperatorRelatedImage): typed("localhost:5000/blocked/related:v1", v1alpha2.TypeOperatorRelatedImage),
+	}
+	require.NoError(t, opts.removeBlocked(cfg, "operator", operator))
+	require.Empty(t, operator)
+
+	require.NoError(t, opts.writeBlockedReport())
+	data, err := os.ReadFile(filepath.Join(opts.Dir, blockedImagesFile))
+	require.NoError(t, err)
+	var report []blockedImage
+	require.NoError(t, json.Unmarshal(data, &report))
+	require.Equal(t, []blockedImage{
+		{Image: "docker.io/library/alpine:latest", Source: "additional", Type: v1alpha2.TypeGeneric, BlockedBy: "name=alpine"},
+		{Image: "quay.io/blocked/related:v1", Source: "operator", Type: v1alpha2.TypeOperatorRelatedImage, BlockedBy: "registry=quay.io namespace=blocked"},
+	}, report)
+
+	// The report of a run blocking no image is removed.
+	opts.blocked = nil
+	require.NoError(t, opts.writeBlockedReport())
+	require.NoFileExists(t, filepath.Join(opts.Dir, blockedImagesFile))
+}
+
 func BenchmarkIsBlocked_1(b *testing.B) {
-	blocked := []v1alpha2.Image{
+	blocked := []v1alpha2.BlockedImage{
 		{Name: "alpine1"},
 	}
 	for i := 0; i < b.N; i++ {
@@ -77,7 +179,7 @@ func BenchmarkIsBlocked_1(b *testing.B) {
 }
 
 func BenchmarkIsBlocked_5(b *testing.B) {
-	blocked := []v1alpha2.Image{
+	blocked := []v1alpha2.BlockedImage{
 		{Name: "alpine1"},
 		{Name: "alpine2"},
 		{Name: "alpine3"},
@@ -91,7 +193,7 @@ func BenchmarkIsBlocked_5(b *testing.B) {
 }
 
 func BenchmarkIsBlocked_10(b *testing.B) {
-	blocked := []v1alpha2.Image{
+	blocked := []v1alpha2.BlockedImage{
 		{Name: "alpine1"},
 		{Name: "alpine2"},
 		{Name: "alpine3"},
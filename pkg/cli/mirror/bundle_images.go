@@ -0,0 +1,136 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// clusterServiceVersionKind is the kind of the objects
+// describing an operator version in a bundle.
+const clusterServiceVersionKind = "ClusterServiceVersion"
+
+// csvContainer holds the fields of a container of a
+// ClusterServiceVersion deployment naming its image.
+type csvContainer struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+// clusterServiceVersion holds the fields of a ClusterServiceVersion
+// declaring the images an operator version runs.
+type clusterServiceVersion struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		RelatedImages []declcfg.RelatedImage `json:"relatedImages"`
+		Install       struct {
+			Spec struct {
+				Deployments []struct {
+					Spec struct {
+						Template struct {
+							Spec struct {
+								Containers     []csvContainer `json:"containers"`
+								InitContainers []csvContainer `json:"initContainers"`
+							} `json:"spec"`
+						} `json:"template"`
+					} `json:"spec"`
+				} `json:"deployments"`
+			} `json:"spec"`
+		} `json:"install"`
+	} `json:"spec"`
+}
+
+// images returns the related images and the container
+// images of the deployments of the ClusterServiceVersion.
+func (csv clusterServiceVersion) images() []declcfg.RelatedImage {
+	images := append([]declcfg.RelatedImage{}, csv.Spec.RelatedImages...)
+	for _, d := range csv.Spec.Install.Spec.Deployments {
+		podSpec := d.Spec.Template.Spec
+		for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+			images = append(images, declcfg.RelatedImage{Name: c.Name, Image: c.Image})
+		}
+	}
+	return images
+}
+
+// addCSVRelatedImages adds the images declared by the ClusterServiceVersion
+// of each bundle of dc to the related images of the bundle when ctlg sets
+// inspectBundles, so images the catalog omits from the related images of a
+// bundle are mirrored too. Bundle images are pulled when the catalog does
+// not hold the objects of the bundles.
+func (o *OperatorOptions) addCSVRelatedImages(ctx context.Context, ctlg v1alpha2.Operator, dc *declcfg.DeclarativeConfig) error {
+	if !ctlg.InspectBundles {
+		return nil
+	}
+	for i, b := range dc.Bundles {
+		manifests, err := o.bundleManifests(ctx, b)
+		if err != nil {
+			return fmt.Errorf("error reading manifests of bundle %s: %v", b.Name, err)
+		}
+		var csvImages []declcfg.RelatedImage
+		for _, data := range manifests {
+			found, err := parseCSVImages(data)
+			if err != nil {
+				return fmt.Errorf("error parsing manifests of bundle %s: %v", b.Name, err)
+			}
+			csvImages = append(csvImages, found...)
+		}
+		added := mergeRelatedImages(&dc.Bundles[i], csvImages)
+		if added != 0 {
+			klog.Infof("Added %d images declared in the ClusterServiceVersion of bundle %s to its related images", added, b.Name)
+		}
+	}
+	return nil
+}
+
+// parseCSVImages returns the images declared by the
+// ClusterServiceVersion documents of the YAML or JSON data.
+func parseCSVImages(data []byte) ([]declcfg.RelatedImage, error) {
+	var images []declcfg.RelatedImage
+	dec := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return images, nil
+			}
+			return nil, err
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		var csv clusterServiceVersion
+		if err := json.Unmarshal(raw, &csv); err != nil || csv.Kind != clusterServiceVersionKind {
+			continue
+		}
+		images = append(images, csv.images()...)
+	}
+}
+
+// mergeRelatedImages adds the images that are not related images of b yet
+// to the related images of b, and returns the number of images added.
+func mergeRelatedImages(b *declcfg.Bundle, images []declcfg.RelatedImage) int {
+	known := map[string]bool{b.Image: true}
+	for _, ri := range b.RelatedImages {
+		known[ri.Image] = true
+	}
+	added := 0
+	for _, ri := range images {
+		if ri.Image == "" || known[ri.Image] {
+			continue
+		}
+		known[ri.Image] = true
+		b.RelatedImages = append(b.RelatedImages, ri)
+		added++
+	}
+	return added
+}
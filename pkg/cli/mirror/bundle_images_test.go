@@ -0,0 +1,139 @@
+package mirror
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+const testCSV = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: foo.v1.0.0
+spec:
+  relatedImages:
+  - name: operand
+    image: quay.io/example/operand@sha256:0000000000000000000000000000000000000000000000000000000000000001
+  install:
+    spec:
+      deployments:
+      - name: foo-operator
+        spec:
+          template:
+            spec:
+              initContainers:
+              - name: init
+                image: quay.io/example/init:v1.0.0
+              containers:
+              - name: manager
+                image: quay.io/example/foo-operator@sha256:0000000000000000000000000000000000000000000000000000000000000002
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: foo-metrics
+`
+
+func TestParseCSVImages(t *testing.T) {
+	images, err := parseCSVImages([]byte(testCSV))
+	require.NoError(t, err)
+	require.Equal(t, []declcfg.RelatedImage{
+		{Name: "operand", Image: "quay.io/example/operand@sha256:0000000000000000000000000000000000000000000000000000000000000001"},
+		{Name: "init", Image: "quay.io/example/init:v1.0.0"},
+		{Name: "manager", Image: "quay.io/example/foo-operator@sha256:0000000000000000000000000000000000000000000000000000000000000002"},
+	}, images)
+}
+
+func TestMergeRelatedImages(t *testing.T) {
+	b := declcfg.Bundle{
+		Image:         "quay.io/example/foo-bundle:v1.0.0",
+		RelatedImages: []declcfg.RelatedImage{{Name: "manager", Image: "quay.io/example/foo-operator:v1.0.0"}},
+	}
+	added := mergeRelatedImages(&b, []declcfg.RelatedImage{
+		{Name: "bundle", Image: "quay.io/example/foo-bundle:v1.0.0"},
+		{Name: "manager", Image: "quay.io/example/foo-operator:v1.0.0"},
+		{Name: "operand", Image: "quay.io/example/operand:v1.0.0"},
+		{Name: "operand-copy", Image: "quay.io/example/operand:v1.0.0"},
+		{Name: "empty"},
+	})
+	require.Equal(t, 1, added)
+	require.Equal(t, []declcfg.RelatedImage{
+		{Name: "manager", Image: "quay.io/example/foo-operator:v1.0.0"},
+		{Name: "operand", Image: "quay.io/example/operand:v1.0.0"},
+	}, b.RelatedImages)
+}
+
+func TestAddCSVRelatedImages(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// The bundle image holds the ClusterServiceVersion in its manifests directory.
+	var layer bytes.Buffer
+	tw := tar.NewWriter(&layer)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "manifests/foo.clusterserviceversion.yaml", Mode: 0644, Size: int64(len(testCSV)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte(testCSV))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	img, err := mutate.AppendLayers(empty.Image, static.NewLayer(layer.Bytes(), types.OCIUncompressedLayer))
+	require.NoError(t, err)
+	bundleImage := u.Host + "/example/foo-bundle:v1.0.0"
+	ref, err := name.ParseReference(bundleImage)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	dc := &declcfg.DeclarativeConfig{
+		Bundles: []declcfg.Bundle{
+			{
+				Name:          "foo.v1.0.0",
+				Package:       "foo",
+				Image:         bundleImage,
+				RelatedImages: []declcfg.RelatedImage{{Name: "bundle", Image: bundleImage}},
+			},
+			{
+				// The catalog holds the ClusterServiceVersion of this bundle.
+				Name:       "foo.v0.9.0",
+				Package:    "foo",
+				Image:      u.Host + "/example/foo-bundle:missing",
+				Properties: []property.Property{property.MustBuildBundleObject([]byte(testCSV))},
+			},
+		},
+	}
+	opts := NewOperatorOptions(&MirrorOptions{
+		RootOptions:     &cli.RootOptions{Dir: t.TempDir()},
+		SourcePlainHTTP: true,
+	})
+
+	require.NoError(t, opts.addCSVRelatedImages(context.Background(), v1alpha2.Operator{}, dc))
+	require.Len(t, dc.Bundles[0].RelatedImages, 1)
+
+	require.NoError(t, opts.addCSVRelatedImages(context.Background(), v1alpha2.Operator{InspectBundles: true}, dc))
+	for _, b := range dc.Bundles {
+		var images []string
+		for _, ri := range b.RelatedImages {
+			images = append(images, ri.Image)
+		}
+		require.Contains(t, images, "quay.io/example/operand@sha256:0000000000000000000000000000000000000000000000000000000000000001", b.Name)
+		require.Contains(t, images, "quay.io/example/init:v1.0.0", b.Name)
+		require.Contains(t, images, "quay.io/example/foo-operator@sha256:0000000000000000000000000000000000000000000000000000000000000002", b.Name)
+	}
+	require.Len(t, dc.Bundles[0].RelatedImages, 4)
+}
@@ -0,0 +1,177 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/operator-framework/operator-registry/pkg/image"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/operator"
+)
+
+// bundleChannels holds the channels a bundle image is published
+// in, read from the channels annotations of the bundle.
+type bundleChannels struct {
+	channels       []string
+	defaultChannel string
+}
+
+// renderBundleList renders the bundle images of ctlg into a declarative config
+// generated by oc-mirror. Satisfies the renderDCFunc function signature.
+func (o *OperatorOptions) renderBundleList(
+	ctx context.Context,
+	reg *containerdregistry.Registry,
+	ctlg v1alpha2.Operator,
+) (*declcfg.DeclarativeConfig, v1alpha2.IncludeConfig, error) {
+	var ic v1alpha2.IncludeConfig
+	dc := &declcfg.DeclarativeConfig{}
+	channelsByBundle := map[string]bundleChannels{}
+	for _, ref := range ctlg.Bundles {
+		rendered, err := action.Render{
+			Registry:       reg,
+			Refs:           []string{ref},
+			AllowedRefMask: action.RefBundleImage,
+		}.Run(ctx)
+		if err != nil {
+			return nil, ic, fmt.Errorf("error rendering bundle %s: %v", ref, err)
+		}
+		labels, err := reg.Labels(ctx, image.SimpleReference(ref))
+		if err != nil {
+			return nil, ic, fmt.Errorf("error reading labels of bundle %s: %v", ref, err)
+		}
+		for _, b := range rendered.Bundles {
+			channelsByBundle[b.Name] = bundleChannels{
+				channels:       splitChannels(labels[bundle.ChannelsLabel]),
+				defaultChannel: labels[bundle.ChannelDefaultLabel],
+			}
+		}
+		dc.Bundles = append(dc.Bundles, rendered.Bundles...)
+	}
+
+	if err := generateChannels(dc, channelsByBundle); err != nil {
+		return nil, ic, err
+	}
+	if _, err := declcfg.ConvertToModel(*dc); err != nil {
+		return nil, ic, fmt.Errorf("error validating catalog generated from bundles: %v", err)
+	}
+
+	// Render ic for incorporation into the metadata
+	ic, err := operator.NewCatalogStrategy().ConvertDCToIncludeConfig(*dc)
+	if err != nil {
+		return nil, ic, fmt.Errorf("error converting declarative config to include config: %v", err)
+	}
+	return dc, ic, nil
+}
+
+// generateChannels adds the packages and channels of the bundles of dc.
+// Bundles without channels annotations are published in a stable channel.
+// The bundles of a channel replace each other in version order, and the
+// default channel of a package is the one of its latest bundle.
+func generateChannels(dc *declcfg.DeclarativeConfig, channelsByBundle map[string]bundleChannels) error {
+	type versionedBundle struct {
+		name    string
+		version semver.Version
+	}
+	bundlesByChannel := map[string]map[string][]versionedBundle{}
+	latest := map[string]versionedBundle{}
+	for _, b := range dc.Bundles {
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return fmt.Errorf("error parsing properties of bundle %s: %v", b.Name, err)
+		}
+		if len(props.Packages) != 1 {
+			return fmt.Errorf("bundle %s must have exactly one package property", b.Name)
+		}
+		version, err := semver.Parse(props.Packages[0].Version)
+		if err != nil {
+			return fmt.Errorf("error parsing version of bundle %s: %v", b.Name, err)
+		}
+		vb := versionedBundle{name: b.Name, version: version}
+
+		channels := channelsByBundle[b.Name].channels
+		if len(channels) == 0 {
+			channels = []string{"stable"}
+		}
+		if bundlesByChannel[b.Package] == nil {
+			bundlesByChannel[b.Package] = map[string][]versionedBundle{}
+		}
+		for _, ch := range channels {
+			bundlesByChannel[b.Package][ch] = append(bundlesByChannel[b.Package][ch], vb)
+		}
+		if current, found := latest[b.Package]; !found || version.GT(current.version) {
+			latest[b.Package] = vb
+		}
+	}
+
+	pkgNames := make([]string, 0, len(bundlesByChannel))
+	for pkgName := range bundlesByChannel {
+		pkgNames = append(pkgNames, pkgName)
+	}
+	sort.Strings(pkgNames)
+
+	dc.Packages = nil
+	dc.Channels = nil
+	for _, pkgName := range pkgNames {
+		channels := bundlesByChannel[pkgName]
+		head := channelsByBundle[latest[pkgName].name]
+		defaultChannel := head.defaultChannel
+		if _, found := channels[defaultChannel]; !found {
+			// Bundles published in a single channel may omit the default channel.
+			defaultChannel = "stable"
+			if len(head.channels) != 0 {
+				defaultChannel = head.channels[0]
+			}
+		}
+		dc.Packages = append(dc.Packages, declcfg.Package{
+			Schema:         declcfg.SchemaPackage,
+			Name:           pkgName,
+			DefaultChannel: defaultChannel,
+		})
+
+		chNames := make([]string, 0, len(channels))
+		for chName := range channels {
+			chNames = append(chNames, chName)
+		}
+		sort.Strings(chNames)
+		for _, chName := range chNames {
+			bundles := channels[chName]
+			sort.Slice(bundles, func(i, j int) bool {
+				return bundles[i].version.LT(bundles[j].version)
+			})
+			ch := declcfg.Channel{
+				Schema:  declcfg.SchemaChannel,
+				Name:    chName,
+				Package: pkgName,
+			}
+			for i, b := range bundles {
+				entry := declcfg.ChannelEntry{Name: b.name}
+				if i > 0 {
+					entry.Replaces = bundles[i-1].name
+				}
+				ch.Entries = append(ch.Entries, entry)
+			}
+			dc.Channels = append(dc.Channels, ch)
+		}
+	}
+	return nil
+}
+
+// splitChannels returns the channels of a comma-separated channels annotation.
+func splitChannels(annotation string) []string {
+	var channels []string
+	for _, ch := range strings.Split(annotation, ",") {
+		if ch = strings.TrimSpace(ch); ch != "" {
+			channels = append(channels, ch)
+		}
+	}
+	return channels
+}
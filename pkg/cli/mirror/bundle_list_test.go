@@ -0,0 +1,111 @@
+package mirror
+
+import (
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateChannels(t *testing.T) {
+	bundle := func(pkg, version string) declcfg.Bundle {
+		return declcfg.Bundle{
+			Schema:     declcfg.SchemaBundle,
+			Name:       pkg + ".v" + version,
+			Package:    pkg,
+			Image:      "quay.io/example/" + pkg + "-bundle:v" + version,
+			Properties: []property.Property{property.MustBuildPackage(pkg, version)},
+		}
+	}
+
+	type spec struct {
+		name             string
+		bundles          []declcfg.Bundle
+		channelsByBundle map[string]bundleChannels
+		expPackages      []declcfg.Package
+		expChannels      []declcfg.Channel
+		expError         string
+	}
+
+	cases := []spec{
+		{
+			name:    "Valid/ChannelsFromAnnotations",
+			bundles: []declcfg.Bundle{bundle("foo", "1.1.0"), bundle("foo", "1.0.0"), bundle("foo", "2.0.0")},
+			channelsByBundle: map[string]bundleChannels{
+				"foo.v1.0.0": {channels: []string{"stable-v1"}, defaultChannel: "stable-v1"},
+				"foo.v1.1.0": {channels: []string{"stable-v1", "fast"}, defaultChannel: "stable-v1"},
+				"foo.v2.0.0": {channels: []string{"stable-v2", "fast"}, defaultChannel: "stable-v2"},
+			},
+			expPackages: []declcfg.Package{
+				{Schema: declcfg.SchemaPackage, Name: "foo", DefaultChannel: "stable-v2"},
+			},
+			expChannels: []declcfg.Channel{
+				{Schema: declcfg.SchemaChannel, Name: "fast", Package: "foo", Entries: []declcfg.ChannelEntry{
+					{Name: "foo.v1.1.0"},
+					{Name: "foo.v2.0.0", Replaces: "foo.v1.1.0"},
+				}},
+				{Schema: declcfg.SchemaChannel, Name: "stable-v1", Package: "foo", Entries: []declcfg.ChannelEntry{
+					{Name: "foo.v1.0.0"},
+					{Name: "foo.v1.1.0", Replaces: "foo.v1.0.0"},
+				}},
+				{Schema: declcfg.SchemaChannel, Name: "stable-v2", Package: "foo", Entries: []declcfg.ChannelEntry{
+					{Name: "foo.v2.0.0"},
+				}},
+			},
+		},
+		{
+			name:    "Valid/NoAnnotations",
+			bundles: []declcfg.Bundle{bundle("foo", "1.0.0"), bundle("bar", "0.1.0")},
+			expPackages: []declcfg.Package{
+				{Schema: declcfg.SchemaPackage, Name: "bar", DefaultChannel: "stable"},
+				{Schema: declcfg.SchemaPackage, Name: "foo", DefaultChannel: "stable"},
+			},
+			expChannels: []declcfg.Channel{
+				{Schema: declcfg.SchemaChannel, Name: "stable", Package: "bar", Entries: []declcfg.ChannelEntry{{Name: "bar.v0.1.0"}}},
+				{Schema: declcfg.SchemaChannel, Name: "stable", Package: "foo", Entries: []declcfg.ChannelEntry{{Name: "foo.v1.0.0"}}},
+			},
+		},
+		{
+			name:    "Valid/NoDefaultChannelAnnotation",
+			bundles: []declcfg.Bundle{bundle("foo", "1.0.0")},
+			channelsByBundle: map[string]bundleChannels{
+				"foo.v1.0.0": {channels: []string{"alpha"}},
+			},
+			expPackages: []declcfg.Package{
+				{Schema: declcfg.SchemaPackage, Name: "foo", DefaultChannel: "alpha"},
+			},
+			expChannels: []declcfg.Channel{
+				{Schema: declcfg.SchemaChannel, Name: "alpha", Package: "foo", Entries: []declcfg.ChannelEntry{{Name: "foo.v1.0.0"}}},
+			},
+		},
+		{
+			name: "Invalid/NoPackageProperty",
+			bundles: []declcfg.Bundle{
+				{Schema: declcfg.SchemaBundle, Name: "foo.v1.0.0", Package: "foo"},
+			},
+			expError: "bundle foo.v1.0.0 must have exactly one package property",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dc := &declcfg.DeclarativeConfig{Bundles: c.bundles}
+			err := generateChannels(dc, c.channelsByBundle)
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expPackages, dc.Packages)
+			require.Equal(t, c.expChannels, dc.Channels)
+			_, err = declcfg.ConvertToModel(*dc)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSplitChannels(t *testing.T) {
+	require.Equal(t, []string{"stable", "fast"}, splitChannels("stable, fast,"))
+	require.Nil(t, splitChannels(""))
+}
@@ -0,0 +1,101 @@
+package mirror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+// catalogCacheKey returns the key of the opm cache of the declarative config
+// at configPath: the digest of the files of the declarative config and of the
+// opm binary at opmPath, as the format of the cache depends on the opm version.
+func catalogCacheKey(configPath, opmPath string) (string, error) {
+	h := sha256.New()
+	// WalkDir visits the files in lexical order, so the key is stable.
+	err := filepath.WalkDir(configPath, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(configPath, fpath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		return hashFile(h, fpath)
+	})
+	if err != nil {
+		return "", fmt.Errorf("error hashing declarative config %s: %v", configPath, err)
+	}
+	fmt.Fprint(h, "\x00opm\x00")
+	if err := hashFile(h, opmPath); err != nil {
+		return "", fmt.Errorf("error hashing opm binary %s: %v", opmPath, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(w io.Writer, fpath string) error {
+	f, err := os.Open(filepath.Clean(fpath))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// catalogCachePath returns the path in the workspace of the opm cache of key.
+func (o *MirrorOptions) catalogCachePath(key string) string {
+	return filepath.Join(o.Dir, config.CatalogCachesDir, key)
+}
+
+// restoreCatalogCache replaces the cache at cachePath with the opm cache of key
+// persisted in the workspace by a previous run. It returns false if there is none.
+func (o *MirrorOptions) restoreCatalogCache(key, cachePath string) (bool, error) {
+	persisted := o.catalogCachePath(key)
+	switch _, err := os.Stat(persisted); {
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	if err := os.RemoveAll(cachePath); err != nil {
+		return false, err
+	}
+	if err := os.CopyFS(cachePath, os.DirFS(persisted)); err != nil {
+		return false, fmt.Errorf("error restoring catalog cache %s: %v", persisted, err)
+	}
+	return true, nil
+}
+
+// persistCatalogCache copies the opm cache at cachePath to the workspace under key,
+// so later runs rebuilding the same declarative config reuse it.
+func (o *MirrorOptions) persistCatalogCache(key, cachePath string) error {
+	persisted := o.catalogCachePath(key)
+	if err := os.MkdirAll(filepath.Dir(persisted), 0750); err != nil {
+		return err
+	}
+	// The cache is copied to a temporary directory then renamed,
+	// so an interrupted copy is never restored.
+	tmp, err := os.MkdirTemp(filepath.Dir(persisted), key+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.CopyFS(tmp, os.DirFS(cachePath)); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, persisted); err != nil {
+		return err
+	}
+	klog.V(1).Infof("Persisted catalog cache %s", persisted)
+	return nil
+}
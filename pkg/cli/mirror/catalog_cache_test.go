@@ -0,0 +1,72 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+func TestCatalogCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "configs")
+	require.NoError(t, os.MkdirAll(filepath.Join(configPath, "foo"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(configPath, "foo", "catalog.json"), []byte(`{"schema":"olm.package"}`), 0600))
+	opmPath := filepath.Join(dir, "opm")
+	require.NoError(t, os.WriteFile(opmPath, []byte("opm v1"), 0600))
+
+	key, err := catalogCacheKey(configPath, opmPath)
+	require.NoError(t, err)
+	again, err := catalogCacheKey(configPath, opmPath)
+	require.NoError(t, err)
+	require.Equal(t, key, again)
+
+	// A new opm binary invalidates the cache.
+	require.NoError(t, os.WriteFile(opmPath, []byte("opm v2"), 0600))
+	opmKey, err := catalogCacheKey(configPath, opmPath)
+	require.NoError(t, err)
+	require.NotEqual(t, key, opmKey)
+
+	// So does a change of the declarative config.
+	require.NoError(t, os.WriteFile(filepath.Join(configPath, "foo", "catalog.json"), []byte(`{"schema":"olm.bundle"}`), 0600))
+	configKey, err := catalogCacheKey(configPath, opmPath)
+	require.NoError(t, err)
+	require.NotEqual(t, opmKey, configKey)
+
+	_, err = catalogCacheKey(filepath.Join(dir, "missing"), opmPath)
+	require.ErrorContains(t, err, "error hashing declarative config")
+}
+
+func TestRestoreCatalogCache(t *testing.T) {
+	o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+
+	cachePath := filepath.Join(t.TempDir(), "cache")
+	require.NoError(t, os.MkdirAll(filepath.Join(cachePath, "pogreb.v1"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(cachePath, "digest"), []byte("abc"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(cachePath, "pogreb.v1", "db"), []byte("db"), 0600))
+
+	restorePath := filepath.Join(t.TempDir(), "cache")
+	restored, err := o.restoreCatalogCache("key", restorePath)
+	require.NoError(t, err)
+	require.False(t, restored)
+
+	require.NoError(t, o.persistCatalogCache("key", cachePath))
+
+	// A stale cache left in the catalog is replaced.
+	require.NoError(t, os.MkdirAll(restorePath, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(restorePath, "stale"), []byte("stale"), 0600))
+	restored, err = o.restoreCatalogCache("key", restorePath)
+	require.NoError(t, err)
+	require.True(t, restored)
+	data, err := os.ReadFile(filepath.Join(restorePath, "pogreb.v1", "db"))
+	require.NoError(t, err)
+	require.Equal(t, "db", string(data))
+	require.NoFileExists(t, filepath.Join(restorePath, "stale"))
+
+	restored, err = o.restoreCatalogCache("other", restorePath)
+	require.NoError(t, err)
+	require.False(t, restored)
+}
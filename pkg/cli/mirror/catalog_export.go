@@ -0,0 +1,85 @@
+package mirror
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/mholt/archiver/v3"
+	"github.com/otiai10/copy"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+/*
+exportCatalogs copies the filtered declarative config of every catalog found in
+<srcDir>/catalogs to <resultsDir>/catalogs/<repoPath>, where <repoPath> identifies
+the catalog and its version (tag or digest), e.g.
+
+	results-1675904745/catalogs/registry.redhat.io/redhat/redhat-operator-index/v4.14/index.json
+
+The exported directories can be inspected, served with opm or catalogd, or used to
+build catalog images downstream. If ExportCatalogsArchive is set, each directory is
+also written as a <repoPath>.tar.gz archive.
+
+# Arguments
+
+• srcDir: the directory containing the catalogs directory, i.e. <some path>/src
+
+• resultsDir: the results directory of the current run
+
+# Returns
+
+• error: non-nil if an error occurs, nil otherwise
+*/
+func (o *MirrorOptions) exportCatalogs(srcDir, resultsDir string) error {
+	catalogsDir := filepath.Join(srcDir, config.CatalogsDir)
+	exportDir := filepath.Join(resultsDir, config.CatalogsDir)
+
+	return filepath.WalkDir(catalogsDir, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && fpath == catalogsDir {
+				return nil
+			}
+			return err
+		}
+		// The OCI layouts do not contain declarative configs.
+		if d.IsDir() && d.Name() == config.LayoutsDir {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() || d.Name() != config.IndexDir {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(fpath, "index.json")); err != nil {
+			return nil
+		}
+
+		// fpath looks like <some path>/src/catalogs/<repoPath>/index
+		repoPath, err := filepath.Rel(catalogsDir, filepath.Dir(fpath))
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(exportDir, repoPath)
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := copy.Copy(fpath, dst); err != nil {
+			return fmt.Errorf("error exporting catalog %s: %v", repoPath, err)
+		}
+		klog.Infof("Wrote declarative config of catalog %s to %s", repoPath, dst)
+
+		if o.ExportCatalogsArchive {
+			archivePath := dst + ".tar.gz"
+			if err := os.RemoveAll(archivePath); err != nil {
+				return err
+			}
+			if err := archiver.NewTarGz().Archive([]string{dst}, archivePath); err != nil {
+				return fmt.Errorf("error archiving catalog %s: %v", repoPath, err)
+			}
+			klog.Infof("Wrote declarative config archive of catalog %s to %s", repoPath, archivePath)
+		}
+		return filepath.SkipDir
+	})
+}
@@ -0,0 +1,74 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+func TestExportCatalogs(t *testing.T) {
+	type spec struct {
+		name     string
+		archive  bool
+		catalogs bool
+		expFiles []string
+	}
+
+	repoPath := filepath.Join("quay.io", "example", "catalog", "v4.14")
+
+	specs := []spec{
+		{
+			name:     "Valid/Directory",
+			catalogs: true,
+			expFiles: []string{
+				filepath.Join(config.CatalogsDir, repoPath, "index.json"),
+			},
+		},
+		{
+			name:     "Valid/DirectoryAndArchive",
+			catalogs: true,
+			archive:  true,
+			expFiles: []string{
+				filepath.Join(config.CatalogsDir, repoPath, "index.json"),
+				filepath.Join(config.CatalogsDir, repoPath+".tar.gz"),
+			},
+		},
+		{
+			name: "Valid/NoCatalogs",
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			resultsDir := t.TempDir()
+
+			if s.catalogs {
+				ctlgDir := filepath.Join(srcDir, config.CatalogsDir, repoPath)
+				require.NoError(t, os.MkdirAll(filepath.Join(ctlgDir, config.IndexDir), 0755))
+				require.NoError(t, os.MkdirAll(filepath.Join(ctlgDir, config.LayoutsDir), 0755))
+				dc := []byte(`{"schema":"olm.package","name":"foo"}`)
+				require.NoError(t, os.WriteFile(filepath.Join(ctlgDir, config.IndexDir, "index.json"), dc, 0600))
+				require.NoError(t, os.WriteFile(filepath.Join(ctlgDir, config.LayoutsDir, "index.json"), []byte(`{}`), 0600))
+			}
+
+			opts := &MirrorOptions{ExportCatalogs: true, ExportCatalogsArchive: s.archive}
+			require.NoError(t, opts.exportCatalogs(srcDir, resultsDir))
+
+			for _, f := range s.expFiles {
+				require.FileExists(t, filepath.Join(resultsDir, f))
+			}
+			if s.catalogs {
+				data, err := os.ReadFile(filepath.Join(resultsDir, config.CatalogsDir, repoPath, "index.json"))
+				require.NoError(t, err)
+				require.JSONEq(t, `{"schema":"olm.package","name":"foo"}`, string(data))
+			} else {
+				require.NoDirExists(t, filepath.Join(resultsDir, config.CatalogsDir))
+			}
+		})
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"io/fs"
 	"math/rand"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"runtime"
@@ -25,6 +24,7 @@ import (
 	"github.com/openshift/oc/pkg/cli/image/imagesource"
 	"github.com/operator-framework/operator-registry/pkg/containertools"
 	"github.com/otiai10/copy"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/klog/v2"
 
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
@@ -148,8 +148,11 @@ func (o *MirrorOptions) rebuildOrCopyCatalogs(ctx context.Context, dstDir string
 				ctlgRef.Ref = sourceRef.Ref
 				// Update registry so the existing catalog image can be pulled.
 				ctlgRef.Ref.Registry = mirrorRef.Ref.Registry
-				ctlgRef.Ref.Namespace = path.Join(o.UserNamespace, ctlgRef.Ref.Namespace)
+				ctlgRef.Ref.Namespace = path.Join(o.UserNamespace, mappedNamespace(o.namespaceMappings, sourceRef.Ref), ctlgRef.Ref.Namespace)
 				ctlgRef = ctlgRef.SetDefaults()
+				if ctlgRef.Ref.Tag, err = o.destinationTag(v1alpha2.TypeOperatorCatalog, sourceRef.Ref, ctlgRef.Ref.Tag); err != nil {
+					return err
+				}
 				// Unset the ID when passing to the image builder.
 				// Tags are needed here since the digest will be recalculated.
 				ctlgRef.Ref.ID = ""
@@ -158,6 +161,9 @@ func (o *MirrorOptions) rebuildOrCopyCatalogs(ctx context.Context, dstDir string
 
 				// Add to mapping for ICSP generation
 				refs.Add(sourceRef, ctlgRef.TypedImageReference, v1alpha2.TypeOperatorCatalog)
+				if err := o.addCatalogImages(ctx, sourceRef, filepath.Join(slashPath, config.IndexDir)); err != nil {
+					return err
+				}
 			}
 			return nil
 		}); err != nil {
@@ -213,8 +219,11 @@ func (o *MirrorOptions) rebuildOrCopyCatalogs(ctx context.Context, dstDir string
 				ctlgRef.Ref = originRef.Ref
 				// Update registry so the existing catalog image can be pulled.
 				ctlgRef.Ref.Registry = mirrorRef.Ref.Registry
-				ctlgRef.Ref.Namespace = path.Join(o.UserNamespace, ctlgRef.Ref.Namespace)
+				ctlgRef.Ref.Namespace = path.Join(o.UserNamespace, mappedNamespace(o.namespaceMappings, originRef.Ref), ctlgRef.Ref.Namespace)
 				ctlgRef = ctlgRef.SetDefaults()
+				if ctlgRef.Ref.Tag, err = o.destinationTag(v1alpha2.TypeOperatorCatalog, originRef.Ref, ctlgRef.Ref.Tag); err != nil {
+					return err
+				}
 				// Unset the ID when passing to the image builder.
 				// Tags are needed here since the digest will be recalculated.
 				ctlgRef.Ref.ID = ""
@@ -260,7 +269,11 @@ func (o *MirrorOptions) rebuildOrCopyCatalogs(ctx context.Context, dstDir string
 					remoteOpts := getRemoteOpts(ctx, destInsecure)
 
 					imgBuilder := builder.NewImageBuilder(nameOpts, remoteOpts)
+					imgBuilder.OCIMediaTypes = o.OCIMediaTypes
 					update := func(cfg *v1.ConfigFile) {}
+					if err := o.ensureRepositories(ctx, ctlgRef.Ref); err != nil {
+						return err
+					}
 					err = imgBuilder.Run(ctx, ctlgRef.Ref.String(), layoutPath, update, []v1.Layer{}...)
 					if err != nil {
 						return fmt.Errorf("error copying image %s from %s: %v", "docker://"+ctlgRef.Ref.String(), fpath, err)
@@ -279,7 +292,7 @@ func (o *MirrorOptions) rebuildOrCopyCatalogs(ctx context.Context, dstDir string
 
 	// Resolve the image's digest for ICSP creation.
 	for source, dest := range refs {
-		destRef, err := image.ResolveToPin(ctx, sysContext, dest.Ref.Exact())
+		destRef, err := o.resolvePushedImage(ctx, sysContext, dest.Ref.Exact())
 		if err != nil {
 			return nil, fmt.Errorf("error retrieving digest for graph image %q: %v", dest.Ref.Exact(), err)
 		}
@@ -305,116 +318,182 @@ processCatalogRefs uses the image builder to update a given image using the data
 
 # Returns
 
-• error: non-nil if error occurs, nil otherwise
+• error: non-nil if any catalog was not published, after trying to publish the others
 */
 func (o *MirrorOptions) processCatalogRefs(ctx context.Context, catalogsByImage map[image.TypedImage]string) error {
+	status, err := o.loadCatalogPublishStatus()
+	if err != nil {
+		return err
+	}
+	// A catalog failing does not stop the others, so that a publish run
+	// again only rebuilds the catalogs that did not reach the destination.
+	var errs []error
 	for ctlgRef, artifactDir := range catalogsByImage {
-		// Always build the catalog image with the new declarative config catalog
-		// using the original catalog as the base image
-		var layoutPath layout.Path
-		refExact := ctlgRef.Ref.Exact()
-
-		var destInsecure bool
-		if o.DestPlainHTTP || o.DestSkipTLS {
-			destInsecure = true
+		if err := o.checkInterrupted(ctx); err != nil {
+			return err
 		}
-
-		// Check push permissions before trying to resolve for Quay compatibility
-		nameOpts := getNameOpts(destInsecure)
-		remoteOpts := getRemoteOpts(ctx, destInsecure)
-		imgBuilder := builder.NewImageBuilder(nameOpts, remoteOpts)
-
-		klog.Infof("Rendering catalog image %q with file-based catalog ", refExact)
-
-		layersToAdd := []v1.Layer{}
-		layersToDelete := []v1.Layer{}
-		withCacheRegeneration := o.RebuildCatalogs && o.BuildCatalogCache
-		_, err := os.Stat(filepath.Join(artifactDir, config.OPMCacheLocationPlaceholder))
-		if errors.Is(err, os.ErrNotExist) {
-			withCacheRegeneration = false
-		} else if err != nil {
-			return fmt.Errorf("unable to determine location of cache for image %s. Cache generation failed: %v", ctlgRef, err)
+		if err := o.publishCatalog(ctx, status, ctlgRef, artifactDir); err != nil {
+			errs = append(errs, fmt.Errorf("catalog %s: %v", ctlgRef.Ref.Exact(), err))
 		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%d of %d catalogs were not published, publish again to retry only them: %v",
+			len(errs), len(catalogsByImage), utilerrors.NewAggregate(errs))
+	}
+	return nil
+}
+
+// rebuildCatalog builds the catalog image ctlgRef with the declarative config
+// of artifactDir, regenerating its cache if requested, and pushes it.
+func (o *MirrorOptions) rebuildCatalog(ctx context.Context, ctlgRef image.TypedImage, artifactDir string) error {
+	// Always build the catalog image with the new declarative config catalog
+	// using the original catalog as the base image
+	var layoutPath layout.Path
+	refExact := ctlgRef.Ref.Exact()
+
+	var destInsecure bool
+	if o.DestPlainHTTP || o.DestSkipTLS {
+		destInsecure = true
+	}
+
+	// Check push permissions before trying to resolve for Quay compatibility
+	nameOpts := getNameOpts(destInsecure)
+	remoteOpts := getRemoteOpts(ctx, destInsecure)
+	imgBuilder := builder.NewImageBuilder(nameOpts, remoteOpts)
+	imgBuilder.OCIMediaTypes = o.OCIMediaTypes
 
-		configLayerToAdd, err := builder.LayerFromPathWithUidGid("/configs", filepath.Join(artifactDir, config.IndexDir), 0, 0)
+	klog.Infof("Rendering catalog image %q with file-based catalog ", refExact)
+
+	layersToAdd := []v1.Layer{}
+	layersToDelete := []v1.Layer{}
+	withCacheRegeneration := o.RebuildCatalogs && o.BuildCatalogCache
+	_, err := os.Stat(filepath.Join(artifactDir, config.OPMCacheLocationPlaceholder))
+	if errors.Is(err, os.ErrNotExist) {
+		withCacheRegeneration = false
+	} else if err != nil {
+		return fmt.Errorf("unable to determine location of cache for image %s. Cache generation failed: %v", ctlgRef, err)
+	}
+
+	configLayerToAdd, err := builder.LayerFromPathWithUidGid("/configs", filepath.Join(artifactDir, config.IndexDir), 0, 0)
+	if err != nil {
+		return fmt.Errorf("error creating add layer: %v", err)
+	}
+	layersToAdd = append(layersToAdd, configLayerToAdd)
+
+	// Since we are defining the FBC as index.json,
+	// remove anything that may currently exist
+	deletedConfigLayer, err := deleteLayer("/.wh.configs")
+	if err != nil {
+		return fmt.Errorf("error creating deleted layer: %v", err)
+	}
+	layersToDelete = append(layersToDelete, deletedConfigLayer)
+
+	if withCacheRegeneration {
+
+		opmCmdPath, opmSource, err := findOpmCmd(artifactDir, o.OPMBinary)
 		if err != nil {
-			return fmt.Errorf("error creating add layer: %v", err)
+			return fmt.Errorf("cannot find opm to regenerate the cache of %v: %v", ctlgRef, err)
 		}
-		layersToAdd = append(layersToAdd, configLayerToAdd)
+		klog.Infof("Using opm %s (%s) to regenerate the cache of %v", opmCmdPath, opmSource, ctlgRef)
 
-		// Since we are defining the FBC as index.json,
-		// remove anything that may currently exist
-		deletedConfigLayer, err := deleteLayer("/.wh.configs")
+		absConfigPath, err := filepath.Abs(filepath.Join(artifactDir, config.IndexDir))
 		if err != nil {
-			return fmt.Errorf("error creating deleted layer: %v", err)
+			return fmt.Errorf("error getting absolute path for catalog's index %v: %v", filepath.Join(artifactDir, config.IndexDir), err)
 		}
-		layersToDelete = append(layersToDelete, deletedConfigLayer)
-
-		if withCacheRegeneration {
-
-			opmCmdPath := ""
-			if opmBinary := os.Getenv("OPM_BINARY"); opmBinary != "" {
-				opmCmdPath = opmBinary
-			} else {
-				opmCmdPath = filepath.Join(artifactDir, config.OpmBinDir, "opm")
-			}
-			_, err = os.Stat(opmCmdPath)
-			if err != nil {
-				return fmt.Errorf("cannot find opm in the extracted catalog %v for %s on %s: %v", ctlgRef, runtime.GOOS, runtime.GOARCH, err)
-			}
-
-			absConfigPath, err := filepath.Abs(filepath.Join(artifactDir, config.IndexDir))
-			if err != nil {
-				return fmt.Errorf("error getting absolute path for catalog's index %v: %v", filepath.Join(artifactDir, config.IndexDir), err)
-			}
-			absCachePath, err := filepath.Abs(filepath.Join(artifactDir, config.TmpDir))
+		absCachePath, err := filepath.Abs(filepath.Join(artifactDir, config.TmpDir))
+		if err != nil {
+			return fmt.Errorf("error getting absolute path for catalog's cache %v: %v", filepath.Join(artifactDir, config.TmpDir), err)
+		}
+		// The cache persisted by a previous run for the same declarative config
+		// and opm binary is reused, as regenerating it is expensive.
+		cacheKey, err := catalogCacheKey(absConfigPath, opmCmdPath)
+		if err != nil {
+			return err
+		}
+		restored, err := o.restoreCatalogCache(cacheKey, absCachePath)
+		if err != nil {
+			return err
+		}
+		if restored {
+			klog.Infof("reusing the cache of %v from a previous run", ctlgRef)
+		} else {
+			cmd, err := opmCommand(opmCmdPath, o.OPMSandbox, "serve", absConfigPath, "--cache-dir", absCachePath, "--cache-only")
 			if err != nil {
-				return fmt.Errorf("error getting absolute path for catalog's cache %v: %v", filepath.Join(artifactDir, config.TmpDir), err)
+				return err
 			}
-			cmd := exec.Command(opmCmdPath, "serve", absConfigPath, "--cache-dir", absCachePath, "--cache-only")
 			if err := cmd.Run(); err != nil {
-				return fmt.Errorf("error regenerating the cache for %v: %v", ctlgRef, err)
+				return fmt.Errorf("error regenerating the cache for %v: %v", ctlgRef, opmStartError(err, o.OPMSandbox))
 			}
-			// Fix OCPBUGS-17546:
-			// Add the cache under /cache in a new layer (instead of white-out /tmp/cache, which resulted in crashLoopBackoff only on some clusters)
-			cacheLayerToAdd, err := builder.LayerFromPathWithUidGid("/cache", filepath.Join(artifactDir, config.TmpDir), cacheFolderUID, cacheFolderGID)
-			if err != nil {
-				return fmt.Errorf("error creating add layer: %v", err)
+			if err := verifyCatalogCache(opmCmdPath, absConfigPath, absCachePath, o.OPMSandbox); err != nil {
+				return fmt.Errorf("error verifying the regenerated cache for %v: %v", ctlgRef, err)
+			}
+			if err := o.persistCatalogCache(cacheKey, absCachePath); err != nil {
+				klog.Warningf("unable to persist the cache of %v for later runs: %v", ctlgRef, err)
 			}
-			layersToAdd = append(layersToAdd, cacheLayerToAdd)
 		}
-
-		// Deleted layers must be added first in the slice
-		// so that the /configs and /tmp directories are deleted
-		// and then added back from the layers rebuilt from the new FBC.
-		layers := []v1.Layer{}
-		layers = append(layers, layersToDelete...)
-		layers = append(layers, layersToAdd...)
-
-		layoutDir := filepath.Join(artifactDir, config.LayoutsDir)
-		layoutPath, err = imgBuilder.CreateLayout("", layoutDir)
+		// Fix OCPBUGS-17546:
+		// Add the cache under /cache in a new layer (instead of white-out /tmp/cache, which resulted in crashLoopBackoff only on some clusters)
+		cacheLayerToAdd, err := builder.LayerFromPathWithUidGid("/cache", filepath.Join(artifactDir, config.TmpDir), cacheFolderUID, cacheFolderGID)
 		if err != nil {
-			return fmt.Errorf("error creating OCI layout: %v", err)
+			return fmt.Errorf("error creating add layer: %v", err)
 		}
+		layersToAdd = append(layersToAdd, cacheLayerToAdd)
+	}
 
-		update := func(cfg *v1.ConfigFile) {
-			labels := map[string]string{
-				containertools.ConfigsLocationLabel: "/configs",
-			}
-			cfg.Config.Labels = labels
-			// Although it was prefered to keep the entrypoint and command as it was
-			// we couldnt reuse /tmp/cache as the cache directory (OCPBUGS-17546)
-			if withCacheRegeneration {
-				cfg.Config.Cmd = []string{"serve", "/configs", "--cache-dir=/cache"}
-			} else { // this means that --build-catalog-cache flag was not used
-				// if the flag was used this means that no cache nor opm binary was found in the original catalog (old catalog with opm < 1.25)
-				cfg.Config.Cmd = []string{"serve", "/configs"}
-			}
+	// Deleted layers must be added first in the slice
+	// so that the /configs and /tmp directories are deleted
+	// and then added back from the layers rebuilt from the new FBC.
+	layers := []v1.Layer{}
+	layers = append(layers, layersToDelete...)
+	layers = append(layers, layersToAdd...)
+
+	layoutDir := filepath.Join(artifactDir, config.LayoutsDir)
+	layoutPath, err = imgBuilder.CreateLayout("", layoutDir)
+	if err != nil {
+		return fmt.Errorf("error creating OCI layout: %v", err)
+	}
+
+	update := func(cfg *v1.ConfigFile) {
+		labels := catalogProvenanceLabels(artifactDir, cfg.Created.Time)
+		labels[containertools.ConfigsLocationLabel] = "/configs"
+		cfg.Config.Labels = labels
+		// Although it was prefered to keep the entrypoint and command as it was
+		// we couldnt reuse /tmp/cache as the cache directory (OCPBUGS-17546)
+		if withCacheRegeneration {
+			cfg.Config.Cmd = []string{"serve", "/configs", "--cache-dir=/cache"}
+		} else { // this means that --build-catalog-cache flag was not used
+			// if the flag was used this means that no cache nor opm binary was found in the original catalog (old catalog with opm < 1.25)
+			cfg.Config.Cmd = []string{"serve", "/configs"}
 		}
-		if err := imgBuilder.Run(ctx, refExact, layoutPath, update, layers...); err != nil {
-			return fmt.Errorf("error building catalog layers: %v", err)
+	}
+	if err := o.ensureRepositories(ctx, ctlgRef.Ref); err != nil {
+		return err
+	}
+	if err := imgBuilder.Run(ctx, refExact, layoutPath, update, layers...); err != nil {
+		return diagnoseQuotaError(fmt.Errorf("error building catalog layers: %v", err))
+	}
+	return nil
+}
+
+// verifyCatalogCache runs opm against a regenerated cache with integrity
+// enforcement enabled. opm compares the digest stored in the cache with the
+// one computed from the declarative config, and fails when they differ,
+// which would otherwise only be noticed once the catalog pod crash loops.
+// With sandbox, opm runs sandboxed like it regenerated the cache.
+func verifyCatalogCache(opmCmdPath, configPath, cachePath string, sandbox bool) error {
+	cmd, err := opmCommand(opmCmdPath, sandbox, "serve", configPath, "--cache-dir", cachePath, "--cache-only", "--cache-enforce-integrity")
+	if err != nil {
+		return err
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
 		}
+		return fmt.Errorf("catalog cache in %s does not match the declarative config in %s: %s", cachePath, configPath, msg)
 	}
+	klog.V(1).Infof("Verified integrity of catalog cache %s", cachePath)
 	return nil
 }
 
@@ -638,6 +717,18 @@ func copyOPMBinary(img v1.Image, ctlgSrcDir string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	// copy the opm binaries built for other platforms shipped next to it, if any
+	extracted, err := copyPlatformOPMBinaries(filepath.Dir(realOpmBinPath), filepath.Dir(targetOpm))
+	if err != nil {
+		return "", err
+	}
+	// verify the binaries against the layers of the image before they may run,
+	// recording their checksums to verify them again when they run
+	extracted[filepath.Base(targetOpm)] = realOpmBinPath
+	err = recordOPMChecksums(img, filepath.Join(ctlgSrcDir, config.CtlgExtractionDir), filepath.Dir(targetOpm), extracted)
+	if err != nil {
+		return "", fmt.Errorf("error verifying the opm binary of the catalog image: %v", err)
+	}
 	// in order to avoid using too much space (case where several operator catalogs are included in the imagesetconfig)
 	// we clean up the extracted catalog right after having copied the opmBinary to its target location
 	err = os.RemoveAll(filepath.Join(ctlgSrcDir, config.CtlgExtractionDir))
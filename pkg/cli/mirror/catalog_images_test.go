@@ -0,0 +1,36 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCatalogCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		err    string
+	}{{
+		name:   "Valid/CacheMatches",
+		script: "#!/bin/sh\nexit 0\n",
+	}, {
+		name:   "Invalid/CacheDigestMismatch",
+		script: "#!/bin/sh\necho 'cache requires rebuild: cache reports digest as \"a\", but computed digest is \"b\"'\nexit 1\n",
+		err:    `catalog cache in cache does not match the declarative config in configs: cache requires rebuild: cache reports digest as "a", but computed digest is "b"`,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opm := filepath.Join(t.TempDir(), "opm")
+			require.NoError(t, os.WriteFile(opm, []byte(test.script), 0700))
+			err := verifyCatalogCache(opm, "configs", "cache", false)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
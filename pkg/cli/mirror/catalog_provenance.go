@@ -0,0 +1,86 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/image/builder"
+	"github.com/openshift/oc-mirror/pkg/operator"
+)
+
+// digestOfFilter returns the digest of the filter applied to the catalog
+// of ctlg: its configuration without the fields naming the target catalog.
+func digestOfFilter(ctlg v1alpha2.Operator) (string, error) {
+	ctlg.TargetName = ""
+	ctlg.TargetCatalog = ""
+	ctlg.TargetTag = ""
+	data, err := json.Marshal(ctlg)
+	if err != nil {
+		return "", err
+	}
+	return digest.FromBytes(data).String(), nil
+}
+
+// writeCatalogProvenance records the source catalog of ctlg, its digest and the
+// digest of its filter in the catalog directory of targetCtlg, where they are
+// read from to label the catalog rebuilt for targetCtlg.
+func (o *OperatorOptions) writeCatalogProvenance(ctx context.Context, ctlg v1alpha2.Operator, targetCtlg image.TypedImageReference) error {
+	sysContext := image.NewSystemContext(o.SourceSkipTLS || o.SourcePlainHTTP, o.OCIRegistriesConfig)
+	pin, err := catalogPin(ctx, sysContext, ctlg)
+	if err != nil {
+		return err
+	}
+	// Only keep the digest of pinned images.
+	if i := strings.LastIndex(pin, "@"); i != -1 {
+		pin = pin[i+1:]
+	}
+	filter, err := digestOfFilter(ctlg)
+	if err != nil {
+		return err
+	}
+	ctlgDir, err := operator.GenerateCatalogDir(targetCtlg.Ref)
+	if err != nil {
+		return err
+	}
+	return operator.WriteProvenance(filepath.Join(o.Dir, config.SourceDir, config.CatalogsDir, ctlgDir), operator.Provenance{
+		SourceCatalog: ctlg.Catalog,
+		SourceDigest:  pin,
+		FilterDigest:  filter,
+	})
+}
+
+// catalogProvenanceLabels returns the labels recording the provenance of
+// the catalog rebuilt from the catalog directory artifactDir on an image
+// created at created.
+func catalogProvenanceLabels(artifactDir string, created time.Time) map[string]string {
+	p, err := operator.ReadProvenance(artifactDir)
+	if err != nil {
+		// Catalogs planned by older versions record no provenance.
+		klog.V(1).Infof("no provenance recorded for catalog %s: %v", artifactDir, err)
+	}
+	p.Version = currentToolVersion
+	if buildTime := catalogBuildTime(created); !buildTime.IsZero() {
+		p.BuildTimestamp = buildTime.UTC().Format(time.RFC3339)
+	}
+	return p.Labels()
+}
+
+// catalogBuildTime returns the build time recorded in the labels of a
+// catalog rebuilt on an image created at created: the time set by
+// SOURCE_DATE_EPOCH, or else created, so rebuilding a catalog from the
+// same content gives the same image digest.
+func catalogBuildTime(created time.Time) time.Time {
+	if t, ok := builder.SourceDateEpoch(); ok {
+		return t
+	}
+	return created
+}
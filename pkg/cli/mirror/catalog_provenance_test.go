@@ -0,0 +1,74 @@
+package mirror
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image/builder"
+	"github.com/openshift/oc-mirror/pkg/operator"
+)
+
+func TestDigestOfFilter(t *testing.T) {
+	ctlg := v1alpha2.Operator{
+		Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+		IncludeConfig: v1alpha2.IncludeConfig{
+			Packages: []v1alpha2.IncludePackage{{Name: "foo"}},
+		},
+	}
+	exp, err := digestOfFilter(ctlg)
+	require.NoError(t, err)
+
+	t.Run("Valid/TargetIgnored", func(t *testing.T) {
+		target := ctlg
+		target.TargetCatalog = "internal/operators"
+		target.TargetTag = "v1"
+		actual, err := digestOfFilter(target)
+		require.NoError(t, err)
+		require.Equal(t, exp, actual)
+	})
+	t.Run("Valid/FilterChanged", func(t *testing.T) {
+		filter := ctlg
+		filter.Packages = []v1alpha2.IncludePackage{{Name: "bar"}}
+		actual, err := digestOfFilter(filter)
+		require.NoError(t, err)
+		require.NotEqual(t, exp, actual)
+	})
+}
+
+func TestCatalogProvenanceLabels(t *testing.T) {
+	defer func(version string) { currentToolVersion = version }(currentToolVersion)
+	currentToolVersion = "v4.17.0"
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Valid/Recorded", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, operator.WriteProvenance(dir, operator.Provenance{
+			SourceCatalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+			SourceDigest:  "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+			FilterDigest:  "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+		}))
+		labels := catalogProvenanceLabels(dir, created)
+		require.Equal(t, "sha256:1111111111111111111111111111111111111111111111111111111111111111", labels[operator.SourceDigestLabel])
+		require.Equal(t, "sha256:2222222222222222222222222222222222222222222222222222222222222222", labels[operator.FilterDigestLabel])
+		require.Equal(t, "v4.17.0", labels[operator.VersionLabel])
+		require.Equal(t, "2024-03-01T12:00:00Z", labels[operator.BuildTimestampLabel])
+	})
+	t.Run("Valid/NotRecorded", func(t *testing.T) {
+		labels := catalogProvenanceLabels(t.TempDir(), created)
+		require.Len(t, labels, 2)
+		require.Equal(t, "v4.17.0", labels[operator.VersionLabel])
+	})
+	t.Run("Valid/SourceDateEpoch", func(t *testing.T) {
+		t.Setenv(builder.SourceDateEpochEnv, "1700000000")
+		labels := catalogProvenanceLabels(t.TempDir(), created)
+		require.Equal(t, "2023-11-14T22:13:20Z", labels[operator.BuildTimestampLabel])
+	})
+	t.Run("Valid/NoCreationTime", func(t *testing.T) {
+		labels := catalogProvenanceLabels(t.TempDir(), time.Time{})
+		require.Len(t, labels, 1)
+		require.Empty(t, labels[operator.BuildTimestampLabel])
+	})
+}
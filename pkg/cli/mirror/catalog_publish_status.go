@@ -0,0 +1,179 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// catalogPublishStatus records the outcome of the last
+// publish of each rebuilt catalog, keyed by destination.
+type catalogPublishStatus struct {
+	Catalogs map[string]catalogPublishState `json:"catalogs"`
+}
+
+// catalogPublishState is the outcome of the last publish of a rebuilt catalog.
+type catalogPublishState struct {
+	// Fingerprint is the digest of the content the catalog was rebuilt from.
+	Fingerprint string `json:"fingerprint"`
+	// Digest is the digest the catalog was pushed with.
+	Digest string `json:"digest,omitempty"`
+	// Error is the error of the last attempt, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// catalogPublishStatusPath returns the path of the publish status of the catalogs.
+func (o *MirrorOptions) catalogPublishStatusPath() string {
+	return filepath.Join(o.Dir, config.CatalogPublishStatusFile)
+}
+
+// loadCatalogPublishStatus reads the publish status of the catalogs
+// recorded in the workspace, empty if there is none.
+func (o *MirrorOptions) loadCatalogPublishStatus() (*catalogPublishStatus, error) {
+	status := &catalogPublishStatus{Catalogs: map[string]catalogPublishState{}}
+	data, err := os.ReadFile(o.catalogPublishStatusPath())
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return status, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, status); err != nil {
+		return nil, fmt.Errorf("error reading catalog publish status %s: %v", o.catalogPublishStatusPath(), err)
+	}
+	if status.Catalogs == nil {
+		status.Catalogs = map[string]catalogPublishState{}
+	}
+	return status, nil
+}
+
+// saveCatalogPublishStatus writes status to the workspace. It is written to a
+// temporary file then renamed, so an interrupted write never loses the status.
+func (o *MirrorOptions) saveCatalogPublishStatus(status *catalogPublishStatus) error {
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return err
+	}
+	statusPath := o.catalogPublishStatusPath()
+	if err := os.MkdirAll(filepath.Dir(statusPath), 0750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(statusPath), config.CatalogPublishStatusFile+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), statusPath)
+}
+
+// catalogFingerprint returns the digest of the content the catalog of
+// artifactDir is rebuilt from: its declarative config, its provenance, the
+// location of its cache and the checksums of its opm binaries, and the
+// options changing the rebuilt image.
+func (o *MirrorOptions) catalogFingerprint(artifactDir string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "build-catalog-cache=%t\x00oci-media-types=%t\x00opm-binary=%s\x00", o.BuildCatalogCache, o.OCIMediaTypes, o.OPMBinary)
+	configPath := filepath.Join(artifactDir, config.IndexDir)
+	// WalkDir visits the files in lexical order, so the fingerprint is stable.
+	err := filepath.WalkDir(configPath, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(artifactDir, fpath)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		return hashFile(h, fpath)
+	})
+	if err != nil {
+		return "", fmt.Errorf("error hashing declarative config %s: %v", configPath, err)
+	}
+	for _, rel := range []string{
+		config.CatalogProvenanceFile,
+		config.OPMCacheLocationPlaceholder,
+		filepath.Join(config.OpmBinDir, opmChecksumsFile),
+	} {
+		fpath := filepath.Join(artifactDir, rel)
+		if _, err := os.Stat(fpath); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		if err := hashFile(h, fpath); err != nil {
+			return "", fmt.Errorf("error hashing %s: %v", fpath, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// destinationRegistryOpts returns the options of the requests resolving
+// and tagging images at the destination, such as the rebuilt catalogs.
+func (o *MirrorOptions) destinationRegistryOpts(ctx context.Context) []crane.Option {
+	insecure := o.DestPlainHTTP || o.DestSkipTLS
+	opts := []crane.Option{
+		crane.WithAuthFromKeychain(image.Keychain()),
+		crane.WithTransport(o.clockSkewMonitor().transport(createRT(insecure))),
+		crane.WithContext(ctx),
+	}
+	if insecure {
+		opts = append(opts, crane.Insecure)
+	}
+	return opts
+}
+
+// publishCatalog rebuilds the catalog ctlgRef from artifactDir and pushes
+// it, recording the outcome in status. A catalog pushed by a previous publish
+// from the same content is tagged again from its digest instead, as its tag
+// may have been replaced by the catalog image of the imageset. It is rebuilt
+// if the digest is gone from the destination.
+func (o *MirrorOptions) publishCatalog(ctx context.Context, status *catalogPublishStatus, ctlgRef image.TypedImage, artifactDir string) error {
+	key := ctlgRef.Ref.Exact()
+	fingerprint, err := o.catalogFingerprint(artifactDir)
+	if err != nil {
+		return err
+	}
+	opts := o.destinationRegistryOpts(ctx)
+	if prev, ok := status.Catalogs[key]; ok && prev.Fingerprint == fingerprint && prev.Digest != "" {
+		pushed := ctlgRef.Ref
+		pushed.Tag, pushed.ID = "", prev.Digest
+		err := crane.Tag(pushed.Exact(), ctlgRef.Ref.Tag, opts...)
+		if err == nil {
+			klog.Infof("Catalog %s was published by a previous run, tagged it again from %s", key, prev.Digest)
+			return nil
+		}
+		klog.Warningf("unable to tag catalog %s again from %s published by a previous run, rebuilding it: %v", key, prev.Digest, err)
+	}
+
+	state := catalogPublishState{Fingerprint: fingerprint}
+	publishErr := o.rebuildCatalog(ctx, ctlgRef, artifactDir)
+	if publishErr != nil {
+		state.Error = publishErr.Error()
+	} else if state.Digest, err = crane.Digest(key, opts...); err != nil {
+		// The catalog is rebuilt again by the next publish.
+		klog.Warningf("unable to resolve the digest of catalog %s: %v", key, err)
+	}
+	status.Catalogs[key] = state
+	if err := o.saveCatalogPublishStatus(status); err != nil {
+		klog.Warningf("unable to record the publish status of catalog %s: %v", key, err)
+	}
+	return publishErr
+}
@@ -0,0 +1,133 @@
+package mirror
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestPublishCatalog(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// The artifacts of the catalog hold its declarative config,
+	// and the layout of the catalog image of the imageset.
+	newArtifactDir := func(t *testing.T, img v1.Image) string {
+		artifactDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(artifactDir, config.IndexDir), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(artifactDir, config.IndexDir, "index.json"), []byte(`{"schema":"olm.package","name":"foo"}`), 0600))
+		layoutPath, err := layout.Write(filepath.Join(artifactDir, config.LayoutsDir), empty.Index)
+		require.NoError(t, err)
+		if img != nil {
+			require.NoError(t, layoutPath.AppendImage(img))
+		}
+		return artifactDir
+	}
+
+	newOpts := func(t *testing.T) *MirrorOptions {
+		return &MirrorOptions{
+			RootOptions:     &cli.RootOptions{Dir: t.TempDir()},
+			DestPlainHTTP:   true,
+			RebuildCatalogs: true,
+		}
+	}
+	pushImage := func(t *testing.T, ref string) (v1.Image, string) {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+		require.NoError(t, crane.Push(img, ref))
+		digest, err := img.Digest()
+		require.NoError(t, err)
+		return img, digest.String()
+	}
+	catalogRef := func(t *testing.T, ref string) image.TypedImage {
+		typed, err := image.ParseReference(ref)
+		require.NoError(t, err)
+		return image.TypedImage{TypedImageReference: image.TypedImageReference{Type: imagesource.DestinationRegistry, Ref: typed.Ref}}
+	}
+
+	t.Run("Valid/TaggedAgain", func(t *testing.T) {
+		ref := u.Host + "/redhat/redhat-operator-index:v4.14"
+		_, rebuilt := pushImage(t, ref)
+		// Publishing the images of the imageset replaced the rebuilt catalog.
+		_, original := pushImage(t, ref)
+		require.NotEqual(t, rebuilt, original)
+
+		artifactDir := newArtifactDir(t, nil)
+		o := newOpts(t)
+		fingerprint, err := o.catalogFingerprint(artifactDir)
+		require.NoError(t, err)
+		status := &catalogPublishStatus{Catalogs: map[string]catalogPublishState{
+			ref: {Fingerprint: fingerprint, Digest: rebuilt},
+		}}
+		require.NoError(t, o.publishCatalog(ctx, status, catalogRef(t, ref), artifactDir))
+		digest, err := crane.Digest(ref)
+		require.NoError(t, err)
+		require.Equal(t, rebuilt, digest)
+	})
+
+	t.Run("Valid/Rebuilt", func(t *testing.T) {
+		ref := u.Host + "/redhat/certified-operator-index:v4.14"
+		img, original := pushImage(t, ref)
+
+		artifactDir := newArtifactDir(t, img)
+		o := newOpts(t)
+		fingerprint, err := o.catalogFingerprint(artifactDir)
+		require.NoError(t, err)
+		// The catalog pushed by the previous run is gone, it is rebuilt.
+		status := &catalogPublishStatus{Catalogs: map[string]catalogPublishState{
+			ref: {Fingerprint: fingerprint, Digest: "sha256:0000000000000000000000000000000000000000000000000000000000000000"},
+		}}
+		require.NoError(t, o.publishCatalog(ctx, status, catalogRef(t, ref), artifactDir))
+		digest, err := crane.Digest(ref)
+		require.NoError(t, err)
+		require.NotEqual(t, original, digest)
+
+		saved, err := o.loadCatalogPublishStatus()
+		require.NoError(t, err)
+		require.Equal(t, catalogPublishState{Fingerprint: fingerprint, Digest: digest}, saved.Catalogs[ref])
+	})
+
+	t.Run("Invalid/Failed", func(t *testing.T) {
+		ref := u.Host + "/redhat/community-operator-index:v4.14"
+		artifactDir := newArtifactDir(t, nil)
+		o := newOpts(t)
+		status := &catalogPublishStatus{Catalogs: map[string]catalogPublishState{}}
+		err := o.publishCatalog(ctx, status, catalogRef(t, ref), artifactDir)
+		require.Error(t, err)
+
+		saved, err := o.loadCatalogPublishStatus()
+		require.NoError(t, err)
+		require.NotEmpty(t, saved.Catalogs[ref].Error)
+		require.Empty(t, saved.Catalogs[ref].Digest)
+	})
+
+	t.Run("Valid/FingerprintChanged", func(t *testing.T) {
+		artifactDir := newArtifactDir(t, nil)
+		o := newOpts(t)
+		before, err := o.catalogFingerprint(artifactDir)
+		require.NoError(t, err)
+		o.BuildCatalogCache = true
+		after, err := o.catalogFingerprint(artifactDir)
+		require.NoError(t, err)
+		require.NotEqual(t, before, after)
+	})
+}
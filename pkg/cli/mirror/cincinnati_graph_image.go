@@ -24,6 +24,7 @@ import (
 	"github.com/openshift/oc-mirror/pkg/config"
 	"github.com/openshift/oc-mirror/pkg/image"
 	"github.com/openshift/oc-mirror/pkg/image/builder"
+	"github.com/openshift/oc-mirror/pkg/network"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -89,6 +90,7 @@ func (o *MirrorOptions) buildGraphImage(ctx context.Context, srcSignatureDir str
 	graphImage.Ref.Name = "graph-image"
 
 	imgBuilder := builder.NewImageBuilder(nameOpts, remoteOpts)
+	imgBuilder.OCIMediaTypes = o.OCIMediaTypes
 	layoutDir := filepath.Join(dstDir, "layout")
 
 	// unpack graph data archive and build image
@@ -120,6 +122,9 @@ func (o *MirrorOptions) buildGraphImage(ctx context.Context, srcSignatureDir str
 	if err != nil {
 		return refs, fmt.Errorf("error creating OCI layout: %v", err)
 	}
+	if err := o.ensureRepositories(ctx, graphImage.Ref); err != nil {
+		return refs, err
+	}
 	if err := imgBuilder.Run(ctx, graphImage.Ref.Exact(), layoutPath, update, add); err != nil {
 		return refs, nil
 	}
@@ -135,7 +140,7 @@ func (o *MirrorOptions) buildGraphImage(ctx context.Context, srcSignatureDir str
 
 	// Resolve the image's digest for UpdateService manifest creation
 	for source, dest := range refs {
-		destRef, err := image.ResolveToPin(ctx, sysContext, dest.Ref.Exact())
+		destRef, err := o.resolvePushedImage(ctx, sysContext, dest.Ref.Exact())
 		if err != nil {
 			return nil, fmt.Errorf("error retrieving digest for graph image %q: %v", dest.Ref.Exact(), err)
 		}
@@ -150,8 +155,9 @@ func (o *MirrorOptions) buildGraphImage(ctx context.Context, srcSignatureDir str
 	return refs, nil
 }
 
-// downloadsGraphData will download the current Cincinnati graph data
-func downloadGraphData(ctx context.Context, dir string) error {
+// downloadsGraphData will download the current Cincinnati graph data from
+// dataURL, graphURL when empty, or copy it from the archive of a file:// dataURL.
+func downloadGraphData(ctx context.Context, dir, dataURL string) error {
 	// TODO(jpower432): It would be helpful to validate
 	// the source of this downloaded file before processing
 	// it further
@@ -162,19 +168,28 @@ func downloadGraphData(ctx context.Context, dir string) error {
 	}
 	defer out.Close()
 
-	req, err := http.NewRequest("GET", graphURL, nil)
-	if err != nil {
+	if dataURL == "" {
+		dataURL = graphURL
+	}
+	if strings.HasPrefix(dataURL, "file://") {
+		in, err := os.Open(strings.TrimPrefix(dataURL, "file://"))
+		if err != nil {
+			return fmt.Errorf("error reading graph data archive: %v", err)
+		}
+		defer in.Close()
+		_, err = io.Copy(out, in)
 		return err
 	}
 
-	client := http.Client{}
-	tls, err := getTLSConfig()
+	req, err := http.NewRequest("GET", dataURL, nil)
 	if err != nil {
 		return err
 	}
+
+	client := http.Client{}
 	transport := &http.Transport{
-		TLSClientConfig: tls,
-		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: network.TLSConfig(false),
+		Proxy:           network.Proxy,
 	}
 	client.Transport = transport
 	timeoutCtx, cancel := context.WithTimeout(ctx, getDataTimeout)
@@ -0,0 +1,265 @@
+package mirror
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+// consoleCLIDownloadKind is the kind of the console.openshift.io objects
+// declaring the client binaries of an operator in the web console.
+const consoleCLIDownloadKind = "ConsoleCLIDownload"
+
+// consoleCLIDownload holds the fields of a ConsoleCLIDownload
+// needed to download the client binaries it links to.
+type consoleCLIDownload struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Links []struct {
+			Href string `json:"href"`
+			Text string `json:"text"`
+		} `json:"links"`
+	} `json:"spec"`
+
+	raw []byte
+}
+
+// extractCLIDownloads downloads the client binaries declared by the
+// ConsoleCLIDownload objects of the bundles of the packages of ctlg that set
+// cliDownloads. Each bundle gets a <package>/<bundle> directory under the CLI
+// downloads directory of the workspace, holding the ConsoleCLIDownload manifests
+// and the binaries they link to. Links that cannot be downloaded, such as links
+// to routes of the cluster running the operator, are skipped with a warning.
+func (o *OperatorOptions) extractCLIDownloads(ctx context.Context, ctlg v1alpha2.Operator, dc *declcfg.DeclarativeConfig) error {
+	selected := map[string]bool{}
+	for _, pkg := range ctlg.Packages {
+		if pkg.CLIDownloads {
+			selected[pkg.Name] = true
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+
+	client := &http.Client{Transport: network.NewTransport(o.SourceSkipTLS)}
+	for _, b := range dc.Bundles {
+		if !selected[b.Package] {
+			continue
+		}
+		downloads, err := o.bundleCLIDownloads(ctx, b)
+		if err != nil {
+			return fmt.Errorf("error reading CLI downloads of bundle %s: %v", b.Name, err)
+		}
+		if len(downloads) == 0 {
+			klog.V(1).Infof("Bundle %s does not declare CLI downloads", b.Name)
+			continue
+		}
+		dir := filepath.Join(o.Dir, config.SourceDir, config.CLIDownloadsDir, b.Package, b.Name)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+		for _, download := range downloads {
+			manifestPath := filepath.Join(dir, download.Metadata.Name+".yaml")
+			if err := os.WriteFile(manifestPath, download.raw, 0600); err != nil {
+				return err
+			}
+			for _, link := range download.Spec.Links {
+				klog.Infof("Downloading %s of bundle %s", link.Text, b.Name)
+				if err := downloadCLIArtifact(ctx, client, link.Href, dir); err != nil {
+					klog.Warningf("skipping CLI download %q of bundle %s: %v", link.Href, b.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// bundleCLIDownloads returns the ConsoleCLIDownload objects of bundle b.
+func (o *OperatorOptions) bundleCLIDownloads(ctx context.Context, b declcfg.Bundle) ([]consoleCLIDownload, error) {
+	manifests, err := o.bundleManifests(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	var downloads []consoleCLIDownload
+	for _, data := range manifests {
+		found, err := parseCLIDownloads(data)
+		if err != nil {
+			return nil, err
+		}
+		downloads = append(downloads, found...)
+	}
+	return downloads, nil
+}
+
+// bundleManifests returns the manifests of bundle b, read from its
+// olm.bundle.object properties when the catalog has them, otherwise
+// from the manifests directory of the bundle image.
+func (o *OperatorOptions) bundleManifests(ctx context.Context, b declcfg.Bundle) ([][]byte, error) {
+	props, err := property.Parse(b.Properties)
+	if err != nil {
+		return nil, err
+	}
+	if len(props.BundleObjects) != 0 {
+		manifests := make([][]byte, 0, len(props.BundleObjects))
+		for _, obj := range props.BundleObjects {
+			manifests = append(manifests, obj.Data)
+		}
+		return manifests, nil
+	}
+
+	ref, err := name.ParseReference(b.Image, getNameOpts(o.insecure)...)
+	if err != nil {
+		return nil, err
+	}
+	img, err := remote.Image(ref, getRemoteOpts(ctx, o.insecure)...)
+	if err != nil {
+		return nil, err
+	}
+	rc := mutate.Extract(img)
+	defer rc.Close()
+	return readBundleManifests(rc)
+}
+
+// readBundleManifests returns the YAML and JSON files of the
+// manifests directory of the bundle image filesystem tarball r.
+func readBundleManifests(r io.Reader) ([][]byte, error) {
+	var manifests [][]byte
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return manifests, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		fpath := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if hdr.Typeflag != tar.TypeReg || path.Dir(fpath) != "manifests" {
+			continue
+		}
+		switch path.Ext(fpath) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, data)
+	}
+}
+
+// parseCLIDownloads returns the ConsoleCLIDownload objects
+// of the YAML or JSON documents in data.
+func parseCLIDownloads(data []byte) ([]consoleCLIDownload, error) {
+	var downloads []consoleCLIDownload
+	dec := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return downloads, nil
+			}
+			return nil, err
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		var download consoleCLIDownload
+		if err := json.Unmarshal(raw, &download); err != nil {
+			// Not an object, it cannot be a ConsoleCLIDownload.
+			continue
+		}
+		if download.Kind != consoleCLIDownloadKind || download.Metadata.Name == "" {
+			continue
+		}
+		manifest, err := sigsyaml.JSONToYAML(raw)
+		if err != nil {
+			return nil, err
+		}
+		download.raw = manifest
+		downloads = append(downloads, download)
+	}
+}
+
+// downloadCLIArtifact downloads href to dir, naming
+// the file after the last element of the URL path.
+func downloadCLIArtifact(ctx context.Context, client *http.Client, href, dir string) error {
+	u, err := url.Parse(href)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("not an http or https URL")
+	}
+	fileName := path.Base(u.Path)
+	if fileName == "/" || fileName == "." {
+		return fmt.Errorf("no file name in URL path")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, href, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	f, err := os.Create(filepath.Join(dir, fileName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		// Do not leave a truncated binary behind.
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return f.Sync()
+}
+
+// unpackCLIDownloads will unpack the operator CLI downloads if they exist
+func (o *MirrorOptions) unpackCLIDownloads(dstDir string, filesInArchive map[string]string) error {
+	if err := unpack(config.CLIDownloadsDir, dstDir, filesInArchive); err != nil {
+		nferr := &ErrArchiveFileNotFound{}
+		if errors.As(err, &nferr) || errors.Is(err, os.ErrNotExist) {
+			klog.V(2).Infof("No operator CLI downloads found in archive, skipping")
+			return nil
+		}
+		return err
+	}
+	klog.Infof("Wrote operator CLI downloads to %s", dstDir)
+	return nil
+}
@@ -0,0 +1,145 @@
+package mirror
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+const testCLIDownload = `apiVersion: console.openshift.io/v1
+kind: ConsoleCLIDownload
+metadata:
+  name: tkn
+spec:
+  displayName: tkn - Tekton CLI
+  links:
+  - href: %s/tkn/tkn-linux-amd64.tar.gz
+    text: Download tkn for Linux for x86_64
+  - href: %s/tkn/missing.tar.gz
+    text: Download tkn for Mac
+`
+
+func TestParseCLIDownloads(t *testing.T) {
+	data := []byte(`apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: openshift-pipelines-operator-rh.v1.14.0
+---
+apiVersion: console.openshift.io/v1
+kind: ConsoleCLIDownload
+metadata:
+  name: tkn
+spec:
+  links:
+  - href: https://mirror.openshift.com/pub/openshift-v4/clients/pipeline/tkn-linux-amd64.tar.gz
+    text: Download tkn for Linux
+`)
+	downloads, err := parseCLIDownloads(data)
+	require.NoError(t, err)
+	require.Len(t, downloads, 1)
+	require.Equal(t, "tkn", downloads[0].Metadata.Name)
+	require.Len(t, downloads[0].Spec.Links, 1)
+	require.Equal(t, "https://mirror.openshift.com/pub/openshift-v4/clients/pipeline/tkn-linux-amd64.tar.gz", downloads[0].Spec.Links[0].Href)
+	require.Contains(t, string(downloads[0].raw), "kind: ConsoleCLIDownload")
+}
+
+func TestExtractCLIDownloads(t *testing.T) {
+	binary := []byte("tkn binary")
+	files := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tkn/tkn-linux-amd64.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(binary)
+	}))
+	t.Cleanup(files.Close)
+	manifest := []byte(fmt.Sprintf(testCLIDownload, files.URL, files.URL))
+
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// The bundle image holds the ConsoleCLIDownload in its manifests directory.
+	var layer bytes.Buffer
+	tw := tar.NewWriter(&layer)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "manifests/tkn-cli.yaml", Mode: 0644, Size: int64(len(manifest)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write(manifest)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	img, err := mutate.AppendLayers(empty.Image, static.NewLayer(layer.Bytes(), types.OCIUncompressedLayer))
+	require.NoError(t, err)
+	bundleImage := u.Host + "/pipelines/bundle:v1.14.0"
+	ref, err := name.ParseReference(bundleImage)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	dc := &declcfg.DeclarativeConfig{
+		Bundles: []declcfg.Bundle{
+			{
+				Name:    "openshift-pipelines-operator-rh.v1.14.0",
+				Package: "openshift-pipelines-operator-rh",
+				Image:   bundleImage,
+			},
+			{
+				// The catalog holds the ConsoleCLIDownload of this bundle.
+				Name:       "openshift-pipelines-operator-rh.v1.13.0",
+				Package:    "openshift-pipelines-operator-rh",
+				Image:      u.Host + "/pipelines/bundle:missing",
+				Properties: []property.Property{property.MustBuildBundleObject(manifest)},
+			},
+			{
+				Name:    "other-operator.v1.0.0",
+				Package: "other-operator",
+				Image:   u.Host + "/other/bundle:missing",
+			},
+		},
+	}
+	ctlg := v1alpha2.Operator{
+		IncludeConfig: v1alpha2.IncludeConfig{
+			Packages: []v1alpha2.IncludePackage{
+				{Name: "openshift-pipelines-operator-rh", CLIDownloads: true},
+				{Name: "other-operator"},
+			},
+		},
+	}
+
+	dir := t.TempDir()
+	opts := NewOperatorOptions(&MirrorOptions{
+		RootOptions:     &cli.RootOptions{Dir: dir},
+		SourcePlainHTTP: true,
+	})
+	require.NoError(t, opts.extractCLIDownloads(context.Background(), ctlg, dc))
+
+	for _, bundle := range []string{"openshift-pipelines-operator-rh.v1.14.0", "openshift-pipelines-operator-rh.v1.13.0"} {
+		bundleDir := filepath.Join(dir, config.SourceDir, config.CLIDownloadsDir, "openshift-pipelines-operator-rh", bundle)
+		got, err := os.ReadFile(filepath.Join(bundleDir, "tkn-linux-amd64.tar.gz"))
+		require.NoError(t, err)
+		require.Equal(t, binary, got)
+		require.FileExists(t, filepath.Join(bundleDir, "tkn.yaml"))
+		require.NoFileExists(t, filepath.Join(bundleDir, "missing.tar.gz"))
+	}
+	require.NoDirExists(t, filepath.Join(dir, config.SourceDir, config.CLIDownloadsDir, "other-operator"))
+}
@@ -0,0 +1,305 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/distribution/distribution/v3/registry/api/errcode"
+	"github.com/distribution/distribution/v3/registry/client"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+// maxClockSkew is the largest difference between the local clock and a registry
+// clock tolerated before tokens issued by the registry are likely to be rejected.
+// Registry tokens commonly live for 5 minutes, so half of it is already risky.
+const maxClockSkew = 2 * time.Minute
+
+// maxTokenResponseSize bounds the token responses inspected for claims.
+const maxTokenResponseSize = 64 * 1024
+
+// clockSkewMonitor inspects the responses of the registries of a run for signs
+// of clock skew between this host and the registries, and records the
+// registries rejecting the credentials of a request.
+type clockSkewMonitor struct {
+	now          func() time.Time // local clock
+	mu           sync.Mutex
+	warned       map[string]bool // registries a clock skew warning was logged for
+	unauthorized map[string]bool // registries that answered 401 to an authenticated request
+}
+
+// newClockSkewMonitor returns a monitor comparing the registry clocks
+// to now, or to time.Now if now is nil.
+func newClockSkewMonitor(now func() time.Time) *clockSkewMonitor {
+	if now == nil {
+		now = time.Now
+	}
+	return &clockSkewMonitor{now: now, warned: map[string]bool{}, unauthorized: map[string]bool{}}
+}
+
+// clockSkewMonitor returns the clock skew monitor of the run, created on first use.
+func (o *MirrorOptions) clockSkewMonitor() *clockSkewMonitor {
+	if o.clockSkew == nil {
+		o.clockSkew = newClockSkewMonitor(o.clock)
+	}
+	return o.clockSkew
+}
+
+// transport returns a round tripper inspecting the responses of next:
+// the Date header of every response and the iat/exp claims of the
+// tokens issued by the registry token services.
+func (m *clockSkewMonitor) transport(next http.RoundTripper) http.RoundTripper {
+	return &clockSkewTransport{next: next, monitor: m}
+}
+
+type clockSkewTransport struct {
+	next    http.RoundTripper
+	monitor *clockSkewMonitor
+}
+
+func (t *clockSkewTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	m := t.monitor
+	if skew, ok := clockSkew(resp, m.now()); ok && exceedsClockSkew(skew) {
+		m.warn(req.URL.Host, clockSkewMessage(req.URL.Host, skew))
+	}
+	// Unauthenticated requests are answered with a 401 challenge,
+	// only the rejection of credentials is a failure.
+	if resp.StatusCode == http.StatusUnauthorized && req.Header.Get("Authorization") != "" {
+		m.mu.Lock()
+		m.unauthorized[req.URL.Host] = true
+		m.mu.Unlock()
+	}
+	if isTokenResponse(resp) {
+		if err := m.checkTokenResponse(resp, req.URL.Host); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (m *clockSkewMonitor) warn(host, msg string) {
+	m.mu.Lock()
+	warned := m.warned[host]
+	m.warned[host] = true
+	m.mu.Unlock()
+	if !warned {
+		klog.Warning(msg)
+	}
+}
+
+// rejectedCredentials returns whether registry answered
+// 401 Unauthorized to an authenticated request.
+func (m *clockSkewMonitor) rejectedCredentials(registry string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.unauthorized[registry]
+}
+
+// clockSkew returns the difference between the local clock and the registry
+// clock, as reported by the Date header of resp. A positive value means the
+// local clock is ahead of the registry.
+func clockSkew(resp *http.Response, local time.Time) (time.Duration, bool) {
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return 0, false
+	}
+	remote, err := http.ParseTime(date)
+	if err != nil {
+		return 0, false
+	}
+	// The Date header has a one second resolution and is set before
+	// the response is sent, allow for it.
+	return local.Sub(remote).Truncate(time.Second), true
+}
+
+func exceedsClockSkew(skew time.Duration) bool {
+	return skew > maxClockSkew || skew < -maxClockSkew
+}
+
+func clockSkewMessage(host string, skew time.Duration) string {
+	direction := "ahead of"
+	if skew < 0 {
+		direction, skew = "behind", -skew
+	}
+	return fmt.Sprintf("the local clock is %s %s registry %s, tokens issued by the registry may be rejected "+
+		"as expired or not yet valid and surface as 401 Unauthorized errors: synchronize the clock of this host "+
+		"(e.g. with chronyd or timedatectl) before mirroring", skew, direction, host)
+}
+
+// isTokenResponse returns true if resp looks like a response of a registry token service.
+func isTokenResponse(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK || resp.Request == nil {
+		return false
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return false
+	}
+	if resp.ContentLength > maxTokenResponseSize {
+		return false
+	}
+	q := resp.Request.URL.Query()
+	return q.Has("service") || q.Has("scope") || strings.HasSuffix(resp.Request.URL.Path, "/token")
+}
+
+// checkTokenResponse reads the token in resp, restoring its body, and warns if
+// the claims of the token show that the local clock is off.
+func (m *clockSkewMonitor) checkTokenResponse(resp *http.Response, host string) error {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseSize))
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	var tr struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &tr); err != nil {
+		return nil
+	}
+	token := tr.Token
+	if token == "" {
+		token = tr.AccessToken
+	}
+	if msg := checkTokenClaims(token, host, m.now()); msg != "" {
+		m.warn(host, msg)
+	}
+	return nil
+}
+
+// checkTokenClaims returns a diagnostic if the claims of a JWT token are
+// inconsistent with the local clock, or an empty string otherwise.
+// Tokens that are not JWTs carry no claims and are ignored.
+func checkTokenClaims(token, host string, local time.Time) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		IssuedAt  int64 `json:"iat"`
+		NotBefore int64 `json:"nbf"`
+		Expiry    int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	switch {
+	case claims.Expiry != 0 && !local.Before(time.Unix(claims.Expiry, 0)):
+		return fmt.Sprintf("registry %s issued a token that already expired at %s according to the local clock (%s): %s",
+			host, time.Unix(claims.Expiry, 0).UTC().Format(time.RFC3339), local.UTC().Format(time.RFC3339),
+			"synchronize the clock of this host (e.g. with chronyd or timedatectl) before mirroring")
+	case claims.IssuedAt != 0 && exceedsClockSkew(local.Sub(time.Unix(claims.IssuedAt, 0))):
+		return clockSkewMessage(host, local.Sub(time.Unix(claims.IssuedAt, 0)).Truncate(time.Second))
+	case claims.NotBefore != 0 && local.Add(maxClockSkew).Before(time.Unix(claims.NotBefore, 0)):
+		return clockSkewMessage(host, local.Sub(time.Unix(claims.NotBefore, 0)).Truncate(time.Second))
+	}
+	return ""
+}
+
+// measureClockSkew queries the API root of registry and returns the clock skew
+// reported by its Date header.
+func (m *clockSkewMonitor) measureClockSkew(ctx context.Context, registry string, insecure bool) (time.Duration, bool) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	schemes := []string{"https"}
+	if insecure {
+		schemes = append(schemes, "http")
+	}
+	// Bypass the clock skew transport, the skew is reported by the caller.
+	rt := network.RouteRegistries(newTransport(insecure))
+	for _, scheme := range schemes {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s/v2/", scheme, registry), nil)
+		if err != nil {
+			return 0, false
+		}
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		return clockSkew(resp, m.now())
+	}
+	return 0, false
+}
+
+// diagnoseAuthError adds a clock skew diagnostic to err if err is an
+// authentication failure, or one of registries rejected the credentials of
+// a request, and the clock of one of registries is off.
+func (m *clockSkewMonitor) diagnoseAuthError(ctx context.Context, err error, registries []string, insecure bool) error {
+	if err == nil {
+		return err
+	}
+	unauthorized := isUnauthorizedError(err)
+	for _, registry := range registries {
+		unauthorized = unauthorized || m.rejectedCredentials(registry)
+	}
+	if !unauthorized {
+		return err
+	}
+	for _, registry := range registries {
+		if skew, ok := m.measureClockSkew(ctx, registry, insecure); ok && exceedsClockSkew(skew) {
+			return fmt.Errorf("%w: %s", err, clockSkewMessage(registry, skew))
+		}
+	}
+	return err
+}
+
+// isUnauthorizedError returns whether err, or one of the errors it
+// aggregates, carries a 401 Unauthorized status of a registry.
+func isUnauthorizedError(err error) bool {
+	var agg utilerrors.Aggregate
+	if errors.As(err, &agg) {
+		for _, e := range agg.Errors() {
+			if isUnauthorizedError(e) {
+				return true
+			}
+		}
+		return false
+	}
+	var codeErrs errcode.Errors
+	if errors.As(err, &codeErrs) {
+		for _, e := range codeErrs {
+			if isUnauthorizedError(e) {
+				return true
+			}
+		}
+		return false
+	}
+	var transportErr *transport.Error
+	var httpErr *client.UnexpectedHTTPResponseError
+	var codeErr errcode.Error
+	var credsErr docker.ErrUnauthorizedForCredentials
+	switch {
+	case errors.As(err, &transportErr):
+		return transportErr.StatusCode == http.StatusUnauthorized
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode == http.StatusUnauthorized
+	case errors.As(err, &codeErr):
+		return codeErr.Code.Descriptor().HTTPStatusCode == http.StatusUnauthorized
+	case errors.As(err, &credsErr):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package mirror
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3/registry/api/errcode"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/stretchr/testify/require"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+func TestClockSkew(t *testing.T) {
+	local := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+
+	type spec struct {
+		name    string
+		date    string
+		expSkew time.Duration
+		expOk   bool
+		exceeds bool
+	}
+
+	specs := []spec{
+		{
+			name:  "Valid/InSync",
+			date:  local.Format(http.TimeFormat),
+			expOk: true,
+		},
+		{
+			name:    "Valid/LocalAhead",
+			date:    local.Add(-10 * time.Minute).Format(http.TimeFormat),
+			expSkew: 10 * time.Minute,
+			expOk:   true,
+			exceeds: true,
+		},
+		{
+			name:    "Valid/LocalBehind",
+			date:    local.Add(time.Hour).Format(http.TimeFormat),
+			expSkew: -time.Hour,
+			expOk:   true,
+			exceeds: true,
+		},
+		{
+			name: "Invalid/NoDate",
+		},
+		{
+			name: "Invalid/MalformedDate",
+			date: "yesterday",
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if s.date != "" {
+				resp.Header.Set("Date", s.date)
+			}
+			skew, ok := clockSkew(resp, local)
+			require.Equal(t, s.expOk, ok)
+			require.Equal(t, s.expSkew, skew)
+			require.Equal(t, s.exceeds, exceedsClockSkew(skew))
+		})
+	}
+}
+
+func TestCheckTokenClaims(t *testing.T) {
+	local := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	jwt := func(claims string) string {
+		return "e30." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2ln"
+	}
+
+	type spec struct {
+		name   string
+		token  string
+		expMsg string
+	}
+
+	specs := []spec{
+		{
+			name:  "Valid/InSync",
+			token: jwt(fmt.Sprintf(`{"iat":%d,"exp":%d}`, local.Unix(), local.Add(5*time.Minute).Unix())),
+		},
+		{
+			name:  "Valid/NotJWT",
+			token: "opaque",
+		},
+		{
+			name:   "Invalid/Expired",
+			token:  jwt(fmt.Sprintf(`{"iat":%d,"exp":%d}`, local.Add(-time.Hour).Unix(), local.Add(-55*time.Minute).Unix())),
+			expMsg: "registry reg.com issued a token that already expired at 2023-11-14T21:18:20Z according to the local clock (2023-11-14T22:13:20Z)",
+		},
+		{
+			name:   "Invalid/IssuedInTheFuture",
+			token:  jwt(fmt.Sprintf(`{"iat":%d,"exp":%d}`, local.Add(time.Hour).Unix(), local.Add(65*time.Minute).Unix())),
+			expMsg: "the local clock is 1h0m0s behind registry reg.com",
+		},
+		{
+			name:   "Invalid/NotYetValid",
+			token:  jwt(fmt.Sprintf(`{"nbf":%d}`, local.Add(time.Hour).Unix())),
+			expMsg: "the local clock is 1h0m0s behind registry reg.com",
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			msg := checkTokenClaims(s.token, "reg.com", local)
+			if s.expMsg == "" {
+				require.Empty(t, msg)
+			} else {
+				require.True(t, strings.HasPrefix(msg, s.expMsg), msg)
+			}
+		})
+	}
+}
+
+func TestDiagnoseAuthError(t *testing.T) {
+	local := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", local.Add(-time.Hour).Format(http.TimeFormat))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+	registry := strings.TrimPrefix(server.URL, "http://")
+	clock := func() time.Time { return local }
+
+	t.Run("Valid/UnauthorizedWithSkew", func(t *testing.T) {
+		m := newClockSkewMonitor(clock)
+		err := m.diagnoseAuthError(context.Background(), &transport.Error{StatusCode: http.StatusUnauthorized}, []string{registry}, true)
+		require.ErrorContains(t, err, "the local clock is 1h0m0s ahead of registry "+registry)
+	})
+	t.Run("Valid/RejectedCredentials", func(t *testing.T) {
+		m := newClockSkewMonitor(clock)
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/v2/foo/manifests/latest", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := m.transport(http.DefaultTransport).RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		err = m.diagnoseAuthError(context.Background(), errors.New("one or more errors occurred"), []string{registry}, true)
+		require.EqualError(t, err, "one or more errors occurred: "+clockSkewMessage(registry, time.Hour))
+	})
+	t.Run("Valid/Challenge", func(t *testing.T) {
+		m := newClockSkewMonitor(clock)
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/v2/", nil)
+		require.NoError(t, err)
+		resp, err := m.transport(http.DefaultTransport).RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		err = m.diagnoseAuthError(context.Background(), errors.New("one or more errors occurred"), []string{registry}, true)
+		require.EqualError(t, err, "one or more errors occurred")
+	})
+	t.Run("Valid/OtherError", func(t *testing.T) {
+		m := newClockSkewMonitor(clock)
+		err := m.diagnoseAuthError(context.Background(), errors.New("blob sha256:4012aa unknown"), []string{registry}, true)
+		require.EqualError(t, err, "blob sha256:4012aa unknown")
+	})
+}
+
+func TestIsUnauthorizedError(t *testing.T) {
+	type spec struct {
+		name string
+		err  error
+		exp  bool
+	}
+	specs := []spec{
+		{name: "Valid/TransportError", err: fmt.Errorf("pushing: %w", &transport.Error{StatusCode: http.StatusUnauthorized}), exp: true},
+		{name: "Valid/ErrorCode", err: errcode.Errors{errcode.ErrorCodeUnauthorized.WithMessage("authentication required")}, exp: true},
+		{name: "Valid/Aggregate", err: utilerrors.NewAggregate([]error{errors.New("manifest unknown"), &transport.Error{StatusCode: http.StatusUnauthorized}}), exp: true},
+		{name: "Invalid/Forbidden", err: &transport.Error{StatusCode: http.StatusForbidden}},
+		{name: "Invalid/StatusInMessage", err: errors.New("unexpected status 401")},
+	}
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			require.Equal(t, s.exp, isUnauthorizedError(s.err))
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/openshift/library-go/pkg/image/reference"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// googleAccessTokenEnv is the environment variable holding the OAuth access
+// token of the Artifact Registry API used by --create-repos, as printed by
+// gcloud auth print-access-token.
+const googleAccessTokenEnv = "GOOGLE_OAUTH_ACCESS_TOKEN"
+
+var (
+	// ecrRegistryRegexp matches the private ECR registries,
+	// <account>.dkr.ecr.<region>.amazonaws.com.
+	ecrRegistryRegexp = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+	// garRegistryRegexp matches the Artifact Registry registries,
+	// <location>-docker.pkg.dev.
+	garRegistryRegexp = regexp.MustCompile(`^([a-z0-9-]+)-docker\.pkg\.dev$`)
+	// pushCreatesRepositoriesRegexp matches the registries creating
+	// repositories on push: Container Registry and Azure Container Registry.
+	pushCreatesRepositoriesRegexp = regexp.MustCompile(`^(?:[a-z]+\.)?gcr\.io$|\.azurecr\.(?:io|cn|us)$`)
+)
+
+var (
+	// garAPIURL is the endpoint of the Artifact Registry API.
+	garAPIURL = "https://artifactregistry.googleapis.com"
+	// garOperationInterval is the interval between two polls of the
+	// operation creating an Artifact Registry repository.
+	garOperationInterval = time.Second
+)
+
+// garOperationTimeout bounds the wait for the creation of an Artifact Registry repository.
+const garOperationTimeout = 2 * time.Minute
+
+// repositoryCreator creates the repositories of a registry that
+// does not create them on push.
+type repositoryCreator interface {
+	// ensureRepository creates the repository repo, such as ns/name,
+	// if it does not exist.
+	ensureRepository(ctx context.Context, repo string) error
+}
+
+// newRepositoryCreator returns the repositoryCreator of the cloud registry
+// registry for --create-repos, nil if registry creates repositories on push
+// or is not a supported cloud registry.
+func newRepositoryCreator(registry string) (repositoryCreator, error) {
+	host := strings.ToLower(registry)
+	switch {
+	case ecrRegistryRegexp.MatchString(host):
+		m := ecrRegistryRegexp.FindStringSubmatch(host)
+		sess, err := session.NewSessionWithOptions(session.Options{
+			Config:            aws.Config{Region: aws.String(m[2])},
+			SharedConfigState: session.SharedConfigEnable,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error loading the AWS configuration: %v", err)
+		}
+		return newECRClient(ecr.New(sess), m[1]), nil
+	case garRegistryRegexp.MatchString(host):
+		token := os.Getenv(googleAccessTokenEnv)
+		if token == "" {
+			return nil, NewClassifiedError(ErrorClassAuth, fmt.Errorf("creating the repositories of %s requires an OAuth access token of the Artifact Registry API in %s", registry, googleAccessTokenEnv))
+		}
+		return newGARClient(garAPIURL, garRegistryRegexp.FindStringSubmatch(host)[1], token), nil
+	case pushCreatesRepositoriesRegexp.MatchString(host):
+		klog.V(1).Infof("Registry %s creates repositories on push, no repository to create", registry)
+		return nil, nil
+	}
+	klog.Warningf("--create-repos does not support registry %s, its repositories must exist or be created on push", registry)
+	return nil, nil
+}
+
+// ecrClient creates the repositories of a private ECR registry with
+// the ECR API. ECR rejects pushes to repositories that do not exist.
+type ecrClient struct {
+	api        ecriface.ECRAPI
+	registryID string
+	// ensured holds the repositories known to exist.
+	ensured sets.Set[string]
+}
+
+func newECRClient(api ecriface.ECRAPI, registryID string) *ecrClient {
+	return &ecrClient{api: api, registryID: registryID, ensured: sets.New[string]()}
+}
+
+func (c *ecrClient) ensureRepository(ctx context.Context, repo string) error {
+	if c.ensured.Has(repo) {
+		return nil
+	}
+	klog.V(1).Infof("Ensuring ECR repository %s exists", repo)
+	_, err := c.api.CreateRepositoryWithContext(ctx, &ecr.CreateRepositoryInput{
+		RegistryId:     aws.String(c.registryID),
+		RepositoryName: aws.String(repo),
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		switch aerr.Code() {
+		case ecr.ErrCodeRepositoryAlreadyExistsException:
+			err = nil
+		case "AccessDeniedException", "UnrecognizedClientException", "ExpiredTokenException", "NoCredentialProviders":
+			return NewClassifiedError(ErrorClassAuth, fmt.Errorf("error creating repository %s: %v", repo, err))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error creating repository %s: %v", repo, err)
+	}
+	c.ensured.Insert(repo)
+	return nil
+}
+
+// garClient creates the repositories of an Artifact Registry registry with the
+// Artifact Registry API. The repository of an image is the component of its
+// path following the project, e.g. repo in project/repo/ns/name, and pushes
+// to repositories that do not exist are rejected.
+type garClient struct {
+	baseURL  string
+	location string
+	token    string
+	client   *http.Client
+	// ensured holds the project/repository pairs known to exist.
+	ensured sets.Set[string]
+}
+
+func newGARClient(baseURL, location, token string) *garClient {
+	return &garClient{
+		baseURL:  baseURL,
+		location: location,
+		token:    token,
+		client:   http.DefaultClient,
+		ensured:  sets.New[string](),
+	}
+}
+
+func (c *garClient) ensureRepository(ctx context.Context, repo string) error {
+	parts := strings.SplitN(repo, "/", 3)
+	if len(parts) < 3 {
+		return fmt.Errorf("repository %s is not in a project and an Artifact Registry repository", repo)
+	}
+	project, name := parts[0], parts[1]
+	if c.ensured.Has(project + "/" + name) {
+		return nil
+	}
+	klog.V(1).Infof("Ensuring Artifact Registry repository %s/%s exists in %s", project, name, c.location)
+	endpoint := fmt.Sprintf("/v1/projects/%s/locations/%s/repositories?repositoryId=%s",
+		url.PathEscape(project), url.PathEscape(c.location), url.QueryEscape(name))
+	var op garOperation
+	created, err := c.do(ctx, http.MethodPost, endpoint, map[string]string{"format": "DOCKER"}, &op)
+	if err != nil {
+		return fmt.Errorf("error creating repository %s/%s: %w", project, name, err)
+	}
+	if created {
+		// The repository is created by a long-running operation,
+		// pushes fail until it is done.
+		if err := c.waitOperation(ctx, op); err != nil {
+			return fmt.Errorf("error creating repository %s/%s: %w", project, name, err)
+		}
+	}
+	c.ensured.Insert(project + "/" + name)
+	return nil
+}
+
+// garOperation is a long-running operation of the Artifact Registry API.
+type garOperation struct {
+	Name  string `json:"name"`
+	Done  bool   `json:"done"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// waitOperation polls op until it is done.
+func (c *garClient) waitOperation(ctx context.Context, op garOperation) error {
+	deadline := time.Now().Add(garOperationTimeout)
+	for !op.Done {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("operation %s not done after %s", op.Name, garOperationTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(garOperationInterval):
+		}
+		if _, err := c.do(ctx, http.MethodGet, "/v1/"+op.Name, nil, &op); err != nil {
+			return err
+		}
+	}
+	if op.Error != nil {
+		return fmt.Errorf("operation %s failed: %s", op.Name, op.Error.Message)
+	}
+	return nil
+}
+
+// do sends a request with body to the endpoint of the Artifact Registry API
+// and decodes the response into out. It returns false, and no error, if the
+// resource the request creates already exists.
+func (c *garClient) do(ctx context.Context, method, endpoint string, body, out interface{}) (bool, error) {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return false, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, reqBody)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	respData, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return true, json.Unmarshal(respData, out)
+	case resp.StatusCode == http.StatusConflict && method == http.MethodPost:
+		return false, nil
+	}
+
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	msg := strings.TrimSpace(string(respData))
+	if json.Unmarshal(respData, &apiErr) == nil && apiErr.Error.Message != "" {
+		msg = apiErr.Error.Message
+	}
+	err = fmt.Errorf("artifact registry API returned %s: %s", resp.Status, msg)
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return false, NewClassifiedError(ErrorClassAuth, err)
+	}
+	return false, err
+}
+
+// ensureRepositories creates the repositories of refs in the destination
+// registries before they are pushed to, with the Quay API if
+// --quay-create-repositories is set and with the API of the cloud
+// registries not creating repositories on push if --create-repos is set.
+func (o *MirrorOptions) ensureRepositories(ctx context.Context, refs ...reference.DockerImageReference) error {
+	if err := o.ensureQuayRepositories(ctx, refs...); err != nil {
+		return err
+	}
+	return o.ensureCloudRepositories(ctx, refs...)
+}
+
+// ensureCloudRepositories creates the repositories of refs in the
+// ECR and Artifact Registry destination registries, if --create-repos is set.
+func (o *MirrorOptions) ensureCloudRepositories(ctx context.Context, refs ...reference.DockerImageReference) error {
+	if !o.CreateRepos || o.DryRun {
+		return nil
+	}
+	if o.repoCreators == nil {
+		o.repoCreators = map[string]repositoryCreator{}
+	}
+	var errs []error
+	for _, ref := range refs {
+		creator, ok := o.repoCreators[ref.Registry]
+		if !ok {
+			var err error
+			if creator, err = newRepositoryCreator(ref.Registry); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			o.repoCreators[ref.Registry] = creator
+		}
+		if creator == nil {
+			continue
+		}
+		if err := creator.ensureRepository(ctx, ref.RepositoryName()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
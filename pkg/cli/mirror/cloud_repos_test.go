@@ -0,0 +1,153 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeECR implements CreateRepository of the ECR API.
+type fakeECR struct {
+	ecriface.ECRAPI
+	repos    map[string]bool
+	requests int
+	err      error
+}
+
+func (e *fakeECR) CreateRepositoryWithContext(_ aws.Context, in *ecr.CreateRepositoryInput, _ ...request.Option) (*ecr.CreateRepositoryOutput, error) {
+	e.requests++
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.repos[aws.StringValue(in.RepositoryName)] {
+		return nil, awserr.New(ecr.ErrCodeRepositoryAlreadyExistsException, "repository already exists", nil)
+	}
+	e.repos[aws.StringValue(in.RepositoryName)] = true
+	return &ecr.CreateRepositoryOutput{}, nil
+}
+
+// fakeGAR serves the endpoints of the Artifact Registry API creating repositories.
+type fakeGAR struct {
+	mu       sync.Mutex
+	repos    map[string]bool
+	requests int
+}
+
+func (g *fakeGAR) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.requests++
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"message": "Request had invalid authentication credentials."}}`))
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/projects/project/locations/us-east1/repositories":
+		repo := r.URL.Query().Get("repositoryId")
+		if g.repos[repo] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		g.repos[repo] = true
+		json.NewEncoder(w).Encode(garOperation{Name: "projects/project/locations/us-east1/operations/" + repo})
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/projects/project/locations/us-east1/operations/mirror":
+		json.NewEncoder(w).Encode(garOperation{Name: "projects/project/locations/us-east1/operations/mirror", Done: true})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestECRClient(t *testing.T) {
+	api := &fakeECR{repos: map[string]bool{"existing/app": true}}
+	c := newECRClient(api, "123456789012")
+	require.NoError(t, c.ensureRepository(context.Background(), "mirror/openshift/release"))
+	require.NoError(t, c.ensureRepository(context.Background(), "existing/app"))
+	require.Equal(t, map[string]bool{"existing/app": true, "mirror/openshift/release": true}, api.repos)
+
+	// Repositories already ensured are not created again.
+	require.NoError(t, c.ensureRepository(context.Background(), "mirror/openshift/release"))
+	require.Equal(t, 2, api.requests)
+
+	api.err = awserr.New("AccessDeniedException", "not authorized to perform ecr:CreateRepository", nil)
+	err := c.ensureRepository(context.Background(), "other/app")
+	require.ErrorContains(t, err, "error creating repository other/app: AccessDeniedException")
+	require.Equal(t, ErrorClassAuth, ClassifyError(err))
+}
+
+func TestGARClient(t *testing.T) {
+	defer func(interval time.Duration) { garOperationInterval = interval }(garOperationInterval)
+	garOperationInterval = time.Millisecond
+
+	gar := &fakeGAR{repos: map[string]bool{"existing": true}}
+	server := httptest.NewServer(gar)
+	t.Cleanup(server.Close)
+
+	c := newGARClient(server.URL, "us-east1", "token")
+	require.NoError(t, c.ensureRepository(context.Background(), "project/mirror/openshift/release"))
+	require.NoError(t, c.ensureRepository(context.Background(), "project/mirror/ubi8/ubi"))
+	require.NoError(t, c.ensureRepository(context.Background(), "project/existing/app"))
+	require.Equal(t, map[string]bool{"existing": true, "mirror": true}, gar.repos)
+	// One creation and one operation poll for mirror, one creation for existing.
+	require.Equal(t, 3, gar.requests)
+
+	err := c.ensureRepository(context.Background(), "project/app")
+	require.EqualError(t, err, "repository project/app is not in a project and an Artifact Registry repository")
+
+	c = newGARClient(server.URL, "us-east1", "bad")
+	err = c.ensureRepository(context.Background(), "project/other/app")
+	require.ErrorContains(t, err, "error creating repository project/other: artifact registry API returned 401 Unauthorized: Request had invalid authentication credentials.")
+	require.Equal(t, ErrorClassAuth, ClassifyError(err))
+}
+
+func TestNewRepositoryCreator(t *testing.T) {
+	t.Setenv(googleAccessTokenEnv, "")
+	for _, registry := range []string{"gcr.io", "us.gcr.io", "example.azurecr.io", "localhost:5000"} {
+		creator, err := newRepositoryCreator(registry)
+		require.NoError(t, err)
+		require.Nil(t, creator, registry)
+	}
+
+	_, err := newRepositoryCreator("us-east1-docker.pkg.dev")
+	require.ErrorContains(t, err, "requires an OAuth access token of the Artifact Registry API in GOOGLE_OAUTH_ACCESS_TOKEN")
+	require.Equal(t, ErrorClassAuth, ClassifyError(err))
+
+	t.Setenv(googleAccessTokenEnv, "token")
+	creator, err := newRepositoryCreator("us-east1-docker.pkg.dev")
+	require.NoError(t, err)
+	require.Equal(t, "us-east1", creator.(*garClient).location)
+
+	creator, err = newRepositoryCreator("123456789012.dkr.ecr.eu-west-1.amazonaws.com")
+	require.NoError(t, err)
+	require.Equal(t, "123456789012", creator.(*ecrClient).registryID)
+}
+
+func TestEnsureCloudRepositories(t *testing.T) {
+	api := &fakeECR{repos: map[string]bool{}}
+	const registry = "123456789012.dkr.ecr.eu-west-1.amazonaws.com"
+	o := &MirrorOptions{CreateRepos: true}
+	o.repoCreators = map[string]repositoryCreator{registry: newECRClient(api, "123456789012")}
+	require.NoError(t, o.ensureRepositories(context.Background(),
+		reference.DockerImageReference{Registry: registry, Namespace: "mirror", Name: "app", Tag: "v1"},
+		reference.DockerImageReference{Registry: "example.azurecr.io", Namespace: "mirror", Name: "app"},
+	))
+	require.Equal(t, map[string]bool{"mirror/app": true}, api.repos)
+	require.Contains(t, o.repoCreators, "example.azurecr.io")
+
+	t.Run("Valid/Disabled", func(t *testing.T) {
+		o := &MirrorOptions{}
+		require.NoError(t, o.ensureRepositories(context.Background(), reference.DockerImageReference{Registry: registry, Namespace: "other", Name: "app"}))
+	})
+}
@@ -0,0 +1,176 @@
+package mirror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blang/semver/v4"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/archive"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/version"
+)
+
+// compatFormat returns the format of the imagesets to create.
+func (o *MirrorOptions) compatFormat() archive.Format {
+	if o.CompatFormat == "" {
+		return archive.CurrentFormat
+	}
+	return archive.Format(o.CompatFormat)
+}
+
+// downgradeMetadata returns a copy of meta holding only the fields
+// known to the oc-mirror versions reading imagesets of format.
+// The metadata is decoded strictly, so older versions fail to
+// publish imagesets whose metadata holds newer fields.
+func downgradeMetadata(meta v1alpha2.Metadata, format archive.Format) (v1alpha2.Metadata, error) {
+	// Deep copy, the metadata of the workspace keeps every field.
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return meta, err
+	}
+	downgraded, err := config.LoadMetadata(data)
+	if err != nil {
+		return meta, err
+	}
+	if format != archive.FormatV1 {
+		return downgraded, nil
+	}
+
+	downgraded.Interrupted = nil
+	downgraded.PastMirror.Stats = nil
+	downgraded.PastMirror.Annotations = nil
+	downgraded.PastMirror.ToolVersion = ""
+	downgraded.PastMirror.ResolvedTags = nil
+	downgraded.PastMirror.Artifacts = nil
+	downgraded.PastMirror.Fingerprint = ""
+	downgraded.PastMirror.Samples = nil
+	downgraded.PastMirror.Mirror.Artifacts = nil
+	downgraded.PastMirror.Mirror.NamespaceMappings = nil
+	downgraded.PastMirror.Mirror.Platform.GraphDataURL = ""
+	downgraded.PastMirror.Mirror.Platform.Samples = nil
+	// Older versions match the blocked images by name only, an entry
+	// without name would block every image.
+	var blocked []v1alpha2.BlockedImage
+	for _, b := range downgraded.PastMirror.Mirror.BlockedImages {
+		if !b.IsPattern() {
+			blocked = append(blocked, b)
+		}
+	}
+	downgraded.PastMirror.Mirror.BlockedImages = blocked
+	for i := range downgraded.PastMirror.Mirror.Platform.Channels {
+		downgraded.PastMirror.Mirror.Platform.Channels[i].GraphURL = ""
+		downgraded.PastMirror.Mirror.Platform.Channels[i].PromotedIn = ""
+	}
+	for i := range downgraded.PastMirror.Operators {
+		downgraded.PastMirror.Operators[i].ChannelHeads = nil
+		downgraded.PastMirror.Operators[i].PinnedBundles = nil
+		for j := range downgraded.PastMirror.Operators[i].Packages {
+			downgraded.PastMirror.Operators[i].Packages[j].CLIDownloads = false
+			downgraded.PastMirror.Operators[i].Packages[j].SelectedBundles = nil
+		}
+	}
+	for i := range downgraded.PastMirror.Mirror.Operators {
+		op := &downgraded.PastMirror.Mirror.Operators[i]
+		op.Bundles = nil
+		op.BaseImage = ""
+		op.IncludeSuccessors = false
+		op.InspectBundles = false
+		op.ExcludeIncompatible = false
+		op.PullSecret = ""
+		for j := range op.Packages {
+			op.Packages[j].CLIDownloads = false
+			op.Packages[j].SelectedBundles = nil
+		}
+	}
+	return downgraded, nil
+}
+
+// currentToolVersion is the version of this oc-mirror, recorded
+// in the metadata of the imagesets it creates.
+var currentToolVersion = version.Get().GitVersion
+
+// checkImageSetFormat returns an error naming the oc-mirror version that created
+// the imageset when the imageset is of a format this version of oc-mirror cannot
+// publish, and warns when the imageset was created by a newer oc-mirror.
+// Imagesets created before the format was recorded are of format archive.FormatV1.
+func checkImageSetFormat(tmpdir string, filesInArchive map[string]string) error {
+	format, err := readImageSetFormat(tmpdir, filesInArchive)
+	if err != nil {
+		return err
+	}
+	createdBy := readImageSetToolVersion(tmpdir, filesInArchive)
+	if !format.Supported() {
+		creator := "a newer oc-mirror"
+		if createdBy != "" {
+			creator = "oc-mirror " + createdBy
+		}
+		return fmt.Errorf("imageset format %q was created by %s, this oc-mirror (%s) supports formats up to %s: "+
+			"upgrade oc-mirror or create the imageset with --compat-format %s", format, creator, currentToolVersion, archive.CurrentFormat, archive.CurrentFormat)
+	}
+	if isNewerVersion(createdBy, currentToolVersion) {
+		klog.Warningf("imageset was created by oc-mirror %s, newer than this oc-mirror (%s): upgrade oc-mirror if the publish fails", createdBy, currentToolVersion)
+	}
+	return nil
+}
+
+// readImageSetFormat returns the format recorded in the imageset, archive.FormatV1 if none.
+func readImageSetFormat(tmpdir string, filesInArchive map[string]string) (archive.Format, error) {
+	if err := unpack(config.FormatBasePath, tmpdir, filesInArchive); err != nil {
+		var aerr *ErrArchiveFileNotFound
+		if errors.As(err, &aerr) {
+			return archive.FormatV1, nil
+		}
+		return "", err
+	}
+	f, err := os.Open(filepath.Join(tmpdir, config.FormatBasePath))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	format, err := archive.ReadFormat(f)
+	if err != nil {
+		return "", fmt.Errorf("error reading imageset format: %v", err)
+	}
+	return format, nil
+}
+
+// readImageSetToolVersion returns the version of oc-mirror recorded in the metadata
+// of the imageset, or an empty string if unknown. The metadata is decoded leniently,
+// as the metadata of a newer format may hold fields unknown to this version.
+func readImageSetToolVersion(tmpdir string, filesInArchive map[string]string) string {
+	if err := unpack(config.MetadataBasePath, tmpdir, filesInArchive); err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(tmpdir, config.MetadataBasePath))
+	if err != nil {
+		return ""
+	}
+	var meta struct {
+		PastMirror struct {
+			ToolVersion string `json:"toolVersion"`
+		} `json:"pastMirror"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return ""
+	}
+	return meta.PastMirror.ToolVersion
+}
+
+// isNewerVersion returns true if both versions are semantic versions and v is newer than current.
+func isNewerVersion(v, current string) bool {
+	newer, err := semver.ParseTolerant(v)
+	if err != nil {
+		return false
+	}
+	cur, err := semver.ParseTolerant(current)
+	if err != nil {
+		return false
+	}
+	return newer.GT(cur)
+}
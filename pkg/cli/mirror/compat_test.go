@@ -0,0 +1,152 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/archive"
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+func TestDowngradeMetadata(t *testing.T) {
+	meta := v1alpha2.NewMetadata()
+	meta.Interrupted = &v1alpha2.InterruptedRun{Sequence: 3, Phase: phaseMirror}
+	meta.PastMirror = v1alpha2.PastMirror{
+		Sequence:     2,
+		Stats:        &v1alpha2.MirrorStats{LayersPublished: 3},
+		Annotations:  map[string]string{"ticket": "CHG-1"},
+		ToolVersion:  "v4.17.0",
+		ResolvedTags: map[string]string{"quay.io/foo/bar:latest": "sha256:1111111111111111111111111111111111111111111111111111111111111111"},
+		Artifacts:    []v1alpha2.ArtifactMetadata{{Name: "quay.io/foo/chart:1.0.0", Digest: "sha256:2222222222222222222222222222222222222222222222222222222222222222"}},
+		Fingerprint:  "sha256:3333333333333333333333333333333333333333333333333333333333333333",
+		Samples:      &v1alpha2.SamplesMetadata{Release: "4.14.1", ImageStreams: []string{"ruby"}, SkippedImageStreams: []string{"nodejs"}},
+		Mirror: v1alpha2.Mirror{
+			Artifacts:         []v1alpha2.Artifact{{Name: "quay.io/foo/chart:1.0.0"}},
+			NamespaceMappings: []v1alpha2.NamespaceMapping{{Source: "quay.io", Namespace: "community"}},
+			BlockedImages:     []v1alpha2.BlockedImage{{Name: "alpine"}, {Registry: "quay.io", Tags: []string{"dev-*"}}},
+			Platform: v1alpha2.Platform{
+				GraphDataURL: "file:///srv/graph-data.tar.gz",
+				Samples:      &v1alpha2.PlatformSamples{Release: "4.14.1", ImageStreams: []string{"ruby"}},
+				Channels:     []v1alpha2.ReleaseChannel{{Name: "stable-4.14", GraphURL: "file:///srv/graph", PromotedIn: "eus-4.14"}},
+			},
+			Operators: []v1alpha2.Operator{{
+				Catalog:             "registry.example.com/catalog:v1",
+				Bundles:             []string{"registry.example.com/bundle:v1"},
+				BaseImage:           "registry.example.com/opm:v1",
+				InspectBundles:      true,
+				ExcludeIncompatible: true,
+				PullSecret:          "/srv/catalog-auth.json",
+				IncludeConfig: v1alpha2.IncludeConfig{
+					Packages: []v1alpha2.IncludePackage{{Name: "foo", CLIDownloads: true, SelectedBundles: []v1alpha2.SelectedBundle{{Name: "foo.v1"}}}},
+				},
+			}},
+		},
+		Operators: []v1alpha2.OperatorMetadata{{
+			Catalog:       "registry.example.com/catalog:v1",
+			ChannelHeads:  []v1alpha2.ChannelHead{{Package: "foo", Channel: "stable", Bundle: "foo.v1"}},
+			PinnedBundles: []v1alpha2.PinnedBundle{{Package: "foo", Channel: "stable", Name: "foo.v1", Image: "registry.example.com/foo@sha256:1234", Selected: true}},
+		}},
+	}
+
+	t.Run("Valid/V1", func(t *testing.T) {
+		downgraded, err := downgradeMetadata(meta, archive.FormatV1)
+		require.NoError(t, err)
+		require.Equal(t, 2, downgraded.PastMirror.Sequence)
+		require.Nil(t, downgraded.PastMirror.Stats)
+		require.Nil(t, downgraded.PastMirror.Annotations)
+		require.Nil(t, downgraded.PastMirror.Operators[0].ChannelHeads)
+		require.Nil(t, downgraded.PastMirror.Operators[0].PinnedBundles)
+		require.Nil(t, downgraded.PastMirror.Mirror.Operators[0].Packages[0].SelectedBundles)
+		require.Nil(t, downgraded.PastMirror.Mirror.Operators[0].Bundles)
+		require.False(t, downgraded.PastMirror.Mirror.Operators[0].InspectBundles)
+		require.False(t, downgraded.PastMirror.Mirror.Operators[0].Packages[0].CLIDownloads)
+		require.Equal(t, []v1alpha2.BlockedImage{{Name: "alpine"}}, downgraded.PastMirror.Mirror.BlockedImages)
+
+		// The fields added after format v1 are left out of the JSON.
+		data, err := json.Marshal(downgraded)
+		require.NoError(t, err)
+		for _, field := range []string{"stats", "annotations", "channelHeads", "bundles", "baseImage", "inspectBundles", "excludeIncompatible", "cliDownloads", "graphURL", "graphDataURL", "promotedIn", "toolVersion", "resolvedTags", "artifacts", "namespaceMappings", "fingerprint", "interrupted", "pinnedBundles", "selectedBundles", "registry", "tags", "samples", "imageStreams", "pullSecret"} {
+			require.NotContains(t, string(data), `"`+field+`"`)
+		}
+
+		// The metadata of the workspace is not modified.
+		require.NotNil(t, meta.PastMirror.Stats)
+		require.True(t, meta.PastMirror.Mirror.Operators[0].Packages[0].CLIDownloads)
+	})
+	t.Run("Valid/V2", func(t *testing.T) {
+		downgraded, err := downgradeMetadata(meta, archive.FormatV2)
+		require.NoError(t, err)
+		require.Equal(t, meta, downgraded)
+	})
+}
+
+func TestCheckImageSetFormat(t *testing.T) {
+	defaultToolVersion := currentToolVersion
+	currentToolVersion = "v4.17.0"
+	t.Cleanup(func() { currentToolVersion = defaultToolVersion })
+
+	type spec struct {
+		name        string
+		format      string
+		toolVersion string
+		expError    string
+	}
+	cases := []spec{
+		{name: "Valid/NoFormat"},
+		{name: "Valid/V1", format: "v1"},
+		{name: "Valid/V2", format: "v2", toolVersion: "v4.17.0"},
+		{name: "Valid/NewerToolVersion", format: "v2", toolVersion: "v4.18.0"},
+		{
+			name:        "Invalid/NewerFormat",
+			format:      "v9",
+			toolVersion: "v4.20.0",
+			expError: `imageset format "v9" was created by oc-mirror v4.20.0, this oc-mirror (v4.17.0) supports formats up to v2: ` +
+				`upgrade oc-mirror or create the imageset with --compat-format v2`,
+		},
+		{
+			name:   "Invalid/NewerFormatNoToolVersion",
+			format: "v9",
+			expError: `imageset format "v9" was created by a newer oc-mirror, this oc-mirror (v4.17.0) supports formats up to v2: ` +
+				`upgrade oc-mirror or create the imageset with --compat-format v2`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			require.NoError(t, os.MkdirAll(filepath.Join(srcDir, config.PublishDir), 0750))
+			meta := fmt.Sprintf(`{"kind":"Metadata","pastMirror":{"sequence":1,"toolVersion":%q,"newField":true}}`, c.toolVersion)
+			require.NoError(t, os.WriteFile(filepath.Join(srcDir, config.MetadataBasePath), []byte(meta), 0600))
+			files := []string{config.MetadataBasePath}
+			if c.format != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(srcDir, config.FormatBasePath), []byte(c.format+"\n"), 0600))
+				files = append(files, config.FormatBasePath)
+			}
+			arcPath := filepath.Join(t.TempDir(), "mirror_seq1_000000.tar")
+			require.NoError(t, archive.NewArchiver().Archive([]string{filepath.Join(srcDir, config.PublishDir)}, arcPath))
+			filesInArchive := map[string]string{}
+			for _, f := range files {
+				filesInArchive[f] = arcPath
+			}
+
+			err := checkImageSetFormat(t.TempDir(), filesInArchive)
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	require.True(t, isNewerVersion("v4.18.0", "v4.17.3"))
+	require.False(t, isNewerVersion("v4.17.0", "v4.17.0"))
+	require.False(t, isNewerVersion("v4.18.0", "unknown"))
+	require.False(t, isNewerVersion("", "v4.17.0"))
+}
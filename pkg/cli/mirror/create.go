@@ -19,15 +19,16 @@ import (
 	"github.com/openshift/oc-mirror/pkg/metadata/storage"
 )
 
-// Create will plan a mirroring operation based on provided configuration
-func (o *MirrorOptions) Create(ctx context.Context, cfg v1alpha2.ImageSetConfiguration) (v1alpha2.Metadata, image.TypedImageMapping, error) {
+// Create will plan a mirroring operation based on provided configuration.
+// The associations of the previous sequences are streamed from the metadata
+// into o.prevAssociations, which the caller closes once the run is done.
+func (o *MirrorOptions) Create(ctx context.Context, cfg v1alpha2.ImageSetConfiguration) (_ v1alpha2.Metadata, _ image.TypedImageMapping, err error) {
 	// Determine stateless or stateful mode.
 	// Empty storage configuration will trigger a metadata cleanup
 	// action and labels metadata as single use
 	path := filepath.Join(o.Dir, config.SourceDir)
 	meta := v1alpha2.NewMetadata()
 	var backend storage.Backend
-	var err error
 	if !cfg.StorageConfig.IsSet() {
 		meta.SingleUse = true
 		klog.Warningf("backend is not configured in %s, using stateless mode", o.ConfigPath)
@@ -49,13 +50,33 @@ func (o *MirrorOptions) Create(ctx context.Context, cfg v1alpha2.ImageSetConfigu
 		}
 	}
 	thisRun := v1alpha2.PastMirror{
-		Timestamp: int(time.Now().Unix()),
+		Timestamp:   int(time.Now().Unix()),
+		Annotations: o.annotations,
+		ToolVersion: currentToolVersion,
+	}
+	if o.Lock {
+		if thisRun.Fingerprint, err = o.configFingerprint(ctx, cfg); err != nil {
+			return meta, image.TypedImageMapping{}, fmt.Errorf("error computing the fingerprint of the imageset configuration: %v", err)
+		}
 	}
 	// Run full or diff mirror.
-	merr := backend.ReadMetadata(ctx, &meta, config.MetadataBasePath)
-	if merr != nil && !errors.Is(merr, storage.ErrMetadataNotExist) {
+	var merr error
+	o.prevAssociations, merr = o.readPastAssociations(ctx, backend, &meta)
+	switch {
+	case merr == nil:
+	case errors.Is(merr, storage.ErrMetadataNotExist):
+		if o.prevAssociations, err = image.OpenAssociationDB(o.associationsDBPath(previousAssociationsDB)); err != nil {
+			return meta, image.TypedImageMapping{}, err
+		}
+	default:
 		return meta, image.TypedImageMapping{}, merr
 	}
+	defer func() {
+		if err != nil {
+			o.prevAssociations.Close()
+			o.prevAssociations = nil
+		}
+	}()
 	// New metadata files get a full mirror, with complete/heads-only catalogs, release images,
 	// and a new UUID. Otherwise, use data from the last mirror to mirror just the layer diff.
 	switch {
@@ -73,10 +94,20 @@ func (o *MirrorOptions) Create(ctx context.Context, cfg v1alpha2.ImageSetConfigu
 			return image.TypedImageMapping{}, nil
 		}
 		mmapping, err := o.run(ctx, &cfg, meta, f)
+		thisRun.ResolvedTags = o.resolvedTags
+		thisRun.Artifacts = o.artifacts
+		thisRun.Samples = o.samples
 		meta.PastMirror = thisRun
 		return meta, mmapping, err
 	default:
 		lastRun := meta.PastMirror
+		// The metadata of the new sequence replaces the record of an interrupted run.
+		logInterruptedRun(meta.Interrupted)
+		meta.Interrupted = nil
+		if thisRun.Fingerprint != "" && thisRun.Fingerprint == lastRun.Fingerprint && !o.ForceFull && !o.IgnoreHistory {
+			klog.Infof("Imageset configuration and catalogs unchanged since sequence %d", lastRun.Sequence)
+			return meta, image.TypedImageMapping{}, ErrNoUpdatesExist
+		}
 		thisRun.Sequence = lastRun.Sequence + 1
 		thisRun.Mirror = cfg.Mirror
 		f := func(ctx context.Context, cfg v1alpha2.ImageSetConfiguration) (image.TypedImageMapping, error) {
@@ -88,6 +119,10 @@ func (o *MirrorOptions) Create(ctx context.Context, cfg v1alpha2.ImageSetConfigu
 			return image.TypedImageMapping{}, nil
 		}
 		mmapping, err := o.run(ctx, &cfg, meta, f)
+		thisRun.ResolvedTags = o.resolvedTags
+		thisRun.Artifacts = o.artifacts
+		thisRun.Samples = o.samples
+		o.reportTagDrifts(lastRun.ResolvedTags, thisRun.ResolvedTags)
 		meta.PastMirror = thisRun
 		return meta, mmapping, err
 	}
@@ -121,15 +156,31 @@ func (o *MirrorOptions) run(
 ) (image.TypedImageMapping, error) {
 
 	mmappings := image.TypedImageMapping{}
+	o.blocked = nil
+	o.samples = nil
 
-	if len(cfg.Mirror.Platform.Channels) != 0 {
+	if o.hasReleases(*cfg) {
 		release := NewReleaseOptions(o)
 		mappings, err := release.Plan(ctx, meta.PastMirror, cfg)
 		if err != nil {
 			return mmappings, err
 		}
+		if err := o.removeBlocked(*cfg, "release", mappings); err != nil {
+			return mmappings, err
+		}
 		mmappings.Merge(mappings)
 
+		if cfg.Mirror.Platform.Samples != nil {
+			samples, err := o.planSamples(ctx, *cfg, mappings)
+			if err != nil {
+				return mmappings, fmt.Errorf("error collecting sample imagestreams: %v", err)
+			}
+			if err := o.removeBlocked(*cfg, "samples", samples); err != nil {
+				return mmappings, err
+			}
+			mmappings.Merge(samples)
+		}
+
 		if cfg.Mirror.Platform.Graph {
 			klog.Info("Adding graph data")
 			// Always add the graph base image to the metadata if needed,
@@ -140,7 +191,7 @@ func (o *MirrorOptions) run(
 			if err := os.MkdirAll(releaseDir, 0750); err != nil {
 				return mmappings, err
 			}
-			if err := downloadGraphData(ctx, releaseDir); err != nil {
+			if err := downloadGraphData(ctx, releaseDir, cfg.Mirror.Platform.GraphDataURL); err != nil {
 				return mmappings, err
 			}
 		}
@@ -155,6 +206,9 @@ func (o *MirrorOptions) run(
 	if err != nil {
 		return mmappings, err
 	}
+	if err := o.removeBlocked(*cfg, "operator", mappings); err != nil {
+		return mmappings, err
+	}
 	mmappings.Merge(mappings)
 
 	if len(cfg.Mirror.AdditionalImages) != 0 {
@@ -163,6 +217,9 @@ func (o *MirrorOptions) run(
 		if err != nil {
 			return mmappings, err
 		}
+		if err := o.removeBlocked(*cfg, "additional", mappings); err != nil {
+			return mmappings, err
+		}
 		mmappings.Merge(mappings)
 	}
 
@@ -172,14 +229,22 @@ func (o *MirrorOptions) run(
 		if err != nil {
 			return mmappings, err
 		}
+		if err := o.removeBlocked(*cfg, "helm", mappings); err != nil {
+			return mmappings, err
+		}
 		mmappings.Merge(mappings)
 	}
 
+	artifacts := NewArtifactOptions(o)
+	if o.artifacts, err = artifacts.PullArtifacts(ctx, cfg.Mirror.Artifacts, meta.PastMirror); err != nil {
+		return mmappings, err
+	}
+
 	if len(cfg.Mirror.Samples) != 0 {
 		klog.Info("sample images full not implemented")
 	}
 
-	return mmappings, nil
+	return mmappings, o.writeBlockedReport()
 }
 
 func (o *MirrorOptions) createOlmArtifactsForOCI(ctx context.Context, cfg v1alpha2.ImageSetConfiguration) error {
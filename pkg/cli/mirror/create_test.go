@@ -35,6 +35,7 @@ func TestCreate(t *testing.T) {
 	}
 	_, mappings, err := opts.Create(ctx, cfg)
 	require.NoError(t, err)
+	defer opts.prevAssociations.Close()
 	require.Len(t, mappings, 1)
 }
 
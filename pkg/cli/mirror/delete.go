@@ -0,0 +1,173 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/bundle"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+type DeleteOptions struct {
+	*cli.RootOptions
+	ConfigPath     string // Path to the imageset configuration holding the storage configuration of the metadata
+	From           string // Path to an archived imageset whose published images are deleted
+	ToMirror       string // Registry the images were mirrored to
+	UserNamespace  string // The <namespace>/<image> portion of the destination reference
+	DestSkipTLS    bool   // Disable TLS validation for destination registry
+	DestPlainHTTP  bool   // Use plain HTTP for destination registry
+	MaxPerRegistry int    // Number of concurrent requests sent to the registry
+	DryRun         bool   // Write the images that would be deleted without deleting them
+}
+
+func NewDeleteCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := DeleteOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "delete <destination registry>",
+		Short: "Delete the images recorded in the metadata from the mirror registry",
+		Long: templates.LongDesc(`
+		Delete from the mirror registry every image recorded in the associations of
+		the mirror metadata, then delete the metadata so that the next mirror
+		operation starts a new imageset sequence. The metadata is read from the
+		storage configuration of an imageset configuration or, with --from, from
+		the registry the imageset archive was published to.
+		Only the manifests are deleted: the registry garbage collection removes the
+		blobs no longer referenced.
+	`),
+		Example: templates.Examples(`
+			# Delete the images mirrored to mirror with the imageset configuration
+			oc-mirror delete --config mirror-config.yaml docker://localhost:5000/namespace
+
+			# Write the images published from an imageset sequence that would be deleted
+			oc-mirror delete --from mirror_seq1_000000.tar docker://localhost:5000/namespace --dry-run
+		`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Validate())
+			checkErr(o.Run(cmd.Context()))
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVarP(&o.ConfigPath, "config", "c", o.ConfigPath, "Path to imageset configuration file holding the metadata storage configuration")
+	fs.StringVar(&o.From, "from", o.From, "Path to an archived imageset whose published images are deleted")
+	fs.BoolVar(&o.DestSkipTLS, "dest-skip-tls", o.DestSkipTLS, "Disable TLS validation for destination registry")
+	fs.BoolVar(&o.DestPlainHTTP, "dest-use-http", o.DestPlainHTTP, "Use plain HTTP for destination registry")
+	fs.IntVar(&o.MaxPerRegistry, "max-per-registry", 6, "Number of concurrent requests allowed per registry")
+	fs.BoolVar(&o.DryRun, "dry-run", o.DryRun, "Write the images that would be deleted to the workspace without deleting them")
+	o.BindFlags(cmd.PersistentFlags())
+
+	return cmd
+}
+
+func (o *DeleteOptions) Complete(args []string) error {
+	destination := args[0]
+	if !strings.HasPrefix(destination, "docker://") {
+		return fmt.Errorf("destination %q must use the docker:// scheme", destination)
+	}
+	mirror, err := imagesource.ParseReference(strings.TrimPrefix(destination, "docker://"))
+	if err != nil {
+		return err
+	}
+	o.ToMirror = mirror.Ref.Registry
+	o.UserNamespace = mirror.Ref.RepositoryName()
+	return nil
+}
+
+func (o *DeleteOptions) Validate() error {
+	switch {
+	case len(o.ConfigPath) == 0 && len(o.From) == 0:
+		return errors.New("must specify --config or --from")
+	case len(o.ConfigPath) != 0 && len(o.From) != 0:
+		return errors.New("--config and --from are mutually exclusive")
+	case o.MaxPerRegistry <= 0:
+		return errors.New("--max-per-registry must be positive")
+	}
+	return nil
+}
+
+func (o *DeleteOptions) Run(ctx context.Context) error {
+	if err := os.MkdirAll(o.Dir, 0750); err != nil {
+		return err
+	}
+	mo := NewMirrorOptions(o.RootOptions)
+	mo.ConfigPath = o.ConfigPath
+	mo.From = o.From
+	mo.ToMirror = o.ToMirror
+	mo.UserNamespace = o.UserNamespace
+	mo.DestSkipTLS = o.DestSkipTLS
+	mo.DestPlainHTTP = o.DestPlainHTTP
+	mo.MaxPerRegistry = o.MaxPerRegistry
+	mo.DryRun = o.DryRun
+
+	stopAudit, err := mo.startAuditLog("delete")
+	if err != nil {
+		return err
+	}
+	defer stopAudit()
+
+	if len(o.ConfigPath) != 0 {
+		return mo.DeleteImageSet(ctx)
+	}
+	return mo.deletePublishedImageSet(ctx)
+}
+
+// deletePublishedImageSet removes every image recorded in the metadata published to
+// the destination registry by the imagesets of the archive at From, along with the
+// published metadata. The images of a single use imageset are read from the archive.
+func (o *MirrorOptions) deletePublishedImageSet(ctx context.Context) error {
+	incoming, err := bundle.ReadMetadataFromFile(ctx, o.From)
+	if err != nil {
+		return fmt.Errorf("error retrieving metadata from %q: %v", o.From, err)
+	}
+	if incoming.SingleUse {
+		prev, err := o.loadAssociations(previousAssociationsDB, incoming.PastAssociations)
+		if err != nil {
+			return err
+		}
+		defer prev.Close()
+		return o.deleteRecordedImages(ctx, prev)
+	}
+
+	metaImage := o.newMetadataImage(incoming.Uid.String())
+	backend, err := storage.NewRegistryBackend(&v1alpha2.RegistryConfig{
+		ImageURL: metaImage,
+		SkipTLS:  o.DestPlainHTTP || o.DestSkipTLS,
+	}, o.Dir)
+	if err != nil {
+		return fmt.Errorf("error creating backend for metadata at %s: %v", metaImage, err)
+	}
+	var meta v1alpha2.Metadata
+	prev, err := o.readPastAssociations(ctx, backend, &meta)
+	if err != nil {
+		if errors.Is(err, storage.ErrMetadataNotExist) {
+			klog.Infof("No metadata published to %s, nothing to delete", metaImage)
+			return nil
+		}
+		return err
+	}
+	defer prev.Close()
+
+	if err := o.deleteRecordedImages(ctx, prev); err != nil || o.DryRun {
+		return err
+	}
+	if err := backend.Cleanup(ctx, config.MetadataBasePath); err != nil {
+		return fmt.Errorf("error deleting metadata from registry %q: %v", o.ToMirror, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package mirror
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+func TestDeleteValidate(t *testing.T) {
+	type spec struct {
+		name     string
+		opts     *DeleteOptions
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name: "Valid/Config",
+			opts: &DeleteOptions{ConfigPath: "foo", MaxPerRegistry: 6},
+		},
+		{
+			name: "Valid/From",
+			opts: &DeleteOptions{From: "foo", MaxPerRegistry: 6},
+		},
+		{
+			name:     "Invalid/NoMetadata",
+			opts:     &DeleteOptions{MaxPerRegistry: 6},
+			expError: "must specify --config or --from",
+		},
+		{
+			name:     "Invalid/ConfigAndFrom",
+			opts:     &DeleteOptions{ConfigPath: "foo", From: "bar", MaxPerRegistry: 6},
+			expError: "--config and --from are mutually exclusive",
+		},
+		{
+			name:     "Invalid/MaxPerRegistry",
+			opts:     &DeleteOptions{ConfigPath: "foo"},
+			expError: "--max-per-registry must be positive",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.opts.Validate()
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDeleteComplete(t *testing.T) {
+	opts := &DeleteOptions{}
+	require.NoError(t, opts.Complete([]string{"docker://localhost:5000/ns"}))
+	require.Equal(t, "localhost:5000", opts.ToMirror)
+	require.Equal(t, "ns", opts.UserNamespace)
+
+	require.EqualError(t, opts.Complete([]string{"file://mirror"}), `destination "file://mirror" must use the docker:// scheme`)
+}
+
+func TestDeleteRecordedImages(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(u.Host + "/ns/app:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	layerDigest, err := layers[0].Digest()
+	require.NoError(t, err)
+
+	meta := v1alpha2.NewMetadata()
+	meta.PastAssociations = []v1alpha2.Association{
+		{Name: "app", Path: "app:v1", ID: digest.String(), TagSymlink: "v1", Type: v1alpha2.TypeGeneric, LayerDigests: []string{layerDigest.String()}},
+	}
+
+	opts := NewMirrorOptions(&cli.RootOptions{
+		IOStreams: genericclioptions.IOStreams{Out: io.Discard, ErrOut: io.Discard},
+		Dir:       t.TempDir(),
+	})
+	opts.ToMirror = u.Host
+	opts.UserNamespace = "ns"
+	opts.DestPlainHTTP = true
+	prev, err := opts.loadAssociations(previousAssociationsDB, meta.PastAssociations)
+	require.NoError(t, err)
+	defer prev.Close()
+
+	t.Run("Valid/DryRun", func(t *testing.T) {
+		opts.DryRun = true
+		defer func() { opts.DryRun = false }()
+		require.NoError(t, opts.deleteRecordedImages(context.Background(), prev))
+		require.FileExists(t, filepath.Join(opts.Dir, "pruning-plan.json"))
+		_, err := remote.Head(ref)
+		require.NoError(t, err)
+	})
+
+	t.Run("Valid/Delete", func(t *testing.T) {
+		require.NoError(t, opts.deleteRecordedImages(context.Background(), prev))
+		_, err := remote.Head(ref.Context().Digest(digest.String()))
+		require.True(t, isNotFound(err))
+	})
+}
@@ -0,0 +1,82 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/operator"
+)
+
+const (
+	// dependenciesPermissive includes the missing operator dependencies
+	// provided by the catalog and warns about the other ones.
+	dependenciesPermissive = "permissive"
+	// dependenciesStrict fails on missing operator dependencies.
+	dependenciesStrict = "strict"
+)
+
+// resolveDependencies checks that the olm.package.required and olm.gvk.required
+// dependencies of the bundles of dc, rendered from ctlg, are mirrored too.
+// Missing dependencies provided by the catalog are added to dc unless
+// --operator-dependencies is strict, in which case they fail the run.
+func (o *OperatorOptions) resolveDependencies(ctx context.Context, reg *containerdregistry.Registry, ctlg v1alpha2.Operator, dc *declcfg.DeclarativeConfig) error {
+	if ctlg.SkipDependencies {
+		return nil
+	}
+	missing, err := operator.MissingDependencies(*dc, declcfg.DeclarativeConfig{})
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+
+	// The full catalog is only rendered when dependencies are missing.
+	// Catalogs generated from bundles only have the listed bundles.
+	catalog := &declcfg.DeclarativeConfig{}
+	if !ctlg.IsBundleList() {
+		ctlgRef := ctlg.Catalog
+		if ctlg.IsFBCDir() {
+			ctlgRef = v1alpha2.TrimProtocol(ctlg.Catalog)
+		}
+		if ctlg.IsFBCOCI() {
+			var ok bool
+			if ctlgRef, ok = o.operatorCatalogToFullArtifactPath[ctlg.Catalog]; !ok {
+				return fmt.Errorf("unable to obtain artifact path for %s while resolving dependencies", ctlg.Catalog)
+			}
+		}
+		catalog, err = action.Render{Registry: reg, Refs: []string{ctlgRef}}.Run(ctx)
+		if err != nil {
+			return fmt.Errorf("error rendering catalog %s to resolve dependencies: %v", ctlg.Catalog, err)
+		}
+	}
+
+	if o.OperatorDependencies == dependenciesStrict {
+		missing, err = operator.MissingDependencies(*dc, *catalog)
+		if err != nil {
+			return err
+		}
+		lines := make([]string, 0, len(missing))
+		for _, dep := range missing {
+			lines = append(lines, dep.String())
+		}
+		return fmt.Errorf("catalog %s: operator dependencies are not mirrored, add them to the imageset configuration "+
+			"or use --operator-dependencies=%s:\n%s", ctlg.Catalog, dependenciesPermissive, strings.Join(lines, "\n"))
+	}
+
+	included, missing, err := operator.IncludeDependencies(dc, *catalog)
+	if err != nil {
+		return err
+	}
+	for _, dep := range included {
+		klog.Infof("catalog %s: including dependency: %s", ctlg.Catalog, dep)
+	}
+	for _, dep := range missing {
+		klog.Warningf("catalog %s: dependency not mirrored: %s", ctlg.Catalog, dep)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// deprecatedPackage describes a requested package that the catalog
+// marks as deprecated through an olm.deprecations blob.
+type deprecatedPackage struct {
+	Name    string
+	Message string
+	// Successor is the catalog package referenced by the deprecation
+	// message, if any.
+	Successor string
+}
+
+// findDeprecatedPackages returns the requested packages that are deprecated
+// in dc. The successor of a package is the first other package of the catalog
+// named in its deprecation message, as catalogs have no structured field for it.
+func findDeprecatedPackages(dc *declcfg.DeclarativeConfig, requested []string) []deprecatedPackage {
+	catalogPackages := make(map[string]bool, len(dc.Packages))
+	for _, pkg := range dc.Packages {
+		catalogPackages[pkg.Name] = true
+	}
+	wanted := make(map[string]bool, len(requested))
+	for _, name := range requested {
+		wanted[name] = true
+	}
+
+	var deprecated []deprecatedPackage
+	for _, d := range dc.Deprecations {
+		if !wanted[d.Package] {
+			continue
+		}
+		for _, entry := range d.Entries {
+			if entry.Reference.Schema != declcfg.SchemaPackage {
+				continue
+			}
+			deprecated = append(deprecated, deprecatedPackage{
+				Name:      d.Package,
+				Message:   strings.TrimSpace(entry.Message),
+				Successor: successorFromMessage(entry.Message, d.Package, catalogPackages),
+			})
+		}
+	}
+	sort.Slice(deprecated, func(i, j int) bool {
+		return deprecated[i].Name < deprecated[j].Name
+	})
+	return deprecated
+}
+
+// successorFromMessage returns the first catalog package other than pkg
+// mentioned in msg.
+func successorFromMessage(msg, pkg string, catalogPackages map[string]bool) string {
+	words := strings.FieldsFunc(msg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '_')
+	})
+	for _, word := range words {
+		word = strings.Trim(word, ".-_")
+		if word != pkg && catalogPackages[word] {
+			return word
+		}
+	}
+	return ""
+}
+
+// handleDeprecatedPackages warns about requested packages that are deprecated
+// in the catalog. When IncludeSuccessors is set, the successor packages are added
+// to the include config so that incremental mirrors do not silently stop updating
+// a renamed or replaced operator.
+func (o *OperatorOptions) handleDeprecatedPackages(
+	ctx context.Context,
+	reg *containerdregistry.Registry,
+	ctlg v1alpha2.Operator,
+) (v1alpha2.Operator, error) {
+	if len(ctlg.IncludeConfig.Packages) == 0 {
+		return ctlg, nil
+	}
+
+	ctlgRef := ctlg.Catalog
+	if ctlg.IsFBCDir() {
+		ctlgRef = v1alpha2.TrimProtocol(ctlg.Catalog)
+	}
+	if ctlg.IsFBCOCI() {
+		var ok bool
+		if ctlgRef, ok = o.operatorCatalogToFullArtifactPath[ctlg.Catalog]; !ok {
+			return ctlg, fmt.Errorf("unable to obtain artifact path for %s while checking deprecations", ctlg.Catalog)
+		}
+	}
+	dc, err := o.renderCatalog(ctx, reg, ctlgRef)
+	if err != nil {
+		return ctlg, err
+	}
+
+	// Do not modify the packages of the caller's configuration.
+	ctlg.IncludeConfig.Packages = append([]v1alpha2.IncludePackage{}, ctlg.IncludeConfig.Packages...)
+	requested := make(map[string]bool, len(ctlg.IncludeConfig.Packages))
+	names := make([]string, 0, len(ctlg.IncludeConfig.Packages))
+	for _, pkg := range ctlg.IncludeConfig.Packages {
+		requested[pkg.Name] = true
+		names = append(names, pkg.Name)
+	}
+
+	for _, dp := range findDeprecatedPackages(dc, names) {
+		switch {
+		case dp.Successor == "":
+			o.Logger.Warnf("package %s is deprecated in catalog %s: %s", dp.Name, ctlg.Catalog, dp.Message)
+		case !ctlg.IncludeSuccessors:
+			o.Logger.Warnf("package %s is deprecated in catalog %s in favor of %s, set includeSuccessors to mirror it: %s", dp.Name, ctlg.Catalog, dp.Successor, dp.Message)
+		case requested[dp.Successor]:
+			o.Logger.Warnf("package %s is deprecated in catalog %s in favor of %s, which is already included", dp.Name, ctlg.Catalog, dp.Successor)
+		default:
+			o.Logger.Warnf("package %s is deprecated in catalog %s, including its successor %s", dp.Name, ctlg.Catalog, dp.Successor)
+			ctlg.IncludeConfig.Packages = append(ctlg.IncludeConfig.Packages, v1alpha2.IncludePackage{Name: dp.Successor})
+			requested[dp.Successor] = true
+		}
+	}
+
+	return ctlg, nil
+}
@@ -0,0 +1,104 @@
+package mirror
+
+import (
+	"context"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDeprecatedPackages(t *testing.T) {
+	dc := &declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Name: "foo-operator"},
+			{Name: "foo-operator-v2"},
+			{Name: "bar"},
+			{Name: "baz"},
+		},
+		Deprecations: []declcfg.Deprecation{
+			{
+				Package: "foo-operator",
+				Entries: []declcfg.DeprecationEntry{
+					{
+						Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaChannel, Name: "alpha"},
+						Message:   "channel alpha is no longer maintained, use bar instead",
+					},
+					{
+						Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaPackage},
+						Message:   "The 'foo-operator' package is deprecated. Please use 'foo-operator-v2'.",
+					},
+				},
+			},
+			{
+				Package: "bar",
+				Entries: []declcfg.DeprecationEntry{
+					{
+						Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaPackage},
+						Message:   "bar is end of life.",
+					},
+				},
+			},
+			{
+				Package: "baz",
+				Entries: []declcfg.DeprecationEntry{
+					{
+						Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaPackage},
+						Message:   "baz is replaced by bar",
+					},
+				},
+			},
+		},
+	}
+
+	type spec struct {
+		name      string
+		requested []string
+		exp       []deprecatedPackage
+	}
+
+	specs := []spec{
+		{
+			name:      "Valid/SuccessorFromMessage",
+			requested: []string{"foo-operator"},
+			exp: []deprecatedPackage{
+				{
+					Name:      "foo-operator",
+					Message:   "The 'foo-operator' package is deprecated. Please use 'foo-operator-v2'.",
+					Successor: "foo-operator-v2",
+				},
+			},
+		},
+		{
+			name:      "Valid/NoSuccessor",
+			requested: []string{"bar", "baz"},
+			exp: []deprecatedPackage{
+				{Name: "bar", Message: "bar is end of life."},
+				{Name: "baz", Message: "baz is replaced by bar", Successor: "bar"},
+			},
+		},
+		{
+			name:      "Valid/NotRequested",
+			requested: []string{"foo-operator-v2"},
+			exp:       nil,
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			require.Equal(t, s.exp, findDeprecatedPackages(dc, s.requested))
+		})
+	}
+}
+
+func TestRenderCatalogOnce(t *testing.T) {
+	o := &OperatorOptions{}
+	ref := "testdata/manifestlist/testonly/configs"
+	dc, err := o.renderCatalog(context.Background(), nil, ref)
+	require.NoError(t, err)
+	require.Len(t, dc.Packages, 2)
+
+	again, err := o.renderCatalog(context.Background(), nil, ref)
+	require.NoError(t, err)
+	require.Same(t, dc, again)
+}
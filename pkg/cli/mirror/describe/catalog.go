@@ -0,0 +1,127 @@
+package describe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/network"
+	"github.com/openshift/oc-mirror/pkg/operator"
+)
+
+type CatalogOptions struct {
+	*cli.RootOptions
+	Catalog  string
+	Insecure bool
+}
+
+func NewCatalogCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := CatalogOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "catalog <image>",
+		Short: "Print the provenance of a catalog image rebuilt by oc-mirror",
+		Long: templates.LongDesc(`
+			Print the provenance of a catalog image rebuilt by oc-mirror: the catalog
+			it is filtered from and its digest, the digest of the imageset configuration
+			filter, and the version of oc-mirror that built it and when.
+		`),
+		Example: templates.Examples(`
+			# Output the provenance of a mirrored catalog
+			oc-mirror describe catalog mirror.local/redhat/redhat-operator-index:v4.14
+		`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Insecure, "insecure", o.Insecure, "Allow pulling the catalog image over HTTP or from a registry with an invalid certificate")
+
+	return cmd
+}
+
+func (o *CatalogOptions) Complete(args []string) error {
+	o.Catalog = args[0]
+	return nil
+}
+
+func (o *CatalogOptions) Run(ctx context.Context) error {
+	var nameOpts []name.Option
+	if o.Insecure {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+	ref, err := name.ParseReference(o.Catalog, nameOpts...)
+	if err != nil {
+		return err
+	}
+	desc, err := remote.Get(ref,
+		remote.WithAuthFromKeychain(image.Keychain()),
+		remote.WithTransport(network.NewTransport(o.Insecure)),
+		remote.WithContext(ctx),
+	)
+	if err != nil {
+		return fmt.Errorf("error retrieving catalog %s: %v", o.Catalog, err)
+	}
+	img, err := catalogImage(desc)
+	if err != nil {
+		return fmt.Errorf("error retrieving catalog %s: %v", o.Catalog, err)
+	}
+	p, err := catalogProvenance(img)
+	if err != nil {
+		return fmt.Errorf("catalog %s: %v", o.Catalog, err)
+	}
+
+	data, err := json.MarshalIndent(&p, "", " ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(o.IOStreams.Out, string(data))
+
+	return nil
+}
+
+// catalogImage returns the image of desc, or the first image of
+// its index. Every image of a rebuilt catalog has the same labels.
+func catalogImage(desc *remote.Descriptor) (v1.Image, error) {
+	if !desc.MediaType.IsIndex() {
+		return desc.Image()
+	}
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Manifests) == 0 {
+		return nil, errors.New("empty image index")
+	}
+	return idx.Image(manifest.Manifests[0].Digest)
+}
+
+// catalogProvenance returns the provenance recorded in the labels of img.
+func catalogProvenance(img v1.Image) (operator.Provenance, error) {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return operator.Provenance{}, err
+	}
+	p, found := operator.ProvenanceFromLabels(cfg.Config.Labels)
+	if !found {
+		return p, errors.New("no provenance labels, the catalog was not rebuilt by oc-mirror")
+	}
+	return p, nil
+}
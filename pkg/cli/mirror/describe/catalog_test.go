@@ -0,0 +1,84 @@
+package describe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/operator"
+)
+
+func TestCatalogRun(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	exp := operator.Provenance{
+		SourceCatalog:  "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+		SourceDigest:   "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		FilterDigest:   "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+		Version:        "v4.17.0",
+		BuildTimestamp: "2024-05-01T10:00:00Z",
+	}
+	push := func(repo string, labels map[string]string) string {
+		cfg, err := empty.Image.ConfigFile()
+		require.NoError(t, err)
+		cfg.Config.Labels = labels
+		img, err := mutate.ConfigFile(empty.Image, cfg)
+		require.NoError(t, err)
+		ref, err := name.ParseReference(u.Host + "/" + repo + ":v1")
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img))
+		return ref.String()
+	}
+
+	type spec struct {
+		name     string
+		catalog  string
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name:    "Valid/Rebuilt",
+			catalog: push("redhat/rebuilt-index", exp.Labels()),
+		},
+		{
+			name:     "Invalid/NotRebuilt",
+			catalog:  push("redhat/upstream-index", map[string]string{"vendor": "Red Hat"}),
+			expError: "no provenance labels",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &CatalogOptions{
+				RootOptions: &cli.RootOptions{IOStreams: genericclioptions.IOStreams{Out: out}},
+				Insecure:    true,
+			}
+			require.NoError(t, o.Complete([]string{c.catalog}))
+			err := o.Run(context.Background())
+			if c.expError != "" {
+				require.ErrorContains(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			var actual operator.Provenance
+			require.NoError(t, json.Unmarshal(out.Bytes(), &actual))
+			require.Equal(t, exp, actual)
+		})
+	}
+}
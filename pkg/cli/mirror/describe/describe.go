@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -12,11 +13,16 @@ import (
 
 	"github.com/openshift/oc-mirror/pkg/bundle"
 	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
 )
 
 type DescribeOptions struct {
 	*cli.RootOptions
-	From string
+	From      string
+	Diff      bool   // If set, compares the images mirrored as of two sequences of the workspace
+	Workspace string // Workspace directory holding the sequence history, for --diff
+	// Sequences compared by --diff, set by Complete.
+	fromSeq, toSeq int
 }
 
 func NewDescribeCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
@@ -24,13 +30,30 @@ func NewDescribeCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command
 	o.RootOptions = ro
 
 	cmd := &cobra.Command{
-		Use:   "describe <archive path>",
+		Use:   "describe <archive path> | --diff <sequence> <sequence>",
 		Short: "Pretty print the contents of mirror metadata",
+		Long: templates.LongDesc(`
+			Pretty print the contents of mirror metadata.
+
+			With --diff, print the images added, removed and updated between two
+			mirror sequences of the workspace, with the size of the blobs transported
+			by the sequences in between, to document what each incremental mirror
+			brought. The workspace records the images of every sequence it creates.
+		`),
 		Example: templates.Examples(`
 			# Output the contents of 'mirror_seq1_00000.tar'
 			oc-mirror describe mirror_seq1_00000.tar
+
+			# Output the images changed between sequences 3 and 5 of the workspace
+			oc-mirror describe --diff 3 5 --workspace archives/oc-mirror-workspace
+
+			# Output the provenance of a catalog image rebuilt by oc-mirror
+			oc-mirror describe catalog mirror.local/redhat/redhat-operator-index:v4.14
+
+			# Output where an image was mirrored by the sequences of the workspace
+			oc-mirror describe image registry.redhat.io/ubi8/ruby-30:latest --workspace archives/oc-mirror-workspace
 		`),
-		Args: cobra.ExactArgs(1),
+		Args: cobra.RangeArgs(1, 2),
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(args))
 			kcmdutil.CheckErr(o.Validate())
@@ -38,12 +61,30 @@ func NewDescribeCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command
 		},
 	}
 
+	cmd.AddCommand(NewCatalogCommand(f, ro))
+	cmd.AddCommand(NewImageCommand(f, ro))
+
 	o.BindFlags(cmd.PersistentFlags())
+	cmd.Flags().BoolVar(&o.Diff, "diff", o.Diff, "Print the images added, removed and updated between the two sequences given as arguments")
+	cmd.Flags().StringVar(&o.Workspace, "workspace", config.DefaultWorkspaceName, "Workspace directory holding the history of the sequences, for --diff")
 
 	return cmd
 }
 
 func (o *DescribeOptions) Complete(args []string) error {
+	if o.Diff {
+		if len(args) != 2 {
+			return errors.New("--diff requires two sequences")
+		}
+		var err error
+		if o.fromSeq, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("invalid sequence %q", args[0])
+		}
+		if o.toSeq, err = strconv.Atoi(args[1]); err != nil {
+			return fmt.Errorf("invalid sequence %q", args[1])
+		}
+		return nil
+	}
 	if len(args) == 1 {
 		o.From = args[0]
 	}
@@ -51,6 +92,12 @@ func (o *DescribeOptions) Complete(args []string) error {
 }
 
 func (o *DescribeOptions) Validate() error {
+	if o.Diff {
+		if o.fromSeq >= o.toSeq {
+			return fmt.Errorf("sequence %d is not older than sequence %d", o.fromSeq, o.toSeq)
+		}
+		return nil
+	}
 	if len(o.From) == 0 {
 		return errors.New("must specify path to imageset archive")
 	}
@@ -58,6 +105,13 @@ func (o *DescribeOptions) Validate() error {
 }
 
 func (o *DescribeOptions) Run(ctx context.Context) error {
+	if o.Diff {
+		diff, err := diffSequences(o.Workspace, o.fromSeq, o.toSeq)
+		if err != nil {
+			return err
+		}
+		return diff.write(o.IOStreams.Out)
+	}
 
 	meta, err := bundle.ReadMetadataFromFile(ctx, o.From)
 	if err != nil {
@@ -30,6 +30,28 @@ func TestDescribeComplete(t *testing.T) {
 				From: "foo",
 			},
 		},
+		{
+			name: "Valid/Diff",
+			opts: &DescribeOptions{Diff: true},
+			args: []string{"3", "5"},
+			expOpts: &DescribeOptions{
+				Diff:    true,
+				fromSeq: 3,
+				toSeq:   5,
+			},
+		},
+		{
+			name:     "Invalid/DiffOneSequence",
+			opts:     &DescribeOptions{Diff: true},
+			args:     []string{"3"},
+			expError: "--diff requires two sequences",
+		},
+		{
+			name:     "Invalid/DiffSequence",
+			opts:     &DescribeOptions{Diff: true},
+			args:     []string{"3", "latest"},
+			expError: `invalid sequence "latest"`,
+		},
 	}
 
 	for _, c := range cases {
@@ -59,6 +81,11 @@ func TestDescribeValidate(t *testing.T) {
 			opts:     &DescribeOptions{},
 			expError: `must specify path to imageset archive`,
 		},
+		{
+			name:     "Invalid/DiffOrder",
+			opts:     &DescribeOptions{Diff: true, fromSeq: 5, toSeq: 3},
+			expError: `sequence 5 is not older than sequence 3`,
+		},
 		{
 			name: "Valid/WithArchivePath",
 			opts: &DescribeOptions{
@@ -0,0 +1,211 @@
+package describe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata"
+)
+
+// Changes of an image between two sequences.
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeUpdated = "updated"
+)
+
+// imageChange is an image added, removed or updated between two sequences.
+type imageChange struct {
+	Name   string
+	Type   v1alpha2.ImageType
+	Change string
+	// ID is the digest of the image in the newer sequence,
+	// in the older one for removed images.
+	ID string
+	// Size is the size of the blobs of the image transported by the
+	// sequences after the older one, -1 when it was not recorded.
+	Size int64
+}
+
+// sequenceDiff holds the images changed between two sequences.
+type sequenceDiff struct {
+	From, To metadata.SequenceRecord
+	Changes  []imageChange
+	// Transported is the size of the blobs transported by the sequences
+	// after From, -1 when the size of a sequence was not recorded.
+	Transported int64
+}
+
+// diffSequences compares the images mirrored as of sequences from and to,
+// from the history of workspace.
+func diffSequences(workspace string, from, to int) (sequenceDiff, error) {
+	var diff sequenceDiff
+	// The associations of the sequences are compared from disk.
+	dir, err := os.MkdirTemp("", "oc-mirror-describe-")
+	if err != nil {
+		return diff, err
+	}
+	defer os.RemoveAll(dir)
+	var older, newer *image.AssociationDB
+	if diff.From, older, err = metadata.ReadSequenceRecord(workspace, from, filepath.Join(dir, "from.db")); err != nil {
+		return diff, err
+	}
+	defer older.Close()
+	if diff.To, newer, err = metadata.ReadSequenceRecord(workspace, to, filepath.Join(dir, "to.db")); err != nil {
+		return diff, err
+	}
+	defer newer.Close()
+
+	// The blobs transported between the sequences are the
+	// blobs of the sequences after from.
+	sizes := map[string]int64{}
+	sizesKnown := true
+	for seq := from + 1; seq <= to; seq++ {
+		rec := diff.To
+		if seq != to {
+			rec, _, err = metadata.ReadSequenceRecord(workspace, seq, "")
+			if errors.Is(err, metadata.ErrSequenceNotRecorded) {
+				sizesKnown = false
+				continue
+			}
+			if err != nil {
+				return diff, err
+			}
+		}
+		if rec.BlobSizes == nil {
+			sizesKnown = false
+		}
+		for digest, size := range rec.BlobSizes {
+			sizes[digest] = size
+		}
+	}
+	diff.Transported = -1
+	if sizesKnown {
+		diff.Transported = 0
+		for _, size := range sizes {
+			diff.Transported += size
+		}
+	}
+
+	err = newer.ForEach(func(name string, assocs image.Associations) error {
+		change := imageChange{Name: name, Type: assocs[name].Type, ID: assocs[name].ID, Size: -1}
+		prev, found, err := older.Search(name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case !found:
+			change.Change = changeAdded
+		case imageID(prev, name) != change.ID:
+			change.Change = changeUpdated
+		default:
+			return nil
+		}
+		if sizesKnown {
+			change.Size = transportedSize(assocs, sizes)
+		}
+		diff.Changes = append(diff.Changes, change)
+		return nil
+	})
+	if err != nil {
+		return diff, fmt.Errorf("sequence %d: %v", to, err)
+	}
+	err = older.ForEach(func(name string, assocs image.Associations) error {
+		found, err := newer.SetContainsKey(name)
+		if err == nil && !found {
+			diff.Changes = append(diff.Changes, imageChange{Name: name, Type: assocs[name].Type, Change: changeRemoved, ID: assocs[name].ID, Size: -1})
+		}
+		return err
+	})
+	if err != nil {
+		return diff, fmt.Errorf("sequence %d: %v", from, err)
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		if diff.Changes[i].Change != diff.Changes[j].Change {
+			return diff.Changes[i].Change < diff.Changes[j].Change
+		}
+		return diff.Changes[i].Name < diff.Changes[j].Name
+	})
+	return diff, nil
+}
+
+// imageID returns the digest of the image name of its associations assocs.
+func imageID(assocs []v1alpha2.Association, name string) string {
+	for _, assoc := range assocs {
+		if assoc.Name == name {
+			return assoc.ID
+		}
+	}
+	return ""
+}
+
+// transportedSize returns the size of the layers of assocs found in sizes,
+// counting the layers shared by several manifests of an index once.
+func transportedSize(assocs image.Associations, sizes map[string]int64) int64 {
+	seen := map[string]bool{}
+	var total int64
+	for _, assoc := range assocs {
+		for _, digest := range assoc.LayerDigests {
+			if !seen[digest] {
+				seen[digest] = true
+				total += sizes[digest]
+			}
+		}
+	}
+	return total
+}
+
+// write prints the report of diff to w.
+func (diff sequenceDiff) write(w io.Writer) error {
+	counts := map[string]int{}
+	for _, c := range diff.Changes {
+		counts[c.Change]++
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "From sequence:\t%d (%s)\n", diff.From.Sequence, formatTimestamp(diff.From.Timestamp))
+	fmt.Fprintf(tw, "To sequence:\t%d (%s)\n", diff.To.Sequence, formatTimestamp(diff.To.Timestamp))
+	fmt.Fprintf(tw, "Images added:\t%d\n", counts[changeAdded])
+	fmt.Fprintf(tw, "Images removed:\t%d\n", counts[changeRemoved])
+	fmt.Fprintf(tw, "Images updated:\t%d\n", counts[changeUpdated])
+	fmt.Fprintf(tw, "Transported:\t%s\n", formatSize(diff.Transported))
+	if len(diff.Changes) != 0 {
+		fmt.Fprintf(tw, "\nCHANGE\tIMAGE\tTYPE\tDIGEST\tSIZE\n")
+		for _, c := range diff.Changes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Change, c.Name, c.Type, c.ID, formatSize(c.Size))
+		}
+	}
+	return tw.Flush()
+}
+
+func formatTimestamp(timestamp int) string {
+	return time.Unix(int64(timestamp), 0).UTC().Format(time.RFC3339)
+}
+
+// formatSize returns size in the largest binary unit it reaches,
+// with one decimal, and - for unknown sizes.
+func formatSize(size int64) string {
+	if size < 0 {
+		return "-"
+	}
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size)
+	units := []string{"KiB", "MiB", "GiB", "TiB"}
+	var unit string
+	for _, unit = range units {
+		value /= 1024
+		if value < 1024 {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, unit)
+}
@@ -0,0 +1,90 @@
+package describe
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata"
+)
+
+func TestDescribeDiff(t *testing.T) {
+	workspace := t.TempDir()
+	app := func(tag, id string, layers ...string) v1alpha2.Association {
+		return v1alpha2.Association{Name: "quay.io/foo/app:" + tag, Path: "foo/app", ID: id, Type: v1alpha2.TypeGeneric, LayerDigests: layers}
+	}
+	records := []recordedSequence{
+		{
+			SequenceRecord: metadata.SequenceRecord{Sequence: 1, Timestamp: 1700000000, BlobSizes: map[string]int64{"sha256:aaaa": 100, "sha256:bbbb": 200}},
+			Associations:   []v1alpha2.Association{app("v1", "sha256:1111", "sha256:aaaa"), app("v2", "sha256:2222", "sha256:bbbb")},
+		},
+		{
+			SequenceRecord: metadata.SequenceRecord{Sequence: 2, Timestamp: 1700086400, BlobSizes: map[string]int64{"sha256:cccc": 3 << 20}},
+			Associations:   []v1alpha2.Association{app("v2", "sha256:3333", "sha256:bbbb", "sha256:cccc")},
+		},
+		{
+			SequenceRecord: metadata.SequenceRecord{Sequence: 3, Timestamp: 1700172800, BlobSizes: map[string]int64{"sha256:dddd": 2048}},
+			Associations:   []v1alpha2.Association{app("v2", "sha256:3333", "sha256:bbbb", "sha256:cccc"), app("v3", "sha256:4444", "sha256:dddd")},
+		},
+	}
+	for _, rec := range records {
+		rec.write(t, workspace)
+	}
+
+	out := new(strings.Builder)
+	opts := &DescribeOptions{
+		RootOptions: &cli.RootOptions{IOStreams: genericclioptions.IOStreams{Out: out, In: os.Stdin, ErrOut: os.Stderr}},
+		Diff:        true,
+		Workspace:   workspace,
+	}
+	require.NoError(t, opts.Complete([]string{"1", "3"}))
+	require.NoError(t, opts.Validate())
+	require.NoError(t, opts.Run(context.TODO()))
+	require.Equal(t, `From sequence:   1 (2023-11-14T22:13:20Z)
+To sequence:     3 (2023-11-16T22:13:20Z)
+Images added:    1
+Images removed:  1
+Images updated:  1
+Transported:     3.0 MiB
+
+CHANGE   IMAGE               TYPE     DIGEST       SIZE
+added    quay.io/foo/app:v3  generic  sha256:4444  2.0 KiB
+removed  quay.io/foo/app:v1  generic  sha256:1111  -
+updated  quay.io/foo/app:v2  generic  sha256:3333  3.0 MiB
+`, out.String())
+
+	t.Run("Invalid/NotRecorded", func(t *testing.T) {
+		_, err := diffSequences(workspace, 3, 4)
+		require.EqualError(t, err, "sequence 4: sequence not recorded in the workspace")
+	})
+
+	t.Run("Valid/UnknownSizes", func(t *testing.T) {
+		workspace := t.TempDir()
+		records[0].write(t, workspace)
+		records[2].write(t, workspace)
+		diff, err := diffSequences(workspace, 1, 3)
+		require.NoError(t, err)
+		require.Equal(t, int64(-1), diff.Transported)
+		require.Len(t, diff.Changes, 3)
+	})
+}
+
+// recordedSequence is a sequence record of the history with its associations.
+type recordedSequence struct {
+	metadata.SequenceRecord
+	Associations []v1alpha2.Association
+}
+
+// write writes r to the history of workspace.
+func (r recordedSequence) write(t *testing.T, workspace string) {
+	assocs, err := image.ConvertToAssociationSet(r.Associations)
+	require.NoError(t, err)
+	require.NoError(t, metadata.WriteSequenceRecord(workspace, r.SequenceRecord, assocs))
+}
@@ -0,0 +1,316 @@
+package describe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata"
+)
+
+type ImageOptions struct {
+	*cli.RootOptions
+	Image     string
+	Workspace string // Workspace directory holding the sequence history
+}
+
+func NewImageCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := ImageOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "image <image>",
+		Short: "Print where a source image was mirrored and what referenced it",
+		Long: templates.LongDesc(`
+			Print where a source image was mirrored by each sequence of the workspace,
+			the release or operator bundles referencing it, and its layer digests.
+
+			The image is matched by repository, and by tag or digest if given; a digest
+			also matches the images mirrored by a tag resolving to it. The operator
+			bundles referencing the image are the ones of the last run of the workspace.
+		`),
+		Example: templates.Examples(`
+			# Output where an image pulled by a cluster was mirrored
+			oc-mirror describe image registry.redhat.io/ubi8/ruby-30@sha256:<digest> --workspace archives/oc-mirror-workspace
+		`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Workspace, "workspace", config.DefaultWorkspaceName, "Workspace directory holding the history of the sequences")
+
+	return cmd
+}
+
+func (o *ImageOptions) Complete(args []string) error {
+	o.Image = args[0]
+	return nil
+}
+
+func (o *ImageOptions) Run() error {
+	descs, err := describeImage(o.Workspace, o.Image)
+	if err != nil {
+		return err
+	}
+	for i, desc := range descs {
+		if i > 0 {
+			fmt.Fprintln(o.IOStreams.Out)
+		}
+		if err := desc.write(o.IOStreams.Out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imageMirror is a sequence that mirrored an image.
+type imageMirror struct {
+	Sequence  int
+	Timestamp int
+	// Destination is the path of the image in the archive or registry it
+	// was mirrored to. Images published from an archive are relative to
+	// the registry and namespace they were published to.
+	Destination string
+	ID          string
+}
+
+// imageDescription holds the mirrors of a source image recorded in
+// the workspace history and what referenced it.
+type imageDescription struct {
+	Name    string
+	Type    v1alpha2.ImageType
+	Mirrors []imageMirror
+	// References are the releases and operator bundles referencing the image.
+	References []string
+	// Layers are the layer digests of the image as of its last sequence,
+	// of every manifest for an index.
+	Layers []string
+}
+
+// describeImage returns the images of the history of workspace
+// matching img, sorted by name.
+func describeImage(workspace, img string) ([]imageDescription, error) {
+	query, err := reference.Parse(img)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %s: %v", img, err)
+	}
+	seqs, err := metadata.ListSequenceRecords(workspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(seqs) == 0 {
+		return nil, fmt.Errorf("no sequence recorded in the history of workspace %s", workspace)
+	}
+
+	// The associations of the sequences are read from disk one sequence at a time.
+	dir, err := os.MkdirTemp("", "oc-mirror-describe-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	descs := map[string]*imageDescription{}
+	// The releases referencing an image are the ones of the
+	// last sequence mirroring it.
+	releaseRefs := map[string][]string{}
+	for _, seq := range seqs {
+		if err := describeSequence(workspace, seq, filepath.Join(dir, "sequence.db"), query, descs, releaseRefs); err != nil {
+			return nil, err
+		}
+	}
+	if len(descs) == 0 {
+		return nil, fmt.Errorf("image %s not found in the history of workspace %s", img, workspace)
+	}
+
+	usages, err := readOperatorImageUsage(workspace)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]imageDescription, 0, len(descs))
+	for name, desc := range descs {
+		desc.References = append(releaseRefs[name], usages[name]...)
+		result = append(result, *desc)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// describeSequence adds the mirrors of the images matching query recorded
+// by sequence seq of the history of workspace to descs, with the releases
+// referencing them to releaseRefs, reading its associations into dbPath.
+func describeSequence(workspace string, seq int, dbPath string, query reference.DockerImageReference, descs map[string]*imageDescription, releaseRefs map[string][]string) error {
+	rec, assocDB, err := metadata.ReadSequenceRecord(workspace, seq, dbPath)
+	if err != nil {
+		return err
+	}
+	defer assocDB.Close()
+	err = assocDB.ForEach(func(name string, assocs image.Associations) error {
+		assoc := assocs[name]
+		if !matchesImage(query, name, assoc.ID) {
+			return nil
+		}
+		desc, ok := descs[name]
+		if !ok {
+			desc = &imageDescription{Name: name}
+			descs[name] = desc
+		}
+		desc.Type = assoc.Type
+		desc.Mirrors = append(desc.Mirrors, imageMirror{
+			Sequence:    rec.Sequence,
+			Timestamp:   rec.Timestamp,
+			Destination: assoc.Path,
+			ID:          assoc.ID,
+		})
+		desc.Layers = layerDigests(assocs)
+		refs, err := releaseReferences(assocDB, name, assoc)
+		releaseRefs[name] = refs
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("sequence %d: %v", seq, err)
+	}
+	return nil
+}
+
+// matchesImage returns whether the image name, recorded with the digest
+// id, is an image of the repository of query with the tag or digest of
+// query, if any.
+func matchesImage(query reference.DockerImageReference, name, id string) bool {
+	ref, err := reference.Parse(name)
+	if err != nil {
+		return false
+	}
+	if ref.DockerClientDefaults().AsRepository() != query.DockerClientDefaults().AsRepository() {
+		return false
+	}
+	switch {
+	case query.ID != "":
+		return query.ID == ref.ID || query.ID == id
+	case query.Tag != "":
+		return query.Tag == ref.Tag
+	}
+	return true
+}
+
+// layerDigests returns the layer digests of assocs, sorted.
+func layerDigests(assocs image.Associations) []string {
+	seen := map[string]bool{}
+	var layers []string
+	for _, assoc := range assocs {
+		for _, digest := range assoc.LayerDigests {
+			if !seen[digest] {
+				seen[digest] = true
+				layers = append(layers, digest)
+			}
+		}
+	}
+	sort.Strings(layers)
+	return layers
+}
+
+// releaseReferences returns the releases of assocs the release content image
+// name, of association component, is a component of. The components of a
+// release are mirrored with the tag of the release followed by the name of
+// the component.
+func releaseReferences(assocs image.AssociationIterator, name string, component v1alpha2.Association) ([]string, error) {
+	if component.Type != v1alpha2.TypeOCPReleaseContent {
+		return nil, nil
+	}
+	componentRef, err := reference.Parse(component.Path)
+	if err != nil || componentRef.Tag == "" {
+		return nil, nil
+	}
+	var refs []string
+	err = assocs.ForEach(func(releaseName string, values image.Associations) error {
+		release := values[releaseName]
+		if release.Type != v1alpha2.TypeOCPRelease {
+			return nil
+		}
+		releaseRef, err := reference.Parse(release.Path)
+		if err != nil || releaseRef.Tag == "" {
+			return nil
+		}
+		if c, found := strings.CutPrefix(componentRef.Tag, releaseRef.Tag+"-"); found {
+			refs = append(refs, fmt.Sprintf("release %s (component %s)", releaseName, c))
+		}
+		return nil
+	})
+	sort.Strings(refs)
+	return refs, err
+}
+
+// readOperatorImageUsage returns the operator bundles referencing each
+// image of the last run of workspace, described and sorted. It returns
+// none if the last run mirrored no operators.
+func readOperatorImageUsage(workspace string) (map[string][]string, error) {
+	data, err := os.ReadFile(filepath.Join(workspace, config.OperatorImageUsageFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var usages []struct {
+		Image      string `json:"image"`
+		References []struct {
+			Catalog string `json:"catalog"`
+			Package string `json:"package"`
+			Bundle  string `json:"bundle"`
+		} `json:"references"`
+	}
+	if err := json.Unmarshal(data, &usages); err != nil {
+		return nil, fmt.Errorf("error reading %s: %v", config.OperatorImageUsageFile, err)
+	}
+	refs := make(map[string][]string, len(usages))
+	for _, u := range usages {
+		for _, r := range u.References {
+			refs[u.Image] = append(refs[u.Image], fmt.Sprintf("bundle %s of package %s in catalog %s", r.Bundle, r.Package, r.Catalog))
+		}
+	}
+	return refs, nil
+}
+
+// write prints desc to w.
+func (desc imageDescription) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Image:\t%s\n", desc.Name)
+	fmt.Fprintf(tw, "Type:\t%s\n", desc.Type)
+	fmt.Fprintf(tw, "\nSEQUENCE\tMIRRORED\tDESTINATION\tDIGEST\n")
+	for _, m := range desc.Mirrors {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", m.Sequence, formatTimestamp(m.Timestamp), m.Destination, m.ID)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "\nReferenced by:")
+	if len(desc.References) == 0 {
+		fmt.Fprintln(w, "  no release or operator bundle of the last run")
+	}
+	for _, r := range desc.References {
+		fmt.Fprintf(w, "  %s\n", r)
+	}
+	fmt.Fprintln(w, "\nLayers:")
+	for _, l := range desc.Layers {
+		fmt.Fprintf(w, "  %s\n", l)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package describe
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/metadata"
+)
+
+func TestDescribeImage(t *testing.T) {
+	workspace := t.TempDir()
+	const (
+		operatorImage = "registry.redhat.io/foo/bar@sha256:5555555555555555555555555555555555555555555555555555555555555555"
+		releaseImage  = "quay.io/openshift-release-dev/ocp-release:4.14.1-x86_64"
+		componentImg  = "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:7777777777777777777777777777777777777777777777777777777777777777"
+	)
+	app := func(tag, id string, layers ...string) v1alpha2.Association {
+		return v1alpha2.Association{Name: "quay.io/foo/app:" + tag, Path: "foo/app", ID: id, Type: v1alpha2.TypeGeneric, LayerDigests: layers}
+	}
+	release := []v1alpha2.Association{
+		{Name: releaseImage, Path: "openshift/release-images:4.14.1-x86_64", ID: "sha256:6666666666666666666666666666666666666666666666666666666666666666", Type: v1alpha2.TypeOCPRelease, LayerDigests: []string{"sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"}},
+		{Name: componentImg, Path: "openshift/release:4.14.1-x86_64-cluster-samples-operator", ID: "sha256:7777777777777777777777777777777777777777777777777777777777777777", Type: v1alpha2.TypeOCPReleaseContent, LayerDigests: []string{"sha256:eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"}},
+	}
+	records := []recordedSequence{
+		{
+			SequenceRecord: metadata.SequenceRecord{Sequence: 1, Timestamp: 1700000000},
+			Associations:   append([]v1alpha2.Association{app("v1", "sha256:1111111111111111111111111111111111111111111111111111111111111111", "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")}, release...),
+		},
+		{
+			SequenceRecord: metadata.SequenceRecord{Sequence: 2, Timestamp: 1700086400},
+			Associations: append([]v1alpha2.Association{
+				app("v1", "sha256:2222222222222222222222222222222222222222222222222222222222222222", "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"),
+				app("v2", "sha256:3333333333333333333333333333333333333333333333333333333333333333", "sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"),
+				{Name: operatorImage, Path: "foo/bar", ID: "sha256:5555555555555555555555555555555555555555555555555555555555555555", Type: v1alpha2.TypeOperatorRelatedImage, LayerDigests: []string{"sha256:dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd"}},
+			}, release...),
+		},
+	}
+	for _, rec := range records {
+		rec.write(t, workspace)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, config.OperatorImageUsageFile), []byte(`[
+  {"image": "registry.redhat.io/foo/bar@sha256:5555555555555555555555555555555555555555555555555555555555555555", "references": [
+    {"catalog": "registry.redhat.io/redhat/redhat-operator-index:v4.14", "package": "bar", "bundle": "bar.v1.0.0"}
+  ]}
+]`), 0600))
+
+	type spec struct {
+		name     string
+		image    string
+		expNames []string
+		expError string
+	}
+	cases := []spec{
+		{name: "Valid/Tag", image: "quay.io/foo/app:v1", expNames: []string{"quay.io/foo/app:v1"}},
+		{name: "Valid/Repository", image: "quay.io/foo/app", expNames: []string{"quay.io/foo/app:v1", "quay.io/foo/app:v2"}},
+		{name: "Valid/DigestOfTag", image: "quay.io/foo/app@sha256:3333333333333333333333333333333333333333333333333333333333333333", expNames: []string{"quay.io/foo/app:v2"}},
+		{name: "Invalid/NotMirrored", image: "quay.io/foo/app:v3", expError: "image quay.io/foo/app:v3 not found in the history of workspace " + workspace},
+		{name: "Invalid/Reference", image: "quay.io/Foo", expError: "invalid image reference quay.io/Foo: repository name must be lowercase"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			descs, err := describeImage(workspace, c.image)
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			for _, desc := range descs {
+				names = append(names, desc.Name)
+			}
+			require.Equal(t, c.expNames, names)
+		})
+	}
+	t.Run("Valid/Release", func(t *testing.T) {
+		descs, err := describeImage(workspace, componentImg)
+		require.NoError(t, err)
+		require.Len(t, descs, 1)
+		require.Equal(t, []string{"release " + releaseImage + " (component cluster-samples-operator)"}, descs[0].References)
+		require.Len(t, descs[0].Mirrors, 2)
+	})
+	t.Run("Invalid/NoHistory", func(t *testing.T) {
+		empty := t.TempDir()
+		_, err := describeImage(empty, "quay.io/foo/app:v1")
+		require.EqualError(t, err, "no sequence recorded in the history of workspace "+empty)
+	})
+
+	out := new(strings.Builder)
+	opts := &ImageOptions{
+		RootOptions: &cli.RootOptions{IOStreams: genericclioptions.IOStreams{Out: out, In: os.Stdin, ErrOut: os.Stderr}},
+		Workspace:   workspace,
+	}
+	require.NoError(t, opts.Complete([]string{operatorImage}))
+	require.NoError(t, opts.Run())
+	require.Equal(t, `Image:  registry.redhat.io/foo/bar@sha256:5555555555555555555555555555555555555555555555555555555555555555
+Type:   operatorRelatedImage
+
+SEQUENCE  MIRRORED              DESTINATION  DIGEST
+2         2023-11-15T22:13:20Z  foo/bar      sha256:5555555555555555555555555555555555555555555555555555555555555555
+
+Referenced by:
+  bundle bar.v1.0.0 of package bar in catalog registry.redhat.io/redhat/redhat-operator-index:v4.14
+
+Layers:
+  sha256:dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, opts.Complete([]string{"quay.io/foo/app:v1"}))
+	require.NoError(t, opts.Run())
+	require.Equal(t, `Image:  quay.io/foo/app:v1
+Type:   generic
+
+SEQUENCE  MIRRORED              DESTINATION  DIGEST
+1         2023-11-14T22:13:20Z  foo/app      sha256:1111111111111111111111111111111111111111111111111111111111111111
+2         2023-11-15T22:13:20Z  foo/app      sha256:2222222222222222222222222222222222222222222222222222222222222222
+
+Referenced by:
+  no release or operator bundle of the last run
+
+Layers:
+  sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+  sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb
+`, out.String())
+}
@@ -0,0 +1,92 @@
+package mirror
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// mirrorDestination is a registry, and optional namespace, images are pushed to.
+type mirrorDestination struct {
+	Registry  string
+	Namespace string
+}
+
+func (d mirrorDestination) String() string {
+	return path.Join(d.Registry, d.Namespace)
+}
+
+// dirName returns a name, unique to the destination, suitable for a directory.
+func (d mirrorDestination) dirName() string {
+	return strings.NewReplacer("/", "_", ":", "_").Replace(d.String())
+}
+
+// parseMirrorDestination parses a docker://<registry>[/<namespace>] destination.
+func parseMirrorDestination(destination string, maxNestedPaths int) (mirrorDestination, error) {
+	ref, found := strings.CutPrefix(destination, "docker://")
+	if !found {
+		return mirrorDestination{}, fmt.Errorf("destination %q must use the docker:// scheme", destination)
+	}
+	mirror, err := imagesource.ParseReference(ref)
+	if err != nil {
+		return mirrorDestination{}, err
+	}
+	if err := checkDockerReference(mirror, maxNestedPaths); err != nil {
+		return mirrorDestination{}, err
+	}
+	// get the <namespace>/<image> portion of the docker reference only
+	return mirrorDestination{Registry: mirror.Ref.Registry, Namespace: mirror.Ref.RepositoryName()}, nil
+}
+
+// mirrorDestinations returns every registry destination of the run, the
+// destination given as argument first, followed by the ones set with --to.
+func (o *MirrorOptions) mirrorDestinations() []mirrorDestination {
+	if len(o.destinations) == 0 {
+		return []mirrorDestination{{Registry: o.ToMirror, Namespace: o.UserNamespace}}
+	}
+	return o.destinations
+}
+
+// destinationsString returns every registry destination, comma separated.
+func (o *MirrorOptions) destinationsString() string {
+	var dests []string
+	for _, d := range o.mirrorDestinations() {
+		dests = append(dests, d.String())
+	}
+	return strings.Join(dests, ",")
+}
+
+// setDestination makes d the destination of the subsequent mirroring steps.
+func (o *MirrorOptions) setDestination(d mirrorDestination) {
+	o.ToMirror = d.Registry
+	o.UserNamespace = d.Namespace
+}
+
+// destinationPath returns the path of name in dir for the current destination.
+// When mirroring to several registries a sub directory is used per destination
+// so that the mapping, ICSP and catalog source files of each one are kept apart.
+func (o *MirrorOptions) destinationPath(dir, name string) (string, error) {
+	if len(o.mirrorDestinations()) > 1 {
+		current := mirrorDestination{Registry: o.ToMirror, Namespace: o.UserNamespace}
+		dir = filepath.Join(dir, current.dirName())
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// copyMapping returns a copy of mapping that can be pointed to another registry.
+func copyMapping(mapping image.TypedImageMapping) image.TypedImageMapping {
+	out := make(image.TypedImageMapping, len(mapping))
+	for k, v := range mapping {
+		out[k] = v
+	}
+	return out
+}
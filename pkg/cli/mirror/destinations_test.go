@@ -0,0 +1,46 @@
+package mirror
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDestinationPath(t *testing.T) {
+	type spec struct {
+		name         string
+		destinations []mirrorDestination
+		current      mirrorDestination
+		exp          string
+	}
+
+	specs := []spec{
+		{
+			name:    "Valid/SingleDestination",
+			current: mirrorDestination{Registry: "reg.com", Namespace: "foo"},
+			exp:     "mapping.txt",
+		},
+		{
+			name: "Valid/MultipleDestinations",
+			destinations: []mirrorDestination{
+				{Registry: "reg.com", Namespace: "foo"},
+				{Registry: "reg2.com:5000", Namespace: "bar/baz"},
+			},
+			current: mirrorDestination{Registry: "reg2.com:5000", Namespace: "bar/baz"},
+			exp:     filepath.Join("reg2.com_5000_bar_baz", "mapping.txt"),
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			dir := t.TempDir()
+			opts := &MirrorOptions{destinations: s.destinations}
+			opts.setDestination(s.current)
+			p, err := opts.destinationPath(dir, mappingFile)
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(dir, s.exp), p)
+			require.DirExists(t, filepath.Dir(p))
+		})
+	}
+}
@@ -0,0 +1,463 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	dockercfg "github.com/docker/cli/cli/config"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+const (
+	// doctorMinFreeSpace is the free space of the workspace below
+	// which a warning is reported, a release with a few operators
+	// typically needing tens of GiB.
+	doctorMinFreeSpace = 50 * segMultiplier
+	// doctorMinOpenFiles is the limit of open files below which
+	// a warning is reported, as images are mirrored concurrently.
+	doctorMinOpenFiles = 4096
+	// releaseRegistry is the registry serving the OpenShift and OKD releases.
+	releaseRegistry = "quay.io"
+)
+
+type DoctorOptions struct {
+	*cli.RootOptions
+	ConfigPath          string   // Path to the imageset configuration whose registries and metadata are checked
+	Profile             string   // Name of a profile merged onto the base configuration
+	Overlays            []string // Paths to imageset configurations merged onto the base configuration before the profile
+	ToMirror            string   // Registry the images are mirrored to
+	UserNamespace       string   // The <namespace>/<image> portion of the destination reference
+	DestSkipTLS         bool     // Disable TLS validation for destination registry
+	DestPlainHTTP       bool     // Use plain HTTP for destination registry
+	CredentialProviders []string // Credential providers consulted before the docker/podman credential files
+}
+
+func NewDoctorCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := DoctorOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "doctor [<destination registry>]",
+		Short: "Diagnose the environment before a mirror run",
+		Long: templates.LongDesc(`
+		Diagnose the environment before starting a long mirror run. The opm and
+		credential helper binaries, the credentials of the registries of the
+		imageset configuration, the metadata of its storage configuration, the
+		readiness of the destination registry, the free disk space of the
+		workspace and the limit of open files are checked, and a table of the
+		results is printed with a hint to fix each warning or failure.
+	`),
+		Example: templates.Examples(`
+			# Diagnose the environment of a mirror to mirror run
+			oc-mirror doctor --config mirror-config.yaml docker://localhost:5000/namespace
+
+			# Diagnose the environment of a mirror to disk run
+			oc-mirror doctor --config mirror-config.yaml
+		`),
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Validate())
+			checkErr(o.Run(cmd.Context()))
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVarP(&o.ConfigPath, "config", "c", o.ConfigPath, "Path to imageset configuration file")
+	fs.StringVar(&o.Profile, "profile", o.Profile, "Name of a profile in the imageset configuration to merge onto the base configuration")
+	fs.StringArrayVar(&o.Overlays, "overlay", o.Overlays, "Path to an imageset configuration to merge onto the base configuration before the profile. "+
+		"Can be repeated, each overlay taking precedence over the previous ones")
+	fs.BoolVar(&o.DestSkipTLS, "dest-skip-tls", o.DestSkipTLS, "Disable TLS validation for destination registry")
+	fs.BoolVar(&o.DestPlainHTTP, "dest-use-http", o.DestPlainHTTP, "Use plain HTTP for destination registry")
+	fs.StringArrayVar(&o.CredentialProviders, "credential-provider", o.CredentialProviders, "Credential provider to consult, in order, before the docker/podman "+
+		"credential files: env, file=<path>, ecr or exec=<command>")
+	o.BindFlags(cmd.PersistentFlags())
+
+	return cmd
+}
+
+func (o *DoctorOptions) Complete(args []string) error {
+	var providers []image.CredentialProvider
+	for _, spec := range o.CredentialProviders {
+		provider, err := image.ParseCredentialProvider(spec)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, provider)
+	}
+	image.SetCredentialProviders(providers...)
+
+	if len(args) == 0 {
+		return nil
+	}
+	destination := args[0]
+	if !strings.HasPrefix(destination, "docker://") {
+		return fmt.Errorf("destination %q must use the docker:// scheme", destination)
+	}
+	mirror, err := imagesource.ParseReference(strings.TrimPrefix(destination, "docker://"))
+	if err != nil {
+		return err
+	}
+	o.ToMirror = mirror.Ref.Registry
+	o.UserNamespace = mirror.Ref.RepositoryName()
+	return nil
+}
+
+func (o *DoctorOptions) Validate() error {
+	if len(o.ConfigPath) == 0 && len(o.ToMirror) == 0 {
+		return errors.New("must specify --config or a destination registry")
+	}
+	return nil
+}
+
+func (o *DoctorOptions) Run(ctx context.Context) error {
+	var cfg *v1alpha2.ImageSetConfiguration
+	if len(o.ConfigPath) != 0 {
+		c, err := config.ReadConfigWithOverlays(o.ConfigPath, o.Overlays, o.Profile)
+		if err != nil {
+			return err
+		}
+		if err := network.Configure(c.Proxy); err != nil {
+			return err
+		}
+		cfg = &c
+	}
+
+	report := &doctorReport{}
+	checkOpmBinary(report)
+	checkCredentialHelpers(report)
+	for _, host := range o.registries(cfg) {
+		checkRegistryAuth(report, host)
+	}
+	if cfg != nil {
+		o.checkMetadata(ctx, report, *cfg)
+	}
+	if err := o.checkDestination(ctx, report); err != nil {
+		return err
+	}
+	checkWorkspaceSpace(report, o.Dir)
+	checkOpenFiles(report)
+
+	if err := report.write(o.IOStreams.Out); err != nil {
+		return err
+	}
+	if failed := report.failed(); failed != 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(report.Results))
+	}
+	return nil
+}
+
+// doctorResult is the outcome of a diagnostic check,
+// with a hint to fix it when it does not pass.
+type doctorResult struct {
+	Check  string
+	Status preflightStatus
+	Detail string
+	Hint   string
+}
+
+// doctorReport is the outcome of the diagnostic checks.
+type doctorReport struct {
+	Results []doctorResult
+}
+
+func (r *doctorReport) add(check string, status preflightStatus, hint string, format string, args ...interface{}) {
+	r.Results = append(r.Results, doctorResult{Check: check, Status: status, Detail: fmt.Sprintf(format, args...), Hint: hint})
+}
+
+// failed returns the number of checks that failed.
+func (r *doctorReport) failed() int {
+	var failed int
+	for _, res := range r.Results {
+		if res.Status == preflightFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// write renders the report as a table, the hints of the checks
+// that did not pass on the line following their results.
+func (r *doctorReport) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "[%s]\t%s\t%s\n", res.Status, res.Check, res.Detail)
+		if res.Hint != "" && (res.Status == preflightWarn || res.Status == preflightFail) {
+			fmt.Fprintf(tw, "\t\thint: %s\n", res.Hint)
+		}
+	}
+	return tw.Flush()
+}
+
+// checkOpmBinary checks the opm binary regenerating the caches of rebuilt
+// catalogs when the opm binaries of the catalogs cannot be used.
+func checkOpmBinary(report *doctorReport) {
+	const check = "opm binary"
+	hint := fmt.Sprintf("install opm for %s/%s in PATH or set OPM_BINARY", runtime.GOOS, runtime.GOARCH)
+	if opmBinary := os.Getenv("OPM_BINARY"); opmBinary != "" {
+		if err := runsOn(opmBinary, runtime.GOOS, runtime.GOARCH); err != nil {
+			report.add(check, preflightFail, hint, "OPM_BINARY %s: %v", opmBinary, err)
+			return
+		}
+		report.add(check, preflightPass, "", "OPM_BINARY %s", opmBinary)
+		return
+	}
+	if opmPath, err := exec.LookPath("opm"); err == nil {
+		if err := runsOn(opmPath, runtime.GOOS, runtime.GOARCH); err != nil {
+			report.add(check, preflightWarn, hint, "%s: %v", opmPath, err)
+			return
+		}
+		report.add(check, preflightPass, "", "%s", opmPath)
+		return
+	}
+	if runtime.GOOS != "linux" {
+		report.add(check, preflightWarn, hint, "no opm in PATH, the opm binaries of most catalogs only run on linux")
+		return
+	}
+	report.add(check, preflightPass, "", "the opm binaries of the catalogs are used")
+}
+
+// checkCredentialHelpers checks that the docker credential helpers
+// configured in the docker configuration file are installed.
+func checkCredentialHelpers(report *doctorReport) {
+	cf, err := dockercfg.Load(dockercfg.Dir())
+	if err != nil {
+		report.add("credential helpers", preflightFail, "fix the docker configuration file in "+dockercfg.Dir(), "%v", err)
+		return
+	}
+	helpers := map[string]bool{}
+	if cf.CredentialsStore != "" {
+		helpers[cf.CredentialsStore] = true
+	}
+	for _, helper := range cf.CredentialHelpers {
+		helpers[helper] = true
+	}
+	if len(helpers) == 0 {
+		report.add("credential helpers", preflightSkip, "", "none configured")
+		return
+	}
+	names := make([]string, 0, len(helpers))
+	for helper := range helpers {
+		names = append(names, helper)
+	}
+	sort.Strings(names)
+	for _, helper := range names {
+		bin := "docker-credential-" + helper
+		check := "credential helper " + helper
+		if path, err := exec.LookPath(bin); err != nil {
+			report.add(check, preflightFail, fmt.Sprintf("install %s in PATH or remove it from %s", bin, cf.Filename), "%s not found", bin)
+		} else {
+			report.add(check, preflightPass, "", "%s", path)
+		}
+	}
+}
+
+// registries returns the registries the images of cfg are pulled from
+// and the destination registry.
+func (o *DoctorOptions) registries(cfg *v1alpha2.ImageSetConfiguration) []string {
+	hosts := map[string]bool{}
+	if o.ToMirror != "" {
+		hosts[o.ToMirror] = true
+	}
+	if cfg != nil {
+		if len(cfg.Mirror.Platform.Channels) != 0 {
+			hosts[releaseRegistry] = true
+		}
+		var refs []string
+		for _, op := range cfg.Mirror.Operators {
+			if !op.IsFBCOCI() {
+				refs = append(refs, op.CatalogImage())
+			}
+		}
+		for _, img := range cfg.Mirror.AdditionalImages {
+			refs = append(refs, img.Name)
+		}
+		for _, artifact := range cfg.Mirror.Artifacts {
+			refs = append(refs, artifact.Name)
+		}
+		for _, ref := range refs {
+			parsed, err := reference.Parse(ref)
+			if err != nil {
+				continue
+			}
+			hosts[parsed.DockerClientDefaults().Registry] = true
+		}
+	}
+	registries := make([]string, 0, len(hosts))
+	for host := range hosts {
+		registries = append(registries, host)
+	}
+	sort.Strings(registries)
+	return registries
+}
+
+// checkRegistryAuth checks that credentials are configured for host.
+func checkRegistryAuth(report *doctorReport, host string) {
+	check := "auth " + host
+	hint := fmt.Sprintf("log in with `podman login %s` or configure a --credential-provider", host)
+	reg, err := name.NewRegistry(host)
+	if err != nil {
+		report.add(check, preflightFail, "", "invalid registry: %v", err)
+		return
+	}
+	auth, err := image.Keychain().Resolve(reg)
+	switch {
+	case err != nil:
+		report.add(check, preflightFail, hint, "unable to resolve credentials: %v", err)
+	case auth == authn.Anonymous:
+		report.add(check, preflightWarn, hint, "no credentials, anonymous access is used")
+	default:
+		report.add(check, preflightPass, "", "credentials found")
+	}
+}
+
+// checkMetadata checks that the metadata of the storage configuration of cfg is
+// readable, is consistent with the copy in the workspace, and that no metadata
+// is still queued in the workspace by a publish that could not write it.
+func (o *DoctorOptions) checkMetadata(ctx context.Context, report *doctorReport, cfg v1alpha2.ImageSetConfiguration) {
+	const check = "metadata"
+	srcDir := filepath.Join(o.Dir, config.SourceDir)
+
+	// The registry backend overwrites the copy in the workspace
+	// when reading, so it reads the metadata to a temporary directory.
+	tmpDir, err := os.MkdirTemp("", "oc-mirror-doctor")
+	if err != nil {
+		report.add(check, preflightFail, "", "%v", err)
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+	backend, err := storage.ByConfig(tmpDir, cfg.StorageConfig)
+	if err != nil {
+		report.add(check, preflightSkip, "", "no storage configuration, the metadata is read from the imageset archives")
+		return
+	}
+
+	var curr v1alpha2.Metadata
+	switch err := backend.ReadMetadata(ctx, &curr, config.MetadataBasePath); {
+	case errors.Is(err, storage.ErrMetadataNotExist):
+		report.add(check, preflightPass, "", "no metadata, the next run starts sequence 1")
+	case err != nil:
+		report.add(check, preflightFail, "check the storage configuration and the credentials of its registry", "unable to read metadata: %v", err)
+		return
+	default:
+		report.add(check, preflightPass, "", "uid %s, sequence %d", curr.Uid, curr.PastMirror.Sequence)
+		var copied v1alpha2.Metadata
+		if workspace, err := storage.NewLocalBackend(srcDir); err == nil {
+			if err := workspace.ReadMetadata(ctx, &copied, config.MetadataBasePath); err == nil &&
+				(copied.Uid != curr.Uid || copied.PastMirror.Sequence != curr.PastMirror.Sequence) {
+				report.add("workspace metadata", preflightWarn,
+					fmt.Sprintf("run from the workspace of this imageset configuration or remove %s", srcDir),
+					"uid %s, sequence %d in the workspace, uid %s, sequence %d in the storage",
+					copied.Uid, copied.PastMirror.Sequence, curr.Uid, curr.PastMirror.Sequence)
+			}
+		}
+	}
+
+	markers, err := filepath.Glob(filepath.Join(o.Dir, config.PendingMetadataDir, "*", config.PendingMarkerFile))
+	if err != nil {
+		return
+	}
+	for _, marker := range markers {
+		metaImage, err := os.ReadFile(filepath.Clean(marker))
+		if err != nil {
+			continue
+		}
+		report.add("pending metadata", preflightWarn, "publish the next imageset to the same registry to push it",
+			"metadata queued for %s by a publish that could not write it", metaImage)
+	}
+}
+
+// checkDestination runs the readiness checks of the destination registry, if any.
+func (o *DoctorOptions) checkDestination(ctx context.Context, report *doctorReport) error {
+	if o.ToMirror == "" {
+		report.add("destination", preflightSkip, "", "no destination registry")
+		return nil
+	}
+	mo := &MirrorOptions{
+		RootOptions:   o.RootOptions,
+		ToMirror:      o.ToMirror,
+		UserNamespace: o.UserNamespace,
+		DestSkipTLS:   o.DestSkipTLS,
+		DestPlainHTTP: o.DestPlainHTTP,
+	}
+	preflight, err := mo.preflight(ctx)
+	if err != nil {
+		return err
+	}
+	hints := map[string]string{
+		"connectivity":    "check the registry host name, the proxy settings and the firewall rules",
+		"tls":             "add the CA of the registry to the system trust store or use --dest-skip-tls",
+		"clock skew":      "synchronize the clock of this host with NTP",
+		"push permission": fmt.Sprintf("log in with `podman login %s` with an account allowed to push", o.ToMirror),
+		"media types":     "use a registry accepting OCI image indexes, or --rebuild-catalogs=false when mirroring",
+	}
+	for _, res := range preflight.Results {
+		report.Results = append(report.Results, doctorResult{Check: "destination " + res.Check, Status: res.Status, Detail: res.Detail, Hint: hints[res.Check]})
+	}
+	return nil
+}
+
+// checkWorkspaceSpace checks the free space of the filesystem of the workspace at dir.
+func checkWorkspaceSpace(report *doctorReport, dir string) {
+	const check = "disk space"
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		report.add(check, preflightFail, "", "%v", err)
+		return
+	}
+	// The workspace is created by the first run.
+	for {
+		if _, err := os.Stat(dir); err == nil || filepath.Dir(dir) == dir {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	free, _, err := freeSpace(dir)
+	switch {
+	case err != nil:
+		report.add(check, preflightFail, "", "unable to read the free space of %s: %v", dir, err)
+	case free < doctorMinFreeSpace:
+		report.add(check, preflightWarn, "free space or run oc-mirror from a larger filesystem",
+			"%s free in %s, less than %s", formatGiB(free), dir, formatGiB(doctorMinFreeSpace))
+	default:
+		report.add(check, preflightPass, "", "%s free in %s", formatGiB(free), dir)
+	}
+}
+
+// checkOpenFiles checks the limit of open files of the process.
+func checkOpenFiles(report *doctorReport) {
+	const check = "open files"
+	limit, err := openFilesLimit()
+	if err != nil {
+		report.add(check, preflightSkip, "", "unable to read the limit: %v", err)
+		return
+	}
+	if limit < doctorMinOpenFiles {
+		report.add(check, preflightWarn, fmt.Sprintf("raise the limit with `ulimit -n %d` before running oc-mirror", doctorMinOpenFiles),
+			"limit of %d, less than %d", limit, doctorMinOpenFiles)
+		return
+	}
+	report.add(check, preflightPass, "", "limit of %d", limit)
+}
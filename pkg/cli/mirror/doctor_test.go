@@ -0,0 +1,194 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dockercfg "github.com/docker/cli/cli/config"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+func TestDoctorComplete(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		expMirror string
+		expNs     string
+		expError  string
+	}{
+		{name: "Valid/NoDestination"},
+		{name: "Valid/Destination", args: []string{"docker://localhost:5000/namespace"}, expMirror: "localhost:5000", expNs: "namespace"},
+		{name: "Invalid/Scheme", args: []string{"localhost:5000/namespace"}, expError: `destination "localhost:5000/namespace" must use the docker:// scheme`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &DoctorOptions{RootOptions: &cli.RootOptions{}}
+			err := o.Complete(tt.args)
+			if tt.expError != "" {
+				require.EqualError(t, err, tt.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expMirror, o.ToMirror)
+			require.Equal(t, tt.expNs, o.UserNamespace)
+		})
+	}
+
+	o := &DoctorOptions{RootOptions: &cli.RootOptions{}}
+	require.EqualError(t, o.Validate(), "must specify --config or a destination registry")
+}
+
+func TestDoctorRegistries(t *testing.T) {
+	cfg := &v1alpha2.ImageSetConfiguration{}
+	cfg.Mirror.Platform.Channels = []v1alpha2.ReleaseChannel{{Name: "stable-4.14"}}
+	cfg.Mirror.Operators = []v1alpha2.Operator{
+		{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14"},
+		{Catalog: "oci:///tmp/catalog"},
+	}
+	cfg.Mirror.AdditionalImages = []v1alpha2.Image{
+		{Name: "busybox:latest"},
+		{Name: "registry.redhat.io/ubi8/ubi:latest"},
+	}
+
+	o := &DoctorOptions{ToMirror: "localhost:5000"}
+	require.Equal(t, []string{"docker.io", "localhost:5000", "quay.io", "registry.redhat.io"}, o.registries(cfg))
+	require.Equal(t, []string{"localhost:5000"}, o.registries(nil))
+}
+
+func TestDoctorReport(t *testing.T) {
+	report := &doctorReport{}
+	report.add("disk space", preflightPass, "free space", "%s free", "100 GiB")
+	report.add("open files", preflightWarn, "raise the limit", "limit of %d", 1024)
+	report.add("metadata", preflightFail, "check the storage", "unable to read metadata")
+	require.Equal(t, 1, report.failed())
+
+	var out bytes.Buffer
+	require.NoError(t, report.write(&out))
+	require.Equal(t, `[PASS]  disk space  100 GiB free
+[WARN]  open files  limit of 1024
+                    hint: raise the limit
+[FAIL]  metadata    unable to read metadata
+                    hint: check the storage
+`, out.String())
+}
+
+func TestDoctorCheckMetadata(t *testing.T) {
+	ctx := context.Background()
+	metaDir := t.TempDir()
+	cfg := v1alpha2.ImageSetConfiguration{}
+	cfg.StorageConfig.Local = &v1alpha2.LocalConfig{Path: metaDir}
+
+	newMeta := func(seq int) *v1alpha2.Metadata {
+		meta := v1alpha2.NewMetadata()
+		meta.Uid = uuid.MustParse("360a43c2-8a14-4b5d-906b-07491459f25f")
+		meta.PastMirror.Sequence = seq
+		return &meta
+	}
+	checks := func(report *doctorReport) map[string]preflightStatus {
+		statuses := map[string]preflightStatus{}
+		for _, res := range report.Results {
+			statuses[res.Check] = res.Status
+		}
+		return statuses
+	}
+
+	o := &DoctorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+	report := &doctorReport{}
+	o.checkMetadata(ctx, report, cfg)
+	require.Equal(t, map[string]preflightStatus{"metadata": preflightPass}, checks(report))
+	require.Contains(t, report.Results[0].Detail, "the next run starts sequence 1")
+
+	backend, err := storage.NewLocalBackend(metaDir)
+	require.NoError(t, err)
+	require.NoError(t, backend.WriteMetadata(ctx, newMeta(2), config.MetadataBasePath))
+
+	// The workspace holds the metadata of a previous sequence.
+	workspace, err := storage.NewLocalBackend(filepath.Join(o.Dir, config.SourceDir))
+	require.NoError(t, err)
+	require.NoError(t, workspace.WriteMetadata(ctx, newMeta(1), config.MetadataBasePath))
+	// A publish queued its metadata.
+	mo := &MirrorOptions{RootOptions: o.RootOptions}
+	require.NoError(t, mo.queueMetadata(ctx, "localhost:5000/ns/oc-mirror:test", newMeta(3), nil))
+
+	report = &doctorReport{}
+	o.checkMetadata(ctx, report, cfg)
+	require.Equal(t, map[string]preflightStatus{
+		"metadata":           preflightPass,
+		"workspace metadata": preflightWarn,
+		"pending metadata":   preflightWarn,
+	}, checks(report))
+	require.Contains(t, report.Results[2].Detail, "localhost:5000/ns/oc-mirror:test")
+
+	report = &doctorReport{}
+	o.checkMetadata(ctx, report, v1alpha2.ImageSetConfiguration{})
+	require.Equal(t, map[string]preflightStatus{"metadata": preflightSkip}, checks(report))
+}
+
+func TestDoctorCheckDestination(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	o := &DoctorOptions{RootOptions: &cli.RootOptions{}, ToMirror: u.Host, UserNamespace: "ns", DestPlainHTTP: true}
+	report := &doctorReport{}
+	require.NoError(t, o.checkDestination(context.Background(), report))
+	require.Zero(t, report.failed())
+	require.Equal(t, "destination connectivity", report.Results[0].Check)
+	require.Equal(t, preflightPass, report.Results[0].Status)
+
+	report = &doctorReport{}
+	o = &DoctorOptions{RootOptions: &cli.RootOptions{}}
+	require.NoError(t, o.checkDestination(context.Background(), report))
+	require.Equal(t, preflightSkip, report.Results[0].Status)
+}
+
+func TestDoctorCheckWorkspaceSpace(t *testing.T) {
+	defer func(f func(string) (int64, uint64, error)) { freeSpace = f }(freeSpace)
+	var statted string
+	freeSpace = func(dir string) (int64, uint64, error) {
+		statted = dir
+		return 10 * segMultiplier, 1, nil
+	}
+
+	// The workspace is not created yet.
+	dir := t.TempDir()
+	report := &doctorReport{}
+	checkWorkspaceSpace(report, filepath.Join(dir, "oc-mirror-workspace"))
+	require.Equal(t, dir, statted)
+	require.Equal(t, preflightWarn, report.Results[0].Status)
+
+	freeSpace = func(string) (int64, uint64, error) { return 100 * segMultiplier, 1, nil }
+	report = &doctorReport{}
+	checkWorkspaceSpace(report, dir)
+	require.Equal(t, preflightPass, report.Results[0].Status)
+}
+
+func TestDoctorCheckCredentialHelpers(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	// DOCKER_CONFIG is only read once, on the first lookup of the directory.
+	prev := dockercfg.Dir()
+	dockercfg.SetDir(dir)
+	t.Cleanup(func() { dockercfg.SetDir(prev) })
+	t.Setenv("PATH", t.TempDir())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"credHelpers":{"quay.io":"missing"}}`), 0600))
+
+	report := &doctorReport{}
+	checkCredentialHelpers(report)
+	require.Len(t, report.Results, 1)
+	require.Equal(t, "credential helper missing", report.Results[0].Check)
+	require.Equal(t, preflightFail, report.Results[0].Status)
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package mirror
+
+import "golang.org/x/sys/unix"
+
+// openFilesLimit returns the soft limit of open files of the process.
+func openFilesLimit() (uint64, error) {
+	var limit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &limit); err != nil {
+		return 0, err
+	}
+	return uint64(limit.Cur), nil
+}
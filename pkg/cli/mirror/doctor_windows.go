@@ -0,0 +1,10 @@
+//go:build windows
+
+package mirror
+
+import "errors"
+
+// openFilesLimit fails, Windows has no limit of open files per process.
+func openFilesLimit() (uint64, error) {
+	return 0, errors.New("no limit of open files on Windows")
+}
@@ -0,0 +1,195 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containers/image/v5/docker"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+// ErrorClass is the class of the failure of a command,
+// surfaced as the exit code of the command.
+type ErrorClass int
+
+const (
+	// ErrorClassUnknown is any failure not in another class.
+	ErrorClassUnknown ErrorClass = iota
+	// ErrorClassAuth is a failure to authenticate to,
+	// or to be authorized by, a registry.
+	ErrorClassAuth
+	// ErrorClassNotFound is an image, a metadata image or
+	// an archive file that does not exist.
+	ErrorClassNotFound
+	// ErrorClassSequence is an imageset published out of order.
+	ErrorClassSequence
+	// ErrorClassNetwork is a failure to reach a registry or a
+	// server, including timeouts and TLS handshake failures.
+	ErrorClassNetwork
+	// ErrorClassDiskFull is a write to a full filesystem.
+	ErrorClassDiskFull
+	// ErrorClassQuota is a push rejected by a registry because
+	// the storage quota of a namespace is exceeded.
+	ErrorClassQuota
+	// ErrorClassLocked is a run with --lock started while another
+	// run holds the lock of its workspace or of a destination.
+	ErrorClassLocked
+	// ErrorClassInterrupted is a run stopped by SIGINT or SIGTERM.
+	ErrorClassInterrupted
+)
+
+var errorClassExitCodes = map[ErrorClass]int{
+	ErrorClassUnknown:     kcmdutil.DefaultErrorExitCode,
+	ErrorClassAuth:        3,
+	ErrorClassNotFound:    4,
+	ErrorClassSequence:    5,
+	ErrorClassNetwork:     6,
+	ErrorClassDiskFull:    7,
+	ErrorClassQuota:       8,
+	ErrorClassLocked:      9,
+	ErrorClassInterrupted: 10,
+}
+
+var errorClassNames = map[ErrorClass]string{
+	ErrorClassUnknown:     "unknown",
+	ErrorClassAuth:        "auth",
+	ErrorClassNotFound:    "not-found",
+	ErrorClassSequence:    "sequence",
+	ErrorClassNetwork:     "network",
+	ErrorClassDiskFull:    "disk-full",
+	ErrorClassQuota:       "quota",
+	ErrorClassLocked:      "locked",
+	ErrorClassInterrupted: "interrupted",
+}
+
+// ExitCode returns the exit code of the commands failing with an error of class c.
+func (c ErrorClass) ExitCode() int {
+	if code, found := errorClassExitCodes[c]; found {
+		return code
+	}
+	return kcmdutil.DefaultErrorExitCode
+}
+
+func (c ErrorClass) String() string {
+	if name, found := errorClassNames[c]; found {
+		return name
+	}
+	return fmt.Sprintf("ErrorClass(%d)", int(c))
+}
+
+// ClassifiedError is an error whose class is known where it occurs.
+type ClassifiedError struct {
+	Class ErrorClass
+	Err   error
+}
+
+// NewClassifiedError returns err with the class class.
+func NewClassifiedError(class ErrorClass, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{Class: class, Err: err}
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// Messages of the errors of the registries and of the operating
+// system, for the errors wrapped without %w by oc and oc-mirror.
+var errorClassMessages = []struct {
+	class    ErrorClass
+	messages []string
+}{
+	{ErrorClassDiskFull, []string{"no space left on device", "disk quota exceeded"}},
+	{ErrorClassAuth, []string{"unauthorized", "authentication required", "denied: ", "access denied", "403 forbidden", "invalid username/password"}},
+	{ErrorClassNotFound, []string{"manifest unknown", "name unknown", "repository name not known", "not found in archive"}},
+	{ErrorClassNetwork, []string{"connection refused", "connection reset by peer", "no such host", "i/o timeout", "tls handshake timeout", "network is unreachable", "x509: "}},
+}
+
+// ClassifyError returns the class of err, looking at the error types
+// of the error chain first and at the error message second.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassUnknown
+	}
+
+	var classified *ClassifiedError
+	var invalidSeq *ErrInvalidSequence
+	var mirrorSeq *ErrMirrorSequence
+	var notInArchive *ErrArchiveFileNotFound
+	var unauthorized docker.ErrUnauthorizedForCredentials
+	var transportErr *transport.Error
+	var netErr net.Error
+	switch {
+	case errors.As(err, &classified):
+		return classified.Class
+	case errors.Is(err, context.Canceled):
+		return ErrorClassInterrupted
+	case errors.As(err, &invalidSeq), errors.As(err, &mirrorSeq):
+		return ErrorClassSequence
+	case errors.Is(err, syscall.ENOSPC), errors.Is(err, syscall.EDQUOT):
+		return ErrorClassDiskFull
+	case isQuotaExceededError(err):
+		// Quay rejects the pushes over quota with a 403 "denied" error.
+		return ErrorClassQuota
+	case errors.As(err, &unauthorized):
+		return ErrorClassAuth
+	case errors.As(err, &transportErr) && (transportErr.StatusCode == http.StatusUnauthorized || transportErr.StatusCode == http.StatusForbidden):
+		return ErrorClassAuth
+	case errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound,
+		errors.As(err, &notInArchive),
+		errors.Is(err, storage.ErrMetadataNotExist),
+		errors.Is(err, errdefs.ErrNotFound),
+		errors.Is(err, os.ErrNotExist):
+		return ErrorClassNotFound
+	case errors.As(err, &netErr),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET):
+		return ErrorClassNetwork
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, c := range errorClassMessages {
+		for _, m := range c.messages {
+			if strings.Contains(msg, m) {
+				return c.class
+			}
+		}
+	}
+	return ErrorClassUnknown
+}
+
+// checkErr prints err and exits with the exit code of its class,
+// as kcmdutil.CheckErr does with the default exit code.
+func checkErr(err error) {
+	if err == nil {
+		return
+	}
+	code := ClassifyError(err).ExitCode()
+	kcmdutil.BehaviorOnFatal(func(msg string, _ int) {
+		if len(msg) > 0 {
+			if !strings.HasSuffix(msg, "\n") {
+				msg += "\n"
+			}
+			fmt.Fprint(os.Stderr, msg)
+		}
+		os.Exit(code)
+	})
+	defer kcmdutil.DefaultBehaviorOnFatal()
+	kcmdutil.CheckErr(err)
+}
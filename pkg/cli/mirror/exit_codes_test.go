@@ -0,0 +1,167 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/containerd/containerd/errdefs"
+	"github.com/containers/image/v5/docker"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+func TestClassifyError(t *testing.T) {
+	type spec struct {
+		name     string
+		err      error
+		expClass ErrorClass
+		expCode  int
+	}
+
+	cases := []spec{
+		{
+			name:     "Valid/Classified",
+			err:      fmt.Errorf("publishing: %w", NewClassifiedError(ErrorClassNetwork, errors.New("registry offline"))),
+			expClass: ErrorClassNetwork,
+			expCode:  6,
+		},
+		{
+			name:     "Valid/InvalidSequence",
+			err:      fmt.Errorf("error: %w", &ErrInvalidSequence{wantSeq: 2, gotSeq: 3}),
+			expClass: ErrorClassSequence,
+			expCode:  5,
+		},
+		{
+			name:     "Valid/MirrorSequence",
+			err:      &ErrMirrorSequence{msg: "no metadata detected"},
+			expClass: ErrorClassSequence,
+			expCode:  5,
+		},
+		{
+			name:     "Valid/DiskFull",
+			err:      &os.PathError{Op: "write", Path: "/tmp/blob", Err: syscall.ENOSPC},
+			expClass: ErrorClassDiskFull,
+			expCode:  7,
+		},
+		{
+			name:     "Valid/DiskFullMessage",
+			err:      errors.New("error writing layer: write /tmp/blob: no space left on device"),
+			expClass: ErrorClassDiskFull,
+			expCode:  7,
+		},
+		{
+			name:     "Valid/QuotaExceeded",
+			err:      &transport.Error{StatusCode: http.StatusForbidden, Errors: []transport.Diagnostic{{Code: transport.DeniedErrorCode, Message: "Quota has been exceeded on namespace org"}}},
+			expClass: ErrorClassQuota,
+			expCode:  8,
+		},
+		{
+			name:     "Valid/QuotaExceededMessage",
+			err:      errors.New("error running generic image mirror: denied: Quota has been exceeded on namespace org"),
+			expClass: ErrorClassQuota,
+			expCode:  8,
+		},
+		{
+			name:     "Valid/Unauthorized",
+			err:      docker.ErrUnauthorizedForCredentials{Err: errors.New("bad password")},
+			expClass: ErrorClassAuth,
+			expCode:  3,
+		},
+		{
+			name:     "Valid/TransportForbidden",
+			err:      &transport.Error{StatusCode: http.StatusForbidden},
+			expClass: ErrorClassAuth,
+			expCode:  3,
+		},
+		{
+			name:     "Valid/AuthMessage",
+			err:      errors.New("error: unable to retrieve source image quay.io/ns/img manifest: unauthorized: authentication required"),
+			expClass: ErrorClassAuth,
+			expCode:  3,
+		},
+		{
+			name:     "Valid/TransportNotFound",
+			err:      &transport.Error{StatusCode: http.StatusNotFound},
+			expClass: ErrorClassNotFound,
+			expCode:  4,
+		},
+		{
+			name:     "Valid/ArchiveFileNotFound",
+			err:      &ErrArchiveFileNotFound{filename: "publish/.metadata.json"},
+			expClass: ErrorClassNotFound,
+			expCode:  4,
+		},
+		{
+			name:     "Valid/MetadataNotExist",
+			err:      fmt.Errorf("reading metadata: %w", storage.ErrMetadataNotExist),
+			expClass: ErrorClassNotFound,
+			expCode:  4,
+		},
+		{
+			name:     "Valid/ContainerdNotFound",
+			err:      fmt.Errorf("resolving catalog: %w", errdefs.ErrNotFound),
+			expClass: ErrorClassNotFound,
+			expCode:  4,
+		},
+		{
+			name:     "Valid/ManifestUnknownMessage",
+			err:      errors.New("quay.io/ns/img:v1: manifest unknown: manifest unknown"),
+			expClass: ErrorClassNotFound,
+			expCode:  4,
+		},
+		{
+			name:     "Valid/DNSError",
+			err:      fmt.Errorf("pinging registry: %w", &net.DNSError{Err: "no such host", Name: "registry.example.com"}),
+			expClass: ErrorClassNetwork,
+			expCode:  6,
+		},
+		{
+			name:     "Valid/ConnectionRefused",
+			err:      &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED},
+			expClass: ErrorClassNetwork,
+			expCode:  6,
+		},
+		{
+			name:     "Valid/NetworkMessage",
+			err:      errors.New("Get \"https://registry.example.com/v2/\": dial tcp 10.0.0.5:443: i/o timeout"),
+			expClass: ErrorClassNetwork,
+			expCode:  6,
+		},
+		{
+			name:     "Valid/Canceled",
+			err:      fmt.Errorf("planning operators: %w", context.Canceled),
+			expClass: ErrorClassInterrupted,
+			expCode:  10,
+		},
+		{
+			name:     "Valid/Unknown",
+			err:      errors.New("invalid imageset configuration"),
+			expClass: ErrorClassUnknown,
+			expCode:  1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			class := ClassifyError(c.err)
+			require.Equal(t, c.expClass, class)
+			require.Equal(t, c.expCode, class.ExitCode())
+		})
+	}
+}
+
+func TestNewClassifiedError(t *testing.T) {
+	require.NoError(t, NewClassifiedError(ErrorClassAuth, nil))
+	err := NewClassifiedError(ErrorClassAuth, storage.ErrMetadataNotExist)
+	require.EqualError(t, err, storage.ErrMetadataNotExist.Error())
+	require.ErrorIs(t, err, storage.ErrMetadataNotExist)
+	require.Equal(t, "auth", ClassifyError(err).String())
+}
@@ -0,0 +1,58 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// loadFBCDir renders the file-based catalog directory dir
+// of a dir:// catalog into a declarative config.
+func loadFBCDir(ctx context.Context, dir string) (*declcfg.DeclarativeConfig, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading catalog directory: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("catalog directory %s is not a directory", dir)
+	}
+	dc, err := declcfg.LoadFS(ctx, os.DirFS(dir))
+	if err != nil {
+		return nil, fmt.Errorf("error loading catalog directory %s: %v", dir, err)
+	}
+	return dc, nil
+}
+
+// hashFBCDir returns the digest of the paths and contents
+// of the files of the file-based catalog directory dir.
+func hashFBCDir(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, fpath)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(fpath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fmt.Fprintf(h, "%s\n", filepath.ToSlash(rel))
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return digest.NewDigest(digest.SHA256, h).String(), nil
+}
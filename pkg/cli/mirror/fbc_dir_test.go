@@ -0,0 +1,40 @@
+package mirror
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFBCDir(t *testing.T) {
+	dc, err := loadFBCDir(context.Background(), "testdata/manifestlist/testonly/configs")
+	require.NoError(t, err)
+	require.Len(t, dc.Packages, 2)
+	require.NotEmpty(t, dc.Bundles)
+
+	_, err = loadFBCDir(context.Background(), "testdata/missing")
+	require.ErrorContains(t, err, "error reading catalog directory")
+
+	_, err = loadFBCDir(context.Background(), "testdata/configs/one.json")
+	require.EqualError(t, err, "catalog directory testdata/configs/one.json is not a directory")
+}
+
+func TestHashFBCDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foo"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo", "catalog.json"), []byte(`{"schema":"olm.package","name":"foo"}`), 0600))
+
+	first, err := hashFBCDir(dir)
+	require.NoError(t, err)
+	again, err := hashFBCDir(dir)
+	require.NoError(t, err)
+	require.Equal(t, first, again)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo", "catalog.json"), []byte(`{"schema":"olm.package","name":"foo","defaultChannel":"stable"}`), 0600))
+	changed, err := hashFBCDir(dir)
+	require.NoError(t, err)
+	require.NotEqual(t, first, changed)
+}
@@ -15,7 +15,6 @@ import (
 
 	imagecopy "github.com/containers/image/v5/copy"
 	"github.com/containers/image/v5/manifest"
-	"github.com/containers/image/v5/pkg/cli/environment"
 	"github.com/containers/image/v5/pkg/sysregistriesv2"
 	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/transports/alltransports"
@@ -23,6 +22,7 @@ import (
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/opencontainers/go-digest"
+	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/pkg/containertools"
 	"k8s.io/klog/v2"
@@ -57,7 +57,7 @@ type RemoteRegFuncs struct {
 	mirrorMappings     func(cfg v1alpha2.ImageSetConfiguration, images image.TypedImageMapping, insecure bool) error
 	newImageSource     func(ctx context.Context, sys *types.SystemContext, imgRef types.ImageReference) (types.ImageSource, error)
 	getManifest        func(ctx context.Context, instanceDigest *digest.Digest, imgSrc types.ImageSource) ([]byte, string, error)
-	handleMetadata     func(ctx context.Context, tmpdir string, filesInArchive map[string]string) (backend storage.Backend, incoming, curr v1alpha2.Metadata, err error)
+	handleMetadata     func(ctx context.Context, tmpdir string, filesInArchive map[string]string) (backend storage.Backend, incoming, curr streamedMetadata, err error)
 	m2mWorkflowWrapper func(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, cleanup cleanupFunc) error
 }
 
@@ -516,13 +516,19 @@ func (o *MirrorOptions) copyImage(ctx context.Context, from, to string, funcs Re
 		return digest.Digest(""), err
 	}
 
+	// Images pushed to registries accepting OCI images only are converted
+	var forceManifestMIMEType string
+	if o.OCIMediaTypes && strings.HasPrefix(to, dockerPrefix) {
+		forceManifestMIMEType = imgspecv1.MediaTypeImageManifest
+	}
+
 	// call the copy.Image function with the set options
 	manifestBytes, err := funcs.copy(ctx, policyContext, destRef, srcRef, &imagecopy.Options{
 		RemoveSignatures:      true,
 		ReportWriter:          os.Stdout,
 		SourceCtx:             sourceCtx,
 		DestinationCtx:        destinationCtx,
-		ForceManifestMIMEType: "",
+		ForceManifestMIMEType: forceManifestMIMEType,
 		ImageListSelection:    imagecopy.CopyAllImages,
 		OciDecryptConfig:      nil,
 		OciEncryptLayers:      nil,
@@ -534,29 +540,8 @@ func (o *MirrorOptions) copyImage(ctx context.Context, from, to string, funcs Re
 	return manifest.Digest(manifestBytes)
 }
 
-// newSystemContext set the context for source & destination resources
+// newSystemContext set the context for source & destination resources,
+// with the credentials of the merged auth file of the run.
 func newSystemContext(skipTLS bool, registriesConfigPath string) *types.SystemContext {
-	skipTLSVerify := types.OptionalBoolFalse
-	if skipTLS {
-		skipTLSVerify = types.OptionalBoolTrue
-	}
-	ctx := &types.SystemContext{
-		RegistriesDirPath:           "",
-		ArchitectureChoice:          "",
-		OSChoice:                    "",
-		VariantChoice:               "",
-		BigFilesTemporaryDir:        "", //*globalArgs.cache + "/tmp",
-		DockerInsecureSkipTLSVerify: skipTLSVerify,
-	}
-	if registriesConfigPath != "" {
-		ctx.SystemRegistriesConfPath = registriesConfigPath
-	} else {
-		err := environment.UpdateRegistriesConf(ctx)
-		if err != nil {
-			// log and ignore
-			klog.Warningf("unable to load registries.conf from environment variables: %v", err)
-
-		}
-	}
-	return ctx
+	return image.NewSystemContext(skipTLS, registriesConfigPath)
 }
@@ -1184,10 +1184,10 @@ func createMockFunctions(errorType int) RemoteRegFuncs {
 		return nil
 	}
 
-	theMock.handleMetadata = func(ctx context.Context, tmpdir string, filesInArchive map[string]string) (backend storage.Backend, incoming, curr v1alpha2.Metadata, err error) {
+	theMock.handleMetadata = func(ctx context.Context, tmpdir string, filesInArchive map[string]string) (backend storage.Backend, incoming, curr streamedMetadata, err error) {
 		md := v1alpha2.NewMetadata()
 		md.SingleUse = true
-		return nil, md, v1alpha2.NewMetadata(), nil
+		return nil, streamedMetadata{Metadata: md}, streamedMetadata{Metadata: v1alpha2.NewMetadata()}, nil
 	}
 
 	theMock.getManifest = getManifestFnc
@@ -0,0 +1,78 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// configFingerprint returns the digest of the imageset configuration
+// and of the current digests of the catalogs it references, and of the
+// --release-digests file. Two runs with the same fingerprint mirror the
+// same operators; releases resolved with Cincinnati and images referenced
+// by tag may still differ.
+func (o *MirrorOptions) configFingerprint(ctx context.Context, cfg v1alpha2.ImageSetConfiguration) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(data)
+	if o.ReleaseDigests != "" {
+		releases, err := os.ReadFile(o.ReleaseDigests)
+		if err != nil {
+			return "", err
+		}
+		h.Write(releases)
+	}
+
+	sysContext := image.NewSystemContext(o.SourceSkipTLS || o.SourcePlainHTTP, o.OCIRegistriesConfig)
+	for _, ctlg := range cfg.Mirror.Operators {
+		// The bundles are listed by the configuration.
+		if ctlg.IsBundleList() {
+			continue
+		}
+		pin, err := catalogPin(ctx, sysContext, ctlg)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "\n%s=%s", ctlg.Catalog, pin)
+	}
+	return digest.NewDigest(digest.SHA256, h).String(), nil
+}
+
+// catalogPin returns the current digest of the content of the catalog
+// of ctlg: its image pinned by digest, or the digest of the index of its
+// OCI layout or of the files of its directory.
+func catalogPin(ctx context.Context, sysContext *types.SystemContext, ctlg v1alpha2.Operator) (pin string, err error) {
+	switch {
+	case ctlg.IsFBCOCI():
+		// The index of an OCI layout changes with its content.
+		index, err := os.ReadFile(filepath.Join(v1alpha2.TrimProtocol(ctlg.Catalog), "index.json"))
+		if err != nil {
+			return "", fmt.Errorf("error reading catalog %s: %v", ctlg.Catalog, err)
+		}
+		pin = digest.FromBytes(index).String()
+	case ctlg.IsFBCDir():
+		// The files of the directory are the content of the catalog.
+		if pin, err = hashFBCDir(v1alpha2.TrimProtocol(ctlg.Catalog)); err != nil {
+			return "", fmt.Errorf("error reading catalog %s: %v", ctlg.Catalog, err)
+		}
+	case image.IsImagePinned(ctlg.Catalog):
+		pin = ctlg.Catalog
+	default:
+		if pin, err = image.ResolveToPin(ctx, sysContext, ctlg.Catalog); err != nil {
+			return "", fmt.Errorf("error resolving catalog %s: %v", ctlg.Catalog, err)
+		}
+	}
+	return pin, nil
+}
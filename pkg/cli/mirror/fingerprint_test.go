@@ -0,0 +1,53 @@
+package mirror
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestConfigFingerprint(t *testing.T) {
+	layout := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(layout, "index.json"), []byte(`{"schemaVersion":2,"manifests":[]}`), 0600))
+	cfg := v1alpha2.ImageSetConfiguration{}
+	cfg.Mirror.Operators = []v1alpha2.Operator{
+		{Catalog: v1alpha2.OCITransportPrefix + "//" + layout},
+		{Catalog: "registry.example.com/catalog@sha256:1111111111111111111111111111111111111111111111111111111111111111"},
+	}
+
+	o := &MirrorOptions{}
+	fingerprint, err := o.configFingerprint(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Regexp(t, "^sha256:[a-f0-9]{64}$", fingerprint)
+	again, err := o.configFingerprint(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Equal(t, fingerprint, again)
+
+	t.Run("Valid/CatalogChanged", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(layout, "index.json"), []byte(`{"schemaVersion":2,"manifests":[{}]}`), 0600))
+		t.Cleanup(func() { os.WriteFile(filepath.Join(layout, "index.json"), []byte(`{"schemaVersion":2,"manifests":[]}`), 0600) })
+		changed, err := o.configFingerprint(context.Background(), cfg)
+		require.NoError(t, err)
+		require.NotEqual(t, fingerprint, changed)
+	})
+
+	t.Run("Valid/ConfigChanged", func(t *testing.T) {
+		cfg := cfg
+		cfg.Mirror.AdditionalImages = []v1alpha2.Image{{Name: "registry.example.com/app:v1"}}
+		changed, err := o.configFingerprint(context.Background(), cfg)
+		require.NoError(t, err)
+		require.NotEqual(t, fingerprint, changed)
+	})
+
+	t.Run("Invalid/MissingLayout", func(t *testing.T) {
+		cfg := v1alpha2.ImageSetConfiguration{}
+		cfg.Mirror.Operators = []v1alpha2.Operator{{Catalog: v1alpha2.OCITransportPrefix + "//" + filepath.Join(layout, "missing")}}
+		_, err := o.configFingerprint(context.Background(), cfg)
+		require.ErrorContains(t, err, "error reading catalog")
+	})
+}
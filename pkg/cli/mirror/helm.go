@@ -29,6 +29,7 @@ import (
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
 	"github.com/openshift/oc-mirror/pkg/config"
 	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/network"
 )
 
 type HelmOptions struct {
@@ -135,7 +136,7 @@ func (h *HelmOptions) PullCharts(ctx context.Context, cfg v1alpha2.ImageSetConfi
 func IndexFile(indexURL string) ([]v1alpha2.Chart, error) {
 	var charts []v1alpha2.Chart
 	var indexFile helmrepo.IndexFile
-	httpClient := http.Client{Timeout: time.Duration(5) * time.Second}
+	httpClient := http.Client{Transport: network.NewTransport(false), Timeout: time.Duration(5) * time.Second}
 	if !strings.HasSuffix(indexURL, "/index.yaml") {
 		indexURL += "/index.yaml"
 	}
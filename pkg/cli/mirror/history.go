@@ -0,0 +1,52 @@
+package mirror
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata"
+)
+
+// recordSequence records the images mirrored as of the sequence of meta, the
+// past associations assocs, in the history of the workspace, along with the sizes of the blobs of the v2 directory
+// diskPath transported by the sequence, for describe --diff. Failures are only
+// logged, the history does not affect the runs.
+func (o *MirrorOptions) recordSequence(meta v1alpha2.Metadata, assocs image.AssociationIterator, diskPath string, blobs []string) {
+	var sizes map[string]int64
+	if len(blobs) != 0 {
+		var err error
+		if sizes, err = blobSizes(diskPath, blobs); err != nil {
+			klog.Warningf("error reading the sizes of the blobs of sequence %d: %v", meta.PastMirror.Sequence, err)
+		}
+	}
+	if err := metadata.WriteSequenceRecord(o.Dir, metadata.NewSequenceRecord(meta, sizes), assocs); err != nil {
+		klog.Warningf("error recording sequence %d in the workspace history: %v", meta.PastMirror.Sequence, err)
+	}
+}
+
+// blobSizes returns the sizes of the blobs of the v2 directory diskPath
+// named by blobs, keyed by digest.
+func blobSizes(diskPath string, blobs []string) (map[string]int64, error) {
+	wanted := make(map[string]bool, len(blobs))
+	for _, b := range blobs {
+		wanted[b] = true
+	}
+	sizes := make(map[string]int64, len(blobs))
+	err := filepath.WalkDir(diskPath, func(fpath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() || filepath.Base(filepath.Dir(fpath)) != config.BlobDir || !wanted[d.Name()] {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sizes[d.Name()] = info.Size()
+		return nil
+	})
+	return sizes, err
+}
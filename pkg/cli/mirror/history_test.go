@@ -0,0 +1,38 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata"
+)
+
+func TestRecordSequence(t *testing.T) {
+	diskPath := filepath.Join(t.TempDir(), "v2")
+	blobsDir := filepath.Join(diskPath, "foo", "bar", "blobs")
+	require.NoError(t, os.MkdirAll(blobsDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(blobsDir, "sha256:aaaa"), []byte("layer"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(blobsDir, "sha256:bbbb"), []byte("known layer"), 0600))
+
+	o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+	meta := v1alpha2.NewMetadata()
+	meta.PastMirror.Sequence = 1
+	assoc := v1alpha2.Association{Name: "quay.io/foo/bar:v1", Path: "foo/bar", ID: "sha256:1111", Type: v1alpha2.TypeGeneric, LayerDigests: []string{"sha256:aaaa", "sha256:bbbb"}}
+	assocs, err := image.ConvertToAssociationSet([]v1alpha2.Association{assoc})
+	require.NoError(t, err)
+	o.recordSequence(meta, assocs, diskPath, []string{"sha256:aaaa"})
+
+	rec, db, err := metadata.ReadSequenceRecord(o.Dir, 1, filepath.Join(t.TempDir(), "record.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	require.Equal(t, map[string]int64{"sha256:aaaa": 5}, rec.BlobSizes)
+	values, _, err := db.Search(assoc.Name)
+	require.NoError(t, err)
+	require.Equal(t, []v1alpha2.Association{assoc}, values)
+}
@@ -0,0 +1,199 @@
+package mirror
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// aggregatedICSPName is the name prefix of the ICSPs consolidated with --icsp-budget.
+const aggregatedICSPName = "aggregated"
+
+// icspBudgetBytes returns the number of bytes of ICSPBudget,
+// or 0 when the ICSPs are not consolidated.
+func (o *MirrorOptions) icspBudgetBytes() (int64, error) {
+	if o.ICSPBudget == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(o.ICSPBudget)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --icsp-budget %q: %v", o.ICSPBudget, err)
+	}
+	if q.Sign() <= 0 {
+		return 0, fmt.Errorf("--icsp-budget must be positive")
+	}
+	return q.Value(), nil
+}
+
+// consolidateICSPs replaces icsps with the fewest ICSPs holding their repository
+// digest mirrors, aggregated by registry scope, and fails if their total size
+// exceeds budget bytes. Each ICSP stays under the per object limit byteLimit.
+func consolidateICSPs(icsps []operatorv1alpha1.ImageContentSourcePolicy, budget int64, byteLimit int) ([]operatorv1alpha1.ImageContentSourcePolicy, error) {
+	var entries []operatorv1alpha1.RepositoryDigestMirrors
+	for _, icsp := range icsps {
+		entries = append(entries, icsp.Spec.RepositoryDigestMirrors...)
+	}
+	aggregated := aggregateDigestMirrors(entries)
+	klog.V(1).Infof("Aggregated %d repository digest mirrors into %d", len(entries), len(aggregated))
+
+	var consolidated []operatorv1alpha1.ImageContentSourcePolicy
+	var total int64
+	newICSP := func() operatorv1alpha1.ImageContentSourcePolicy {
+		return operatorv1alpha1.ImageContentSourcePolicy{
+			TypeMeta: icspTypeMeta,
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   aggregatedICSPName + "-" + strconv.Itoa(len(consolidated)),
+				Labels: map[string]string{icspScopeLabel: aggregatedICSPName},
+			},
+		}
+	}
+	icsp := newICSP()
+	var size int
+	for _, entry := range aggregated {
+		icsp.Spec.RepositoryDigestMirrors = append(icsp.Spec.RepositoryDigestMirrors, entry)
+		y, err := yaml.Marshal(icsp)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal ImageContentSourcePolicy yaml: %v", err)
+		}
+		if len(y) <= byteLimit {
+			size = len(y)
+			continue
+		}
+		if len(icsp.Spec.RepositoryDigestMirrors) == 1 {
+			return nil, fmt.Errorf("repository digest mirror for %q cannot fit into any ICSP with byte limit %d", entry.Source, byteLimit)
+		}
+		icsp.Spec.RepositoryDigestMirrors = icsp.Spec.RepositoryDigestMirrors[:len(icsp.Spec.RepositoryDigestMirrors)-1]
+		consolidated = append(consolidated, icsp)
+		total += int64(size)
+		icsp = newICSP()
+		icsp.Spec.RepositoryDigestMirrors = append(icsp.Spec.RepositoryDigestMirrors, entry)
+		y, err = yaml.Marshal(icsp)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal ImageContentSourcePolicy yaml: %v", err)
+		}
+		size = len(y)
+	}
+	if len(icsp.Spec.RepositoryDigestMirrors) != 0 {
+		consolidated = append(consolidated, icsp)
+		total += int64(size)
+	}
+	if total > budget {
+		return nil, fmt.Errorf("the %d ImageContentSourcePolicies holding the %d aggregated repository digest mirrors take %d bytes, over the --icsp-budget of %d bytes",
+			len(consolidated), len(aggregated), total, budget)
+	}
+	klog.Infof("Consolidated %d ImageContentSourcePolicies into %d of %d bytes", len(icsps), len(consolidated), total)
+	return consolidated, nil
+}
+
+// aggregateDigestMirrors merges the entries with the same source and replaces
+// the entries of a registry, or else of a namespace of a registry, with a single
+// entry when they all mirror their source path under the same destination,
+// e.g. quay.io/a/b to mirror.local/ns/a/b and quay.io/c/d to mirror.local/ns/c/d
+// with quay.io to mirror.local/ns. The entries are returned sorted by source.
+func aggregateDigestMirrors(entries []operatorv1alpha1.RepositoryDigestMirrors) []operatorv1alpha1.RepositoryDigestMirrors {
+	bySource := map[string][]string{}
+	for _, e := range entries {
+		bySource[e.Source] = appendMissing(bySource[e.Source], e.Mirrors...)
+	}
+	// A namespace scope is only aggregated into the scope of its registry,
+	// the registry itself is not part of a wider scope.
+	for _, depth := range []int{1, 0} {
+		groups := map[string][]string{}
+		for source := range bySource {
+			if scope, ok := sourceScope(source, depth); ok && scope != source {
+				groups[scope] = append(groups[scope], source)
+			}
+		}
+		for scope, sources := range groups {
+			mirrors, ok := commonMirrorPrefixes(scope, sources, bySource)
+			if !ok {
+				continue
+			}
+			// The scope may be a source itself, mirrored the same way.
+			if existing, found := bySource[scope]; found && !equalStrings(existing, mirrors) {
+				continue
+			}
+			for _, source := range sources {
+				delete(bySource, source)
+			}
+			bySource[scope] = mirrors
+		}
+	}
+
+	aggregated := make([]operatorv1alpha1.RepositoryDigestMirrors, 0, len(bySource))
+	for source, mirrors := range bySource {
+		aggregated = append(aggregated, operatorv1alpha1.RepositoryDigestMirrors{Source: source, Mirrors: mirrors})
+	}
+	sort.Slice(aggregated, func(i, j int) bool {
+		return aggregated[i].Source < aggregated[j].Source
+	})
+	return aggregated
+}
+
+// sourceScope returns the registry of source followed by
+// its first depth path components, false if source has fewer.
+func sourceScope(source string, depth int) (string, bool) {
+	parts := strings.Split(source, "/")
+	if len(parts) <= depth {
+		return "", false
+	}
+	return strings.Join(parts[:depth+1], "/"), true
+}
+
+// commonMirrorPrefixes returns the mirrors of scope if every source under scope
+// is mirrored to the same mirrors followed by its path under scope.
+func commonMirrorPrefixes(scope string, sources []string, bySource map[string][]string) ([]string, bool) {
+	var common []string
+	for i, source := range sources {
+		suffix := strings.TrimPrefix(source, scope)
+		prefixes := make([]string, 0, len(bySource[source]))
+		for _, mirror := range bySource[source] {
+			if !strings.HasSuffix(mirror, suffix) {
+				return nil, false
+			}
+			prefixes = append(prefixes, strings.TrimSuffix(mirror, suffix))
+		}
+		if i == 0 {
+			common = prefixes
+		} else if !equalStrings(common, prefixes) {
+			return nil, false
+		}
+	}
+	return common, true
+}
+
+// appendMissing appends the values missing from s to s.
+func appendMissing(s []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range s {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s = append(s, v)
+		}
+	}
+	return s
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package mirror
+
+import (
+	"testing"
+
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateDigestMirrors(t *testing.T) {
+	type spec struct {
+		name    string
+		entries []operatorv1alpha1.RepositoryDigestMirrors
+		exp     []operatorv1alpha1.RepositoryDigestMirrors
+	}
+	cases := []spec{
+		{
+			name: "Valid/Registry",
+			entries: []operatorv1alpha1.RepositoryDigestMirrors{
+				{Source: "quay.io/openshift-release-dev/ocp-release", Mirrors: []string{"mirror.local/ns/openshift-release-dev/ocp-release"}},
+				{Source: "quay.io/openshift-release-dev/ocp-v4.0-art-dev", Mirrors: []string{"mirror.local/ns/openshift-release-dev/ocp-v4.0-art-dev"}},
+				{Source: "quay.io/app", Mirrors: []string{"mirror.local/ns/app"}},
+			},
+			exp: []operatorv1alpha1.RepositoryDigestMirrors{
+				{Source: "quay.io", Mirrors: []string{"mirror.local/ns"}},
+			},
+		},
+		{
+			name: "Valid/Namespace",
+			entries: []operatorv1alpha1.RepositoryDigestMirrors{
+				{Source: "registry.redhat.io/rhel8/foo", Mirrors: []string{"mirror.local/ns/rhel8/foo"}},
+				{Source: "registry.redhat.io/rhel8/bar", Mirrors: []string{"mirror.local/ns/rhel8/bar"}},
+				{Source: "registry.redhat.io/ubi8", Mirrors: []string{"other.local/ubi8"}},
+			},
+			exp: []operatorv1alpha1.RepositoryDigestMirrors{
+				{Source: "registry.redhat.io/rhel8", Mirrors: []string{"mirror.local/ns/rhel8"}},
+				{Source: "registry.redhat.io/ubi8", Mirrors: []string{"other.local/ubi8"}},
+			},
+		},
+		{
+			name: "Valid/DifferentDestinations",
+			entries: []operatorv1alpha1.RepositoryDigestMirrors{
+				{Source: "quay.io/a/foo", Mirrors: []string{"mirror.local/x/foo"}},
+				{Source: "quay.io/a/bar", Mirrors: []string{"mirror.local/y/bar"}},
+			},
+			exp: []operatorv1alpha1.RepositoryDigestMirrors{
+				{Source: "quay.io/a/bar", Mirrors: []string{"mirror.local/y/bar"}},
+				{Source: "quay.io/a/foo", Mirrors: []string{"mirror.local/x/foo"}},
+			},
+		},
+		{
+			name: "Valid/DuplicateSources",
+			entries: []operatorv1alpha1.RepositoryDigestMirrors{
+				{Source: "quay.io", Mirrors: []string{"mirror.local/ns"}},
+				{Source: "quay.io", Mirrors: []string{"mirror.local/ns"}},
+				{Source: "quay.io/a", Mirrors: []string{"mirror.local/ns/a"}},
+			},
+			exp: []operatorv1alpha1.RepositoryDigestMirrors{
+				{Source: "quay.io", Mirrors: []string{"mirror.local/ns"}},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.exp, aggregateDigestMirrors(c.entries))
+		})
+	}
+}
+
+func TestConsolidateICSPs(t *testing.T) {
+	icsps := []operatorv1alpha1.ImageContentSourcePolicy{
+		(&ReleaseBuilder{}).New("release", 0),
+		(&OperatorBuilder{Catalog: "redhat-operator-index"}).New("operator", 0),
+	}
+	icsps[0].Spec.RepositoryDigestMirrors = []operatorv1alpha1.RepositoryDigestMirrors{
+		{Source: "quay.io/openshift-release-dev", Mirrors: []string{"mirror.local/ns/openshift-release-dev"}},
+	}
+	icsps[1].Spec.RepositoryDigestMirrors = []operatorv1alpha1.RepositoryDigestMirrors{
+		{Source: "registry.redhat.io/rhel8", Mirrors: []string{"mirror.local/ns/rhel8"}},
+		{Source: "registry.redhat.io/openshift4", Mirrors: []string{"mirror.local/ns/openshift4"}},
+		{Source: "quay.io/app", Mirrors: []string{"mirror.local/ns/app"}},
+	}
+
+	consolidated, err := consolidateICSPs(icsps, 1<<20, icspSizeLimit)
+	require.NoError(t, err)
+	require.Len(t, consolidated, 1)
+	require.Equal(t, "aggregated-0", consolidated[0].Name)
+	require.Equal(t, map[string]string{icspScopeLabel: "aggregated"}, consolidated[0].Labels)
+	require.Equal(t, []operatorv1alpha1.RepositoryDigestMirrors{
+		{Source: "quay.io", Mirrors: []string{"mirror.local/ns"}},
+		{Source: "registry.redhat.io", Mirrors: []string{"mirror.local/ns"}},
+	}, consolidated[0].Spec.RepositoryDigestMirrors)
+
+	// Each ICSP holds what fits under the per object limit.
+	consolidated, err = consolidateICSPs(icsps, 1<<20, 320)
+	require.NoError(t, err)
+	require.Len(t, consolidated, 2)
+	require.Equal(t, "aggregated-1", consolidated[1].Name)
+
+	_, err = consolidateICSPs(icsps, 100, icspSizeLimit)
+	require.ErrorContains(t, err, "over the --icsp-budget of 100 bytes")
+}
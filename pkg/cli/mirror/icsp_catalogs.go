@@ -0,0 +1,94 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// catalogImageSet holds the exact references of the bundle
+// and related images of a catalog.
+type catalogImageSet map[string]struct{}
+
+// addCatalogImages records the bundle and related images of the catalog
+// rendered to indexDir, keyed by the name of the CatalogSource generated
+// for the catalog image catalogRef, so operator ICSPs can be generated
+// for each catalog.
+func (o *MirrorOptions) addCatalogImages(ctx context.Context, catalogRef image.TypedImageReference, indexDir string) error {
+	catalog, err := createRFC1035NameForCatalogSource(catalogRef.Ref.Name)
+	if err != nil {
+		return err
+	}
+	dc, err := declcfg.LoadFS(ctx, os.DirFS(indexDir))
+	if err != nil {
+		return fmt.Errorf("error loading catalog index %s: %v", indexDir, err)
+	}
+
+	if o.catalogImages == nil {
+		o.catalogImages = map[string]catalogImageSet{}
+	}
+	images, found := o.catalogImages[catalog]
+	if !found {
+		images = catalogImageSet{}
+		o.catalogImages[catalog] = images
+	}
+	for _, b := range dc.Bundles {
+		refs := []string{b.Image}
+		for _, ri := range b.RelatedImages {
+			refs = append(refs, ri.Image)
+		}
+		for _, ref := range refs {
+			if ref == "" {
+				continue
+			}
+			img, err := image.ParseReference(ref)
+			if err != nil {
+				klog.Warningf("unable to parse image %s of catalog %s: %v", ref, catalog, err)
+				continue
+			}
+			images[img.Ref.Exact()] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// splitByCatalog returns the images of mapping referenced by each catalog of catalogImages,
+// keyed by catalog, and the images of mapping referenced by none of the catalogs.
+// An image referenced by several catalogs is returned for each of them.
+func splitByCatalog(mapping image.TypedImageMapping, catalogImages map[string]catalogImageSet) (map[string]image.TypedImageMapping, image.TypedImageMapping) {
+	byCatalog := map[string]image.TypedImageMapping{}
+	unattributed := image.TypedImageMapping{}
+	for src, dst := range mapping {
+		var found bool
+		for catalog, images := range catalogImages {
+			if _, ok := images[src.Ref.Exact()]; !ok {
+				continue
+			}
+			if byCatalog[catalog] == nil {
+				byCatalog[catalog] = image.TypedImageMapping{}
+			}
+			byCatalog[catalog][src] = dst
+			found = true
+		}
+		if !found {
+			unattributed[src] = dst
+		}
+	}
+	return byCatalog, unattributed
+}
+
+// sortedCatalogs returns the catalogs of byCatalog sorted by name.
+func sortedCatalogs(byCatalog map[string]image.TypedImageMapping) []string {
+	catalogs := make([]string, 0, len(byCatalog))
+	for catalog := range byCatalog {
+		catalogs = append(catalogs, catalog)
+	}
+	sort.Strings(catalogs)
+	return catalogs
+}
@@ -0,0 +1,75 @@
+package mirror
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestAddCatalogImages(t *testing.T) {
+	indexDir := t.TempDir()
+	index := `{"schema": "olm.package", "name": "foo"}
+{"schema": "olm.bundle", "name": "foo.v1", "package": "foo", "image": "quay.io/foo/bundle@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+ "relatedImages": [{"name": "operator", "image": "quay.io/foo/operator@sha256:0000000000000000000000000000000000000000000000000000000000000002"}]}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(indexDir, "index.json"), []byte(index), 0600))
+
+	catalogRef, err := image.ParseReference("registry.redhat.io/redhat/redhat-operator-index:v4.14")
+	require.NoError(t, err)
+
+	o := &MirrorOptions{}
+	require.NoError(t, o.addCatalogImages(context.Background(), catalogRef, indexDir))
+	require.Equal(t, map[string]catalogImageSet{
+		"cs-redhat-operator-index": {
+			"quay.io/foo/bundle@sha256:0000000000000000000000000000000000000000000000000000000000000001":   {},
+			"quay.io/foo/operator@sha256:0000000000000000000000000000000000000000000000000000000000000002": {},
+		},
+	}, o.catalogImages)
+}
+
+func TestSplitByCatalog(t *testing.T) {
+	mapping := image.TypedImageMapping{}
+	add := func(src string) {
+		srcRef, err := image.ParseReference(src)
+		require.NoError(t, err)
+		dstRef := srcRef
+		dstRef.Ref.Registry = "localhost:5000"
+		mapping.Add(srcRef, dstRef, v1alpha2.TypeOperatorRelatedImage)
+	}
+	add("quay.io/foo/shared@sha256:0000000000000000000000000000000000000000000000000000000000000001")
+	add("quay.io/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000002")
+	add("quay.io/foo/other@sha256:0000000000000000000000000000000000000000000000000000000000000003")
+
+	byCatalog, unattributed := splitByCatalog(mapping, map[string]catalogImageSet{
+		"cs-a": {
+			"quay.io/foo/shared@sha256:0000000000000000000000000000000000000000000000000000000000000001": {},
+			"quay.io/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000002":    {},
+		},
+		"cs-b": {
+			"quay.io/foo/shared@sha256:0000000000000000000000000000000000000000000000000000000000000001": {},
+		},
+	})
+	require.Equal(t, []string{"cs-a", "cs-b"}, sortedCatalogs(byCatalog))
+	require.Len(t, byCatalog["cs-a"], 2)
+	require.Len(t, byCatalog["cs-b"], 1)
+	require.Len(t, unattributed, 1)
+	for src := range unattributed {
+		require.Equal(t, "other", src.Ref.Name)
+	}
+}
+
+func TestOperatorBuilderCatalog(t *testing.T) {
+	icsp := (&OperatorBuilder{Catalog: "cs-redhat-operator-index"}).New("operator-cs-redhat-operator-index", 0)
+	require.Equal(t, "operator-cs-redhat-operator-index-0", icsp.Name)
+	require.Equal(t, map[string]string{
+		"operators.openshift.org/catalog": "true",
+		icspScopeLabel:                    "operator",
+		icspCatalogLabel:                  "cs-redhat-operator-index",
+	}, icsp.Labels)
+}
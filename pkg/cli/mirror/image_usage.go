@@ -0,0 +1,84 @@
+package mirror
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+// imageUsage holds the bundles referencing an operator image.
+type imageUsage struct {
+	Image      string           `json:"image"`
+	References []imageReference `json:"references"`
+}
+
+// imageReference is a bundle of a catalog referencing an image.
+type imageReference struct {
+	Catalog string `json:"catalog"`
+	Package string `json:"package"`
+	Bundle  string `json:"bundle"`
+}
+
+// imageUsageReport maps operator images to the bundles referencing them.
+type imageUsageReport map[string][]imageReference
+
+// add records the bundle image and the related images of
+// each bundle of dc, rendered from the catalog image catalog.
+func (r imageUsageReport) add(catalog string, dc *declcfg.DeclarativeConfig) {
+	for _, b := range dc.Bundles {
+		ref := imageReference{Catalog: catalog, Package: b.Package, Bundle: b.Name}
+		images := map[string]struct{}{}
+		if b.Image != "" {
+			images[b.Image] = struct{}{}
+		}
+		for _, ri := range b.RelatedImages {
+			if ri.Image != "" {
+				images[ri.Image] = struct{}{}
+			}
+		}
+		for img := range images {
+			r[img] = append(r[img], ref)
+		}
+	}
+}
+
+// usages returns the usage of each image, sorted by image,
+// with references sorted by catalog, package and bundle.
+func (r imageUsageReport) usages() []imageUsage {
+	usages := make([]imageUsage, 0, len(r))
+	for img, refs := range r {
+		sort.Slice(refs, func(i, j int) bool {
+			if refs[i].Catalog != refs[j].Catalog {
+				return refs[i].Catalog < refs[j].Catalog
+			}
+			if refs[i].Package != refs[j].Package {
+				return refs[i].Package < refs[j].Package
+			}
+			return refs[i].Bundle < refs[j].Bundle
+		})
+		usages = append(usages, imageUsage{Image: img, References: refs})
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Image < usages[j].Image
+	})
+	return usages
+}
+
+// writeImageUsageReport writes the operator image usage report to the
+// workspace, to find the images left unused when packages are removed
+// from the imageset configuration, and for describe image.
+func (o *OperatorOptions) writeImageUsageReport(report imageUsageReport) error {
+	data, err := json.MarshalIndent(report.usages(), "", "  ")
+	if err != nil {
+		return err
+	}
+	reportPath := filepath.Clean(filepath.Join(o.Dir, config.OperatorImageUsageFile))
+	klog.Infof("Writing operator image usage report to %s", reportPath)
+	return os.WriteFile(reportPath, data, 0600)
+}
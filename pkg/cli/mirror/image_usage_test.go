@@ -0,0 +1,67 @@
+package mirror
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+func TestImageUsageReport(t *testing.T) {
+	report := imageUsageReport{}
+	report.add("registry.example.com/redhat/redhat-operator-index:v4.14", &declcfg.DeclarativeConfig{
+		Bundles: []declcfg.Bundle{
+			{
+				Name:    "foo.v1.0.0",
+				Package: "foo",
+				Image:   "quay.io/example/foo-bundle@sha256:01",
+				RelatedImages: []declcfg.RelatedImage{
+					{Name: "bundle", Image: "quay.io/example/foo-bundle@sha256:01"},
+					{Name: "operator", Image: "quay.io/example/foo-operator@sha256:02"},
+					{Name: "kube-rbac-proxy", Image: "quay.io/example/kube-rbac-proxy@sha256:03"},
+				},
+			},
+		},
+	})
+	report.add("registry.example.com/redhat/certified-operator-index:v4.14", &declcfg.DeclarativeConfig{
+		Bundles: []declcfg.Bundle{
+			{
+				Name:          "bar.v2.0.0",
+				Package:       "bar",
+				Image:         "quay.io/example/bar-bundle@sha256:04",
+				RelatedImages: []declcfg.RelatedImage{{Name: "kube-rbac-proxy", Image: "quay.io/example/kube-rbac-proxy@sha256:03"}},
+			},
+		},
+	})
+
+	require.Equal(t, []imageUsage{
+		{Image: "quay.io/example/bar-bundle@sha256:04", References: []imageReference{
+			{Catalog: "registry.example.com/redhat/certified-operator-index:v4.14", Package: "bar", Bundle: "bar.v2.0.0"},
+		}},
+		{Image: "quay.io/example/foo-bundle@sha256:01", References: []imageReference{
+			{Catalog: "registry.example.com/redhat/redhat-operator-index:v4.14", Package: "foo", Bundle: "foo.v1.0.0"},
+		}},
+		{Image: "quay.io/example/foo-operator@sha256:02", References: []imageReference{
+			{Catalog: "registry.example.com/redhat/redhat-operator-index:v4.14", Package: "foo", Bundle: "foo.v1.0.0"},
+		}},
+		{Image: "quay.io/example/kube-rbac-proxy@sha256:03", References: []imageReference{
+			{Catalog: "registry.example.com/redhat/certified-operator-index:v4.14", Package: "bar", Bundle: "bar.v2.0.0"},
+			{Catalog: "registry.example.com/redhat/redhat-operator-index:v4.14", Package: "foo", Bundle: "foo.v1.0.0"},
+		}},
+	}, report.usages())
+
+	dir := t.TempDir()
+	opts := NewOperatorOptions(&MirrorOptions{RootOptions: &cli.RootOptions{Dir: dir}})
+	require.NoError(t, opts.writeImageUsageReport(report))
+	data, err := os.ReadFile(filepath.Join(dir, config.OperatorImageUsageFile))
+	require.NoError(t, err)
+	var usages []imageUsage
+	require.NoError(t, json.Unmarshal(data, &usages))
+	require.Len(t, usages, 4)
+}
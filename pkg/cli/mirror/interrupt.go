@@ -0,0 +1,146 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openshift/oc/pkg/cli/image/mirror"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+// mirrorBatchSize is the number of images mirrored by each run of
+// `oc image mirror`. An interrupted run stops between two batches.
+const mirrorBatchSize = 50
+
+// interruptionTimeout bounds the writes of an interrupted run,
+// done after the cancellation of its context.
+const interruptionTimeout = 30 * time.Second
+
+// checkInterrupted returns an error of class ErrorClassInterrupted
+// if ctx is cancelled, nil otherwise.
+func (o *MirrorOptions) checkInterrupted(ctx context.Context) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return NewClassifiedError(ErrorClassInterrupted, fmt.Errorf("run interrupted in the %s phase after mirroring %d images", o.phase, o.imagesMirrored))
+}
+
+// interrupted returns the error err of a run stopped by the cancellation of
+// its context as an error of class ErrorClassInterrupted, and removes the
+// temporary directories of the run. The images mirrored to the workspace
+// are kept for the next run.
+func (o *MirrorOptions) interrupted(err error) error {
+	klog.Warningf("Run interrupted in the %s phase after mirroring %d images, removing temporary directories", o.phase, o.imagesMirrored)
+	if !o.SkipCleanup {
+		os.RemoveAll(artifactsFolderName)
+		removeTmpDirs()
+	}
+	if ClassifyError(err) == ErrorClassInterrupted {
+		return err
+	}
+	return NewClassifiedError(ErrorClassInterrupted, fmt.Errorf("run interrupted: %v", err))
+}
+
+// runMirrorBatches runs opts on mappings in batches of mirrorBatchSize images,
+// so that an interrupted run stops between two images instead of in the middle
+// of one. With --continue-on-error, the failure of a batch does not stop the next ones.
+func (o *MirrorOptions) runMirrorBatches(ctx context.Context, opts *mirror.MirrorImageOptions, mappings []mirror.Mapping) error {
+	var errs []error
+	for start := 0; start < len(mappings); start += mirrorBatchSize {
+		o.monitor.waitIfPaused(ctx)
+		if err := o.checkInterrupted(ctx); err != nil {
+			return err
+		}
+		end := min(start+mirrorBatchSize, len(mappings))
+		opts.Mappings = mappings[start:end]
+		names := make([]string, 0, end-start)
+		for _, m := range opts.Mappings {
+			names = append(names, m.Source.String())
+		}
+		o.monitor.start(names...)
+		if err := opts.Run(); err != nil {
+			o.monitor.addError(err)
+			if !o.ContinueOnError {
+				return err
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if !o.DryRun {
+			o.countMirrored(end - start)
+			o.monitor.finish(names...)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// recordInterruption records the interruption of the run creating or
+// publishing sequence in the metadata of backend, if any, so that the next
+// run reports it. Errors are logged and never change the outcome of the run.
+func (o *MirrorOptions) recordInterruption(ctx context.Context, backend storage.Backend, sequence, planned int) {
+	if backend == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), interruptionTimeout)
+	defer cancel()
+
+	// The associations of the metadata are kept as is, streamed through the workspace.
+	var meta v1alpha2.Metadata
+	assocs, err := storage.ReadMetadataStream(ctx, backend, &meta, config.MetadataBasePath, map[string]string{
+		image.PastAssociationsField:     o.associationsDBPath(interruptedAssociationsDB),
+		image.MirroredAssociationsField: o.associationsDBPath(interruptedMirroredDB),
+	})
+	switch {
+	case errors.Is(err, storage.ErrMetadataNotExist):
+		klog.V(1).Infof("No metadata to record the interruption of the run in")
+		return
+	case err != nil:
+		klog.Warningf("unable to record the interruption of the run in the metadata: %v", err)
+		return
+	}
+	defer assocs.Close()
+	meta.Interrupted = &v1alpha2.InterruptedRun{
+		Timestamp:      int(time.Now().Unix()),
+		Sequence:       sequence,
+		Phase:          o.phase,
+		ImagesMirrored: o.imagesMirrored,
+		ImagesPlanned:  planned,
+	}
+	if err := storage.WriteMetadataStream(ctx, backend, &meta, config.MetadataBasePath, assocs.Iterators()); err != nil {
+		klog.Warningf("unable to record the interruption of the run in the metadata: %v", err)
+		return
+	}
+	klog.Infof("Recorded the interruption of sequence %d in the metadata", sequence)
+}
+
+// recordConfigInterruption records the interruption of the run in the
+// metadata of the storage configured by cfg. Stateless runs keep no metadata.
+func (o *MirrorOptions) recordConfigInterruption(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, sequence, planned int) {
+	if !cfg.StorageConfig.IsSet() {
+		return
+	}
+	backend, err := storage.ByConfig(o.Dir, cfg.StorageConfig)
+	if err != nil {
+		klog.Warningf("unable to record the interruption of the run in the metadata: %v", err)
+		return
+	}
+	o.recordInterruption(ctx, backend, sequence, planned)
+}
+
+// logInterruptedRun warns about the run recorded as interrupted in the metadata.
+func logInterruptedRun(r *v1alpha2.InterruptedRun) {
+	if r == nil {
+		return
+	}
+	klog.Warningf("The run of sequence %d was interrupted at %s in the %s phase, after mirroring %d of %d images",
+		r.Sequence, time.Unix(int64(r.Timestamp), 0).UTC().Format(time.RFC3339), r.Phase, r.ImagesMirrored, r.ImagesPlanned)
+}
@@ -0,0 +1,80 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openshift/oc/pkg/cli/image/mirror"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+func TestCheckInterrupted(t *testing.T) {
+	// SkipCleanup keeps the temporary directories of other runs.
+	o := &MirrorOptions{phase: phaseMirror, imagesMirrored: 3, SkipCleanup: true}
+	require.NoError(t, o.checkInterrupted(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := o.checkInterrupted(ctx)
+	require.EqualError(t, err, "run interrupted in the mirror phase after mirroring 3 images")
+	require.Equal(t, ErrorClassInterrupted, ClassifyError(err))
+
+	err = o.interrupted(errors.New("error resolving catalog: context canceled"))
+	require.EqualError(t, err, "run interrupted: error resolving catalog: context canceled")
+	require.Equal(t, 10, ClassifyError(err).ExitCode())
+}
+
+func TestRunMirrorBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	o := &MirrorOptions{phase: phaseMirror}
+	opts := mirror.NewMirrorImageOptions(genericiooptions.IOStreams{})
+	err := o.runMirrorBatches(ctx, opts, make([]mirror.Mapping, 2*mirrorBatchSize))
+	require.Equal(t, ErrorClassInterrupted, ClassifyError(err))
+	require.Empty(t, opts.Mappings)
+	require.Zero(t, o.imagesMirrored)
+}
+
+func TestRecordInterruption(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}, phase: phaseMirror, imagesMirrored: 3}
+
+	t.Run("Valid/Recorded", func(t *testing.T) {
+		backend, err := storage.NewLocalBackend(t.TempDir())
+		require.NoError(t, err)
+		meta := v1alpha2.NewMetadata()
+		meta.PastMirror.Sequence = 1
+		meta.PastAssociations = []v1alpha2.Association{
+			{Name: "quay.io/foo/app:v1", Path: "foo/app", ID: "sha256:1111", TagSymlink: "v1", Type: v1alpha2.TypeGeneric, LayerDigests: []string{"sha256:aaaa"}},
+		}
+		require.NoError(t, backend.WriteMetadata(context.Background(), &meta, config.MetadataBasePath))
+
+		o.recordInterruption(ctx, backend, 2, 10)
+
+		var recorded v1alpha2.Metadata
+		require.NoError(t, backend.ReadMetadata(context.Background(), &recorded, config.MetadataBasePath))
+		require.Equal(t, 1, recorded.PastMirror.Sequence)
+		require.Equal(t, meta.PastAssociations, recorded.PastAssociations)
+		require.NotNil(t, recorded.Interrupted)
+		require.NotZero(t, recorded.Interrupted.Timestamp)
+		recorded.Interrupted.Timestamp = 0
+		require.Equal(t, &v1alpha2.InterruptedRun{Sequence: 2, Phase: phaseMirror, ImagesMirrored: 3, ImagesPlanned: 10}, recorded.Interrupted)
+	})
+	t.Run("Valid/NoMetadata", func(t *testing.T) {
+		backend, err := storage.NewLocalBackend(t.TempDir())
+		require.NoError(t, err)
+
+		o.recordInterruption(ctx, backend, 1, 10)
+
+		var recorded v1alpha2.Metadata
+		require.ErrorIs(t, backend.ReadMetadata(context.Background(), &recorded, config.MetadataBasePath), storage.ErrMetadataNotExist)
+	})
+}
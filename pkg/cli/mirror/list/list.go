@@ -17,6 +17,7 @@ func NewListCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
 
 	cmd.AddCommand(NewOperatorsCommand(f, ro))
 	cmd.AddCommand(NewReleasesCommand(f, ro))
+	cmd.AddCommand(NewReleaseContentsCommand(f, ro))
 	cmd.AddCommand(NewUpdatesCommand(f, ro))
 
 	return cmd
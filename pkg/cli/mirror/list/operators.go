@@ -22,6 +22,14 @@ type OperatorsOptions struct {
 	Channel  string
 	Version  string
 	Catalogs bool
+	// ConfigPath is the imageset configuration whose operator
+	// catalog filtering is simulated.
+	ConfigPath string
+	// Profile is the name of a profile merged onto the configuration.
+	Profile string
+	// Overlays are the imageset configurations merged onto the
+	// configuration before the profile.
+	Overlays []string
 }
 
 func NewOperatorsCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
@@ -46,6 +54,9 @@ func NewOperatorsCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command
 
 			# List all available versions for a specified operator in a channel
 			oc-mirror list operators --catalog=catalog-name --package=operator-name --channel=channel-name
+
+			# List the bundles each package of the operator catalogs of an imageset configuration selects
+			oc-mirror list operators --config=imageset-config.yaml
 		`),
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete())
@@ -60,6 +71,11 @@ func NewOperatorsCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command
 	fs.StringVar(&o.Package, "package", o.Package, "List information for a specified package")
 	fs.StringVar(&o.Channel, "channel", o.Channel, "List information for a specified channel")
 	fs.StringVar(&o.Version, "version", o.Version, "Specify an OpenShift release version")
+	fs.StringVar(&o.ConfigPath, "config", o.ConfigPath, "List the bundles each package of the operator catalogs of an imageset configuration selects, "+
+		"failing if a package selects none")
+	fs.StringVar(&o.Profile, "profile", o.Profile, "Name of a profile in the imageset configuration to merge onto the base configuration")
+	fs.StringArrayVar(&o.Overlays, "overlay", o.Overlays, "Path to an imageset configuration to merge onto the base configuration before the profile. "+
+		"Can be repeated, each overlay taking precedence over the previous ones")
 
 	o.BindFlags(cmd.PersistentFlags())
 
@@ -74,6 +90,9 @@ func (o *OperatorsOptions) Complete() error {
 }
 
 func (o *OperatorsOptions) Validate() error {
+	if len(o.ConfigPath) > 0 && (o.Catalogs || len(o.Catalog) > 0 || len(o.Package) > 0 || len(o.Channel) > 0) {
+		return errors.New("--config cannot be used with --catalogs, --version, --catalog, --package or --channel")
+	}
 	if len(o.Version) == 0 && o.Catalogs {
 		return errors.New("must specify --version with --catalogs")
 	}
@@ -93,6 +112,8 @@ func (o *OperatorsOptions) Run(cmd *cobra.Command) error {
 
 	// Process cases from most specific to most broad
 	switch {
+	case len(o.ConfigPath) > 0:
+		return o.runConfig(ctx)
 	case len(o.Channel) > 0:
 		// Print Version for all bundles in a channel
 		var ch model.Channel
@@ -0,0 +1,322 @@
+package list
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/network"
+	"github.com/openshift/oc-mirror/pkg/operator"
+	"github.com/openshift/oc-mirror/pkg/operator/diff"
+)
+
+// packageSelection is the bundles of a package of a catalog
+// selected by the imageset configuration.
+type packageSelection struct {
+	pkg string
+	// selection describes the filter of the package.
+	selection string
+	bundles   []selectedBundle
+	// err is why the package selects no bundles, if it failed to filter.
+	err error
+}
+
+// selectedBundle is a bundle selected in a channel of a package.
+type selectedBundle struct {
+	channel string
+	name    string
+	version semver.Version
+}
+
+// runConfig prints the bundles the operator catalogs of the imageset
+// configuration select, and returns an error naming the packages
+// selecting none.
+func (o *OperatorsOptions) runConfig(ctx context.Context) error {
+	cfg, err := config.ReadConfigWithOverlays(o.ConfigPath, o.Overlays, o.Profile)
+	if err != nil {
+		return err
+	}
+	if err := network.Configure(cfg.Proxy); err != nil {
+		return err
+	}
+	if len(cfg.Mirror.Operators) == 0 {
+		_, err := fmt.Fprintf(o.IOStreams.Out, "No operator catalogs found in %s\n", o.ConfigPath)
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp(o.Dir, "listtmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	reg, err := containerdregistry.NewRegistry(
+		containerdregistry.SkipTLSVerify(false),
+		containerdregistry.WithRootCAs(network.RootCAs()),
+		containerdregistry.WithCacheDir(filepath.Join(tmpDir, "cache")),
+	)
+	if err != nil {
+		return err
+	}
+	defer reg.Destroy()
+
+	var empty []string
+	for i, ctlg := range cfg.Mirror.Operators {
+		switch {
+		case ctlg.IsBundleList():
+			// Catalogs generated from bundles select their bundles by image.
+			logrus.Infof("Skipping catalog %s generated from bundles", ctlg.Catalog)
+			continue
+		case ctlg.IsFBCOCI():
+			logrus.Infof("Skipping OCI catalog %s, its filtering cannot be simulated", ctlg.Catalog)
+			continue
+		}
+		sels, err := selectCatalog(ctx, reg, filepath.Join(tmpDir, fmt.Sprintf("catalog-%d", i)), ctlg)
+		if err != nil {
+			return fmt.Errorf("catalog %s: %v", ctlg.Catalog, err)
+		}
+		if err := writeSelections(o.IOStreams.Out, ctlg.Catalog, sels); err != nil {
+			return err
+		}
+		for _, sel := range sels {
+			if len(sel.bundles) == 0 {
+				empty = append(empty, fmt.Sprintf("%s (%s)", sel.pkg, ctlg.Catalog))
+			}
+		}
+	}
+	if len(empty) != 0 {
+		return fmt.Errorf("packages selecting no bundles: %s", strings.Join(empty, ", "))
+	}
+	return nil
+}
+
+// selectCatalog returns the bundles each package of the include
+// configuration of ctlg selects, filtered as a mirror of the catalog
+// does, or the bundles of every package if it includes none. The
+// catalog is rendered once into dir, then each package is filtered
+// on its own, so a package selecting nothing does not hide the others.
+// The dependencies of the bundles are not listed.
+func selectCatalog(ctx context.Context, reg *containerdregistry.Registry, dir string, ctlg v1alpha2.Operator) ([]packageSelection, error) {
+	ref := ctlg.Catalog
+	if ctlg.IsFBCDir() {
+		ref = v1alpha2.TrimProtocol(ctlg.Catalog)
+	}
+	dc, err := action.Render{
+		Registry: reg,
+		Refs:     []string{ref},
+	}.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	indexFile, err := os.Create(filepath.Join(dir, "index.json"))
+	if err != nil {
+		return nil, err
+	}
+	if err := declcfg.WriteJSON(*dc, indexFile); err != nil {
+		indexFile.Close()
+		return nil, err
+	}
+	if err := indexFile.Close(); err != nil {
+		return nil, err
+	}
+
+	catLogger := logrus.WithField("catalog", ctlg.Catalog)
+	if len(ctlg.IncludeConfig.Packages) == 0 {
+		if !ctlg.IsHeadsOnly() {
+			return catalogSelections(*dc, "all bundles")
+		}
+		heads, err := diff.Diff{
+			Registry:         reg,
+			NewRefs:          []string{dir},
+			Logger:           catLogger,
+			SkipDependencies: true,
+			HeadsOnly:        true,
+		}.Run(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return catalogSelections(*heads, "channel heads")
+	}
+
+	sels := make([]packageSelection, 0, len(ctlg.IncludeConfig.Packages))
+	for _, pkg := range ctlg.IncludeConfig.Packages {
+		sel := packageSelection{pkg: pkg.Name, selection: describeSelection(ctlg, pkg)}
+		ic := v1alpha2.IncludeConfig{Packages: []v1alpha2.IncludePackage{pkg}}
+		sel.bundles, sel.err = selectPackage(ctx, reg, dir, ctlg, ic, catLogger.WithField("package", pkg.Name))
+		sels = append(sels, sel)
+	}
+	return sels, nil
+}
+
+// selectPackage returns the bundles of the single package of ic
+// selected from the catalog rendered in dir.
+func selectPackage(ctx context.Context, reg *containerdregistry.Registry, dir string, ctlg v1alpha2.Operator, ic v1alpha2.IncludeConfig, logger *logrus.Entry) ([]selectedBundle, error) {
+	dic, err := ic.ConvertToDiffIncludeConfig()
+	if err != nil {
+		return nil, err
+	}
+	dc, err := diff.Diff{
+		Registry:         reg,
+		NewRefs:          []string{dir},
+		Logger:           logger,
+		IncludeConfig:    dic,
+		SkipDependencies: true,
+		HeadsOnly:        ctlg.IsHeadsOnly(),
+	}.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := operator.SelectBundles(dc, ic); err != nil {
+		return nil, err
+	}
+	bundles, err := packageBundles(*dc)
+	if err != nil {
+		return nil, err
+	}
+	return bundles[ic.Packages[0].Name], nil
+}
+
+// catalogSelections returns the bundles of every package of dc,
+// each selected by selection, sorted by package.
+func catalogSelections(dc declcfg.DeclarativeConfig, selection string) ([]packageSelection, error) {
+	bundles, err := packageBundles(dc)
+	if err != nil {
+		return nil, err
+	}
+	sels := make([]packageSelection, 0, len(dc.Packages))
+	for _, pkg := range dc.Packages {
+		sels = append(sels, packageSelection{pkg: pkg.Name, selection: selection, bundles: bundles[pkg.Name]})
+	}
+	sort.Slice(sels, func(i, j int) bool {
+		return sels[i].pkg < sels[j].pkg
+	})
+	return sels, nil
+}
+
+// packageBundles returns the bundles of the channels of dc keyed by
+// package, sorted by channel then version.
+func packageBundles(dc declcfg.DeclarativeConfig) (map[string][]selectedBundle, error) {
+	m, err := declcfg.ConvertToModel(dc)
+	if err != nil {
+		return nil, err
+	}
+	bundles := map[string][]selectedBundle{}
+	for pkgName, pkg := range m {
+		for chName, ch := range pkg.Channels {
+			for _, b := range ch.Bundles {
+				bundles[pkgName] = append(bundles[pkgName], selectedBundle{channel: chName, name: b.Name, version: b.Version})
+			}
+		}
+		pkgBundles := bundles[pkgName]
+		sort.Slice(pkgBundles, func(i, j int) bool {
+			if pkgBundles[i].channel != pkgBundles[j].channel {
+				return pkgBundles[i].channel < pkgBundles[j].channel
+			}
+			return pkgBundles[i].version.LT(pkgBundles[j].version)
+		})
+	}
+	return bundles, nil
+}
+
+// describeSelection describes how the bundles of pkg are selected from ctlg.
+func describeSelection(ctlg v1alpha2.Operator, pkg v1alpha2.IncludePackage) string {
+	if len(pkg.SelectedBundles) != 0 {
+		names := make([]string, 0, len(pkg.SelectedBundles))
+		for _, b := range pkg.SelectedBundles {
+			names = append(names, b.Name)
+		}
+		return "selected bundles " + strings.Join(names, ",")
+	}
+	if r := describeBundleRange(pkg.IncludeBundle); r != "" {
+		return "versions " + r
+	}
+	if len(pkg.Channels) != 0 {
+		channels := make([]string, 0, len(pkg.Channels))
+		for _, ch := range pkg.Channels {
+			if r := describeBundleRange(ch.IncludeBundle); r != "" {
+				channels = append(channels, fmt.Sprintf("%s (%s)", ch.Name, r))
+				continue
+			}
+			channels = append(channels, ch.Name)
+		}
+		return "channels " + strings.Join(channels, ",")
+	}
+	if ctlg.IsHeadsOnly() {
+		return "channel heads"
+	}
+	return "all bundles"
+}
+
+// describeBundleRange describes the versions selected by b,
+// or returns an empty string if it selects none.
+func describeBundleRange(b v1alpha2.IncludeBundle) string {
+	switch {
+	case b.MinVersion != "" && b.MaxVersion != "":
+		return fmt.Sprintf(">=%s <=%s", b.MinVersion, b.MaxVersion)
+	case b.MinVersion != "":
+		return ">=" + b.MinVersion
+	case b.MaxVersion != "":
+		return "<=" + b.MaxVersion
+	case b.MinBundle != "":
+		return "from " + b.MinBundle
+	}
+	return ""
+}
+
+// writeSelections prints the bundles each package selects
+// from catalog, and warns of the packages selecting none.
+func writeSelections(w io.Writer, catalog string, sels []packageSelection) error {
+	if _, err := fmt.Fprintf(w, "Catalog: %s\n", catalog); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "PACKAGE\tSELECTION\tCHANNEL\tBUNDLE\tVERSION"); err != nil {
+		return err
+	}
+	for _, sel := range sels {
+		if len(sel.bundles) == 0 {
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\n", sel.pkg, sel.selection); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, b := range sel.bundles {
+			if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", sel.pkg, sel.selection, b.channel, b.name, b.version); err != nil {
+				return err
+			}
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, sel := range sels {
+		if len(sel.bundles) != 0 {
+			continue
+		}
+		msg := fmt.Sprintf("WARNING: package %s selects no bundles", sel.pkg)
+		if sel.err != nil {
+			msg = fmt.Sprintf("%s: %v", msg, sel.err)
+		}
+		if _, err := fmt.Fprintln(w, msg); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "")
+	return err
+}
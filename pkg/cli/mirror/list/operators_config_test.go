@@ -0,0 +1,139 @@
+package list
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestSelectCatalog(t *testing.T) {
+	tmpDir := t.TempDir()
+	reg, err := containerdregistry.NewRegistry(containerdregistry.WithCacheDir(filepath.Join(tmpDir, "cache")))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, reg.Destroy()) })
+
+	catalogPath, err := filepath.Abs(filepath.Join("testdata", "catalog"))
+	require.NoError(t, err)
+
+	type bundle struct {
+		pkg, channel, name, version string
+	}
+	type spec struct {
+		name     string
+		ctlg     v1alpha2.Operator
+		expSels  map[string]string
+		expBndls []bundle
+		expEmpty map[string]string
+	}
+	cases := []spec{
+		{
+			name: "Valid/HeadsOnlyCatalog",
+			ctlg: v1alpha2.Operator{},
+			expSels: map[string]string{
+				"bar": "channel heads",
+				"baz": "channel heads",
+				"foo": "channel heads",
+			},
+			expBndls: []bundle{
+				{"bar", "alpha", "bar.v1.0.0", "1.0.0"},
+				{"bar", "stable", "bar.v1.0.0", "1.0.0"},
+				{"baz", "stable", "baz.v1.1.0", "1.1.0"},
+				{"foo", "beta", "foo.v0.3.1", "0.3.1"},
+			},
+		},
+		{
+			name: "Valid/Packages",
+			ctlg: v1alpha2.Operator{
+				IncludeConfig: v1alpha2.IncludeConfig{
+					Packages: []v1alpha2.IncludePackage{
+						{Name: "foo", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.2.0", MaxVersion: "0.3.0"}},
+						{Name: "bar", Channels: []v1alpha2.IncludeChannel{{Name: "stable"}}},
+						{Name: "baz", SelectedBundles: []v1alpha2.SelectedBundle{{Name: "baz.v1.0.0"}}},
+					},
+				},
+			},
+			expSels: map[string]string{
+				"foo": "versions >=0.2.0 <=0.3.0",
+				"bar": "channels stable",
+				"baz": "selected bundles baz.v1.0.0",
+			},
+			expBndls: []bundle{
+				{"foo", "beta", "foo.v0.2.0", "0.2.0"},
+				{"foo", "beta", "foo.v0.3.0", "0.3.0"},
+				{"bar", "stable", "bar.v1.0.0", "1.0.0"},
+				{"baz", "stable", "baz.v1.0.0", "1.0.0"},
+			},
+		},
+		{
+			name: "Valid/NoBundles",
+			ctlg: v1alpha2.Operator{
+				IncludeConfig: v1alpha2.IncludeConfig{
+					Packages: []v1alpha2.IncludePackage{
+						{Name: "foo", Channels: []v1alpha2.IncludeChannel{{Name: "beta", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.3.1"}}}},
+						{Name: "qux"},
+					},
+				},
+			},
+			expSels: map[string]string{
+				"foo": "channels beta (>=0.3.1)",
+				"qux": "channel heads",
+			},
+			expBndls: []bundle{
+				{"foo", "beta", "foo.v0.3.1", "0.3.1"},
+			},
+			expEmpty: map[string]string{
+				"qux": "package does not exist in new model",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.ctlg.Catalog = v1alpha2.DirTransportPrefix + catalogPath
+			sels, err := selectCatalog(context.Background(), reg, filepath.Join(tmpDir, c.name, "catalog"), c.ctlg)
+			require.NoError(t, err)
+			require.Len(t, sels, len(c.expSels))
+			var bndls []bundle
+			for _, sel := range sels {
+				require.Equal(t, c.expSels[sel.pkg], sel.selection, "selection of package %s", sel.pkg)
+				for _, b := range sel.bundles {
+					bndls = append(bndls, bundle{sel.pkg, b.channel, b.name, b.version.String()})
+				}
+				if msg, ok := c.expEmpty[sel.pkg]; ok {
+					require.Empty(t, sel.bundles)
+					require.ErrorContains(t, sel.err, msg)
+					continue
+				}
+				require.NoError(t, sel.err, "package %s", sel.pkg)
+			}
+			require.Equal(t, c.expBndls, bndls)
+		})
+	}
+}
+
+func TestWriteSelections(t *testing.T) {
+	var out bytes.Buffer
+	sels := []packageSelection{
+		{
+			pkg:       "foo",
+			selection: "channel heads",
+			bundles:   []selectedBundle{{channel: "beta", name: "foo.v0.3.1", version: semver.MustParse("0.3.1")}},
+		},
+		{pkg: "qux", selection: "channel heads", err: errors.New("package does not exist")},
+	}
+	require.NoError(t, writeSelections(&out, "registry.example.com/catalog:v1", sels))
+	require.Equal(t, `Catalog: registry.example.com/catalog:v1
+PACKAGE  SELECTION      CHANNEL  BUNDLE      VERSION
+foo      channel heads  beta     foo.v0.3.1  0.3.1
+qux      channel heads  -        -           -
+WARNING: package qux selects no bundles: package does not exist
+
+`, out.String())
+}
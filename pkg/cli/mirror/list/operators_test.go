@@ -110,6 +110,21 @@ func TestOperatorsValidate(t *testing.T) {
 			},
 			expError: "",
 		},
+		{
+			name: "Valid/Config",
+			opts: &OperatorsOptions{
+				ConfigPath: "imageset-config.yaml",
+			},
+			expError: "",
+		},
+		{
+			name: "Invalid/ConfigWithCatalog",
+			opts: &OperatorsOptions{
+				ConfigPath: "imageset-config.yaml",
+				Catalog:    "foo-catalog",
+			},
+			expError: "--config cannot be used with --catalogs, --version, --catalog, --package or --channel",
+		},
 	}
 
 	for _, c := range cases {
@@ -0,0 +1,203 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/uuid"
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/oc/pkg/cli/admin/release"
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cincinnati"
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+type ReleaseContentsOptions struct {
+	*cli.RootOptions
+	Image    string
+	Version  string
+	Channel  string
+	Arch     string
+	Filter   []string
+	Insecure bool
+}
+
+// releaseComponent is a component image of a release payload
+type releaseComponent struct {
+	Name       string
+	Repository string
+	Digest     string
+}
+
+func NewReleaseContentsCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := ReleaseContentsOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "release-contents [RELEASE-IMAGE]",
+		Short: "List the component images of a release",
+		Example: templates.Examples(`
+			# List the component images of an OpenShift release version
+			oc-mirror list release-contents --version=4.14.3
+
+			# List the component images of a release image
+			oc-mirror list release-contents quay.io/openshift-release-dev/ocp-release:4.14.3-x86_64
+
+			# List the component images matching one or more patterns
+			oc-mirror list release-contents --version=4.14.3 --filter='*-operator' --filter='ovn-*'
+		`),
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run(cmd.Context()))
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&o.Version, "version", o.Version, "Specify an OpenShift release version (x.y.z)")
+	fs.StringVar(&o.Channel, "channel", o.Channel, "The channel the release version is looked up in, defaults to the stable channel of the version")
+	fs.StringVar(&o.Arch, "arch", v1alpha2.DefaultPlatformArchitecture, "The architecture of the release version. Valid architectures: amd64, arm64, ppc64le, s390x, multi")
+	fs.StringArrayVar(&o.Filter, "filter", o.Filter, "Only list the components whose name matches the pattern (e.g. '*-operator'), can be repeated")
+	fs.BoolVar(&o.Insecure, "insecure", o.Insecure, "Allow pulling the release image over HTTP or from a registry with an invalid certificate")
+
+	o.BindFlags(cmd.PersistentFlags())
+
+	return cmd
+}
+
+func (o *ReleaseContentsOptions) Complete(args []string) error {
+	if len(args) == 1 {
+		o.Image = args[0]
+	}
+	if len(o.Version) > 0 && len(o.Channel) == 0 {
+		r := releaseVersion{}
+		if err := r.parseTag(o.Version); err != nil {
+			return err
+		}
+		o.Channel = fmt.Sprintf("stable-%s", r.String())
+	}
+	return nil
+}
+
+func (o *ReleaseContentsOptions) Validate() error {
+	switch {
+	case len(o.Image) > 0 && len(o.Version) > 0:
+		return errors.New("cannot specify both a release image and --version")
+	case len(o.Image) == 0 && len(o.Version) == 0:
+		return errors.New("must specify a release image or --version")
+	}
+	if len(o.Version) > 0 {
+		if _, err := semver.Parse(o.Version); err != nil {
+			return fmt.Errorf("invalid --version %q, expected x.y.z: %v", o.Version, err)
+		}
+	}
+	for _, pattern := range o.Filter {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid --filter %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (o *ReleaseContentsOptions) Run(ctx context.Context) error {
+	if len(o.Version) > 0 {
+		client, err := cincinnati.NewOCPClient(uuid.New())
+		if err != nil {
+			return err
+		}
+		o.Image, err = resolveReleaseImage(ctx, client, o.Channel, o.Arch, o.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	info := release.NewInfoOptions(o.IOStreams)
+	info.SecurityOptions.Insecure = o.Insecure
+	ri, err := info.LoadReleaseInfo(o.Image, false)
+	if err != nil {
+		return fmt.Errorf("error reading release %s: %v", o.Image, err)
+	}
+	if ri.References == nil {
+		return fmt.Errorf("release %s has no image references", o.Image)
+	}
+
+	components := releaseComponents(ri.References, o.Filter)
+	if len(components) == 0 && len(o.Filter) > 0 {
+		return fmt.Errorf("no component of release %s matches %s", o.Image, strings.Join(o.Filter, ", "))
+	}
+	return writeReleaseComponents(o.IOStreams.Out, components)
+}
+
+// resolveReleaseImage returns the release image of version in channel, as published in the update graph
+func resolveReleaseImage(ctx context.Context, client cincinnati.Client, channel, arch, version string) (string, error) {
+	vers, err := semver.ParseRange("=" + version)
+	if err != nil {
+		return "", err
+	}
+	updates, err := cincinnati.GetUpdatesInRange(ctx, client, channel, arch, vers)
+	if err != nil {
+		return "", err
+	}
+	if len(updates) == 0 {
+		return "", fmt.Errorf("release %s not found in channel %s for architecture %s", version, channel, arch)
+	}
+	return updates[0].Image, nil
+}
+
+// releaseComponents returns the components of the image-references of a release,
+// sorted by name and limited to those matching one of filters if any
+func releaseComponents(references *imagev1.ImageStream, filters []string) []releaseComponent {
+	var components []releaseComponent
+	for _, tag := range references.Spec.Tags {
+		if tag.From == nil || !matchesAny(tag.Name, filters) {
+			continue
+		}
+		component := releaseComponent{Name: tag.Name, Repository: tag.From.Name}
+		if repo, dgst, ok := strings.Cut(tag.From.Name, "@"); ok {
+			component.Repository, component.Digest = repo, dgst
+		}
+		components = append(components, component)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Name < components[j].Name
+	})
+	return components
+}
+
+func matchesAny(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		// patterns are validated beforehand
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func writeReleaseComponents(w io.Writer, components []releaseComponent) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "NAME\tREPOSITORY\tDIGEST"); err != nil {
+		return err
+	}
+	for _, c := range components {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Name, c.Repository, c.Digest); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
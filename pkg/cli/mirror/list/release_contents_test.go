@@ -0,0 +1,133 @@
+package list
+
+import (
+	"bytes"
+	"testing"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+func TestReleaseContentsValidate(t *testing.T) {
+	type spec struct {
+		name     string
+		args     []string
+		opts     *ReleaseContentsOptions
+		expOpts  *ReleaseContentsOptions
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name: "Valid/Version",
+			opts: &ReleaseContentsOptions{
+				Version:     "4.14.3",
+				RootOptions: &cli.RootOptions{},
+			},
+			expOpts: &ReleaseContentsOptions{
+				Version:     "4.14.3",
+				Channel:     "stable-4.14",
+				RootOptions: &cli.RootOptions{},
+			},
+		},
+		{
+			name: "Valid/Image",
+			args: []string{"quay.io/openshift-release-dev/ocp-release:4.14.3-x86_64"},
+			opts: &ReleaseContentsOptions{
+				Filter:      []string{"*-operator"},
+				RootOptions: &cli.RootOptions{},
+			},
+			expOpts: &ReleaseContentsOptions{
+				Image:       "quay.io/openshift-release-dev/ocp-release:4.14.3-x86_64",
+				Filter:      []string{"*-operator"},
+				RootOptions: &cli.RootOptions{},
+			},
+		},
+		{
+			name: "Invalid/NoRelease",
+			opts: &ReleaseContentsOptions{
+				RootOptions: &cli.RootOptions{},
+			},
+			expError: "must specify a release image or --version",
+		},
+		{
+			name: "Invalid/ImageAndVersion",
+			args: []string{"quay.io/openshift-release-dev/ocp-release:4.14.3-x86_64"},
+			opts: &ReleaseContentsOptions{
+				Version:     "4.14.3",
+				RootOptions: &cli.RootOptions{},
+			},
+			expError: "cannot specify both a release image and --version",
+		},
+		{
+			name: "Invalid/MinorVersion",
+			opts: &ReleaseContentsOptions{
+				Version:     "4.14",
+				RootOptions: &cli.RootOptions{},
+			},
+			expError: `invalid --version "4.14", expected x.y.z: No Major.Minor.Patch elements found`,
+		},
+		{
+			name: "Invalid/Filter",
+			opts: &ReleaseContentsOptions{
+				Version:     "4.14.3",
+				Filter:      []string{"[a-"},
+				RootOptions: &cli.RootOptions{},
+			},
+			expError: `invalid --filter "[a-": syntax error in pattern`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.NoError(t, c.opts.Complete(c.args))
+			err := c.opts.Validate()
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, c.expOpts, c.opts)
+			}
+		})
+	}
+}
+
+func TestReleaseComponents(t *testing.T) {
+	references := &imagev1.ImageStream{
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{
+					Name: "ovn-kubernetes",
+					From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:bbbb"},
+				},
+				{
+					Name: "cluster-version-operator",
+					From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:aaaa"},
+				},
+				{
+					Name: "no-source",
+				},
+			},
+		},
+	}
+
+	t.Run("Valid/NoFilter", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, writeReleaseComponents(&out, releaseComponents(references, nil)))
+		exp := "NAME                      REPOSITORY                                      DIGEST\n" +
+			"cluster-version-operator  quay.io/openshift-release-dev/ocp-v4.0-art-dev  sha256:aaaa\n" +
+			"ovn-kubernetes            quay.io/openshift-release-dev/ocp-v4.0-art-dev  sha256:bbbb\n"
+		require.Equal(t, exp, out.String())
+	})
+	t.Run("Valid/Filter", func(t *testing.T) {
+		require.Equal(t, []releaseComponent{
+			{Name: "ovn-kubernetes", Repository: "quay.io/openshift-release-dev/ocp-v4.0-art-dev", Digest: "sha256:bbbb"},
+		}, releaseComponents(references, []string{"ovn-*", "machine-*"}))
+	})
+	t.Run("Valid/NoMatch", func(t *testing.T) {
+		require.Empty(t, releaseComponents(references, []string{"console"}))
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"text/tabwriter"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/google/uuid"
@@ -23,12 +25,15 @@ import (
 	"github.com/openshift/oc-mirror/pkg/cli"
 	"github.com/openshift/oc-mirror/pkg/config"
 	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+	"github.com/openshift/oc-mirror/pkg/network"
 	"github.com/openshift/oc-mirror/pkg/operator/diff"
 )
 
 type UpdatesOptions struct {
 	*cli.RootOptions
 	ConfigPath string
+	Profile    string   // Name of a profile merged onto the base configuration
+	Overlays   []string // Paths to imageset configurations merged onto the base configuration before the profile
 }
 
 func NewUpdatesCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
@@ -54,6 +59,10 @@ func NewUpdatesCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
 		},
 	}
 
+	fs := cmd.Flags()
+	fs.StringVar(&o.Profile, "profile", o.Profile, "Name of a profile in the imageset configuration to merge onto the base configuration")
+	fs.StringArrayVar(&o.Overlays, "overlay", o.Overlays, "Path to an imageset configuration to merge onto the base configuration before the profile. "+
+		"Can be repeated, each overlay taking precedence over the previous ones")
 	o.BindFlags(cmd.PersistentFlags())
 
 	return cmd
@@ -74,10 +83,13 @@ func (o *UpdatesOptions) Validate() error {
 }
 
 func (o *UpdatesOptions) Run(ctx context.Context) error {
-	cfg, err := config.ReadConfig(o.ConfigPath)
+	cfg, err := config.ReadConfigWithOverlays(o.ConfigPath, o.Overlays, o.Profile)
 	if err != nil {
 		return err
 	}
+	if err := network.Configure(cfg.Proxy); err != nil {
+		return err
+	}
 
 	path := filepath.Join(o.Dir, config.SourceDir)
 	backend, err := storage.ByConfig(path, cfg.StorageConfig)
@@ -92,6 +104,9 @@ func (o *UpdatesOptions) Run(ctx context.Context) error {
 	case err != nil && errors.Is(err, storage.ErrMetadataNotExist):
 		return fmt.Errorf("no metadata detected")
 	default:
+		if err := o.writeLastMirror(meta.PastMirror); err != nil {
+			return err
+		}
 		for _, arch := range cfg.Mirror.Platform.Architectures {
 			if len(cfg.Mirror.Platform.Channels) != 0 {
 				if err := o.releaseUpdates(ctx, arch, cfg, meta.PastMirror); err != nil {
@@ -180,6 +195,7 @@ func (o UpdatesOptions) operatorUpdates(ctx context.Context, cfg v1alpha2.ImageS
 
 	reg, err := containerdregistry.NewRegistry(
 		containerdregistry.SkipTLSVerify(false),
+		containerdregistry.WithRootCAs(network.RootCAs()),
 		containerdregistry.WithCacheDir(filepath.Join(dstDir, "cache")),
 	)
 	defer reg.Destroy()
@@ -187,6 +203,11 @@ func (o UpdatesOptions) operatorUpdates(ctx context.Context, cfg v1alpha2.ImageS
 		return err
 	}
 	for _, ctlg := range cfg.Mirror.Operators {
+		if ctlg.IsBundleList() {
+			// Catalogs generated from bundles have no upstream catalog to compare to.
+			logrus.Infof("Skipping catalog %s generated from bundles", ctlg.Catalog)
+			continue
+		}
 		catLogger := logrus.WithField("catalog", ctlg.Catalog)
 		dic, err := ctlg.IncludeConfig.ConvertToDiffIncludeConfig()
 		if err != nil {
@@ -213,6 +234,27 @@ func (o UpdatesOptions) operatorUpdates(ctx context.Context, cfg v1alpha2.ImageS
 	return nil
 }
 
+func (o UpdatesOptions) writeLastMirror(last v1alpha2.PastMirror) error {
+	tw := tabwriter.NewWriter(o.IOStreams.Out, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintf(tw, "Last mirror sequence:\t%d\n", last.Sequence); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(tw, "Mirrored at:\t%s\n", time.Unix(int64(last.Timestamp), 0).UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(last.Annotations))
+	for k := range last.Annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(tw, "Annotation:\t%s=%s\n", k, last.Annotations[k]); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
 func (o UpdatesOptions) writeReleaseColumns(upgrades []semver.Version, arch, channel string) error {
 	if len(upgrades) == 0 {
 		if _, err := fmt.Fprintf(os.Stdout, "No updates found for release channel %s\n", channel); err != nil {
@@ -1,9 +1,14 @@
 package list
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
 )
 
 func TestUpdatesComplete(t *testing.T) {
@@ -73,3 +78,26 @@ func TestUpdatesValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdatesWriteLastMirror(t *testing.T) {
+	out := &bytes.Buffer{}
+	opts := UpdatesOptions{
+		RootOptions: &cli.RootOptions{
+			IOStreams: genericclioptions.IOStreams{Out: out},
+		},
+	}
+	last := v1alpha2.PastMirror{
+		Sequence:  3,
+		Timestamp: 1700000000,
+		Annotations: map[string]string{
+			"ticket": "OPS-1234",
+			"site":   "dc1",
+		},
+	}
+	require.NoError(t, opts.writeLastMirror(last))
+	exp := "Last mirror sequence:  3\n" +
+		"Mirrored at:           2023-11-14T22:13:20Z\n" +
+		"Annotation:            site=dc1\n" +
+		"Annotation:            ticket=OPS-1234\n"
+	require.Equal(t, exp, out.String())
+}
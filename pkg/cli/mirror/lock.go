@@ -0,0 +1,108 @@
+package mirror
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// lockFileName is the name of the lock file of the workspace.
+const lockFileName = ".oc-mirror.lock"
+
+// errLockHeld is returned by lockFileExclusive when another
+// process holds the lock.
+var errLockHeld = errors.New("lock held by another process")
+
+// runLock is the set of lock files held by a run with --lock.
+type runLock struct {
+	files []*os.File
+}
+
+// acquireLocks locks the workspace and, when mirroring to registries,
+// every destination, so that a concurrent run against the same workspace
+// or destination fails fast instead of corrupting the metadata.
+// The locks are released by the kernel if the process dies.
+func (o *MirrorOptions) acquireLocks() (*runLock, error) {
+	lock := &runLock{}
+	if err := os.MkdirAll(o.Dir, 0750); err != nil {
+		return nil, err
+	}
+	targets := map[string]string{filepath.Join(o.Dir, lockFileName): "workspace " + o.Dir}
+	if o.ToMirror != "" {
+		for _, d := range o.mirrorDestinations() {
+			targets[destinationLockPath(d)] = "destination " + d.String()
+		}
+	}
+	// Locks are always taken in the same order.
+	paths := make([]string, 0, len(targets))
+	for p := range targets {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		f, err := lockFile(p, targets[p])
+		if err != nil {
+			lock.release()
+			return nil, err
+		}
+		lock.files = append(lock.files, f)
+	}
+	return lock, nil
+}
+
+// destinationLockPath returns the path of the lock file of a destination,
+// shared by the runs of every workspace of the host. It is only shared by the
+// processes seeing the same temporary directory: runs on other hosts, in other
+// containers, or in services with a private /tmp (systemd PrivateTmp) don't
+// see the lock of the destination.
+func destinationLockPath(d mirrorDestination) string {
+	sum := sha256.Sum256([]byte(d.String()))
+	return filepath.Join(os.TempDir(), fmt.Sprintf("oc-mirror-%x.lock", sum[:8]))
+}
+
+// lockFile takes an exclusive lock on the file at path without waiting,
+// and records the process holding it in the file for the runs failing to lock it.
+func lockFile(path, what string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file of %s: %v", what, err)
+	}
+	if err := lockFileExclusive(f); err != nil {
+		holder, _ := io.ReadAll(io.LimitReader(f, 1024))
+		f.Close()
+		if errors.Is(err, errLockHeld) {
+			return nil, NewClassifiedError(ErrorClassLocked, fmt.Errorf("%s is locked by another oc-mirror run (%s), wait for it to complete",
+				what, strings.TrimSpace(string(holder))))
+		}
+		return nil, fmt.Errorf("error locking %s: %v", what, err)
+	}
+	hostname, _ := os.Hostname()
+	holder := fmt.Sprintf("pid %d on %s since %s: %s", os.Getpid(), hostname, time.Now().UTC().Format(time.RFC3339), strings.Join(os.Args, " "))
+	if err := f.Truncate(0); err == nil {
+		if _, err := f.WriteAt([]byte(holder+"\n"), 0); err != nil {
+			klog.V(1).Infof("error recording the holder of the lock of %s: %v", what, err)
+		}
+	}
+	klog.V(1).Infof("Locked %s", what)
+	return f, nil
+}
+
+// release unlocks the lock files. The files are kept: removing them
+// would let a run lock a new file while another one holds the old one.
+func (l *runLock) release() {
+	for _, f := range l.files {
+		if err := unlockFile(f); err != nil {
+			klog.Warningf("error unlocking %s: %v", f.Name(), err)
+		}
+		f.Close()
+	}
+	l.files = nil
+}
@@ -0,0 +1,42 @@
+package mirror
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+func TestAcquireLocks(t *testing.T) {
+	dest := fmt.Sprintf("localhost:%d", os.Getpid())
+	o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}, ToMirror: dest, UserNamespace: "lock-test"}
+	lock, err := o.acquireLocks()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(destinationLockPath(mirrorDestination{Registry: dest, Namespace: "lock-test"})) })
+
+	t.Run("Invalid/WorkspaceLocked", func(t *testing.T) {
+		other := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: o.Dir}}
+		_, err := other.acquireLocks()
+		require.ErrorContains(t, err, "workspace "+o.Dir+" is locked by another oc-mirror run (pid ")
+		require.Equal(t, ErrorClassLocked, ClassifyError(err))
+	})
+
+	t.Run("Invalid/DestinationLocked", func(t *testing.T) {
+		other := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}, ToMirror: dest, UserNamespace: "lock-test"}
+		_, err := other.acquireLocks()
+		require.ErrorContains(t, err, "destination "+dest+"/lock-test is locked by another oc-mirror run")
+		// The workspace locked before the failure is released.
+		other.ToMirror = ""
+		again, err := other.acquireLocks()
+		require.NoError(t, err)
+		again.release()
+	})
+
+	lock.release()
+	lock, err = o.acquireLocks()
+	require.NoError(t, err)
+	lock.release()
+}
@@ -31,6 +31,21 @@ const (
 	namespaceICSPScope  = "namespace"
 	icspKind            = "ImageContentSourcePolicy"
 	updateServiceKind   = "UpdateService"
+
+	// icspScopeLabel is set on every generated ICSP to the
+	// content it mirrors: release, generic or operator.
+	icspScopeLabel = "oc-mirror.openshift.io/scope"
+	// icspCatalogLabel is set on the operator ICSPs generated for a
+	// catalog to the name of the CatalogSource of that catalog.
+	icspCatalogLabel = "oc-mirror.openshift.io/catalog"
+
+	// manifestOutputSingleFile writes every generated cluster
+	// resource to the clusterResourcesFile multi-document file.
+	manifestOutputSingleFile = "single-file"
+	// manifestOutputPerResource writes each generated cluster
+	// resource to its own <kind>-<name>.yaml file.
+	manifestOutputPerResource = "per-resource"
+	clusterResourcesFile      = "clusterResources.yaml"
 )
 
 var icspTypeMeta = metav1.TypeMeta{
@@ -53,7 +68,8 @@ func (b *ReleaseBuilder) New(icspName string, icspCount int) operatorv1alpha1.Im
 	return operatorv1alpha1.ImageContentSourcePolicy{
 		TypeMeta: icspTypeMeta,
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name:   name,
+			Labels: map[string]string{icspScopeLabel: "release"},
 		},
 		Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 			RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{},
@@ -70,15 +86,26 @@ func (b *ReleaseBuilder) GetMapping(_ string, mapping image.TypedImageMapping) (
 
 var _ ICSPBuilder = &OperatorBuilder{}
 
-type OperatorBuilder struct{}
+// OperatorBuilder generates the ICSPs of operator images. When Catalog is set,
+// the ICSPs are labeled with the name of the CatalogSource of the catalog.
+type OperatorBuilder struct {
+	Catalog string
+}
 
 func (b *OperatorBuilder) New(icspName string, icspCount int) operatorv1alpha1.ImageContentSourcePolicy {
 	name := strings.Join(strings.Split(icspName, "/"), "-") + "-" + strconv.Itoa(icspCount)
+	labels := map[string]string{
+		"operators.openshift.org/catalog": "true",
+		icspScopeLabel:                    "operator",
+	}
+	if b.Catalog != "" {
+		labels[icspCatalogLabel] = b.Catalog
+	}
 	return operatorv1alpha1.ImageContentSourcePolicy{
 		TypeMeta: icspTypeMeta,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   name,
-			Labels: map[string]string{"operators.openshift.org/catalog": "true"},
+			Labels: labels,
 		},
 		Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 			RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{},
@@ -99,7 +126,8 @@ func (b *GenericBuilder) New(icspName string, icspCount int) operatorv1alpha1.Im
 	return operatorv1alpha1.ImageContentSourcePolicy{
 		TypeMeta: icspTypeMeta,
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name:   name,
+			Labels: map[string]string{icspScopeLabel: "generic"},
 		},
 		Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 			RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{},
@@ -264,30 +292,45 @@ func WriteICSPs(dir string, icsps []operatorv1alpha1.ImageContentSourcePolicy) e
 
 	klog.Infof("Writing ICSP manifests to %s", dir)
 
+	resources, err := icspResources(icsps)
+	if err != nil {
+		return err
+	}
+	icspBytes := make([][]byte, len(resources))
+	for i, resource := range resources {
+		icspBytes[i] = resource.data
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "imageContentSourcePolicy.yaml"), aggregateICSPs(icspBytes), os.ModePerm); err != nil {
+		return fmt.Errorf("error writing ImageContentSourcePolicy: %v", err)
+	}
+
+	return nil
+}
+
+// icspResources returns the manifests of the ImageContentSourcePolicy objects, sorted by name.
+func icspResources(icsps []operatorv1alpha1.ImageContentSourcePolicy) ([]clusterResource, error) {
 	// Stable ICSP generation.
 	sort.Slice(icsps, func(i, j int) bool {
 		return string(icsps[i].Name) < string(icsps[j].Name)
 	})
 
-	icspBytes := make([][]byte, len(icsps))
+	resources := make([]clusterResource, len(icsps))
 	for i := range icsps {
 		// Create an unstructured object for removing creationTimestamp
 		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&icsps[i])
 		if err != nil {
-			return fmt.Errorf("error converting to unstructured: %v", err)
+			return nil, fmt.Errorf("error converting to unstructured: %v", err)
 		}
 		delete(obj["metadata"].(map[string]interface{}), "creationTimestamp")
 
-		if icspBytes[i], err = yaml.Marshal(obj); err != nil {
-			return fmt.Errorf("unable to marshal ImageContentSourcePolicy yaml: %v", err)
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal ImageContentSourcePolicy yaml: %v", err)
 		}
+		resources[i] = clusterResource{kind: "imageContentSourcePolicy", name: icsps[i].Name, data: data}
 	}
-
-	if err := os.WriteFile(filepath.Join(dir, "imageContentSourcePolicy.yaml"), aggregateICSPs(icspBytes), os.ModePerm); err != nil {
-		return fmt.Errorf("error writing ImageContentSourcePolicy: %v", err)
-	}
-
-	return nil
+	return resources, nil
 }
 
 // WriteCatalogSource will generate a CatalogSource object and write it to disk
@@ -299,16 +342,41 @@ func WriteCatalogSource(mapping image.TypedImageMapping, dir string) error {
 
 	klog.Infof("Writing CatalogSource manifests to %s", dir)
 
+	resources, err := catalogSourceResources(mapping)
+	if err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		if err := os.WriteFile(filepath.Join(dir, resource.fileName()), resource.data, os.ModePerm); err != nil {
+			return fmt.Errorf("error writing CatalogSource: %v", err)
+		}
+	}
+	return nil
+}
+
+// catalogSourceResources returns a CatalogSource manifest for each catalog
+// of the mapping, in the order of the source catalog references.
+func catalogSourceResources(mapping image.TypedImageMapping) ([]clusterResource, error) {
+	sources := make([]image.TypedImage, 0, len(mapping))
+	for source := range mapping {
+		sources = append(sources, source)
+	}
+	// Sort the catalogs so duplicate names get the same suffix on every run.
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Ref.Exact() < sources[j].Ref.Exact()
+	})
+
 	// Keep track of the names and to make sure no
 	// manifest are overwritten.
 	// If found, increment the name suffix by one.
 	names := make(map[string]int, len(mapping))
-	for source, dest := range mapping {
+	resources := make([]clusterResource, 0, len(mapping))
+	for _, source := range sources {
 		name := source.Ref.Name
 		name, err := createRFC1035NameForCatalogSource(name)
 		// in theory this should never error
 		if err != nil {
-			return err
+			return nil, err
 		}
 		value, found := names[name]
 		if found {
@@ -319,15 +387,13 @@ func WriteCatalogSource(mapping image.TypedImageMapping, dir string) error {
 			names[name] = 0
 		}
 
-		catalogSource, err := generateCatalogSource(name, dest.Ref)
+		catalogSource, err := generateCatalogSource(name, mapping[source].Ref)
 		if err != nil {
-			return err
-		}
-		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("catalogSource-%s.yaml", name)), catalogSource, os.ModePerm); err != nil {
-			return fmt.Errorf("error writing CatalogSource: %v", err)
+			return nil, err
 		}
+		resources = append(resources, clusterResource{kind: "catalogSource", name: name, data: catalogSource})
 	}
-	return nil
+	return resources, nil
 }
 
 /*
@@ -433,12 +499,66 @@ func toRFC1035(r rune) rune {
 // WriteUpdateService will generate an UpdateService object and write it to disk
 func WriteUpdateService(release, graph image.TypedImage, dir string) error {
 	klog.Infof("Writing UpdateService manifests to %s", dir)
-	updateService, err := generateUpdateService("update-service-oc-mirror", release.Ref, graph.Ref)
+	resource, err := updateServiceResource(release, graph)
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(filepath.Join(dir, "updateService.yaml"), updateService, os.ModePerm); err != nil {
+	if err := os.WriteFile(filepath.Join(dir, "updateService.yaml"), resource.data, os.ModePerm); err != nil {
 		return fmt.Errorf("error writing UpdateService: %v", err)
 	}
 	return nil
 }
+
+// updateServiceResource returns the UpdateService manifest serving the graph data image.
+func updateServiceResource(release, graph image.TypedImage) (clusterResource, error) {
+	name := "update-service-oc-mirror"
+	updateService, err := generateUpdateService(name, release.Ref, graph.Ref)
+	if err != nil {
+		return clusterResource{}, err
+	}
+	return clusterResource{kind: "updateService", name: name, data: updateService}, nil
+}
+
+// clusterResource is the manifest of a generated cluster resource.
+type clusterResource struct {
+	// kind is the lower camel case kind of the resource.
+	kind string
+	name string
+	data []byte
+}
+
+// fileName returns the file name of the resource with per-resource manifest output.
+func (r clusterResource) fileName() string {
+	return fmt.Sprintf("%s-%s.yaml", r.kind, r.name)
+}
+
+// writeClusterResources writes the resources to dir in the layout of the manifest
+// output: a single multi-document clusterResources.yaml file, or one file per resource.
+func writeClusterResources(dir, output string, resources []clusterResource) error {
+	if len(resources) == 0 {
+		klog.V(2).Info("No cluster resources generated to write")
+		return nil
+	}
+	klog.Infof("Writing cluster resource manifests to %s", dir)
+
+	switch output {
+	case manifestOutputSingleFile:
+		var data []byte
+		for _, resource := range resources {
+			data = append(data, []byte("---\n")...)
+			data = append(data, resource.data...)
+		}
+		if err := os.WriteFile(filepath.Join(dir, clusterResourcesFile), data, os.ModePerm); err != nil {
+			return fmt.Errorf("error writing cluster resources: %v", err)
+		}
+	case manifestOutputPerResource:
+		for _, resource := range resources {
+			if err := os.WriteFile(filepath.Join(dir, resource.fileName()), resource.data, os.ModePerm); err != nil {
+				return fmt.Errorf("error writing %s %s: %v", resource.kind, resource.name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown manifest output %q", output)
+	}
+	return nil
+}
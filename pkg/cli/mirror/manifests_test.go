@@ -61,7 +61,7 @@ func TestICSPGeneration(t *testing.T) {
 				Kind:       "ImageContentSourcePolicy"},
 			ObjectMeta: metav1.ObjectMeta{
 				Name:   "test-0",
-				Labels: map[string]string{"operators.openshift.org/catalog": "true"},
+				Labels: map[string]string{"operators.openshift.org/catalog": "true", icspScopeLabel: "operator"},
 			},
 			Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 				RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{
@@ -108,7 +108,8 @@ func TestICSPGeneration(t *testing.T) {
 					APIVersion: operatorv1alpha1.GroupVersion.String(),
 					Kind:       "ImageContentSourcePolicy"},
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "test-0",
+					Name:   "test-0",
+					Labels: map[string]string{icspScopeLabel: "generic"},
 				},
 				Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 					RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{
@@ -154,7 +155,8 @@ func TestICSPGeneration(t *testing.T) {
 					APIVersion: operatorv1alpha1.GroupVersion.String(),
 					Kind:       "ImageContentSourcePolicy"},
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "test-0",
+					Name:   "test-0",
+					Labels: map[string]string{icspScopeLabel: "release"},
 				},
 				Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 					RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{
@@ -199,7 +201,8 @@ func TestICSPGeneration(t *testing.T) {
 					APIVersion: operatorv1alpha1.GroupVersion.String(),
 					Kind:       "ImageContentSourcePolicy"},
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "test-0",
+					Name:   "test-0",
+					Labels: map[string]string{icspScopeLabel: "generic"},
 				},
 				Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 					RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{
@@ -245,7 +248,8 @@ func TestICSPGeneration(t *testing.T) {
 					APIVersion: operatorv1alpha1.GroupVersion.String(),
 					Kind:       "ImageContentSourcePolicy"},
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "test-0",
+					Name:   "test-0",
+					Labels: map[string]string{icspScopeLabel: "generic"},
 				},
 				Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 					RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{
@@ -291,7 +295,8 @@ func TestICSPGeneration(t *testing.T) {
 					APIVersion: operatorv1alpha1.GroupVersion.String(),
 					Kind:       "ImageContentSourcePolicy"},
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "test-0",
+					Name:   "test-0",
+					Labels: map[string]string{icspScopeLabel: "generic"},
 				},
 				Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 					RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{
@@ -426,7 +431,7 @@ func TestICSPGeneration(t *testing.T) {
 					Kind:       "ImageContentSourcePolicy"},
 				ObjectMeta: metav1.ObjectMeta{
 					Name:   "test-0",
-					Labels: map[string]string{"operators.openshift.org/catalog": "true"},
+					Labels: map[string]string{"operators.openshift.org/catalog": "true", icspScopeLabel: "operator"},
 				},
 				Spec: operatorv1alpha1.ImageContentSourcePolicySpec{
 					RepositoryDigestMirrors: []operatorv1alpha1.RepositoryDigestMirrors{
@@ -648,6 +653,89 @@ func TestWriteCatalogSource(t *testing.T) {
 	}
 }
 
+func TestWriteClusterResources(t *testing.T) {
+	resources := []clusterResource{
+		{kind: "updateService", name: "update-service-oc-mirror", data: []byte("kind: UpdateService\n")},
+		{kind: "catalogSource", name: "cs-redhat-operator-index", data: []byte("kind: CatalogSource\n")},
+		{kind: "imageContentSourcePolicy", name: "operator-0", data: []byte("kind: ImageContentSourcePolicy\n")},
+	}
+
+	type spec struct {
+		name     string
+		output   string
+		expFiles map[string]string
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name:   "Valid/SingleFile",
+			output: manifestOutputSingleFile,
+			expFiles: map[string]string{
+				"clusterResources.yaml": "---\nkind: UpdateService\n---\nkind: CatalogSource\n---\nkind: ImageContentSourcePolicy\n",
+			},
+		},
+		{
+			name:   "Valid/PerResource",
+			output: manifestOutputPerResource,
+			expFiles: map[string]string{
+				"updateService-update-service-oc-mirror.yaml": "kind: UpdateService\n",
+				"catalogSource-cs-redhat-operator-index.yaml": "kind: CatalogSource\n",
+				"imageContentSourcePolicy-operator-0.yaml":    "kind: ImageContentSourcePolicy\n",
+			},
+		},
+		{
+			name:     "Invalid/UnknownOutput",
+			output:   "kustomize",
+			expError: `unknown manifest output "kustomize"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := writeClusterResources(dir, c.output, resources)
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			require.Len(t, entries, len(c.expFiles))
+			for file, exp := range c.expFiles {
+				data, err := os.ReadFile(filepath.Join(dir, file))
+				require.NoError(t, err)
+				require.Equal(t, exp, string(data))
+			}
+		})
+	}
+}
+
+func TestCatalogSourceResourcesDeterministic(t *testing.T) {
+	mapping := image.TypedImageMapping{}
+	for _, tag := range []string{"v2", "latest", "v1"} {
+		ctlg := image.TypedImage{
+			TypedImageReference: image.TypedImageReference{
+				Ref:  reference.DockerImageReference{Registry: "test-registry", Name: "dev", Tag: tag},
+				Type: imagesource.DestinationRegistry,
+			},
+			Category: v1alpha2.TypeOperatorCatalog,
+		}
+		mapping[ctlg] = ctlg
+	}
+	for i := 0; i < 5; i++ {
+		resources, err := catalogSourceResources(mapping)
+		require.NoError(t, err)
+		var files []string
+		for _, resource := range resources {
+			files = append(files, resource.fileName())
+		}
+		require.Equal(t, []string{"catalogSource-cs-dev.yaml", "catalogSource-cs-dev-1.yaml", "catalogSource-cs-dev-2.yaml"}, files)
+		require.Contains(t, string(resources[0].data), "test-registry/dev:latest")
+	}
+}
+
 func TestGenerateCatalogSource(t *testing.T) {
 
 	expCfg := `apiVersion: operators.coreos.com/v1alpha1
@@ -0,0 +1,159 @@
+package mirror
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"golang.org/x/exp/slices"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+const (
+	// mappingFormatSkopeoSync writes the source images of the mapping
+	// as the YAML source of skopeo sync to skopeoSyncFile.
+	mappingFormatSkopeoSync = "skopeo-sync"
+	// mappingFormatDockerSave writes the source images of the mapping
+	// to dockerSaveFile, one per line, to pull and docker save them.
+	mappingFormatDockerSave = "docker-save"
+	skopeoSyncFile          = "skopeo-sync.yaml"
+	dockerSaveFile          = "docker-save.txt"
+)
+
+// skopeoSyncRegistry is a registry of the YAML source of skopeo sync.
+type skopeoSyncRegistry struct {
+	// Images are the tags or digests to sync keyed by repository.
+	Images    map[string][]string `json:"images"`
+	TLSVerify *bool               `json:"tls-verify,omitempty"`
+}
+
+// registrySources returns the source images of mapping pulled from a registry,
+// with the number of images read from disk, which cannot be transferred by
+// skopeo sync or docker.
+func registrySources(mapping image.TypedImageMapping) (srcs []image.TypedImage, skipped int) {
+	for src := range mapping {
+		if src.Type != imagesource.DestinationRegistry {
+			skipped++
+			continue
+		}
+		srcs = append(srcs, src)
+	}
+	sort.Slice(srcs, func(i, j int) bool {
+		return srcs[i].Ref.Exact() < srcs[j].Ref.Exact()
+	})
+	return srcs, skipped
+}
+
+// writeSkopeoSync writes the source images of mapping pulled from a registry
+// as the YAML source of skopeo sync, by digest when they are pinned. The TLS
+// certificates of the registries for which skipTLS is true are not verified.
+func writeSkopeoSync(w io.Writer, mapping image.TypedImageMapping, skipTLS func(registry string) bool) error {
+	srcs, _ := registrySources(mapping)
+	registries := map[string]*skopeoSyncRegistry{}
+	for _, src := range srcs {
+		ref := src.Ref.DockerClientDefaults()
+		reg, ok := registries[ref.Registry]
+		if !ok {
+			reg = &skopeoSyncRegistry{Images: map[string][]string{}}
+			if skipTLS(ref.Registry) {
+				verify := false
+				reg.TLSVerify = &verify
+			}
+			registries[ref.Registry] = reg
+		}
+		repo := path.Join(ref.Namespace, ref.Name)
+		version := ref.ID
+		if version == "" {
+			version = ref.Tag
+		}
+		if !slices.Contains(reg.Images[repo], version) {
+			reg.Images[repo] = append(reg.Images[repo], version)
+		}
+	}
+	data, err := yaml.Marshal(registries)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeDockerSave writes the source images of mapping pulled
+// from a registry, one per line.
+func writeDockerSave(w io.Writer, mapping image.TypedImageMapping) error {
+	srcs, _ := registrySources(mapping)
+	for _, src := range srcs {
+		if _, err := fmt.Fprintln(w, src.Ref.DockerClientDefaults().Exact()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skopeoSyncMismatches returns the number of images of mapping pulled from a
+// registry whose destination repository is not the source repository under
+// the destination prefix, where skopeo sync pushes them.
+func skopeoSyncMismatches(mapping image.TypedImageMapping, prefix string) int {
+	n := 0
+	for src, dest := range mapping {
+		if src.Type != imagesource.DestinationRegistry || dest.Type != imagesource.DestinationRegistry {
+			continue
+		}
+		ref := src.Ref.DockerClientDefaults()
+		if dest.Ref.AsRepository().Exact() != path.Join(prefix, ref.Namespace, ref.Name) {
+			n++
+		}
+	}
+	return n
+}
+
+// writeMappingFormatFile writes the source images of mapping in the format
+// of --mapping-format to dir, for sites transferring images with skopeo or
+// docker. It does nothing when the flag is not set.
+func (o *MirrorOptions) writeMappingFormatFile(dir string, mapping image.TypedImageMapping) error {
+	if o.MappingFormat == "" {
+		return nil
+	}
+	name := dockerSaveFile
+	if o.MappingFormat == mappingFormatSkopeoSync {
+		name = skopeoSyncFile
+	}
+	formatPath := filepath.Clean(filepath.Join(dir, name))
+	f, err := os.Create(formatPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	klog.Infof("Writing %s image list to %s", o.MappingFormat, formatPath)
+
+	switch o.MappingFormat {
+	case mappingFormatSkopeoSync:
+		skipTLS := func(registry string) bool {
+			return o.SourceSkipTLS || slices.Contains(o.SourceSkipTLSRegistries, registry)
+		}
+		if err := writeSkopeoSync(f, mapping, skipTLS); err != nil {
+			return err
+		}
+		if len(o.ToMirror) != 0 {
+			prefix := path.Join(o.ToMirror, o.UserNamespace)
+			if n := skopeoSyncMismatches(mapping, prefix); n != 0 {
+				klog.Warningf("%d images are mirrored to another repository than the one skopeo sync pushes them to under %s, see %s", n, prefix, mappingFile)
+			}
+		}
+	default:
+		if err := writeDockerSave(f, mapping); err != nil {
+			return err
+		}
+	}
+	if _, skipped := registrySources(mapping); skipped != 0 {
+		klog.Warningf("%d images read from disk are not listed in %s", skipped, formatPath)
+	}
+	return f.Sync()
+}
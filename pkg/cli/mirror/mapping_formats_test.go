@@ -0,0 +1,60 @@
+package mirror
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestWriteMappingFormats(t *testing.T) {
+	typed := func(typ imagesource.DestinationType, registry, namespace, name, tag, id string) image.TypedImage {
+		return image.TypedImage{
+			TypedImageReference: image.TypedImageReference{
+				Type: typ,
+				Ref:  reference.DockerImageReference{Registry: registry, Namespace: namespace, Name: name, Tag: tag, ID: id},
+			},
+		}
+	}
+	digest := "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+
+	mapping := image.TypedImageMapping{
+		typed(imagesource.DestinationRegistry, "quay.io", "openshift-release-dev", "ocp-release", "4.14.1-x86_64", ""): typed(imagesource.DestinationRegistry, "mirror.local", "ns/openshift-release-dev", "ocp-release", "4.14.1-x86_64", ""),
+		typed(imagesource.DestinationRegistry, "quay.io", "openshift-release-dev", "ocp-v4.0-art-dev", "", digest):     typed(imagesource.DestinationRegistry, "mirror.local", "ns/openshift-release-dev", "ocp-v4.0-art-dev", "111111", ""),
+		typed(imagesource.DestinationRegistry, "registry.redhat.io", "ubi9", "ubi", "latest", ""):                      typed(imagesource.DestinationRegistry, "mirror.local", "ns/redhat/ubi9", "ubi", "latest", ""),
+		typed(image.DestinationOCI, "", "local", "catalog", "v1", ""):                                                  typed(imagesource.DestinationRegistry, "mirror.local", "ns/local", "catalog", "v1", ""),
+	}
+
+	t.Run("Valid/SkopeoSync", func(t *testing.T) {
+		var buf bytes.Buffer
+		skipTLS := func(registry string) bool { return registry == "registry.redhat.io" }
+		require.NoError(t, writeSkopeoSync(&buf, mapping, skipTLS))
+		require.Equal(t, `quay.io:
+  images:
+    openshift-release-dev/ocp-release:
+    - 4.14.1-x86_64
+    openshift-release-dev/ocp-v4.0-art-dev:
+    - sha256:1111111111111111111111111111111111111111111111111111111111111111
+registry.redhat.io:
+  images:
+    ubi9/ubi:
+    - latest
+  tls-verify: false
+`, buf.String())
+		// The namespace mapped image is not where skopeo sync pushes it.
+		require.Equal(t, 1, skopeoSyncMismatches(mapping, "mirror.local/ns"))
+	})
+
+	t.Run("Valid/DockerSave", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeDockerSave(&buf, mapping))
+		require.Equal(t, `quay.io/openshift-release-dev/ocp-release:4.14.1-x86_64
+quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:1111111111111111111111111111111111111111111111111111111111111111
+registry.redhat.io/ubi9/ubi:latest
+`, buf.String())
+	})
+}
@@ -0,0 +1,208 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+type MetadataOptions struct {
+	*cli.RootOptions
+	ConfigPath string   // Path to the imageset configuration holding the storage configuration of the metadata
+	Profile    string   // Name of a profile merged onto the base configuration
+	Overlays   []string // Paths to imageset configurations merged onto the base configuration before the profile
+	File       string   // Path to the portable metadata file
+	Force      bool     // Overwrite the metadata already stored in the backend on import
+}
+
+// NewMetadataCommand returns the metadata command, whose
+// subcommands move the metadata between storage backends.
+func NewMetadataCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metadata",
+		Short: "Export and import the mirror metadata",
+		Long: templates.LongDesc(`
+		Export the mirror metadata stored in the backend of an imageset configuration
+		to a portable file, and import it to the backend of another imageset
+		configuration. The metadata holds the imageset UUID, the sequence history and
+		the associations of the mirrored images, so moving it moves the workspace
+		between registries or from a local to a registry backend without starting a
+		new imageset sequence.
+	`),
+		Run: kcmdutil.DefaultSubCommandRun(ro.IOStreams.ErrOut),
+	}
+	cmd.AddCommand(newMetadataExportCommand(f, ro))
+	cmd.AddCommand(newMetadataImportCommand(f, ro))
+	return cmd
+}
+
+func newMetadataExportCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := MetadataOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "export <file>",
+		Short: "Export the mirror metadata to a portable file",
+		Example: templates.Examples(`
+			# Export the metadata stored in the backend of the imageset configuration
+			oc-mirror metadata export --config mirror-config.yaml metadata.json
+		`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Validate())
+			checkErr(o.Export(cmd.Context()))
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVarP(&o.ConfigPath, "config", "c", o.ConfigPath, "Path to imageset configuration file holding the metadata storage configuration")
+	fs.StringVar(&o.Profile, "profile", o.Profile, "Name of a profile in the imageset configuration to merge onto the base configuration")
+	fs.StringArrayVar(&o.Overlays, "overlay", o.Overlays, "Path to an imageset configuration to merge onto the base configuration before the profile. "+
+		"Can be repeated, each overlay taking precedence over the previous ones")
+	o.BindFlags(cmd.PersistentFlags())
+
+	return cmd
+}
+
+func newMetadataImportCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := MetadataOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import the mirror metadata from a portable file",
+		Example: templates.Examples(`
+			# Import exported metadata to the backend of the imageset configuration
+			oc-mirror metadata import --config mirror-config.yaml metadata.json
+		`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Validate())
+			checkErr(o.Import(cmd.Context()))
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVarP(&o.ConfigPath, "config", "c", o.ConfigPath, "Path to imageset configuration file holding the metadata storage configuration")
+	fs.StringVar(&o.Profile, "profile", o.Profile, "Name of a profile in the imageset configuration to merge onto the base configuration")
+	fs.StringArrayVar(&o.Overlays, "overlay", o.Overlays, "Path to an imageset configuration to merge onto the base configuration before the profile. "+
+		"Can be repeated, each overlay taking precedence over the previous ones")
+	fs.BoolVar(&o.Force, "force", o.Force, "Overwrite the metadata already stored in the backend")
+	o.BindFlags(cmd.PersistentFlags())
+
+	return cmd
+}
+
+func (o *MetadataOptions) Complete(args []string) error {
+	o.File = args[0]
+	return nil
+}
+
+func (o *MetadataOptions) Validate() error {
+	switch {
+	case len(o.ConfigPath) == 0:
+		return errors.New("must specify --config")
+	case len(o.File) == 0:
+		return errors.New("must specify a metadata file")
+	}
+	return nil
+}
+
+// Export writes the metadata stored in the backend of the
+// imageset configuration to File.
+func (o *MetadataOptions) Export(ctx context.Context) error {
+	backend, err := o.backend()
+	if err != nil {
+		return err
+	}
+	var meta v1alpha2.Metadata
+	if err := backend.ReadMetadata(ctx, &meta, config.MetadataBasePath); err != nil {
+		if errors.Is(err, storage.ErrMetadataNotExist) {
+			return errors.New("no metadata found in the storage backend of the imageset configuration")
+		}
+		return err
+	}
+
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.File, data, 0600); err != nil {
+		return fmt.Errorf("error writing metadata file: %v", err)
+	}
+	klog.Infof("Exported metadata of imageset %s at sequence %d to %s", meta.Uid, meta.PastMirror.Sequence, o.File)
+	return nil
+}
+
+// Import writes the metadata of File to the backend of the imageset
+// configuration. Metadata already stored in the backend is only
+// overwritten with Force.
+func (o *MetadataOptions) Import(ctx context.Context) error {
+	data, err := os.ReadFile(o.File)
+	if err != nil {
+		return fmt.Errorf("error reading metadata file: %v", err)
+	}
+	meta, err := config.LoadMetadata(data)
+	if err != nil {
+		return fmt.Errorf("error loading metadata file %s: %v", o.File, err)
+	}
+	if meta.Uid == uuid.Nil {
+		return fmt.Errorf("metadata file %s has no imageset UUID", o.File)
+	}
+
+	backend, err := o.backend()
+	if err != nil {
+		return err
+	}
+	var existing v1alpha2.Metadata
+	switch err := backend.ReadMetadata(ctx, &existing, config.MetadataBasePath); {
+	case errors.Is(err, storage.ErrMetadataNotExist):
+	case err != nil:
+		return err
+	case !o.Force:
+		return fmt.Errorf("metadata of imageset %s already stored in the backend, use --force to overwrite it", existing.Uid)
+	default:
+		klog.Warningf("overwriting metadata of imageset %s at sequence %d", existing.Uid, existing.PastMirror.Sequence)
+	}
+
+	if err := backend.WriteMetadata(ctx, &meta, config.MetadataBasePath); err != nil {
+		return fmt.Errorf("error writing metadata: %v", err)
+	}
+	klog.Infof("Imported metadata of imageset %s at sequence %d", meta.Uid, meta.PastMirror.Sequence)
+	return nil
+}
+
+// backend returns the storage backend of the imageset configuration.
+func (o *MetadataOptions) backend() (storage.Backend, error) {
+	cfg, err := config.ReadConfigWithOverlays(o.ConfigPath, o.Overlays, o.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.StorageConfig.IsSet() {
+		return nil, errors.New("the imageset configuration must set storageConfig")
+	}
+	if err := os.MkdirAll(o.Dir, 0750); err != nil {
+		return nil, err
+	}
+	backend, err := storage.ByConfig(filepath.Join(o.Dir, config.SourceDir), cfg.StorageConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error opening backend: %v", err)
+	}
+	return backend, nil
+}
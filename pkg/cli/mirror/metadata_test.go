@@ -0,0 +1,130 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+func TestMetadataValidate(t *testing.T) {
+	type spec struct {
+		name     string
+		opts     *MetadataOptions
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name: "Valid/ConfigAndFile",
+			opts: &MetadataOptions{ConfigPath: "foo", File: "metadata.json"},
+		},
+		{
+			name:     "Invalid/NoConfig",
+			opts:     &MetadataOptions{File: "metadata.json"},
+			expError: "must specify --config",
+		},
+		{
+			name:     "Invalid/NoFile",
+			opts:     &MetadataOptions{ConfigPath: "foo"},
+			expError: "must specify a metadata file",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.opts.Validate()
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMetadataExportImport(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	writeConfig := func(name string) (string, string) {
+		storageDir := filepath.Join(tmpDir, name)
+		cfgPath := filepath.Join(tmpDir, name+".yaml")
+		cfg := fmt.Sprintf(`apiVersion: mirror.openshift.io/v1alpha2
+kind: ImageSetConfiguration
+storageConfig:
+  local:
+    path: %s
+mirror:
+  additionalImages:
+  - name: quay.io/foo/bar:latest
+`, storageDir)
+		require.NoError(t, os.WriteFile(cfgPath, []byte(cfg), 0600))
+		return cfgPath, storageDir
+	}
+	sourceCfg, sourceDir := writeConfig("source")
+	targetCfg, targetDir := writeConfig("target")
+
+	meta := v1alpha2.NewMetadata()
+	meta.Uid = uuid.New()
+	meta.PastMirror.Sequence = 3
+	meta.PastAssociations = []v1alpha2.Association{
+		{Name: "quay.io/foo/bar:latest", Path: "foo/bar", ID: "sha256:0000000000000000000000000000000000000000000000000000000000000001", TagSymlink: "latest", Type: v1alpha2.TypeGeneric},
+	}
+	source, err := storage.NewLocalBackend(sourceDir)
+	require.NoError(t, err)
+	require.NoError(t, source.WriteMetadata(ctx, &meta, config.MetadataBasePath))
+
+	ro := &cli.RootOptions{
+		IOStreams: genericclioptions.IOStreams{Out: io.Discard, ErrOut: io.Discard},
+		Dir:       filepath.Join(tmpDir, "oc-mirror-workspace"),
+	}
+	file := filepath.Join(tmpDir, "metadata.json")
+
+	export := &MetadataOptions{RootOptions: ro, ConfigPath: sourceCfg, File: file}
+	require.NoError(t, export.Export(ctx))
+
+	imp := &MetadataOptions{RootOptions: ro, ConfigPath: targetCfg, File: file}
+	require.NoError(t, imp.Import(ctx))
+
+	target, err := storage.NewLocalBackend(targetDir)
+	require.NoError(t, err)
+	var imported v1alpha2.Metadata
+	require.NoError(t, target.ReadMetadata(ctx, &imported, config.MetadataBasePath))
+	require.Equal(t, meta.Uid, imported.Uid)
+	require.Equal(t, 3, imported.PastMirror.Sequence)
+	require.Equal(t, meta.PastAssociations, imported.PastAssociations)
+
+	t.Run("Invalid/AlreadyStored", func(t *testing.T) {
+		require.ErrorContains(t, imp.Import(ctx), "already stored in the backend, use --force to overwrite it")
+	})
+
+	t.Run("Valid/Force", func(t *testing.T) {
+		imp.Force = true
+		defer func() { imp.Force = false }()
+		require.NoError(t, imp.Import(ctx))
+	})
+
+	t.Run("Invalid/NoMetadata", func(t *testing.T) {
+		emptyCfg, _ := writeConfig("empty")
+		export := &MetadataOptions{RootOptions: ro, ConfigPath: emptyCfg, File: filepath.Join(tmpDir, "empty.json")}
+		require.EqualError(t, export.Export(ctx), "no metadata found in the storage backend of the imageset configuration")
+	})
+
+	t.Run("Valid/OverlayStorageConfig", func(t *testing.T) {
+		emptyCfg, _ := writeConfig("overlaid")
+		export := &MetadataOptions{RootOptions: ro, ConfigPath: emptyCfg, Overlays: []string{sourceCfg}, File: filepath.Join(tmpDir, "overlaid.json")}
+		require.NoError(t, export.Export(ctx))
+	})
+}
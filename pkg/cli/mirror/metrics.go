@@ -0,0 +1,186 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+// Phases of a run, timed by the oc_mirror_phase_duration_seconds
+// metric and recorded in the metadata of interrupted runs.
+const (
+	phasePlan    = "plan"
+	phaseMirror  = "mirror"
+	phasePack    = "pack"
+	phasePublish = "publish"
+)
+
+// runMetrics holds the Prometheus metrics of a run, served
+// with --metrics-address and written with --metrics-file.
+type runMetrics struct {
+	registry       *prometheus.Registry
+	imagesMirrored prometheus.Counter
+	imagesSkipped  prometheus.Counter
+	errors         *prometheus.CounterVec
+	phaseDuration  *prometheus.GaugeVec
+	runDuration    prometheus.Gauge
+	runSucceeded   prometheus.Gauge
+	runTimestamp   prometheus.Gauge
+}
+
+func newRunMetrics() *runMetrics {
+	m := &runMetrics{
+		registry: prometheus.NewRegistry(),
+		imagesMirrored: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "oc_mirror_images_mirrored_total",
+			Help: "Images mirrored by the run.",
+		}),
+		imagesSkipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "oc_mirror_images_skipped_total",
+			Help: "Images not published by the run, as a previous publish already pushed them to the destination.",
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "oc_mirror_errors_total",
+			Help: "Errors of the run by class, including the errors skipped with --continue-on-error.",
+		}, []string{"class"}),
+		phaseDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "oc_mirror_phase_duration_seconds",
+			Help: "Time spent by the run in each phase.",
+		}, []string{"phase"}),
+		runDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "oc_mirror_run_duration_seconds",
+			Help: "Duration of the run, set when it completes.",
+		}),
+		runSucceeded: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "oc_mirror_run_succeeded",
+			Help: "1 if the run succeeded without skipping errors, 0 otherwise, set when it completes.",
+		}),
+		runTimestamp: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "oc_mirror_run_completion_timestamp_seconds",
+			Help: "Unix time of the completion of the run.",
+		}),
+	}
+	transferred := func(direction string, bytes func() int64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "oc_mirror_transferred_bytes_total",
+			Help:        "Bytes received from and sent to registries by the run.",
+			ConstLabels: prometheus.Labels{"direction": direction},
+		}, func() float64 { return float64(bytes()) })
+	}
+	m.registry.MustRegister(
+		m.imagesMirrored,
+		m.imagesSkipped,
+		m.errors,
+		m.phaseDuration,
+		m.runDuration,
+		m.runSucceeded,
+		m.runTimestamp,
+		transferred("received", func() int64 { received, _ := network.TransferredBytes(); return received }),
+		transferred("sent", func() int64 { _, sent := network.TransferredBytes(); return sent }),
+	)
+	return m
+}
+
+// addImages counts n mirrored images.
+func (m *runMetrics) addImages(n int) {
+	if m != nil {
+		m.imagesMirrored.Add(float64(n))
+	}
+}
+
+// addSkipped counts n images skipped as already published.
+func (m *runMetrics) addSkipped(n int) {
+	if m != nil {
+		m.imagesSkipped.Add(float64(n))
+	}
+}
+
+// addError counts err by class.
+func (m *runMetrics) addError(err error) {
+	if m != nil && err != nil {
+		m.errors.WithLabelValues(ClassifyError(err).String()).Inc()
+	}
+}
+
+// timePhase starts timing phase, the returned function adds
+// the time spent to the phase. Phases run once per destination
+// when publishing to several destinations.
+func (m *runMetrics) timePhase(phase string) func() {
+	if m == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		m.phaseDuration.WithLabelValues(phase).Add(time.Since(start).Seconds())
+	}
+}
+
+// startPhase records that the run entered phase, for the metrics and the
+// record of interrupted runs. The returned function ends the timing of phase.
+func (o *MirrorOptions) startPhase(phase string) func() {
+	o.phase = phase
+	return o.metrics.timePhase(phase)
+}
+
+// countMirrored counts n images mirrored by the run.
+func (o *MirrorOptions) countMirrored(n int) {
+	o.imagesMirrored += n
+	o.metrics.addImages(n)
+}
+
+// serve serves the metrics on address in the background
+// until the returned function is called.
+func (m *runMetrics) serve(address string) (func(), error) {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("error serving metrics: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.Warningf("error serving metrics: %v", err)
+		}
+	}()
+	klog.Infof("Serving metrics on http://%s/metrics", l.Addr())
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			klog.V(1).Infof("error stopping the metrics server: %v", err)
+		}
+	}, nil
+}
+
+// recordCompletion sets the outcome of the run started at start in the
+// metrics and writes them to the --metrics-file. Errors writing the
+// metrics are logged and never change the outcome of the run.
+func (o *MirrorOptions) recordCompletion(start time.Time, runErr error) {
+	if o.metrics == nil {
+		return
+	}
+	o.metrics.addError(runErr)
+	o.metrics.runDuration.Set(time.Since(start).Seconds())
+	if runErr == nil && !o.continuedOnError {
+		o.metrics.runSucceeded.Set(1)
+	} else {
+		o.metrics.runSucceeded.Set(0)
+	}
+	o.metrics.runTimestamp.SetToCurrentTime()
+
+	if o.MetricsFile != "" {
+		if err := prometheus.WriteToTextfile(o.MetricsFile, o.metrics.registry); err != nil {
+			klog.Warningf("unable to write the metrics: %v", err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package mirror
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordCompletion(t *testing.T) {
+	type spec struct {
+		name         string
+		runErr       error
+		continued    bool
+		expSucceeded string
+		expErrors    []string
+	}
+
+	cases := []spec{
+		{
+			name:         "Valid/Succeeded",
+			expSucceeded: "oc_mirror_run_succeeded 1",
+		},
+		{
+			name:         "Valid/Failed",
+			runErr:       NewClassifiedError(ErrorClassAuth, errors.New("unauthorized")),
+			expSucceeded: "oc_mirror_run_succeeded 0",
+			expErrors:    []string{`oc_mirror_errors_total{class="auth"} 1`},
+		},
+		{
+			name:         "Valid/ContinuedOnError",
+			continued:    true,
+			expSucceeded: "oc_mirror_run_succeeded 0",
+			expErrors:    []string{`oc_mirror_errors_total{class="disk-full"} 1`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "oc-mirror.prom")
+			o := &MirrorOptions{MetricsFile: path, ContinueOnError: c.continued}
+			o.metrics = newRunMetrics()
+
+			stop := o.metrics.timePhase(phaseMirror)
+			stop()
+			o.metrics.addImages(3)
+			if c.continued {
+				require.NoError(t, o.checkErr(syscall.ENOSPC, nil, nil))
+			}
+			o.recordCompletion(time.Now(), c.runErr)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			metrics := string(data)
+			for _, line := range append([]string{
+				c.expSucceeded,
+				"oc_mirror_images_mirrored_total 3",
+				`oc_mirror_phase_duration_seconds{phase="mirror"}`,
+				`oc_mirror_transferred_bytes_total{direction="received"}`,
+				`oc_mirror_transferred_bytes_total{direction="sent"}`,
+				"oc_mirror_run_duration_seconds",
+				"oc_mirror_run_completion_timestamp_seconds",
+			}, c.expErrors...) {
+				require.Contains(t, metrics, line)
+			}
+			if len(c.expErrors) == 0 {
+				require.NotContains(t, metrics, "oc_mirror_errors_total{")
+			}
+		})
+	}
+}
+
+func TestServeMetrics(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	m := newRunMetrics()
+	m.addImages(2)
+	stop, err := m.serve(address)
+	require.NoError(t, err)
+	defer stop()
+
+	_, err = m.serve(address)
+	require.ErrorContains(t, err, "error serving metrics")
+
+	resp, err := http.Get("http://" + address + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "oc_mirror_images_mirrored_total 2")
+}
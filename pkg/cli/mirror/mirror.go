@@ -8,25 +8,29 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	imagecopy "github.com/containers/image/v5/copy"
 	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/types"
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/opencontainers/go-digest"
 	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+	"github.com/openshift/library-go/pkg/image/reference"
 	"github.com/openshift/oc/pkg/cli/image/imagesource"
 	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
 	"github.com/openshift/oc/pkg/cli/image/mirror"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/klog/v2"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 
+	"github.com/openshift/oc-mirror/internal/experimental"
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/archive"
 	"github.com/openshift/oc-mirror/pkg/bundle"
 	"github.com/openshift/oc-mirror/pkg/cli"
 	"github.com/openshift/oc-mirror/pkg/cli/mirror/describe"
@@ -38,6 +42,7 @@ import (
 	"github.com/openshift/oc-mirror/pkg/metadata"
 	"github.com/openshift/oc-mirror/pkg/metadata/storage"
 
+	"github.com/openshift/oc-mirror/pkg/network"
 	cliV2 "github.com/openshift/oc-mirror/v2/pkg/cli"
 	"golang.org/x/exp/slices"
 )
@@ -64,6 +69,8 @@ var (
 		oc-mirror --config mirror-config.yaml file://mirror
 		# Mirror to a directory without layer and image differential operations
 		oc-mirror --config mirror-config.yaml file://mirror --ignore-history
+		# Mirror again every image of the configuration, continuing the imageset sequence
+		oc-mirror --config mirror-config.yaml file://mirror --force-full
 		# Mirror to mirror publish
 		oc-mirror --config mirror-config.yaml docker://localhost:5000
 		# Publish a previously created mirror archive
@@ -101,16 +108,13 @@ func buildV1Cmd() *cobra.Command {
 
 	klog.Warning("\n\n⚠️  oc-mirror v1 is deprecated (starting in 4.18 release) and will be removed in a future release - please migrate to oc-mirror --v2\n\n")
 
-	o := MirrorOptions{
-		operatorCatalogToFullArtifactPath: map[string]string{},
-	}
-	o.RootOptions = &cli.RootOptions{
+	o := NewMirrorOptions(&cli.RootOptions{
 		IOStreams: genericclioptions.IOStreams{
 			In:     os.Stdin,
 			Out:    os.Stdout,
 			ErrOut: os.Stderr,
 		},
-	}
+	})
 
 	// Configures a REST client getter factory from configs for mirroring releases.
 	kubeConfigFlags := genericclioptions.NewConfigFlags(true).WithDiscoveryBurst(250)
@@ -133,7 +137,7 @@ func buildV1Cmd() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(cmd, args))
 			kcmdutil.CheckErr(o.Validate())
-			kcmdutil.CheckErr(o.Run(cmd, f))
+			checkErr(o.Run(cmd, f))
 		},
 	}
 
@@ -144,6 +148,14 @@ func buildV1Cmd() *cobra.Command {
 	cmd.AddCommand(list.NewListCommand(f, o.RootOptions))
 	cmd.AddCommand(describe.NewDescribeCommand(f, o.RootOptions))
 	cmd.AddCommand(initcmd.NewInitCommand(f, o.RootOptions))
+	cmd.AddCommand(NewVerifyCommand(f, o.RootOptions))
+	cmd.AddCommand(NewDeleteCommand(f, o.RootOptions))
+	cmd.AddCommand(NewMetadataCommand(f, o.RootOptions))
+	cmd.AddCommand(NewDoctorCommand(f, o.RootOptions))
+	cmd.AddCommand(NewValidateConfigCommand(f, o.RootOptions))
+	if experimental.Enabled() {
+		cmd.AddCommand(experimental.NewExperimentalCommand(f, o.RootOptions))
+	}
 
 	return cmd
 }
@@ -161,6 +173,10 @@ func (o *MirrorOptions) Complete(cmd *cobra.Command, args []string) error {
 	}
 	typStr, ref := destination[:splitIdx], destination[splitIdx+3:]
 
+	if o.Workspace != "" && cmd.Flags().Changed("dir") {
+		return fmt.Errorf("--workspace cannot be specified with --dir")
+	}
+
 	switch typStr {
 	case "file":
 		if cmd.Flags().Changed("dir") {
@@ -174,6 +190,9 @@ func (o *MirrorOptions) Complete(cmd *cobra.Command, args []string) error {
 		// If the destination is on disk, made the output dir the
 		// parent dir for the workspace
 		o.Dir = filepath.Join(o.OutputDir, o.Dir)
+		if o.Workspace != "" {
+			o.Dir = filepath.Clean(o.Workspace)
+		}
 	case "oci":
 		if cmd.Flags().Changed("dir") {
 			return fmt.Errorf("--dir cannot be specified with oci destination scheme")
@@ -186,11 +205,17 @@ func (o *MirrorOptions) Complete(cmd *cobra.Command, args []string) error {
 		// If the destination is on disk, made the output dir the
 		// parent dir for the workspace
 		o.Dir = filepath.Join(o.OutputDir, o.Dir)
+		if o.Workspace != "" {
+			o.Dir = filepath.Clean(o.Workspace)
+		}
 	case "docker":
 		mirror, err := imagesource.ParseReference(ref)
 		if err != nil {
 			return err
 		}
+		if o.Workspace != "" {
+			o.Dir = filepath.Clean(o.Workspace)
+		}
 		o.ToMirror = mirror.Ref.Registry
 		// get the <namespace>/<image> portion of the docker reference only
 		o.UserNamespace = mirror.Ref.RepositoryName()
@@ -202,6 +227,62 @@ func (o *MirrorOptions) Complete(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unknown destination scheme %q", typStr)
 	}
 
+	if len(o.AdditionalMirrors) > 0 {
+		if typStr != "docker" {
+			return fmt.Errorf("--to can only be used with a docker:// destination")
+		}
+		o.destinations = []mirrorDestination{{Registry: o.ToMirror, Namespace: o.UserNamespace}}
+		seen := map[string]struct{}{o.destinations[0].String(): {}}
+		for _, to := range o.AdditionalMirrors {
+			dest, err := parseMirrorDestination(to, o.MaxNestedPaths)
+			if err != nil {
+				return fmt.Errorf("invalid --to destination: %v", err)
+			}
+			if _, found := seen[dest.String()]; found {
+				return fmt.Errorf("destination %q is specified more than once", to)
+			}
+			seen[dest.String()] = struct{}{}
+			o.destinations = append(o.destinations, dest)
+		}
+	}
+
+	if len(o.Annotations) > 0 {
+		o.annotations = make(map[string]string, len(o.Annotations))
+		for _, annotation := range o.Annotations {
+			key, value, found := strings.Cut(annotation, "=")
+			if !found || strings.TrimSpace(key) == "" {
+				return fmt.Errorf("invalid annotation %q, expected key=value", annotation)
+			}
+			o.annotations[strings.TrimSpace(key)] = value
+		}
+	}
+
+	if len(o.TagTemplates) > 0 {
+		templates, err := parseTagTemplates(o.TagTemplates)
+		if err != nil {
+			return err
+		}
+		o.tagTemplates = templates
+	}
+
+	if len(o.BaselineCatalogs) > 0 {
+		baselines, err := parseBaselineCatalogs(o.BaselineCatalogs)
+		if err != nil {
+			return err
+		}
+		o.baselineCatalogs = baselines
+	}
+
+	var providers []image.CredentialProvider
+	for _, spec := range o.CredentialProviders {
+		provider, err := image.ParseCredentialProvider(spec)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, provider)
+	}
+	image.SetCredentialProviders(providers...)
+
 	return nil
 }
 
@@ -236,16 +317,130 @@ func checkDockerReference(mirror imagesource.TypedImageReference, nested int) er
 	return nil
 }
 
-func (o *MirrorOptions) Validate() error {
+func (o *MirrorOptions) Validate() (err error) {
+	var registries []network.Registry
+	for _, host := range append(append([]string{}, o.SourceSkipTLSRegistries...), o.DestSkipTLSRegistries...) {
+		registries = append(registries, network.Registry{Location: host, Insecure: true})
+	}
+	if o.RegistryOverrides != "" {
+		overrides, err := network.LoadRegistries(o.RegistryOverrides)
+		if err != nil {
+			return err
+		}
+		registries = append(registries, overrides...)
+	}
+	if err := network.SetRegistries(registries); err != nil {
+		return err
+	}
+	chunkSize, err := o.uploadChunkBytes()
+	if err != nil {
+		return err
+	}
+	if o.MaxConcurrentUploads < 0 {
+		return fmt.Errorf("--max-concurrent-uploads must not be negative")
+	}
+	if err := network.SetUploadLimits(chunkSize, o.MaxConcurrentUploads); err != nil {
+		return err
+	}
+
+	if o.OPMSandbox {
+		if err := checkOPMSandbox(); err != nil {
+			return err
+		}
+	}
+
+	// Configure the proxy, the trusted certificate authorities and the credentials
+	// before the first request. Errors reading the configuration are reported below.
+	var cfg v1alpha2.ImageSetConfiguration
+	if o.hasConfig() {
+		if c, err := o.readConfig(); err == nil {
+			if err := network.Configure(c.Proxy); err != nil {
+				return err
+			}
+			cfg = c
+		}
+	}
+	if err := o.configureAuth(context.Background(), cfg); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			o.removeMergedAuth()
+		}
+	}()
+
 	switch {
 	case len(o.From) > 0 && len(o.ToMirror) == 0:
 		return fmt.Errorf("must specify a registry destination")
-	case len(o.OutputDir) > 0 && len(o.ConfigPath) == 0:
+	case len(o.OutputDir) > 0 && !o.hasConfig():
 		return fmt.Errorf("must specify a configuration file with --config")
-	case len(o.ToMirror) > 0 && len(o.ConfigPath) == 0 && len(o.From) == 0:
+	case len(o.ToMirror) > 0 && !o.hasConfig() && len(o.From) == 0:
 		return fmt.Errorf("must specify --config or --from with registry destination")
 	case o.ManifestsOnly && len(o.From) == 0:
 		return fmt.Errorf("must specify a path to an archive with --from with --manifest-only")
+	case o.ExportCatalogsArchive && !o.ExportCatalogs:
+		return fmt.Errorf("--export-catalogs-archive requires --export-catalogs")
+	case len(o.BaselineCatalogs) > 0 && !o.hasConfig():
+		return fmt.Errorf("--baseline-catalog requires --config")
+	case o.ForceFull && !o.hasConfig():
+		return fmt.Errorf("--force-full requires --config")
+	case o.PushReleaseSignatures && len(o.ToMirror) == 0:
+		return fmt.Errorf("--push-release-signatures requires a registry destination")
+	case o.ApplySignaturesKubeconfig != "" && len(o.ToMirror) == 0:
+		return fmt.Errorf("--apply-signatures-kubeconfig requires a registry destination")
+	case o.PreserveDigests && len(o.ToMirror) == 0:
+		return fmt.Errorf("--preserve-digests requires a registry destination")
+	case o.OCIMediaTypes && len(o.From) == 0:
+		return fmt.Errorf("--oci-media-types requires --from")
+	case o.OCIMediaTypes && o.PreserveDigests:
+		return fmt.Errorf("--oci-media-types and --preserve-digests are mutually exclusive")
+	case o.QuayCreateRepositories && len(o.ToMirror) == 0:
+		return fmt.Errorf("--quay-create-repositories requires a registry destination")
+	case o.QuayCreateRepositories && os.Getenv(quayAPITokenEnv) == "":
+		return fmt.Errorf("--quay-create-repositories requires an OAuth token of the Quay API in %s", quayAPITokenEnv)
+	case o.CreateRepos && len(o.ToMirror) == 0:
+		return fmt.Errorf("--create-repos requires a registry destination")
+	case o.ReleaseDigests != "" && !o.hasConfig():
+		return fmt.Errorf("--release-digests requires --config")
+	case len(o.Overlays) != 0 && !o.hasConfig():
+		return fmt.Errorf("--overlay requires --config")
+	case o.OperatorDependencies != "" && o.OperatorDependencies != dependenciesPermissive && o.OperatorDependencies != dependenciesStrict:
+		return fmt.Errorf("--operator-dependencies must be %q or %q", dependenciesPermissive, dependenciesStrict)
+	case o.ManifestOutput != "" && o.ManifestOutput != manifestOutputSingleFile && o.ManifestOutput != manifestOutputPerResource:
+		return fmt.Errorf("--manifest-output must be %q or %q", manifestOutputSingleFile, manifestOutputPerResource)
+	case o.MappingFormat != "" && o.MappingFormat != mappingFormatSkopeoSync && o.MappingFormat != mappingFormatDockerSave:
+		return fmt.Errorf("--mapping-format must be %q or %q", mappingFormatSkopeoSync, mappingFormatDockerSave)
+	case o.SeedFormat != "" && o.SeedFormat != seedFormatDockerArchive && o.SeedFormat != seedFormatOCIArchive:
+		return fmt.Errorf("--seed-format must be %q or %q", seedFormatDockerArchive, seedFormatOCIArchive)
+	case o.SeedFormat != "" && (len(o.OutputDir) == 0 || len(o.From) != 0):
+		return fmt.Errorf("--seed-format requires a file destination")
+	case o.MaxArchiveFiles < 0:
+		return fmt.Errorf("--max-archive-files must not be negative")
+	case o.ArchivesPerDir < 0:
+		return fmt.Errorf("--archives-per-dir must not be negative")
+	case o.CompatFormat != "" && (len(o.OutputDir) == 0 || len(o.From) > 0):
+		return fmt.Errorf("--compat-format requires a file:// destination")
+	case o.MaxDownloadSize != "" && !o.hasConfig():
+		return fmt.Errorf("--max-download-size requires --config")
+	case o.ResolveTimeout < 0:
+		return fmt.Errorf("--resolve-timeout must not be negative")
+	}
+
+	if _, err := o.maxDownloadBytes(); err != nil {
+		return err
+	}
+	if _, err := o.icspBudgetBytes(); err != nil {
+		return err
+	}
+
+	if o.CompatFormat != "" {
+		format, err := archive.ParseFormat(o.CompatFormat)
+		if err != nil {
+			return fmt.Errorf("invalid --compat-format: %v", err)
+		}
+		if format == archive.FormatV1 && o.ArchivesPerDir > 0 {
+			return fmt.Errorf("--archives-per-dir is not supported by imageset format %s", format)
+		}
 	}
 
 	var destInsecure bool
@@ -257,15 +452,17 @@ func (o *MirrorOptions) Validate() error {
 	// FIXME(jpower432): CheckPushPermissions is slated for deprecation
 	// must replace with its replacement
 	if len(o.ToMirror) > 0 && !o.ManifestsOnly {
-		klog.Infof("Checking push permissions for %s", o.ToMirror)
-		ref := path.Join(o.ToMirror, o.UserNamespace, "oc-mirror")
-		klog.V(2).Infof("Using image %s to check permissions", ref)
-		imgRef, err := name.ParseReference(ref, getNameOpts(destInsecure)...)
-		if err != nil {
-			return err
-		}
-		if err := remote.CheckPushPermission(imgRef, authn.DefaultKeychain, createRT(destInsecure)); err != nil {
-			return fmt.Errorf("error checking push permissions for %s: %v", o.ToMirror, err)
+		for _, dest := range o.mirrorDestinations() {
+			klog.Infof("Checking push permissions for %s", dest.Registry)
+			ref := path.Join(dest.Registry, dest.Namespace, "oc-mirror")
+			klog.V(2).Infof("Using image %s to check permissions", ref)
+			imgRef, err := name.ParseReference(ref, getNameOpts(destInsecure)...)
+			if err != nil {
+				return err
+			}
+			if err := remote.CheckPushPermission(imgRef, image.Keychain(), createRT(destInsecure)); err != nil {
+				return fmt.Errorf("error checking push permissions for %s: %v", dest.Registry, err)
+			}
 		}
 	}
 
@@ -277,11 +474,11 @@ func (o *MirrorOptions) Validate() error {
 
 	// mode options
 	mirrorToDisk := len(o.OutputDir) > 0 && o.From == ""
-	mirrorToMirror := len(o.ToMirror) > 0 && len(o.ConfigPath) > 0
+	mirrorToMirror := len(o.ToMirror) > 0 && o.hasConfig()
 
 	// mirrorToMirror workflow using the oci feature must have at least on operator set with oci:// prefix
 	if mirrorToMirror || mirrorToDisk {
-		cfg, err := config.ReadConfig(o.ConfigPath)
+		cfg, err := o.readConfig()
 		if err != nil {
 			if strings.Contains(err.Error(), "config GVK not recognized") && o.LogLevel == 2 {
 				return fmt.Errorf("detected a v2 ImageSetConfiguration, please use --v2 instead of -v2")
@@ -321,6 +518,16 @@ func (o *MirrorOptions) Validate() error {
 type cleanupFunc func() error
 
 func (o *MirrorOptions) Run(cmd *cobra.Command, f kcmdutil.Factory) (err error) {
+	ctx, cancel := o.CancelContext(cmd.Context())
+	defer cancel()
+	return o.Mirror(ctx)
+}
+
+// Mirror runs the workflow selected by the completed options: mirror to disk,
+// disk to mirror, mirror to mirror or manifests only.
+func (o *MirrorOptions) Mirror(ctx context.Context) error {
+	defer o.removeMergedAuth()
+
 	if o.OutputDir != "" {
 		if err := os.MkdirAll(o.OutputDir, 0750); err != nil {
 			return err
@@ -336,7 +543,54 @@ func (o *MirrorOptions) Run(cmd *cobra.Command, f kcmdutil.Factory) (err error)
 		return nil
 	}
 
-	return o.mirrorImages(cmd.Context(), cleanup)
+	start := time.Now()
+	if o.MetricsAddress != "" || o.MetricsFile != "" {
+		o.metrics = newRunMetrics()
+		if o.MetricsAddress != "" {
+			stop, err := o.metrics.serve(o.MetricsAddress)
+			if err != nil {
+				return err
+			}
+			defer stop()
+		}
+	}
+
+	stopAudit, err := o.startAuditLog("mirror")
+	if err != nil {
+		o.recordCompletion(start, err)
+		return err
+	}
+	defer stopAudit()
+
+	if o.Lock {
+		lock, err := o.acquireLocks()
+		if err != nil {
+			o.recordCompletion(start, err)
+			return err
+		}
+		defer lock.release()
+	}
+
+	if o.TUI {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		monitor, err := o.startMonitor(cancel)
+		if err != nil {
+			o.recordCompletion(start, err)
+			return err
+		}
+		o.monitor = monitor
+	}
+
+	err = o.mirrorImages(ctx, cleanup)
+	if err != nil && ctx.Err() != nil {
+		err = o.interrupted(err)
+	}
+	o.monitor.stop(err)
+	o.notifyCompletion(ctx, start, err)
+	o.recordCompletion(start, err)
+	return err
 }
 
 func (o *MirrorOptions) mirrorImages(ctx context.Context, cleanup cleanupFunc) error {
@@ -357,7 +611,7 @@ func (o *MirrorOptions) mirrorImages(ctx context.Context, cleanup cleanupFunc) e
 	// Three mode options
 	mirrorToDisk := len(o.OutputDir) > 0 && o.From == ""
 	diskToMirror := len(o.ToMirror) > 0 && len(o.From) > 0
-	mirrorToMirror := len(o.ToMirror) > 0 && len(o.ConfigPath) > 0
+	mirrorToMirror := len(o.ToMirror) > 0 && o.hasConfig()
 
 	switch {
 	case o.ManifestsOnly:
@@ -370,14 +624,31 @@ func (o *MirrorOptions) mirrorImages(ctx context.Context, cleanup cleanupFunc) e
 		if err != nil {
 			return err
 		}
-		mapping.ToRegistry(o.ToMirror, o.UserNamespace)
+		o.namespaceMappings = meta.PastMirror.Mirror.NamespaceMappings
+		o.samples = meta.PastMirror.Samples
+		o.applyNamespaceMappings(mapping)
 		results, err := o.createResultsDir()
 		if err != nil {
 			return err
 		}
-		return o.generateResults(mapping, results)
+		for _, dest := range o.mirrorDestinations() {
+			o.setDestination(dest)
+			destMapping := copyMapping(mapping)
+			destMapping.ToRegistry(o.ToMirror, o.UserNamespace)
+			if err := o.applyTagTemplates(destMapping); err != nil {
+				return err
+			}
+			dir, err := o.destinationPath(results, "")
+			if err != nil {
+				return err
+			}
+			if err := o.generateResults(destMapping, dir); err != nil {
+				return err
+			}
+		}
+		return nil
 	case mirrorToDisk:
-		cfg, err := config.ReadConfig(o.ConfigPath)
+		cfg, err := o.readConfig()
 		if err != nil {
 			return err
 		}
@@ -385,16 +656,27 @@ func (o *MirrorOptions) mirrorImages(ctx context.Context, cleanup cleanupFunc) e
 		return o.mirrorToDiskWrapper(ctx, cfg, cleanup)
 
 	case diskToMirror:
-		dir, err := o.createResultsDir()
+		results, err := o.createResultsDir()
 		if err != nil {
 			return err
 		}
-		o.OutputDir = dir
-		return o.diskToMirrorWrapper(ctx, cleanup)
+		// Publish the imageset to each destination in turn, the
+		// metadata of every destination is kept in that registry.
+		o.summary = runSummary{Operation: operationPublish, Destination: o.destinationsString()}
+		for _, dest := range o.mirrorDestinations() {
+			o.setDestination(dest)
+			if o.OutputDir, err = o.destinationPath(results, ""); err != nil {
+				return err
+			}
+			if err := o.diskToMirrorWrapper(ctx, cleanup); err != nil {
+				return err
+			}
+		}
+		return nil
 
 	case mirrorToMirror:
 
-		cfg, err := config.ReadConfig(o.ConfigPath)
+		cfg, err := o.readConfig()
 		if err != nil {
 			return err
 		}
@@ -408,18 +690,35 @@ func (o *MirrorOptions) mirrorImages(ctx context.Context, cleanup cleanupFunc) e
 	return cleanup()
 }
 
-// removePreviouslyMirrored will check if an image has been previously mirrored
-// and remove it from the mapping if found. These images are added to the current AssociationSet
-// to maintain a history of images. Any images in the AssociationSet that was not requested in the mapping
-// will be pruned from the history.
-func (o *MirrorOptions) removePreviouslyMirrored(images image.TypedImageMapping, meta v1alpha2.Metadata) (image.AssociationSet, error) {
-	prevDownloads, err := image.ConvertToAssociationSet(meta.PastAssociations)
-	if err != nil {
-		return image.AssociationSet{}, err
+// removePreviouslyMirrored will check if an image of prev, the associations of the
+// previous sequences, has been previously mirrored and remove it from the mapping if found.
+// These images are kept in the returned AssociationDB, the database prunedName of the
+// workspace, to maintain a history of images. Any images in the history that were not
+// requested in the mapping are pruned from it. With --force-full, no image is removed and
+// the history is dropped. Closing the returned AssociationDB removes it.
+func (o *MirrorOptions) removePreviouslyMirrored(images image.TypedImageMapping, prev *image.AssociationDB, prunedName string) (*image.AssociationDB, error) {
+	prunedPath := o.associationsDBPath(prunedName)
+	if o.IgnoreHistory && !o.ForceFull {
+		keys, err := prev.Keys()
+		if err != nil {
+			return nil, err
+		}
+		return prev.Prune(prunedPath, keys)
 	}
 
-	if o.IgnoreHistory {
-		return prevDownloads, nil
+	// A full mirror replaces the history: every image is mirrored again and
+	// the associations of this mirror become the only ones recorded.
+	if o.ForceFull {
+		n, err := prev.Len()
+		if err != nil {
+			return nil, err
+		}
+		klog.Infof("Ignoring %d previously mirrored images, mirroring every image of the configuration", n)
+		keep, err := o.previouslyMirroredBaselineImages(prev)
+		if err != nil {
+			return nil, err
+		}
+		return prev.Prune(prunedPath, keep)
 	}
 
 	var keep []string
@@ -430,27 +729,39 @@ func (o *MirrorOptions) removePreviouslyMirrored(images image.TypedImageMapping,
 		if srcRef.Ref.ID == "" {
 			continue
 		}
-		if found := prevDownloads.SetContainsKey(srcRef.Ref.String()); found {
+		found, err := prev.SetContainsKey(srcRef.Ref.String())
+		if err != nil {
+			return nil, err
+		}
+		if found {
 			klog.V(2).Infof("Skipping previously mirrored image %s", srcRef.Ref.String())
 			images.Remove(srcRef)
 			keep = append(keep, srcRef.Ref.String())
 		}
 	}
-
-	prunedDownloads, err := image.Prune(prevDownloads, keep)
+	baselineKeep, err := o.previouslyMirroredBaselineImages(prev)
 	if err != nil {
-		return prunedDownloads, err
+		return nil, err
 	}
+	keep = append(keep, baselineKeep...)
 
-	if len(images) == 0 {
-		return image.AssociationSet{}, ErrNoUpdatesExist
+	if len(images) == 0 && o.pulledArtifacts == 0 {
+		return nil, ErrNoUpdatesExist
 	}
 
-	return prunedDownloads, prunedDownloads.Validate()
+	prunedDownloads, err := prev.Prune(prunedPath, keep)
+	if err != nil {
+		return nil, err
+	}
+	if err := prunedDownloads.Validate(); err != nil {
+		prunedDownloads.Close()
+		return nil, err
+	}
+	return prunedDownloads, nil
 }
 
 // mirrorMappings downloads individual images from an image mapping.
-func (o *MirrorOptions) mirrorMappings(cfg v1alpha2.ImageSetConfiguration, images image.TypedImageMapping, insecure bool) error {
+func (o *MirrorOptions) mirrorMappings(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, images image.TypedImageMapping, insecure bool) error {
 
 	opts, err := o.newMirrorImageOptions(insecure)
 	if err != nil {
@@ -474,6 +785,7 @@ func (o *MirrorOptions) mirrorMappings(cfg v1alpha2.ImageSetConfiguration, image
 			Ref:  srcRef.Ref,
 			Type: srcRef.Type,
 		}
+		o.monitor.plan(srcTIR.String(), dstRef.Category)
 
 		// OCPBUGS-11922
 		dstTIR := o.processNestedPaths(&dstRef)
@@ -494,11 +806,44 @@ func (o *MirrorOptions) mirrorMappings(cfg v1alpha2.ImageSetConfiguration, image
 			Name:        srcRef.Ref.Name,
 		})
 	}
+	// Runs mirroring OCI artifacts only have no images to mirror.
+	if len(mappings) == 0 {
+		return nil
+	}
 	opts.Mappings = mappings
 	if err := opts.Validate(); err != nil {
 		return err
 	}
-	return o.checkErr(opts.Run(), nil, nil)
+	var dsts []reference.DockerImageReference
+	for _, m := range mappings {
+		if m.Destination.Type == imagesource.DestinationRegistry {
+			dsts = append(dsts, m.Destination.Ref)
+		}
+	}
+	if err := o.ensureRepositories(ctx, dsts...); err != nil {
+		return err
+	}
+	stopMirror := o.startPhase(phaseMirror)
+	err = o.runMirrorBatches(ctx, opts, mappings)
+	stopMirror()
+	if ClassifyError(err) == ErrorClassInterrupted {
+		return err
+	}
+	if err != nil {
+		// Authentication failures caused by clock skew surface as opaque 401s,
+		// check the clocks of the registries involved to explain them.
+		registries := sets.New[string]()
+		for _, m := range mappings {
+			for _, ref := range []imagesource.TypedImageReference{m.Source, m.Destination} {
+				if ref.Type == imagesource.DestinationRegistry {
+					registries.Insert(ref.Ref.Registry)
+				}
+			}
+		}
+		err = o.clockSkewMonitor().diagnoseAuthError(ctx, err, sets.List(registries), insecure)
+		err = diagnoseQuotaError(err)
+	}
+	return o.checkErr(err, nil, nil)
 }
 
 func (o *MirrorOptions) newMirrorImageOptions(insecure bool) (*mirror.MirrorImageOptions, error) {
@@ -518,6 +863,8 @@ func (o *MirrorOptions) newMirrorImageOptions(insecure bool) (*mirror.MirrorImag
 	if err != nil {
 		return opts, fmt.Errorf("error creating registry context: %v", err)
 	}
+	regctx.Transport = o.clockSkewMonitor().transport(regctx.Transport)
+	regctx.InsecureTransport = o.clockSkewMonitor().transport(regctx.InsecureTransport)
 	opts.SecurityOptions.CachedContext = regctx
 
 	return opts, nil
@@ -531,6 +878,9 @@ func (o *MirrorOptions) generateResults(mapping image.TypedImageMapping, dir str
 	if err := o.writeMappingFile(mappingResultsPath, mapping); err != nil {
 		return err
 	}
+	if err := o.writeMappingFormatFile(dir, mapping); err != nil {
+		return err
+	}
 
 	allICSPs := []operatorv1alpha1.ImageContentSourcePolicy{}
 	releases := image.ByCategory(mapping, v1alpha2.TypeOCPRelease, v1alpha2.TypeOCPReleaseContent)
@@ -547,6 +897,10 @@ func (o *MirrorOptions) generateResults(mapping image.TypedImageMapping, dir str
 		return nil
 	}
 
+	// With a manifest output set, the resources are collected and written once
+	// generated, otherwise each kind of resource is written in its legacy layout.
+	var resources []clusterResource
+
 	if len(graphs) == 1 {
 		releaseImages := image.ByCategory(releases, v1alpha2.TypeOCPRelease)
 		if len(releaseImages) != 0 {
@@ -559,7 +913,13 @@ func (o *MirrorOptions) generateResults(mapping image.TypedImageMapping, dir str
 					release = v
 					break
 				}
-				if err := WriteUpdateService(release, graph, dir); err != nil {
+				if o.ManifestOutput != "" {
+					resource, err := updateServiceResource(release, graph)
+					if err != nil {
+						return err
+					}
+					resources = append(resources, resource)
+				} else if err := WriteUpdateService(release, graph, dir); err != nil {
 					return err
 				}
 			}
@@ -569,32 +929,77 @@ func (o *MirrorOptions) generateResults(mapping image.TypedImageMapping, dir str
 
 	ctlgRefs := image.ByCategory(mapping, v1alpha2.TypeOperatorCatalog)
 	if len(ctlgRefs) != 0 {
-		if err := WriteCatalogSource(ctlgRefs, dir); err != nil {
+		if o.ManifestOutput != "" {
+			catalogSources, err := catalogSourceResources(ctlgRefs)
+			if err != nil {
+				return err
+			}
+			resources = append(resources, catalogSources...)
+		} else if err := WriteCatalogSource(ctlgRefs, dir); err != nil {
 			return err
 		}
 	}
 
+	if o.samples != nil {
+		resource, err := samplesConfigResource(*o.samples, o.samplesRegistry())
+		if err != nil {
+			return err
+		}
+		if o.ManifestOutput != "" {
+			resources = append(resources, resource)
+		} else {
+			klog.Infof("Writing samples operator configuration to %s", dir)
+			if err := os.WriteFile(filepath.Join(dir, samplesConfigFile), resource.data, os.ModePerm); err != nil {
+				return fmt.Errorf("error writing samples Config: %v", err)
+			}
+		}
+	}
+
 	if err := getICSP(releases, "release", namespaceICSPScope, &ReleaseBuilder{}); err != nil {
 		return err
 	}
 	if err := getICSP(generic, "generic", namespaceICSPScope, &GenericBuilder{}); err != nil {
 		return err
 	}
+	operatorScope := namespaceICSPScope
 	if o.MaxNestedPaths > 0 {
-		if err := getICSP(operator, "operator", repositoryICSPScope, &OperatorBuilder{}); err != nil {
+		operatorScope = repositoryICSPScope
+	}
+	// The images of the rebuilt catalogs get ICSPs labeled with their catalog,
+	// the operator images of no known catalog are kept in the operator ICSPs.
+	byCatalog, operator := splitByCatalog(operator, o.catalogImages)
+	if err := getICSP(operator, "operator", operatorScope, &OperatorBuilder{}); err != nil {
+		return err
+	}
+	for _, catalog := range sortedCatalogs(byCatalog) {
+		if err := getICSP(byCatalog[catalog], "operator-"+catalog, operatorScope, &OperatorBuilder{Catalog: catalog}); err != nil {
 			return err
 		}
-	} else {
-		if err := getICSP(operator, "operator", namespaceICSPScope, &OperatorBuilder{}); err != nil {
+	}
+
+	budget, err := o.icspBudgetBytes()
+	if err != nil {
+		return err
+	}
+	if budget != 0 {
+		if allICSPs, err = consolidateICSPs(allICSPs, budget, icspSizeLimit); err != nil {
 			return err
 		}
 	}
 
-	return WriteICSPs(dir, allICSPs)
+	if o.ManifestOutput == "" {
+		return WriteICSPs(dir, allICSPs)
+	}
+	icsps, err := icspResources(allICSPs)
+	if err != nil {
+		return err
+	}
+	resources = append(resources, icsps...)
+	return writeClusterResources(dir, o.ManifestOutput, resources)
 }
 
-// moveToResults will move release signatures and helm charts to
-// the specified results directory from the defined source directory
+// moveToResults will move release signatures, helm charts and operator
+// CLI downloads to the specified results directory from the defined source directory
 // in the config package.
 func (o *MirrorOptions) moveToResults(resultsDir string) error {
 
@@ -614,6 +1019,14 @@ func (o *MirrorOptions) moveToResults(resultsDir string) error {
 		return err
 	}
 	klog.V(1).Infof("Moved any downloaded Helm charts to %s", resultsDir)
+
+	// Move operator CLI downloads into results dir
+	srcCLIPath := filepath.Join(o.Dir, config.SourceDir, config.CLIDownloadsDir)
+	dstCLIPath := filepath.Join(resultsDir, config.CLIDownloadsDir)
+	if err := os.Rename(srcCLIPath, dstCLIPath); err != nil {
+		return err
+	}
+	klog.V(1).Infof("Moved any operator CLI downloads to %s", resultsDir)
 	return nil
 }
 
@@ -653,19 +1066,141 @@ func (o *MirrorOptions) writeMappingFile(mappingPath string, mapping image.Typed
 	return mappingFile.Sync()
 }
 
-func (o *MirrorOptions) mirrorToMirrorWrapper(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, cleanup cleanupFunc) error {
-	destInsecure := o.DestPlainHTTP || o.DestSkipTLS
-	srcInsecure := o.SourcePlainHTTP || o.SourceSkipTLS
-
-	mappingPath := filepath.Join(o.Dir, mappingFile)
-
+func (o *MirrorOptions) mirrorToMirrorWrapper(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, cleanup cleanupFunc) (err error) {
+	o.summary = runSummary{Operation: operationMirror, Destination: o.destinationsString()}
+	o.namespaceMappings = cfg.Mirror.NamespaceMappings
 	if err := bundle.MakeWorkspaceDirs(o.Dir); err != nil {
 		return err
 	}
+	stopPlan := o.startPhase(phasePlan)
 	meta, mapping, err := o.Create(ctx, cfg)
+	stopPlan()
 	if err != nil {
+		if errors.Is(err, ErrNoUpdatesExist) {
+			klog.Infof("No updates detected, process stopping")
+			return nil
+		}
+		return err
+	}
+	defer o.prevAssociations.Close()
+	defer func() {
+		// Record the interruption in the metadata of the workspace.
+		if err != nil && ctx.Err() != nil {
+			o.recordConfigInterruption(ctx, cfg, meta.PastMirror.Sequence, len(mapping))
+		}
+	}()
+	if err := o.writeEgressAllowlistFile(mapping); err != nil {
+		return err
+	}
+	o.summary.Sequence, o.summary.Images = meta.PastMirror.Sequence, len(mapping)
+
+	// Fan out to every destination registry. Each destination gets its
+	// own copy of the mapping and metadata, so a destination mirrored
+	// earlier in the run does not hide images from the next one.
+	var sourceBackend storage.Backend
+	if cfg.StorageConfig.IsSet() && !o.DryRun {
+		if sourceBackend, err = storage.ByConfig(o.Dir, cfg.StorageConfig); err != nil {
+			return err
+		}
+	}
+	var resultsDir string
+	// The metadata mirrored to each destination, keyed by destination.
+	mirroredMetas := map[string]v1alpha2.Metadata{}
+	var mirroredMeta v1alpha2.Metadata
+	var mirroredAssocs image.StreamedAssociations
+	defer func() { mirroredAssocs.Close() }()
+	for _, dest := range o.mirrorDestinations() {
+		o.setDestination(dest)
+		if resultsDir == "" && !o.DryRun {
+			if resultsDir, err = o.createResultsDir(); err != nil {
+				return err
+			}
+		}
+		destMeta, destAssocs, targetBackend, err := o.mirrorToDestination(ctx, cfg, meta, copyMapping(mapping), resultsDir)
+		if err != nil {
+			if errors.Is(err, ErrNoUpdatesExist) {
+				klog.Infof("No new images detected for %s, skipping", dest)
+				continue
+			}
+			return err
+		}
+		if o.DryRun {
+			continue
+		}
+		mirroredAssocs.Close()
+		mirroredAssocs = destAssocs
+		mirroredMeta = destMeta
+
+		// Sync the metadata of the destination from disk to the source
+		// backend and to the backend of the destination, so each destination
+		// stores the associations mirrored to it.
+		if sourceBackend != nil {
+			workspace := filepath.Join(o.Dir, config.SourceDir)
+			if err := metadata.UpdateMetadata(ctx, sourceBackend, &destMeta, destAssocs.Iterators(), workspace, o.SourceSkipTLS, o.SourcePlainHTTP); err != nil {
+				return err
+			}
+			if err := metadata.SyncMetadata(ctx, sourceBackend, targetBackend); err != nil {
+				return err
+			}
+		}
+		mirroredMetas[dest.String()] = destMeta
+	}
+
+	if o.DryRun {
+		return cleanup()
+	}
+	if len(mirroredMetas) == 0 {
+		klog.Infof("No new images detected, process stopping")
+		return nil
+	}
+
+	if len(cfg.Mirror.Operators) > 0 && o.ExportCatalogs {
+		if err := o.exportCatalogs(filepath.Join(o.Dir, config.SourceDir), resultsDir); err != nil {
+			return fmt.Errorf("error exporting file-based catalogs: %v", err)
+		}
+	}
+
+	if err := o.moveToResults(resultsDir); err != nil {
 		return err
 	}
+	if err := o.publishSignatureConfigMaps(ctx, filepath.Join(resultsDir, config.ReleaseSignatureDir), resultsDir); err != nil {
+		return err
+	}
+	o.recordSequence(mirroredMeta, mirroredAssocs[image.PastAssociationsField], "", nil)
+	return cleanup()
+}
+
+/*
+mirrorToDestination mirrors the images of a mirror to mirror run to the current
+destination registry, then writes the mapping, ICSP and catalog source files of
+that destination to its results directory.
+
+# Arguments
+
+• ctx: a cancellation context
+
+• cfg: the imageset configuration of the run
+
+• meta: the metadata created for the run
+
+• mapping: the source to disk mapping of the run, it is changed to point to the destination
+
+• resultsDir: the results directory of the run, unused in dry run mode
+
+# Returns
+
+• v1alpha2.Metadata: the metadata of the run mirrored to the destination
+
+• image.StreamedAssociations: the pruned associations of the previous sequences and the
+associations mirrored to the destination, by metadata path, nil in dry run mode
+
+• storage.Backend: the metadata backend of the destination
+
+• error: ErrNoUpdatesExist if the destination is up to date, non-nil if an error occurs, nil otherwise
+*/
+func (o *MirrorOptions) mirrorToDestination(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, meta v1alpha2.Metadata, mapping image.TypedImageMapping, resultsDir string) (_ v1alpha2.Metadata, streamed image.StreamedAssociations, _ storage.Backend, err error) {
+	destInsecure := o.DestPlainHTTP || o.DestSkipTLS
+	srcInsecure := o.SourcePlainHTTP || o.SourceSkipTLS
 
 	// Imageset sequence check
 	metaImage := o.newMetadataImage(meta.Uid.String())
@@ -676,137 +1211,178 @@ func (o *MirrorOptions) mirrorToMirrorWrapper(ctx context.Context, cfg v1alpha2.
 
 	targetBackend, err := storage.NewRegistryBackend(targetCfg, o.Dir)
 	if err != nil {
-		return err
+		return meta, nil, nil, err
 	}
 
 	var curr v1alpha2.Metadata
-	berr := targetBackend.ReadMetadata(ctx, &curr, config.MetadataBasePath)
+	berr := storage.ReadMetadataWithoutAssociations(ctx, targetBackend, &curr, config.MetadataBasePath)
 	if !o.SkipPruning {
 		if err := o.checkSequence(meta, curr, berr); err != nil {
-			return err
+			return meta, nil, nil, err
 		}
 	}
 
 	// Change the destination to registry
 	// TODO(jpower432): Investigate whether oc can produce
 	// registry to registry mapping
+	o.applyNamespaceMappings(mapping)
 	mapping.ToRegistry(o.ToMirror, o.UserNamespace)
+	if err := o.applyTagTemplates(mapping); err != nil {
+		return meta, nil, nil, err
+	}
 
-	prunedAssociations, err := o.removePreviouslyMirrored(mapping, meta)
+	prunedAssociations, err := o.removePreviouslyMirrored(mapping, o.prevAssociations, o.destinationAssociationsDB(prunedAssociationsDB))
 	if err != nil {
-		if errors.Is(err, ErrNoUpdatesExist) {
-			klog.Infof("No new images detected, process stopping")
-			return nil
+		return meta, nil, nil, err
+	}
+	defer func() {
+		// Unless returned with the associations mirrored to the destination.
+		if streamed == nil {
+			prunedAssociations.Close()
+		}
+	}()
+
+	if !o.DryRun && o.MaxDownloadSize != "" {
+		size, err := o.estimateMappingSize(ctx, mapping, srcInsecure)
+		if err != nil {
+			return meta, nil, nil, fmt.Errorf("error estimating the size of the images: %v", err)
+		}
+		if err := o.checkDownloadSize(size); err != nil {
+			return meta, nil, nil, err
 		}
-		return err
 	}
 
 	// QUESTION(jpower432): Can you specify different TLS configuration for source
 	// and destination with `oc image mirror`?
-	if err := o.mirrorMappings(cfg, mapping, destInsecure || srcInsecure); err != nil {
-		return err
-	}
-
-	prevAssociations, err := image.ConvertToAssociationSet(meta.PastAssociations)
-	if err != nil {
-		return err
+	if err := o.mirrorMappings(ctx, cfg, mapping, destInsecure || srcInsecure); err != nil {
+		return meta, nil, nil, err
 	}
 
 	if o.DryRun {
+		mappingPath, err := o.destinationPath(o.Dir, mappingFile)
+		if err != nil {
+			return meta, nil, nil, err
+		}
 		if err := o.writeMappingFile(mappingPath, mapping); err != nil {
-			return err
+			return meta, nil, nil, err
 		}
-		if err := o.outputPruneImagePlan(ctx, prevAssociations, prunedAssociations); err != nil {
-			return err
+		if err := o.writeMappingFormatFile(filepath.Dir(mappingPath), mapping); err != nil {
+			return meta, nil, nil, err
 		}
-		return cleanup()
+		if err := o.outputPruneImagePlan(ctx, o.prevAssociations, prunedAssociations); err != nil {
+			return meta, nil, nil, err
+		}
+		return meta, nil, targetBackend, nil
 	}
 
-	assocs, errs := image.AssociateRemoteImageLayers(ctx, mapping, o.SourceSkipTLS, o.SourcePlainHTTP, o.SkipVerification)
+	mirrored, errs := image.AssociateRemoteImageLayers(ctx, mapping, o.SourceSkipTLS, o.SourcePlainHTTP, o.SkipVerification)
 	if errs != nil {
 		if err := o.processAssociationErrors(errs.Errors()); err != nil {
-			return err
+			return meta, nil, nil, err
 		}
 	}
 
 	// Prune the images that differ between the previous Associations and the
 	// pruned Associations.
-	meta.PastMirror.Associations, err = image.ConvertFromAssociationSet(assocs)
-	if err != nil {
-		return err
+	if err := prunedAssociations.Merge(mirrored); err != nil {
+		return meta, nil, nil, err
 	}
-	prunedAssociations.Merge(assocs)
 
-	if err := o.pruneRegistry(ctx, prevAssociations, prunedAssociations); err != nil {
-		return fmt.Errorf("error pruning from registry %q: %v", o.ToMirror, err)
+	if err := o.pruneRegistry(ctx, o.prevAssociations, prunedAssociations); err != nil {
+		return meta, nil, nil, fmt.Errorf("error pruning from registry %q: %v", o.ToMirror, err)
 	}
 
-	meta.PastAssociations, err = image.ConvertFromAssociationSet(prunedAssociations)
+	artifactMappings, err := o.pushArtifacts(ctx, filepath.Join(o.Dir, config.SourceDir, config.ArtifactsDir))
 	if err != nil {
-		return err
+		return meta, nil, nil, fmt.Errorf("error pushing artifacts to %q: %v", o.ToMirror, err)
 	}
+	mapping.Merge(artifactMappings)
 
-	dir, err := o.createResultsDir()
-	if err != nil {
-		return err
+	if o.PushReleaseSignatures {
+		srcSignatureDir := filepath.Join(o.Dir, config.SourceDir, config.ReleaseSignatureDir)
+		if err := o.pushReleaseSignatures(ctx, srcSignatureDir, mapping); err != nil {
+			return meta, nil, nil, fmt.Errorf("error pushing release signatures to %q: %v", o.ToMirror, err)
+		}
 	}
+
 	// process catalog FBC images
 	if len(cfg.Mirror.Operators) > 0 {
 		ctlgRefs, err := o.rebuildOrCopyCatalogs(ctx, filepath.Join(o.Dir, config.SourceDir))
 		if err != nil {
-			return fmt.Errorf("error rebuilding catalog images from file-based catalogs: %v", err)
+			return meta, nil, nil, fmt.Errorf("error rebuilding catalog images from file-based catalogs: %v", err)
 		}
 		mapping.Merge(ctlgRefs)
 	}
 	// process Cincinnati graph data image
-	if len(cfg.Mirror.Platform.Channels) > 0 {
+	if o.hasReleases(cfg) {
 		if cfg.Mirror.Platform.Graph {
 
 			// copy signatures to Cincinnati graph data directory
 			srcSignatureDir := filepath.Join(o.Dir, config.SourceDir, config.ReleaseSignatureDir)
 			graphRef, err := o.buildGraphImage(ctx, srcSignatureDir, filepath.Join(o.Dir, config.SourceDir))
 			if err != nil {
-				return fmt.Errorf("error building cincinnati graph image: %v", err)
+				return meta, nil, nil, fmt.Errorf("error building cincinnati graph image: %v", err)
 			}
 			mapping.Merge(graphRef)
 		}
 	}
 
-	if err := o.generateResults(mapping, dir); err != nil {
-		return err
+	if o.PreserveDigests {
+		if err := o.verifyPreservedDigests(ctx, mapping, srcInsecure, destInsecure); err != nil {
+			return meta, nil, nil, err
+		}
 	}
 
-	if err := o.moveToResults(dir); err != nil {
-		return err
+	dir, err := o.destinationPath(resultsDir, "")
+	if err != nil {
+		return meta, nil, nil, err
+	}
+	if err := o.generateResults(mapping, dir); err != nil {
+		return meta, nil, nil, err
 	}
 
-	// Sync metadata from disk to source and target backends
-	if cfg.StorageConfig.IsSet() {
-		sourceBackend, err := storage.ByConfig(o.Dir, cfg.StorageConfig)
-		if err != nil {
-			return err
-		}
-		workspace := filepath.Join(o.Dir, config.SourceDir)
-		if err = metadata.UpdateMetadata(ctx, sourceBackend, &meta, workspace, o.SourceSkipTLS, o.SourcePlainHTTP); err != nil {
-			return err
-		}
-		if err := metadata.SyncMetadata(ctx, sourceBackend, targetBackend); err != nil {
-			return err
-		}
+	mirroredAssociations, err := image.OpenAssociationDB(o.associationsDBPath(o.destinationAssociationsDB(mirroredAssociationsDB)))
+	if err != nil {
+		return meta, nil, nil, err
 	}
-	return cleanup()
+	if err := mirroredAssociations.Merge(mirrored); err != nil {
+		mirroredAssociations.Close()
+		return meta, nil, nil, err
+	}
+	streamed = image.StreamedAssociations{
+		image.PastAssociationsField:     prunedAssociations,
+		image.MirroredAssociationsField: mirroredAssociations,
+	}
+	return meta, streamed, targetBackend, nil
 }
 
 // mirrorToDiskWrapper
-func (o *MirrorOptions) mirrorToDiskWrapper(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, cleanup cleanupFunc) error {
+func (o *MirrorOptions) mirrorToDiskWrapper(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, cleanup cleanupFunc) (err error) {
+	o.summary = runSummary{Operation: operationCreate}
 	sourceInsecure := o.SourcePlainHTTP || o.SourceSkipTLS
 
 	if err := bundle.MakeWorkspaceDirs(o.Dir); err != nil {
 		return err
 	}
 
+	stopPlan := o.startPhase(phasePlan)
 	meta, mapping, err := o.Create(ctx, cfg)
+	stopPlan()
 	if err != nil {
+		if errors.Is(err, ErrNoUpdatesExist) {
+			klog.Infof("No updates detected, process stopping")
+			return nil
+		}
+		return err
+	}
+	defer o.prevAssociations.Close()
+	defer func() {
+		// Record the interruption in the metadata of the workspace.
+		if err != nil && ctx.Err() != nil {
+			o.recordConfigInterruption(ctx, cfg, meta.PastMirror.Sequence, len(mapping))
+		}
+	}()
+	if err := o.writeEgressAllowlistFile(mapping); err != nil {
 		return err
 	}
 
@@ -851,16 +1427,36 @@ func (o *MirrorOptions) mirrorToDiskWrapper(ctx context.Context, cfg v1alpha2.Im
 		}
 	}
 	// End Fix OCPBUGS-2633
-	prunedAssociations, err := o.removePreviouslyMirrored(mapping, meta)
-	if err != nil {
-		if errors.Is(err, ErrNoUpdatesExist) {
-			klog.Infof("No new images detected, process stopping")
-			return nil
+	// A seed archive holds every image of the run, not only the new ones.
+	var prunedAssociations *image.AssociationDB
+	if o.SeedFormat == "" {
+		prunedAssociations, err = o.removePreviouslyMirrored(mapping, o.prevAssociations, prunedAssociationsDB)
+		if err != nil {
+			if errors.Is(err, ErrNoUpdatesExist) {
+				klog.Infof("No new images detected, process stopping")
+				return nil
+			}
+			return err
 		}
-		return err
+		defer prunedAssociations.Close()
 	}
+	o.summary.Sequence, o.summary.Images = meta.PastMirror.Sequence, len(mapping)
 
-	if err := o.mirrorMappings(cfg, mapping, sourceInsecure); err != nil {
+	if !o.DryRun {
+		size, err := o.estimateMappingSize(ctx, mapping, sourceInsecure)
+		if err != nil {
+			return fmt.Errorf("error estimating the size of the images: %v", err)
+		}
+		klog.Infof("Estimated size of the images to mirror: %s", formatGiB(size.total))
+		if err := o.checkDownloadSize(size); err != nil {
+			return err
+		}
+		if err := o.checkFreeSpace(size.total); err != nil {
+			return err
+		}
+	}
+
+	if err := o.mirrorMappings(ctx, cfg, mapping, sourceInsecure); err != nil {
 		return err
 	}
 
@@ -869,9 +1465,18 @@ func (o *MirrorOptions) mirrorToDiskWrapper(ctx context.Context, cfg v1alpha2.Im
 		if err := o.writeMappingFile(mappingPath, mapping); err != nil {
 			return err
 		}
+		if err := o.writeMappingFormatFile(o.Dir, mapping); err != nil {
+			return err
+		}
 		return cleanup()
 	}
 
+	if o.SeedFormat != "" {
+		stopPack := o.startPhase(phasePack)
+		defer stopPack()
+		return o.writeSeedArchive(mapping)
+	}
+
 	// Create and store associations
 	assocDir := filepath.Join(o.Dir, config.SourceDir)
 	assocs, errs := image.AssociateLocalImageLayers(assocDir, mapping)
@@ -882,7 +1487,10 @@ func (o *MirrorOptions) mirrorToDiskWrapper(ctx context.Context, cfg v1alpha2.Im
 	}
 
 	// Pack the images set
+	o.archiveCompression = cfg.ArchiveCompression
+	stopPack := o.startPhase(phasePack)
 	tmpBackend, err := o.Pack(ctx, prunedAssociations, assocs, &meta, cfg.ArchiveSize)
+	stopPack()
 	if err != nil {
 		if errors.Is(err, ErrNoUpdatesExist) {
 			klog.Infof("No updates detected, process stopping")
@@ -891,6 +1499,8 @@ func (o *MirrorOptions) mirrorToDiskWrapper(ctx context.Context, cfg v1alpha2.Im
 		return err
 	}
 
+	o.summary.Archives = archivesOfSequence(o.OutputDir, meta.PastMirror.Sequence)
+
 	// Sync metadata from temporary backend to target backend
 	if cfg.StorageConfig.IsSet() {
 		targetBackend, err := storage.ByConfig(o.Dir, cfg.StorageConfig)
@@ -909,7 +1519,10 @@ func (o *MirrorOptions) diskToMirrorWrapper(ctx context.Context, cleanup cleanup
 	// this takes care of syncing the metadata to the
 	// registry backends.
 
+	stopPublish := o.startPhase(phasePublish)
 	mapping, err := o.Publish(ctx)
+	stopPublish()
+	o.summary.Images = len(mapping)
 	if err != nil {
 		// OCPBUGS-4959 for automation processes to end gracefully
 		// when we have the same sequence - i.e nothing to do
@@ -930,13 +1543,23 @@ func (o *MirrorOptions) diskToMirrorWrapper(ctx context.Context, cleanup cleanup
 		if err := o.writeMappingFile(mappingPath, mapping); err != nil {
 			return err
 		}
+		if err := o.writeMappingFormatFile(o.Dir, mapping); err != nil {
+			return err
+		}
 		return cleanup()
 	}
 
+	if o.PushReleaseSignatures {
+		srcSignatureDir := filepath.Join(o.OutputDir, config.ReleaseSignatureDir)
+		if err := o.pushReleaseSignatures(ctx, srcSignatureDir, mapping); err != nil {
+			return fmt.Errorf("error pushing release signatures to %q: %v", o.ToMirror, err)
+		}
+	}
+
 	if err := o.generateResults(mapping, o.OutputDir); err != nil {
 		return err
 	}
-	return nil
+	return o.publishSignatureConfigMaps(ctx, filepath.Join(o.OutputDir, config.ReleaseSignatureDir), o.OutputDir)
 }
 
 func (o *MirrorOptions) processNestedPaths(ref *image.TypedImage) imagesource.TypedImageReference {
@@ -73,6 +73,23 @@ func TestMirrorComplete(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Valid/FileDestWorkspace",
+			args: []string{"file://foo"},
+			opts: &MirrorOptions{
+				RootOptions: &cli.RootOptions{
+					Dir: "bar",
+				},
+				Workspace: "/scratch/ws/",
+			},
+			expOpts: &MirrorOptions{
+				OutputDir: "foo",
+				RootOptions: &cli.RootOptions{
+					Dir: "/scratch/ws",
+				},
+				Workspace: "/scratch/ws/",
+			},
+		},
 		{
 			name: "Valid/RegDest",
 			args: []string{"docker://reg.com"},
@@ -212,6 +229,54 @@ func TestMirrorComplete(t *testing.T) {
 			opts:     &MirrorOptions{},
 			expError: `"" is not a valid image reference: repository name must have at least one component`,
 		},
+		{
+			name: "Valid/AdditionalRegDests",
+			args: []string{"docker://reg.com/foo"},
+			opts: &MirrorOptions{AdditionalMirrors: []string{"docker://reg2.com:5000/bar"}},
+			expOpts: &MirrorOptions{
+				ToMirror:          "reg.com",
+				UserNamespace:     "foo",
+				AdditionalMirrors: []string{"docker://reg2.com:5000/bar"},
+				destinations: []mirrorDestination{
+					{Registry: "reg.com", Namespace: "foo"},
+					{Registry: "reg2.com:5000", Namespace: "bar"},
+				},
+			},
+		},
+		{
+			name:     "Invalid/AdditionalRegDestWithFileDest",
+			args:     []string{"file://foo"},
+			opts:     &MirrorOptions{RootOptions: &cli.RootOptions{}, AdditionalMirrors: []string{"docker://reg2.com"}},
+			expError: "--to can only be used with a docker:// destination",
+		},
+		{
+			name:     "Invalid/AdditionalRegDestNoScheme",
+			args:     []string{"docker://reg.com"},
+			opts:     &MirrorOptions{AdditionalMirrors: []string{"reg2.com"}},
+			expError: `invalid --to destination: destination "reg2.com" must use the docker:// scheme`,
+		},
+		{
+			name:     "Invalid/DuplicateRegDest",
+			args:     []string{"docker://reg.com/foo"},
+			opts:     &MirrorOptions{AdditionalMirrors: []string{"docker://reg.com/foo"}},
+			expError: `destination "docker://reg.com/foo" is specified more than once`,
+		},
+		{
+			name: "Valid/Annotations",
+			args: []string{"docker://reg.com"},
+			opts: &MirrorOptions{Annotations: []string{"ticket=OPS-1234", "note=a=b"}},
+			expOpts: &MirrorOptions{
+				ToMirror:    "reg.com",
+				Annotations: []string{"ticket=OPS-1234", "note=a=b"},
+				annotations: map[string]string{"ticket": "OPS-1234", "note": "a=b"},
+			},
+		},
+		{
+			name:     "Invalid/Annotation",
+			args:     []string{"docker://reg.com"},
+			opts:     &MirrorOptions{Annotations: []string{"ticket"}},
+			expError: `invalid annotation "ticket", expected key=value`,
+		},
 		{
 			name:     "Invalid/EmptyScheme",
 			args:     []string{"://foo"},
@@ -246,6 +311,7 @@ func TestMirrorComplete(t *testing.T) {
 }
 
 func TestMirrorValidate(t *testing.T) {
+	t.Setenv(quayAPITokenEnv, "")
 
 	server := httptest.NewServer(registry.New())
 	t.Cleanup(server.Close)
@@ -289,6 +355,197 @@ func TestMirrorValidate(t *testing.T) {
 			},
 			expError: `must specify a configuration file with --config`,
 		},
+		{
+			name: "Invalid/ManifestOutput",
+			opts: &MirrorOptions{
+				OutputDir:      t.TempDir(),
+				ConfigPath:     "testdata/configs/iscfg.yaml",
+				ManifestOutput: "kustomize",
+			},
+			expError: `--manifest-output must be "single-file" or "per-resource"`,
+		},
+		{
+			name: "Invalid/MaxArchiveFiles",
+			opts: &MirrorOptions{
+				OutputDir:       t.TempDir(),
+				ConfigPath:      "testdata/configs/iscfg.yaml",
+				MaxArchiveFiles: -1,
+			},
+			expError: `--max-archive-files must not be negative`,
+		},
+		{
+			name: "Invalid/CompatFormatArchivesPerDir",
+			opts: &MirrorOptions{
+				OutputDir:      t.TempDir(),
+				ConfigPath:     "testdata/configs/iscfg.yaml",
+				CompatFormat:   "v1",
+				ArchivesPerDir: 4,
+			},
+			expError: `--archives-per-dir is not supported by imageset format v1`,
+		},
+		{
+			name: "Invalid/PreserveDigestsNoRegistry",
+			opts: &MirrorOptions{
+				OutputDir:       t.TempDir(),
+				ConfigPath:      "testdata/configs/iscfg.yaml",
+				PreserveDigests: true,
+			},
+			expError: `--preserve-digests requires a registry destination`,
+		},
+		{
+			name: "Invalid/ApplySignaturesKubeconfigNoRegistry",
+			opts: &MirrorOptions{
+				OutputDir:                 t.TempDir(),
+				ConfigPath:                "testdata/configs/iscfg.yaml",
+				ApplySignaturesKubeconfig: "kubeconfig",
+			},
+			expError: `--apply-signatures-kubeconfig requires a registry destination`,
+		},
+		{
+			name: "Invalid/SeedFormat",
+			opts: &MirrorOptions{
+				OutputDir:  t.TempDir(),
+				ConfigPath: "testdata/configs/iscfg.yaml",
+				SeedFormat: "tar",
+			},
+			expError: `--seed-format must be "docker-archive" or "oci-archive"`,
+		},
+		{
+			name: "Invalid/SeedFormatNoFileDestination",
+			opts: &MirrorOptions{
+				ConfigPath: "testdata/configs/iscfg.yaml",
+				ToMirror:   "localhost:5000",
+				SeedFormat: seedFormatOCIArchive,
+			},
+			expError: `--seed-format requires a file destination`,
+		},
+		{
+			name: "Invalid/OCIMediaTypesNoFrom",
+			opts: &MirrorOptions{
+				ConfigPath:    "testdata/configs/iscfg.yaml",
+				ToMirror:      "localhost:5000",
+				OCIMediaTypes: true,
+			},
+			expError: `--oci-media-types requires --from`,
+		},
+		{
+			name: "Invalid/OCIMediaTypesPreserveDigests",
+			opts: &MirrorOptions{
+				From:            t.TempDir(),
+				ToMirror:        "localhost:5000",
+				OCIMediaTypes:   true,
+				PreserveDigests: true,
+			},
+			expError: `--oci-media-types and --preserve-digests are mutually exclusive`,
+		},
+		{
+			name: "Invalid/QuayCreateRepositoriesNoRegistry",
+			opts: &MirrorOptions{
+				OutputDir:              t.TempDir(),
+				ConfigPath:             "testdata/configs/iscfg.yaml",
+				QuayCreateRepositories: true,
+			},
+			expError: `--quay-create-repositories requires a registry destination`,
+		},
+		{
+			name: "Invalid/QuayCreateRepositoriesNoToken",
+			opts: &MirrorOptions{
+				From:                   t.TempDir(),
+				ToMirror:               "localhost:5000",
+				QuayCreateRepositories: true,
+			},
+			expError: `--quay-create-repositories requires an OAuth token of the Quay API in QUAY_API_TOKEN`,
+		},
+		{
+			name: "Invalid/CreateReposNoRegistry",
+			opts: &MirrorOptions{
+				OutputDir:   t.TempDir(),
+				ConfigPath:  "testdata/configs/iscfg.yaml",
+				CreateRepos: true,
+			},
+			expError: `--create-repos requires a registry destination`,
+		},
+		{
+			name: "Invalid/ReleaseDigestsNoConfig",
+			opts: &MirrorOptions{
+				From:           t.TempDir(),
+				ToMirror:       "localhost:5000",
+				ReleaseDigests: "release-digests.txt",
+			},
+			expError: `--release-digests requires --config`,
+		},
+		{
+			name: "Invalid/OperatorDependencies",
+			opts: &MirrorOptions{
+				From:                 t.TempDir(),
+				ToMirror:             "localhost:5000",
+				OperatorDependencies: "lenient",
+			},
+			expError: `--operator-dependencies must be "permissive" or "strict"`,
+		},
+		{
+			name: "Invalid/MaxDownloadSizeNoConfig",
+			opts: &MirrorOptions{
+				From:            t.TempDir(),
+				ToMirror:        "localhost:5000",
+				MaxDownloadSize: "50Gi",
+			},
+			expError: `--max-download-size requires --config`,
+		},
+		{
+			name: "Invalid/MaxDownloadSizeQuantity",
+			opts: &MirrorOptions{
+				OutputDir:       t.TempDir(),
+				ConfigPath:      "testdata/configs/iscfg.yaml",
+				MaxDownloadSize: "50 gigs",
+			},
+			expError: `invalid --max-download-size "50 gigs": quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'`,
+		},
+		{
+			name: "Invalid/UploadChunkSizeNotPositive",
+			opts: &MirrorOptions{
+				From:            t.TempDir(),
+				ToMirror:        "localhost:5000",
+				UploadChunkSize: "0",
+			},
+			expError: `--upload-chunk-size must be positive`,
+		},
+		{
+			name: "Invalid/MaxConcurrentUploadsNegative",
+			opts: &MirrorOptions{
+				From:                 t.TempDir(),
+				ToMirror:             "localhost:5000",
+				MaxConcurrentUploads: -1,
+			},
+			expError: `--max-concurrent-uploads must not be negative`,
+		},
+		{
+			name: "Invalid/ICSPBudgetNotPositive",
+			opts: &MirrorOptions{
+				From:       t.TempDir(),
+				ToMirror:   "localhost:5000",
+				ICSPBudget: "0",
+			},
+			expError: `--icsp-budget must be positive`,
+		},
+		{
+			name: "Invalid/SkipTLSVerifyRegistryIPAddress",
+			opts: &MirrorOptions{
+				OutputDir:               t.TempDir(),
+				ConfigPath:              "testdata/configs/iscfg.yaml",
+				SourceSkipTLSRegistries: []string{"10.0.0.5:5000"},
+			},
+			expError: `insecure registry "10.0.0.5:5000" must be a host name, not an IP address`,
+		},
+		{
+			name: "Invalid/RegistryOverridesNotFound",
+			opts: &MirrorOptions{
+				OutputDir:         t.TempDir(),
+				ConfigPath:        "testdata/configs/iscfg.yaml",
+				RegistryOverrides: "testdata/registries-not-found.conf",
+			},
+			expError: "error reading registries configuration testdata/registries-not-found.conf: open testdata/registries-not-found.conf: no such file or directory",
+		},
 		{
 			name: "Valid/ManifestOnlyWithFakeMirror",
 			opts: &MirrorOptions{
@@ -433,6 +690,78 @@ func TestRemovePreviouslyMirrored(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Valid/BaselineImage",
+			opts: &MirrorOptions{
+				RootOptions: &cli.RootOptions{
+					Dir: "bar",
+				},
+				baselineExcluded: []string{
+					"test-registry/imgname@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+					"test-registry/other@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+				},
+			},
+			expSet: image.AssociationSet{"test-registry/imgname@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19": image.Associations{
+				"test-registry/imgname@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19": {
+					Name:            "test-registry/imgname@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+					Path:            "single_manifest",
+					TagSymlink:      "latest",
+					ID:              "sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+					Type:            v1alpha2.TypeGeneric,
+					ManifestDigests: nil,
+					LayerDigests: []string{
+						"sha256:e8614d09b7bebabd9d8a450f44e88a8807c98a438a2ddd63146865286b132d1b",
+						"sha256:601401253d0aac2bc95cccea668761a6e69216468809d1cee837b2e8b398e241",
+						"sha256:211941188a4f55ffc6bcefa4f69b69b32c13fafb65738075de05808bbfcec086",
+						"sha256:f0fd5be261dfd2e36d01069a387a3e5125f5fd5adfec90f3cb190d1d5f1d1ad9",
+						"sha256:0c0beb258254c0566315c641b4107b080a96fa78d4f96833453dd6c5b9edf2b7",
+						"sha256:30c794a11b4c340c77238c5b7ca845752904bd8b74b73a9b16d31253234da031",
+					},
+				},
+			}},
+			images: image.TypedImageMapping{
+				{TypedImageReference: image.TypedImageReference{
+					Ref: reference.DockerImageReference{
+						Registry: "test-registry",
+						Name:     "imgname",
+						ID:       "sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df17",
+					},
+					Type: imagesource.DestinationRegistry,
+				},
+					Category: v1alpha2.TypeOCPRelease}: {
+					TypedImageReference: image.TypedImageReference{
+						Ref: reference.DockerImageReference{
+							Registry: "test-registry",
+							Name:     "imgname",
+							ID:       "sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df17",
+						},
+						Type: imagesource.DestinationRegistry,
+					},
+					Category: v1alpha2.TypeOCPRelease},
+			},
+			meta: v1alpha2.Metadata{
+				MetadataSpec: v1alpha2.MetadataSpec{
+					PastAssociations: []v1alpha2.Association{
+						{
+							Name:            "test-registry/imgname@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+							Path:            "single_manifest",
+							TagSymlink:      "latest",
+							ID:              "sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+							Type:            v1alpha2.TypeGeneric,
+							ManifestDigests: nil,
+							LayerDigests: []string{
+								"sha256:e8614d09b7bebabd9d8a450f44e88a8807c98a438a2ddd63146865286b132d1b",
+								"sha256:601401253d0aac2bc95cccea668761a6e69216468809d1cee837b2e8b398e241",
+								"sha256:211941188a4f55ffc6bcefa4f69b69b32c13fafb65738075de05808bbfcec086",
+								"sha256:f0fd5be261dfd2e36d01069a387a3e5125f5fd5adfec90f3cb190d1d5f1d1ad9",
+								"sha256:0c0beb258254c0566315c641b4107b080a96fa78d4f96833453dd6c5b9edf2b7",
+								"sha256:30c794a11b4c340c77238c5b7ca845752904bd8b74b73a9b16d31253234da031",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Failure/NoNewImages",
 			opts: &MirrorOptions{
@@ -485,15 +814,77 @@ func TestRemovePreviouslyMirrored(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Valid/ForceFull",
+			opts: &MirrorOptions{
+				RootOptions: &cli.RootOptions{
+					Dir: "bar",
+				},
+				ForceFull: true,
+			},
+			expSet: image.AssociationSet{},
+			images: image.TypedImageMapping{
+				{TypedImageReference: image.TypedImageReference{
+					Ref: reference.DockerImageReference{
+						Registry: "test-registry",
+						Name:     "imgname",
+						ID:       "sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+					},
+					Type: imagesource.DestinationRegistry,
+				},
+					Category: v1alpha2.TypeOCPRelease}: {
+					TypedImageReference: image.TypedImageReference{
+						Ref: reference.DockerImageReference{
+							Registry: "test-registry",
+							Name:     "imgname",
+							ID:       "sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+						},
+						Type: imagesource.DestinationRegistry,
+					},
+					Category: v1alpha2.TypeOCPRelease},
+			},
+			meta: v1alpha2.Metadata{
+				MetadataSpec: v1alpha2.MetadataSpec{
+					PastAssociations: []v1alpha2.Association{
+						{
+							Name:            "test-registry/imgname@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+							Path:            "single_manifest",
+							TagSymlink:      "latest",
+							ID:              "sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+							Type:            v1alpha2.TypeGeneric,
+							ManifestDigests: nil,
+							LayerDigests: []string{
+								"sha256:e8614d09b7bebabd9d8a450f44e88a8807c98a438a2ddd63146865286b132d1b",
+								"sha256:601401253d0aac2bc95cccea668761a6e69216468809d1cee837b2e8b398e241",
+								"sha256:211941188a4f55ffc6bcefa4f69b69b32c13fafb65738075de05808bbfcec086",
+								"sha256:f0fd5be261dfd2e36d01069a387a3e5125f5fd5adfec90f3cb190d1d5f1d1ad9",
+								"sha256:0c0beb258254c0566315c641b4107b080a96fa78d4f96833453dd6c5b9edf2b7",
+								"sha256:30c794a11b4c340c77238c5b7ca845752904bd8b74b73a9b16d31253234da031",
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			set, err := c.opts.removePreviouslyMirrored(c.images, c.meta)
+			c.opts.Dir = t.TempDir()
+			prev, err := c.opts.loadAssociations(previousAssociationsDB, c.meta.PastAssociations)
+			require.NoError(t, err)
+			defer prev.Close()
+			db, err := c.opts.removePreviouslyMirrored(c.images, prev, prunedAssociationsDB)
 			if c.expError != "" {
 				require.EqualError(t, err, c.expError)
 			} else {
 				require.NoError(t, err)
+				defer db.Close()
+				set := image.AssociationSet{}
+				require.NoError(t, db.ForEach(func(imageName string, assocs image.Associations) error {
+					set[imageName] = assocs
+					return nil
+				}))
 				require.Equal(t, c.expSet, set)
 			}
 		})
@@ -0,0 +1,70 @@
+package mirror
+
+import (
+	"path"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// mappedNamespace returns the namespace of the first of mappings matching
+// the source image src, or an empty namespace if none matches. A mapping
+// matches when its source matches the registry of src, or its registry
+// followed by one or more levels of its namespace.
+func mappedNamespace(mappings []v1alpha2.NamespaceMapping, src reference.DockerImageReference) string {
+	if len(mappings) == 0 || src.Registry == "" {
+		return ""
+	}
+	candidates := []string{src.Registry}
+	prefix := src.Registry
+	for _, component := range strings.Split(src.Namespace, "/") {
+		if component == "" {
+			continue
+		}
+		prefix = path.Join(prefix, component)
+		candidates = append(candidates, prefix)
+	}
+	for _, m := range mappings {
+		for _, candidate := range candidates {
+			// Sources are validated with the configuration.
+			if matched, _ := path.Match(m.Source, candidate); matched {
+				return m.Namespace
+			}
+		}
+	}
+	return ""
+}
+
+// applyNamespaceMappings prefixes the destination namespaces of mapping with
+// the namespace mapped to their source by mirror.namespaceMappings. It is
+// applied before the mapping is moved to the registry with ToRegistry, which
+// prefixes the user namespace.
+func (o *MirrorOptions) applyNamespaceMappings(mapping image.TypedImageMapping) {
+	if len(o.namespaceMappings) == 0 {
+		return
+	}
+	for src, dest := range mapping {
+		if ns := mappedNamespace(o.namespaceMappings, src.Ref); ns != "" {
+			dest.Ref.Namespace = path.Join(ns, dest.Ref.Namespace)
+			mapping[src] = dest
+		}
+	}
+}
+
+// associationSources returns the source images of the associations of
+// images mirrored to disk, by association path, to find the namespace
+// mapped to the repositories of the paths.
+func associationSources(assocs []v1alpha2.Association) map[string]reference.DockerImageReference {
+	sources := map[string]reference.DockerImageReference{}
+	for _, assoc := range assocs {
+		ref, err := reference.Parse(assoc.Name)
+		if err != nil || ref.Registry == "" {
+			continue
+		}
+		sources[assoc.Path] = ref
+	}
+	return sources
+}
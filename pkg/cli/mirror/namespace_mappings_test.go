@@ -0,0 +1,86 @@
+package mirror
+
+import (
+	"testing"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestMappedNamespace(t *testing.T) {
+	mappings := []v1alpha2.NamespaceMapping{
+		{Source: "registry.redhat.io", Namespace: "redhat"},
+		{Source: "quay.io/openshift-*", Namespace: "openshift"},
+		{Source: "quay.io", Namespace: "community"},
+		{Source: "*.example.com/team", Namespace: "teams"},
+	}
+	type spec struct {
+		name  string
+		src   string
+		expNs string
+	}
+	cases := []spec{
+		{
+			name:  "Valid/Registry",
+			src:   "registry.redhat.io/rhel8/support-tools:latest",
+			expNs: "redhat",
+		},
+		{
+			name:  "Valid/FirstMatchWins",
+			src:   "quay.io/openshift-release-dev/ocp-release:4.14.1-x86_64",
+			expNs: "openshift",
+		},
+		{
+			name:  "Valid/RegistryAfterNamespace",
+			src:   "quay.io/other/app:v1",
+			expNs: "community",
+		},
+		{
+			name:  "Valid/RegistryGlob",
+			src:   "registry.example.com/team/a/app:v1",
+			expNs: "teams",
+		},
+		{
+			name: "Valid/NoMatch",
+			src:  "docker.io/library/busybox:latest",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ref, err := reference.Parse(c.src)
+			require.NoError(t, err)
+			require.Equal(t, c.expNs, mappedNamespace(mappings, ref))
+		})
+	}
+}
+
+func TestApplyNamespaceMappings(t *testing.T) {
+	o := &MirrorOptions{namespaceMappings: []v1alpha2.NamespaceMapping{{Source: "quay.io", Namespace: "community"}}}
+
+	parse := func(ref string) image.TypedImage {
+		img, err := image.ParseTypedImage(ref, v1alpha2.TypeGeneric)
+		require.NoError(t, err)
+		return img
+	}
+	mapped := parse("quay.io/ns/app:v1")
+	unmapped := parse("registry.example.com/ns/app:v1")
+	mapping := image.TypedImageMapping{mapped: mapped, unmapped: unmapped}
+	o.applyNamespaceMappings(mapping)
+	mapping.ToRegistry("localhost:5000", "mirror")
+
+	require.Equal(t, "localhost:5000/mirror/community/ns/app:v1", mapping[mapped].Ref.Exact())
+	require.Equal(t, "localhost:5000/mirror/ns/app:v1", mapping[unmapped].Ref.Exact())
+}
+
+func TestAssociationSources(t *testing.T) {
+	assocs := []v1alpha2.Association{
+		{Name: "quay.io/ns/app:v1", Path: "ns/app"},
+		{Name: "sha256:1111111111111111111111111111111111111111111111111111111111111111", Path: "ns/app"},
+	}
+	sources := associationSources(assocs)
+	require.Len(t, sources, 1)
+	require.Equal(t, "quay.io", sources["ns/app"].Registry)
+}
@@ -0,0 +1,160 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/archive"
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+// notificationTimeout bounds each notification request so an
+// unreachable endpoint does not hold the end of the run.
+const notificationTimeout = 30 * time.Second
+
+const (
+	operationCreate  = "create"
+	operationPublish = "publish"
+	operationMirror  = "mirror"
+)
+
+// runSummary is the payload posted to the notification
+// endpoints when create or publish finishes.
+type runSummary struct {
+	Operation   string   `json:"operation"`
+	Succeeded   bool     `json:"succeeded"`
+	Sequence    int      `json:"sequence,omitempty"`
+	Images      int      `json:"images"`
+	Failures    []string `json:"failures,omitempty"`
+	Duration    string   `json:"duration"`
+	Destination string   `json:"destination,omitempty"`
+	Archives    []string `json:"archives,omitempty"`
+	Drifts      []string `json:"drifts,omitempty"`
+	Storage     []string `json:"storage,omitempty"`
+}
+
+// Text renders the summary as a short message for chat channels.
+func (s runSummary) Text() string {
+	var sb strings.Builder
+	status := "succeeded"
+	if !s.Succeeded {
+		status = "failed"
+	}
+	fmt.Fprintf(&sb, "oc-mirror %s %s", s.Operation, status)
+	if s.Sequence != 0 {
+		fmt.Fprintf(&sb, " (sequence %d)", s.Sequence)
+	}
+	fmt.Fprintf(&sb, " in %s: %d images", s.Duration, s.Images)
+	if s.Destination != "" {
+		fmt.Fprintf(&sb, " to %s", s.Destination)
+	}
+	for _, archive := range s.Archives {
+		fmt.Fprintf(&sb, "\nArchive: %s", archive)
+	}
+	for _, drift := range s.Drifts {
+		fmt.Fprintf(&sb, "\nDrift: %s", drift)
+	}
+	for _, storage := range s.Storage {
+		fmt.Fprintf(&sb, "\nStorage: %s", storage)
+	}
+	for _, failure := range s.Failures {
+		fmt.Fprintf(&sb, "\nFailure: %s", failure)
+	}
+	return sb.String()
+}
+
+// notifyCompletion posts the summary of the run to the notification endpoints
+// of the imageset configuration. Notification errors are logged and never
+// change the outcome of the run.
+func (o *MirrorOptions) notifyCompletion(ctx context.Context, start time.Time, runErr error) {
+	if !o.hasConfig() || o.summary.Operation == "" {
+		return
+	}
+	cfg, err := o.readConfig()
+	if err != nil || !cfg.Notifications.IsSet() {
+		return
+	}
+
+	summary := o.summary
+	summary.Duration = time.Since(start).Round(time.Second).String()
+	if runErr != nil {
+		summary.Failures = append(summary.Failures, runErr.Error())
+	}
+	if o.continuedOnError {
+		summary.Failures = append(summary.Failures, "errors were skipped with --continue-on-error, see the logs")
+	}
+	summary.Succeeded = len(summary.Failures) == 0
+
+	for _, err := range notify(ctx, &http.Client{Transport: network.NewTransport(false), Timeout: notificationTimeout}, *cfg.Notifications, summary) {
+		klog.Warningf("unable to send the run notification: %v", err)
+	}
+}
+
+// notify posts summary as JSON to the webhook and as text to Slack.
+func notify(ctx context.Context, client *http.Client, notifications v1alpha2.Notifications, summary runSummary) []error {
+	var errs []error
+	if notifications.Webhook != "" {
+		if err := postJSON(ctx, client, notifications.Webhook, summary); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %v", err))
+		}
+	}
+	if notifications.Slack != "" {
+		msg := struct {
+			Text string `json:"text"`
+		}{Text: summary.Text()}
+		if err := postJSON(ctx, client, notifications.Slack, msg); err != nil {
+			errs = append(errs, fmt.Errorf("slack: %v", err))
+		}
+	}
+	return errs
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// archivesOfSequence returns the imageset archives of the sequence in dir.
+func archivesOfSequence(dir string, seq int) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("mirror_seq%d_*", seq)))
+	if err != nil {
+		return nil
+	}
+	var archives []string
+	for _, match := range matches {
+		if archive.IsArchive(match) {
+			archives = append(archives, match)
+		}
+	}
+	sort.Strings(archives)
+	return archives
+}
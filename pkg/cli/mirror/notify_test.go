@@ -0,0 +1,140 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestNotify(t *testing.T) {
+	summary := runSummary{
+		Operation: operationCreate,
+		Succeeded: true,
+		Sequence:  2,
+		Images:    12,
+		Duration:  "1m30s",
+		Archives:  []string{"mirror_seq2_000000.tar"},
+	}
+
+	type spec struct {
+		name       string
+		status     int
+		expPayload string
+		expErrors  int
+	}
+
+	cases := []spec{
+		{
+			name:   "Valid/Delivered",
+			status: http.StatusOK,
+		},
+		{
+			name:      "Invalid/EndpointError",
+			status:    http.StatusInternalServerError,
+			expErrors: 2,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var webhookBody, slackBody []byte
+			handler := func(body *[]byte) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					require.Equal(t, http.MethodPost, r.Method)
+					require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+					data, err := io.ReadAll(r.Body)
+					require.NoError(t, err)
+					*body = data
+					w.WriteHeader(c.status)
+				}
+			}
+			webhook := httptest.NewServer(handler(&webhookBody))
+			t.Cleanup(webhook.Close)
+			slack := httptest.NewServer(handler(&slackBody))
+			t.Cleanup(slack.Close)
+
+			errs := notify(context.Background(), webhook.Client(), v1alpha2.Notifications{Webhook: webhook.URL, Slack: slack.URL}, summary)
+			require.Len(t, errs, c.expErrors)
+
+			var gotSummary runSummary
+			require.NoError(t, json.Unmarshal(webhookBody, &gotSummary))
+			require.Equal(t, summary, gotSummary)
+
+			var gotMsg struct {
+				Text string `json:"text"`
+			}
+			require.NoError(t, json.Unmarshal(slackBody, &gotMsg))
+			require.Equal(t, summary.Text(), gotMsg.Text)
+		})
+	}
+}
+
+func TestNotifyCompletion(t *testing.T) {
+	var got runSummary
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	t.Cleanup(webhook.Close)
+
+	o := &MirrorOptions{
+		ImageSetConfig: &v1alpha2.ImageSetConfiguration{
+			ImageSetConfigurationSpec: v1alpha2.ImageSetConfigurationSpec{
+				Notifications: &v1alpha2.Notifications{Webhook: webhook.URL},
+			},
+		},
+		summary: runSummary{Operation: operationMirror, Sequence: 3, Images: 4, Destination: "localhost:5000"},
+	}
+	o.notifyCompletion(context.Background(), time.Now(), errors.New("mirror failed"))
+
+	require.Equal(t, runSummary{
+		Operation:   operationMirror,
+		Succeeded:   false,
+		Sequence:    3,
+		Images:      4,
+		Failures:    []string{"mirror failed"},
+		Duration:    "0s",
+		Destination: "localhost:5000",
+	}, got)
+}
+
+func TestRunSummaryText(t *testing.T) {
+	summary := runSummary{
+		Operation:   operationPublish,
+		Sequence:    1,
+		Images:      3,
+		Duration:    "10s",
+		Destination: "localhost:5000/ns",
+		Drifts:      []string{"quay.io/foo/bar:latest: drift detected"},
+		Storage:     []string{"localhost:5000/ns/foo/bar: 1.0 MiB"},
+		Failures:    []string{"error pruning"},
+	}
+	require.Equal(t, "oc-mirror publish failed (sequence 1) in 10s: 3 images to localhost:5000/ns\nDrift: quay.io/foo/bar:latest: drift detected\n"+
+		"Storage: localhost:5000/ns/foo/bar: 1.0 MiB\nFailure: error pruning", summary.Text())
+}
+
+func TestArchivesOfSequence(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"mirror_seq2_000001.tar", "mirror_seq2_000000.tar", "mirror_seq1_000000.tar", "mirror_seq20_000000.tar",
+		"mirror_seq3_000000.tar.zst", "mirror_seq3_000001.tar.zst", "mirror_seq3_000000.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+	require.Equal(t, []string{
+		filepath.Join(dir, "mirror_seq2_000000.tar"),
+		filepath.Join(dir, "mirror_seq2_000001.tar"),
+	}, archivesOfSequence(dir, 2))
+	require.Equal(t, []string{
+		filepath.Join(dir, "mirror_seq3_000000.tar.zst"),
+		filepath.Join(dir, "mirror_seq3_000001.tar.zst"),
+	}, archivesOfSequence(dir, 3))
+}
@@ -0,0 +1,107 @@
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// convertManifestsToOCI converts the manifests of the associations unpacked
+// to the v2 directory v2Dir to OCI media types before they are published.
+// The converted manifests are written next to the original ones, named by their
+// new digest, and the tag symlinks are updated to point to them. The configs and
+// the layers are unchanged. It returns the new digests keyed by original digest.
+func convertManifestsToOCI(v2Dir string, assocs []v1alpha2.Association) (map[string]string, error) {
+	digests := map[string]string{}
+	converted := map[string]v1.Descriptor{}
+
+	var convert func(manifestsDir, digest string) (v1.Descriptor, error)
+	convert = func(manifestsDir, digest string) (v1.Descriptor, error) {
+		manifestPath := filepath.Join(manifestsDir, digest)
+		if desc, ok := converted[manifestPath]; ok {
+			return desc, nil
+		}
+		data, err := os.ReadFile(filepath.Clean(manifestPath))
+		if err != nil {
+			return v1.Descriptor{}, fmt.Errorf("error reading manifest %s: %v", digest, err)
+		}
+		ociData, err := image.ConvertManifestToOCI(data, func(child v1.Hash) (v1.Descriptor, error) {
+			return convert(manifestsDir, child.String())
+		})
+		if err != nil {
+			return v1.Descriptor{}, fmt.Errorf("error converting manifest %s: %v", digest, err)
+		}
+		hash, size, err := v1.SHA256(bytes.NewReader(ociData))
+		if err != nil {
+			return v1.Descriptor{}, err
+		}
+		var meta struct {
+			MediaType types.MediaType `json:"mediaType"`
+		}
+		if err := json.Unmarshal(ociData, &meta); err != nil {
+			return v1.Descriptor{}, err
+		}
+		if hash.String() != digest {
+			if err := os.WriteFile(filepath.Join(manifestsDir, hash.String()), ociData, 0600); err != nil {
+				return v1.Descriptor{}, err
+			}
+			digests[digest] = hash.String()
+			klog.V(3).Infof("Converted manifest %s to OCI media types with digest %s", digest, hash)
+		}
+		desc := v1.Descriptor{MediaType: meta.MediaType, Digest: hash, Size: size}
+		converted[manifestPath] = desc
+		return desc, nil
+	}
+
+	for _, assoc := range assocs {
+		manifestsDir := filepath.Join(v2Dir, assoc.Path, "manifests")
+		if _, err := convert(manifestsDir, assoc.ID); err != nil {
+			return nil, fmt.Errorf("image %q: %v", assoc.Name, err)
+		}
+		newDigest, ok := digests[assoc.ID]
+		if !ok || assoc.TagSymlink == "" {
+			continue
+		}
+		symlink := filepath.Join(manifestsDir, assoc.TagSymlink)
+		if err := os.Remove(symlink); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err := os.Symlink(newDigest, symlink); err != nil {
+			return nil, err
+		}
+	}
+	return digests, nil
+}
+
+// remapAssociationDBDigests replaces the original digests of the images converted
+// to OCI media types by their new digests in db. Child manifests are named by
+// their digest, so their names are replaced too.
+func remapAssociationDBDigests(db *image.AssociationDB, digests map[string]string) error {
+	return db.UpdateValues(func(_ string, assoc v1alpha2.Association) v1alpha2.Association {
+		remapAssociationDigest(&assoc, digests)
+		return assoc
+	})
+}
+
+func remapAssociationDigest(assoc *v1alpha2.Association, digests map[string]string) {
+	if newDigest, ok := digests[assoc.ID]; ok {
+		if assoc.Name == assoc.ID {
+			assoc.Name = newDigest
+		}
+		assoc.ID = newDigest
+	}
+	for j, manifestDigest := range assoc.ManifestDigests {
+		if newDigest, ok := digests[manifestDigest]; ok {
+			assoc.ManifestDigests[j] = newDigest
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package mirror
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestConvertManifestsToOCI(t *testing.T) {
+	v2Dir := filepath.Join(t.TempDir(), config.V2Dir)
+	manifestsDir := filepath.Join(v2Dir, "ns", "img", "manifests")
+	require.NoError(t, os.MkdirAll(manifestsDir, 0750))
+
+	writeManifest := func(data []byte) string {
+		hash, _, err := v1.SHA256(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(manifestsDir, hash.String()), data, 0600))
+		return hash.String()
+	}
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	imgData, err := img.RawManifest()
+	require.NoError(t, err)
+	imgDigest := writeManifest(imgData)
+	idx := mutate.IndexMediaType(mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: img}), types.DockerManifestList)
+	idxData, err := idx.RawManifest()
+	require.NoError(t, err)
+	idxDigest := writeManifest(idxData)
+	require.NoError(t, os.Symlink(idxDigest, filepath.Join(manifestsDir, "latest")))
+
+	assocs := []v1alpha2.Association{
+		{Name: "quay.io/ns/img:latest", Path: "ns/img", ID: idxDigest, TagSymlink: "latest", Type: v1alpha2.TypeGeneric, ManifestDigests: []string{imgDigest}},
+		{Name: imgDigest, Path: "ns/img", ID: imgDigest, Type: v1alpha2.TypeGeneric, LayerDigests: []string{"sha256:aaaa"}},
+	}
+	digests, err := convertManifestsToOCI(v2Dir, assocs)
+	require.NoError(t, err)
+	require.Len(t, digests, 2)
+
+	// The tag points to the converted manifest list, which refers to the converted image.
+	data, err := os.ReadFile(filepath.Join(manifestsDir, "latest"))
+	require.NoError(t, err)
+	hash, _, err := v1.SHA256(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, digests[idxDigest], hash.String())
+	im, err := v1.ParseIndexManifest(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, types.OCIImageIndex, im.MediaType)
+	require.Equal(t, digests[imgDigest], im.Manifests[0].Digest.String())
+
+	data, err = os.ReadFile(filepath.Join(manifestsDir, digests[imgDigest]))
+	require.NoError(t, err)
+	m, err := v1.ParseManifest(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, types.OCIManifestSchema1, m.MediaType)
+	require.Equal(t, types.OCIConfigJSON, m.Config.MediaType)
+
+	// The original manifests are kept.
+	require.FileExists(t, filepath.Join(manifestsDir, imgDigest))
+
+	db, err := image.ConvertToAssociationDB(filepath.Join(t.TempDir(), "assocs.db"), assocs)
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, remapAssociationDBDigests(db, digests))
+	assocs, found, err := db.Search("quay.io/ns/img:latest")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.ElementsMatch(t, []v1alpha2.Association{
+		{Name: "quay.io/ns/img:latest", Path: "ns/img", ID: digests[idxDigest], TagSymlink: "latest", Type: v1alpha2.TypeGeneric, ManifestDigests: []string{digests[imgDigest]}},
+		{Name: digests[imgDigest], Path: "ns/img", ID: digests[imgDigest], Type: v1alpha2.TypeGeneric, LayerDigests: []string{"sha256:aaaa"}},
+	}, assocs)
+
+	// Converted manifests are unchanged by a second conversion.
+	digests, err = convertManifestsToOCI(v2Dir, assocs)
+	require.NoError(t, err)
+	require.Empty(t, digests)
+}
@@ -0,0 +1,196 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// maxOpenShiftVersionProperty is the bundle property declaring the
+// latest OpenShift minor version an operator can be installed on.
+const maxOpenShiftVersionProperty = "olm.maxOpenShiftVersion"
+
+// channelVersionRegexp matches the minor version ending a release channel name,
+// e.g. 4.14 in stable-4.14.
+var channelVersionRegexp = regexp.MustCompile(`(\d+\.\d+)$`)
+
+// lowestPlatformVersion returns the lowest OpenShift minor version mirrored by
+// the channels of platform, read from their minVersion or else from their name.
+func lowestPlatformVersion(platform v1alpha2.Platform) (semver.Version, error) {
+	var lowest semver.Version
+	if len(platform.Channels) == 0 {
+		return lowest, fmt.Errorf("no release channels in the platform section")
+	}
+	for i, ch := range platform.Channels {
+		version := ch.MinVersion
+		if version == "" {
+			version = channelVersionRegexp.FindString(ch.Name)
+		}
+		if version == "" {
+			return lowest, fmt.Errorf("cannot determine the OpenShift version of release channel %q, set its minVersion", ch.Name)
+		}
+		v, err := semver.ParseTolerant(version)
+		if err != nil {
+			return lowest, fmt.Errorf("release channel %q: invalid version %q: %v", ch.Name, version, err)
+		}
+		v = semver.Version{Major: v.Major, Minor: v.Minor}
+		if i == 0 || v.LT(lowest) {
+			lowest = v
+		}
+	}
+	return lowest, nil
+}
+
+// bundleMaxOpenShiftVersion returns the minor version of the olm.maxOpenShiftVersion
+// property of b, and false if b does not declare the property.
+func bundleMaxOpenShiftVersion(b declcfg.Bundle) (semver.Version, bool, error) {
+	for _, p := range b.Properties {
+		if p.Type != maxOpenShiftVersionProperty {
+			continue
+		}
+		// The version is declared as a string or a number.
+		var value interface{}
+		if err := json.Unmarshal(p.Value, &value); err != nil {
+			return semver.Version{}, true, err
+		}
+		v, err := semver.ParseTolerant(strings.TrimSpace(fmt.Sprint(value)))
+		if err != nil {
+			return semver.Version{}, true, err
+		}
+		return semver.Version{Major: v.Major, Minor: v.Minor}, true, nil
+	}
+	return semver.Version{}, false, nil
+}
+
+// excludeIncompatibleBundles removes from dc the bundles whose olm.maxOpenShiftVersion
+// is below release, which cannot be installed on the mirrored releases, and warns
+// about the packages with bundles not declaring the property. Channel entries
+// replacing an excluded bundle replace the bundle it replaced and skip it instead,
+// so that the channels keep a single head. Channels left empty are removed, along
+// with the packages whose default channel is.
+func excludeIncompatibleBundles(catalog string, dc *declcfg.DeclarativeConfig, release semver.Version) error {
+	excluded := map[string]map[string]bool{}
+	undeclared := map[string]int{}
+	bundles := dc.Bundles[:0]
+	for _, b := range dc.Bundles {
+		maxVersion, found, err := bundleMaxOpenShiftVersion(b)
+		switch {
+		case err != nil:
+			return fmt.Errorf("bundle %s: invalid %s property: %v", b.Name, maxOpenShiftVersionProperty, err)
+		case !found:
+			undeclared[b.Package]++
+		case maxVersion.LT(release):
+			klog.V(1).Infof("Excluding bundle %s of catalog %s: %s is %s", b.Name, catalog, maxOpenShiftVersionProperty, minorVersion(maxVersion))
+			if excluded[b.Package] == nil {
+				excluded[b.Package] = map[string]bool{}
+			}
+			excluded[b.Package][b.Name] = true
+			continue
+		}
+		bundles = append(bundles, b)
+	}
+	dc.Bundles = bundles
+
+	for pkg, n := range undeclared {
+		klog.Warningf("Package %s of catalog %s: %d bundles do not declare %s and are kept, they may not install on OpenShift %s",
+			pkg, catalog, n, maxOpenShiftVersionProperty, minorVersion(release))
+	}
+	if len(excluded) == 0 {
+		return nil
+	}
+
+	emptied := map[string]bool{}
+	channels := dc.Channels[:0]
+	for _, ch := range dc.Channels {
+		if removed := excluded[ch.Package]; removed != nil {
+			ch.Entries = withoutEntries(ch.Entries, removed)
+		}
+		if len(ch.Entries) == 0 {
+			emptied[ch.Package+"/"+ch.Name] = true
+			continue
+		}
+		channels = append(channels, ch)
+	}
+	dc.Channels = channels
+
+	dropped := map[string]bool{}
+	packages := dc.Packages[:0]
+	for _, p := range dc.Packages {
+		if emptied[p.Name+"/"+p.DefaultChannel] {
+			klog.Warningf("Excluding package %s of catalog %s: every bundle of its default channel %s requires an OpenShift version below %s",
+				p.Name, catalog, p.DefaultChannel, minorVersion(release))
+			dropped[p.Name] = true
+			continue
+		}
+		packages = append(packages, p)
+	}
+	dc.Packages = packages
+	if len(dropped) != 0 {
+		channels := dc.Channels[:0]
+		for _, ch := range dc.Channels {
+			if !dropped[ch.Package] {
+				channels = append(channels, ch)
+			}
+		}
+		dc.Channels = channels
+		bundles := dc.Bundles[:0]
+		for _, b := range dc.Bundles {
+			if !dropped[b.Package] {
+				bundles = append(bundles, b)
+			}
+		}
+		dc.Bundles = bundles
+		deprecations := dc.Deprecations[:0]
+		for _, d := range dc.Deprecations {
+			if !dropped[d.Package] {
+				deprecations = append(deprecations, d)
+			}
+		}
+		dc.Deprecations = deprecations
+	}
+
+	n := 0
+	for _, names := range excluded {
+		n += len(names)
+	}
+	klog.Infof("Excluded %d bundles of catalog %s whose %s is below OpenShift %s", n, catalog, maxOpenShiftVersionProperty, minorVersion(release))
+	return nil
+}
+
+// minorVersion formats v as a minor version, e.g. 4.14.
+func minorVersion(v semver.Version) string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// withoutEntries returns entries without the entries of removed. Entries replacing
+// a removed entry replace the entry it replaced instead, and skip the removed one.
+func withoutEntries(entries []declcfg.ChannelEntry, removed map[string]bool) []declcfg.ChannelEntry {
+	byName := make(map[string]declcfg.ChannelEntry, len(entries))
+	for _, e := range entries {
+		byName[e.Name] = e
+	}
+	kept := make([]declcfg.ChannelEntry, 0, len(entries))
+	for _, e := range entries {
+		if removed[e.Name] {
+			continue
+		}
+		var skips []string
+		// Bounded by the number of entries in case of a replaces cycle.
+		for i := 0; i < len(entries) && e.Replaces != "" && removed[e.Replaces]; i++ {
+			skips = append(skips, e.Replaces)
+			e.Replaces = byName[e.Replaces].Replaces
+		}
+		if len(skips) != 0 {
+			e.Skips = append(append([]string{}, e.Skips...), skips...)
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
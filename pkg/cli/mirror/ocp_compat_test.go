@@ -0,0 +1,100 @@
+package mirror
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestLowestPlatformVersion(t *testing.T) {
+	type spec struct {
+		name     string
+		channels []v1alpha2.ReleaseChannel
+		exp      string
+		expError string
+	}
+	cases := []spec{
+		{
+			name:     "Valid/ChannelNames",
+			channels: []v1alpha2.ReleaseChannel{{Name: "stable-4.15"}, {Name: "eus-4.14"}},
+			exp:      "4.14",
+		},
+		{
+			name:     "Valid/MinVersion",
+			channels: []v1alpha2.ReleaseChannel{{Name: "stable-4.14", MinVersion: "4.13.20"}},
+			exp:      "4.13",
+		},
+		{
+			name:     "Invalid/NoVersion",
+			channels: []v1alpha2.ReleaseChannel{{Name: "okd"}},
+			expError: `cannot determine the OpenShift version of release channel "okd", set its minVersion`,
+		},
+		{
+			name:     "Invalid/NoChannels",
+			expError: "no release channels in the platform section",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := lowestPlatformVersion(v1alpha2.Platform{Channels: c.channels})
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.exp, minorVersion(v))
+		})
+	}
+}
+
+func TestExcludeIncompatibleBundles(t *testing.T) {
+	maxVersion := func(v string) []property.Property {
+		return []property.Property{{Type: maxOpenShiftVersionProperty, Value: json.RawMessage(v)}}
+	}
+	dc := &declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Name: "foo", DefaultChannel: "stable"},
+			{Name: "bar", DefaultChannel: "stable"},
+		},
+		Channels: []declcfg.Channel{
+			{Package: "foo", Name: "stable", Entries: []declcfg.ChannelEntry{
+				{Name: "foo.v1"},
+				{Name: "foo.v2", Replaces: "foo.v1"},
+				{Name: "foo.v3", Replaces: "foo.v2"},
+			}},
+			{Package: "foo", Name: "legacy", Entries: []declcfg.ChannelEntry{{Name: "foo.v1"}}},
+			{Package: "bar", Name: "stable", Entries: []declcfg.ChannelEntry{{Name: "bar.v1"}}},
+		},
+		Bundles: []declcfg.Bundle{
+			{Package: "foo", Name: "foo.v1"},
+			{Package: "foo", Name: "foo.v2", Properties: maxVersion(`"4.13"`)},
+			{Package: "foo", Name: "foo.v3", Properties: maxVersion(`4.15`)},
+			{Package: "bar", Name: "bar.v1", Properties: maxVersion(`"4.12"`)},
+		},
+		Deprecations: []declcfg.Deprecation{{Package: "bar"}},
+	}
+
+	require.NoError(t, excludeIncompatibleBundles("registry.example.com/catalog:v1", dc, semver.Version{Major: 4, Minor: 14}))
+	require.Equal(t, []declcfg.Package{{Name: "foo", DefaultChannel: "stable"}}, dc.Packages)
+	require.Equal(t, []declcfg.Channel{
+		{Package: "foo", Name: "stable", Entries: []declcfg.ChannelEntry{
+			{Name: "foo.v1"},
+			{Name: "foo.v3", Replaces: "foo.v1", Skips: []string{"foo.v2"}},
+		}},
+		{Package: "foo", Name: "legacy", Entries: []declcfg.ChannelEntry{{Name: "foo.v1"}}},
+	}, dc.Channels)
+	require.Len(t, dc.Bundles, 2)
+	require.Equal(t, "foo.v1", dc.Bundles[0].Name)
+	require.Equal(t, "foo.v3", dc.Bundles[1].Name)
+	require.Empty(t, dc.Deprecations)
+
+	invalid := &declcfg.DeclarativeConfig{Bundles: []declcfg.Bundle{{Package: "foo", Name: "foo.v1", Properties: maxVersion(`"latest"`)}}}
+	err := excludeIncompatibleBundles("registry.example.com/catalog:v1", invalid, semver.Version{Major: 4, Minor: 14})
+	require.ErrorContains(t, err, "bundle foo.v1: invalid olm.maxOpenShiftVersion property")
+}
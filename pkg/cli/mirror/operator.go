@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/blang/semver/v4"
 	"github.com/containerd/errdefs"
 	"github.com/containers/image/v5/types"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -36,6 +37,7 @@ import (
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
 	"github.com/openshift/oc-mirror/pkg/config"
 	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/network"
 	"github.com/openshift/oc-mirror/pkg/operator"
 	"github.com/openshift/oc-mirror/pkg/operator/diff"
 )
@@ -50,6 +52,9 @@ type OperatorOptions struct {
 
 	tmp      string
 	insecure bool
+	// rendered holds the declarative configs of the catalog being
+	// planned, by reference, so that it is rendered once.
+	rendered map[string]*declcfg.DeclarativeConfig
 }
 
 func NewOperatorOptions(mo *MirrorOptions) *OperatorOptions {
@@ -102,7 +107,11 @@ PlanDiff plans only the diff between each old and new catalog image pair
 func (o *OperatorOptions) PlanDiff(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, lastRun v1alpha2.PastMirror) (image.TypedImageMapping, error) {
 	// Wrapper renderDCDiff so it satisfies the renderDCFunc function signature.
 	f := func(ctx context.Context, reg *containerdregistry.Registry, ctlg v1alpha2.Operator) (*declcfg.DeclarativeConfig, v1alpha2.IncludeConfig, error) {
-		return o.renderDCDiff(ctx, reg, ctlg, lastRun)
+		dc, ic, err := o.renderDCDiff(ctx, reg, ctlg, lastRun)
+		if err != nil {
+			return dc, ic, err
+		}
+		return dc, ic, o.addPinnedBundles(ctx, reg, ctlg, lastRun, dc)
 	}
 	return o.run(ctx, cfg, f)
 }
@@ -156,13 +165,27 @@ func (o *OperatorOptions) run(
 	}
 
 	mmapping := image.TypedImageMapping{}
+	usage := imageUsageReport{}
+	var release semver.Version
 	for _, ctlg := range cfg.Mirror.Operators {
-		reg, err := o.createRegistry()
+		if ctlg.ExcludeIncompatible {
+			if release, err = lowestPlatformVersion(cfg.Mirror.Platform); err != nil {
+				return nil, fmt.Errorf("catalog %s: excludeIncompatible: %v", ctlg.Catalog, err)
+			}
+			break
+		}
+	}
+	for _, ctlg := range cfg.Mirror.Operators {
+		// Catalogs read from a directory are built on their base image.
+		if ctlg.IsFBCDir() && !o.RebuildCatalogs {
+			return nil, fmt.Errorf("catalog %s: dir:// catalogs require --rebuild-catalogs", ctlg.Catalog)
+		}
+		reg, err := o.createRegistry(o.SourceSkipTLS || skipTLSVerifyFor(ctlg.CatalogImage()), o.SourcePlainHTTP || plainHTTPFor(ctlg.CatalogImage()))
 		if err != nil {
 			return nil, fmt.Errorf("error creating container registry: %v", err)
 		}
 
-		ctlgRef, err := image.ParseReference(ctlg.Catalog)
+		ctlgRef, err := image.ParseReference(ctlg.CatalogImage())
 		if err != nil {
 			reg.Destroy()
 			return nil, err
@@ -181,13 +204,61 @@ func (o *OperatorOptions) run(
 			return nil, fmt.Errorf("error parsing catalog: %v", err)
 		}
 
+		if err := o.writeCatalogProvenance(ctx, ctlg, targetCtlg); err != nil {
+			reg.Destroy()
+			return nil, err
+		}
+
+		o.rendered = map[string]*declcfg.DeclarativeConfig{}
+
+		ctlg, err = o.handleDeprecatedPackages(ctx, reg, ctlg)
+		if err != nil {
+			reg.Destroy()
+			return nil, err
+		}
+
 		// Render the catalog to mirror into a declarative config.
-		dc, ic, err := renderDC(ctx, reg, ctlg)
+		// Catalogs generated from bundles are rendered from the bundles
+		// alone, the catalog image is only the base of the generated catalog.
+		render := renderDC
+		if ctlg.IsBundleList() {
+			render = o.renderBundleList
+		}
+		dc, ic, err := render(ctx, reg, ctlg)
 		if err != nil {
 			reg.Destroy()
 			return nil, o.checkValidationErr(err)
 		}
 
+		if err := operator.SelectBundles(dc, ctlg.IncludeConfig); err != nil {
+			reg.Destroy()
+			return nil, fmt.Errorf("catalog %s: %v", ctlg.Catalog, err)
+		}
+
+		if err := o.resolveDependencies(ctx, reg, ctlg, dc); err != nil {
+			reg.Destroy()
+			return nil, err
+		}
+
+		if ctlg.ExcludeIncompatible {
+			if err := excludeIncompatibleBundles(ctlg.Catalog, dc, release); err != nil {
+				reg.Destroy()
+				return nil, err
+			}
+		}
+
+		if err := o.addCSVRelatedImages(ctx, ctlg, dc); err != nil {
+			reg.Destroy()
+			return nil, err
+		}
+
+		if !o.DryRun {
+			if err := o.extractCLIDownloads(ctx, ctlg, dc); err != nil {
+				reg.Destroy()
+				return nil, err
+			}
+		}
+
 		if o.RebuildCatalogs && o.BuildCatalogCache {
 			ctlgSrcDir := filepath.Join(o.Dir, config.SourceDir, config.CatalogsDir, targetCtlg.Ref.Registry, targetCtlg.Ref.Namespace, targetCtlg.Ref.Name)
 			if targetCtlg.Ref.ID != "" {
@@ -207,10 +278,25 @@ func (o *OperatorOptions) run(
 			reg.Destroy()
 			return nil, err
 		}
+		usage.add(ctlg.Catalog, dc)
+		if baseline, found := o.baselineCatalog(ctlgRef.Ref); found && !ctlg.IsFBCOCI() && !ctlg.IsFBCDir() && !ctlg.IsBundleList() {
+			images, err := o.baselineImages(ctx, reg, ctlg, baseline.Exact())
+			if err != nil {
+				reg.Destroy()
+				return nil, err
+			}
+			var excluded []string
+			mappings, excluded = withoutBaselineImages(mappings, images)
+			o.baselineExcluded = append(o.baselineExcluded, excluded...)
+			klog.Infof("Mirroring %d images of catalog %s not referenced by baseline %s", len(mappings), ctlg.Catalog, baseline.ID)
+		}
 		mmapping.Merge(mappings)
 		reg.Destroy()
 	}
 
+	if err := o.writeImageUsageReport(usage); err != nil {
+		return nil, err
+	}
 	return mmapping, nil
 }
 
@@ -223,7 +309,7 @@ func (o *OperatorOptions) mktempDir() (func(), error) {
 	}, os.MkdirAll(o.tmp, os.ModePerm)
 }
 
-func (o *OperatorOptions) createRegistry() (*containerdregistry.Registry, error) {
+func (o *OperatorOptions) createRegistry(skipTLSVerify, plainHTTP bool) (*containerdregistry.Registry, error) {
 	cacheDir, err := os.MkdirTemp("", "imageset-catalog-registry-")
 	if err != nil {
 		return nil, err
@@ -235,8 +321,12 @@ func (o *OperatorOptions) createRegistry() (*containerdregistry.Registry, error)
 
 	return containerdregistry.NewRegistry(
 		containerdregistry.WithCacheDir(cacheDir),
-		containerdregistry.SkipTLSVerify(o.SourceSkipTLS),
-		containerdregistry.WithPlainHTTP(o.SourcePlainHTTP),
+		containerdregistry.SkipTLSVerify(skipTLSVerify),
+		containerdregistry.WithPlainHTTP(plainHTTP),
+		containerdregistry.WithRootCAs(network.RootCAs()),
+		// The merged auth file, if any, replaces the docker config file.
+		// REGISTRY_AUTH_FILE still takes precedence over both.
+		containerdregistry.WithResolverConfigDir(o.authConfigDir),
 		// The containerd registry impl is somewhat verbose, even on the happy path,
 		// so discard all logger logs. Any important failures will be returned from
 		// registry methods and eventually logged as fatal errors.
@@ -244,6 +334,30 @@ func (o *OperatorOptions) createRegistry() (*containerdregistry.Registry, error)
 	)
 }
 
+// renderCatalog returns the declarative config of the catalog ctlgRef, rendered
+// once per catalog planned: the config rendered to check the deprecations is
+// reused by the diffs selecting the packages of the catalog. The returned config
+// is shared and must not be modified.
+func (o *OperatorOptions) renderCatalog(ctx context.Context, reg *containerdregistry.Registry, ctlgRef string) (*declcfg.DeclarativeConfig, error) {
+	if dc, ok := o.rendered[ctlgRef]; ok {
+		return dc, nil
+	}
+	dc, err := action.Render{
+		Registry: reg,
+		Refs:     []string{ctlgRef},
+		// Catalogs only, like the diffs reusing the config.
+		AllowedRefMask: action.RefDCDir | action.RefDCImage | action.RefSqliteFile | action.RefSqliteImage,
+	}.Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if o.rendered == nil {
+		o.rendered = map[string]*declcfg.DeclarativeConfig{}
+	}
+	o.rendered[ctlgRef] = dc
+	return dc, nil
+}
+
 // renderDCFull renders data in ctlg into a declarative config for o.Full().
 // Satisfies the renderDCFunc function signature.
 func (o *OperatorOptions) renderDCFull(
@@ -258,6 +372,9 @@ func (o *OperatorOptions) renderDCFull(
 
 	catLogger := o.Logger.WithField("catalog", ctlg.Catalog)
 	ctlgRef := ctlg.Catalog //applies for all docker-v2 remote catalogs
+	if ctlg.IsFBCDir() {
+		ctlgRef = v1alpha2.TrimProtocol(ctlg.Catalog)
+	}
 	if ctlg.IsFBCOCI() {
 		// initialize path where we assume the catalog config dir is <current working directory>/olm_artifacts/<repo>/<config folder>
 		var ok bool
@@ -266,7 +383,14 @@ func (o *OperatorOptions) renderDCFull(
 			return dc, ic, err
 		}
 	}
-	if full {
+	switch {
+	case full && ctlg.IsFBCDir():
+		// Mirror the entire catalog directory.
+		dc, err = loadFBCDir(ctx, ctlgRef)
+		if err != nil {
+			return dc, ic, err
+		}
+	case full:
 		// Mirror the entire catalog.
 		dc, err = action.Render{
 			Registry: reg,
@@ -275,15 +399,20 @@ func (o *OperatorOptions) renderDCFull(
 		if err != nil {
 			return dc, ic, err
 		}
-	} else {
+	default:
 		// Generate and mirror a heads-only diff using only the catalog as a new ref.
 		dic, derr := ctlg.IncludeConfig.ConvertToDiffIncludeConfig()
 		if derr != nil {
 			return dc, ic, derr
 		}
+		newCfg, rerr := o.renderCatalog(ctx, reg, ctlgRef)
+		if rerr != nil {
+			return dc, ic, rerr
+		}
 		dc, err = diff.Diff{
 			Registry:         reg,
 			NewRefs:          []string{ctlgRef},
+			NewConfig:        newCfg,
 			Logger:           catLogger,
 			IncludeConfig:    dic,
 			SkipDependencies: ctlg.SkipDependencies,
@@ -350,6 +479,9 @@ func (o *OperatorOptions) renderDCDiff(
 	catLogger := o.Logger.WithField("catalog", ctlg.Catalog)
 
 	ctlgRef := ctlg.Catalog //applies for all docker-v2 remote catalogs
+	if ctlg.IsFBCDir() {
+		ctlgRef = v1alpha2.TrimProtocol(ctlg.Catalog)
+	}
 	if ctlg.IsFBCOCI() {
 		var ok bool
 		if ctlgRef, ok = o.operatorCatalogToFullArtifactPath[ctlg.Catalog]; !ok {
@@ -357,9 +489,14 @@ func (o *OperatorOptions) renderDCDiff(
 			return dc, ic, err
 		}
 	}
+	newCfg, err := o.renderCatalog(ctx, reg, ctlgRef)
+	if err != nil {
+		return dc, ic, err
+	}
 	a := diff.Diff{
 		Registry:         reg,
 		NewRefs:          []string{ctlgRef},
+		NewConfig:        newCfg,
 		Logger:           catLogger,
 		SkipDependencies: ctlg.SkipDependencies,
 	}
@@ -373,13 +510,7 @@ func (o *OperatorOptions) renderDCDiff(
 	switch {
 	case catalogHeadsOnly:
 		icManager = operator.NewCatalogStrategy()
-		dc, err = action.Render{
-			Registry: reg,
-			Refs:     []string{ctlgRef},
-		}.Run(ctx)
-		if err != nil {
-			return dc, ic, err
-		}
+		dc = newCfg
 
 	case includeWithHeadsOnly:
 		icManager = operator.NewPackageStrategy(ctlg.IncludeConfig)
@@ -399,8 +530,13 @@ func (o *OperatorOptions) renderDCDiff(
 	}
 
 	// Update the IncludeConfig and diff include configuration based on previous mirrored bundles
-	// and the current catalog.
-	ic, err = icManager.UpdateIncludeConfig(*dc, prev.IncludeConfig)
+	// and the current catalog, or on the channel heads of the previous mirror when diffing by
+	// channel heads. Metadata of older mirrors has no channel heads.
+	if o.DiffByChannelHeads && len(prev.ChannelHeads) != 0 {
+		ic, err = operator.IncludeConfigFromHeads(*dc, prev.ChannelHeads, ctlg.IncludeConfig)
+	} else {
+		ic, err = icManager.UpdateIncludeConfig(*dc, prev.IncludeConfig)
+	}
 	if err != nil {
 		return dc, ic, fmt.Errorf("error updating include config: %v", err)
 	}
@@ -557,7 +693,7 @@ func (o *OperatorOptions) plan(ctx context.Context, dc *declcfg.DeclarativeConfi
 		targetLocation := o.ToMirror
 
 		mirrorToDisk := len(o.OutputDir) > 0 && o.From == ""
-		mirrorToMirror := len(o.ToMirror) > 0 && len(o.ConfigPath) > 0
+		mirrorToMirror := len(o.ToMirror) > 0 && o.hasConfig()
 		// Case of MirrorToDisk workflow, the location is on disk
 		// as in vendor/github.com/openshift/oc/pkg/cli/admin/catalog/mirrorer.go, function mount.
 		// for the case of mirrorToDisk, it's as if we wanted to call mount with dest=file:// and
@@ -0,0 +1,409 @@
+package mirror
+
+import (
+	"archive/tar"
+	"bufio"
+	"crypto/sha256"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+// platformOpmPattern matches the opm binaries built for other platforms
+// that operator-registry based catalogs ship next to their opm binary,
+// like darwin-amd64-opm or windows-amd64-opm.
+const platformOpmPattern = "*-*-opm"
+
+// opmChecksumsFile records, next to the opm binaries extracted from a
+// catalog image, their sha256 in the sha256sum format. The binaries are
+// verified against it before they run.
+const opmChecksumsFile = "opm.sha256"
+
+// platformOpmName returns the name of the opm binary
+// built for goos and goarch shipped by catalogs.
+func platformOpmName(goos, goarch string) string {
+	return fmt.Sprintf("%s-%s-opm", goos, goarch)
+}
+
+// copyPlatformOPMBinaries moves the opm binaries built for other platforms
+// found in srcDir to binDir, so the cache of the catalog can be regenerated
+// on hosts that cannot run the opm binary of the catalog. It returns the
+// path each moved binary had, keyed by its name in binDir.
+func copyPlatformOPMBinaries(srcDir, binDir string) (map[string]string, error) {
+	matches, err := filepath.Glob(filepath.Join(srcDir, platformOpmPattern))
+	if err != nil {
+		return nil, err
+	}
+	moved := map[string]string{}
+	for _, match := range matches {
+		realPath, err := filepath.EvalSymlinks(match)
+		if err != nil {
+			klog.V(1).Infof("skipping opm binary %s: %v", match, err)
+			continue
+		}
+		if err := os.Rename(realPath, filepath.Join(binDir, filepath.Base(match))); err != nil {
+			return nil, err
+		}
+		moved[filepath.Base(match)] = realPath
+	}
+	return moved, nil
+}
+
+// recordOPMChecksums verifies the opm binaries moved to binDir against the
+// layers of the catalog image img they were extracted from to extractDir,
+// and records their checksums in binDir. extracted maps the name of each
+// binary in binDir to the path it was extracted to.
+func recordOPMChecksums(img v1.Image, extractDir, binDir string, extracted map[string]string) error {
+	sums := make(map[string]string, len(extracted))
+	files := make(map[string]string, len(extracted))
+	for name, extractedPath := range extracted {
+		rel, err := filepath.Rel(extractDir, extractedPath)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("opm binary %s is outside of the extracted catalog image", extractedPath)
+		}
+		sum, err := fileSHA256(filepath.Join(binDir, name))
+		if err != nil {
+			return err
+		}
+		sums[name] = sum
+		files[filepath.ToSlash(rel)] = sum
+	}
+	if err := verifyOPMLayers(img, files); err != nil {
+		return err
+	}
+	return writeOPMChecksums(binDir, sums)
+}
+
+// verifyOPMLayers verifies the opm binaries extracted from img against its
+// layers. files maps the path of each binary in the image to the sha256 of
+// the extracted file. The layers are read from the top until every binary
+// is found, each of them checked against the diff ID of the image config,
+// as a binary is the file of the topmost layer holding it.
+func verifyOPMLayers(img v1.Image, files map[string]string) error {
+	layers, err := img.Layers()
+	if err != nil {
+		return fmt.Errorf("error reading the layers of the catalog image: %v", err)
+	}
+	pending := make(map[string]string, len(files))
+	for name, sum := range files {
+		pending[name] = sum
+	}
+	for i := len(layers) - 1; i >= 0 && len(pending) != 0; i-- {
+		found, err := layerFileDigests(layers[i], pending)
+		if err != nil {
+			return err
+		}
+		for name, sum := range found {
+			if sum != pending[name] {
+				return fmt.Errorf("opm binary %s does not match the catalog image: extracted sha256 %s, image layer sha256 %s", name, pending[name], sum)
+			}
+			delete(pending, name)
+		}
+	}
+	if len(pending) != 0 {
+		var missing []string
+		for name := range pending {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return fmt.Errorf("opm binaries %s not found in the layers of the catalog image", strings.Join(missing, ", "))
+	}
+	klog.V(1).Infof("Verified %d opm binaries against the layers of the catalog image", len(files))
+	return nil
+}
+
+// layerFileDigests returns the sha256 of the regular files of layer whose
+// path is in wanted, after checking the layer against its diff ID.
+func layerFileDigests(layer v1.Layer, wanted map[string]string) (map[string]string, error) {
+	diffID, err := layer.DiffID()
+	if err != nil {
+		return nil, err
+	}
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return nil, fmt.Errorf("error reading layer %s of the catalog image: %v", diffID, err)
+	}
+	defer rc.Close()
+	layerHash := sha256.New()
+	r := io.TeeReader(rc, layerHash)
+	tr := tar.NewReader(r)
+	// Hard links refer to the files before them in the layer.
+	sums := map[string]string{}
+	found := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading layer %s of the catalog image: %v", diffID, err)
+		}
+		name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			h := sha256.New()
+			if _, err := io.Copy(h, tr); err != nil {
+				return nil, fmt.Errorf("error reading layer %s of the catalog image: %v", diffID, err)
+			}
+			sums[name] = fmt.Sprintf("%x", h.Sum(nil))
+		case tar.TypeLink:
+			sums[name] = sums[strings.TrimPrefix(path.Clean("/"+hdr.Linkname), "/")]
+		default:
+			continue
+		}
+		if _, ok := wanted[name]; ok {
+			found[name] = sums[name]
+		}
+	}
+	// Read the padding after the end of the archive.
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return nil, fmt.Errorf("error reading layer %s of the catalog image: %v", diffID, err)
+	}
+	if digest := fmt.Sprintf("sha256:%x", layerHash.Sum(nil)); digest != diffID.String() {
+		return nil, fmt.Errorf("layer %s of the catalog image does not match its diff ID, read %s", diffID, digest)
+	}
+	return found, nil
+}
+
+// fileSHA256 returns the hex encoded sha256 of the file at fpath.
+func fileSHA256(fpath string) (string, error) {
+	h := sha256.New()
+	if err := hashFile(h, fpath); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// writeOPMChecksums writes the checksums sums of the opm binaries
+// of binDir, keyed by binary name, to the opmChecksumsFile of binDir.
+func writeOPMChecksums(binDir string, sums map[string]string) error {
+	names := make([]string, 0, len(sums))
+	for name := range sums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s  %s\n", sums[name], name)
+	}
+	return os.WriteFile(filepath.Join(binDir, opmChecksumsFile), []byte(b.String()), 0600)
+}
+
+// verifyOPMChecksum returns an error if the opm binary at opmPath does not
+// match the checksum recorded when it was extracted from the catalog image.
+func verifyOPMChecksum(opmPath string) error {
+	f, err := os.Open(filepath.Join(filepath.Dir(opmPath), opmChecksumsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return errors.New("no checksum recorded when the catalog was extracted")
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	want := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == filepath.Base(opmPath) {
+			want = fields[0]
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if want == "" {
+		return errors.New("no checksum recorded when the catalog was extracted")
+	}
+	got, err := fileSHA256(opmPath)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("sha256 %s does not match the checksum %s recorded when the catalog was extracted", got, want)
+	}
+	return nil
+}
+
+// opmCommand returns the command running the opm binary at opmPath with
+// args. With sandbox, opm runs with an empty environment in new user,
+// network, IPC, UTS and PID namespaces, without network access and without
+// seeing or signaling the other processes of the host, and is killed if
+// oc-mirror exits. Sandboxing requires Linux and unprivileged user namespaces.
+func opmCommand(opmPath string, sandbox bool, args ...string) (*exec.Cmd, error) {
+	cmd := exec.Command(opmPath, args...)
+	if !sandbox {
+		return cmd, nil
+	}
+	cmd.Env = []string{}
+	if err := sandboxOPMCommand(cmd); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// opmStartError adds to err, returned by an opm command
+// with sandbox, the requirement of the sandbox it may miss.
+func opmStartError(err error, sandbox bool) error {
+	if sandbox && (errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EINVAL)) {
+		return fmt.Errorf("%v: --opm-sandbox requires unprivileged user namespaces", err)
+	}
+	return err
+}
+
+// opmCandidate is an opm binary that may regenerate catalog caches.
+type opmCandidate struct {
+	path   string
+	source string
+}
+
+// findOpmCmd returns the path of the opm binary regenerating the cache of the
+// catalog extracted to artifactDir, and where it comes from. In order, it is the
+// trusted binary trustedOpm set by --opm-binary, the binary set by OPM_BINARY, the opm
+// binary of the catalog, the opm binary built for the host platform shipped by
+// the catalog, then the opm binary in PATH. The binaries of the catalog are only
+// used if they run on the host platform and match the checksums recorded when
+// they were extracted from the catalog image.
+func findOpmCmd(artifactDir, trustedOpm string) (string, string, error) {
+	if trustedOpm != "" {
+		if _, err := os.Stat(trustedOpm); err != nil {
+			return "", "", fmt.Errorf("opm binary set by --opm-binary: %v", err)
+		}
+		return trustedOpm, "--opm-binary", nil
+	}
+	if opmBinary := os.Getenv("OPM_BINARY"); opmBinary != "" {
+		if _, err := os.Stat(opmBinary); err != nil {
+			return "", "", fmt.Errorf("opm binary set by OPM_BINARY: %v", err)
+		}
+		return opmBinary, "OPM_BINARY", nil
+	}
+
+	binDir := filepath.Join(artifactDir, config.OpmBinDir)
+	candidates := []opmCandidate{
+		{path: filepath.Join(binDir, "opm"), source: "catalog"},
+		{path: filepath.Join(binDir, platformOpmName(runtime.GOOS, runtime.GOARCH)), source: fmt.Sprintf("catalog, built for %s/%s", runtime.GOOS, runtime.GOARCH)},
+	}
+	var rejected []string
+	for _, c := range candidates {
+		err := runsOn(c.path, runtime.GOOS, runtime.GOARCH)
+		if err == nil {
+			err = verifyOPMChecksum(c.path)
+		}
+		if err == nil {
+			return c.path, c.source, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			rejected = append(rejected, fmt.Sprintf("%s: %v", c.path, err))
+		}
+	}
+	if opmPath, err := exec.LookPath("opm"); err == nil {
+		return opmPath, "PATH", nil
+	}
+	if len(rejected) == 0 {
+		return "", "", fmt.Errorf("no opm binary in the catalog or in PATH, use --opm-binary or set OPM_BINARY to an opm binary for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	return "", "", fmt.Errorf("no opm binary for %s/%s in the catalog or in PATH (%s), use --opm-binary or set OPM_BINARY to an opm binary for %s/%s",
+		runtime.GOOS, runtime.GOARCH, strings.Join(rejected, "; "), runtime.GOOS, runtime.GOARCH)
+}
+
+// runsOn returns an error if the binary at path is not an executable for goos and goarch.
+func runsOn(path, goos, goarch string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	binOS, binArchs, err := binaryPlatform(path)
+	if err != nil {
+		return err
+	}
+	if binOS != goos {
+		return fmt.Errorf("built for %s, not %s", binOS, goos)
+	}
+	for _, arch := range binArchs {
+		if arch == goarch {
+			return nil
+		}
+	}
+	return fmt.Errorf("built for %s/%s, not %s/%s", binOS, strings.Join(binArchs, ","), goos, goarch)
+}
+
+// binaryPlatform returns the operating system and the architectures
+// of the executable at path, several for universal macOS binaries.
+func binaryPlatform(path string) (string, []string, error) {
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		return "linux", []string{elfArch(f)}, nil
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		return "darwin", []string{machoArch(f.Cpu)}, nil
+	}
+	if f, err := macho.OpenFat(path); err == nil {
+		defer f.Close()
+		var archs []string
+		for _, a := range f.Arches {
+			archs = append(archs, machoArch(a.Cpu))
+		}
+		return "darwin", archs, nil
+	}
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		return "windows", []string{peArch(f.Machine)}, nil
+	}
+	return "", nil, errors.New("not an executable")
+}
+
+func elfArch(f *elf.File) string {
+	switch f.Machine {
+	case elf.EM_X86_64:
+		return "amd64"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_PPC64:
+		if f.ByteOrder.String() == "LittleEndian" {
+			return "ppc64le"
+		}
+		return "ppc64"
+	case elf.EM_S390:
+		return "s390x"
+	default:
+		return f.Machine.String()
+	}
+}
+
+func machoArch(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.CpuAmd64:
+		return "amd64"
+	case macho.CpuArm64:
+		return "arm64"
+	default:
+		return cpu.String()
+	}
+}
+
+func peArch(machine uint16) string {
+	switch machine {
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return "amd64"
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return "arm64"
+	default:
+		return fmt.Sprintf("machine %#x", machine)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build linux
+
+package mirror
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// checkOPMSandbox returns an error if --opm-sandbox is
+// not supported on the platform.
+func checkOPMSandbox() error {
+	return nil
+}
+
+// sandboxOPMCommand runs cmd in new user, network, IPC, UTS and PID
+// namespaces, and kills it if oc-mirror exits.
+func sandboxOPMCommand(cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS | syscall.CLONE_NEWPID,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+		Pdeathsig:   syscall.SIGKILL,
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build linux
+
+package mirror
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOPMSandbox(t *testing.T) {
+	require.NoError(t, checkOPMSandbox())
+
+	cmd, err := opmCommand("/bin/opm", true, "serve", "/configs")
+	require.NoError(t, err)
+	require.Equal(t, []string{}, cmd.Env)
+	require.NotZero(t, cmd.SysProcAttr.Cloneflags&syscall.CLONE_NEWNET)
+	require.NotZero(t, cmd.SysProcAttr.Cloneflags&syscall.CLONE_NEWUSER)
+	require.Equal(t, syscall.SIGKILL, cmd.SysProcAttr.Pdeathsig)
+
+	require.EqualError(t, opmStartError(syscall.EPERM, true), "operation not permitted: --opm-sandbox requires unprivileged user namespaces")
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package mirror
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// checkOPMSandbox returns an error if --opm-sandbox is
+// not supported on the platform.
+func checkOPMSandbox() error {
+	return errors.New("--opm-sandbox is only supported on Linux")
+}
+
+// sandboxOPMCommand fails, opm cannot be sandboxed on the platform.
+func sandboxOPMCommand(cmd *exec.Cmd) error {
+	return checkOPMSandbox()
+}
@@ -0,0 +1,208 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+func TestRunsOn(t *testing.T) {
+	// The test binary runs on the host platform.
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	require.NoError(t, runsOn(exe, runtime.GOOS, runtime.GOARCH))
+	require.ErrorContains(t, runsOn(exe, "plan9", runtime.GOARCH), "not plan9")
+	require.ErrorContains(t, runsOn(exe, runtime.GOOS, "mips"), "/mips")
+
+	script := filepath.Join(t.TempDir(), "opm")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0700))
+	require.EqualError(t, runsOn(script, runtime.GOOS, runtime.GOARCH), "not an executable")
+	require.ErrorIs(t, runsOn(filepath.Join(t.TempDir(), "missing"), runtime.GOOS, runtime.GOARCH), os.ErrNotExist)
+}
+
+func TestFindOpmCmd(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	// Hide any opm binary installed on the host.
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("OPM_BINARY", "")
+
+	newArtifactDir := func(t *testing.T, binaries map[string]string) string {
+		dir := t.TempDir()
+		binDir := filepath.Join(dir, config.OpmBinDir)
+		require.NoError(t, os.MkdirAll(binDir, 0750))
+		sums := map[string]string{}
+		for name, target := range binaries {
+			require.NoError(t, os.Symlink(target, filepath.Join(binDir, name)))
+			sum, err := fileSHA256(target)
+			require.NoError(t, err)
+			sums[name] = sum
+		}
+		require.NoError(t, writeOPMChecksums(binDir, sums))
+		return dir
+	}
+	foreign := filepath.Join(t.TempDir(), "foreign")
+	require.NoError(t, os.WriteFile(foreign, []byte("not a binary"), 0700))
+
+	t.Run("Valid/CatalogOpm", func(t *testing.T) {
+		dir := newArtifactDir(t, map[string]string{"opm": exe})
+		path, source, err := findOpmCmd(dir, "")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, config.OpmBinDir, "opm"), path)
+		require.Equal(t, "catalog", source)
+	})
+
+	t.Run("Valid/PlatformOpm", func(t *testing.T) {
+		platformOpm := platformOpmName(runtime.GOOS, runtime.GOARCH)
+		dir := newArtifactDir(t, map[string]string{"opm": foreign, platformOpm: exe})
+		path, _, err := findOpmCmd(dir, "")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, config.OpmBinDir, platformOpm), path)
+	})
+
+	t.Run("Valid/PathOpm", func(t *testing.T) {
+		pathDir := t.TempDir()
+		require.NoError(t, os.Symlink(exe, filepath.Join(pathDir, "opm")))
+		t.Setenv("PATH", pathDir)
+		dir := newArtifactDir(t, map[string]string{"opm": foreign})
+		path, source, err := findOpmCmd(dir, "")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(pathDir, "opm"), path)
+		require.Equal(t, "PATH", source)
+	})
+
+	t.Run("Valid/OpmBinary", func(t *testing.T) {
+		t.Setenv("OPM_BINARY", foreign)
+		path, source, err := findOpmCmd(newArtifactDir(t, nil), "")
+		require.NoError(t, err)
+		require.Equal(t, foreign, path)
+		require.Equal(t, "OPM_BINARY", source)
+	})
+
+	t.Run("Valid/TrustedOpm", func(t *testing.T) {
+		t.Setenv("OPM_BINARY", exe)
+		path, source, err := findOpmCmd(newArtifactDir(t, map[string]string{"opm": exe}), foreign)
+		require.NoError(t, err)
+		require.Equal(t, foreign, path)
+		require.Equal(t, "--opm-binary", source)
+	})
+
+	t.Run("Invalid/TrustedOpmMissing", func(t *testing.T) {
+		_, _, err := findOpmCmd(newArtifactDir(t, nil), filepath.Join(t.TempDir(), "opm"))
+		require.ErrorContains(t, err, "opm binary set by --opm-binary")
+	})
+
+	t.Run("Invalid/ChecksumMismatch", func(t *testing.T) {
+		dir := newArtifactDir(t, map[string]string{"opm": exe})
+		binDir := filepath.Join(dir, config.OpmBinDir)
+		require.NoError(t, writeOPMChecksums(binDir, map[string]string{"opm": "0000"}))
+		_, _, err := findOpmCmd(dir, "")
+		require.ErrorContains(t, err, filepath.Join(binDir, "opm")+": sha256")
+		require.ErrorContains(t, err, "does not match the checksum 0000 recorded when the catalog was extracted")
+	})
+
+	t.Run("Invalid/NoChecksum", func(t *testing.T) {
+		dir := newArtifactDir(t, map[string]string{"opm": exe})
+		require.NoError(t, os.Remove(filepath.Join(dir, config.OpmBinDir, opmChecksumsFile)))
+		_, _, err := findOpmCmd(dir, "")
+		require.ErrorContains(t, err, "opm: no checksum recorded when the catalog was extracted")
+	})
+
+	t.Run("Invalid/ForeignOpm", func(t *testing.T) {
+		dir := newArtifactDir(t, map[string]string{"opm": foreign})
+		_, _, err := findOpmCmd(dir, "")
+		require.ErrorContains(t, err, filepath.Join(dir, config.OpmBinDir, "opm")+": not an executable")
+		require.ErrorContains(t, err, "set OPM_BINARY")
+	})
+
+	t.Run("Invalid/NoOpm", func(t *testing.T) {
+		_, _, err := findOpmCmd(newArtifactDir(t, nil), "")
+		require.ErrorContains(t, err, "no opm binary in the catalog or in PATH")
+	})
+}
+
+func TestCopyPlatformOPMBinaries(t *testing.T) {
+	srcDir := t.TempDir()
+	binDir := t.TempDir()
+	for _, name := range []string{"opm", "darwin-amd64-opm", "windows-amd64-opm"} {
+		require.NoError(t, os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0700))
+	}
+	moved, err := copyPlatformOPMBinaries(srcDir, binDir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"darwin-amd64-opm":  filepath.Join(srcDir, "darwin-amd64-opm"),
+		"windows-amd64-opm": filepath.Join(srcDir, "windows-amd64-opm"),
+	}, moved)
+	require.FileExists(t, filepath.Join(binDir, "darwin-amd64-opm"))
+	require.FileExists(t, filepath.Join(binDir, "windows-amd64-opm"))
+	require.NoFileExists(t, filepath.Join(binDir, "opm"))
+}
+
+func TestRecordOPMChecksums(t *testing.T) {
+	base, err := crane.Layer(map[string][]byte{"bin/opm": []byte("old opm"), "bin/darwin-amd64-opm": []byte("darwin opm")})
+	require.NoError(t, err)
+	top, err := crane.Layer(map[string][]byte{"bin/opm": []byte("opm"), "configs/index.json": []byte("{}")})
+	require.NoError(t, err)
+	img, err := mutate.AppendLayers(empty.Image, base, top)
+	require.NoError(t, err)
+
+	newBinDir := func(t *testing.T, binaries map[string]string) string {
+		binDir := t.TempDir()
+		for name, content := range binaries {
+			require.NoError(t, os.WriteFile(filepath.Join(binDir, name), []byte(content), 0700))
+		}
+		return binDir
+	}
+	extractDir := "/workspace/extracted"
+	extracted := map[string]string{
+		"opm":              filepath.Join(extractDir, "bin", "opm"),
+		"darwin-amd64-opm": filepath.Join(extractDir, "bin", "darwin-amd64-opm"),
+	}
+
+	t.Run("Valid/TopmostLayer", func(t *testing.T) {
+		binDir := newBinDir(t, map[string]string{"opm": "opm", "darwin-amd64-opm": "darwin opm"})
+		require.NoError(t, recordOPMChecksums(img, extractDir, binDir, extracted))
+		require.NoError(t, verifyOPMChecksum(filepath.Join(binDir, "opm")))
+		require.NoError(t, verifyOPMChecksum(filepath.Join(binDir, "darwin-amd64-opm")))
+
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, "opm"), []byte("tampered"), 0700))
+		require.ErrorContains(t, verifyOPMChecksum(filepath.Join(binDir, "opm")), "does not match the checksum")
+	})
+
+	t.Run("Invalid/LayerMismatch", func(t *testing.T) {
+		binDir := newBinDir(t, map[string]string{"opm": "old opm", "darwin-amd64-opm": "darwin opm"})
+		err := recordOPMChecksums(img, extractDir, binDir, extracted)
+		require.ErrorContains(t, err, "opm binary bin/opm does not match the catalog image")
+		require.NoFileExists(t, filepath.Join(binDir, opmChecksumsFile))
+	})
+
+	t.Run("Invalid/NotInLayers", func(t *testing.T) {
+		binDir := newBinDir(t, map[string]string{"opm": "opm"})
+		err := recordOPMChecksums(img, extractDir, binDir, map[string]string{"opm": filepath.Join(extractDir, "usr", "bin", "opm")})
+		require.EqualError(t, err, "opm binaries usr/bin/opm not found in the layers of the catalog image")
+	})
+
+	t.Run("Invalid/OutsideExtraction", func(t *testing.T) {
+		binDir := newBinDir(t, map[string]string{"opm": "opm"})
+		err := recordOPMChecksums(img, extractDir, binDir, map[string]string{"opm": "/usr/bin/opm"})
+		require.EqualError(t, err, "opm binary /usr/bin/opm is outside of the extracted catalog image")
+	})
+}
+
+func TestOPMCommand(t *testing.T) {
+	cmd, err := opmCommand("/bin/opm", false, "serve", "/configs")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/bin/opm", "serve", "/configs"}, cmd.Args)
+	require.Nil(t, cmd.SysProcAttr)
+
+	require.Equal(t, syscall.EPERM, opmStartError(syscall.EPERM, false))
+}
@@ -6,52 +6,132 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"text/template"
+	"time"
 
+	imgreference "github.com/openshift/library-go/pkg/image/reference"
 	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
 
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
 	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/image"
 )
 
 type MirrorOptions struct {
 	*cli.RootOptions
-	OutputDir                           string // directory path, whose value is dependent on how oc mirror was invoked
-	ConfigPath                          string // Path to imageset configuration file
-	SkipImagePin                        bool   // Do not replace image tags with digest pins in operator catalogs
-	ManifestsOnly                       bool   // Generate manifests and do not mirror
-	From                                string // Path to an input file (e.g. archived imageset)
-	ToMirror                            string // Final destination for the mirror operation
-	UserNamespace                       string // The <namespace>/<image> portion of a docker reference only
-	DryRun                              bool   // Print actions without mirroring images
-	SourceSkipTLS                       bool   // Disable TLS validation for source registry
-	DestSkipTLS                         bool   // Disable TLS validation for destination registry
-	V2                                  bool   // Redirect the flow to oc-mirror v2 - PLEASE DO NOT USE that. V2 is still under development and it is not ready to be used.
-	V1                                  bool   // Redirect the flow to oc-mirror v1 - This flag is going to redirect the flow to v1 (legacy code) when v2 becomes the default (still under development).
-	SourcePlainHTTP                     bool   // Use plain HTTP for source registry
-	DestPlainHTTP                       bool   // Use plain HTTP for destination registry
-	SkipVerification                    bool   // Skip verifying the integrity of the retrieved content.
-	SkipCleanup                         bool   // Skip removal of artifact directories
-	SkipMissing                         bool   // If an input image is not found, skip them.
-	SkipMetadataCheck                   bool   // Skip metadata when publishing an imageset
-	SkipPruning                         bool   // If set, will disable pruning globally
-	ContinueOnError                     bool   // If an error occurs, keep going and attempt to complete operations if possible
-	IgnoreHistory                       bool   // Ignore past mirrors when downloading images and packing layers
-	MaxPerRegistry                      int    // Number of concurrent requests allowed per registry
-	OCIRegistriesConfig                 string // Registries config file location (it works only with local oci catalogs)
-	OCIInsecureSignaturePolicy          bool   // If set, OCI catalog push will not try to push signatures
-	EnableOperatorSignatureVerification bool   // If set, verifies operator catalog signatures prior to mirroring
+	OutputDir                           string                          // directory path, whose value is dependent on how oc mirror was invoked
+	ConfigPath                          string                          // Path to imageset configuration file
+	ImageSetConfig                      *v1alpha2.ImageSetConfiguration // Imageset configuration used instead of ConfigPath when embedding oc-mirror
+	Profile                             string                          // Name of the imageset configuration profile merged onto the base configuration
+	Overlays                            []string                        // Paths to imageset configurations merged onto the base configuration before the profile
+	SkipImagePin                        bool                            // Do not replace image tags with digest pins in operator catalogs
+	ManifestsOnly                       bool                            // Generate manifests and do not mirror
+	From                                string                          // Path to an input file (e.g. archived imageset)
+	ToMirror                            string                          // Final destination for the mirror operation
+	UserNamespace                       string                          // The <namespace>/<image> portion of a docker reference only
+	DryRun                              bool                            // Print actions without mirroring images
+	SourceSkipTLS                       bool                            // Disable TLS validation for source registry
+	DestSkipTLS                         bool                            // Disable TLS validation for destination registry
+	V2                                  bool                            // Redirect the flow to oc-mirror v2 - PLEASE DO NOT USE that. V2 is still under development and it is not ready to be used.
+	V1                                  bool                            // Redirect the flow to oc-mirror v1 - This flag is going to redirect the flow to v1 (legacy code) when v2 becomes the default (still under development).
+	SourcePlainHTTP                     bool                            // Use plain HTTP for source registry
+	DestPlainHTTP                       bool                            // Use plain HTTP for destination registry
+	SkipVerification                    bool                            // Skip verifying the integrity of the retrieved content.
+	SkipCleanup                         bool                            // Skip removal of artifact directories
+	SkipMissing                         bool                            // If an input image is not found, skip them.
+	SkipMetadataCheck                   bool                            // Skip metadata when publishing an imageset
+	SkipPruning                         bool                            // If set, will disable pruning globally
+	ContinueOnError                     bool                            // If an error occurs, keep going and attempt to complete operations if possible
+	IgnoreHistory                       bool                            // Ignore past mirrors when downloading images and packing layers
+	ForceFull                           bool                            // Mirror again every image of the configuration and replace the associations of past mirrors, continuing the sequence
+	MaxPerRegistry                      int                             // Number of concurrent requests allowed per registry
+	OCIRegistriesConfig                 string                          // Registries config file location (it works only with local oci catalogs)
+	OCIInsecureSignaturePolicy          bool                            // If set, OCI catalog push will not try to push signatures
+	EnableOperatorSignatureVerification bool                            // If set, verifies operator catalog signatures prior to mirroring
+	ResolveTimeout                      time.Duration                   // Time allowed to resolve the digests of the images pushed by the run, for eventually consistent registries
 	MaxNestedPaths                      int
-	RebuildCatalogs                     bool // If set, rebuilds catalogs based on filtered declarative config, and regenerates the cache of that catalog
-	BuildCatalogCache                   bool // If set (defaults to false), attempt to build catalog cache while building catalogs, using OPM_BINARY if provided, otherwise opm binary from catalog.
+	RebuildCatalogs                     bool     // If set, rebuilds catalogs based on filtered declarative config, and regenerates the cache of that catalog
+	BuildCatalogCache                   bool     // If set (defaults to false), attempt to build catalog cache while building catalogs, using OPM_BINARY if provided, otherwise opm binary from catalog.
+	CredentialProviders                 []string // Credential providers consulted before the docker/podman credential files
+	AuthFiles                           []string // Auth files merged over the default auth file, each taking precedence over the ones before it
+	ExportCatalogs                      bool     // If set, writes the filtered declarative config of each catalog to the results directory
+	ExportCatalogsArchive               bool     // If set with ExportCatalogs, also writes each exported catalog as a tar.gz archive
+	SkipPreflight                       bool     // If set, skips the registry readiness checks run before publishing
+	PreflightPushTest                   bool     // If set, the registry readiness checks push and delete a test image index
+	SkipPublished                       bool     // If set, the images already at the destination are not published again
+	AdditionalMirrors                   []string // Additional docker:// destinations the images are mirrored to
+	Annotations                         []string // key=value pairs recorded in the metadata of the mirror operation
+	BaselineCatalogs                    []string // Catalog images pinned by digest whose bundles and related images are already mirrored
+	PushReleaseSignatures               bool     // If set, pushes the release signatures to the destination registry as artifacts referring to the release images
+	DiffByChannelHeads                  bool     // If set, computes incremental operator catalog diffs from the channel heads recorded in the metadata
+	ManifestOutput                      string   // Layout of the generated cluster resource manifests, single-file or per-resource
+	SourceSkipTLSRegistries             []string // Source registry hosts whose TLS certificates are not verified
+	DestSkipTLSRegistries               []string // Destination registry hosts whose TLS certificates are not verified
+	RegistryOverrides                   string   // Path to the per-registry configuration (insecure, plain-http, mirror-of) of the registries
+	Workspace                           string   // Workspace directory, instead of oc-mirror-workspace in the output directory
+	MaxArchiveFiles                     int      // Maximum number of files per imageset archive, 0 for no limit
+	ArchivesPerDir                      int      // Number of imageset archives per numbered subdirectory of the output directory, 0 for no subdirectories
+	CompatFormat                        string   // Format of the imageset archives created, for older oc-mirror versions publishing them
+	MaxDownloadSize                     string   // Maximum size of the images downloaded by a run, as a quantity such as 50Gi
+	ICSPBudget                          string   // Maximum total size of the generated ICSPs, as a quantity such as 512Ki, consolidating their mirrors when set
+	PreserveDigests                     bool     // If set, fails when the digest of a mirrored image differs from its source, the images rebuilt by oc-mirror excepted
+	OCIMediaTypes                       bool     // If set, converts the manifests and configs of the published images to OCI media types
+	QuayCreateRepositories              bool     // If set, creates the organizations and repositories of the destination with the Quay API before pushing
+	CreateRepos                         bool     // If set, creates the repositories of the ECR and Artifact Registry destinations with their API before pushing
+	OperatorDependencies                string   // Handling of the operator dependencies missing from the filtered catalogs, permissive or strict
+	ReleaseDigests                      string   // Path to a file listing the release payloads to mirror by digest, instead of resolving the release channels with Cincinnati
+	Lock                                bool     // If set, locks the workspace and the destinations for the run and skips runs whose configuration and catalogs are unchanged
+	TagTemplates                        []string // type=template pairs rendering the destination tags of the images of each type
+	MetricsAddress                      string   // Address serving the Prometheus metrics of the run on /metrics while it runs
+	MetricsFile                         string   // Path of the file the Prometheus metrics of the run are written to when it completes
+	MappingFormat                       string   // Format of the image list written next to mapping.txt, skopeo-sync or docker-save
+	TUI                                 bool     // If set, shows the progress of the run in a terminal UI instead of its logs
+	OPMBinary                           string   // Path of a trusted opm binary regenerating the catalog caches, instead of the opm binary of the catalogs
+	OPMSandbox                          bool     // If set, runs opm without network access and environment, in new user, network, IPC, UTS and PID namespaces
+	UploadChunkSize                     string   // Maximum size of the blob upload requests, as a quantity such as 5Mi
+	MaxConcurrentUploads                int      // Maximum number of blob uploads in progress at the same time
+	ApplySignaturesKubeconfig           string   // Path to the kubeconfig of a cluster the release signature ConfigMaps are applied to
+	SeedFormat                          string   // Format of the seed archive written instead of imagesets, docker-archive or oci-archive
 	// cancelCh is a channel listening for command cancellations
 	cancelCh                          <-chan struct{}
 	once                              sync.Once
 	continuedOnError                  bool
+	destinations                      []mirrorDestination                          // every registry destination, set by Complete when --to is used
+	annotations                       map[string]string                            // parsed Annotations, set by Complete
+	baselineCatalogs                  map[string]imgreference.DockerImageReference // parsed BaselineCatalogs keyed by catalog repository, set by Complete
+	baselineExcluded                  []string                                     // source images not mirrored as referenced by a baseline catalog, kept in the history
+	catalogImages                     map[string]catalogImageSet                   // images of each rebuilt catalog keyed by CatalogSource name, for the operator ICSPs
+	resolvedTags                      map[string]string                            // digests of the additional images referenced by tag, set by AdditionalOptions.Plan
+	plannedDownload                   int64                                        // estimated bytes of the images planned for download by the run
+	archiveCompression                *v1alpha2.ArchiveCompression                 // compression of the imageset archives, from the imageset configuration
+	convertedDigests                  map[string]string                            // digests of the images converted to OCI media types keyed by original digest, set by Publish
+	artifacts                         []v1alpha2.ArtifactMetadata                  // OCI artifacts of the run with their digests, set by ArtifactOptions.PullArtifacts
+	pulledArtifacts                   int                                          // number of OCI artifacts pulled to the workspace by the run
+	quayClients                       map[string]*quayClient                       // Quay API clients keyed by registry, for --quay-create-repositories
+	repoCreators                      map[string]repositoryCreator                 // cloud registry API clients keyed by registry, nil for registries creating repositories on push, for --create-repos
+	tagTemplates                      map[v1alpha2.ImageType]*template.Template    // parsed TagTemplates, set by Complete
+	namespaceMappings                 []v1alpha2.NamespaceMapping                  // mirror.namespaceMappings of the configuration or of the imageset published
+	blocked                           []blockedImage                               // images removed by the blocked images of the configuration, set by run
+	samples                           *v1alpha2.SamplesMetadata                    // sample imagestreams of the run or of the imageset published
+	authConfigDir                     string                                       // directory of the merged auth file read when rendering catalogs, set by Validate
+	prevAssociations                  *image.AssociationDB                         // associations of the previous sequences streamed from the metadata, set by Create
 	remoteRegFuncs                    RemoteRegFuncs
+	summary                           runSummary        // summary of the run posted to the notification endpoints
+	metrics                           *runMetrics       // metrics of the run, set by Mirror with --metrics-address or --metrics-file
+	phase                             string            // phase of the run, set by startPhase
+	imagesMirrored                    int               // number of images mirrored by the run, set by countMirrored
+	monitor                           *runMonitor       // terminal UI of the run, set by Mirror with --tui
+	clock                             func() time.Time  // local clock compared to the registry clocks, time.Now if nil
+	clockSkew                         *clockSkewMonitor // clock skew diagnostics of the registries of the run, set by clockSkewMonitor
 	operatorCatalogToFullArtifactPath map[string]string // stores temporary paths to declarative config directory key: OCI URI (e.g. oci://foo which originates with v1alpha2.Operator.Catalog) value: <current working directory>/olm_artifacts/<repo>/<config folder>
 }
 
 func (o *MirrorOptions) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVarP(&o.ConfigPath, "config", "c", o.ConfigPath, "Path to imageset configuration file")
+	fs.StringVar(&o.Profile, "profile", o.Profile, "Name of a profile in the imageset configuration to merge onto the base configuration")
+	fs.StringArrayVar(&o.Overlays, "overlay", o.Overlays, "Path to an imageset configuration to merge onto the base configuration before the profile. "+
+		"Can be repeated, each overlay taking precedence over the previous ones")
 	fs.BoolVar(&o.SkipImagePin, "skip-image-pin", o.SkipImagePin, "Do not replace image tags with digest pins in operator catalogs")
 	fs.StringVar(&o.From, "from", o.From, "Path to an input file (e.g. archived imageset)")
 	fs.BoolVar(&o.ManifestsOnly, "manifests-only", o.ManifestsOnly, "Generate manifests and do not mirror")
@@ -67,6 +147,8 @@ func (o *MirrorOptions) BindFlags(fs *pflag.FlagSet) {
 		"Only bypass verification if the registry is known to be trustworthy.")
 	fs.BoolVar(&o.SkipCleanup, "skip-cleanup", o.SkipCleanup, "Skip removal of artifact directories")
 	fs.BoolVar(&o.IgnoreHistory, "ignore-history", o.IgnoreHistory, "Ignore past mirrors when downloading images and packing layers")
+	fs.BoolVar(&o.ForceFull, "force-full", o.ForceFull, "Ignore the images mirrored previously and mirror again every image of the configuration, "+
+		"e.g. when the destination registry lost content. The metadata sequence is incremented and its associations are replaced by this mirror's")
 	fs.BoolVar(&o.SkipMetadataCheck, "skip-metadata-check", o.SkipMetadataCheck, "Skip metadata when publishing an imageset."+
 		"This is only recommended when the imageset was created --ignore-history")
 	fs.BoolVar(&o.ContinueOnError, "continue-on-error", o.ContinueOnError, "If an error occurs, keep going "+
@@ -82,6 +164,91 @@ func (o *MirrorOptions) BindFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&o.MaxNestedPaths, "max-nested-paths", 0, "Number of nested paths, for destination registries that limit nested paths")
 	fs.BoolVar(&o.RebuildCatalogs, "rebuild-catalogs", true, "If set (defaults to true), rebuilds catalogs based on filtered declarative config, and regenerates the cache of that catalog")
 	fs.BoolVar(&o.BuildCatalogCache, "build-catalog-cache", false, "If set (defaults to false), attempt to build catalog cache while building catalogs, using OPM_BINARY if provided, otherwise opm binary from catalog.")
+	fs.StringArrayVar(&o.CredentialProviders, "credential-provider", o.CredentialProviders, "Credential provider to consult, in order, before the docker/podman "+
+		"credential files. One of env, file=<path>, ecr or exec=<command>. Can be specified multiple times")
+	fs.StringArrayVar(&o.AuthFiles, "authfile", o.AuthFiles, "Docker or podman auth file merged over the default auth file, with the pullSecret of the operator catalogs, "+
+		"into the credentials of the run. Later auth files take precedence. Can be specified multiple times")
+	fs.BoolVar(&o.ExportCatalogs, "export-catalogs", o.ExportCatalogs, "If set, writes the filtered declarative config of each operator catalog as a directory in the results directory")
+	fs.BoolVar(&o.ExportCatalogsArchive, "export-catalogs-archive", o.ExportCatalogsArchive, "If set with --export-catalogs, also writes each exported catalog as a tar.gz archive")
+	fs.BoolVar(&o.SkipPreflight, "skip-preflight", o.SkipPreflight, "If set, skips the registry readiness checks run before publishing an imageset")
+	fs.BoolVar(&o.PreflightPushTest, "preflight-push-test", o.PreflightPushTest, "If set, the registry readiness checks push an empty OCI image index to "+
+		"<namespace>/oc-mirror:oc-mirror-preflight and delete it, to check that the registry accepts the media types of rebuilt catalogs")
+	fs.BoolVar(&o.SkipPublished, "skip-published", o.SkipPublished, "If set, publishing skips the images whose manifests already exist at the destination, "+
+		"such as the images pushed by a previous publish of the imageset that failed part way, instead of pushing them again")
+	fs.StringArrayVar(&o.AdditionalMirrors, "to", o.AdditionalMirrors, "Additional docker://<registry>[/<namespace>] destination to mirror "+
+		"the images to. Can be specified multiple times, each destination gets its own results sub directory")
+	fs.StringArrayVar(&o.Annotations, "annotation", o.Annotations, "Annotation in the form key=value recorded in the metadata of this mirror operation, "+
+		"e.g. a ticket ID or the site mirrored to. Shown by the describe and list updates commands. Can be specified multiple times")
+	fs.StringArrayVar(&o.BaselineCatalogs, "baseline-catalog", o.BaselineCatalogs, "Catalog image pinned by digest (e.g. registry.redhat.io/redhat/redhat-operator-index@sha256:<digest>) "+
+		"whose bundles and related images are already mirrored. Only the images added to the catalog since are mirrored, "+
+		"even when the metadata of previous runs is missing. The baseline is filtered by the packages of the catalog in the configuration "+
+		"and its images are never pruned. Can be specified once per catalog")
+	fs.BoolVar(&o.PushReleaseSignatures, "push-release-signatures", o.PushReleaseSignatures, "If set, pushes the signatures of the mirrored release images "+
+		"to the destination registry as OCI artifacts referring to each release image, in addition to the release-signatures results directory")
+	fs.BoolVar(&o.DiffByChannelHeads, "diff-by-channel-heads", o.DiffByChannelHeads, "If set, computes incremental operator catalog diffs from the channel heads "+
+		"recorded by the previous mirror, so bundles added to catalogs rebuilt upstream are mirrored")
+	fs.StringVar(&o.ManifestOutput, "manifest-output", o.ManifestOutput, "Layout of the generated cluster resource manifests: "+
+		"single-file writes every resource to clusterResources.yaml, per-resource writes each resource to its own <kind>-<name>.yaml file. "+
+		"By default ImageContentSourcePolicies are written to imageContentSourcePolicy.yaml and each other resource to its own file")
+	fs.StringSliceVar(&o.SourceSkipTLSRegistries, "source-skip-tls-verify-registries", o.SourceSkipTLSRegistries, "Comma-separated source registry host names "+
+		"whose TLS certificates are not verified, while the certificates of every other registry are. Can be specified multiple times")
+	fs.StringSliceVar(&o.DestSkipTLSRegistries, "dest-skip-tls-verify-registries", o.DestSkipTLSRegistries, "Comma-separated destination registry host names "+
+		"whose TLS certificates are not verified, while the certificates of every other registry are. Can be specified multiple times")
+	fs.StringVar(&o.RegistryOverrides, "registry-overrides", o.RegistryOverrides, "Path to a registries.conf style file whose [[registry]] tables "+
+		"set the insecure, plain-http and mirror-of settings of a registry location, applied to every image operation")
+	fs.StringVar(&o.Workspace, "workspace", o.Workspace, "Directory holding the workspace and temporary files of the mirror operation, "+
+		"instead of oc-mirror-workspace in the output directory. Mirroring to disk checks it has enough free space for the images before downloading them")
+	fs.IntVar(&o.MaxArchiveFiles, "max-archive-files", o.MaxArchiveFiles, "Maximum number of files (manifests and blobs) written to each imageset archive, "+
+		"in addition to the archiveSize limit of the configuration. 0 means no limit")
+	fs.IntVar(&o.ArchivesPerDir, "archives-per-dir", o.ArchivesPerDir, "Group the imageset archives into numbered subdirectories of the output directory "+
+		"holding this number of archives each, e.g. one subdirectory per tape volume. 0 writes every archive to the output directory")
+	fs.StringVar(&o.CompatFormat, "compat-format", o.CompatFormat, "Create imageset archives of an older format (v1 or v2), "+
+		"so they can be published by older oc-mirror versions. Features not supported by the format are disabled. Defaults to the latest format")
+	fs.StringVar(&o.MaxDownloadSize, "max-download-size", o.MaxDownloadSize, "Maximum estimated size of the images downloaded from the source registries by a run, "+
+		"as a quantity such as 500Mi or 50Gi. The run stops before mirroring any image when the estimate exceeds it, listing the largest images")
+	fs.StringVar(&o.ICSPBudget, "icsp-budget", o.ICSPBudget, "Maximum total size of the generated ImageContentSourcePolicies, as a quantity such as 512Ki. "+
+		"When set, the mirrors of every ICSP are aggregated by registry scope and packed into the fewest ICSPs, and the run fails if they exceed the budget")
+	fs.BoolVar(&o.PreserveDigests, "preserve-digests", o.PreserveDigests, "If set, verifies after pushing that every mirrored image kept the digest of its source "+
+		"and fails otherwise. The rebuilt operator catalogs and the Cincinnati graph data image, built by oc-mirror, are reported as the only images with new digests")
+	fs.BoolVar(&o.OCIMediaTypes, "oci-media-types", o.OCIMediaTypes, "If set, converts the Docker v2 schema 2 manifests, manifest lists and configs "+
+		"of the published images to OCI media types, for registries accepting OCI images only. Converted images get new digests, used by the generated mappings and ICSPs")
+	fs.BoolVar(&o.QuayCreateRepositories, "quay-create-repositories", o.QuayCreateRepositories, "If set, creates the organizations and repositories of the destination "+
+		"with the Quay API before pushing to them, authenticating with the OAuth token in the "+quayAPITokenEnv+" environment variable")
+	fs.BoolVar(&o.CreateRepos, "create-repos", o.CreateRepos, "If set, creates the repositories of AWS ECR and Google Artifact Registry destinations, "+
+		"which reject pushes to missing repositories, before pushing to them. ECR authenticates with the AWS credentials of the environment, "+
+		"Artifact Registry with the OAuth access token in the "+googleAccessTokenEnv+" environment variable. Container Registry and Azure Container Registry create repositories on push")
+	fs.StringVar(&o.OperatorDependencies, "operator-dependencies", o.OperatorDependencies, "Handling of the package and GVK dependencies of the mirrored operator bundles "+
+		"missing from the filtered catalogs: permissive (default) includes the ones provided by the catalog and warns about the others, strict fails the run")
+	fs.StringVar(&o.ReleaseDigests, "release-digests", o.ReleaseDigests, "Path to a file listing the release payloads to mirror, one pull spec by digest per line. "+
+		"The release channels of the imageset configuration are not resolved with Cincinnati when set")
+	fs.BoolVar(&o.Lock, "lock", o.Lock, "If set, for scheduled runs: fails fast when another run holds the workspace or a destination, "+
+		"and completes without mirroring when the imageset configuration and the digests of its catalogs are unchanged since the last run. "+
+		"Destinations are locked in the temporary directory of the host, so only runs of the same host sharing that directory are excluded")
+	fs.StringArrayVar(&o.TagTemplates, "tag-template", o.TagTemplates, "Template of the destination tags of the images of a type, in the form type=template, "+
+		"such as operatorBundle={{ .Component }}-{{ .DigestShort }}. The fields are .Component, .Repository, .Version, .Tag, .Digest, .DigestShort and .Type. "+
+		"Types are ocpRelease, ocpReleaseContent, operatorCatalog, operatorBundle, operatorRelatedImage and generic. Can be repeated")
+	fs.StringVar(&o.MetricsAddress, "metrics-address", o.MetricsAddress, "Address, such as localhost:9090, serving the Prometheus metrics of the run on /metrics while it runs: "+
+		"images mirrored, bytes transferred, errors by class and duration of each phase")
+	fs.StringVar(&o.MetricsFile, "metrics-file", o.MetricsFile, "Path of a file the Prometheus metrics of the run are written to when it completes, "+
+		"in the text format read by the textfile collector of the node exporter")
+	fs.StringVar(&o.MappingFormat, "mapping-format", o.MappingFormat, "Also write the source images of mapping.txt for another transfer tool: "+
+		"skopeo-sync writes the YAML source of skopeo sync to skopeo-sync.yaml, docker-save writes the images to pull and docker save to docker-save.txt, one per line")
+	fs.BoolVar(&o.TUI, "tui", o.TUI, "If set, shows the progress of the run in a terminal UI instead of its logs, which are written to .oc-mirror.log: "+
+		"a progress bar per image category, the images being mirrored, the transfer rate and the errors. Press p to pause or resume the run and q to abort it")
+	fs.StringVar(&o.OPMBinary, "opm-binary", o.OPMBinary, "Path of a trusted opm binary regenerating the caches of the rebuilt catalogs with --build-catalog-cache, "+
+		"instead of the opm binary extracted from each catalog image")
+	fs.BoolVar(&o.OPMSandbox, "opm-sandbox", o.OPMSandbox, "If set, runs opm with an empty environment and without network access, in new user, network, IPC, UTS and PID namespaces, "+
+		"when regenerating the caches of the rebuilt catalogs. Requires unprivileged user namespaces")
+	fs.StringVar(&o.UploadChunkSize, "upload-chunk-size", o.UploadChunkSize, "Maximum size of the blob upload requests sent to the registries, as a quantity such as 5Mi. "+
+		"Larger blobs are uploaded in successive chunks, for registries and proxies limiting the size of the requests, such as Nexus or Artifactory")
+	fs.IntVar(&o.MaxConcurrentUploads, "max-concurrent-uploads", o.MaxConcurrentUploads, "Maximum number of blob uploads in progress at the same time, "+
+		"for registries limiting the concurrent uploads. 0 for no limit")
+	fs.StringVar(&o.ApplySignaturesKubeconfig, "apply-signatures-kubeconfig", o.ApplySignaturesKubeconfig, "Path to the kubeconfig of a cluster the ConfigMaps of the release signatures "+
+		"are applied to in the openshift-config-managed namespace, once written to "+signatureConfigMapsFile+" in the results directory")
+	fs.StringVar(&o.SeedFormat, "seed-format", o.SeedFormat, "When mirroring to disk, writes the images of the run to "+seedArchiveFile+" in the output directory instead of imageset archives, "+
+		"to be loaded with podman load, for instance to seed a mirror registry: docker-archive, holding the linux/amd64 image of the manifest lists, or oci-archive")
+	fs.DurationVar(&o.ResolveTimeout, "resolve-timeout", defaultResolveTimeout, "Time allowed to resolve the digests of the catalog and graph data images "+
+		"pushed by the run, retried with a backoff while the destination does not serve them, as geo-replicated registries can after a push")
 	fs.MarkDeprecated("oci-insecure-signature-policy", "and will be removed in a future release. Use enable-operator-secure-policy instead.")
 	fs.MarkHidden("build-catalog-cache")
 }
@@ -98,9 +265,14 @@ func (o *MirrorOptions) CancelContext(parent context.Context) (context.Context,
 	go func() {
 		select {
 		case <-o.cancelCh:
+			klog.Warningf("Interrupt received, stopping the run at the next image, interrupt again to exit at once")
 			cancel()
 		case <-ctx.Done():
+			return
 		}
+		<-o.cancelCh
+		klog.Errorf("Interrupt received again, exiting")
+		os.Exit(ErrorClassInterrupted.ExitCode())
 	}()
 	return ctx, cancel
 }
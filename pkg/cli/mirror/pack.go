@@ -34,7 +34,7 @@ const (
 
 // Pack will pack the imageset and return a temporary backend storing metadata for final push
 // The metadata has been updated by the plan stage at this point but not pushed to the backend
-func (o *MirrorOptions) Pack(ctx context.Context, prevAssocs, currAssocs image.AssociationSet, meta *v1alpha2.Metadata, archiveSize int64) (storage.Backend, error) {
+func (o *MirrorOptions) Pack(ctx context.Context, prevAssocs *image.AssociationDB, currAssocs image.AssociationSet, meta *v1alpha2.Metadata, archiveSize int64) (storage.Backend, error) {
 	tmpdir, _, err := o.mktempDir()
 	if err != nil {
 		return nil, err
@@ -49,7 +49,7 @@ func (o *MirrorOptions) Pack(ctx context.Context, prevAssocs, currAssocs image.A
 	// Define a map that associates locations
 	// on disk to location in archive
 	paths := map[string]string{diskPath: config.V2Dir}
-	reconcileAssociation := image.AssociationSet{}
+	var reconcileAssociation image.AssociationIterator = image.AssociationSet{}
 	if !o.IgnoreHistory {
 		reconcileAssociation = prevAssocs
 	}
@@ -58,28 +58,38 @@ func (o *MirrorOptions) Pack(ctx context.Context, prevAssocs, currAssocs image.A
 		return tmpBackend, fmt.Errorf("error reconciling v2 files: %v", err)
 	}
 
-	// Stop the process if no new blobs
-	if len(blobs) == 0 {
+	// Stop the process if no new blobs or artifacts
+	if len(blobs) == 0 && o.pulledArtifacts == 0 {
 		return tmpBackend, ErrNoUpdatesExist
 	}
 
 	// Update Association in PastMirror to the current value and update
-	meta.PastMirror.Associations, err = image.ConvertFromAssociationSet(currAssocs)
-	if err != nil {
+	if err := prevAssocs.Merge(currAssocs); err != nil {
 		return tmpBackend, err
 	}
-	prevAssocs.Merge(currAssocs)
-	meta.PastAssociations, err = image.ConvertFromAssociationSet(prevAssocs)
-	if err != nil {
-		return tmpBackend, err
+	// The associations are streamed to the metadata from the databases.
+	assocs := map[string]image.AssociationIterator{
+		image.PastAssociationsField:     prevAssocs,
+		image.MirroredAssociationsField: currAssocs,
 	}
-	if err := metadata.UpdateMetadata(ctx, tmpBackend, meta, filepath.Join(o.Dir, config.SourceDir), o.SourceSkipTLS, o.SourcePlainHTTP); err != nil {
+	if err := metadata.UpdateMetadata(ctx, tmpBackend, meta, assocs, filepath.Join(o.Dir, config.SourceDir), o.SourceSkipTLS, o.SourcePlainHTTP); err != nil {
 		return tmpBackend, err
 	}
+	if format := o.compatFormat(); format != archive.CurrentFormat {
+		// Only the metadata packed in the archives is downgraded.
+		archiveMeta, err := downgradeMetadata(*meta, format)
+		if err != nil {
+			return tmpBackend, err
+		}
+		if err := storage.WriteMetadataStream(ctx, tmpBackend, &archiveMeta, config.MetadataBasePath, assocs); err != nil {
+			return tmpBackend, err
+		}
+	}
 
 	if err := o.prepareArchive(ctx, tmpBackend, archiveSize, meta.PastMirror.Sequence, manifests, blobs); err != nil {
 		return tmpBackend, err
 	}
+	o.recordSequence(*meta, prevAssocs, diskPath, blobs)
 
 	/* Commenting out temporarily because no concrete types implement this
 	if committer, isCommitter := backend.(storage.Committer); isCommitter {
@@ -119,6 +129,21 @@ func (o *MirrorOptions) prepareArchive(ctx context.Context, backend storage.Back
 	defer os.Chdir(cwd)
 
 	packager := archive.NewPackager(manifests, blobs)
+	if o.archiveCompression.IsSet() {
+		if o.CompatFormat != "" {
+			// Older oc-mirror versions only read plain tar archives.
+			klog.Warningf("archive compression is not supported with --compat-format, creating uncompressed archives")
+		} else {
+			arc, err := archive.NewCompressedArchiver(o.archiveCompression.Algorithm, o.archiveCompression.Level)
+			if err != nil {
+				return err
+			}
+			packager.Archiver = arc
+		}
+	}
+	packager.MaxFiles = o.MaxArchiveFiles
+	packager.ArchivesPerDir = o.ArchivesPerDir
+	packager.Format = o.compatFormat()
 	prefix := fmt.Sprintf("mirror_seq%d", seq)
 	if err := packager.CreateSplitArchive(ctx, backend, segSize, output, ".", prefix, o.SkipCleanup); err != nil {
 		return fmt.Errorf("failed to create archive: %v", err)
@@ -171,8 +171,9 @@ func TestPack(t *testing.T) {
 			require.NoError(t, testutils.LocalMirrorFromFiles(filepath.Join("testdata", config.V2Dir), path))
 			ctx := context.Background()
 
-			prevAssocs, err := image.ConvertToAssociationSet(c.meta.PastAssociations)
+			prevAssocs, err := image.ConvertToAssociationDB(filepath.Join(tmpdir, "prev.db"), c.meta.PastAssociations)
 			require.NoError(t, err)
+			defer prevAssocs.Close()
 			// First run will create mirror_seq1_0000.tar
 			_, err = c.opts.Pack(ctx, prevAssocs, c.assocs, &c.meta, 0)
 
@@ -0,0 +1,107 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+// pendingMetadataDir returns the directory of the workspace
+// where the metadata to push to metaImage is queued.
+func (o *MirrorOptions) pendingMetadataDir(metaImage string) string {
+	sum := sha256.Sum256([]byte(metaImage))
+	return filepath.Join(o.Dir, config.PendingMetadataDir, hex.EncodeToString(sum[:]))
+}
+
+// writeMetadata writes meta, with the Associations of each iterator of assocs streamed
+// at its path, to the metadata image metaImage through backend. When the backend is
+// unreachable, meta is queued in the workspace instead and pushed by reconcileMetadata
+// at the start of the next publish, so the sequence state of the published imageset
+// is not lost.
+func (o *MirrorOptions) writeMetadata(ctx context.Context, backend storage.Backend, metaImage string, meta *v1alpha2.Metadata, assocs map[string]image.AssociationIterator) error {
+	err := storage.CheckHealth(ctx, backend)
+	if err == nil {
+		err = storage.WriteMetadataStream(ctx, backend, meta, config.MetadataBasePath, assocs)
+	}
+	if err == nil {
+		// The metadata supersedes any metadata still queued.
+		return os.RemoveAll(o.pendingMetadataDir(metaImage))
+	}
+
+	klog.Warningf("unable to write metadata to %s, queuing it in the workspace until the next publish: %v", metaImage, err)
+	if qerr := o.queueMetadata(ctx, metaImage, meta, assocs); qerr != nil {
+		return fmt.Errorf("error writing metadata to %s: %v, error queuing it: %v", metaImage, err, qerr)
+	}
+	return nil
+}
+
+// queueMetadata stores meta in the workspace with a marker
+// recording that it is pending to be pushed to metaImage.
+func (o *MirrorOptions) queueMetadata(ctx context.Context, metaImage string, meta *v1alpha2.Metadata, assocs map[string]image.AssociationIterator) error {
+	dir := o.pendingMetadataDir(metaImage)
+	local, err := storage.NewLocalBackend(dir)
+	if err != nil {
+		return err
+	}
+	if err := storage.WriteMetadataStream(ctx, local, meta, config.MetadataBasePath, assocs); err != nil {
+		return err
+	}
+	// The marker is written last, so only complete metadata is pending.
+	return os.WriteFile(filepath.Join(dir, config.PendingMarkerFile), []byte(metaImage), 0600)
+}
+
+// reconcileMetadata pushes the metadata queued for metaImage by a previous publish
+// to backend, unless the backend already stores metadata of the same or a later
+// sequence. When the backend is still unreachable, the workspace backend of the
+// queued metadata is returned, to read it as the current metadata of the destination.
+func (o *MirrorOptions) reconcileMetadata(ctx context.Context, backend storage.Backend, metaImage string) (storage.Backend, error) {
+	dir := o.pendingMetadataDir(metaImage)
+	switch _, err := os.Stat(filepath.Join(dir, config.PendingMarkerFile)); {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	local, err := storage.NewLocalBackend(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Only the sequences are compared, the metadata is copied as is.
+	var pending v1alpha2.Metadata
+	if err := storage.ReadMetadataWithoutAssociations(ctx, local, &pending, config.MetadataBasePath); err != nil {
+		return nil, fmt.Errorf("error reading metadata queued for %s: %v", metaImage, err)
+	}
+
+	var curr v1alpha2.Metadata
+	err = storage.CheckHealth(ctx, backend)
+	if err == nil {
+		err = storage.ReadMetadataWithoutAssociations(ctx, backend, &curr, config.MetadataBasePath)
+	}
+	switch {
+	case err == nil && curr.PastMirror.Sequence >= pending.PastMirror.Sequence:
+		klog.Warningf("discarding metadata of sequence %d queued for %s, metadata of sequence %d is already stored",
+			pending.PastMirror.Sequence, metaImage, curr.PastMirror.Sequence)
+		return nil, os.RemoveAll(dir)
+	case err == nil || errors.Is(err, storage.ErrMetadataNotExist):
+		err = storage.CopyMetadata(ctx, local, backend, config.MetadataBasePath)
+	}
+	if err != nil {
+		klog.Warningf("unable to push metadata of sequence %d queued for %s, using it as the current metadata: %v",
+			pending.PastMirror.Sequence, metaImage, err)
+		return local, nil
+	}
+	klog.Infof("Pushed metadata of sequence %d queued for %s by a previous publish", pending.PastMirror.Sequence, metaImage)
+	return nil, os.RemoveAll(dir)
+}
@@ -0,0 +1,107 @@
+package mirror
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+)
+
+func TestPendingMetadata(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	newMeta := func(seq int) *v1alpha2.Metadata {
+		meta := v1alpha2.NewMetadata()
+		meta.Uid = uuid.MustParse("360a43c2-8a14-4b5d-906b-07491459f25f")
+		meta.PastMirror.Sequence = seq
+		return &meta
+	}
+	newBackend := func(t *testing.T, metaImage string) storage.Backend {
+		backend, err := storage.NewRegistryBackend(&v1alpha2.RegistryConfig{ImageURL: metaImage, SkipTLS: true}, t.TempDir())
+		require.NoError(t, err)
+		return backend
+	}
+	pendingMarker := func(o *MirrorOptions, metaImage string) string {
+		return filepath.Join(o.pendingMetadataDir(metaImage), config.PendingMarkerFile)
+	}
+
+	t.Run("Valid/Reconciled", func(t *testing.T) {
+		o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+		metaImage := u.Host + "/reconciled/oc-mirror:test"
+
+		// The registry is unreachable at the end of the publish.
+		unreachable := newBackend(t, "127.0.0.1:1/reconciled/oc-mirror:test")
+		require.NoError(t, o.writeMetadata(ctx, unreachable, metaImage, newMeta(1), nil))
+		require.FileExists(t, pendingMarker(o, metaImage))
+
+		// It is still unreachable at the start of the next publish.
+		pending, err := o.reconcileMetadata(ctx, unreachable, metaImage)
+		require.NoError(t, err)
+		require.NotNil(t, pending)
+		var queued v1alpha2.Metadata
+		require.NoError(t, pending.ReadMetadata(ctx, &queued, config.MetadataBasePath))
+		require.Equal(t, 1, queued.PastMirror.Sequence)
+		require.FileExists(t, pendingMarker(o, metaImage))
+
+		// The queued metadata is pushed once the registry is reachable.
+		backend := newBackend(t, metaImage)
+		pending, err = o.reconcileMetadata(ctx, backend, metaImage)
+		require.NoError(t, err)
+		require.Nil(t, pending)
+		require.NoFileExists(t, pendingMarker(o, metaImage))
+
+		var curr v1alpha2.Metadata
+		require.NoError(t, newBackend(t, metaImage).ReadMetadata(ctx, &curr, config.MetadataBasePath))
+		require.Equal(t, 1, curr.PastMirror.Sequence)
+	})
+
+	t.Run("Valid/Superseded", func(t *testing.T) {
+		o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+		metaImage := u.Host + "/superseded/oc-mirror:test"
+		backend := newBackend(t, metaImage)
+
+		require.NoError(t, o.queueMetadata(ctx, metaImage, newMeta(1), nil))
+		require.NoError(t, o.writeMetadata(ctx, backend, metaImage, newMeta(2), nil))
+		require.NoFileExists(t, pendingMarker(o, metaImage))
+	})
+
+	t.Run("Valid/Stale", func(t *testing.T) {
+		o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+		metaImage := u.Host + "/stale/oc-mirror:test"
+		backend := newBackend(t, metaImage)
+		require.NoError(t, backend.WriteMetadata(ctx, newMeta(2), config.MetadataBasePath))
+
+		// Metadata of a later sequence was stored by another publish.
+		require.NoError(t, o.queueMetadata(ctx, metaImage, newMeta(1), nil))
+		pending, err := o.reconcileMetadata(ctx, newBackend(t, metaImage), metaImage)
+		require.NoError(t, err)
+		require.Nil(t, pending)
+		require.NoFileExists(t, pendingMarker(o, metaImage))
+
+		var curr v1alpha2.Metadata
+		require.NoError(t, newBackend(t, metaImage).ReadMetadata(ctx, &curr, config.MetadataBasePath))
+		require.Equal(t, 2, curr.PastMirror.Sequence)
+	})
+
+	t.Run("Valid/NothingPending", func(t *testing.T) {
+		o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}}
+		metaImage := u.Host + "/none/oc-mirror:test"
+		pending, err := o.reconcileMetadata(ctx, newBackend(t, metaImage), metaImage)
+		require.NoError(t, err)
+		require.Nil(t, pending)
+	})
+}
@@ -0,0 +1,223 @@
+package mirror
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+const preflightTag = "oc-mirror-preflight"
+
+type preflightStatus string
+
+const (
+	preflightPass preflightStatus = "PASS"
+	preflightWarn preflightStatus = "WARN"
+	preflightFail preflightStatus = "FAIL"
+	preflightSkip preflightStatus = "SKIP"
+)
+
+// preflightResult is the outcome of a single readiness check.
+type preflightResult struct {
+	Check  string
+	Status preflightStatus
+	Detail string
+}
+
+// preflightReport is the consolidated outcome of the readiness checks
+// run against the target registry.
+type preflightReport struct {
+	Registry string
+	Results  []preflightResult
+}
+
+func (r *preflightReport) add(check string, status preflightStatus, format string, args ...interface{}) {
+	r.Results = append(r.Results, preflightResult{Check: check, Status: status, Detail: fmt.Sprintf(format, args...)})
+}
+
+// Failed returns true if any of the checks failed.
+func (r preflightReport) Failed() bool {
+	for _, res := range r.Results {
+		if res.Status == preflightFail {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the report as a table.
+func (r preflightReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Registry readiness report for %s:\n", r.Registry)
+	tw := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "  [%s]\t%s\t%s\n", res.Status, res.Check, res.Detail)
+	}
+	tw.Flush()
+	return sb.String()
+}
+
+/*
+preflight validates that the target registry is ready to receive the
+image set before anything is pushed. It checks connectivity, the TLS
+configuration and push permissions. With PreflightPushTest, it also
+checks whether the registry accepts the OCI media types used by rebuilt
+catalogs, by pushing a test image index.
+
+# Arguments
+
+• ctx: a cancellation context
+
+# Returns
+
+• preflightReport: the outcome of every check
+
+• error: non-nil if the target registry reference is invalid
+*/
+func (o *MirrorOptions) preflight(ctx context.Context) (preflightReport, error) {
+	insecure := o.DestPlainHTTP || o.DestSkipTLS
+	repo, err := name.NewRepository(path.Join(o.ToMirror, o.UserNamespace, "oc-mirror"), getNameOpts(insecure)...)
+	if err != nil {
+		return preflightReport{}, err
+	}
+	report := preflightReport{Registry: repo.RegistryStr()}
+	rt := createRT(insecure)
+
+	if !checkConnectivity(ctx, &report, repo.Registry, rt, insecure, o.clockSkewMonitor().now()) {
+		// The remaining checks require a reachable registry.
+		for _, check := range []string{"push permission", "media types"} {
+			report.add(check, preflightSkip, "registry is not reachable")
+		}
+		return report, nil
+	}
+
+	if err := remote.CheckPushPermission(repo.Tag(preflightTag), image.Keychain(), rt); err != nil {
+		report.add("push permission", preflightFail, "cannot push to %s: %v", repo, err)
+		report.add("media types", preflightSkip, "push permission is required")
+	} else {
+		report.add("push permission", preflightPass, "push allowed to %s", repo)
+		// The registry API has no read-only way to check the manifest
+		// media types, so the check pushes to the registry and is opt-in.
+		if o.PreflightPushTest {
+			checkMediaTypes(&report, repo, getRemoteOpts(ctx, insecure))
+		} else {
+			report.add("media types", preflightSkip, "use --preflight-push-test to push a test image index")
+		}
+	}
+
+	return report, nil
+}
+
+// checkConnectivity queries the registry API root and reports on reachability
+// and the TLS configuration. It returns false if the registry cannot be reached.
+func checkConnectivity(ctx context.Context, report *preflightReport, reg name.Registry, rt http.RoundTripper, insecure bool, local time.Time) bool {
+	schemes := []string{"https"}
+	if insecure || reg.Scheme() == "http" {
+		schemes = append(schemes, "http")
+	}
+
+	var errs []string
+	var tlsErr error
+	for _, scheme := range schemes {
+		url := fmt.Sprintf("%s://%s/v2/", scheme, reg.RegistryStr())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			if isCertificateError(err) {
+				tlsErr = err
+			}
+			errs = append(errs, err.Error())
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+			errs = append(errs, fmt.Sprintf("%s returned HTTP %d", url, resp.StatusCode))
+			continue
+		}
+
+		report.add("connectivity", preflightPass, "reached %s (HTTP %d)", url, resp.StatusCode)
+		switch {
+		case resp.TLS == nil:
+			report.add("tls", preflightWarn, "using plain HTTP")
+		case insecure:
+			report.add("tls", preflightWarn, "%s, certificate verification disabled", tls.VersionName(resp.TLS.Version))
+		default:
+			report.add("tls", preflightPass, "%s, certificate verified", tls.VersionName(resp.TLS.Version))
+		}
+		switch skew, ok := clockSkew(resp, local); {
+		case !ok:
+			report.add("clock skew", preflightSkip, "registry did not report its time")
+		case exceedsClockSkew(skew):
+			report.add("clock skew", preflightFail, "%s", clockSkewMessage(reg.RegistryStr(), skew))
+		default:
+			report.add("clock skew", preflightPass, "local clock within %s of the registry clock", maxClockSkew)
+		}
+		return true
+	}
+
+	report.add("connectivity", preflightFail, "unable to reach %s: %s", reg.RegistryStr(), strings.Join(errs, "; "))
+	if tlsErr != nil {
+		report.add("tls", preflightFail, "certificate not trusted, add the CA to the system trust store or use --dest-skip-tls: %v", tlsErr)
+	} else {
+		report.add("tls", preflightSkip, "registry is not reachable")
+	}
+	report.add("clock skew", preflightSkip, "registry is not reachable")
+	return false
+}
+
+// checkMediaTypes pushes an empty OCI image index, as used by rebuilt
+// catalogs, and removes it again.
+func checkMediaTypes(report *preflightReport, repo name.Repository, opts []remote.Option) {
+	img := mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON)
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex), mutate.IndexAddendum{
+		Add: img,
+		Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "linux", Architecture: "amd64"},
+		},
+	})
+	tag := repo.Tag(preflightTag)
+	if err := remote.WriteIndex(tag, idx, opts...); err != nil {
+		report.add("media types", preflightFail, "registry does not accept OCI image indexes: %v", err)
+		return
+	}
+	digest, err := idx.Digest()
+	if err == nil {
+		err = remote.Delete(repo.Digest(digest.String()), opts...)
+	}
+	if err != nil {
+		klog.V(1).Infof("unable to delete %s after preflight: %v", tag, err)
+		report.add("media types", preflightPass, "OCI image indexes accepted (%s was left in place)", tag)
+		return
+	}
+	report.add("media types", preflightPass, "OCI image indexes accepted")
+}
+
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var verification *tls.CertificateVerificationError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) ||
+		errors.As(err, &invalid) || errors.As(err, &verification)
+}
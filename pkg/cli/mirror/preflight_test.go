@@ -0,0 +1,124 @@
+package mirror
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight(t *testing.T) {
+	skewedRegistry := registry.New()
+
+	type spec struct {
+		name      string
+		handler   http.Handler
+		pushTest  bool
+		expStatus map[string]preflightStatus
+		expFailed bool
+	}
+
+	specs := []spec{
+		{
+			name:    "Valid/ReadyRegistry",
+			handler: registry.New(),
+			expStatus: map[string]preflightStatus{
+				"connectivity":    preflightPass,
+				"tls":             preflightWarn,
+				"clock skew":      preflightPass,
+				"push permission": preflightPass,
+				"media types":     preflightSkip,
+			},
+		},
+		{
+			name:     "Valid/PushTest",
+			handler:  registry.New(),
+			pushTest: true,
+			expStatus: map[string]preflightStatus{
+				"connectivity":    preflightPass,
+				"tls":             preflightWarn,
+				"clock skew":      preflightPass,
+				"push permission": preflightPass,
+				"media types":     preflightPass,
+			},
+		},
+		{
+			name: "Invalid/PushDenied",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/v2/" {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				w.WriteHeader(http.StatusForbidden)
+			}),
+			expStatus: map[string]preflightStatus{
+				"connectivity":    preflightPass,
+				"tls":             preflightWarn,
+				"clock skew":      preflightPass,
+				"push permission": preflightFail,
+				"media types":     preflightSkip,
+			},
+			expFailed: true,
+		},
+		{
+			name: "Invalid/ClockSkew",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+				skewedRegistry.ServeHTTP(w, r)
+			}),
+			expStatus: map[string]preflightStatus{
+				"connectivity":    preflightPass,
+				"tls":             preflightWarn,
+				"clock skew":      preflightFail,
+				"push permission": preflightPass,
+				"media types":     preflightSkip,
+			},
+			expFailed: true,
+		},
+		{
+			name: "Invalid/Unreachable",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}),
+			expStatus: map[string]preflightStatus{
+				"connectivity":    preflightFail,
+				"tls":             preflightSkip,
+				"clock skew":      preflightSkip,
+				"push permission": preflightSkip,
+				"media types":     preflightSkip,
+			},
+			expFailed: true,
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			server := httptest.NewServer(s.handler)
+			t.Cleanup(server.Close)
+			u, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			opts := &MirrorOptions{
+				ToMirror:          u.Host,
+				DestPlainHTTP:     true,
+				PreflightPushTest: s.pushTest,
+			}
+
+			report, err := opts.preflight(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, s.expFailed, report.Failed())
+
+			status := map[string]preflightStatus{}
+			for _, res := range report.Results {
+				status[res.Check] = res.Status
+			}
+			require.Equal(t, s.expStatus, status)
+			require.Contains(t, report.String(), "Registry readiness report for "+u.Host)
+		})
+	}
+}
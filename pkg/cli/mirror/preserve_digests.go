@@ -0,0 +1,98 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// isRebuilt returns true if the images of category are built by oc-mirror,
+// so their digests differ from the digests of their sources.
+func (o *MirrorOptions) isRebuilt(category v1alpha2.ImageType) bool {
+	return category == v1alpha2.TypeCincinnatiGraph || (category == v1alpha2.TypeOperatorCatalog && o.RebuildCatalogs)
+}
+
+// verifyPreservedDigests returns an error listing the images of mapping whose digest
+// in the destination registry differs from the digest of their source, the images
+// rebuilt by oc-mirror excepted. The digest of an image mirrored by tag is read from
+// the tag in the destination registry, so a manifest rewritten on push is detected.
+func (o *MirrorOptions) verifyPreservedDigests(ctx context.Context, mapping image.TypedImageMapping, srcInsecure, dstInsecure bool) error {
+	var rebuilt, mismatches []string
+	var verified int
+	for srcRef, dstRef := range mapping {
+		if dstRef.Type != imagesource.DestinationRegistry {
+			continue
+		}
+		if o.isRebuilt(srcRef.Category) {
+			rebuilt = append(rebuilt, dstRef.Ref.Exact())
+			continue
+		}
+
+		expected, err := o.sourceDigest(ctx, srcRef, dstRef, srcInsecure)
+		if err != nil {
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", dstRef.Ref.Exact(), err))
+			continue
+		}
+		ref := dstRef.Ref
+		if ref.Tag != "" {
+			ref.ID = ""
+		} else {
+			ref.ID = expected
+		}
+		dst, err := name.ParseReference(ref.Exact(), getNameOpts(dstInsecure)...)
+		if err != nil {
+			return err
+		}
+		desc, err := remote.Head(dst, getRemoteOpts(ctx, dstInsecure)...)
+		switch {
+		case err != nil:
+			mismatches = append(mismatches, fmt.Sprintf("%s: %v", dst, err))
+		case desc.Digest.String() != expected:
+			mismatches = append(mismatches, fmt.Sprintf("%s: digest %s, source digest %s", dst, desc.Digest, expected))
+		default:
+			verified++
+		}
+	}
+
+	if len(rebuilt) != 0 {
+		sort.Strings(rebuilt)
+		klog.Infof("Images rebuilt by oc-mirror, whose digests differ from their sources: %s", strings.Join(rebuilt, ", "))
+	}
+	if len(mismatches) != 0 {
+		sort.Strings(mismatches)
+		return fmt.Errorf("digests not preserved for %d images: %s", len(mismatches), strings.Join(mismatches, "; "))
+	}
+	klog.Infof("Verified the digests of %d mirrored images", verified)
+	return nil
+}
+
+// sourceDigest returns the digest of the source of a mapping,
+// read from the source registry when the mapping has none.
+func (o *MirrorOptions) sourceDigest(ctx context.Context, srcRef, dstRef image.TypedImage, insecure bool) (string, error) {
+	switch {
+	case srcRef.Ref.ID != "":
+		return srcRef.Ref.ID, nil
+	case dstRef.Ref.ID != "":
+		return dstRef.Ref.ID, nil
+	case srcRef.Type != imagesource.DestinationRegistry:
+		return "", fmt.Errorf("no source digest for %s", srcRef.Ref.Exact())
+	}
+	src, err := name.ParseReference(srcRef.Ref.Exact(), getNameOpts(insecure)...)
+	if err != nil {
+		return "", err
+	}
+	desc, err := remote.Head(src, getRemoteOpts(ctx, insecure)...)
+	if err != nil {
+		return "", fmt.Errorf("error reading source digest: %v", err)
+	}
+	return desc.Digest.String(), nil
+}
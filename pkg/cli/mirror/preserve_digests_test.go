@@ -0,0 +1,81 @@
+package mirror
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestVerifyPreservedDigests(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	push := func(ref string) string {
+		img, err := random.Image(64, 1)
+		require.NoError(t, err)
+		r, err := name.ParseReference(ref)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(r, img))
+		digest, err := img.Digest()
+		require.NoError(t, err)
+		return digest.String()
+	}
+	add := func(mapping image.TypedImageMapping, src, dst string, typ v1alpha2.ImageType) {
+		srcRef, err := image.ParseReference(src)
+		require.NoError(t, err)
+		dstRef, err := image.ParseReference(dst)
+		require.NoError(t, err)
+		mapping.Add(srcRef, dstRef, typ)
+	}
+
+	srcDigest := push(u.Host + "/src/app:v1")
+	// The image is mirrored unchanged, by digest and by tag.
+	srcRef, err := name.ParseReference(u.Host + "/src/app:v1")
+	require.NoError(t, err)
+	img, err := remote.Image(srcRef)
+	require.NoError(t, err)
+	dstRef, err := name.ParseReference(u.Host + "/dst/app:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(dstRef, img))
+	// The tag of another image points to a different manifest.
+	otherDigest := push(u.Host + "/src/other:v1")
+	rewritten := push(u.Host + "/dst/other:v1")
+
+	o := &MirrorOptions{RebuildCatalogs: true}
+
+	t.Run("Valid/Preserved", func(t *testing.T) {
+		mapping := image.TypedImageMapping{}
+		add(mapping, u.Host+"/src/app@"+srcDigest, u.Host+"/dst/app@"+srcDigest, v1alpha2.TypeGeneric)
+		add(mapping, u.Host+"/src/app:v1", u.Host+"/dst/app:v1", v1alpha2.TypeGeneric)
+		// The rebuilt catalog is not verified.
+		add(mapping, "registry.redhat.io/redhat/redhat-operator-index:v4.14", u.Host+"/dst/redhat-operator-index:v4.14", v1alpha2.TypeOperatorCatalog)
+		require.NoError(t, o.verifyPreservedDigests(context.TODO(), mapping, true, true))
+	})
+
+	t.Run("Invalid/Rewritten", func(t *testing.T) {
+		mapping := image.TypedImageMapping{}
+		add(mapping, u.Host+"/src/other@"+otherDigest, u.Host+"/dst/other:v1", v1alpha2.TypeGeneric)
+		err := o.verifyPreservedDigests(context.TODO(), mapping, true, true)
+		require.EqualError(t, err, "digests not preserved for 1 images: "+u.Host+"/dst/other:v1: digest "+rewritten+", source digest "+otherDigest)
+	})
+
+	t.Run("Invalid/CatalogCopied", func(t *testing.T) {
+		// Catalogs copied instead of rebuilt keep the digest of their source.
+		copied := &MirrorOptions{}
+		mapping := image.TypedImageMapping{}
+		add(mapping, u.Host+"/src/other@"+otherDigest, u.Host+"/dst/other:v1", v1alpha2.TypeOperatorCatalog)
+		require.ErrorContains(t, copied.verifyPreservedDigests(context.TODO(), mapping, true, true), "digests not preserved for 1 images")
+	})
+}
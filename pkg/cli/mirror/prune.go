@@ -26,7 +26,7 @@ import (
 )
 
 // pruneRegistry plans and executes registry pruning based on current and previous Associations.
-func (o *MirrorOptions) pruneRegistry(ctx context.Context, prev, curr image.AssociationSet) error {
+func (o *MirrorOptions) pruneRegistry(ctx context.Context, prev, curr image.AssociationIterator) error {
 	//CFE-739
 	if !o.SkipPruning {
 		deleter, toRemove, err := o.planImagePruning(ctx, curr, prev)
@@ -42,7 +42,7 @@ func (o *MirrorOptions) pruneRegistry(ctx context.Context, prev, curr image.Asso
 }
 
 // planImagePruning creates a ManifestDeleter and map of manifests scheduled for deletion.
-func (o *MirrorOptions) planImagePruning(ctx context.Context, curr, prev image.AssociationSet) (imageprune.ManifestDeleter, map[string][]string, error) {
+func (o *MirrorOptions) planImagePruning(ctx context.Context, curr, prev image.AssociationIterator) (imageprune.ManifestDeleter, map[string][]string, error) {
 	var insecure bool
 	if o.DestPlainHTTP || o.DestSkipTLS {
 		insecure = true
@@ -52,7 +52,7 @@ func (o *MirrorOptions) planImagePruning(ctx context.Context, curr, prev image.A
 
 	// We compare repo locations to allow the translation between
 	// mirror-to-mirror and disk-to-mirror association paths.
-	getRepoLoc := func(assocPath string) (string, error) {
+	getRepoLoc := func(imageName, assocPath string) (string, error) {
 		ref, err := reference.Parse(assocPath)
 		if err != nil {
 			return "", fmt.Errorf("invalid association set")
@@ -66,42 +66,49 @@ func (o *MirrorOptions) planImagePruning(ctx context.Context, curr, prev image.A
 			ref.Registry = ""
 			repoLoc = ref.AsRepository().String()
 		} else {
-			repoLoc = path.Join(o.UserNamespace, ref.AsRepository().String())
+			var mappedNs string
+			if source, err := reference.Parse(imageName); err == nil {
+				mappedNs = mappedNamespace(o.namespaceMappings, source)
+			}
+			repoLoc = path.Join(o.UserNamespace, mappedNs, ref.AsRepository().String())
 		}
 
 		return repoLoc, nil
 	}
 
-	keyforUniqueName := func(assoc v1alpha2.Association) (string, error) {
+	keyforUniqueName := func(imageName string, assoc v1alpha2.Association) (string, string, error) {
 		// Combine the source image or child manifest digest with the
 		// target location.
-		repoLoc, err := getRepoLoc(assoc.Path)
+		repoLoc, err := getRepoLoc(imageName, assoc.Path)
 		if err != nil {
-			return "", err
+			return "", "", err
 		}
-		return fmt.Sprintf("%v-%v", assoc.ID, repoLoc), nil
+		return fmt.Sprintf("%v-%v", assoc.ID, repoLoc), repoLoc, nil
 	}
 
 	// Gather all manifests that exists
 	// current set.
 	currSet := map[string]v1alpha2.Association{}
-	for _, assocs := range curr {
+	if err := curr.ForEach(func(imageName string, assocs image.Associations) error {
 		for _, assoc := range assocs {
-			unique, err := keyforUniqueName(assoc)
+			unique, _, err := keyforUniqueName(imageName, assoc)
 			if err != nil {
-				return deleter, manifestsByRepo, err
+				return err
 			}
 			currSet[unique] = assoc
 		}
-
+		return nil
+	}); err != nil {
+		return deleter, manifestsByRepo, err
 	}
 
 	outputSet := map[string]v1alpha2.Association{}
-	for _, assocs := range prev {
+	repoLocs := map[string]string{}
+	if err := prev.ForEach(func(imageName string, assocs image.Associations) error {
 		for _, assoc := range assocs {
-			unique, err := keyforUniqueName(assoc)
+			unique, repoLoc, err := keyforUniqueName(imageName, assoc)
 			if err != nil {
-				return deleter, manifestsByRepo, err
+				return err
 			}
 			if _, exists := currSet[unique]; exists {
 				// Do not add to the output set if the manifest
@@ -109,10 +116,14 @@ func (o *MirrorOptions) planImagePruning(ctx context.Context, curr, prev image.A
 				continue
 			}
 			outputSet[unique] = assoc
+			repoLocs[unique] = repoLoc
 		}
+		return nil
+	}); err != nil {
+		return deleter, manifestsByRepo, err
 	}
 
-	for _, assoc := range outputSet {
+	for unique, assoc := range outputSet {
 
 		// We are only processing keys where we have
 		// access to the manifest digest. Associated
@@ -121,11 +132,7 @@ func (o *MirrorOptions) planImagePruning(ctx context.Context, curr, prev image.A
 			continue
 		}
 
-		repoLoc, err := getRepoLoc(assoc.Path)
-		if err != nil {
-			return deleter, manifestsByRepo, err
-		}
-
+		repoLoc := repoLocs[unique]
 		manifests := manifestsByRepo[repoLoc]
 		manifests = append(manifests, assoc.ID)
 		sortManifests(manifests)
@@ -223,7 +230,7 @@ type repository struct {
 }
 
 // outputPruneImagePlan will write a plan for pruning images to disk.
-func (o *MirrorOptions) outputPruneImagePlan(ctx context.Context, prev, curr image.AssociationSet) error {
+func (o *MirrorOptions) outputPruneImagePlan(ctx context.Context, prev, curr image.AssociationIterator) error {
 	_, toRemove, err := o.planImagePruning(ctx, curr, prev)
 	if err != nil {
 		return err
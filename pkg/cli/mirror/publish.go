@@ -11,6 +11,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/opencontainers/go-digest"
 	"github.com/openshift/library-go/pkg/image/reference"
 	"github.com/openshift/library-go/pkg/image/registryclient"
@@ -27,6 +30,7 @@ import (
 	"github.com/openshift/oc-mirror/pkg/config"
 	"github.com/openshift/oc-mirror/pkg/image"
 	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+	"github.com/openshift/oc-mirror/pkg/network"
 )
 
 type ErrArchiveFileNotFound struct {
@@ -52,6 +56,17 @@ func (o *MirrorOptions) Publish(ctx context.Context) (image.TypedImageMapping, e
 		o.OutputDir = dir
 	}
 
+	if !o.SkipPreflight {
+		report, err := o.preflight(ctx)
+		if err != nil {
+			return allMappings, err
+		}
+		klog.Info(report.String())
+		if report.Failed() {
+			return allMappings, fmt.Errorf("registry %s is not ready, fix the failed checks or use --skip-preflight", report.Registry)
+		}
+	}
+
 	// Create workspace
 	cleanup, tmpdir, err := mktempDir(o.Dir)
 	if err != nil {
@@ -66,18 +81,38 @@ func (o *MirrorOptions) Publish(ctx context.Context) (image.TypedImageMapping, e
 	klog.V(2).Infof("Unarchiving metadata into %s", tmpdir)
 
 	// Get file information from the source archives
-	filesInArchive, err := bundle.ReadImageSet(archive.NewArchiver(), o.From)
+	filesInArchive, err := bundle.ReadImageSet(o.From)
 	if err != nil {
 		return allMappings, err
 	}
+	if err := checkImageSetFormat(tmpdir, filesInArchive); err != nil {
+		return allMappings, err
+	}
 
 	backend, incomingMeta, currentMeta, err := o.remoteRegFuncs.handleMetadata(ctx, tmpdir, filesInArchive)
 	if err != nil {
 		return allMappings, err
 	}
-	incomingAssocs, err := image.ConvertToAssociationSet(incomingMeta.PastAssociations)
-	if err != nil {
-		return allMappings, fmt.Errorf("error processing incoming past associations: %v", err)
+	defer func() {
+		incomingMeta.assocs.Close()
+		currentMeta.assocs.Close()
+	}()
+	o.summary.Sequence = incomingMeta.PastMirror.Sequence
+	o.namespaceMappings = incomingMeta.PastMirror.Mirror.NamespaceMappings
+	o.samples = incomingMeta.PastMirror.Samples
+	logInterruptedRun(currentMeta.Interrupted)
+	if len(currentMeta.OCIDigests) > 0 && !o.OCIMediaTypes {
+		// The images published before would be pruned, their digests differ from the incoming ones.
+		return allMappings, fmt.Errorf("images in %s were published with --oci-media-types, use --oci-media-types to publish to it", o.ToMirror)
+	}
+	incomingAssocs, mirroredAssocs := incomingMeta.assocs[image.PastAssociationsField], incomingMeta.assocs[image.MirroredAssociationsField]
+	currentAssocs := currentMeta.assocs[image.PastAssociationsField]
+	if currentAssocs == nil {
+		// Nothing was published to the destination yet.
+		if currentAssocs, err = image.OpenAssociationDB(o.associationsDBPath(currentAssociationsDB)); err != nil {
+			return allMappings, err
+		}
+		currentMeta.assocs = image.StreamedAssociations{image.PastAssociationsField: currentAssocs}
 	}
 
 	// Unpack chart to user destination if it exists
@@ -86,25 +121,67 @@ func (o *MirrorOptions) Publish(ctx context.Context) (image.TypedImageMapping, e
 		return allMappings, err
 	}
 
-	// Load image associations to find layers not present locally.
-	assocs, err := image.ConvertToAssociationSet(incomingMeta.PastMirror.Associations)
-	if err != nil {
-		return allMappings, err
-	}
-	if err := assocs.UpdatePath(); err != nil {
+	// Unpack operator CLI downloads to user destination if they exist
+	if err := o.unpackCLIDownloads(o.OutputDir, filesInArchive); err != nil {
 		return allMappings, err
 	}
 
 	klog.V(3).Infof("Process all images in imageset")
-	imgMappings, err := o.processMirroredImages(ctx, assocs, filesInArchive, currentMeta)
+	stats := &v1alpha2.MirrorStats{}
+	usage := newRepositoryUsage()
+	imgMappings, err := o.processMirroredImages(ctx, mirroredAssocs, currentAssocs, filesInArchive, currentMeta.Metadata, stats, usage)
 	if err != nil {
+		if ctx.Err() != nil {
+			n, _ := mirroredAssocs.Len()
+			o.recordInterruption(ctx, backend, incomingMeta.PastMirror.Sequence, n)
+			return allMappings, o.checkInterrupted(ctx)
+		}
 		return allMappings, fmt.Errorf("error occurred during image processing: %v", err)
 	}
 	allMappings.Merge(imgMappings)
+	logMirrorStats(stats)
+	logRepositoryUsage(usage)
+	o.summary.Storage = usage.Lines()
 
-	currentAssocs, err := image.ConvertToAssociationSet(currentMeta.PastAssociations)
+	artifactMappings, err := o.publishArtifacts(ctx, tmpdir, filesInArchive)
 	if err != nil {
-		return allMappings, fmt.Errorf("error processing incoming past associations: %v", err)
+		return allMappings, err
+	}
+	allMappings.Merge(artifactMappings)
+	incomingMeta.PastMirror.Stats = stats
+
+	// Annotations given when publishing complement the ones recorded
+	// when the imageset was created.
+	if len(o.annotations) > 0 {
+		if incomingMeta.PastMirror.Annotations == nil {
+			incomingMeta.PastMirror.Annotations = make(map[string]string, len(o.annotations))
+		}
+		for k, v := range o.annotations {
+			incomingMeta.PastMirror.Annotations[k] = v
+		}
+	}
+
+	if o.OCIMediaTypes {
+		// Record the digests of the converted images, so the images published by
+		// previous runs are not pruned and keep their digests in the next runs.
+		ociDigests := make(map[string]string, len(currentMeta.OCIDigests)+len(o.convertedDigests))
+		for digest, newDigest := range currentMeta.OCIDigests {
+			ociDigests[digest] = newDigest
+		}
+		for digest, newDigest := range o.convertedDigests {
+			ociDigests[digest] = newDigest
+		}
+		for _, assocs := range incomingMeta.assocs {
+			if err := remapAssociationDBDigests(assocs, ociDigests); err != nil {
+				return allMappings, fmt.Errorf("error processing incoming past associations: %v", err)
+			}
+		}
+		incomingMeta.OCIDigests = ociDigests
+		if len(o.convertedDigests) > 0 {
+			klog.Warningf("%d manifests were converted to OCI media types and have new digests: "+
+				"references to their original digests, like release payload components or the related images of operator bundles, "+
+				"cannot be resolved by the generated ImageContentSourcePolicies. See the mapping.txt file for the new digests", len(o.convertedDigests))
+		}
 	}
 
 	if o.DryRun {
@@ -129,9 +206,18 @@ func (o *MirrorOptions) Publish(ctx context.Context) (image.TypedImageMapping, e
 	}
 	allMappings.Merge(customMappings)
 
+	// The images skipped as already published are part of allMappings,
+	// so their digests at the destination are verified as well.
+	if o.PreserveDigests {
+		if err := o.verifyPreservedDigests(ctx, allMappings, false, o.DestPlainHTTP || o.DestSkipTLS); err != nil {
+			return allMappings, err
+		}
+	}
+
 	// Replace old metadata with new metadata if metadata is not single use
 	if !incomingMeta.SingleUse {
-		if err := backend.WriteMetadata(ctx, &incomingMeta, config.MetadataBasePath); err != nil {
+		metaImage := o.newMetadataImage(incomingMeta.Uid.String())
+		if err := o.writeMetadata(ctx, backend, metaImage, &incomingMeta.Metadata, incomingMeta.assocs.Iterators()); err != nil {
 			return allMappings, err
 		}
 	}
@@ -139,13 +225,27 @@ func (o *MirrorOptions) Publish(ctx context.Context) (image.TypedImageMapping, e
 	return allMappings, nil
 }
 
+// streamedMetadata is a metadata read with its Associations
+// streamed into databases, keyed by their path in the metadata.
+type streamedMetadata struct {
+	v1alpha2.Metadata
+	assocs image.StreamedAssociations
+}
+
 // handleMetadata unpacks and performs sequence checks on metadata coming from the imageset and metadata
-// exists in the registry.
-func (o *MirrorOptions) handleMetadata(ctx context.Context, tmpdir string, filesInArchive map[string]string) (backend storage.Backend, incoming, curr v1alpha2.Metadata, err error) {
+// exists in the registry. The Associations of the metadata are streamed into the incoming and current
+// association databases of the workspace, which the caller closes.
+func (o *MirrorOptions) handleMetadata(ctx context.Context, tmpdir string, filesInArchive map[string]string) (backend storage.Backend, incoming, curr streamedMetadata, err error) {
 	// Extract metadata from archive
 	if err := unpack(config.MetadataBasePath, tmpdir, filesInArchive); err != nil {
 		return backend, incoming, curr, err
 	}
+	defer func() {
+		if err != nil {
+			incoming.assocs.Close()
+			curr.assocs.Close()
+		}
+	}()
 
 	var insecure bool
 	if o.DestPlainHTTP || o.DestSkipTLS {
@@ -157,7 +257,11 @@ func (o *MirrorOptions) handleMetadata(ctx context.Context, tmpdir string, files
 		return backend, incoming, curr, fmt.Errorf("error opening local backend: %v", err)
 	}
 	// Load incoming metadta
-	if err := workspace.ReadMetadata(ctx, &incoming, config.MetadataBasePath); err != nil {
+	incoming.assocs, err = storage.ReadMetadataStream(ctx, workspace, &incoming.Metadata, config.MetadataBasePath, map[string]string{
+		image.PastAssociationsField:     o.associationsDBPath(incomingAssociationsDB),
+		image.MirroredAssociationsField: o.associationsDBPath(incomingMirroredAssociationsDB),
+	})
+	if err != nil {
 		return backend, incoming, curr, fmt.Errorf("error reading incoming metadata: %v", err)
 	}
 
@@ -183,18 +287,35 @@ func (o *MirrorOptions) handleMetadata(ctx context.Context, tmpdir string, files
 		return backend, incoming, curr, fmt.Errorf("error creating backend for metadata at %s: %v", metaImage, err)
 	}
 
+	// Push the metadata queued by a previous publish, if any
+	pending, err := o.reconcileMetadata(ctx, backend, metaImage)
+	if err != nil {
+		return backend, incoming, curr, err
+	}
+
 	// Read in current metadata, if present
-	berr := backend.ReadMetadata(ctx, &curr, config.MetadataBasePath)
+	currBackend := backend
+	if pending != nil {
+		currBackend = pending
+	}
+	curr.assocs, err = storage.ReadMetadataStream(ctx, currBackend, &curr.Metadata, config.MetadataBasePath, map[string]string{
+		image.PastAssociationsField:     o.associationsDBPath(currentAssociationsDB),
+		image.MirroredAssociationsField: "",
+	})
 	if !o.SkipPruning {
-		if err := o.checkSequence(incoming, curr, berr); err != nil {
+		if err := o.checkSequence(incoming.Metadata, curr.Metadata, err); err != nil {
 			return backend, incoming, curr, err
 		}
 	}
 	return backend, incoming, curr, nil
 }
 
-// processMirroredImages unpacks, reconstructs, and published all images in the provided imageset to the specified registry.
-func (o *MirrorOptions) processMirroredImages(ctx context.Context, assocs image.AssociationSet, filesInArchive map[string]string, currentMeta v1alpha2.Metadata) (image.TypedImageMapping, error) {
+// processMirroredImages unpacks, reconstructs, and published all images of assocs, the associations mirrored by the
+// provided imageset, to the specified registry. The associations are read from the database image by image, and the
+// layers missing from the imageset are fetched from the repositories of currentAssocs, the associations published
+// to the registry. Layer statistics are recorded in stats, and the statistics of the previous publish of currentMeta
+// are used to order the images.
+func (o *MirrorOptions) processMirroredImages(ctx context.Context, assocs, currentAssocs *image.AssociationDB, filesInArchive map[string]string, currentMeta v1alpha2.Metadata, stats *v1alpha2.MirrorStats, usage *repositoryUsage) (image.TypedImageMapping, error) {
 	allMappings := image.TypedImageMapping{}
 	var errs []error
 	toMirrorRef, err := imagesource.ParseReference(o.ToMirror)
@@ -206,11 +327,99 @@ func (o *MirrorOptions) processMirroredImages(ctx context.Context, assocs image.
 		return allMappings, fmt.Errorf("destination %q must be a registry reference", o.ToMirror)
 	}
 
-	for _, imageName := range assocs.Keys() {
+	// Blobs used by several images are stored once and linked to each of them.
+	store, err := newBlobStore(filepath.Join(o.Dir, config.BlobStoreDir))
+	if err != nil {
+		return allMappings, err
+	}
+	if !o.SkipCleanup {
+		defer store.Close()
+	}
+
+	// With SkipPublished, the images pushed by a previous publish are looked up
+	// at the destination with a single puller, reusing its connections and tokens.
+	var puller *remote.Puller
+	if o.SkipPublished {
+		insecure := o.DestPlainHTTP || o.DestSkipTLS
+		puller, err = remote.NewPuller(
+			remote.WithAuthFromKeychain(image.Keychain()),
+			remote.WithTransport(o.clockSkewMonitor().transport(createRT(insecure))),
+			remote.WithContext(ctx),
+		)
+		if err != nil {
+			return allMappings, err
+		}
+	}
+
+	if err := o.monitor.planAssociations(assocs); err != nil {
+		return allMappings, err
+	}
+	// Each blob is evicted from the store once the last image using it is published.
+	err = assocs.ForEach(func(_ string, values image.Associations) error {
+		imageAssocs := make([]v1alpha2.Association, 0, len(values))
+		for _, assoc := range values {
+			imageAssocs = append(imageAssocs, assoc)
+		}
+		store.retain(imageBlobPaths(imageAssocs))
+		return nil
+	})
+	if err != nil {
+		return allMappings, err
+	}
+	release := func(values []v1alpha2.Association) {
+		if o.SkipCleanup {
+			return
+		}
+		if err := store.release(imageBlobPaths(values)); err != nil {
+			klog.Warning(err)
+		}
+	}
+	keys, err := assocs.Keys()
+	if err != nil {
+		return allMappings, err
+	}
+	// The repositories of the published blobs are only
+	// read when a layer is missing from the imageset.
+	var pathsByLayer map[string]string
+	for _, imageName := range orderByMirrorHits(keys, currentMeta.PastMirror.Stats) {
+		o.monitor.waitIfPaused(ctx)
+		if err := o.checkInterrupted(ctx); err != nil {
+			return allMappings, err
+		}
+		o.monitor.start(imageName)
 
 		var mmapping []imgmirror.Mapping
 
-		values, _ := assocs.Search(imageName)
+		values, _, err := assocs.Search(imageName)
+		if err != nil {
+			return allMappings, err
+		}
+		// Update paths for local usage.
+		for i := range values {
+			values[i].Path = filepath.FromSlash(values[i].Path)
+		}
+		// The images of a source registry or namespace may be mirrored under a mapped namespace.
+		var mappedNs string
+		if source, err := reference.Parse(imageName); err == nil {
+			mappedNs = mappedNamespace(o.namespaceMappings, source)
+		}
+
+		// Images pushed by a previous attempt are not unpacked nor pushed again.
+		if puller != nil {
+			if published, ok := o.publishedImage(ctx, puller, imageName, values, toMirrorRef, mappedNs); ok {
+				klog.V(1).Infof("Image %s already published, skipping", imageName)
+				allMappings.Merge(published)
+				layers := 0
+				for _, assoc := range values {
+					layers += len(assoc.LayerDigests)
+				}
+				stats.RecordSkipped(imageName, layers)
+				o.metrics.addSkipped(1)
+				o.monitor.finish(imageName)
+				release(values)
+				continue
+			}
+		}
 
 		// Create temp workspace for image processing
 		cleanUnpackDir, unpackDir, err := mktempDir(o.Dir)
@@ -228,7 +437,11 @@ func (o *MirrorOptions) processMirroredImages(ctx context.Context, assocs image.
 			klog.V(3).Infof("reading assoc: %s", assoc.Name)
 			if len(assoc.ManifestDigests) != 0 {
 				for _, manifestDigest := range assoc.ManifestDigests {
-					if hasManifest := assocs.ContainsKey(imageName, manifestDigest); !hasManifest {
+					hasManifest, err := assocs.ContainsKey(imageName, manifestDigest)
+					if err != nil {
+						return allMappings, err
+					}
+					if !hasManifest {
 						errs = append(errs, fmt.Errorf("image %q: expected associations to have manifest %s but was not found", imageName, manifestDigest))
 						continue
 					}
@@ -252,6 +465,7 @@ func (o *MirrorOptions) processMirroredImages(ctx context.Context, assocs image.
 				continue
 			}
 
+			destRepo := path.Join(o.ToMirror, o.UserNamespace, mappedNs, assoc.Path)
 			for _, layerDigest := range assoc.LayerDigests {
 				klog.V(4).Infof("Found layer %v for image %s", layerDigest, imageName)
 				// Construct blob path, which is adjacent to the manifests path.
@@ -259,21 +473,26 @@ func (o *MirrorOptions) processMirroredImages(ctx context.Context, assocs image.
 				imagePath := filepath.Join(unpackDir, config.V2Dir, assoc.Path)
 				imageBlobPath := filepath.Join(imagePath, blobPath)
 				aerr := &ErrArchiveFileNotFound{}
-				switch err := unpack(blobPath, imagePath, filesInArchive); {
+				switch err := store.unpack(blobPath, imageBlobPath, filesInArchive); {
 				case err == nil:
 					klog.V(4).Infof("Blob %s found in %s", layerDigest, assoc.Path)
+					stats.Record(imageName, false)
+					if info, err := os.Stat(imageBlobPath); err == nil {
+						usage.Record(destRepo, layerDigest, info.Size())
+					}
 				case errors.Is(err, os.ErrNotExist) || errors.As(err, &aerr):
 					// Image layer must exist in the mirror registry since it wasn't archived,
 					// so fetch the layer and place it in the blob dir so it can be mirrored by `oc`.
 					missingLayers[layerDigest] = append(missingLayers[layerDigest], imageBlobPath)
+					stats.Record(imageName, true)
 				default:
 					errs = append(errs, fmt.Errorf("accessing image %q blob %q at %s: %v", imageName, layerDigest, blobPath, err))
 				}
 			}
 
-			m := imgmirror.Mapping{Name: assoc.Name}
-			if m.Source, err = imagesource.ParseReference("file://" + assoc.Path); err != nil {
-				errs = append(errs, fmt.Errorf("error parsing source ref %q: %v", assoc.Path, err))
+			m, err := o.publishMapping(imageName, assoc, toMirrorRef, mappedNs)
+			if err != nil {
+				errs = append(errs, err)
 				continue
 			}
 
@@ -282,16 +501,8 @@ func (o *MirrorOptions) processMirroredImages(ctx context.Context, assocs image.
 					errs = append(errs, fmt.Errorf("error unpacking symlink %v", err))
 					continue
 				}
-				m.Source.Ref.Tag = assoc.TagSymlink
 			}
 
-			m.Source.Ref.ID = assoc.ID
-			m.Destination = toMirrorRef
-			m.Destination.Ref.Name = m.Source.Ref.Name
-			m.Destination.Ref.Tag = m.Source.Ref.Tag
-			m.Destination.Ref.ID = m.Source.Ref.ID
-			m.Destination.Ref.Namespace = path.Join(o.UserNamespace, m.Source.Ref.Namespace)
-
 			// Add references for the mirror mapping
 			mmapping = append(mmapping, m)
 
@@ -312,16 +523,39 @@ func (o *MirrorOptions) processMirroredImages(ctx context.Context, assocs image.
 			if len(missingLayers) != 0 {
 				// Fetch all layers and mount them at the specified paths.
 				// Must use metadata for current published run to find images already mirrored.
-				if err := o.fetchBlobs(ctx, currentMeta, missingLayers); err != nil {
+				if pathsByLayer == nil {
+					if pathsByLayer, err = image.AssocPathsForBlobs(currentAssocs); err != nil {
+						return allMappings, err
+					}
+				}
+				if err := o.fetchBlobs(ctx, store, pathsByLayer, missingLayers); err != nil {
 					return allMappings, err
 				}
 			}
 		}
 
+		// Convert the manifests of this image before they are mirrored
+		if o.OCIMediaTypes && len(mmapping) != 0 {
+			if err := o.convertMappingsToOCI(mmapping, unpackDir, values); err != nil {
+				errs = append(errs, err)
+				mmapping = nil
+			}
+		}
+
 		// Mirror all mappings for this image
 		if len(mmapping) != 0 {
-			if err := o.publishImage(mmapping, unpackDir); err != nil {
+			var dsts []reference.DockerImageReference
+			for _, m := range mmapping {
+				dsts = append(dsts, m.Destination.Ref)
+			}
+			if err := o.ensureRepositories(ctx, dsts...); err != nil {
 				errs = append(errs, err)
+				o.monitor.addError(err)
+			} else if err := o.publishImage(mmapping, unpackDir); err != nil {
+				errs = append(errs, err)
+				o.monitor.addError(err)
+			} else if !o.DryRun {
+				o.monitor.finish(imageName)
 			}
 		}
 
@@ -329,10 +563,137 @@ func (o *MirrorOptions) processMirroredImages(ctx context.Context, assocs image.
 		if !o.SkipCleanup {
 			cleanUnpackDir()
 		}
+		release(values)
+	}
+
+	// The destinations of the converted images are pinned by their new digests.
+	for src, dst := range allMappings {
+		if newDigest, ok := o.convertedDigests[dst.Ref.ID]; ok {
+			dst.Ref.ID = newDigest
+			allMappings[src] = dst
+		}
 	}
 	return allMappings, utilerrors.NewAggregate(errs)
 }
 
+// publishMapping returns the mapping pushing the association assoc of
+// the image imageName from the imageset to the destination toMirrorRef,
+// under the namespace mappedNs mapped to the source of the image.
+func (o *MirrorOptions) publishMapping(imageName string, assoc v1alpha2.Association, toMirrorRef imagesource.TypedImageReference, mappedNs string) (imgmirror.Mapping, error) {
+	m := imgmirror.Mapping{Name: assoc.Name}
+	var err error
+	if m.Source, err = imagesource.ParseReference("file://" + assoc.Path); err != nil {
+		return m, fmt.Errorf("error parsing source ref %q: %v", assoc.Path, err)
+	}
+	m.Source.Ref.Tag = assoc.TagSymlink
+	m.Source.Ref.ID = assoc.ID
+	m.Destination = toMirrorRef
+	m.Destination.Ref.Name = m.Source.Ref.Name
+	m.Destination.Ref.Tag = m.Source.Ref.Tag
+	m.Destination.Ref.ID = m.Source.Ref.ID
+	m.Destination.Ref.Namespace = path.Join(o.UserNamespace, mappedNs, m.Source.Ref.Namespace)
+	if assoc.Name == imageName && m.Destination.Ref.Tag != "" && len(o.tagTemplates) != 0 {
+		source, err := image.ParseReference(imageName)
+		if err != nil {
+			return m, err
+		}
+		if source.Ref.ID == "" {
+			source.Ref.ID = assoc.ID
+		}
+		if m.Destination.Ref.Tag, err = o.destinationTag(assoc.Type, source.Ref, m.Destination.Ref.Tag); err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+// publishedImage returns the ICSP mapping of the image imageName, and true,
+// when a previous publish already pushed it: the manifest of each of its
+// associations exists at the destination by digest, and the tag of the
+// destination, if any, resolves to it. Publishing again after a partial
+// failure then only pushes the images missing from the destination.
+// The manifests found are recorded in the audit log as skipped.
+// Images converted to OCI media types get new digests and are always pushed.
+func (o *MirrorOptions) publishedImage(ctx context.Context, puller *remote.Puller, imageName string, assocs []v1alpha2.Association, toMirrorRef imagesource.TypedImageReference, mappedNs string) (image.TypedImageMapping, bool) {
+	if o.OCIMediaTypes || len(assocs) == 0 {
+		return nil, false
+	}
+	nameOpts := getNameOpts(o.DestPlainHTTP || o.DestSkipTLS)
+	head := func(ref reference.DockerImageReference) (*v1.Descriptor, error) {
+		r, err := name.ParseReference(ref.Exact(), nameOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return puller.Head(ctx, r)
+	}
+	published := image.TypedImageMapping{}
+	var skipped []network.ManifestChange
+	for _, assoc := range assocs {
+		m, err := o.publishMapping(imageName, assoc, toMirrorRef, mappedNs)
+		if err != nil {
+			return nil, false
+		}
+		byDigest := m.Destination.Ref
+		byDigest.Tag = ""
+		if _, err := head(byDigest); err != nil {
+			klog.V(4).Infof("Image %s not found at the destination: %v", byDigest.Exact(), err)
+			return nil, false
+		}
+		tagOrDigest := assoc.ID
+		if m.Destination.Ref.Tag != "" {
+			byTag := m.Destination.Ref
+			byTag.ID = ""
+			desc, err := head(byTag)
+			if err != nil || desc.Digest.String() != assoc.ID {
+				klog.V(4).Infof("Tag %s of the destination does not resolve to %s", byTag.Exact(), assoc.ID)
+				return nil, false
+			}
+			tagOrDigest = m.Destination.Ref.Tag
+		}
+		skipped = append(skipped, network.ManifestChange{
+			Action:       network.ManifestSkip,
+			Registry:     m.Destination.Ref.Registry,
+			Repository:   m.Destination.Ref.RepositoryName(),
+			Reference:    tagOrDigest,
+			DigestBefore: assoc.ID,
+			DigestAfter:  assoc.ID,
+		})
+		if assoc.Name == imageName {
+			source, err := image.ParseReference(imageName)
+			if err != nil {
+				return nil, false
+			}
+			published.Add(source, image.TypedImageReference{Ref: m.Destination.Ref, Type: m.Destination.Type}, assoc.Type)
+		}
+	}
+	for _, change := range skipped {
+		network.AuditManifest(change)
+	}
+	return published, true
+}
+
+// convertMappingsToOCI converts the manifests of the associations of an image unpacked
+// to unpackDir to OCI media types, and pins the mappings to the converted manifests.
+func (o *MirrorOptions) convertMappingsToOCI(mappings []imgmirror.Mapping, unpackDir string, assocs []v1alpha2.Association) error {
+	digests, err := convertManifestsToOCI(filepath.Join(unpackDir, config.V2Dir), assocs)
+	if err != nil {
+		return err
+	}
+	for i := range mappings {
+		if newDigest, ok := digests[mappings[i].Source.Ref.ID]; ok {
+			mappings[i].Source.Ref.ID = newDigest
+			mappings[i].Destination.Ref.ID = newDigest
+		}
+	}
+	if o.convertedDigests == nil {
+		o.convertedDigests = make(map[string]string, len(digests))
+	}
+	for digest, newDigest := range digests {
+		o.convertedDigests[digest] = newDigest
+	}
+	return nil
+}
+
 // processCustomImages builds custom images for operator catalogs or Cincinnati graph data if data is present in the archive
 func (o *MirrorOptions) processCustomImages(ctx context.Context, dir string, filesInArchive map[string]string) (image.TypedImageMapping, error) {
 	allMappings := image.TypedImageMapping{}
@@ -349,6 +710,11 @@ func (o *MirrorOptions) processCustomImages(ctx context.Context, dir string, fil
 			return allMappings, fmt.Errorf("error rebuilding catalog images from file-based catalogs: %v", err)
 		}
 		allMappings.Merge(ctlgRefs)
+		if o.ExportCatalogs {
+			if err := o.exportCatalogs(dir, o.OutputDir); err != nil {
+				return allMappings, fmt.Errorf("error exporting file-based catalogs: %v", err)
+			}
+		}
 	}
 
 	klog.V(2).Infof("building cincinnati graph data image")
@@ -372,8 +738,8 @@ func (o *MirrorOptions) processCustomImages(ctx context.Context, dir string, fil
 	return allMappings, nil
 }
 
-// TODO(estroz): symlink blobs instead of copying them to avoid data duplication.
-// `oc` mirror libs should be able to follow these symlinks.
+// copyBlobFile copies the blob read from src to dstPath, where
+// the blob store can neither hard link nor reflink it.
 func copyBlobFile(src io.Reader, dstPath string) error {
 	klog.V(4).Infof("copying blob to %s", dstPath)
 	if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
@@ -393,26 +759,22 @@ func copyBlobFile(src io.Reader, dstPath string) error {
 	return nil
 }
 
-func (o *MirrorOptions) fetchBlobs(ctx context.Context, meta v1alpha2.Metadata, missingLayers map[string][]string) error {
+// fetchBlobs fetches each layer of missingLayers from the destination repository
+// of pathsByLayer holding it into store, then places it at its paths.
+func (o *MirrorOptions) fetchBlobs(ctx context.Context, store *blobStore, pathsByLayer map[string]string, missingLayers map[string][]string) error {
 	regctx, err := image.NewContext(o.SkipVerification)
 	if err != nil {
 		return fmt.Errorf("error creating registry context: %v", err)
 	}
 
-	asSet, err := image.ConvertToAssociationSet(meta.PastAssociations)
-	if err != nil {
-		return err
-	}
-
 	var errs []error
-	pathsByLayer := image.AssocPathsForBlobs(asSet)
 	for layerDigest, dstBlobPaths := range missingLayers {
 		imgRef, err := o.findBlobRepo(pathsByLayer, layerDigest)
 		if err != nil {
 			errs = append(errs, fmt.Errorf("error finding remote layer %q: %v", layerDigest, err))
 			continue
 		}
-		if err := o.fetchBlob(ctx, regctx, imgRef.Ref, layerDigest, dstBlobPaths); err != nil {
+		if err := o.fetchBlob(ctx, regctx, store, imgRef.Ref, layerDigest, dstBlobPaths); err != nil {
 			errs = append(errs, fmt.Errorf("layer %s: %v", layerDigest, err))
 			continue
 		}
@@ -422,8 +784,8 @@ func (o *MirrorOptions) fetchBlobs(ctx context.Context, meta v1alpha2.Metadata,
 }
 
 // fetchBlob fetches a blob at <o.ToMirror>/<resource>/blobs/<layerDigest>
-// then copies it to each path in dstPaths.
-func (o *MirrorOptions) fetchBlob(ctx context.Context, regctx *registryclient.Context, ref reference.DockerImageReference, layerDigest string, dstPaths []string) error {
+// into store then places it at each path in dstPaths.
+func (o *MirrorOptions) fetchBlob(ctx context.Context, regctx *registryclient.Context, store *blobStore, ref reference.DockerImageReference, layerDigest string, dstPaths []string) error {
 	var insecure bool
 	if o.DestPlainHTTP || o.DestSkipTLS {
 		insecure = true
@@ -442,13 +804,8 @@ func (o *MirrorOptions) fetchBlob(ctx context.Context, regctx *registryclient.Co
 		return fmt.Errorf("open blob: %v", err)
 	}
 	defer rc.Close()
-	for _, dstPath := range dstPaths {
-		if err := copyBlobFile(rc, dstPath); err != nil {
-			return fmt.Errorf("copy blob for %s: %v", ref, err)
-		}
-		if _, err := rc.Seek(0, 0); err != nil {
-			return fmt.Errorf("seek to start of blob: %v", err)
-		}
+	if err := store.fetch(rc, filepath.Join("blobs", layerDigest), dstPaths); err != nil {
+		return fmt.Errorf("copy blob for %s: %v", ref, err)
 	}
 
 	return nil
@@ -469,7 +826,7 @@ func unpack(archiveFilePath, dest string, filesInArchive map[string]string) erro
 				return err
 			}
 		} else {
-			if err := archive.NewArchiver().Extract(archivePath, archiveFilePath, dest); err != nil {
+			if err := archive.ArchiverFor(archivePath).Extract(archivePath, archiveFilePath, dest); err != nil {
 				return err
 			}
 			if _, err := os.Stat(filepath.Join(dest, archiveFilePath)); err != nil {
@@ -499,7 +856,13 @@ func unarchive(archivePath, dest string) error {
 	if err != nil {
 		return fmt.Errorf("error opening tar %s: %v", archivePath, err)
 	}
-	reader := tar.NewReader(file)
+	defer file.Close()
+	stream, err := archive.Decompress(archivePath, file)
+	if err != nil {
+		return fmt.Errorf("error decompressing archive %s: %v", archivePath, err)
+	}
+	defer stream.Close()
+	reader := tar.NewReader(stream)
 	for {
 		header, err := reader.Next()
 
@@ -592,7 +955,10 @@ func (o *MirrorOptions) publishImage(mappings []imgmirror.Mapping, fromDir strin
 		return fmt.Errorf("invalid image mirror options: %v", err)
 	}
 	if err := genOpts.Run(); err != nil {
-		return fmt.Errorf("error running generic image mirror: %v", err)
+		return diagnoseQuotaError(fmt.Errorf("error running generic image mirror: %v", err))
+	}
+	if !o.DryRun {
+		o.countMirrored(len(mappings))
 	}
 
 	return nil
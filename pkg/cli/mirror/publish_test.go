@@ -9,7 +9,10 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/uuid"
 	"github.com/openshift/library-go/pkg/image/reference"
 	"github.com/openshift/oc/pkg/cli/image/imagesource"
@@ -21,6 +24,7 @@ import (
 	"github.com/openshift/oc-mirror/pkg/config"
 	"github.com/openshift/oc-mirror/pkg/image"
 	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+	"github.com/openshift/oc-mirror/pkg/network"
 )
 
 func TestHandleMetadata(t *testing.T) {
@@ -229,7 +233,8 @@ func TestFindBlobRepo(t *testing.T) {
 				},
 			}
 
-			aByL := image.AssocPathsForBlobs(assocs)
+			aByL, err := image.AssocPathsForBlobs(assocs)
+			require.NoError(t, err)
 
 			ref, err := test.options.findBlobRepo(aByL, test.digest)
 			if len(test.err) != 0 {
@@ -267,3 +272,103 @@ func prepMetadata(ctx context.Context, host, dir, uuid string) error {
 
 	return reg.WriteMetadata(ctx, &meta, dir)
 }
+
+func TestPublishedImage(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	img, err := random.Image(16, 1)
+	require.NoError(t, err)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	other, err := random.Image(16, 1)
+	require.NoError(t, err)
+
+	// A previous publish pushed the image under the user namespace.
+	pushed, err := name.ParseReference(u.Host + "/mirror/ubi8/ubi:latest")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(pushed, img))
+	moved, err := name.ParseReference(u.Host + "/mirror/ubi8/ubi-minimal:latest")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(moved, img))
+	// The tag was moved to another image after the publish.
+	require.NoError(t, remote.Write(moved, other))
+
+	toMirrorRef, err := imagesource.ParseReference(u.Host)
+	require.NoError(t, err)
+	assoc := func(imageName, path, tag string) v1alpha2.Association {
+		return v1alpha2.Association{
+			Name:       imageName,
+			Path:       path,
+			ID:         digest.String(),
+			TagSymlink: tag,
+			Type:       v1alpha2.TypeGeneric,
+		}
+	}
+
+	type spec struct {
+		name         string
+		opts         *MirrorOptions
+		imageName    string
+		assoc        v1alpha2.Association
+		expPublished bool
+	}
+	cases := []spec{
+		{
+			name:         "Valid/Published",
+			opts:         &MirrorOptions{UserNamespace: "mirror", DestPlainHTTP: true},
+			imageName:    "registry.access.redhat.com/ubi8/ubi:latest",
+			assoc:        assoc("registry.access.redhat.com/ubi8/ubi:latest", "ubi8/ubi", "latest"),
+			expPublished: true,
+		},
+		{
+			name:      "Valid/NotPublished",
+			opts:      &MirrorOptions{UserNamespace: "other", DestPlainHTTP: true},
+			imageName: "registry.access.redhat.com/ubi8/ubi:latest",
+			assoc:     assoc("registry.access.redhat.com/ubi8/ubi:latest", "ubi8/ubi", "latest"),
+		},
+		{
+			name:      "Valid/TagMoved",
+			opts:      &MirrorOptions{UserNamespace: "mirror", DestPlainHTTP: true},
+			imageName: "registry.access.redhat.com/ubi8/ubi-minimal:latest",
+			assoc:     assoc("registry.access.redhat.com/ubi8/ubi-minimal:latest", "ubi8/ubi-minimal", "latest"),
+		},
+		{
+			name:      "Valid/OCIMediaTypes",
+			opts:      &MirrorOptions{UserNamespace: "mirror", DestPlainHTTP: true, OCIMediaTypes: true},
+			imageName: "registry.access.redhat.com/ubi8/ubi:latest",
+			assoc:     assoc("registry.access.redhat.com/ubi8/ubi:latest", "ubi8/ubi", "latest"),
+		},
+	}
+	puller, err := remote.NewPuller()
+	require.NoError(t, err)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var skipped []network.ManifestChange
+			network.SetManifestAuditor(func(change network.ManifestChange) { skipped = append(skipped, change) })
+			t.Cleanup(func() { network.SetManifestAuditor(nil) })
+
+			mapping, ok := c.opts.publishedImage(context.Background(), puller, c.imageName, []v1alpha2.Association{c.assoc}, toMirrorRef, "")
+			require.Equal(t, c.expPublished, ok)
+			if !c.expPublished {
+				require.Empty(t, skipped)
+				return
+			}
+			require.Len(t, mapping, 1)
+			for _, dst := range mapping {
+				require.Equal(t, u.Host+"/mirror/ubi8/ubi@"+digest.String(), dst.Ref.Exact())
+				require.Equal(t, "latest", dst.Ref.Tag)
+			}
+			require.Equal(t, []network.ManifestChange{{
+				Action:       network.ManifestSkip,
+				Registry:     u.Host,
+				Repository:   "mirror/ubi8/ubi",
+				Reference:    "latest",
+				DigestBefore: digest.String(),
+				DigestAfter:  digest.String(),
+			}}, skipped)
+		})
+	}
+}
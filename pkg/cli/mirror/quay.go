@@ -0,0 +1,178 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+// quayAPITokenEnv is the environment variable holding the OAuth
+// token of the Quay API used by --quay-create-repositories.
+const quayAPITokenEnv = "QUAY_API_TOKEN"
+
+// Messages of the registries rejecting a push over the storage quota
+// of a namespace. Quay answers "denied: Quota has been exceeded on namespace".
+var quotaExceededMessages = []string{"quota has been exceeded", "exceeds quota", "quota exceeded for"}
+
+// isQuotaExceededError returns true if err is a registry rejecting
+// a push because the storage quota of a namespace is exceeded.
+func isQuotaExceededError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, m := range quotaExceededMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// diagnoseQuotaError classifies err as a quota failure and explains
+// how to recover from it if err is a registry rejecting a push
+// because the storage quota of a namespace is exceeded.
+func diagnoseQuotaError(err error) error {
+	if err == nil || !isQuotaExceededError(err) {
+		return err
+	}
+	return NewClassifiedError(ErrorClassQuota, fmt.Errorf("%w: the storage quota of a namespace of the destination registry is exceeded, "+
+		"raise the quota of the organization or delete images from it and publish again", err))
+}
+
+// quayClient creates the organizations and repositories of a Quay registry
+// with the Quay API. Quay only creates repositories on push in existing
+// organizations, for the users allowed to create repositories in them.
+type quayClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+	// ensured holds the organizations and repositories known to exist.
+	ensured sets.Set[string]
+}
+
+func newQuayClient(registry, token string, plainHTTP, skipTLS bool) *quayClient {
+	scheme := "https"
+	if plainHTTP {
+		scheme = "http"
+	}
+	return &quayClient{
+		baseURL: scheme + "://" + registry,
+		token:   token,
+		client:  &http.Client{Transport: network.NewTransport(skipTLS)},
+		ensured: sets.New[string](),
+	}
+}
+
+// ensureRepository creates the repository repo, such as org/ns/name,
+// and its organization, the first component of repo, if they do not exist.
+func (c *quayClient) ensureRepository(ctx context.Context, repo string) error {
+	if c.ensured.Has(repo) {
+		return nil
+	}
+	org, name, found := strings.Cut(repo, "/")
+	if !found {
+		return fmt.Errorf("repository %s is not in an organization", repo)
+	}
+	if !c.ensured.Has(org) {
+		if err := c.create(ctx, "/api/v1/organization/", map[string]string{"name": org}); err != nil {
+			return fmt.Errorf("error creating organization %s: %w", org, err)
+		}
+		c.ensured.Insert(org)
+	}
+	body := map[string]string{
+		"namespace":   org,
+		"repository":  name,
+		"visibility":  "private",
+		"description": "",
+		"repo_kind":   "image",
+	}
+	if err := c.create(ctx, "/api/v1/repository", body); err != nil {
+		return fmt.Errorf("error creating repository %s: %w", repo, err)
+	}
+	c.ensured.Insert(repo)
+	return nil
+}
+
+// create posts body to the endpoint of the Quay API creating a resource.
+// Resources that already exist are not an error.
+func (c *quayClient) create(ctx context.Context, endpoint string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	respData, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var apiErr struct {
+		ErrorMessage string `json:"error_message"`
+		Detail       string `json:"detail"`
+	}
+	msg := strings.TrimSpace(string(respData))
+	if json.Unmarshal(respData, &apiErr) == nil {
+		switch {
+		case apiErr.ErrorMessage != "":
+			msg = apiErr.ErrorMessage
+		case apiErr.Detail != "":
+			msg = apiErr.Detail
+		}
+	}
+	if resp.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(msg), "already exists") {
+		return nil
+	}
+	err = fmt.Errorf("quay API %s returned %s: %s", endpoint, resp.Status, msg)
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return NewClassifiedError(ErrorClassAuth, err)
+	}
+	return err
+}
+
+// ensureQuayRepositories creates the repositories of refs, and their
+// organizations, in the destination registries with the Quay API
+// before they are pushed to, if --quay-create-repositories is set.
+func (o *MirrorOptions) ensureQuayRepositories(ctx context.Context, refs ...reference.DockerImageReference) error {
+	if !o.QuayCreateRepositories || o.DryRun {
+		return nil
+	}
+	if o.quayClients == nil {
+		o.quayClients = map[string]*quayClient{}
+	}
+	var errs []error
+	for _, ref := range refs {
+		client, ok := o.quayClients[ref.Registry]
+		if !ok {
+			client = newQuayClient(ref.Registry, os.Getenv(quayAPITokenEnv), o.DestPlainHTTP, o.DestSkipTLS)
+			o.quayClients[ref.Registry] = client
+		}
+		repo := ref.RepositoryName()
+		if !client.ensured.Has(repo) {
+			klog.V(1).Infof("Ensuring repository %s/%s exists", ref.Registry, repo)
+		}
+		if err := client.ensureRepository(ctx, repo); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
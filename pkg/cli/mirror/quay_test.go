@@ -0,0 +1,115 @@
+package mirror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQuay serves the endpoints of the Quay API creating organizations and repositories.
+type fakeQuay struct {
+	mu       sync.Mutex
+	orgs     map[string]bool
+	repos    map[string]bool
+	requests int
+}
+
+func (q *fakeQuay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.requests++
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var body map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/organization/":
+		if q.orgs[body["name"]] {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error_message": "A user or organization with this name already exists"}`))
+			return
+		}
+		q.orgs[body["name"]] = true
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/repository":
+		if !q.orgs[body["namespace"]] {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"detail": "Unauthorized"}`))
+			return
+		}
+		repo := body["namespace"] + "/" + body["repository"]
+		if q.repos[repo] {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error_message": "Repository already exists"}`))
+			return
+		}
+		q.repos[repo] = true
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func TestEnsureQuayRepositories(t *testing.T) {
+	quay := &fakeQuay{orgs: map[string]bool{"existing": true}, repos: map[string]bool{"existing/app": true}}
+	server := httptest.NewServer(quay)
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	t.Setenv(quayAPITokenEnv, "token")
+
+	o := &MirrorOptions{QuayCreateRepositories: true, DestPlainHTTP: true}
+	refs := []reference.DockerImageReference{
+		{Registry: u.Host, Namespace: "mirror/openshift", Name: "release"},
+		{Registry: u.Host, Namespace: "mirror/openshift", Name: "release", Tag: "4.14"},
+		{Registry: u.Host, Namespace: "existing", Name: "app"},
+	}
+	require.NoError(t, o.ensureQuayRepositories(context.Background(), refs...))
+	require.Equal(t, map[string]bool{"existing": true, "mirror": true}, quay.orgs)
+	require.Equal(t, map[string]bool{"existing/app": true, "mirror/openshift/release": true}, quay.repos)
+
+	// Repositories already ensured are not created again.
+	requests := quay.requests
+	require.NoError(t, o.ensureQuayRepositories(context.Background(), refs...))
+	require.Equal(t, requests, quay.requests)
+
+	t.Run("Invalid/Unauthorized", func(t *testing.T) {
+		t.Setenv(quayAPITokenEnv, "bad")
+		o := &MirrorOptions{QuayCreateRepositories: true, DestPlainHTTP: true}
+		err := o.ensureQuayRepositories(context.Background(), reference.DockerImageReference{Registry: u.Host, Namespace: "other", Name: "app"})
+		require.ErrorContains(t, err, "error creating organization other: quay API /api/v1/organization/ returned 401 Unauthorized")
+		require.Equal(t, ErrorClassAuth, ClassifyError(err))
+	})
+
+	t.Run("Valid/Disabled", func(t *testing.T) {
+		o := &MirrorOptions{}
+		require.NoError(t, o.ensureQuayRepositories(context.Background(), reference.DockerImageReference{Registry: "127.0.0.1:1", Namespace: "other", Name: "app"}))
+	})
+}
+
+func TestDiagnoseQuotaError(t *testing.T) {
+	err := diagnoseQuotaError(errors.New("error running generic image mirror: denied: Quota has been exceeded on namespace mirror"))
+	require.ErrorContains(t, err, "the storage quota of a namespace of the destination registry is exceeded")
+	require.Equal(t, ErrorClassQuota, ClassifyError(err))
+
+	err = errors.New("error running generic image mirror: manifest unknown")
+	require.Equal(t, err, diagnoseQuotaError(err))
+
+	// Full disks are not registry quotas.
+	err = errors.New("write /tmp/blob: disk quota exceeded")
+	require.Equal(t, err, diagnoseQuotaError(err))
+	require.Equal(t, ErrorClassDiskFull, ClassifyError(err))
+}
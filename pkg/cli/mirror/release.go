@@ -1,6 +1,7 @@
 package mirror
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	_ "embed"
@@ -25,6 +26,7 @@ import (
 	"github.com/openshift/oc-mirror/pkg/cincinnati"
 	"github.com/openshift/oc-mirror/pkg/config"
 	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/network"
 )
 
 const (
@@ -76,23 +78,27 @@ func (o *ReleaseOptions) Plan(ctx context.Context, lastRun v1alpha2.PastMirror,
 		prevChannels[ch.ReleaseChannel] = ch.MinVersion
 	}
 
-	for _, arch := range cfg.Mirror.Platform.Architectures {
+	// Release payloads pinned by digest bypass Cincinnati entirely.
+	architectures := cfg.Mirror.Platform.Architectures
+	if o.ReleaseDigests != "" {
+		pinned, err := readReleaseDigests(o.ReleaseDigests)
+		if err != nil {
+			return mmapping, err
+		}
+		for _, img := range pinned {
+			klog.V(1).Infof("Found pinned release %s", img)
+			releaseDownloads[img] = struct{}{}
+		}
+		architectures = nil
+	}
+
+	for _, arch := range architectures {
 
 		versionsByChannel := make(map[string]v1alpha2.ReleaseChannel, len(cfg.Mirror.Platform.Channels))
 
 		for _, ch := range cfg.Mirror.Platform.Channels {
 
-			var client cincinnati.Client
-			var err error
-			switch ch.Type {
-			case v1alpha2.TypeOCP:
-				client, err = cincinnati.NewOCPClient(o.uuid)
-			case v1alpha2.TypeOKD:
-				client, err = cincinnati.NewOKDClient(o.uuid)
-			default:
-				errs = append(errs, fmt.Errorf("invalid platform type %v", ch.Type))
-				continue
-			}
+			client, err := cincinnati.NewClient(o.uuid, ch)
 			if err != nil {
 				errs = append(errs, err)
 				continue
@@ -113,11 +119,26 @@ func (o *ReleaseOptions) Plan(ctx context.Context, lastRun v1alpha2.PastMirror,
 				}
 			}
 
+			// Channels restricted to the releases promoted to another channel.
+			var promoted map[string]semver.Version
+			if ch.PromotedIn != "" {
+				promoted, err = promotedReleases(ctx, client, arch, ch.PromotedIn)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+			}
+
 			if len(ch.MaxVersion) == 0 || len(ch.MinVersion) == 0 {
 
 				// Find channel maximum value and only set the minimum as well if heads-only is true
 				if len(ch.MaxVersion) == 0 {
-					latest, err := cincinnati.GetChannelMinOrMax(ctx, client, arch, ch.Name, false)
+					var latest semver.Version
+					if promoted != nil {
+						latest, err = latestPromotedVersion(ctx, client, arch, ch, promoted)
+					} else {
+						latest, err = cincinnati.GetChannelMinOrMax(ctx, client, arch, ch.Name, false)
+					}
 					if err != nil {
 						errs = append(errs, err)
 						continue
@@ -162,6 +183,9 @@ func (o *ReleaseOptions) Plan(ctx context.Context, lastRun v1alpha2.PastMirror,
 				errs = append(errs, err)
 				continue
 			}
+			if promoted != nil {
+				filterPromoted(ch, downloads, promoted)
+			}
 			releaseDownloads.Merge(downloads)
 		}
 
@@ -175,7 +199,8 @@ func (o *ReleaseOptions) Plan(ctx context.Context, lastRun v1alpha2.PastMirror,
 		}
 
 		if len(cfg.Mirror.Platform.Channels) > 1 {
-			client, err := cincinnati.NewOCPClient(o.uuid)
+			// The channels mirrored together share their graph endpoint.
+			client, err := cincinnati.NewClient(o.uuid, v1alpha2.ReleaseChannel{Type: v1alpha2.TypeOCP, GraphURL: cfg.Mirror.Platform.Channels[0].GraphURL})
 			if err != nil {
 				errs = append(errs, err)
 				continue
@@ -499,7 +524,7 @@ func createSignatureFileName(digest string) (string, error) {
 }
 
 func (o *ReleaseOptions) HTTPClient() (*http.Client, error) {
-	return &http.Client{}, nil
+	return &http.Client{Transport: network.NewTransport(false)}, nil
 }
 
 // unpackReleaseSignatures will unpack the release signatures if they exist
@@ -515,3 +540,48 @@ func (o *MirrorOptions) unpackReleaseSignatures(dstDir string, filesInArchive ma
 	klog.Infof("Wrote release signatures to %s", dstDir)
 	return nil
 }
+
+// hasReleases returns true if the run mirrors release payloads, from the
+// channels of cfg or pinned by digest with --release-digests.
+func (o *MirrorOptions) hasReleases(cfg v1alpha2.ImageSetConfiguration) bool {
+	return len(cfg.Mirror.Platform.Channels) != 0 || o.ReleaseDigests != ""
+}
+
+// readReleaseDigests reads the release payloads listed in the file at path,
+// one pull spec by digest per line. Blank lines and lines starting with #
+// are ignored.
+func readReleaseDigests(path string) ([]string, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading release digests: %v", err)
+	}
+	defer file.Close()
+
+	var releases []string
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		spec := strings.TrimSpace(scanner.Text())
+		if spec == "" || strings.HasPrefix(spec, "#") {
+			continue
+		}
+		ref, err := image.ParseReference(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid release %q: %v", path, line, spec, err)
+		}
+		if ref.Ref.ID == "" {
+			return nil, fmt.Errorf("%s:%d: release %q must be pinned by digest", path, line, spec)
+		}
+		if !seen[spec] {
+			seen[spec] = true
+			releases = append(releases, spec)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading release digests: %v", err)
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("%s: no release digests found", path)
+	}
+	return releases, nil
+}
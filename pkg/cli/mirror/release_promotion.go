@@ -0,0 +1,69 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cincinnati"
+)
+
+// promotedReleases returns the release images of channel for arch,
+// with their versions, for the channels restricted by promotedIn.
+func promotedReleases(ctx context.Context, c cincinnati.Client, arch, channel string) (map[string]semver.Version, error) {
+	all := func(semver.Version) bool { return true }
+	updates, err := cincinnati.GetUpdatesInRange(ctx, c, channel, arch, all)
+	if err != nil {
+		return nil, err
+	}
+	promoted := make(map[string]semver.Version, len(updates))
+	for _, u := range updates {
+		promoted[u.Image] = u.Version
+	}
+	return promoted, nil
+}
+
+// latestPromotedVersion returns the latest version of the release
+// channel ch for arch that is also in the promoted releases.
+func latestPromotedVersion(ctx context.Context, c cincinnati.Client, arch string, ch v1alpha2.ReleaseChannel, promoted map[string]semver.Version) (semver.Version, error) {
+	versions, err := cincinnati.GetVersions(ctx, c, arch, ch.Name)
+	if err != nil {
+		return semver.Version{}, err
+	}
+	inPromoted := make(map[string]bool, len(promoted))
+	for _, v := range promoted {
+		inPromoted[v.String()] = true
+	}
+	// Versions are sorted in ascending order.
+	for i := len(versions) - 1; i >= 0; i-- {
+		if inPromoted[versions[i].String()] {
+			return versions[i], nil
+		}
+	}
+	return semver.Version{}, fmt.Errorf("release channel %q: no release for %s is in channel %q", ch.Name, arch, ch.PromotedIn)
+}
+
+// filterPromoted removes from releaseDownloads of the release channel ch the
+// releases that are not in the promoted releases of its promotedIn channel.
+func filterPromoted(ch v1alpha2.ReleaseChannel, releaseDownloads downloads, promoted map[string]semver.Version) {
+	excluded := 0
+	for img := range releaseDownloads {
+		if _, ok := promoted[img]; ok {
+			continue
+		}
+		klog.V(1).Infof("Excluding release %s of channel %s: not in channel %s", img, ch.Name, ch.PromotedIn)
+		delete(releaseDownloads, img)
+		excluded++
+	}
+	if excluded == 0 {
+		return
+	}
+	if ch.ShortestPath {
+		klog.Warningf("Excluded %d releases of the upgrade path of channel %s that are not in channel %s, the path may not be complete", excluded, ch.Name, ch.PromotedIn)
+		return
+	}
+	klog.Infof("Excluded %d releases of channel %s that are not in channel %s", excluded, ch.Name, ch.PromotedIn)
+}
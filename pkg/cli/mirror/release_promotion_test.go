@@ -0,0 +1,54 @@
+package mirror
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestPromotedReleases(t *testing.T) {
+	requestQuery := make(chan string, 10)
+	ts := httptest.NewServer(getHandlerMulti(t, requestQuery))
+	t.Cleanup(ts.Close)
+	endpoint, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	c := &mockClient{url: endpoint}
+	ctx := context.Background()
+
+	promoted, err := promotedReleases(ctx, c, "test-arch", "stable-4.1")
+	require.NoError(t, err)
+	require.Len(t, promoted, 7)
+	require.Equal(t, semver.MustParse("4.1.0-6"), promoted["quay.io/openshift-release-dev/ocp-release:4.1.0-6"])
+	<-requestQuery
+
+	t.Run("Valid/LatestPromotedVersion", func(t *testing.T) {
+		ch := v1alpha2.ReleaseChannel{Name: "fast-4.1", PromotedIn: "stable-4.1"}
+		latest, err := latestPromotedVersion(ctx, c, "test-arch", ch, promoted)
+		<-requestQuery
+		require.NoError(t, err)
+		require.Equal(t, semver.MustParse("4.1.0-6"), latest)
+	})
+
+	t.Run("Invalid/NoPromotedVersion", func(t *testing.T) {
+		ch := v1alpha2.ReleaseChannel{Name: "fast-4.1", PromotedIn: "stable-4.1"}
+		_, err := latestPromotedVersion(ctx, c, "test-arch", ch, map[string]semver.Version{})
+		<-requestQuery
+		require.EqualError(t, err, `release channel "fast-4.1": no release for test-arch is in channel "stable-4.1"`)
+	})
+
+	t.Run("Valid/FilterPromoted", func(t *testing.T) {
+		ch := v1alpha2.ReleaseChannel{Name: "fast-4.1", PromotedIn: "stable-4.1"}
+		releaseDownloads := downloads{
+			"quay.io/openshift-release-dev/ocp-release:4.1.0-6": struct{}{},
+			"quay.io/openshift-release-dev/ocp-release:4.1.1":   struct{}{},
+		}
+		filterPromoted(ch, releaseDownloads, promoted)
+		require.Equal(t, downloads{"quay.io/openshift-release-dev/ocp-release:4.1.0-6": struct{}{}}, releaseDownloads)
+	})
+}
@@ -0,0 +1,161 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/openshift/library-go/pkg/verify/util"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+const (
+	// releaseSignatureArtifactType is the config media type of the OCI
+	// artifacts holding the signatures of a release image. Registries
+	// report it as the artifact type of the referrers of the release.
+	releaseSignatureArtifactType types.MediaType = "application/vnd.openshift.release.signature.v1+json"
+	// releaseSignatureMediaType is the media type of each signature layer.
+	releaseSignatureMediaType types.MediaType = "application/pgp-signature"
+)
+
+// pushReleaseSignatures pushes the signatures found in sigDir for the release
+// images of mapping to the destination registry, one release at a time.
+// The signatures of a release are pushed as an OCI artifact whose subject is
+// the mirrored release manifest, so they are listed by the referrers of the release.
+// Releases without signatures in sigDir are skipped.
+func (o *MirrorOptions) pushReleaseSignatures(ctx context.Context, sigDir string, mapping image.TypedImageMapping) error {
+	insecure := o.DestPlainHTTP || o.DestSkipTLS
+	remoteOpts := getRemoteOpts(ctx, insecure)
+	nameOpts := getNameOpts(insecure)
+
+	var releases []image.TypedImage
+	for _, dst := range image.ByCategory(mapping, v1alpha2.TypeOCPRelease) {
+		if dst.Type != imagesource.DestinationRegistry || dst.Ref.ID == "" {
+			continue
+		}
+		releases = append(releases, dst)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Ref.Exact() < releases[j].Ref.Exact()
+	})
+
+	var errs []error
+	for _, release := range releases {
+		signatures, err := readReleaseSignatures(sigDir, release.Ref.ID)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			klog.Warningf("no signatures found for release image %s, skipping", release.Ref.Exact())
+			continue
+		case err != nil:
+			errs = append(errs, err)
+			continue
+		}
+		releaseRef, err := name.NewDigest(release.Ref.AsRepository().Exact()+"@"+release.Ref.ID, nameOpts...)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := pushReleaseSignatureArtifact(releaseRef, signatures, remoteOpts...); err != nil {
+			errs = append(errs, fmt.Errorf("error pushing signatures of release image %s: %v", releaseRef, err))
+			continue
+		}
+		klog.Infof("Pushed %d signatures of release image %s", len(signatures), releaseRef)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// readReleaseSignatures returns the signatures of the release digest from its
+// signature configmap in sigDir, ordered by signature number. It fails if the
+// configmap holds signatures of another digest.
+func readReleaseSignatures(sigDir, digest string) ([][]byte, error) {
+	fileName, err := createSignatureFileName(digest)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Clean(filepath.Join(sigDir, fileName)))
+	if err != nil {
+		return nil, err
+	}
+	cm, err := util.ReadConfigMap(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signatures of %s: %v", digest, err)
+	}
+	prefix, err := util.DigestToKeyPrefix(digest, "-")
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for key := range cm.BinaryData {
+		if !strings.HasPrefix(key, prefix+"-") {
+			return nil, fmt.Errorf("signature %s in %s does not belong to %s", key, fileName, digest)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signatures of %s in %s", digest, fileName)
+	}
+	// Keys end with the signature number, order sha256-<hash>-2 before sha256-<hash>-10.
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	signatures := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		signatures = append(signatures, cm.BinaryData[key])
+	}
+	return signatures, nil
+}
+
+// pushReleaseSignatureArtifact checks the release manifest exists in the registry,
+// then pushes the signatures as an artifact referring to it. Registries without
+// the referrers API get the artifact listed in the referrers fallback tag.
+func pushReleaseSignatureArtifact(releaseRef name.Digest, signatures [][]byte, opts ...remote.Option) error {
+	subject, err := remote.Head(releaseRef, opts...)
+	if err != nil {
+		return fmt.Errorf("release manifest not found in the registry: %v", err)
+	}
+	if subject.Digest.String() != releaseRef.DigestStr() {
+		return fmt.Errorf("registry returned digest %s", subject.Digest)
+	}
+
+	artifact := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	artifact = mutate.ConfigMediaType(artifact, releaseSignatureArtifactType)
+	for _, signature := range signatures {
+		artifact, err = mutate.Append(artifact, mutate.Addendum{
+			Layer: static.NewLayer(signature, releaseSignatureMediaType),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	artifact = mutate.Subject(artifact, v1.Descriptor{
+		MediaType: subject.MediaType,
+		Digest:    subject.Digest,
+		Size:      subject.Size,
+	}).(v1.Image)
+
+	digest, err := artifact.Digest()
+	if err != nil {
+		return err
+	}
+	return remote.Write(releaseRef.Context().Digest(digest.String()), artifact, opts...)
+}
@@ -0,0 +1,152 @@
+package mirror
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/library-go/pkg/verify"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestPushReleaseSignatures(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	release, err := random.Image(64, 1)
+	require.NoError(t, err)
+	releaseRef, err := name.ParseReference(u.Host + "/ns/openshift/release-images:4.14.1-x86_64")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(releaseRef, release))
+	releaseDigest, err := release.Digest()
+	require.NoError(t, err)
+
+	// Releases without signatures are skipped.
+	unsigned, err := random.Image(64, 1)
+	require.NoError(t, err)
+	unsignedDigest, err := unsigned.Digest()
+	require.NoError(t, err)
+
+	sigDir := t.TempDir()
+	signatures := [][]byte{[]byte("signature-1"), []byte("signature-2")}
+	cm, err := verify.GetSignaturesAsConfigmap(releaseDigest.String(), signatures)
+	require.NoError(t, err)
+	data, err := yaml.Marshal(cm)
+	require.NoError(t, err)
+	fileName, err := createSignatureFileName(releaseDigest.String())
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(sigDir, fileName), data, 0600))
+
+	typed := func(registry, namespace, name, id string) image.TypedImage {
+		return image.TypedImage{
+			TypedImageReference: image.TypedImageReference{
+				Type: imagesource.DestinationRegistry,
+				Ref:  reference.DockerImageReference{Registry: registry, Namespace: namespace, Name: name, ID: id},
+			},
+			Category: v1alpha2.TypeOCPRelease,
+		}
+	}
+	mapping := image.TypedImageMapping{
+		typed("quay.io", "openshift-release-dev", "ocp-release", releaseDigest.String()):  typed(u.Host, "ns/openshift", "release-images", releaseDigest.String()),
+		typed("quay.io", "openshift-release-dev", "ocp-release", unsignedDigest.String()): typed(u.Host, "ns/openshift", "release-images", unsignedDigest.String()),
+	}
+
+	opts := &MirrorOptions{
+		RootOptions:   &cli.RootOptions{},
+		DestPlainHTTP: true,
+	}
+	require.NoError(t, opts.pushReleaseSignatures(context.Background(), sigDir, mapping))
+
+	subject, err := name.NewDigest(u.Host + "/ns/openshift/release-images@" + releaseDigest.String())
+	require.NoError(t, err)
+	referrers, err := remote.Referrers(subject)
+	require.NoError(t, err)
+	manifest, err := referrers.IndexManifest()
+	require.NoError(t, err)
+	require.Len(t, manifest.Manifests, 1)
+	require.Equal(t, string(releaseSignatureArtifactType), manifest.Manifests[0].ArtifactType)
+
+	artifact, err := remote.Image(subject.Context().Digest(manifest.Manifests[0].Digest.String()))
+	require.NoError(t, err)
+	layers, err := artifact.Layers()
+	require.NoError(t, err)
+	require.Len(t, layers, len(signatures))
+	for i, layer := range layers {
+		mediaType, err := layer.MediaType()
+		require.NoError(t, err)
+		require.Equal(t, releaseSignatureMediaType, mediaType)
+		rc, err := layer.Uncompressed()
+		require.NoError(t, err)
+		got, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		require.Equal(t, signatures[i], got)
+	}
+}
+
+func TestReadReleaseSignatures(t *testing.T) {
+	const digest = "sha256:73946971c03b43a0dc6f7b0946b26a177c2f3c9d37105441315b4e3359373a55"
+	const otherDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	type spec struct {
+		name     string
+		cmDigest string
+		sigs     [][]byte
+		expSigs  [][]byte
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name:     "Valid/OrderedBySignatureNumber",
+			cmDigest: digest,
+			sigs:     [][]byte{[]byte("1"), []byte("2"), []byte("3"), []byte("4"), []byte("5"), []byte("6"), []byte("7"), []byte("8"), []byte("9"), []byte("10")},
+			expSigs:  [][]byte{[]byte("1"), []byte("2"), []byte("3"), []byte("4"), []byte("5"), []byte("6"), []byte("7"), []byte("8"), []byte("9"), []byte("10")},
+		},
+		{
+			name:     "Invalid/OtherDigest",
+			cmDigest: otherDigest,
+			sigs:     [][]byte{[]byte("1")},
+			expError: "signature sha256-0000000000000000000000000000000000000000000000000000000000000000-1 in " +
+				"signature-sha256-73946971c03b43a0.json does not belong to " + digest,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cm, err := verify.GetSignaturesAsConfigmap(c.cmDigest, c.sigs)
+			require.NoError(t, err)
+			data, err := yaml.Marshal(cm)
+			require.NoError(t, err)
+			fileName, err := createSignatureFileName(digest)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, fileName), data, 0600))
+
+			sigs, err := readReleaseSignatures(dir, digest)
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, c.expSigs, sigs)
+			}
+		})
+	}
+}
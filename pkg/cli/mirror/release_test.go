@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/uuid"
@@ -653,3 +655,46 @@ func getHandlerMulti(t *testing.T, requestQuery chan<- string) http.HandlerFunc
 		}
 	}
 }
+
+func TestReadReleaseDigests(t *testing.T) {
+	const (
+		release1 = "quay.io/openshift-release-dev/ocp-release@sha256:0000000000000000000000000000000000000000000000000000000000000001"
+		release2 = "quay.io/openshift-release-dev/ocp-release@sha256:0000000000000000000000000000000000000000000000000000000000000002"
+	)
+	type spec struct {
+		name     string
+		content  string
+		expected []string
+		expError string
+	}
+	cases := []spec{
+		{
+			name:     "Valid/PinList",
+			content:  "# approved releases\n" + release1 + "\n\n  " + release2 + "  \n" + release1 + "\n",
+			expected: []string{release1, release2},
+		},
+		{
+			name:     "Invalid/Tag",
+			content:  release1 + "\nquay.io/openshift-release-dev/ocp-release:4.14.1-x86_64\n",
+			expError: `release "quay.io/openshift-release-dev/ocp-release:4.14.1-x86_64" must be pinned by digest`,
+		},
+		{
+			name:     "Invalid/Empty",
+			content:  "# no approved releases\n",
+			expError: "no release digests found",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "release-digests.txt")
+			require.NoError(t, os.WriteFile(path, []byte(c.content), 0600))
+			releases, err := readReleaseDigests(path)
+			if c.expError != "" {
+				require.ErrorContains(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, releases)
+		})
+	}
+}
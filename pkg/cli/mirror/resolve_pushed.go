@@ -0,0 +1,61 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+const (
+	// defaultResolveTimeout is the default of --resolve-timeout.
+	defaultResolveTimeout = time.Minute
+	// maxResolveRetryInterval caps the interval between two resolutions.
+	maxResolveRetryInterval = 15 * time.Second
+)
+
+// resolveRetryInterval is the interval before the first retry of a resolution,
+// doubled after each retry.
+var resolveRetryInterval = time.Second
+
+// resolvePushedImage returns the pin of the image ref pushed by the run to
+// the destination. Geo-replicated registries can serve a pushed image only after
+// a delay, so resolutions failing because the image is not found or the registry
+// is unreachable are retried with an exponential backoff until --resolve-timeout.
+func (o *MirrorOptions) resolvePushedImage(ctx context.Context, sysContext *types.SystemContext, ref string) (string, error) {
+	return resolveWithBackoff(ctx, o.ResolveTimeout, ref, func(ctx context.Context) (string, error) {
+		return image.ResolveToPin(ctx, sysContext, ref)
+	})
+}
+
+func resolveWithBackoff(ctx context.Context, timeout time.Duration, ref string, resolve func(context.Context) (string, error)) (string, error) {
+	deadline := time.Now().Add(timeout)
+	interval := resolveRetryInterval
+	for attempt := 1; ; attempt++ {
+		pin, err := resolve(ctx)
+		if err == nil {
+			return pin, nil
+		}
+		if class := ClassifyError(err); class != ErrorClassNotFound && class != ErrorClassNetwork {
+			return "", err
+		}
+		wait := min(interval, time.Until(deadline))
+		if wait <= 0 {
+			if attempt > 1 {
+				return "", fmt.Errorf("%v (after %d attempts in %s, see --resolve-timeout)", err, attempt, timeout)
+			}
+			return "", err
+		}
+		klog.V(1).Infof("Image %s not resolved yet, retrying in %s: %v", ref, wait.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return "", err
+		case <-time.After(wait):
+		}
+		interval = min(2*interval, maxResolveRetryInterval)
+	}
+}
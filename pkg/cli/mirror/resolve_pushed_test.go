@@ -0,0 +1,85 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveWithBackoff(t *testing.T) {
+	defer func(interval time.Duration) { resolveRetryInterval = interval }(resolveRetryInterval)
+	resolveRetryInterval = time.Millisecond
+
+	const pin = "registry.example.com/catalog@sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	notFound := errors.New("reading manifest v4.17 in registry.example.com/catalog: manifest unknown")
+
+	type spec struct {
+		name     string
+		timeout  time.Duration
+		failures int
+		err      error
+		expCalls int
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name:     "Valid/Resolved",
+			timeout:  time.Second,
+			expCalls: 1,
+		},
+		{
+			name:     "Valid/ReplicationDelay",
+			timeout:  10 * time.Second,
+			failures: 3,
+			err:      notFound,
+			expCalls: 4,
+		},
+		{
+			name:     "Invalid/Timeout",
+			timeout:  20 * time.Millisecond,
+			failures: 1000,
+			err:      notFound,
+			expError: "manifest unknown (after",
+		},
+		{
+			name:     "Invalid/NoTimeout",
+			failures: 1,
+			err:      notFound,
+			expCalls: 1,
+			expError: notFound.Error(),
+		},
+		{
+			name:     "Invalid/NotRetried",
+			timeout:  10 * time.Second,
+			failures: 1,
+			err:      errors.New("invalid reference format"),
+			expCalls: 1,
+			expError: "invalid reference format",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			calls := 0
+			resolved, err := resolveWithBackoff(context.Background(), c.timeout, "registry.example.com/catalog:v4.17", func(context.Context) (string, error) {
+				calls++
+				if calls <= c.failures {
+					return "", c.err
+				}
+				return pin, nil
+			})
+			if c.expError != "" {
+				require.ErrorContains(t, err, c.expError)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, pin, resolved)
+			}
+			if c.expCalls != 0 {
+				require.Equal(t, c.expCalls, calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package mirror
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	imagev1 "github.com/openshift/api/image/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	samplesv1 "github.com/openshift/api/samples/v1"
+	imgreference "github.com/openshift/library-go/pkg/image/reference"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+const (
+	// samplesOperatorComponent is the component of the release
+	// payloads holding the sample imagestreams.
+	samplesOperatorComponent = "cluster-samples-operator"
+	// samplesAssetsDir is the directory of the samples operator image
+	// holding the imagestreams and templates of each architecture.
+	samplesAssetsDir = "opt/openshift/operator"
+	// samplesRegistrySource is the registry of the sample images
+	// the samples operator replaces with its samplesRegistry.
+	samplesRegistrySource = "registry.redhat.io"
+	// samplesConfigFile is the file of the results directory
+	// holding the samples operator configuration.
+	samplesConfigFile = "samplesConfig.yaml"
+)
+
+// samplesOperator is the samples operator image of a mirrored release.
+type samplesOperator struct {
+	version semver.Version
+	// arch is the architecture of the release, such as x86_64 or multi.
+	arch  string
+	image image.TypedImage
+}
+
+// samplesOperators returns the samples operator images of the release
+// payloads of mapping, read from the destination tags of the release
+// content: <version>-<arch>-cluster-samples-operator.
+func samplesOperators(mapping image.TypedImageMapping) []samplesOperator {
+	var operators []samplesOperator
+	for src, dst := range mapping {
+		if src.Category != v1alpha2.TypeOCPReleaseContent {
+			continue
+		}
+		prefix, ok := strings.CutSuffix(dst.Ref.Tag, "-"+samplesOperatorComponent)
+		if !ok {
+			continue
+		}
+		i := strings.LastIndex(prefix, "-")
+		if i <= 0 {
+			continue
+		}
+		version, err := semver.Parse(prefix[:i])
+		if err != nil {
+			klog.V(2).Infof("skipping samples operator image %s: %v", dst.Ref.Exact(), err)
+			continue
+		}
+		operators = append(operators, samplesOperator{version: version, arch: prefix[i+1:], image: src})
+	}
+	sort.Slice(operators, func(i, j int) bool {
+		if !operators[i].version.EQ(operators[j].version) {
+			return operators[i].version.LT(operators[j].version)
+		}
+		return operators[i].arch < operators[j].arch
+	})
+	return operators
+}
+
+// selectSamplesOperators returns the samples operator images of the
+// release of version release, or of the latest release if it is empty.
+func selectSamplesOperators(operators []samplesOperator, release string) ([]samplesOperator, error) {
+	if len(operators) == 0 {
+		return nil, fmt.Errorf("no %s image found in the mirrored releases", samplesOperatorComponent)
+	}
+	version := operators[len(operators)-1].version
+	if release != "" {
+		var err error
+		if version, err = semver.Parse(release); err != nil {
+			return nil, fmt.Errorf("invalid samples release version %q: %v", release, err)
+		}
+	}
+	var selected []samplesOperator
+	for _, op := range operators {
+		if op.version.EQ(version) {
+			selected = append(selected, op)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("samples release %s is not one of the mirrored releases", release)
+	}
+	return selected, nil
+}
+
+// readSampleImageStreams returns the imagestreams of architecture arch
+// of the samples operator image filesystem tarball r, keyed by name.
+// The imagestreams of every architecture are returned for multi.
+func readSampleImageStreams(r io.Reader, arch string) (map[string]imagev1.ImageStream, error) {
+	streams := map[string]imagev1.ImageStream{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return streams, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		fpath := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		archPath, ok := strings.CutPrefix(fpath, samplesAssetsDir+"/")
+		if hdr.Typeflag != tar.TypeReg || !ok || path.Ext(fpath) != ".json" || path.Base(path.Dir(fpath)) != "imagestreams" {
+			continue
+		}
+		if arch != "multi" && !strings.HasPrefix(archPath, arch+"/") {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		var stream imagev1.ImageStream
+		if err := json.Unmarshal(data, &stream); err != nil {
+			return nil, fmt.Errorf("error parsing imagestream %s: %v", fpath, err)
+		}
+		if stream.Kind != "ImageStream" || stream.Name == "" {
+			continue
+		}
+		streams[stream.Name] = stream
+	}
+}
+
+// sampleImages returns the images referenced by the tags of the imagestreams, sorted.
+// The tags referencing other imagestream tags have no image of their own.
+func sampleImages(streams map[string]imagev1.ImageStream) []string {
+	seen := map[string]bool{}
+	var images []string
+	for _, stream := range streams {
+		for _, tag := range stream.Spec.Tags {
+			if tag.From == nil || tag.From.Kind != "DockerImage" || seen[tag.From.Name] {
+				continue
+			}
+			seen[tag.From.Name] = true
+			images = append(images, tag.From.Name)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// selectImageStreams returns the imagestreams of streams named by names, or all
+// of them if names is empty, and the names of the imagestreams left out, sorted.
+func selectImageStreams(streams map[string]imagev1.ImageStream, names []string) (map[string]imagev1.ImageStream, []string, error) {
+	if len(names) == 0 {
+		return streams, nil, nil
+	}
+	selected := make(map[string]imagev1.ImageStream, len(names))
+	var missing []string
+	for _, name := range names {
+		stream, ok := streams[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		selected[name] = stream
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return nil, nil, fmt.Errorf("sample imagestreams not found: %s", strings.Join(missing, ", "))
+	}
+	var skipped []string
+	for name := range streams {
+		if _, ok := selected[name]; !ok {
+			skipped = append(skipped, name)
+		}
+	}
+	sort.Strings(skipped)
+	return selected, skipped, nil
+}
+
+// planSamples returns the mapping of the images of the sample imagestreams
+// of the release selected by the platform samples of cfg, read from the
+// samples operator image of releaseMapping. The imagestreams mirrored are
+// recorded in o.samples.
+func (o *MirrorOptions) planSamples(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, releaseMapping image.TypedImageMapping) (image.TypedImageMapping, error) {
+	samples := cfg.Mirror.Platform.Samples
+	operators, err := selectSamplesOperators(samplesOperators(releaseMapping), samples.Release)
+	if err != nil {
+		return nil, err
+	}
+
+	insecure := o.SourcePlainHTTP || o.SourceSkipTLS
+	streams := map[string]imagev1.ImageStream{}
+	for _, op := range operators {
+		klog.V(1).Infof("Reading sample imagestreams of release %s-%s from %s", op.version, op.arch, op.image.Ref.Exact())
+		ref, err := name.ParseReference(op.image.Ref.Exact(), getNameOpts(insecure)...)
+		if err != nil {
+			return nil, err
+		}
+		img, err := remote.Image(ref, getRemoteOpts(ctx, insecure)...)
+		if err != nil {
+			return nil, fmt.Errorf("error pulling samples operator image %s: %v", op.image.Ref.Exact(), err)
+		}
+		rc := mutate.Extract(img)
+		found, err := readSampleImageStreams(rc, op.arch)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading sample imagestreams of %s: %v", op.image.Ref.Exact(), err)
+		}
+		for name, stream := range found {
+			streams[name] = stream
+		}
+	}
+
+	version := operators[0].version.String()
+	selected, skipped, err := selectImageStreams(streams, samples.ImageStreams)
+	if err != nil {
+		return nil, fmt.Errorf("release %s: %v", version, err)
+	}
+	names := make([]string, 0, len(selected))
+	for name := range selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	o.samples = &v1alpha2.SamplesMetadata{
+		Release:             version,
+		ImageStreams:        names,
+		SkippedImageStreams: skipped,
+	}
+
+	var images []v1alpha2.Image
+	for _, img := range sampleImages(selected) {
+		images = append(images, v1alpha2.Image{Name: img})
+	}
+	klog.Infof("Adding %d images of %d sample imagestreams of release %s", len(images), len(names), version)
+	return NewAdditionalOptions(o).Plan(ctx, images)
+}
+
+// samplesConfig is the samples operator configuration without status,
+// the operator reports it.
+type samplesConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              samplesv1.ConfigSpec `json:"spec"`
+}
+
+// samplesConfigResource returns the samples operator configuration
+// installing the mirrored imagestreams of samples from registry.
+func samplesConfigResource(samples v1alpha2.SamplesMetadata, registry string) (clusterResource, error) {
+	obj := samplesConfig{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: samplesv1.GroupVersion.String(),
+			Kind:       "Config",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: samplesv1.ConfigName,
+		},
+		Spec: samplesv1.ConfigSpec{
+			ManagementState:     operatorv1.Managed,
+			SamplesRegistry:     registry,
+			SkippedImagestreams: samples.SkippedImageStreams,
+		},
+	}
+	// Create an unstructured object for removing creationTimestamp
+	unstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&obj)
+	if err != nil {
+		return clusterResource{}, fmt.Errorf("error converting to unstructured: %v", err)
+	}
+	delete(unstructured["metadata"].(map[string]interface{}), "creationTimestamp")
+	data, err := yaml.Marshal(unstructured)
+	if err != nil {
+		return clusterResource{}, fmt.Errorf("unable to marshal samples Config yaml: %v", err)
+	}
+	return clusterResource{kind: "samplesConfig", name: samplesv1.ConfigName, data: data}, nil
+}
+
+// samplesRegistry returns the registry and namespace the samples
+// operator pulls the images of registry.redhat.io from.
+func (o *MirrorOptions) samplesRegistry() string {
+	src := imgreference.DockerImageReference{Registry: samplesRegistrySource}
+	return path.Join(o.ToMirror, o.UserNamespace, mappedNamespace(o.namespaceMappings, src))
+}
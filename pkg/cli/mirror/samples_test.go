@@ -0,0 +1,219 @@
+package mirror
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+const testSampleImageStream = `{
+  "kind": "ImageStream",
+  "apiVersion": "image.openshift.io/v1",
+  "metadata": {"name": %q},
+  "spec": {
+    "tags": [
+      {"name": "latest", "from": {"kind": "ImageStreamTag", "name": "1.0"}},
+      {"name": "1.0", "from": {"kind": "DockerImage", "name": %q}}
+    ]
+  }
+}`
+
+// samplesOperatorLayer returns a layer holding the imagestreams
+// of images, keyed by name, in the assets of architecture arch.
+func samplesOperatorLayer(t *testing.T, arch string, images map[string]string) []byte {
+	var layer bytes.Buffer
+	tw := tar.NewWriter(&layer)
+	for stream, img := range images {
+		data := []byte(fmt.Sprintf(testSampleImageStream, stream, img))
+		hdr := &tar.Header{
+			Name:     fmt.Sprintf("%s/%s/%s/imagestreams/%s-rhel.json", samplesAssetsDir, arch, stream, stream),
+			Mode:     0644,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	// The templates are not imagestreams.
+	template := []byte(`{"kind": "Template", "metadata": {"name": "rails"}}`)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: samplesAssetsDir + "/" + arch + "/ruby/templates/rails.json", Mode: 0644, Size: int64(len(template)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(template)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	return layer.Bytes()
+}
+
+func TestSamplesOperators(t *testing.T) {
+	mapping := image.TypedImageMapping{}
+	add := func(src, dst string, typ v1alpha2.ImageType) {
+		srcRef, err := image.ParseReference(src)
+		require.NoError(t, err)
+		dstRef, err := image.ParseReference(dst)
+		require.NoError(t, err)
+		mapping.Add(srcRef, dstRef, typ)
+	}
+	add("quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		"file://openshift/release:4.14.1-x86_64-cluster-samples-operator", v1alpha2.TypeOCPReleaseContent)
+	add("quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:2222222222222222222222222222222222222222222222222222222222222222",
+		"file://openshift/release:4.14.2-x86_64-cluster-samples-operator", v1alpha2.TypeOCPReleaseContent)
+	add("quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:3333333333333333333333333333333333333333333333333333333333333333",
+		"file://openshift/release:4.14.2-aarch64-cluster-samples-operator", v1alpha2.TypeOCPReleaseContent)
+	add("quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:4444444444444444444444444444444444444444444444444444444444444444",
+		"file://openshift/release:4.14.2-x86_64-cluster-version-operator", v1alpha2.TypeOCPReleaseContent)
+	operators := samplesOperators(mapping)
+	require.Len(t, operators, 3)
+
+	type spec struct {
+		name     string
+		release  string
+		expArchs []string
+		expError string
+	}
+	cases := []spec{
+		{name: "Valid/Latest", expArchs: []string{"aarch64", "x86_64"}},
+		{name: "Valid/Release", release: "4.14.1", expArchs: []string{"x86_64"}},
+		{name: "Invalid/NotMirrored", release: "4.13.0", expError: "samples release 4.13.0 is not one of the mirrored releases"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			selected, err := selectSamplesOperators(operators, c.release)
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			var archs []string
+			for _, op := range selected {
+				archs = append(archs, op.arch)
+			}
+			require.Equal(t, c.expArchs, archs)
+		})
+	}
+	t.Run("Invalid/NoOperator", func(t *testing.T) {
+		_, err := selectSamplesOperators(nil, "")
+		require.EqualError(t, err, "no cluster-samples-operator image found in the mirrored releases")
+	})
+}
+
+func TestReadSampleImageStreams(t *testing.T) {
+	layer := samplesOperatorLayer(t, "x86_64", map[string]string{
+		"ruby":   "registry.redhat.io/ubi8/ruby-30:latest",
+		"nodejs": "registry.redhat.io/ubi8/nodejs-18:latest",
+	})
+	streams, err := readSampleImageStreams(bytes.NewReader(layer), "x86_64")
+	require.NoError(t, err)
+	require.Len(t, streams, 2)
+	require.Equal(t, []string{"registry.redhat.io/ubi8/nodejs-18:latest", "registry.redhat.io/ubi8/ruby-30:latest"}, sampleImages(streams))
+
+	other, err := readSampleImageStreams(bytes.NewReader(layer), "s390x")
+	require.NoError(t, err)
+	require.Empty(t, other)
+
+	t.Run("Valid/SelectImageStreams", func(t *testing.T) {
+		selected, skipped, err := selectImageStreams(streams, []string{"ruby"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"registry.redhat.io/ubi8/ruby-30:latest"}, sampleImages(selected))
+		require.Equal(t, []string{"nodejs"}, skipped)
+	})
+	t.Run("Invalid/SelectImageStreams", func(t *testing.T) {
+		_, _, err := selectImageStreams(streams, []string{"ruby", "perl", "php"})
+		require.EqualError(t, err, "sample imagestreams not found: perl, php")
+	})
+}
+
+func TestPlanSamples(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	sample, err := random.Image(16, 1)
+	require.NoError(t, err)
+	sampleRef, err := name.ParseReference(u.Host + "/ubi8/ruby-30:latest")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(sampleRef, sample))
+	sampleDigest, err := sample.Digest()
+	require.NoError(t, err)
+
+	layer := samplesOperatorLayer(t, "x86_64", map[string]string{
+		"ruby":   sampleRef.String(),
+		"nodejs": u.Host + "/ubi8/nodejs-18:latest",
+	})
+	operatorImg, err := mutate.AppendLayers(empty.Image, static.NewLayer(layer, types.OCIUncompressedLayer))
+	require.NoError(t, err)
+	operatorRef, err := name.ParseReference(u.Host + "/openshift-release-dev/ocp-v4.0-art-dev:samples")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(operatorRef, operatorImg))
+
+	releaseMapping := image.TypedImageMapping{}
+	srcRef, err := image.ParseReference(operatorRef.String())
+	require.NoError(t, err)
+	dstRef, err := image.ParseReference("file://openshift/release:4.14.1-x86_64-cluster-samples-operator")
+	require.NoError(t, err)
+	releaseMapping.Add(srcRef, dstRef, v1alpha2.TypeOCPReleaseContent)
+
+	cfg := v1alpha2.ImageSetConfiguration{}
+	cfg.Mirror.Platform.Samples = &v1alpha2.PlatformSamples{ImageStreams: []string{"ruby"}}
+	opts := &MirrorOptions{
+		RootOptions:     &cli.RootOptions{Dir: t.TempDir()},
+		SourcePlainHTTP: true,
+	}
+	mapping, err := opts.planSamples(context.Background(), cfg, releaseMapping)
+	require.NoError(t, err)
+	require.Len(t, mapping, 1)
+	for src := range mapping {
+		require.Equal(t, v1alpha2.TypeGeneric, src.Category)
+		require.Equal(t, "ubi8/ruby-30", src.Ref.RepositoryName())
+		require.Equal(t, sampleDigest.String(), src.Ref.ID)
+	}
+	require.Equal(t, &v1alpha2.SamplesMetadata{
+		Release:             "4.14.1",
+		ImageStreams:        []string{"ruby"},
+		SkippedImageStreams: []string{"nodejs"},
+	}, opts.samples)
+}
+
+func TestSamplesConfigResource(t *testing.T) {
+	opts := &MirrorOptions{
+		ToMirror:          "registry.example.com",
+		UserNamespace:     "mirror",
+		namespaceMappings: []v1alpha2.NamespaceMapping{{Source: "registry.redhat.io", Namespace: "redhat"}},
+	}
+	resource, err := samplesConfigResource(v1alpha2.SamplesMetadata{
+		Release:             "4.14.1",
+		ImageStreams:        []string{"ruby"},
+		SkippedImageStreams: []string{"nodejs", "perl"},
+	}, opts.samplesRegistry())
+	require.NoError(t, err)
+	require.Equal(t, "samplesConfig-cluster.yaml", resource.fileName())
+	require.Equal(t, `apiVersion: samples.operator.openshift.io/v1
+kind: Config
+metadata:
+  name: cluster
+spec:
+  managementState: Managed
+  samplesRegistry: registry.example.com/mirror/redhat
+  skippedImagestreams:
+  - nodejs
+  - perl
+`, string(resource.data))
+}
@@ -0,0 +1,426 @@
+package mirror
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+const (
+	// seedFormatDockerArchive writes the images of the run to a
+	// docker-archive, loadable by podman load and docker load.
+	seedFormatDockerArchive = "docker-archive"
+	// seedFormatOCIArchive writes the images of the run to an oci-archive,
+	// an OCI image layout in a tar file, loadable by podman load.
+	seedFormatOCIArchive = "oci-archive"
+	seedArchiveFile      = "mirror_seed.tar"
+
+	// ociRefNameAnnotation and containerdImageNameAnnotation name the
+	// images of the index of an oci-archive for the tools loading it.
+	ociRefNameAnnotation          = "org.opencontainers.image.ref.name"
+	containerdImageNameAnnotation = "io.containerd.image.name"
+)
+
+// seedPlatform is the platform of the image written to a docker-archive
+// for the images mirrored as a manifest list, as docker-archives
+// cannot hold manifest lists.
+var seedPlatform = v1.Platform{OS: "linux", Architecture: "amd64"}
+
+// seedImage is an image of the workspace written to a seed archive.
+type seedImage struct {
+	ref     name.Tag // Name of the image in the archive, the source repository with its tag
+	repo    string   // Repository of the image in the v2 directory of the workspace
+	tagOrID string   // Tag or digest of the manifest of the image in the repository
+}
+
+// seedImages returns the images of mapping mirrored to the workspace,
+// sorted by name. The images are named after their source, and tagged with
+// their source tag or, when pulled by digest, the tag of their destination.
+func seedImages(mapping image.TypedImageMapping) ([]seedImage, error) {
+	var images []seedImage
+	for src, dst := range mapping {
+		tag := src.Ref.Tag
+		if tag == "" {
+			tag = dst.Ref.Tag
+		}
+		tagOrID := dst.Ref.Tag
+		if tagOrID == "" {
+			tagOrID = dst.Ref.ID
+		}
+		if tag == "" || tagOrID == "" {
+			return nil, fmt.Errorf("image %s has no tag", src.Ref.Exact())
+		}
+		ref, err := name.NewTag(src.Ref.DockerClientDefaults().AsRepository().Exact()+":"+tag, name.WeakValidation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image name for %s: %v", src.Ref.Exact(), err)
+		}
+		images = append(images, seedImage{ref: ref, repo: dst.Ref.AsRepository().String(), tagOrID: tagOrID})
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].ref.String() < images[j].ref.String()
+	})
+	return images, nil
+}
+
+// localStore reads the manifests and blobs of the images
+// mirrored to the v2 directory of the workspace.
+type localStore struct {
+	v2Dir string
+	// blobs are the paths of the blobs of the v2 directory keyed by digest,
+	// read when a blob is not in the repository of its image.
+	blobs map[string]string
+}
+
+// manifest returns the manifest of the tag or digest of repo, with its media type.
+func (s *localStore) manifest(repo, tagOrID string) ([]byte, types.MediaType, error) {
+	data, err := os.ReadFile(filepath.Join(s.v2Dir, filepath.FromSlash(repo), "manifests", tagOrID))
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading manifest %s of %s: %v", tagOrID, repo, err)
+	}
+	var meta struct {
+		MediaType     types.MediaType `json:"mediaType"`
+		SchemaVersion int             `json:"schemaVersion"`
+		Manifests     json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, "", fmt.Errorf("error parsing manifest %s of %s: %v", tagOrID, repo, err)
+	}
+	mediaType := meta.MediaType
+	if mediaType == "" {
+		// OCI manifests may omit their media type.
+		mediaType = types.OCIManifestSchema1
+		if meta.Manifests != nil {
+			mediaType = types.OCIImageIndex
+		}
+	}
+	if meta.SchemaVersion == 1 {
+		return nil, "", fmt.Errorf("manifest %s of %s: schema 1 manifests are not supported", tagOrID, repo)
+	}
+	return data, mediaType, nil
+}
+
+// blobPath returns the path of the blob digest of repo, in the
+// repository or, when it is not there, anywhere in the v2 directory.
+func (s *localStore) blobPath(repo string, digest v1.Hash) (string, error) {
+	blobPath := filepath.Join(s.v2Dir, filepath.FromSlash(repo), config.BlobDir, digest.String())
+	if _, err := os.Stat(blobPath); err == nil {
+		return blobPath, nil
+	}
+	if s.blobs == nil {
+		s.blobs = map[string]string{}
+		err := filepath.WalkDir(s.v2Dir, func(fpath string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() || filepath.Base(filepath.Dir(fpath)) != config.BlobDir {
+				return err
+			}
+			s.blobs[d.Name()] = fpath
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	blobPath, ok := s.blobs[digest.String()]
+	if !ok {
+		return "", fmt.Errorf("blob %s of %s not found in the workspace", digest, repo)
+	}
+	return blobPath, nil
+}
+
+// image returns the image of the manifest of repo. Manifest lists
+// are resolved to their image of seedPlatform.
+func (s *localStore) image(repo, tagOrID string) (v1.Image, error) {
+	data, mediaType, err := s.manifest(repo, tagOrID)
+	if err != nil {
+		return nil, err
+	}
+	if mediaType.IsIndex() {
+		index, err := v1.ParseIndexManifest(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing manifest list %s of %s: %v", tagOrID, repo, err)
+		}
+		for _, desc := range index.Manifests {
+			if desc.Platform != nil && desc.Platform.Satisfies(seedPlatform) {
+				return s.image(repo, desc.Digest.String())
+			}
+		}
+		return nil, fmt.Errorf("manifest list %s of %s has no image for %s", tagOrID, repo, seedPlatform.String())
+	}
+	return partial.CompressedToImage(&localImage{store: s, repo: repo, manifest: data, mediaType: mediaType})
+}
+
+// localImage is an image of the workspace, its blobs read from the local store.
+type localImage struct {
+	store     *localStore
+	repo      string
+	manifest  []byte
+	mediaType types.MediaType
+}
+
+var _ partial.CompressedImageCore = (*localImage)(nil)
+
+func (i *localImage) RawManifest() ([]byte, error) {
+	return i.manifest, nil
+}
+
+func (i *localImage) MediaType() (types.MediaType, error) {
+	return i.mediaType, nil
+}
+
+func (i *localImage) RawConfigFile() ([]byte, error) {
+	m, err := v1.ParseManifest(bytes.NewReader(i.manifest))
+	if err != nil {
+		return nil, err
+	}
+	configPath, err := i.store.blobPath(i.repo, m.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Clean(configPath))
+}
+
+func (i *localImage) LayerByDigest(digest v1.Hash) (partial.CompressedLayer, error) {
+	m, err := v1.ParseManifest(bytes.NewReader(i.manifest))
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range m.Layers {
+		if desc.Digest != digest {
+			continue
+		}
+		layerPath, err := i.store.blobPath(i.repo, digest)
+		if err != nil {
+			return nil, err
+		}
+		return &localLayer{path: layerPath, desc: desc}, nil
+	}
+	return nil, fmt.Errorf("layer %s not found in the manifest", digest)
+}
+
+// localLayer is a compressed layer of an image of the workspace.
+type localLayer struct {
+	path string
+	desc v1.Descriptor
+}
+
+func (l *localLayer) Digest() (v1.Hash, error) {
+	return l.desc.Digest, nil
+}
+
+func (l *localLayer) Compressed() (io.ReadCloser, error) {
+	return os.Open(filepath.Clean(l.path))
+}
+
+func (l *localLayer) Size() (int64, error) {
+	return l.desc.Size, nil
+}
+
+func (l *localLayer) MediaType() (types.MediaType, error) {
+	return l.desc.MediaType, nil
+}
+
+// writeDockerArchive writes the images to w as a docker-archive.
+func writeDockerArchive(w io.Writer, store *localStore, images []seedImage) error {
+	refToImage := make(map[name.Reference]v1.Image, len(images))
+	for _, img := range images {
+		v1img, err := store.image(img.repo, img.tagOrID)
+		if err != nil {
+			return fmt.Errorf("image %s: %v", img.ref, err)
+		}
+		refToImage[img.ref] = v1img
+	}
+	return tarball.MultiRefWrite(refToImage, w)
+}
+
+// ociArchiveWriter writes an OCI image layout to a tar stream,
+// each blob once.
+type ociArchiveWriter struct {
+	tw      *tar.Writer
+	store   *localStore
+	written map[v1.Hash]bool
+}
+
+// writeOCIArchive writes the images to w as an OCI image layout, their manifest
+// lists kept. The images are named in the index with their full name.
+func writeOCIArchive(w io.Writer, store *localStore, images []seedImage) error {
+	aw := &ociArchiveWriter{tw: tar.NewWriter(w), store: store, written: map[v1.Hash]bool{}}
+	if err := aw.writeFile("oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)); err != nil {
+		return err
+	}
+	for _, dir := range []string{"blobs", "blobs/sha256"} {
+		if err := aw.writeDir(dir); err != nil {
+			return err
+		}
+	}
+
+	index := v1.IndexManifest{SchemaVersion: 2, MediaType: types.OCIImageIndex}
+	for _, img := range images {
+		desc, err := aw.writeManifest(img.repo, img.tagOrID)
+		if err != nil {
+			return fmt.Errorf("image %s: %v", img.ref, err)
+		}
+		desc.Annotations = map[string]string{
+			ociRefNameAnnotation:          img.ref.String(),
+			containerdImageNameAnnotation: img.ref.String(),
+		}
+		index.Manifests = append(index.Manifests, desc)
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := aw.writeFile("index.json", data); err != nil {
+		return err
+	}
+	return aw.tw.Close()
+}
+
+// writeManifest writes the manifest of repo, and the manifests, configs and
+// layers it refers to, and returns its descriptor.
+func (aw *ociArchiveWriter) writeManifest(repo, tagOrID string) (v1.Descriptor, error) {
+	data, mediaType, err := aw.store.manifest(repo, tagOrID)
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if mediaType.IsIndex() {
+		index, err := v1.ParseIndexManifest(bytes.NewReader(data))
+		if err != nil {
+			return v1.Descriptor{}, err
+		}
+		for _, desc := range index.Manifests {
+			if _, err := aw.writeManifest(repo, desc.Digest.String()); err != nil {
+				return v1.Descriptor{}, err
+			}
+		}
+	} else {
+		m, err := v1.ParseManifest(bytes.NewReader(data))
+		if err != nil {
+			return v1.Descriptor{}, err
+		}
+		for _, desc := range append([]v1.Descriptor{m.Config}, m.Layers...) {
+			if err := aw.writeBlob(repo, desc); err != nil {
+				return v1.Descriptor{}, err
+			}
+		}
+	}
+
+	digest, size, err := v1.SHA256(bytes.NewReader(data))
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	if !aw.written[digest] {
+		if err := aw.writeFile("blobs/sha256/"+digest.Hex, data); err != nil {
+			return v1.Descriptor{}, err
+		}
+		aw.written[digest] = true
+	}
+	return v1.Descriptor{MediaType: mediaType, Digest: digest, Size: size}, nil
+}
+
+// writeBlob copies the blob of desc from the local store.
+func (aw *ociArchiveWriter) writeBlob(repo string, desc v1.Descriptor) error {
+	if aw.written[desc.Digest] {
+		return nil
+	}
+	blobPath, err := aw.store.blobPath(repo, desc.Digest)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Clean(blobPath))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := aw.tw.WriteHeader(&tar.Header{
+		Name:     "blobs/sha256/" + desc.Digest.Hex,
+		Mode:     0644,
+		Size:     info.Size(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	if _, err := io.Copy(aw.tw, f); err != nil {
+		return err
+	}
+	aw.written[desc.Digest] = true
+	return nil
+}
+
+func (aw *ociArchiveWriter) writeFile(name string, data []byte) error {
+	if err := aw.tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	_, err := aw.tw.Write(data)
+	return err
+}
+
+func (aw *ociArchiveWriter) writeDir(name string) error {
+	return aw.tw.WriteHeader(&tar.Header{Name: name + "/", Mode: 0755, Typeflag: tar.TypeDir})
+}
+
+// writeSeedArchive writes the images of mapping, mirrored to the workspace,
+// to the seed archive of the output directory in the format of --seed-format,
+// to be loaded directly with podman load instead of published by oc-mirror.
+func (o *MirrorOptions) writeSeedArchive(mapping image.TypedImageMapping) (err error) {
+	images, err := seedImages(mapping)
+	if err != nil {
+		return err
+	}
+	store := &localStore{v2Dir: filepath.Join(o.Dir, config.SourceDir, config.V2Dir)}
+
+	archivePath := filepath.Join(o.OutputDir, seedArchiveFile)
+	klog.Infof("Writing %d images to %s %s", len(images), o.SeedFormat, archivePath)
+	f, err := os.Create(filepath.Clean(archivePath))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			if rerr := os.Remove(archivePath); rerr != nil && !errors.Is(rerr, os.ErrNotExist) {
+				klog.Warningf("error removing incomplete seed archive %s: %v", archivePath, rerr)
+			}
+		}
+	}()
+
+	switch o.SeedFormat {
+	case seedFormatDockerArchive:
+		err = writeDockerArchive(f, store, images)
+	case seedFormatOCIArchive:
+		err = writeOCIArchive(f, store, images)
+	default:
+		err = fmt.Errorf("unknown seed format %q", o.SeedFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing seed archive: %v", err)
+	}
+	o.summary.Archives = []string{archivePath}
+	return nil
+}
@@ -0,0 +1,181 @@
+package mirror
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	ggcrvalidate "github.com/google/go-containerregistry/pkg/v1/validate"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// writeWorkspaceImage writes the manifest of img to repo of v2Dir, and
+// its config and layers to the blobs of blobRepo, as oc mirrors to disk.
+func writeWorkspaceImage(t *testing.T, v2Dir, repo, blobRepo string, img v1.Image) v1.Hash {
+	t.Helper()
+	blobsDir := filepath.Join(v2Dir, blobRepo, config.BlobDir)
+	require.NoError(t, os.MkdirAll(blobsDir, 0750))
+	configName, err := img.ConfigName()
+	require.NoError(t, err)
+	configData, err := img.RawConfigFile()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(blobsDir, configName.String()), configData, 0600))
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	for _, layer := range layers {
+		digest, err := layer.Digest()
+		require.NoError(t, err)
+		rc, err := layer.Compressed()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		require.NoError(t, os.WriteFile(filepath.Join(blobsDir, digest.String()), data, 0600))
+	}
+	data, err := img.RawManifest()
+	require.NoError(t, err)
+	return writeWorkspaceManifest(t, v2Dir, repo, data)
+}
+
+func writeWorkspaceManifest(t *testing.T, v2Dir, repo string, data []byte) v1.Hash {
+	t.Helper()
+	manifestsDir := filepath.Join(v2Dir, repo, "manifests")
+	require.NoError(t, os.MkdirAll(manifestsDir, 0750))
+	digest, _, err := v1.SHA256(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(manifestsDir, digest.String()), data, 0600))
+	return digest
+}
+
+func TestWriteSeedArchive(t *testing.T) {
+	workspace := t.TempDir()
+	v2Dir := filepath.Join(workspace, config.SourceDir, config.V2Dir)
+
+	// A single image by tag, its blobs stored in another repository.
+	single, err := random.Image(64, 2)
+	require.NoError(t, err)
+	singleDigest := writeWorkspaceImage(t, v2Dir, "ns/single", "ns/other", single)
+	require.NoError(t, os.Symlink(singleDigest.String(), filepath.Join(v2Dir, "ns/single/manifests/v1")))
+
+	// A manifest list pulled by digest.
+	amd64, err := random.Image(64, 1)
+	require.NoError(t, err)
+	arm64, err := random.Image(64, 1)
+	require.NoError(t, err)
+	writeWorkspaceImage(t, v2Dir, "ns/multi", "ns/multi", amd64)
+	writeWorkspaceImage(t, v2Dir, "ns/multi", "ns/multi", arm64)
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+	)
+	idxData, err := idx.RawManifest()
+	require.NoError(t, err)
+	idxDigest := writeWorkspaceManifest(t, v2Dir, "ns/multi", idxData)
+	require.NoError(t, os.Symlink(idxDigest.String(), filepath.Join(v2Dir, "ns/multi/manifests/latest")))
+
+	typed := func(typ imagesource.DestinationType, ref reference.DockerImageReference) image.TypedImage {
+		return image.TypedImage{
+			TypedImageReference: image.TypedImageReference{Type: typ, Ref: ref},
+			Category:            v1alpha2.TypeGeneric,
+		}
+	}
+	mapping := image.TypedImageMapping{
+		typed(imagesource.DestinationRegistry, reference.DockerImageReference{Registry: "quay.io", Namespace: "ns", Name: "single", Tag: "v1"}):             typed(imagesource.DestinationFile, reference.DockerImageReference{Namespace: "ns", Name: "single", Tag: "v1"}),
+		typed(imagesource.DestinationRegistry, reference.DockerImageReference{Registry: "quay.io", Namespace: "ns", Name: "multi", ID: idxDigest.String()}): typed(imagesource.DestinationFile, reference.DockerImageReference{Namespace: "ns", Name: "multi", Tag: "latest", ID: idxDigest.String()}),
+	}
+
+	t.Run("Valid/DockerArchive", func(t *testing.T) {
+		opts := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: workspace}, OutputDir: t.TempDir(), SeedFormat: seedFormatDockerArchive}
+		require.NoError(t, opts.writeSeedArchive(mapping))
+		archivePath := filepath.Join(opts.OutputDir, seedArchiveFile)
+		require.Equal(t, []string{archivePath}, opts.summary.Archives)
+
+		for tag, want := range map[string]v1.Image{"quay.io/ns/single:v1": single, "quay.io/ns/multi:latest": amd64} {
+			ref, err := name.NewTag(tag)
+			require.NoError(t, err)
+			img, err := tarball.ImageFromPath(archivePath, &ref)
+			require.NoError(t, err)
+			wantConfig, err := want.ConfigName()
+			require.NoError(t, err)
+			gotConfig, err := img.ConfigName()
+			require.NoError(t, err)
+			require.Equal(t, wantConfig, gotConfig, tag)
+		}
+	})
+	t.Run("Valid/OCIArchive", func(t *testing.T) {
+		opts := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: workspace}, OutputDir: t.TempDir(), SeedFormat: seedFormatOCIArchive}
+		require.NoError(t, opts.writeSeedArchive(mapping))
+
+		layoutDir := t.TempDir()
+		f, err := os.Open(filepath.Join(opts.OutputDir, seedArchiveFile))
+		require.NoError(t, err)
+		defer f.Close()
+		tr := tar.NewReader(f)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			target := filepath.Join(layoutDir, hdr.Name)
+			if hdr.Typeflag == tar.TypeDir {
+				require.NoError(t, os.MkdirAll(target, 0750))
+				continue
+			}
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(target, data, 0600))
+		}
+
+		index, err := layout.ImageIndexFromPath(layoutDir)
+		require.NoError(t, err)
+		im, err := index.IndexManifest()
+		require.NoError(t, err)
+		require.Len(t, im.Manifests, 2)
+		require.Equal(t, "quay.io/ns/multi:latest", im.Manifests[0].Annotations[ociRefNameAnnotation])
+		require.Equal(t, idxDigest, im.Manifests[0].Digest)
+		require.Equal(t, "quay.io/ns/single:v1", im.Manifests[1].Annotations[ociRefNameAnnotation])
+		require.Equal(t, singleDigest, im.Manifests[1].Digest)
+
+		// The manifest list is kept with both of its images.
+		multi, err := index.ImageIndex(idxDigest)
+		require.NoError(t, err)
+		armDigest, err := arm64.Digest()
+		require.NoError(t, err)
+		armImg, err := multi.Image(armDigest)
+		require.NoError(t, err)
+		require.NoError(t, ggcrvalidate.Image(armImg))
+	})
+	t.Run("Invalid/MissingBlob", func(t *testing.T) {
+		missing, err := random.Image(64, 1)
+		require.NoError(t, err)
+		data, err := missing.RawManifest()
+		require.NoError(t, err)
+		workspace := t.TempDir()
+		digest := writeWorkspaceManifest(t, filepath.Join(workspace, config.SourceDir, config.V2Dir), "ns/missing", data)
+		mapping := image.TypedImageMapping{
+			typed(imagesource.DestinationRegistry, reference.DockerImageReference{Registry: "quay.io", Namespace: "ns", Name: "missing", Tag: "v1"}): typed(imagesource.DestinationFile, reference.DockerImageReference{Namespace: "ns", Name: "missing", Tag: "v1", ID: digest.String()}),
+		}
+		require.NoError(t, os.Symlink(digest.String(), filepath.Join(workspace, config.SourceDir, config.V2Dir, "ns/missing/manifests/v1")))
+		opts := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: workspace}, OutputDir: t.TempDir(), SeedFormat: seedFormatOCIArchive}
+		require.ErrorContains(t, opts.writeSeedArchive(mapping), "not found in the workspace")
+		require.NoFileExists(t, filepath.Join(opts.OutputDir, seedArchiveFile))
+	})
+}
@@ -0,0 +1,71 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/operator-registry/alpha/action"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/operator"
+)
+
+// addPinnedBundles adds to dc the bundles of the dependency closure of the
+// selected bundles of ctlg pinned by the last run that dc does not have,
+// rendered from their bundle images by digest, so they stay mirrored even if
+// the catalog dropped them. The pins only apply while ctlg selects the same
+// bundles, the closure of a new selection is resolved again.
+func (o *OperatorOptions) addPinnedBundles(ctx context.Context, reg *containerdregistry.Registry, ctlg v1alpha2.Operator, lastRun v1alpha2.PastMirror, dc *declcfg.DeclarativeConfig) error {
+	uniqueName, err := ctlg.GetUniqueName()
+	if err != nil {
+		return err
+	}
+	var pins []v1alpha2.PinnedBundle
+	for _, pastCtlg := range lastRun.Operators {
+		if pastCtlg.Catalog == uniqueName {
+			pins = pastCtlg.PinnedBundles
+		}
+	}
+	if len(pins) == 0 {
+		return nil
+	}
+	if !operator.SameSelection(pins, ctlg.IncludeConfig) {
+		klog.Infof("catalog %s: selected bundles changed since the last run, resolving their dependencies again", ctlg.Catalog)
+		return nil
+	}
+
+	for _, pin := range pins {
+		if hasBundle(*dc, pin.Package, pin.Name) {
+			continue
+		}
+		rendered, err := action.Render{
+			Registry:       reg,
+			Refs:           []string{pin.Image},
+			AllowedRefMask: action.RefBundleImage,
+		}.Run(ctx)
+		if err != nil {
+			return fmt.Errorf("catalog %s: error rendering pinned bundle %s: %v", ctlg.Catalog, pin.Name, err)
+		}
+		if len(rendered.Bundles) != 1 || rendered.Bundles[0].Name != pin.Name || rendered.Bundles[0].Package != pin.Package {
+			return fmt.Errorf("catalog %s: image %s is not pinned bundle %s of package %s", ctlg.Catalog, pin.Image, pin.Name, pin.Package)
+		}
+		if _, err := operator.AddPinnedBundle(dc, pin, rendered.Bundles[0]); err != nil {
+			return fmt.Errorf("catalog %s: error adding pinned bundle %s: %v", ctlg.Catalog, pin.Name, err)
+		}
+		klog.Infof("catalog %s: mirroring pinned bundle %s of package %s from %s", ctlg.Catalog, pin.Name, pin.Package, pin.Image)
+	}
+	return nil
+}
+
+// hasBundle returns whether dc has the bundle name of package pkgName.
+func hasBundle(dc declcfg.DeclarativeConfig, pkgName, name string) bool {
+	for _, b := range dc.Bundles {
+		if b.Package == pkgName && b.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openshift/library-go/pkg/verify/store/configmap"
+	"github.com/openshift/library-go/pkg/verify/util"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// signatureConfigMapsFile is the file of the results directory holding the
+// ConfigMaps of the release signatures, ready to be applied to a cluster.
+const signatureConfigMapsFile = "releaseSignatureConfigMaps.yaml"
+
+// readSignatureConfigMaps returns the ConfigMaps of the release signatures
+// found in sigDir, sorted by name. They are set in the namespace and with
+// the label the cluster version operator reads the signatures from.
+// No ConfigMaps are returned when sigDir does not exist.
+func readSignatureConfigMaps(sigDir string) ([]*corev1.ConfigMap, error) {
+	entries, err := os.ReadDir(sigDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cms []*corev1.ConfigMap
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(sigDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cm, err := util.ReadConfigMap(data)
+		if err != nil {
+			return nil, fmt.Errorf("error reading release signatures %s: %v", entry.Name(), err)
+		}
+		if cm == nil || len(cm.BinaryData) == 0 {
+			return nil, fmt.Errorf("release signatures %s: no signatures found", entry.Name())
+		}
+		cm.TypeMeta = metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "ConfigMap"}
+		cm.Namespace = configmap.NamespaceLabelConfigMap
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		cm.Labels[configmap.ReleaseLabelConfigMap] = ""
+		cms = append(cms, cm)
+	}
+	sort.Slice(cms, func(i, j int) bool {
+		return cms[i].Name < cms[j].Name
+	})
+	return cms, nil
+}
+
+// writeSignatureConfigMaps writes the ConfigMaps to a single
+// multi-document file of dir, for oc apply -f.
+func writeSignatureConfigMaps(dir string, cms []*corev1.ConfigMap) error {
+	var data bytes.Buffer
+	for _, cm := range cms {
+		// Create an unstructured object for removing creationTimestamp
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+		if err != nil {
+			return fmt.Errorf("error converting to unstructured: %v", err)
+		}
+		delete(obj["metadata"].(map[string]interface{}), "creationTimestamp")
+		cmBytes, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("unable to marshal ConfigMap yaml: %v", err)
+		}
+		data.WriteString("---\n")
+		data.Write(cmBytes)
+	}
+	if err := os.WriteFile(filepath.Join(dir, signatureConfigMapsFile), data.Bytes(), 0640); err != nil {
+		return fmt.Errorf("error writing release signature ConfigMaps: %v", err)
+	}
+	return nil
+}
+
+// applySignatureConfigMaps creates the ConfigMaps in the cluster of client,
+// or replaces their data and labels when they already exist.
+func applySignatureConfigMaps(ctx context.Context, client kubernetes.Interface, cms []*corev1.ConfigMap) error {
+	var errs []error
+	for _, cm := range cms {
+		configMaps := client.CoreV1().ConfigMaps(cm.Namespace)
+		_, err := configMaps.Create(ctx, cm, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			var current *corev1.ConfigMap
+			current, err = configMaps.Get(ctx, cm.Name, metav1.GetOptions{})
+			if err == nil {
+				current = current.DeepCopy()
+				if current.Labels == nil {
+					current.Labels = map[string]string{}
+				}
+				for k, v := range cm.Labels {
+					current.Labels[k] = v
+				}
+				current.Data = cm.Data
+				current.BinaryData = cm.BinaryData
+				_, err = configMaps.Update(ctx, current, metav1.UpdateOptions{})
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error applying ConfigMap %s/%s: %v", cm.Namespace, cm.Name, err))
+			continue
+		}
+		klog.V(1).Infof("Applied release signature ConfigMap %s/%s", cm.Namespace, cm.Name)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// publishSignatureConfigMaps writes the ConfigMaps of the release signatures
+// of sigDir to dir and, with --apply-signatures-kubeconfig, applies them to
+// the cluster of that kubeconfig.
+func (o *MirrorOptions) publishSignatureConfigMaps(ctx context.Context, sigDir, dir string) error {
+	cms, err := readSignatureConfigMaps(sigDir)
+	if err != nil {
+		return err
+	}
+	if len(cms) == 0 {
+		klog.V(2).Info("No release signatures to write as ConfigMaps")
+		return nil
+	}
+	klog.Infof("Writing release signature ConfigMaps to %s", dir)
+	if err := writeSignatureConfigMaps(dir, cms); err != nil {
+		return err
+	}
+	if o.ApplySignaturesKubeconfig == "" {
+		return nil
+	}
+
+	restConfig, err := clientcmd.BuildConfigFromFlags("", o.ApplySignaturesKubeconfig)
+	if err != nil {
+		return fmt.Errorf("error loading kubeconfig %s: %v", o.ApplySignaturesKubeconfig, err)
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("error creating cluster client: %v", err)
+	}
+	if err := applySignatureConfigMaps(ctx, client, cms); err != nil {
+		return err
+	}
+	klog.Infof("Applied %d release signature ConfigMaps to %s", len(cms), restConfig.Host)
+	return nil
+}
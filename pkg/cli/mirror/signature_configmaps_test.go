@@ -0,0 +1,122 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/library-go/pkg/verify"
+	"github.com/openshift/library-go/pkg/verify/util"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	testSignatureDigest1 = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	testSignatureDigest2 = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// writeTestSignatures writes the signature configmap of digest to sigDir
+// as generateReleaseSignatures does.
+func writeTestSignatures(t *testing.T, sigDir, digest string, signatures ...string) {
+	t.Helper()
+	var sigs [][]byte
+	for _, sig := range signatures {
+		sigs = append(sigs, []byte(sig))
+	}
+	cm, err := verify.GetSignaturesAsConfigmap(digest, sigs)
+	require.NoError(t, err)
+	data, err := util.ConfigMapAsBytes(cm)
+	require.NoError(t, err)
+	fileName, err := createSignatureFileName(digest)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(sigDir, fileName), data, 0600))
+}
+
+func TestReadSignatureConfigMaps(t *testing.T) {
+	t.Run("Valid/Signatures", func(t *testing.T) {
+		sigDir := t.TempDir()
+		writeTestSignatures(t, sigDir, testSignatureDigest2, "sig-2")
+		writeTestSignatures(t, sigDir, testSignatureDigest1, "sig-1a", "sig-1b")
+
+		cms, err := readSignatureConfigMaps(sigDir)
+		require.NoError(t, err)
+		require.Len(t, cms, 2)
+		require.Equal(t, "sha256-1111111111111111111111111111111111111111111111111111111111111111", cms[0].Name)
+		require.Equal(t, "openshift-config-managed", cms[0].Namespace)
+		require.Equal(t, map[string]string{"release.openshift.io/verification-signatures": ""}, cms[0].Labels)
+		require.Equal(t, []byte("sig-1b"), cms[0].BinaryData[cms[0].Name+"-2"])
+		require.Equal(t, "ConfigMap", cms[0].Kind)
+		require.Equal(t, "sha256-2222222222222222222222222222222222222222222222222222222222222222", cms[1].Name)
+	})
+	t.Run("Valid/NoSignatureDir", func(t *testing.T) {
+		cms, err := readSignatureConfigMaps(filepath.Join(t.TempDir(), "release-signatures"))
+		require.NoError(t, err)
+		require.Empty(t, cms)
+	})
+	t.Run("Invalid/NotAConfigMap", func(t *testing.T) {
+		sigDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(sigDir, "signature-sha256-1111111111111111.json"), []byte("{}"), 0600))
+		_, err := readSignatureConfigMaps(sigDir)
+		require.Error(t, err)
+	})
+}
+
+func TestWriteSignatureConfigMaps(t *testing.T) {
+	sigDir := t.TempDir()
+	writeTestSignatures(t, sigDir, testSignatureDigest1, "sig-1")
+	writeTestSignatures(t, sigDir, testSignatureDigest2, "sig-2")
+	cms, err := readSignatureConfigMaps(sigDir)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, writeSignatureConfigMaps(dir, cms))
+	data, err := os.ReadFile(filepath.Join(dir, signatureConfigMapsFile))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "creationTimestamp")
+
+	docs := bytes.Split(bytes.TrimPrefix(data, []byte("---\n")), []byte("---\n"))
+	require.Len(t, docs, 2)
+	var cm corev1.ConfigMap
+	require.NoError(t, yaml.Unmarshal(docs[1], &cm))
+	require.Equal(t, "v1", cm.APIVersion)
+	require.Equal(t, "ConfigMap", cm.Kind)
+	require.Equal(t, "openshift-config-managed", cm.Namespace)
+	require.Equal(t, []byte("sig-2"), cm.BinaryData[cm.Name+"-1"])
+}
+
+func TestApplySignatureConfigMaps(t *testing.T) {
+	sigDir := t.TempDir()
+	writeTestSignatures(t, sigDir, testSignatureDigest1, "sig-1a", "sig-1b")
+	writeTestSignatures(t, sigDir, testSignatureDigest2, "sig-2")
+	cms, err := readSignatureConfigMaps(sigDir)
+	require.NoError(t, err)
+
+	// The configmap of the first release exists with an outdated signature.
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "openshift-config-managed",
+			Name:      cms[0].Name,
+			Labels:    map[string]string{"team": "platform"},
+		},
+		BinaryData: map[string][]byte{cms[0].Name + "-1": []byte("old")},
+	}
+	client := fake.NewSimpleClientset(existing)
+	ctx := context.Background()
+	require.NoError(t, applySignatureConfigMaps(ctx, client, cms))
+
+	for _, want := range cms {
+		got, err := client.CoreV1().ConfigMaps("openshift-config-managed").Get(ctx, want.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, want.BinaryData, got.BinaryData)
+		require.Contains(t, got.Labels, "release.openshift.io/verification-signatures")
+	}
+	updated, err := client.CoreV1().ConfigMaps("openshift-config-managed").Get(ctx, cms[0].Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "platform", updated.Labels["team"])
+}
@@ -0,0 +1,97 @@
+package mirror
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// orderByMirrorHits orders images so the ones that reused the most layers
+// from the mirror registry during the previous publish are processed first.
+// Those images only need their new layers pushed, so they become available
+// early in the run, while large transfers are left for the end.
+// Images without history keep their original order.
+func orderByMirrorHits(images []string, prev *v1alpha2.MirrorStats) []string {
+	if prev == nil || len(prev.ReusedByImage) == 0 {
+		return images
+	}
+	ordered := make([]string, len(images))
+	copy(ordered, images)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return prev.ReusedByImage[ordered[i]] > prev.ReusedByImage[ordered[j]]
+	})
+	return ordered
+}
+
+// logMirrorStats reports how many layers did not have to be transferred
+// because they were already present in the mirror registry.
+func logMirrorStats(stats *v1alpha2.MirrorStats) {
+	if stats.ImagesSkipped != 0 {
+		klog.Infof("%d images were already published to the mirror registry and were skipped", stats.ImagesSkipped)
+	}
+	total := stats.LayersPublished + stats.LayersReused
+	if total == 0 {
+		return
+	}
+	klog.Infof("%d of %d layers (%.1f%%) were already present in the mirror registry and were not transferred",
+		stats.LayersReused, total, stats.ReuseRatio()*100)
+}
+
+// repositoryUsage is the size of the layers pushed by a publish to each
+// repository of the destination registry. Registry quotas are per
+// namespace, so a layer pushed to several repositories counts in each.
+type repositoryUsage struct {
+	sizes  map[string]int64
+	layers sets.Set[string]
+}
+
+func newRepositoryUsage() *repositoryUsage {
+	return &repositoryUsage{sizes: map[string]int64{}, layers: sets.New[string]()}
+}
+
+// Record adds the size of the layer digest pushed to repo,
+// unless it was already recorded for repo.
+func (u *repositoryUsage) Record(repo, digest string, size int64) {
+	key := repo + "@" + digest
+	if u.layers.Has(key) {
+		return
+	}
+	u.layers.Insert(key)
+	u.sizes[repo] += size
+}
+
+// Lines returns the usage of each repository, largest first.
+func (u *repositoryUsage) Lines() []string {
+	repos := make([]string, 0, len(u.sizes))
+	for repo := range u.sizes {
+		repos = append(repos, repo)
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if u.sizes[repos[i]] != u.sizes[repos[j]] {
+			return u.sizes[repos[i]] > u.sizes[repos[j]]
+		}
+		return repos[i] < repos[j]
+	})
+	lines := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		lines = append(lines, fmt.Sprintf("%s: %s", repo, formatMiB(u.sizes[repo])))
+	}
+	return lines
+}
+
+// logRepositoryUsage reports the storage consumed in each
+// repository of the destination registry by the layers pushed.
+func logRepositoryUsage(usage *repositoryUsage) {
+	lines := usage.Lines()
+	if len(lines) == 0 {
+		return
+	}
+	klog.Info("Storage consumed by the layers pushed to each repository:")
+	for _, line := range lines {
+		klog.Infof("  %s", line)
+	}
+}
@@ -0,0 +1,68 @@
+package mirror
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestOrderByMirrorHits(t *testing.T) {
+	images := []string{"a", "b", "c", "d"}
+
+	tests := []struct {
+		name     string
+		prev     *v1alpha2.MirrorStats
+		expected []string
+	}{{
+		name:     "Valid/NoHistory",
+		expected: []string{"a", "b", "c", "d"},
+	}, {
+		name: "Valid/WithHistory",
+		prev: &v1alpha2.MirrorStats{
+			ReusedByImage: map[string]int{"c": 5, "b": 1},
+		},
+		expected: []string{"c", "b", "a", "d"},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, orderByMirrorHits(images, test.prev))
+			// input must not be reordered
+			require.Equal(t, []string{"a", "b", "c", "d"}, images)
+		})
+	}
+}
+
+func TestMirrorStatsRecord(t *testing.T) {
+	stats := &v1alpha2.MirrorStats{}
+	stats.Record("a", true)
+	stats.Record("a", true)
+	stats.Record("a", false)
+	stats.Record("b", false)
+	require.Equal(t, 2, stats.LayersPublished)
+	require.Equal(t, 2, stats.LayersReused)
+	require.Equal(t, map[string]int{"a": 2}, stats.ReusedByImage)
+	require.Equal(t, 0.5, stats.ReuseRatio())
+
+	stats.RecordSkipped("c", 2)
+	require.Equal(t, 1, stats.ImagesSkipped)
+	require.Equal(t, 4, stats.LayersReused)
+	require.Equal(t, map[string]int{"a": 2, "c": 2}, stats.ReusedByImage)
+}
+
+func TestRepositoryUsage(t *testing.T) {
+	usage := newRepositoryUsage()
+	require.Empty(t, usage.Lines())
+
+	usage.Record("registry/mirror/a", "sha256:1", 1<<20)
+	usage.Record("registry/mirror/a", "sha256:1", 1<<20)
+	usage.Record("registry/mirror/b", "sha256:1", 1<<20)
+	usage.Record("registry/mirror/b", "sha256:2", 3<<20)
+	usage.Record("registry/mirror/c", "sha256:3", 1<<20)
+	require.Equal(t, []string{
+		"registry/mirror/b: 4.0 MiB",
+		"registry/mirror/a: 1.0 MiB",
+		"registry/mirror/c: 1.0 MiB",
+	}, usage.Lines())
+}
@@ -0,0 +1,134 @@
+package mirror
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// validTag matches the tags accepted by registries.
+var validTag = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// tagTemplateData holds the fields of the source image
+// available to the templates of --tag-template.
+type tagTemplateData struct {
+	// Component is the last path component of the source repository.
+	Component string
+	// Repository is the path of the source repository, without registry.
+	Repository string
+	// Version is the tag of the source image, empty for images referenced by digest.
+	Version string
+	// Tag is the destination tag oc-mirror uses without template.
+	Tag string
+	// Digest and DigestShort are the digest of the source image and its
+	// first 12 hexadecimal characters, empty if the digest is not known.
+	Digest      string
+	DigestShort string
+	// Type is the image type, such as operatorBundle.
+	Type string
+}
+
+// parseTagTemplates parses the --tag-template values, in the form type=template.
+func parseTagTemplates(values []string) (map[v1alpha2.ImageType]*template.Template, error) {
+	templates := make(map[v1alpha2.ImageType]*template.Template, len(values))
+	for _, value := range values {
+		typeName, text, found := strings.Cut(value, "=")
+		if !found || strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("invalid tag template %q, expected type=template", value)
+		}
+		typ, err := v1alpha2.ParseImageType(strings.TrimSpace(typeName))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag template %q: %v", value, err)
+		}
+		if typ == v1alpha2.TypeCincinnatiGraph {
+			return nil, fmt.Errorf("invalid tag template %q: the tag of the graph data image built by oc-mirror cannot be templated", value)
+		}
+		if _, found := templates[typ]; found {
+			return nil, fmt.Errorf("tag template of image type %s is specified more than once", typ)
+		}
+		tmpl, err := template.New(typ.String()).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag template %q: %v", value, err)
+		}
+		templates[typ] = tmpl
+	}
+	return templates, nil
+}
+
+// destinationTag returns the destination tag of the source image src
+// of type typ, rendered from the --tag-template of typ, or tag if
+// the type has no template.
+func (o *MirrorOptions) destinationTag(typ v1alpha2.ImageType, src reference.DockerImageReference, tag string) (string, error) {
+	tmpl, found := o.tagTemplates[typ]
+	if !found {
+		return tag, nil
+	}
+	data := tagTemplateData{
+		Component:  src.Name,
+		Repository: path.Join(src.Namespace, src.Name),
+		Version:    src.Tag,
+		Tag:        tag,
+		Digest:     src.ID,
+		Type:       typ.String(),
+	}
+	if _, hex, found := strings.Cut(src.ID, ":"); found {
+		data.DigestShort = hex
+		if len(hex) > 12 {
+			data.DigestShort = hex[:12]
+		}
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("error rendering the tag template of image %s: %v", src.Exact(), err)
+	}
+	rendered := b.String()
+	if !validTag.MatchString(rendered) {
+		return "", fmt.Errorf("tag template of type %s renders invalid tag %q for image %s", typ, rendered, src.Exact())
+	}
+	return rendered, nil
+}
+
+// applyTagTemplates replaces the destination tags of mapping
+// with the ones rendered from the --tag-template values.
+// Two images rendered to the same destination tag are an error.
+func (o *MirrorOptions) applyTagTemplates(mapping image.TypedImageMapping) error {
+	if len(o.tagTemplates) == 0 {
+		return nil
+	}
+	rendered := map[string][]string{}
+	for src, dest := range mapping {
+		if _, found := o.tagTemplates[src.Category]; !found {
+			continue
+		}
+		tag, err := o.destinationTag(src.Category, src.Ref, dest.Ref.Tag)
+		if err != nil {
+			return err
+		}
+		dest.Ref.Tag = tag
+		mapping[src] = dest
+		tagged := dest.Ref
+		tagged.ID = ""
+		rendered[tagged.Exact()] = append(rendered[tagged.Exact()], src.Ref.Exact())
+	}
+
+	var collisions []string
+	for dest, srcs := range rendered {
+		if len(srcs) > 1 {
+			sort.Strings(srcs)
+			collisions = append(collisions, fmt.Sprintf("%s: %s", dest, strings.Join(srcs, ", ")))
+		}
+	}
+	if len(collisions) != 0 {
+		sort.Strings(collisions)
+		return fmt.Errorf("tag templates render the same destination tag for several images:\n%s", strings.Join(collisions, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package mirror
+
+import (
+	"testing"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestParseTagTemplates(t *testing.T) {
+	type spec struct {
+		name     string
+		values   []string
+		expError string
+	}
+	cases := []spec{
+		{
+			name:   "Valid/SeveralTypes",
+			values: []string{"operatorBundle={{ .Component }}-{{ .DigestShort }}", "generic=mirror-{{ .Version }}"},
+		},
+		{
+			name:     "Invalid/NoTemplate",
+			values:   []string{"generic"},
+			expError: `invalid tag template "generic", expected type=template`,
+		},
+		{
+			name:     "Invalid/UnknownType",
+			values:   []string{"helm={{ .Version }}"},
+			expError: `invalid tag template "helm={{ .Version }}": unknown image type "helm"`,
+		},
+		{
+			name:     "Invalid/GraphImage",
+			values:   []string{"cincinnatiGraph={{ .Version }}"},
+			expError: "the tag of the graph data image built by oc-mirror cannot be templated",
+		},
+		{
+			name:     "Invalid/Duplicate",
+			values:   []string{"generic={{ .Version }}", "generic={{ .Tag }}"},
+			expError: "tag template of image type generic is specified more than once",
+		},
+		{
+			name:     "Invalid/Syntax",
+			values:   []string{"generic={{ .Version "},
+			expError: `invalid tag template "generic={{ .Version "`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			templates, err := parseTagTemplates(c.values)
+			if c.expError != "" {
+				require.ErrorContains(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, templates, len(c.values))
+		})
+	}
+}
+
+func TestApplyTagTemplates(t *testing.T) {
+	templates, err := parseTagTemplates([]string{
+		"operatorBundle={{ .Component }}-{{ .DigestShort }}",
+		"generic={{ or .Version .DigestShort }}-mirrored",
+	})
+	require.NoError(t, err)
+	o := &MirrorOptions{tagTemplates: templates}
+
+	parse := func(ref string, typ v1alpha2.ImageType) image.TypedImage {
+		img, err := image.ParseTypedImage(ref, typ)
+		require.NoError(t, err)
+		return img
+	}
+	bundle := parse("registry.example.com/ns/etcd-bundle@sha256:1111111111111111111111111111111111111111111111111111111111111111", v1alpha2.TypeOperatorBundle)
+	app := parse("registry.example.com/ns/app:v1.2.3", v1alpha2.TypeGeneric)
+	related := parse("registry.example.com/ns/related@sha256:2222222222222222222222222222222222222222222222222222222222222222", v1alpha2.TypeOperatorRelatedImage)
+	mapping := image.TypedImageMapping{bundle: bundle, app: app, related: related}
+	mapping.ToRegistry("localhost:5000", "mirror")
+
+	require.NoError(t, o.applyTagTemplates(mapping))
+	require.Equal(t, "etcd-bundle-111111111111", mapping[bundle].Ref.Tag)
+	require.Equal(t, "v1.2.3-mirrored", mapping[app].Ref.Tag)
+	// Types without template keep the default tag.
+	require.Equal(t, "222222", mapping[related].Ref.Tag)
+
+	t.Run("Invalid/Collision", func(t *testing.T) {
+		other := parse("registry.example.com/ns/app:v2", v1alpha2.TypeGeneric)
+		mapping := image.TypedImageMapping{app: app, other: other}
+		mapping.ToRegistry("localhost:5000", "mirror")
+		templates, err := parseTagTemplates([]string{"generic=latest"})
+		require.NoError(t, err)
+		o := &MirrorOptions{tagTemplates: templates}
+		require.ErrorContains(t, o.applyTagTemplates(mapping), "tag templates render the same destination tag for several images:\n"+
+			"localhost:5000/mirror/ns/app:latest: registry.example.com/ns/app:v1.2.3, registry.example.com/ns/app:v2")
+	})
+
+	t.Run("Invalid/Tag", func(t *testing.T) {
+		src := reference.DockerImageReference{Registry: "registry.example.com", Namespace: "ns", Name: "app"}
+		_, err := o.destinationTag(v1alpha2.TypeGeneric, src, "latest")
+		require.ErrorContains(t, err, `tag template of type generic renders invalid tag "-mirrored" for image registry.example.com/ns/app`)
+	})
+}
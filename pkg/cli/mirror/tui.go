@@ -0,0 +1,333 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+// tuiLogFile receives the logs of the run while the terminal
+// UI is shown, like the logs of every run.
+const tuiLogFile = ".oc-mirror.log"
+
+// Keys read by the terminal UI.
+const (
+	keyPause = 'p'
+	keyAbort = 'q'
+)
+
+// runMonitor shows the progress of a run in the terminal with --tui: a
+// progress bar per image category, the images being mirrored, the transfer
+// rate and the errors of the run. Pressing p pauses the run between two
+// batches of images and q aborts it like an interrupt. The methods of
+// a nil runMonitor do nothing.
+type runMonitor struct {
+	progress *mpb.Progress
+	status   []*mpb.Bar
+	abort    context.CancelFunc
+	restore  func()
+	done     chan struct{}
+	keys     sync.WaitGroup
+
+	// barsMu guards the bars and the images planned. The bars are
+	// updated under barsMu only, as their rendering locks mu.
+	barsMu  sync.Mutex
+	bars    map[v1alpha2.ImageType]*mpb.Bar
+	totals  map[v1alpha2.ImageType]int64
+	planned map[string]v1alpha2.ImageType
+
+	mu        sync.Mutex
+	current   string
+	errors    int
+	lastError string
+	paused    bool
+	resume    chan struct{}
+	rateBytes int64
+	rateTime  time.Time
+	rate      int64
+}
+
+// startMonitor shows the terminal UI of the run until stop is called. The
+// logs of the run and the output of the image mirroring are written to
+// tuiLogFile instead of the terminal, abort is called when q is pressed.
+func (o *MirrorOptions) startMonitor(abort context.CancelFunc) (*runMonitor, error) {
+	out, ok := o.IOStreams.Out.(*os.File)
+	if !ok || !isTerminal(out) || !isTerminal(os.Stdin) {
+		return nil, errors.New("--tui requires a terminal")
+	}
+	logFile, err := os.OpenFile(tuiLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening the log file of the terminal UI: %v", err)
+	}
+	restoreTerm, err := cbreakMode(os.Stdin)
+	if err != nil {
+		logFile.Close()
+		return nil, fmt.Errorf("error configuring the terminal: %v", err)
+	}
+
+	stdout, stderr := o.IOStreams.Out, o.IOStreams.ErrOut
+	klog.SetOutput(logFile)
+	o.IOStreams.Out, o.IOStreams.ErrOut = logFile, logFile
+
+	m := &runMonitor{
+		progress: mpb.New(mpb.WithOutput(out), mpb.WithWidth(40), mpb.WithRefreshRate(200*time.Millisecond)),
+		abort:    abort,
+		done:     make(chan struct{}),
+		bars:     map[v1alpha2.ImageType]*mpb.Bar{},
+		totals:   map[v1alpha2.ImageType]int64{},
+		planned:  map[string]v1alpha2.ImageType{},
+		rateTime: time.Now(),
+	}
+	m.restore = func() {
+		restoreTerm()
+		o.IOStreams.Out, o.IOStreams.ErrOut = stdout, stderr
+		klog.SetOutput(io.MultiWriter(stdout, logFile))
+	}
+	// The status lines stay below the bars of the categories.
+	for i, line := range []func() string{m.stateLine, m.currentLine, m.errorLine} {
+		line := line
+		m.status = append(m.status, m.progress.New(0, mpb.NopStyle(),
+			mpb.BarPriority(math.MaxInt32-2+i),
+			mpb.PrependDecorators(decor.Any(func(decor.Statistics) string { return line() })),
+		))
+	}
+	m.keys.Add(1)
+	go m.readKeys(os.Stdin)
+	return m, nil
+}
+
+// readKeys handles the keys pressed until the monitor stops.
+func (m *runMonitor) readKeys(r io.Reader) {
+	defer m.keys.Done()
+	buf := make([]byte, 1)
+	for {
+		select {
+		case <-m.done:
+			return
+		default:
+		}
+		n, err := r.Read(buf)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return
+		}
+		if n == 0 {
+			continue
+		}
+		switch buf[0] {
+		case keyPause:
+			m.togglePause()
+		case keyAbort:
+			klog.Warningf("Abort requested from the terminal UI, stopping the run at the next image")
+			m.mu.Lock()
+			m.paused = false
+			if m.resume != nil {
+				close(m.resume)
+				m.resume = nil
+			}
+			m.mu.Unlock()
+			m.abort()
+		}
+	}
+}
+
+// togglePause pauses the run, or resumes it if it is paused.
+func (m *runMonitor) togglePause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = !m.paused
+	if m.paused {
+		klog.Infof("Run paused from the terminal UI")
+		m.resume = make(chan struct{})
+		return
+	}
+	klog.Infof("Run resumed from the terminal UI")
+	close(m.resume)
+	m.resume = nil
+}
+
+// waitIfPaused blocks while the run is paused, until
+// it is resumed or ctx is cancelled.
+func (m *runMonitor) waitIfPaused(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	resume := m.resume
+	m.mu.Unlock()
+	if resume == nil {
+		return
+	}
+	select {
+	case <-resume:
+	case <-ctx.Done():
+	}
+}
+
+// plan adds the image name of type typ to the images planned by the run.
+func (m *runMonitor) plan(name string, typ v1alpha2.ImageType) {
+	if m == nil {
+		return
+	}
+	m.barsMu.Lock()
+	defer m.barsMu.Unlock()
+	m.planned[name] = typ
+	m.totals[typ]++
+	bar, ok := m.bars[typ]
+	if !ok {
+		label := typ.String()
+		if label == "" {
+			label = "images"
+		}
+		// Bars are completed by stop, the totals grow with each destination.
+		bar = m.progress.AddBar(0,
+			mpb.PrependDecorators(decor.Name(label, decor.WC{W: 26, C: decor.DindentRight})),
+			mpb.AppendDecorators(decor.CountersNoUnit("%d / %d", decor.WCSyncSpace)),
+		)
+		m.bars[typ] = bar
+	}
+	bar.SetTotal(m.totals[typ], false)
+}
+
+// planAssociations adds the images of assocs to the images planned by the run.
+func (m *runMonitor) planAssociations(assocs image.AssociationIterator) error {
+	if m == nil {
+		return nil
+	}
+	return assocs.ForEach(func(imageName string, values image.Associations) error {
+		var typ v1alpha2.ImageType
+		if value, ok := values[imageName]; ok {
+			typ = value.Type
+		}
+		m.plan(imageName, typ)
+		return nil
+	})
+}
+
+// start shows names as the images being mirrored.
+func (m *runMonitor) start(names ...string) {
+	if m == nil || len(names) == 0 {
+		return
+	}
+	current := names[0]
+	if len(names) > 1 {
+		current = fmt.Sprintf("%s (+%d)", names[0], len(names)-1)
+	}
+	m.mu.Lock()
+	m.current = current
+	m.mu.Unlock()
+}
+
+// finish counts the images names as mirrored.
+func (m *runMonitor) finish(names ...string) {
+	if m == nil {
+		return
+	}
+	m.barsMu.Lock()
+	for _, name := range names {
+		typ, ok := m.planned[name]
+		if !ok {
+			continue
+		}
+		delete(m.planned, name)
+		m.bars[typ].Increment()
+	}
+	m.barsMu.Unlock()
+
+	m.mu.Lock()
+	m.current = ""
+	m.mu.Unlock()
+}
+
+// addError shows err in the error ticker.
+func (m *runMonitor) addError(err error) {
+	if m == nil || err == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors++
+	m.lastError = strings.Join(strings.Fields(err.Error()), " ")
+}
+
+// stateLine renders the state of the run, the transfer rate
+// sampled every second, and the keys of the terminal UI.
+func (m *runMonitor) stateLine() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	received, sent := network.TransferredBytes()
+	if elapsed := time.Since(m.rateTime); elapsed >= time.Second {
+		m.rate = int64(float64(received+sent-m.rateBytes) / elapsed.Seconds())
+		m.rateBytes, m.rateTime = received+sent, time.Now()
+	}
+	state := "running"
+	if m.paused {
+		state = "PAUSED"
+	}
+	return fmt.Sprintf("[%s] % .1f/s   p: pause/resume   q: abort", state, decor.SizeB1024(m.rate))
+}
+
+// currentLine renders the images being mirrored.
+func (m *runMonitor) currentLine() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current == "" {
+		return ""
+	}
+	return "mirroring " + truncate(m.current, 120)
+}
+
+// errorLine renders the error ticker: the number of
+// errors of the run and the last one.
+func (m *runMonitor) errorLine() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.errors == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d errors, last: %s", m.errors, truncate(m.lastError, 120))
+}
+
+// truncate returns s cut to n runes.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+// stop removes the terminal UI, restores the terminal and the logs,
+// and prints the outcome of the run err.
+func (m *runMonitor) stop(err error) {
+	if m == nil {
+		return
+	}
+	close(m.done)
+	m.keys.Wait()
+	m.barsMu.Lock()
+	for _, bar := range m.bars {
+		bar.Abort(false)
+	}
+	m.barsMu.Unlock()
+	for _, bar := range m.status {
+		bar.Abort(false)
+	}
+	m.progress.Wait()
+	m.restore()
+	if err != nil {
+		klog.Errorf("Run failed, see %s for its logs", tuiLogFile)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin
+
+package mirror
+
+import (
+	"errors"
+	"os"
+)
+
+// isTerminal returns false, the terminal UI is only
+// supported on Linux and macOS.
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+// cbreakMode fails, the terminal UI is only
+// supported on Linux and macOS.
+func cbreakMode(f *os.File) (func(), error) {
+	return nil, errors.New("the terminal UI is only supported on Linux and macOS")
+}
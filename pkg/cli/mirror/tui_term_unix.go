@@ -0,0 +1,40 @@
+//go:build linux || darwin
+
+package mirror
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+	"k8s.io/klog/v2"
+)
+
+// isTerminal returns whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), ioctlReadTermios)
+	return err == nil
+}
+
+// cbreakMode disables the line buffering and the echo of the terminal f so
+// that keys are read as they are pressed, keeping the output processing and
+// the signals of the terminal. Reads return after 100ms without input. The
+// returned function restores the terminal.
+func cbreakMode(f *os.File) (func(), error) {
+	fd := int(f.Fd())
+	saved, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+	termios := *saved
+	termios.Lflag &^= unix.ICANON | unix.ECHO
+	termios.Cc[unix.VMIN] = 0
+	termios.Cc[unix.VTIME] = 1
+	if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, &termios); err != nil {
+		return nil, err
+	}
+	return func() {
+		if err := unix.IoctlSetTermios(fd, ioctlWriteTermios, saved); err != nil {
+			klog.Warningf("unable to restore the terminal: %v", err)
+		}
+	}, nil
+}
@@ -0,0 +1,87 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vbauerster/mpb/v8"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func newTestMonitor(abort context.CancelFunc) *runMonitor {
+	return &runMonitor{
+		progress: mpb.New(mpb.WithOutput(io.Discard)),
+		abort:    abort,
+		restore:  func() {},
+		done:     make(chan struct{}),
+		bars:     map[v1alpha2.ImageType]*mpb.Bar{},
+		totals:   map[v1alpha2.ImageType]int64{},
+		planned:  map[string]v1alpha2.ImageType{},
+		rateTime: time.Now(),
+	}
+}
+
+func TestRunMonitor(t *testing.T) {
+	t.Run("Valid/Nil", func(t *testing.T) {
+		var m *runMonitor
+		m.plan("quay.io/ns/app:v1", v1alpha2.TypeGeneric)
+		m.start("quay.io/ns/app:v1")
+		m.finish("quay.io/ns/app:v1")
+		m.addError(errors.New("error"))
+		m.waitIfPaused(context.Background())
+		m.stop(nil)
+	})
+
+	t.Run("Valid/Progress", func(t *testing.T) {
+		m := newTestMonitor(func() {})
+		m.plan("quay.io/ns/app:v1", v1alpha2.TypeGeneric)
+		m.plan("quay.io/ns/app:v2", v1alpha2.TypeGeneric)
+		m.plan("quay.io/ns/bundle:v1", v1alpha2.TypeOperatorBundle)
+		m.start("quay.io/ns/app:v1", "quay.io/ns/app:v2")
+		require.Equal(t, "mirroring quay.io/ns/app:v1 (+1)", m.currentLine())
+		m.finish("quay.io/ns/app:v1", "quay.io/ns/app:v2")
+		require.Equal(t, "", m.currentLine())
+		require.Equal(t, int64(2), m.bars[v1alpha2.TypeGeneric].Current())
+		require.Equal(t, int64(0), m.bars[v1alpha2.TypeOperatorBundle].Current())
+		require.Equal(t, int64(1), m.totals[v1alpha2.TypeOperatorBundle])
+
+		require.Equal(t, "", m.errorLine())
+		m.addError(errors.New("error copying\nlayer"))
+		m.addError(errors.New("manifest unknown"))
+		require.Equal(t, "2 errors, last: manifest unknown", m.errorLine())
+		m.stop(nil)
+	})
+
+	t.Run("Valid/PauseAndAbort", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		m := newTestMonitor(cancel)
+
+		m.togglePause()
+		require.Contains(t, m.stateLine(), "[PAUSED]")
+		waited := make(chan struct{})
+		go func() {
+			m.waitIfPaused(ctx)
+			close(waited)
+		}()
+		select {
+		case <-waited:
+			t.Fatal("paused run did not wait")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		// q resumes the paused run and aborts it.
+		m.keys.Add(1)
+		go m.readKeys(strings.NewReader(string(keyAbort)))
+		<-waited
+		require.Error(t, ctx.Err())
+		require.Contains(t, m.stateLine(), "[running]")
+		m.stop(nil)
+	})
+}
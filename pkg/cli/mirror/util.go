@@ -2,8 +2,6 @@ package mirror
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"net"
 	"net/http"
@@ -13,19 +11,21 @@ import (
 	"runtime"
 	"time"
 
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/network"
 )
 
 const mappingFile = "mapping.txt"
 
 func getRemoteOpts(ctx context.Context, insecure bool) []remote.Option {
 	return []remote.Option{
-		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithAuthFromKeychain(image.Keychain()),
 		remote.WithTransport(createRT(insecure)),
 		remote.WithContext(ctx),
 	}
@@ -37,7 +37,7 @@ func getCraneOpts(ctx context.Context, insecure bool) []crane.Option {
 		OS:           runtime.GOOS,
 	}
 	opts := []crane.Option{
-		crane.WithAuthFromKeychain(authn.DefaultKeychain),
+		crane.WithAuthFromKeychain(image.Keychain()),
 		crane.WithTransport(createRT(insecure)),
 		crane.WithContext(ctx),
 		crane.WithPlatform(&currentPlatform),
@@ -55,9 +55,33 @@ func getNameOpts(insecure bool) (options []name.Option) {
 	return options
 }
 
+// skipTLSVerifyFor returns whether the registry of the image ref is
+// set as insecure by the per-registry TLS verification flags.
+func skipTLSVerifyFor(ref string) bool {
+	parsed, err := image.ParseReference(ref)
+	if err != nil || parsed.Ref.Registry == "" {
+		return false
+	}
+	return network.IsInsecureRegistry(parsed.Ref.Registry)
+}
+
+// plainHTTPFor returns whether the registry of the image ref is
+// set as plain HTTP by the registry overrides.
+func plainHTTPFor(ref string) bool {
+	parsed, err := image.ParseReference(ref)
+	if err != nil || parsed.Ref.Registry == "" {
+		return false
+	}
+	return network.IsPlainHTTPRegistry(parsed.Ref.Registry)
+}
+
 func createRT(insecure bool) http.RoundTripper {
+	return network.AuditManifests(network.ChunkUploads(network.CountTransfers(network.RouteRegistries(newTransport(insecure)))))
+}
+
+func newTransport(insecure bool) *http.Transport {
 	return &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
+		Proxy: network.Proxy,
 		DialContext: (&net.Dialer{
 			// By default, we wrap the transport in retries, so reduce the
 			// default dial timeout to 5s to avoid 5x 30s of connection
@@ -70,10 +94,7 @@ func createRT(insecure bool) http.RoundTripper {
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: insecure,
-			MinVersion:         tls.VersionTLS12,
-		},
+		TLSClientConfig:       network.TLSConfig(insecure),
 	}
 }
 
@@ -93,18 +114,6 @@ func (o *MirrorOptions) newMetadataImage(uid string) string {
 	return fmt.Sprintf("%s:%s", repo, uid)
 }
 
-func getTLSConfig() (*tls.Config, error) {
-	certPool, err := x509.SystemCertPool()
-	if err != nil {
-		return nil, err
-	}
-	config := &tls.Config{
-		RootCAs:    certPool,
-		MinVersion: tls.VersionTLS12,
-	}
-	return config, nil
-}
-
 func (o *MirrorOptions) checkErr(err error, acceptableErr func(error) bool, logMessage func(error) string) error {
 
 	if err == nil {
@@ -127,6 +136,8 @@ func (o *MirrorOptions) checkErr(err error, acceptableErr func(error) bool, logM
 	if o.ContinueOnError && (skip || skipAllTypes) {
 		klog.Errorf("error: %v", message)
 		o.continuedOnError = true
+		o.metrics.addError(err)
+		o.monitor.addError(err)
 	} else {
 		return fmt.Errorf("%v", message)
 	}
@@ -0,0 +1,78 @@
+package mirror
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+type ValidateConfigOptions struct {
+	*cli.RootOptions
+	ConfigPath string   // Path to the imageset configuration to validate
+	Profile    string   // Name of a profile merged onto the base configuration before validation
+	Overlays   []string // Paths to imageset configurations merged onto the base configuration before the profile
+}
+
+// NewValidateConfigCommand returns the validate-config command, which
+// checks an imageset configuration without mirroring anything.
+func NewValidateConfigCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := ValidateConfigOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "validate-config",
+		Short: "Validate an imageset configuration",
+		Long: templates.LongDesc(`
+		Validate an imageset configuration without mirroring anything. The
+		configuration is checked against the schema of its version, reporting
+		unknown fields, values of the wrong type and mutually exclusive options
+		with their line and column, then against the rules checked before a
+		mirror run, such as duplicate catalogs or release channels.
+	`),
+		Example: templates.Examples(`
+			# Validate an imageset configuration
+			oc-mirror validate-config --config imageset-config.yaml
+
+			# Validate an imageset configuration with a profile merged onto it
+			oc-mirror validate-config --config imageset-config.yaml --profile edge
+
+			# Validate a shared base configuration with the overlay of a site merged onto it
+			oc-mirror validate-config --config base.yaml --overlay site-a.yaml
+		`),
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Validate())
+			checkErr(o.Run())
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVarP(&o.ConfigPath, "config", "c", o.ConfigPath, "Path to imageset configuration file")
+	fs.StringVar(&o.Profile, "profile", o.Profile, "Name of a profile in the imageset configuration to merge onto the base configuration")
+	fs.StringArrayVar(&o.Overlays, "overlay", o.Overlays, "Path to an imageset configuration to merge onto the base configuration before the profile. "+
+		"Can be repeated, each overlay taking precedence over the previous ones")
+	o.BindFlags(cmd.PersistentFlags())
+
+	return cmd
+}
+
+func (o *ValidateConfigOptions) Validate() error {
+	if len(o.ConfigPath) == 0 {
+		return errors.New("must specify --config")
+	}
+	return nil
+}
+
+func (o *ValidateConfigOptions) Run() error {
+	if _, err := config.ReadConfigWithOverlays(o.ConfigPath, o.Overlays, o.Profile); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.IOStreams.Out, "Imageset configuration %s is valid\n", o.ConfigPath)
+	return nil
+}
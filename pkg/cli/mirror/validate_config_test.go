@@ -0,0 +1,34 @@
+package mirror
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc-mirror/pkg/cli"
+)
+
+func TestValidateConfigRun(t *testing.T) {
+	out := &bytes.Buffer{}
+	o := &ValidateConfigOptions{
+		RootOptions: &cli.RootOptions{IOStreams: genericclioptions.IOStreams{Out: out, ErrOut: out}},
+		ConfigPath:  "testdata/configs/iscfg.yaml",
+	}
+	require.NoError(t, o.Run())
+	require.Equal(t, "Imageset configuration testdata/configs/iscfg.yaml is valid\n", out.String())
+
+	t.Run("Invalid/UnknownField", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "imageset-config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("apiVersion: mirror.openshift.io/v1alpha2\nkind: ImageSetConfiguration\nmirrors: {}\n"), 0600))
+		o := &ValidateConfigOptions{RootOptions: o.RootOptions, ConfigPath: path}
+		require.EqualError(t, o.Run(), "invalid configuration: 1 schema errors:\n  line 3, column 1: mirrors: unknown field")
+	})
+
+	t.Run("Invalid/NoConfig", func(t *testing.T) {
+		require.EqualError(t, (&ValidateConfigOptions{}).Validate(), "must specify --config")
+	})
+}
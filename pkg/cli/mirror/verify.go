@@ -0,0 +1,350 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/spf13/cobra"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/bundle"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+	"github.com/openshift/oc-mirror/pkg/network"
+)
+
+type VerifyOptions struct {
+	*cli.RootOptions
+	ConfigPath     string   // Path to the imageset configuration holding the storage configuration of the metadata
+	Profile        string   // Name of a profile merged onto the base configuration
+	Overlays       []string // Paths to imageset configurations merged onto the base configuration before the profile
+	From           string   // Path to an archived imageset holding the metadata
+	ToMirror       string   // Registry the images were published to
+	UserNamespace  string   // The <namespace>/<image> portion of the destination reference
+	DestSkipTLS    bool     // Disable TLS validation for destination registry
+	DestPlainHTTP  bool     // Use plain HTTP for destination registry
+	BlobChecks     int      // Number of layers checked per image manifest
+	MaxPerRegistry int      // Number of concurrent requests sent to the registry
+
+	namespaceMappings []v1alpha2.NamespaceMapping // mirror.namespaceMappings recorded in the metadata
+}
+
+func NewVerifyCommand(f kcmdutil.Factory, ro *cli.RootOptions) *cobra.Command {
+	o := VerifyOptions{}
+	o.RootOptions = ro
+
+	cmd := &cobra.Command{
+		Use:   "verify <destination registry>",
+		Short: "Verify that the images recorded in the last metadata still exist in the mirror registry",
+		Long: templates.LongDesc(`
+		Verify that every image recorded in the associations of the last mirror
+		metadata still exists in the mirror registry. The image manifests are
+		checked, along with a sample of the layers of each manifest, to report the
+		drift caused by the registry garbage collection or manual deletions.
+		The metadata is read from the storage configuration of an imageset
+		configuration or, with --from, from the registry the imageset archive was
+		published to.
+	`),
+		Example: templates.Examples(`
+			# Verify a mirror to mirror publish using the metadata of the storage configuration
+			oc-mirror verify --config mirror-config.yaml docker://localhost:5000/namespace
+
+			# Verify a published imageset, checking 3 layers per image manifest
+			oc-mirror verify --from mirror_seq1_000000.tar docker://localhost:5000/namespace --blob-checks 3
+		`),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Validate())
+			checkErr(o.Run(cmd.Context()))
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVarP(&o.ConfigPath, "config", "c", o.ConfigPath, "Path to imageset configuration file holding the metadata storage configuration")
+	fs.StringVar(&o.Profile, "profile", o.Profile, "Name of a profile in the imageset configuration to merge onto the base configuration")
+	fs.StringArrayVar(&o.Overlays, "overlay", o.Overlays, "Path to an imageset configuration to merge onto the base configuration before the profile. "+
+		"Can be repeated, each overlay taking precedence over the previous ones")
+	fs.StringVar(&o.From, "from", o.From, "Path to an archived imageset whose published metadata is verified")
+	fs.BoolVar(&o.DestSkipTLS, "dest-skip-tls", o.DestSkipTLS, "Disable TLS validation for destination registry")
+	fs.BoolVar(&o.DestPlainHTTP, "dest-use-http", o.DestPlainHTTP, "Use plain HTTP for destination registry")
+	fs.IntVar(&o.BlobChecks, "blob-checks", 1, "Number of layers checked per image manifest, 0 disables the layer checks")
+	fs.IntVar(&o.MaxPerRegistry, "max-per-registry", 6, "Number of concurrent requests allowed per registry")
+	o.BindFlags(cmd.PersistentFlags())
+
+	return cmd
+}
+
+func (o *VerifyOptions) Complete(args []string) error {
+	destination := args[0]
+	if !strings.HasPrefix(destination, "docker://") {
+		return fmt.Errorf("destination %q must use the docker:// scheme", destination)
+	}
+	mirror, err := imagesource.ParseReference(strings.TrimPrefix(destination, "docker://"))
+	if err != nil {
+		return err
+	}
+	o.ToMirror = mirror.Ref.Registry
+	o.UserNamespace = mirror.Ref.RepositoryName()
+	return nil
+}
+
+func (o *VerifyOptions) Validate() error {
+	switch {
+	case len(o.ConfigPath) == 0 && len(o.From) == 0:
+		return errors.New("must specify --config or --from")
+	case len(o.ConfigPath) != 0 && len(o.From) != 0:
+		return errors.New("--config and --from are mutually exclusive")
+	case o.BlobChecks < 0:
+		return errors.New("--blob-checks must not be negative")
+	case o.MaxPerRegistry <= 0:
+		return errors.New("--max-per-registry must be positive")
+	}
+	return nil
+}
+
+func (o *VerifyOptions) Run(ctx context.Context) error {
+	meta, err := o.readMetadata(ctx)
+	if err != nil {
+		return err
+	}
+	klog.Infof("Verifying %d associations of mirror sequence %d against %s", len(meta.PastAssociations), meta.PastMirror.Sequence, o.ToMirror)
+	o.namespaceMappings = meta.PastMirror.Mirror.NamespaceMappings
+
+	drifts, err := o.verify(ctx, meta.PastAssociations)
+	if err != nil {
+		return err
+	}
+	if err := writeDrifts(o.IOStreams.Out, drifts); err != nil {
+		return err
+	}
+	if len(drifts) != 0 {
+		return fmt.Errorf("%d missing manifests or layers detected in %s", len(drifts), o.ToMirror)
+	}
+	return nil
+}
+
+// readMetadata reads the last metadata from the storage configuration of the
+// imageset configuration, or from the registry the imageset archive was
+// published to, falling back to the metadata of the archive itself.
+func (o *VerifyOptions) readMetadata(ctx context.Context) (meta v1alpha2.Metadata, err error) {
+	var backend storage.Backend
+	if len(o.ConfigPath) != 0 {
+		cfg, err := config.ReadConfigWithOverlays(o.ConfigPath, o.Overlays, o.Profile)
+		if err != nil {
+			return meta, err
+		}
+		if err := network.Configure(cfg.Proxy); err != nil {
+			return meta, err
+		}
+		backend, err = storage.ByConfig(filepath.Join(o.Dir, config.SourceDir), cfg.StorageConfig)
+		if err != nil {
+			return meta, fmt.Errorf("error opening backend: %v", err)
+		}
+	} else {
+		incoming, err := bundle.ReadMetadataFromFile(ctx, o.From)
+		if err != nil {
+			return meta, fmt.Errorf("error retrieving metadata from %q: %v", o.From, err)
+		}
+		if incoming.SingleUse {
+			return incoming, nil
+		}
+		metaImage := fmt.Sprintf("%s:%s", path.Join(o.ToMirror, o.UserNamespace, "oc-mirror"), incoming.Uid.String())
+		backend, err = storage.NewRegistryBackend(&v1alpha2.RegistryConfig{ImageURL: metaImage, SkipTLS: o.insecure()}, o.Dir)
+		if err != nil {
+			return meta, fmt.Errorf("error creating backend for metadata at %s: %v", metaImage, err)
+		}
+		if err := backend.ReadMetadata(ctx, &meta, config.MetadataBasePath); err != nil {
+			klog.Warningf("unable to read the published metadata at %s, using the metadata of %s: %v", metaImage, o.From, err)
+			return incoming, nil
+		}
+		return meta, nil
+	}
+
+	switch err := backend.ReadMetadata(ctx, &meta, config.MetadataBasePath); {
+	case errors.Is(err, storage.ErrMetadataNotExist):
+		return meta, fmt.Errorf("no metadata detected")
+	case err != nil:
+		return meta, err
+	}
+	return meta, nil
+}
+
+func (o *VerifyOptions) insecure() bool {
+	return o.DestPlainHTTP || o.DestSkipTLS
+}
+
+// drift is a manifest or a layer recorded in the metadata
+// that is missing from the mirror registry.
+type drift struct {
+	Image  string
+	Ref    string
+	Reason string
+}
+
+// verify checks that the manifest of every association exists in the mirror
+// registry, along with a sample of its layers.
+func (o *VerifyOptions) verify(ctx context.Context, assocs []v1alpha2.Association) ([]drift, error) {
+	nopts := getNameOpts(o.insecure())
+	ropts := getRemoteOpts(ctx, o.insecure())
+
+	type check struct {
+		assoc v1alpha2.Association
+		ref   name.Digest
+	}
+	var checks []check
+	sources := associationSources(assocs)
+	for _, assoc := range assocs {
+		// Only the associations holding a manifest digest can be checked,
+		// tags are checked through the manifests they point to.
+		if assoc.ID == "" {
+			continue
+		}
+		repoLoc, err := o.repositoryLocation(assoc.Path, sources[assoc.Path])
+		if err != nil {
+			return nil, err
+		}
+		ref, err := name.NewDigest(fmt.Sprintf("%s@%s", path.Join(o.ToMirror, repoLoc), assoc.ID), nopts...)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing image reference for %s: %v", assoc.Name, err)
+		}
+		checks = append(checks, check{assoc: assoc, ref: ref})
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mutex  sync.Mutex
+		drifts []drift
+		errs   []error
+	)
+	workQueue := make(chan check)
+	for i := 0; i < o.MaxPerRegistry; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range workQueue {
+				found, err := o.verifyAssociation(c.assoc, c.ref, ropts)
+				mutex.Lock()
+				drifts = append(drifts, found...)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, c := range checks {
+		workQueue <- c
+	}
+	close(workQueue)
+	wg.Wait()
+
+	if len(errs) != 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Image != drifts[j].Image {
+			return drifts[i].Image < drifts[j].Image
+		}
+		return drifts[i].Ref < drifts[j].Ref
+	})
+	return drifts, nil
+}
+
+// verifyAssociation checks the manifest of assoc at ref and a sample of its layers.
+func (o *VerifyOptions) verifyAssociation(assoc v1alpha2.Association, ref name.Digest, ropts []remote.Option) ([]drift, error) {
+	if _, err := remote.Head(ref, ropts...); err != nil {
+		if isNotFound(err) {
+			return []drift{{Image: assoc.Name, Ref: ref.String(), Reason: "manifest not found"}}, nil
+		}
+		return nil, fmt.Errorf("error checking manifest %s: %v", ref, err)
+	}
+
+	var drifts []drift
+	for _, digest := range sampleDigests(assoc.LayerDigests, o.BlobChecks) {
+		layerRef := ref.Context().Digest(digest)
+		layer, err := remote.Layer(layerRef, ropts...)
+		if err != nil {
+			return nil, fmt.Errorf("error checking layer %s: %v", layerRef, err)
+		}
+		exists, err := partial.Exists(layer)
+		if err != nil && !isNotFound(err) {
+			return nil, fmt.Errorf("error checking layer %s: %v", layerRef, err)
+		}
+		if !exists {
+			drifts = append(drifts, drift{Image: assoc.Name, Ref: layerRef.String(), Reason: "layer not found"})
+		}
+	}
+	return drifts, nil
+}
+
+// repositoryLocation returns the location in the mirror registry of the
+// association path of the source image source, for both mirror to mirror
+// and disk to mirror paths.
+func (o *VerifyOptions) repositoryLocation(assocPath string, source reference.DockerImageReference) (string, error) {
+	ref, err := reference.Parse(assocPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid association path %q: %v", assocPath, err)
+	}
+	if ref.Registry != "" {
+		ref.Registry = ""
+		return ref.AsRepository().String(), nil
+	}
+	return path.Join(o.UserNamespace, mappedNamespace(o.namespaceMappings, source), ref.AsRepository().String()), nil
+}
+
+// sampleDigests returns n digests evenly spread across digests, including the
+// first one, so that the same layers are checked on every run.
+func sampleDigests(digests []string, n int) []string {
+	if n >= len(digests) {
+		return digests
+	}
+	sample := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		sample = append(sample, digests[i*len(digests)/n])
+	}
+	return sample
+}
+
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
+
+// writeDrifts writes the missing manifests and layers as a table.
+func writeDrifts(w io.Writer, drifts []drift) error {
+	if len(drifts) == 0 {
+		_, err := fmt.Fprintln(w, "No drift detected: every recorded image exists in the mirror registry")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "IMAGE\tREFERENCE\tREASON"); err != nil {
+		return err
+	}
+	for _, d := range drifts {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", d.Image, d.Ref, d.Reason); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
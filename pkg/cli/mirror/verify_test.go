@@ -0,0 +1,152 @@
+package mirror
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestVerifyValidate(t *testing.T) {
+	type spec struct {
+		name     string
+		opts     *VerifyOptions
+		expError string
+	}
+
+	cases := []spec{
+		{
+			name:     "Valid/Config",
+			opts:     &VerifyOptions{ConfigPath: "foo", BlobChecks: 1, MaxPerRegistry: 6},
+			expError: "",
+		},
+		{
+			name:     "Valid/From",
+			opts:     &VerifyOptions{From: "foo", MaxPerRegistry: 6},
+			expError: "",
+		},
+		{
+			name:     "Invalid/NoMetadata",
+			opts:     &VerifyOptions{MaxPerRegistry: 6},
+			expError: "must specify --config or --from",
+		},
+		{
+			name:     "Invalid/ConfigAndFrom",
+			opts:     &VerifyOptions{ConfigPath: "foo", From: "bar", MaxPerRegistry: 6},
+			expError: "--config and --from are mutually exclusive",
+		},
+		{
+			name:     "Invalid/NegativeBlobChecks",
+			opts:     &VerifyOptions{ConfigPath: "foo", BlobChecks: -1, MaxPerRegistry: 6},
+			expError: "--blob-checks must not be negative",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.opts.Validate()
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	img, err := random.Image(64, 3)
+	require.NoError(t, err)
+	ref, err := name.ParseReference(u.Host + "/ns/app:v1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, img))
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	var layerDigests []string
+	for _, layer := range layers {
+		d, err := layer.Digest()
+		require.NoError(t, err)
+		layerDigests = append(layerDigests, d.String())
+	}
+	const missingDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	type spec struct {
+		name      string
+		assocs    []v1alpha2.Association
+		expDrifts []drift
+	}
+
+	cases := []spec{
+		{
+			name: "Valid/NoDrift",
+			assocs: []v1alpha2.Association{
+				{Name: "app", Path: "app:v1", ID: digest.String(), LayerDigests: layerDigests},
+				{Name: "app", Path: "app:v1", TagSymlink: "v1"},
+			},
+		},
+		{
+			name: "Valid/MirrorToMirrorPath",
+			assocs: []v1alpha2.Association{
+				{Name: "app", Path: "registry.example.com/ns/app:v1", ID: digest.String(), LayerDigests: layerDigests},
+			},
+		},
+		{
+			name: "Invalid/MissingManifest",
+			assocs: []v1alpha2.Association{
+				{Name: "app", Path: "app:v1", ID: digest.String(), LayerDigests: layerDigests},
+				{Name: "other", Path: "other:v1", ID: digest.String(), LayerDigests: layerDigests},
+			},
+			expDrifts: []drift{
+				{Image: "other", Ref: u.Host + "/ns/other@" + digest.String(), Reason: "manifest not found"},
+			},
+		},
+		{
+			name: "Invalid/MissingLayer",
+			assocs: []v1alpha2.Association{
+				{Name: "app", Path: "app:v1", ID: digest.String(), LayerDigests: []string{missingDigest}},
+			},
+			expDrifts: []drift{
+				{Image: "app", Ref: u.Host + "/ns/app@" + missingDigest, Reason: "layer not found"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := &VerifyOptions{
+				ToMirror:       u.Host,
+				UserNamespace:  "ns",
+				DestPlainHTTP:  true,
+				BlobChecks:     3,
+				MaxPerRegistry: 2,
+			}
+			drifts, err := opts.verify(context.Background(), c.assocs)
+			require.NoError(t, err)
+			require.Equal(t, c.expDrifts, drifts)
+		})
+	}
+}
+
+func TestSampleDigests(t *testing.T) {
+	digests := []string{"a", "b", "c", "d", "e", "f"}
+	require.Equal(t, []string{"a"}, sampleDigests(digests, 1))
+	require.Equal(t, []string{"a", "c", "e"}, sampleDigests(digests, 3))
+	require.Equal(t, digests, sampleDigests(digests, 10))
+	require.Empty(t, sampleDigests(digests, 0))
+}
@@ -0,0 +1,260 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// maxDownloadContributors is the number of largest images listed
+// when the images to download exceed MaxDownloadSize.
+const maxDownloadContributors = 5
+
+// blobSet holds the size of blobs keyed by digest, so the blobs
+// shared by several images are only counted once.
+type blobSet map[v1.Hash]int64
+
+// size returns the sum of the sizes of the blobs of s.
+func (s blobSet) size() int64 {
+	var total int64
+	for _, size := range s {
+		total += size
+	}
+	return total
+}
+
+// addManifest adds the manifest, the config and the layers of img to s.
+func (s blobSet) addManifest(img v1.Image) error {
+	digest, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	size, err := img.Size()
+	if err != nil {
+		return err
+	}
+	s[digest] = size
+
+	m, err := img.Manifest()
+	if err != nil {
+		return err
+	}
+	s[m.Config.Digest] = m.Config.Size
+	for _, layer := range m.Layers {
+		s[layer.Digest] = layer.Size
+	}
+	return nil
+}
+
+// addIndex adds the index and every manifest of idx to s.
+func (s blobSet) addIndex(idx v1.ImageIndex) error {
+	digest, err := idx.Digest()
+	if err != nil {
+		return err
+	}
+	size, err := idx.Size()
+	if err != nil {
+		return err
+	}
+	s[digest] = size
+
+	m, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, desc := range m.Manifests {
+		switch {
+		case desc.MediaType.IsIndex():
+			child, err := idx.ImageIndex(desc.Digest)
+			if err != nil {
+				return err
+			}
+			if err := s.addIndex(child); err != nil {
+				return err
+			}
+		case desc.MediaType.IsImage():
+			img, err := idx.Image(desc.Digest)
+			if err != nil {
+				return err
+			}
+			if err := s.addManifest(img); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mappingSize is the estimated size of the source images of a mapping.
+type mappingSize struct {
+	total   int64            // bytes of the blobs of every image, the shared blobs counted once
+	byImage map[string]int64 // bytes of the blobs of each image, keyed by source reference
+}
+
+// estimateMappingSize returns the number of bytes of the blobs of the source
+// images of mapping, read from their manifests. Every manifest of a manifest
+// list is counted, so the estimate is an upper bound of the size mirrored.
+func (o *MirrorOptions) estimateMappingSize(ctx context.Context, mapping image.TypedImageMapping, insecure bool) (mappingSize, error) {
+	size := mappingSize{byImage: map[string]int64{}}
+	blobs := blobSet{}
+	for srcRef := range mapping {
+		if srcRef.Type != imagesource.DestinationRegistry {
+			continue
+		}
+		ref, err := name.ParseReference(srcRef.Ref.Exact(), getNameOpts(insecure)...)
+		if err != nil {
+			return size, err
+		}
+		desc, err := remote.Get(ref, getRemoteOpts(ctx, insecure)...)
+		if err != nil {
+			return size, fmt.Errorf("error reading manifest of %s: %v", srcRef.Ref.Exact(), err)
+		}
+		imageBlobs := blobSet{}
+		if desc.MediaType.IsIndex() {
+			idx, err := desc.ImageIndex()
+			if err != nil {
+				return size, err
+			}
+			err = imageBlobs.addIndex(idx)
+		} else {
+			img, err := desc.Image()
+			if err != nil {
+				return size, err
+			}
+			err = imageBlobs.addManifest(img)
+		}
+		if err != nil {
+			return size, fmt.Errorf("error reading manifest of %s: %v", srcRef.Ref.Exact(), err)
+		}
+		size.byImage[srcRef.Ref.Exact()] = imageBlobs.size()
+		for digest, blobSize := range imageBlobs {
+			blobs[digest] = blobSize
+		}
+	}
+	size.total = blobs.size()
+	return size, nil
+}
+
+// maxDownloadBytes returns the number of bytes of MaxDownloadSize,
+// or 0 when the size downloaded is not limited.
+func (o *MirrorOptions) maxDownloadBytes() (int64, error) {
+	if o.MaxDownloadSize == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(o.MaxDownloadSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --max-download-size %q: %v", o.MaxDownloadSize, err)
+	}
+	if q.Sign() <= 0 {
+		return 0, fmt.Errorf("--max-download-size must be positive")
+	}
+	return q.Value(), nil
+}
+
+// uploadChunkBytes returns the number of bytes of UploadChunkSize,
+// or 0 when the blob uploads are not chunked.
+func (o *MirrorOptions) uploadChunkBytes() (int64, error) {
+	if o.UploadChunkSize == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(o.UploadChunkSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --upload-chunk-size %q: %v", o.UploadChunkSize, err)
+	}
+	if q.Sign() <= 0 {
+		return 0, fmt.Errorf("--upload-chunk-size must be positive")
+	}
+	return q.Value(), nil
+}
+
+// checkDownloadSize returns an error listing the largest images when the images
+// of size, added to the images planned earlier in the run, exceed MaxDownloadSize.
+func (o *MirrorOptions) checkDownloadSize(size mappingSize) error {
+	limit, err := o.maxDownloadBytes()
+	if err != nil || limit == 0 {
+		return err
+	}
+	o.plannedDownload += size.total
+	if o.plannedDownload <= limit {
+		return nil
+	}
+
+	refs := make([]string, 0, len(size.byImage))
+	for ref := range size.byImage {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if size.byImage[refs[i]] != size.byImage[refs[j]] {
+			return size.byImage[refs[i]] > size.byImage[refs[j]]
+		}
+		return refs[i] < refs[j]
+	})
+	if len(refs) > maxDownloadContributors {
+		refs = refs[:maxDownloadContributors]
+	}
+	contributors := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		contributors = append(contributors, fmt.Sprintf("%s (%s)", ref, formatGiB(size.byImage[ref])))
+	}
+	return fmt.Errorf("estimated download size %s exceeds --max-download-size %s, largest images: %s",
+		formatGiB(o.plannedDownload), o.MaxDownloadSize, strings.Join(contributors, ", "))
+}
+
+// freeSpace returns the bytes available to the user on the filesystem of dir,
+// and an identifier of that filesystem.
+var freeSpace = filesystemFreeSpace
+
+// checkFreeSpace returns an error when the filesystems of the workspace
+// and of the output directory cannot hold need bytes of images: the blobs
+// are written to the workspace first, then to the imageset archives.
+func (o *MirrorOptions) checkFreeSpace(need int64) error {
+	needByDevice := map[uint64]int64{}
+	freeByDevice := map[uint64]int64{}
+	dirByDevice := map[uint64]string{}
+	for _, dir := range []string{o.Dir, o.OutputDir} {
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return err
+		}
+		free, dev, err := freeSpace(dir)
+		if err != nil {
+			return fmt.Errorf("error reading free space of %s: %v", dir, err)
+		}
+		needByDevice[dev] += need
+		freeByDevice[dev] = free
+		if _, found := dirByDevice[dev]; !found {
+			dirByDevice[dev] = dir
+		}
+	}
+	for dev, need := range needByDevice {
+		if free := freeByDevice[dev]; need > free {
+			return NewClassifiedError(ErrorClassDiskFull,
+				fmt.Errorf("not enough disk space for %s: need %s free, have %s", dirByDevice[dev], formatGiB(need), formatGiB(free)))
+		}
+	}
+	return nil
+}
+
+// formatGiB returns size in GiB, with one decimal.
+func formatGiB(size int64) string {
+	return fmt.Sprintf("%.1f GiB", float64(size)/float64(segMultiplier))
+}
+
+// formatMiB returns size in MiB, with one decimal.
+func formatMiB(size int64) string {
+	return fmt.Sprintf("%.1f MiB", float64(size)/(1<<20))
+}
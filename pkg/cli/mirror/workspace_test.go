@@ -0,0 +1,135 @@
+package mirror
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestCheckFreeSpace(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	// Two images sharing a layer, the shared layer is only counted once.
+	shared, err := random.Layer(1024, "application/vnd.oci.image.layer.v1.tar")
+	require.NoError(t, err)
+	own, err := random.Layer(2048, "application/vnd.oci.image.layer.v1.tar")
+	require.NoError(t, err)
+	foo, err := mutate.AppendLayers(empty.Image, shared)
+	require.NoError(t, err)
+	bar, err := mutate.AppendLayers(empty.Image, shared, own)
+	require.NoError(t, err)
+
+	mapping := image.TypedImageMapping{}
+	var expSize int64
+	for _, img := range []struct {
+		ref string
+		img v1.Image
+	}{{u.Host + "/test/foo:v1", foo}, {u.Host + "/test/bar:v1", bar}} {
+		ref, err := name.ParseReference(img.ref)
+		require.NoError(t, err)
+		require.NoError(t, remote.Write(ref, img.img))
+		src, err := image.ParseTypedImage(img.ref, v1alpha2.TypeOperatorRelatedImage)
+		require.NoError(t, err)
+		mapping[src] = src
+
+		size, err := img.img.Size()
+		require.NoError(t, err)
+		m, err := img.img.Manifest()
+		require.NoError(t, err)
+		expSize += size + m.Config.Size
+	}
+	sharedSize, err := shared.Size()
+	require.NoError(t, err)
+	ownSize, err := own.Size()
+	require.NoError(t, err)
+	expSize += sharedSize + ownSize
+
+	o := &MirrorOptions{RootOptions: &cli.RootOptions{Dir: t.TempDir()}, OutputDir: t.TempDir()}
+	size, err := o.estimateMappingSize(context.TODO(), mapping, true)
+	require.NoError(t, err)
+	require.Equal(t, expSize, size.total)
+	require.Len(t, size.byImage, 2)
+
+	defaultFreeSpace := freeSpace
+	t.Cleanup(func() { freeSpace = defaultFreeSpace })
+
+	t.Run("Valid/EnoughSpace", func(t *testing.T) {
+		freeSpace = func(string) (int64, uint64, error) { return 2 * expSize, 1, nil }
+		require.NoError(t, o.checkFreeSpace(size.total))
+	})
+	t.Run("Invalid/SameFilesystem", func(t *testing.T) {
+		// The workspace and the archives share the filesystem, both must fit.
+		freeSpace = func(string) (int64, uint64, error) { return 2*expSize - 1, 1, nil }
+		err := o.checkFreeSpace(size.total)
+		require.ErrorContains(t, err, "not enough disk space")
+		require.Equal(t, ErrorClassDiskFull, ClassifyError(err))
+	})
+	t.Run("Valid/SeparateFilesystems", func(t *testing.T) {
+		dev := uint64(0)
+		freeSpace = func(string) (int64, uint64, error) { dev++; return expSize, dev, nil }
+		require.NoError(t, o.checkFreeSpace(size.total))
+	})
+}
+
+func TestCheckDownloadSize(t *testing.T) {
+	size := mappingSize{
+		total: 6 * segMultiplier,
+		byImage: map[string]int64{
+			"quay.io/foo/a@sha256:0000000000000000000000000000000000000000000000000000000000000001": 3 * segMultiplier,
+			"quay.io/foo/b@sha256:0000000000000000000000000000000000000000000000000000000000000002": 2 * segMultiplier,
+			"quay.io/foo/c@sha256:0000000000000000000000000000000000000000000000000000000000000003": segMultiplier,
+		},
+	}
+
+	t.Run("Valid/NoLimit", func(t *testing.T) {
+		o := &MirrorOptions{}
+		require.NoError(t, o.checkDownloadSize(size))
+	})
+	t.Run("Valid/UnderLimit", func(t *testing.T) {
+		o := &MirrorOptions{MaxDownloadSize: "6Gi"}
+		require.NoError(t, o.checkDownloadSize(size))
+	})
+	t.Run("Invalid/OverLimit", func(t *testing.T) {
+		o := &MirrorOptions{MaxDownloadSize: "5Gi"}
+		require.EqualError(t, o.checkDownloadSize(size), "estimated download size 6.0 GiB exceeds --max-download-size 5Gi, largest images: "+
+			"quay.io/foo/a@sha256:0000000000000000000000000000000000000000000000000000000000000001 (3.0 GiB), "+
+			"quay.io/foo/b@sha256:0000000000000000000000000000000000000000000000000000000000000002 (2.0 GiB), "+
+			"quay.io/foo/c@sha256:0000000000000000000000000000000000000000000000000000000000000003 (1.0 GiB)")
+	})
+	t.Run("Invalid/OverLimitAcrossDestinations", func(t *testing.T) {
+		// The images planned for every destination of the run count towards the limit.
+		o := &MirrorOptions{MaxDownloadSize: "10Gi"}
+		require.NoError(t, o.checkDownloadSize(size))
+		require.ErrorContains(t, o.checkDownloadSize(size), "estimated download size 12.0 GiB exceeds --max-download-size 10Gi")
+	})
+	t.Run("Invalid/Quantity", func(t *testing.T) {
+		o := &MirrorOptions{MaxDownloadSize: "lots"}
+		require.ErrorContains(t, o.checkDownloadSize(size), `invalid --max-download-size "lots"`)
+	})
+	t.Run("Invalid/NotPositive", func(t *testing.T) {
+		o := &MirrorOptions{MaxDownloadSize: "0"}
+		require.EqualError(t, o.checkDownloadSize(size), "--max-download-size must be positive")
+	})
+}
+
+func TestFormatGiB(t *testing.T) {
+	require.Equal(t, "1.5 GiB", formatGiB(3*segMultiplier/2))
+	require.Equal(t, "0.0 GiB", formatGiB(0))
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package mirror
+
+import "golang.org/x/sys/unix"
+
+// filesystemFreeSpace returns the bytes available to the user on the
+// filesystem of dir, and the device of that filesystem.
+func filesystemFreeSpace(dir string) (int64, uint64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return 0, 0, err
+	}
+	var stat unix.Stat_t
+	if err := unix.Stat(dir, &stat); err != nil {
+		return 0, 0, err
+	}
+	return int64(uint64(st.Bavail) * uint64(st.Bsize)), uint64(stat.Dev), nil
+}
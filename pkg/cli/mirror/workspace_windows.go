@@ -0,0 +1,27 @@
+//go:build windows
+
+package mirror
+
+import (
+	"hash/fnv"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// filesystemFreeSpace returns the bytes available to the user on the
+// volume of dir, and an identifier of that volume.
+func filesystemFreeSpace(dir string) (int64, uint64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &free, &total, &totalFree); err != nil {
+		return 0, 0, err
+	}
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToUpper(filepath.VolumeName(dir))))
+	return int64(free), h.Sum64(), nil
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
 
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
@@ -20,6 +21,40 @@ import (
 // ReadConfig opens an imageset configuration file at the given path
 // and loads it into a v1alpha2.ImageSetConfiguration instance for processing and validation.
 func ReadConfig(configPath string) (c v1alpha2.ImageSetConfiguration, err error) {
+	return ReadConfigWithProfile(configPath, "")
+}
+
+// ReadConfigWithProfile behaves like ReadConfig and merges the named profile
+// onto the base configuration before it is completed and validated.
+func ReadConfigWithProfile(configPath, profile string) (c v1alpha2.ImageSetConfiguration, err error) {
+	return ReadConfigWithOverlays(configPath, nil, profile)
+}
+
+// ReadConfigWithOverlays behaves like ReadConfigWithProfile and merges the
+// imageset configuration files at overlayPaths onto the base configuration,
+// in order, before the named profile.
+func ReadConfigWithOverlays(configPath string, overlayPaths []string, profile string) (c v1alpha2.ImageSetConfiguration, err error) {
+	c, err = readConfigFile(configPath)
+	if err != nil {
+		return c, err
+	}
+
+	if err := ApplyOverlays(&c, overlayPaths); err != nil {
+		return c, err
+	}
+
+	if err := ApplyProfile(&c, profile); err != nil {
+		return c, err
+	}
+
+	Complete(&c)
+
+	return c, Validate(&c)
+}
+
+// readConfigFile loads the imageset configuration file at configPath
+// without completing and validating it.
+func readConfigFile(configPath string) (c v1alpha2.ImageSetConfiguration, err error) {
 
 	data, err := os.ReadFile(filepath.Clean(configPath))
 	if err != nil {
@@ -33,17 +68,20 @@ func ReadConfig(configPath string) (c v1alpha2.ImageSetConfiguration, err error)
 
 	switch typeMeta.GroupVersionKind() {
 	case v1alpha2.GroupVersion.WithKind(v1alpha2.ImageSetConfigurationKind):
+		if err := ValidateSchema(data); err != nil {
+			return c, err
+		}
 		c, err = LoadConfig(data)
 		if err != nil {
 			return c, err
 		}
+	case schema.GroupVersionKind{Group: v1alpha2.GroupVersion.Group, Version: "v2alpha1", Kind: v1alpha2.ImageSetConfigurationKind}:
+		return c, fmt.Errorf("config GVK not recognized: %s, v2alpha1 configurations are used with --v2", typeMeta.GroupVersionKind())
 	default:
 		return c, fmt.Errorf("config GVK not recognized: %s", typeMeta.GroupVersionKind())
 	}
 
-	Complete(&c)
-
-	return c, Validate(&c)
+	return c, nil
 }
 
 // LoadConfig loads data into a v1alpha2.ImageSetConfiguration instance
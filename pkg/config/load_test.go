@@ -91,7 +91,7 @@ func TestLoadConfig(t *testing.T) {
 							{Name: "podinfo", Path: "/test/podinfo-5.0.0.tar.gz"},
 						},
 					},
-					BlockedImages: []v1alpha2.Image{
+					BlockedImages: []v1alpha2.BlockedImage{
 						{Name: "alpine"},
 						{Name: "redis"},
 					},
@@ -17,6 +17,7 @@ Constants defined here refer to this workspace layout:
 			│               └── sha256:6f02ecef46020bcd21bdd24a01f435023d5fc3943972ef0d9769d5276e178e76 ─┘
 			│                   ├── cacheLocation.txt          <—— this file stores the location of the opm cache within the operators catalog container
 			│                   ├── include-config.gob         <—— this represents v1alpha2.IncludeConfig for associated with index/index.json
+			│                   ├── provenance.json            <—— the source and filter digests labeling the rebuilt catalog image
 			│                   ├── index                      <—— this represents a decl config for "single architecture image" image
 			│                   │   │                              (i.e. no multi arch use case)
 			│                   │   └── index.json             <—— Declarative Config
@@ -69,6 +70,13 @@ const (
 	// HelmDir is the directory that contains all
 	// downloaded charts.
 	HelmDir = "charts"
+	// CLIDownloadsDir is the directory that contains
+	// the client binaries declared by the ConsoleCLIDownload
+	// objects of operator bundles.
+	CLIDownloadsDir = "cli-downloads"
+	// ArtifactsDir is the directory containing the
+	// OCI layout of the OCI artifacts of the imageset.
+	ArtifactsDir = "oci-artifacts"
 	// V2Dir is the directory containing images
 	// mirrored to disk.
 	V2Dir = "v2"
@@ -78,6 +86,9 @@ const (
 	// MetadataFile is the filename that contains
 	// the metadata.
 	MetadataFile = ".metadata.json"
+	// FormatFile is the filename that contains
+	// the format version of an imageset archive.
+	FormatFile = ".format"
 	// ReleaseSignatureDir is the top-level
 	// directory where platform release-signature
 	// configmaps are stored.
@@ -108,6 +119,55 @@ const (
 	// catalog include config data for incorporation
 	// into the metadata is located.
 	IncludeConfigFile = "include-config.gob"
+	// CatalogProvenanceFile is the file where the
+	// source digest and filter digest of a catalog are
+	// stored during plan, to label the rebuilt catalog.
+	CatalogProvenanceFile = "provenance.json"
+	// CatalogCachesDir is the directory of the oc-mirror
+	// workspace holding the opm caches regenerated for the
+	// rebuilt catalogs, keyed by the digest of their
+	// declarative config. It is outside of SourceDir
+	// so the caches are reused across runs.
+	CatalogCachesDir = "catalog-caches"
+	// CatalogPublishStatusFile is the file of the oc-mirror
+	// workspace recording the catalogs rebuilt by publish, with
+	// the digest they were pushed with or their last error, so
+	// that a publish run again only rebuilds the catalogs that
+	// did not reach the destination.
+	CatalogPublishStatusFile = "catalog-publish-status.json"
+	// AuditLogFile is the append-only log of the oc-mirror
+	// workspace recording every manifest pushed, tag updated
+	// and manifest deleted in the registries, one JSON
+	// object per line.
+	AuditLogFile = "audit.jsonl"
+	// PendingMetadataDir is the directory of the oc-mirror
+	// workspace holding the metadata that could not be
+	// written to an unreachable registry backend, one
+	// directory per metadata image, until it is pushed
+	// at the start of the next publish.
+	PendingMetadataDir = "pending-metadata"
+	// PendingMarkerFile is the file marking queued
+	// metadata as pending. It contains the metadata
+	// image the metadata is to be pushed to.
+	PendingMarkerFile = "pending"
+	// AssociationsDir is the directory of the oc-mirror
+	// workspace holding the databases the image
+	// associations of metadata are loaded into while
+	// they are processed.
+	AssociationsDir = "associations"
+	// OperatorImageUsageFile is the file of the oc-mirror
+	// workspace listing, for each operator image of the
+	// last run, the bundles referencing it.
+	OperatorImageUsageFile = "operator-image-usage.json"
+	// HistoryDir is the directory of the oc-mirror
+	// workspace holding a record of the images mirrored
+	// by each sequence, compared by describe --diff.
+	HistoryDir = "history"
+	// BlobStoreDir is the directory of the oc-mirror
+	// workspace holding a single copy of the blobs
+	// unpacked or fetched during publish, linked to
+	// the directory of each image using them.
+	BlobStoreDir = "blob-store"
 	// OPMCacheLocationPlaceholder is the file where
 	// the path to the catalog cache is stored during plan
 	// so that it is later used to rebuild the cache layer.
@@ -119,3 +179,7 @@ const (
 // MetadataBasePath is the local path relative to the oc-mirror workspace
 // where metadata is stored.
 var MetadataBasePath = filepath.Join(PublishDir, MetadataFile)
+
+// FormatBasePath is the path in the imageset archives
+// where the format version is stored.
+var FormatBasePath = filepath.Join(PublishDir, FormatFile)
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// ApplyOverlays reads the imageset configuration files at overlayPaths and
+// merges them onto cfg with ApplyOverlay, in order, so that each overlay
+// takes precedence over the base and the overlays before it.
+func ApplyOverlays(cfg *v1alpha2.ImageSetConfiguration, overlayPaths []string) error {
+	for _, overlayPath := range overlayPaths {
+		overlay, err := readConfigFile(overlayPath)
+		if err != nil {
+			return fmt.Errorf("overlay %s: %v", overlayPath, err)
+		}
+		if err := ApplyOverlay(cfg, overlay); err != nil {
+			return fmt.Errorf("overlay %s: %v", overlayPath, err)
+		}
+	}
+	return nil
+}
+
+// ApplyOverlay merges the overlay configuration onto the base configuration cfg.
+// The settings set by the overlay replace those of the base. Listed content is
+// merged by name: release channels, Helm repositories and charts, and profiles
+// replace those of the base with the same name, operators are merged like
+// profiles merge them, and images and artifacts missing from the base are
+// appended. Namespace mappings of the overlay are placed before those of the
+// base, replacing those with the same source, so that they match first.
+func ApplyOverlay(cfg *v1alpha2.ImageSetConfiguration, overlay v1alpha2.ImageSetConfiguration) error {
+	if overlay.ArchiveSize != 0 {
+		cfg.ArchiveSize = overlay.ArchiveSize
+	}
+	if overlay.ArchiveCompression != nil {
+		cfg.ArchiveCompression = overlay.ArchiveCompression
+	}
+	if overlay.StorageConfig.IsSet() {
+		cfg.StorageConfig = overlay.StorageConfig
+	}
+	if overlay.Notifications != nil {
+		cfg.Notifications = overlay.Notifications
+	}
+	if overlay.Proxy != nil {
+		cfg.Proxy = overlay.Proxy
+	}
+	for name, profile := range overlay.Profiles {
+		if cfg.Profiles == nil {
+			cfg.Profiles = map[string]v1alpha2.Profile{}
+		}
+		cfg.Profiles[name] = profile
+	}
+
+	mirror, add := &cfg.Mirror, overlay.Mirror
+	mergePlatform(&mirror.Platform, add.Platform)
+	for _, op := range add.Operators {
+		if err := mergeOperator(mirror, op); err != nil {
+			return err
+		}
+	}
+	mirror.AdditionalImages = mergeByName(mirror.AdditionalImages, add.AdditionalImages, imageName)
+	mirror.BlockedImages = mergeByName(mirror.BlockedImages, add.BlockedImages, blockedImageName)
+	mirror.Samples = mergeByName(mirror.Samples, add.Samples, sampleName)
+	mirror.Artifacts = mergeByName(mirror.Artifacts, add.Artifacts, artifactName)
+	mergeHelm(&mirror.Helm, add.Helm)
+	mirror.NamespaceMappings = mergeNamespaceMappings(mirror.NamespaceMappings, add.NamespaceMappings)
+	return nil
+}
+
+// mergePlatform merges the platform add onto base. The graph image
+// of the base cannot be disabled by an overlay.
+func mergePlatform(base *v1alpha2.Platform, add v1alpha2.Platform) {
+	base.Graph = base.Graph || add.Graph
+	if len(add.Architectures) != 0 {
+		base.Architectures = add.Architectures
+	}
+	if add.GraphDataURL != "" {
+		base.GraphDataURL = add.GraphDataURL
+	}
+	if add.Samples != nil {
+		base.Samples = add.Samples
+	}
+	for _, ch := range add.Channels {
+		replaced := false
+		for i, baseCh := range base.Channels {
+			if baseCh.Name == ch.Name {
+				base.Channels[i] = ch
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			base.Channels = append(base.Channels, ch)
+		}
+	}
+}
+
+// mergeHelm merges the Helm repositories and local charts of add onto base.
+func mergeHelm(base *v1alpha2.Helm, add v1alpha2.Helm) {
+	for _, repo := range add.Repositories {
+		replaced := false
+		for i, baseRepo := range base.Repositories {
+			if baseRepo.Name == repo.Name {
+				base.Repositories[i] = repo
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			base.Repositories = append(base.Repositories, repo)
+		}
+	}
+	for _, chart := range add.Local {
+		replaced := false
+		for i, baseChart := range base.Local {
+			if baseChart.Name == chart.Name {
+				base.Local[i] = chart
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			base.Local = append(base.Local, chart)
+		}
+	}
+}
+
+// mergeNamespaceMappings returns the mappings of add followed by
+// the mappings of base whose source is not mapped by add.
+func mergeNamespaceMappings(base, add []v1alpha2.NamespaceMapping) []v1alpha2.NamespaceMapping {
+	if len(add) == 0 {
+		return base
+	}
+	merged := append([]v1alpha2.NamespaceMapping{}, add...)
+	for _, m := range base {
+		overridden := false
+		for _, a := range add {
+			if a.Source == m.Source {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, m)
+		}
+	}
+	return merged
+}
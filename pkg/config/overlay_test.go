@@ -0,0 +1,118 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestApplyOverlay(t *testing.T) {
+	cfg := v1alpha2.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v1alpha2.ImageSetConfigurationSpec{
+			ArchiveSize: 4,
+			Mirror: v1alpha2.Mirror{
+				Platform: v1alpha2.Platform{
+					Graph:    true,
+					Channels: []v1alpha2.ReleaseChannel{{Name: "stable-4.14", MinVersion: "4.14.1"}},
+				},
+				AdditionalImages: []v1alpha2.Image{{Name: "registry.redhat.io/ubi8/ubi:latest"}},
+				Samples:          []v1alpha2.SampleImages{{Image: v1alpha2.Image{Name: "ruby"}}},
+				Artifacts:        []v1alpha2.Artifact{{Name: "quay.io/charts/podinfo:6.5.4"}},
+				Helm: v1alpha2.Helm{
+					Repositories: []v1alpha2.Repository{{Name: "podinfo", URL: "https://stefanprodan.github.io/podinfo"}},
+				},
+				NamespaceMappings: []v1alpha2.NamespaceMapping{
+					{Source: "registry.redhat.io", Namespace: "redhat"},
+					{Source: "quay.io", Namespace: "community"},
+				},
+			},
+			Profiles: map[string]v1alpha2.Profile{"edge": {}},
+		},
+	}
+	overlay := v1alpha2.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v1alpha2.ImageSetConfigurationSpec{
+			Proxy: &v1alpha2.Proxy{HTTPSProxy: "http://proxy.site-a.example.com:3128"},
+			Mirror: v1alpha2.Mirror{
+				Platform: v1alpha2.Platform{
+					Channels: []v1alpha2.ReleaseChannel{
+						{Name: "stable-4.14", MinVersion: "4.14.10"},
+						{Name: "stable-4.15"},
+					},
+				},
+				AdditionalImages: []v1alpha2.Image{
+					{Name: "registry.redhat.io/ubi8/ubi:latest"},
+					{Name: "registry.example.com/site-a/agent:v1"},
+				},
+				Samples:   []v1alpha2.SampleImages{{Image: v1alpha2.Image{Name: "ruby"}}, {Image: v1alpha2.Image{Name: "python"}}},
+				Artifacts: []v1alpha2.Artifact{{Name: "quay.io/charts/podinfo:6.5.5"}},
+				Helm: v1alpha2.Helm{
+					Repositories: []v1alpha2.Repository{{Name: "podinfo", URL: "https://charts.site-a.example.com/podinfo"}},
+				},
+				NamespaceMappings: []v1alpha2.NamespaceMapping{{Source: "quay.io", Namespace: "site-a"}},
+			},
+			Profiles: map[string]v1alpha2.Profile{"site-a": {}},
+		},
+	}
+
+	require.NoError(t, ApplyOverlay(&cfg, overlay))
+	require.Equal(t, int64(4), cfg.ArchiveSize)
+	require.Equal(t, overlay.Proxy, cfg.Proxy)
+	require.True(t, cfg.Mirror.Platform.Graph)
+	require.Equal(t, []v1alpha2.ReleaseChannel{
+		{Name: "stable-4.14", MinVersion: "4.14.10"},
+		{Name: "stable-4.15"},
+	}, cfg.Mirror.Platform.Channels)
+	require.Equal(t, []v1alpha2.Image{
+		{Name: "registry.redhat.io/ubi8/ubi:latest"},
+		{Name: "registry.example.com/site-a/agent:v1"},
+	}, cfg.Mirror.AdditionalImages)
+	require.Len(t, cfg.Mirror.Samples, 2)
+	require.Len(t, cfg.Mirror.Artifacts, 2)
+	require.Equal(t, []v1alpha2.Repository{{Name: "podinfo", URL: "https://charts.site-a.example.com/podinfo"}}, cfg.Mirror.Helm.Repositories)
+	require.Equal(t, []v1alpha2.NamespaceMapping{
+		{Source: "quay.io", Namespace: "site-a"},
+		{Source: "registry.redhat.io", Namespace: "redhat"},
+	}, cfg.Mirror.NamespaceMappings)
+	require.Contains(t, cfg.Profiles, "edge")
+	require.Contains(t, cfg.Profiles, "site-a")
+}
+
+func TestReadConfigWithOverlays(t *testing.T) {
+	type spec struct {
+		name     string
+		overlays []string
+		expError string
+	}
+	cases := []spec{
+		{
+			name:     "Valid/Overlay",
+			overlays: []string{filepath.Join("testdata", "config", "overlay-site.yaml")},
+		},
+		{
+			name:     "Invalid/MissingOverlay",
+			overlays: []string{filepath.Join("testdata", "config", "missing.yaml")},
+			expError: "overlay testdata/config/missing.yaml: open testdata/config/missing.yaml: no such file or directory",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, err := ReadConfigWithOverlays(filepath.Join("testdata", "config", "overlay-base.yaml"), c.overlays, "")
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(8), cfg.ArchiveSize)
+			require.Equal(t, "/var/lib/oc-mirror/metadata", cfg.StorageConfig.Local.Path)
+			require.Equal(t, "4.14.10", cfg.Mirror.Platform.Channels[0].MinVersion)
+			pkgs := cfg.Mirror.Operators[0].Packages
+			require.Len(t, pkgs, 2)
+			require.Equal(t, "lvms-operator", pkgs[1].Name)
+			require.Equal(t, []v1alpha2.IncludeChannel{{Name: "stable-4.14"}}, pkgs[1].Channels)
+			require.Len(t, cfg.Mirror.AdditionalImages, 2)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// ApplyProfile merges the content of the named profile onto the base
+// Mirror configuration and clears the profiles from the configuration.
+// An empty name only clears the profiles, leaving the base untouched.
+func ApplyProfile(cfg *v1alpha2.ImageSetConfiguration, name string) error {
+	profiles := cfg.Profiles
+	cfg.Profiles = nil
+	if name == "" {
+		return nil
+	}
+
+	profile, found := profiles[name]
+	if !found {
+		available := make([]string, 0, len(profiles))
+		for p := range profiles {
+			available = append(available, p)
+		}
+		sort.Strings(available)
+		return fmt.Errorf("profile %q not found in configuration, available profiles: [%s]", name, strings.Join(available, ", "))
+	}
+
+	for _, op := range profile.Operators {
+		if err := mergeOperator(&cfg.Mirror, op); err != nil {
+			return fmt.Errorf("profile %q: %v", name, err)
+		}
+	}
+	cfg.Mirror.AdditionalImages = mergeByName(cfg.Mirror.AdditionalImages, profile.AdditionalImages, imageName)
+	cfg.Mirror.BlockedImages = mergeByName(cfg.Mirror.BlockedImages, profile.BlockedImages, blockedImageName)
+	return nil
+}
+
+// mergeOperator adds the operator to the mirror configuration. When the
+// catalog is already present, the profile packages replace the base
+// packages with the same name and are appended otherwise.
+func mergeOperator(mirror *v1alpha2.Mirror, op v1alpha2.Operator) error {
+	name, err := op.GetUniqueName()
+	if err != nil {
+		return err
+	}
+	for i, base := range mirror.Operators {
+		baseName, err := base.GetUniqueName()
+		if err != nil {
+			return err
+		}
+		if baseName != name {
+			continue
+		}
+		for _, pkg := range op.Packages {
+			replaced := false
+			for j, basePkg := range base.Packages {
+				if basePkg.Name == pkg.Name {
+					base.Packages[j] = pkg
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				base.Packages = append(base.Packages, pkg)
+			}
+		}
+		mirror.Operators[i] = base
+		return nil
+	}
+	mirror.Operators = append(mirror.Operators, op)
+	return nil
+}
+
+// mergeByName appends the elements of add whose name, as returned by
+// name, is not the name of an element of base or of a previous element.
+func mergeByName[T any](base, add []T, name func(T) string) []T {
+	seen := make(map[string]struct{}, len(base))
+	for _, e := range base {
+		seen[name(e)] = struct{}{}
+	}
+	for _, e := range add {
+		if _, ok := seen[name(e)]; ok {
+			continue
+		}
+		seen[name(e)] = struct{}{}
+		base = append(base, e)
+	}
+	return base
+}
+
+func imageName(img v1alpha2.Image) string { return img.Name }
+
+// blockedImageName identifies a blocked image by all of its fields,
+// as entries with the same name can block different images.
+func blockedImageName(img v1alpha2.BlockedImage) string { return fmt.Sprintf("%#v", img) }
+
+func sampleName(img v1alpha2.SampleImages) string { return img.Name }
+
+func artifactName(a v1alpha2.Artifact) string { return a.Name }
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestApplyProfile(t *testing.T) {
+	base := func() v1alpha2.ImageSetConfiguration {
+		return v1alpha2.ImageSetConfiguration{
+			ImageSetConfigurationSpec: v1alpha2.ImageSetConfigurationSpec{
+				Mirror: v1alpha2.Mirror{
+					Operators: []v1alpha2.Operator{
+						{
+							Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+							IncludeConfig: v1alpha2.IncludeConfig{
+								Packages: []v1alpha2.IncludePackage{
+									{Name: "aws-load-balancer-operator"},
+									{Name: "local-storage-operator"},
+								},
+							},
+						},
+					},
+					AdditionalImages: []v1alpha2.Image{
+						{Name: "registry.redhat.io/ubi8/ubi:latest"},
+					},
+				},
+				Profiles: map[string]v1alpha2.Profile{
+					"edge": {
+						Operators: []v1alpha2.Operator{
+							{
+								Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+								IncludeConfig: v1alpha2.IncludeConfig{
+									Packages: []v1alpha2.IncludePackage{
+										{Name: "local-storage-operator", Channels: []v1alpha2.IncludeChannel{{Name: "stable"}}},
+										{Name: "lvms-operator"},
+									},
+								},
+							},
+							{
+								Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.14",
+							},
+						},
+						AdditionalImages: []v1alpha2.Image{
+							{Name: "registry.redhat.io/ubi8/ubi:latest"},
+							{Name: "registry.redhat.io/ubi8/ubi-minimal:latest"},
+						},
+					},
+					"datacenter": {},
+				},
+			},
+		}
+	}
+
+	type spec struct {
+		name     string
+		profile  string
+		expected v1alpha2.Mirror
+		err      string
+	}
+
+	specs := []spec{
+		{
+			name:     "Valid/NoProfile",
+			expected: base().Mirror,
+		},
+		{
+			name:    "Valid/Edge",
+			profile: "edge",
+			expected: v1alpha2.Mirror{
+				Operators: []v1alpha2.Operator{
+					{
+						Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+						IncludeConfig: v1alpha2.IncludeConfig{
+							Packages: []v1alpha2.IncludePackage{
+								{Name: "aws-load-balancer-operator"},
+								{Name: "local-storage-operator", Channels: []v1alpha2.IncludeChannel{{Name: "stable"}}},
+								{Name: "lvms-operator"},
+							},
+						},
+					},
+					{
+						Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.14",
+					},
+				},
+				AdditionalImages: []v1alpha2.Image{
+					{Name: "registry.redhat.io/ubi8/ubi:latest"},
+					{Name: "registry.redhat.io/ubi8/ubi-minimal:latest"},
+				},
+			},
+		},
+		{
+			name:    "Invalid/UnknownProfile",
+			profile: "lab",
+			err:     `profile "lab" not found in configuration, available profiles: [datacenter, edge]`,
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			cfg := base()
+			err := ApplyProfile(&cfg, s.profile)
+			if s.err != "" {
+				require.EqualError(t, err, s.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, s.expected, cfg.Mirror)
+			require.Nil(t, cfg.Profiles)
+		})
+	}
+}
+
+func TestMergeByName(t *testing.T) {
+	base := []v1alpha2.Image{{Name: "a"}, {Name: "b"}}
+	add := []v1alpha2.Image{{Name: "b"}, {Name: "c"}, {Name: "c"}}
+	require.Equal(t, []v1alpha2.Image{{Name: "a"}, {Name: "b"}, {Name: "c"}}, mergeByName(base, add, imageName))
+
+	blocked := []v1alpha2.BlockedImage{{Name: "foo", Tags: []string{"latest"}}}
+	addBlocked := []v1alpha2.BlockedImage{{Name: "foo", Tags: []string{"latest"}}, {Name: "foo", Tags: []string{"v1"}}}
+	require.Equal(t, []v1alpha2.BlockedImage{{Name: "foo", Tags: []string{"latest"}}, {Name: "foo", Tags: []string{"v1"}}},
+		mergeByName(blocked, addBlocked, blockedImageName))
+}
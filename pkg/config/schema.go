@@ -0,0 +1,403 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// FieldError is an error of a field of an imageset
+// configuration, at its position in the YAML document.
+type FieldError struct {
+	// Line and Column locate the field in the document,
+	// 0 if the field is not found in the document.
+	Line   int
+	Column int
+	// Field is the path of the field, such as mirror.operators[0].catalog.
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.Field, e.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s: %s", e.Line, e.Column, e.Field, e.Message)
+}
+
+// SchemaErrors are the errors of an imageset configuration
+// not matching the schema of its version, sorted by position.
+type SchemaErrors []FieldError
+
+func (errs SchemaErrors) Error() string {
+	lines := make([]string, 0, len(errs)+1)
+	lines = append(lines, fmt.Sprintf("invalid configuration: %d schema errors:", len(errs)))
+	for _, err := range errs {
+		lines = append(lines, "  "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// exclusiveOptions are the options of an object of the configuration
+// that cannot be set together, keyed by the path of the object with
+// * matching any list index or map key.
+var exclusiveOptions = map[string][][2]string{
+	"mirror.operators.*": {
+		{"bundles", "packages"},
+		{"bundles", "full"},
+		{"bundles", "includeSuccessors"},
+		{"bundles", "baseImage"},
+	},
+	"mirror.operators.*.packages.*":            {{"minVersion", "minBundle"}},
+	"mirror.operators.*.packages.*.channels.*": {{"minVersion", "minBundle"}},
+	"profiles.*.operators.*": {
+		{"bundles", "packages"},
+		{"bundles", "full"},
+		{"bundles", "includeSuccessors"},
+		{"bundles", "baseImage"},
+	},
+	"profiles.*.operators.*.packages.*":            {{"minVersion", "minBundle"}},
+	"profiles.*.operators.*.packages.*.channels.*": {{"minVersion", "minBundle"}},
+}
+
+// schemaOverrides are the schemas of the types decoded by a custom
+// unmarshaler, whose accepted values cannot be derived from their Go type.
+var schemaOverrides = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(v1alpha2.PlatformType(0)): {"type": []string{"string", "null"}, "enum": []interface{}{"ocp", "okd", nil}},
+	reflect.TypeOf(v1alpha2.ImageType(0)):    {"type": []string{"string", "null"}},
+}
+
+var (
+	configType = reflect.TypeOf(v1alpha2.ImageSetConfiguration{})
+	// configSchema is the JSON schema of a v1alpha2 imageset configuration.
+	configSchema = gojsonschema.NewGoLoader(typeSchema(configType))
+)
+
+// ValidateSchema checks data, a v1alpha2 imageset configuration
+// document, against the schema of the configuration: unknown fields,
+// values of the wrong type and mutually exclusive options set together
+// are reported with their line and column. It returns an error if data
+// is not a YAML document, and nil if the document matches the schema.
+func ValidateSchema(data []byte) error {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %v", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return fmt.Errorf("invalid configuration: %v", err)
+	}
+	// Fields are matched case-insensitively, as encoding/json does.
+	doc = canonicalKeys(doc, configType)
+	result, err := gojsonschema.Validate(configSchema, gojsonschema.NewGoLoader(doc))
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %v", err)
+	}
+	// Errors are reported without position if the parsers disagree.
+	root := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(data, root); err != nil {
+		root = nil
+	}
+
+	var errs SchemaErrors
+	for _, resultErr := range result.Errors() {
+		path := splitField(resultErr.Field())
+		message := resultErr.Description()
+		switch resultErr.Type() {
+		case "additional_property_not_allowed":
+			property := fmt.Sprint(resultErr.Details()["property"])
+			path = append(path, property)
+			message = "unknown field"
+		case "invalid_type":
+			message = fmt.Sprintf("expected %s, got %s", strings.Join(schemaValues(resultErr.Details()["expected"]), " or "), resultErr.Details()["given"])
+		case "enum":
+			message = fmt.Sprintf("must be one of %s", strings.Join(schemaValues(resultErr.Details()["allowed"]), ", "))
+		}
+		errs = append(errs, newFieldError(root, path, message))
+	}
+	errs = append(errs, exclusiveOptionErrors(root, doc, nil)...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+	return errs
+}
+
+// schemaValues returns the values of the types or of the enum of a
+// gojsonschema error detail, such as [integer,null], without null,
+// as every field of the configuration can be null.
+func schemaValues(detail interface{}) []string {
+	var values []string
+	for _, value := range strings.Split(strings.Trim(fmt.Sprint(detail), "[]"), ",") {
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		if value != "" && value != "null" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// exclusiveOptionErrors returns the errors of the objects of doc, found
+// at path, setting options of exclusiveOptions together.
+func exclusiveOptionErrors(root *yamlv3.Node, doc interface{}, path []string) SchemaErrors {
+	var errs SchemaErrors
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for _, pair := range exclusiveOptions[strings.Join(wildcardPath(path), ".")] {
+			if isSet(v[pair[0]]) && isSet(v[pair[1]]) {
+				errs = append(errs, newFieldError(root, append(append([]string{}, path...), pair[1]),
+					fmt.Sprintf("cannot be set with %s", pair[0])))
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			errs = append(errs, exclusiveOptionErrors(root, v[key], append(append([]string{}, path...), key))...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			errs = append(errs, exclusiveOptionErrors(root, item, append(append([]string{}, path...), strconv.Itoa(i)))...)
+		}
+	}
+	return errs
+}
+
+// wildcardPath replaces the list indexes and the map keys of path by *.
+func wildcardPath(path []string) []string {
+	wildcard := make([]string, len(path))
+	for i, elem := range path {
+		_, err := strconv.Atoi(elem)
+		if err == nil || (i > 0 && path[i-1] == "profiles") {
+			elem = "*"
+		}
+		wildcard[i] = elem
+	}
+	return wildcard
+}
+
+// isSet returns true if v, a decoded JSON value, is not a zero value.
+func isSet(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) != 0
+	case map[string]interface{}:
+		return len(v) != 0
+	}
+	return true
+}
+
+// splitField splits a field of a gojsonschema error, such as
+// (root).mirror.operators.0, into its path elements.
+func splitField(field string) []string {
+	field = strings.TrimPrefix(strings.TrimPrefix(field, "(root)"), ".")
+	if field == "" {
+		return nil
+	}
+	return strings.Split(field, ".")
+}
+
+// newFieldError returns the error message of the field at path,
+// located in the document of root.
+func newFieldError(root *yamlv3.Node, path []string, message string) FieldError {
+	fieldErr := FieldError{Field: displayPath(path), Message: message}
+	if node := findNode(root, path); node != nil {
+		fieldErr.Line, fieldErr.Column = node.Line, node.Column
+	}
+	return fieldErr
+}
+
+// findNode returns the node of the field at path in the document
+// of root: the key node of map entries, the item node of list items.
+// When the field is not found, it returns the node of its closest parent.
+func findNode(root *yamlv3.Node, path []string) *yamlv3.Node {
+	if root == nil {
+		return nil
+	}
+	node := root
+	if node.Kind == yamlv3.DocumentNode && len(node.Content) != 0 {
+		node = node.Content[0]
+	}
+	found := node
+	for _, elem := range path {
+		for node.Kind == yamlv3.AliasNode && node.Alias != nil {
+			node = node.Alias
+		}
+		var next, pos *yamlv3.Node
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if strings.EqualFold(node.Content[i].Value, elem) {
+					pos, next = node.Content[i], node.Content[i+1]
+					if node.Content[i].Value == elem {
+						break
+					}
+				}
+			}
+		case yamlv3.SequenceNode:
+			if i, err := strconv.Atoi(elem); err == nil && i < len(node.Content) {
+				pos, next = node.Content[i], node.Content[i]
+			}
+		}
+		if next == nil {
+			return found
+		}
+		node, found = next, pos
+	}
+	return found
+}
+
+// displayPath formats path as a field, such as mirror.operators[0].catalog.
+func displayPath(path []string) string {
+	var b strings.Builder
+	for _, elem := range path {
+		if _, err := strconv.Atoi(elem); err == nil {
+			fmt.Fprintf(&b, "[%s]", elem)
+			continue
+		}
+		if b.Len() != 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(elem)
+	}
+	if b.Len() == 0 {
+		return "(root)"
+	}
+	return b.String()
+}
+
+// typeSchema returns the JSON schema of the values decoded into t
+// by encoding/json, where every field can also be null. Objects do
+// not allow unknown fields, as the configuration loader.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if override, found := schemaOverrides[t]; found {
+		return override
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": []string{"string", "null"}}
+	case reflect.Bool:
+		return map[string]interface{}{"type": []string{"boolean", "null"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": []string{"integer", "null"}}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": []string{"number", "null"}}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for name, fieldType := range structFields(t) {
+			properties[name] = typeSchema(fieldType)
+		}
+		return map[string]interface{}{"type": []string{"object", "null"}, "properties": properties, "additionalProperties": false}
+	}
+	return map[string]interface{}{}
+}
+
+// structFields returns the types of the fields of the struct t keyed by
+// JSON name, with the fields of the embedded structs without JSON name.
+func structFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for name, fieldType := range structFields(embedded) {
+					fields[name] = fieldType
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// canonicalKeys renames the keys of the objects of doc, a decoded JSON
+// value of type t, matching a field of t case-insensitively to the JSON
+// name of the field.
+func canonicalKeys(doc interface{}, t reflect.Type) interface{} {
+	if _, found := schemaOverrides[t]; found {
+		return doc
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Map:
+			for key, value := range v {
+				v[key] = canonicalKeys(value, t.Elem())
+			}
+		case reflect.Struct:
+			fields := structFields(t)
+			canonical := make(map[string]interface{}, len(v))
+			for key, value := range v {
+				name, fieldType := key, reflect.Type(nil)
+				if ft, found := fields[key]; found {
+					fieldType = ft
+				} else {
+					for fieldName, ft := range fields {
+						if strings.EqualFold(fieldName, key) {
+							name, fieldType = fieldName, ft
+							break
+						}
+					}
+				}
+				if fieldType != nil {
+					value = canonicalKeys(value, fieldType)
+				}
+				canonical[name] = value
+			}
+			return canonical
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range v {
+				v[i] = canonicalKeys(item, t.Elem())
+			}
+		}
+	}
+	return doc
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchema(t *testing.T) {
+	type spec struct {
+		name      string
+		config    string
+		expErrors []string
+	}
+
+	cases := []spec{
+		{
+			name: "Valid/Minimal",
+			config: `apiVersion: mirror.openshift.io/v1alpha2
+kind: ImageSetConfiguration
+mirror:
+  platform:
+    channels:
+    - name: stable-4.14
+      type: ocp
+`,
+		},
+		{
+			name: "Valid/CaseInsensitiveFields",
+			config: `apiVersion: mirror.openshift.io/v1alpha2
+kind: ImageSetConfiguration
+mirror:
+  additionalimages:
+  - name: registry.example.com/app:v1
+`,
+		},
+		{
+			name: "Invalid/UnknownField",
+			config: `apiVersion: mirror.openshift.io/v1alpha2
+kind: ImageSetConfiguration
+mirror:
+  operators:
+  - catalog: registry.example.com/catalog:v4.14
+    packages:
+    - name: etcd
+      chanels:
+      - name: stable
+`,
+			expErrors: []string{"line 8, column 7: mirror.operators[0].packages[0].chanels: unknown field"},
+		},
+		{
+			name: "Invalid/TypeMismatch",
+			config: `apiVersion: mirror.openshift.io/v1alpha2
+kind: ImageSetConfiguration
+archiveSize: large
+mirror:
+  platform:
+    graph: "yes"
+    channels:
+    - name: stable-4.14
+      type: rhel
+`,
+			expErrors: []string{
+				"line 3, column 1: archiveSize: expected integer, got string",
+				"line 6, column 5: mirror.platform.graph: expected boolean, got string",
+				"line 9, column 7: mirror.platform.channels[0].type: must be one of ocp, okd",
+			},
+		},
+		{
+			name: "Invalid/ExclusiveOptions",
+			config: `apiVersion: mirror.openshift.io/v1alpha2
+kind: ImageSetConfiguration
+mirror:
+  operators:
+  - catalog: registry.example.com/catalog:v4.14
+    targetCatalog: custom/catalog
+    bundles:
+    - registry.example.com/bundle@sha256:1111111111111111111111111111111111111111111111111111111111111111
+    full: true
+    packages:
+    - name: etcd
+      minVersion: 1.0.0
+      minBundle: etcd.v1.0.0
+`,
+			expErrors: []string{
+				"line 9, column 5: mirror.operators[0].full: cannot be set with bundles",
+				"line 10, column 5: mirror.operators[0].packages: cannot be set with bundles",
+				"line 13, column 7: mirror.operators[0].packages[0].minBundle: cannot be set with minVersion",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateSchema([]byte(c.config))
+			if len(c.expErrors) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			var schemaErrs SchemaErrors
+			require.True(t, errors.As(err, &schemaErrs), "unexpected error: %v", err)
+			var messages []string
+			for _, fieldErr := range schemaErrs {
+				messages = append(messages, fieldErr.Error())
+			}
+			require.Equal(t, c.expErrors, messages)
+		})
+	}
+}
+
+func TestReadConfigSchemaErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "imageset-config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`apiVersion: mirror.openshift.io/v1alpha2
+kind: ImageSetConfiguration
+mirror:
+  additionalImages:
+  - name: registry.example.com/app:v1
+    tag: v1
+`), 0600))
+	_, err := ReadConfig(path)
+	require.EqualError(t, err, "invalid configuration: 1 schema errors:\n  line 6, column 5: mirror.additionalImages[0].tag: unknown field")
+
+	require.NoError(t, os.WriteFile(path, []byte("apiVersion: mirror.openshift.io/v2alpha1\nkind: ImageSetConfiguration\n"), 0600))
+	_, err = ReadConfig(path)
+	require.ErrorContains(t, err, "v2alpha1 configurations are used with --v2")
+}
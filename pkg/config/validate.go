@@ -2,7 +2,13 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
 
+	"github.com/blang/semver/v4"
+	"github.com/opencontainers/go-digest"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
@@ -10,7 +16,7 @@ import (
 
 type validationFunc func(cfg *v1alpha2.ImageSetConfiguration) error
 
-var validationChecks = []validationFunc{validateOperatorOptions, validateReleaseChannels}
+var validationChecks = []validationFunc{validateOperatorOptions, validateReleaseChannels, validateNotifications, validateProxy, validateArchiveCompression, validateArtifacts, validateNamespaceMappings, validateBlockedImages}
 
 // Validate will check an ImagesetConfiguration for input errors.
 func Validate(cfg *v1alpha2.ImageSetConfiguration) error {
@@ -36,6 +42,96 @@ func validateOperatorOptions(cfg *v1alpha2.ImageSetConfiguration) error {
 			)
 		}
 		seen[ctlgName] = true
+		if ctlg.IsBundleList() {
+			if err := validateBundleList(ctlg); err != nil {
+				return fmt.Errorf("catalog %q: %v", ctlgName, err)
+			}
+		}
+		if err := validateFBCDir(ctlg); err != nil {
+			return fmt.Errorf("catalog %q: %v", ctlg.Catalog, err)
+		}
+		if err := validateSelectedBundles(ctlg); err != nil {
+			return fmt.Errorf("catalog %q: %v", ctlgName, err)
+		}
+		if ctlg.ExcludeIncompatible && len(cfg.Mirror.Platform.Channels) == 0 {
+			return fmt.Errorf("catalog %q: excludeIncompatible requires release channels in the platform section", ctlgName)
+		}
+	}
+	return nil
+}
+
+// validateFBCDir checks the options of a catalog read from a file-based
+// catalog directory, built on BaseImage since it has no image of its own.
+func validateFBCDir(ctlg v1alpha2.Operator) error {
+	switch {
+	case !ctlg.IsFBCDir() && ctlg.BaseImage != "":
+		return fmt.Errorf("baseImage only applies to dir:// catalogs")
+	case !ctlg.IsFBCDir():
+		return nil
+	case v1alpha2.TrimProtocol(ctlg.Catalog) == "":
+		return fmt.Errorf("dir:// catalogs require the path of the directory")
+	case ctlg.BaseImage == "":
+		return fmt.Errorf("dir:// catalogs require baseImage to build the catalog on")
+	case ctlg.TargetCatalog == "" && ctlg.TargetName == "":
+		return fmt.Errorf("dir:// catalogs require targetCatalog to name the catalog")
+	}
+	return nil
+}
+
+// validateSelectedBundles checks the packages selecting bundles by name,
+// which replace the version and channel selection of the package.
+func validateSelectedBundles(ctlg v1alpha2.Operator) error {
+	for _, pkg := range ctlg.Packages {
+		if len(pkg.SelectedBundles) == 0 {
+			continue
+		}
+		switch {
+		case pkg.MinVersion != "" || pkg.MaxVersion != "" || pkg.MinBundle != "":
+			return fmt.Errorf("package %q: selectedBundles cannot be combined with minVersion, maxVersion or minBundle", pkg.Name)
+		case len(pkg.Channels) != 0:
+			return fmt.Errorf("package %q: selectedBundles cannot be combined with channels", pkg.Name)
+		}
+		seen := map[string]bool{}
+		for _, b := range pkg.SelectedBundles {
+			if b.Name == "" {
+				return fmt.Errorf("package %q: selectedBundles require a name", pkg.Name)
+			}
+			if seen[b.Name] {
+				return fmt.Errorf("package %q: selected bundle %q: duplicate found in configuration", pkg.Name, b.Name)
+			}
+			seen[b.Name] = true
+		}
+	}
+	return nil
+}
+
+// validateBundleList checks the options of a catalog generated from
+// a list of bundles. Options selecting content of the base catalog do
+// not apply, and the generated catalog must not replace the base one.
+func validateBundleList(ctlg v1alpha2.Operator) error {
+	switch {
+	case ctlg.IsFBCOCI():
+		return fmt.Errorf("bundles cannot be built on an oci catalog")
+	case ctlg.IsFBCDir():
+		return fmt.Errorf("bundles cannot be combined with a dir catalog")
+	case ctlg.TargetCatalog == "" && ctlg.TargetName == "":
+		return fmt.Errorf("bundles require targetCatalog to name the generated catalog")
+	case len(ctlg.Packages) != 0:
+		return fmt.Errorf("bundles cannot be combined with packages")
+	case ctlg.Full:
+		return fmt.Errorf("bundles cannot be combined with full")
+	case ctlg.IncludeSuccessors:
+		return fmt.Errorf("bundles cannot be combined with includeSuccessors")
+	}
+	seen := map[string]bool{}
+	for _, bundle := range ctlg.Bundles {
+		if bundle == "" {
+			return fmt.Errorf("bundles cannot contain empty references")
+		}
+		if seen[bundle] {
+			return fmt.Errorf("bundle %q: duplicate found in configuration", bundle)
+		}
+		seen[bundle] = true
 	}
 	return nil
 }
@@ -49,6 +145,206 @@ func validateReleaseChannels(cfg *v1alpha2.ImageSetConfiguration) error {
 			)
 		}
 		seen[channel.Name] = true
+		if err := validateGraphURL(channel.GraphURL); err != nil {
+			return fmt.Errorf("release channel %q: graphURL: %v", channel.Name, err)
+		}
+		if channel.GraphURL != cfg.Mirror.Platform.Channels[0].GraphURL {
+			return fmt.Errorf("release channels with different graphURLs cannot be mirrored together")
+		}
+		switch {
+		case channel.PromotedIn == "":
+		case channel.PromotedIn == channel.Name:
+			return fmt.Errorf("release channel %q: promotedIn must name another channel", channel.Name)
+		case channel.Type == v1alpha2.TypeOKD:
+			return fmt.Errorf("release channel %q: promotedIn is not supported for okd channels", channel.Name)
+		}
+	}
+	if err := validateGraphURL(cfg.Mirror.Platform.GraphDataURL); err != nil {
+		return fmt.Errorf("platform graphDataURL: %v", err)
+	}
+	if err := validatePlatformSamples(cfg.Mirror.Platform.Samples); err != nil {
+		return fmt.Errorf("platform samples: %v", err)
+	}
+	return nil
+}
+
+// validatePlatformSamples checks the release version and
+// the imagestream names of the sample imagestreams, if set.
+func validatePlatformSamples(samples *v1alpha2.PlatformSamples) error {
+	if samples == nil {
+		return nil
+	}
+	if samples.Release != "" {
+		if _, err := semver.Parse(samples.Release); err != nil {
+			return fmt.Errorf("invalid release version %q: %v", samples.Release, err)
+		}
+	}
+	seen := map[string]bool{}
+	for _, name := range samples.ImageStreams {
+		if name == "" {
+			return fmt.Errorf("imagestream names must not be empty")
+		}
+		if seen[name] {
+			return fmt.Errorf("imagestream %q: duplicate found in configuration", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// validateGraphURL checks that value, if set, is an http or https
+// URL, or a file:// path for offline graph data.
+func validateGraphURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	switch {
+	case u.Scheme == "file" && u.Path != "":
+		return nil
+	case (u.Scheme == "http" || u.Scheme == "https") && u.Host != "":
+		return nil
+	}
+	return fmt.Errorf("%q must be an http, https or file URL", value)
+}
+
+func validateNotifications(cfg *v1alpha2.ImageSetConfiguration) error {
+	if !cfg.Notifications.IsSet() {
+		return nil
+	}
+	endpoints := []struct{ name, value string }{
+		{"webhook", cfg.Notifications.Webhook},
+		{"slack", cfg.Notifications.Slack},
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.value == "" {
+			continue
+		}
+		u, err := url.Parse(endpoint.value)
+		if err != nil {
+			return fmt.Errorf("notifications %s: %v", endpoint.name, err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("notifications %s %q: must be an http or https URL", endpoint.name, endpoint.value)
+		}
+	}
+	return nil
+}
+
+func validateProxy(cfg *v1alpha2.ImageSetConfiguration) error {
+	if !cfg.Proxy.IsSet() {
+		return nil
+	}
+	proxies := []struct{ name, value string }{
+		{"httpProxy", cfg.Proxy.HTTPProxy},
+		{"httpsProxy", cfg.Proxy.HTTPSProxy},
+	}
+	for _, proxy := range proxies {
+		if proxy.value == "" {
+			continue
+		}
+		// The URL may hold credentials, do not print it.
+		u, err := url.Parse(os.ExpandEnv(proxy.value))
+		if err != nil {
+			return fmt.Errorf("proxy %s: invalid URL", proxy.name)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("proxy %s: must be an http or https URL", proxy.name)
+		}
+	}
+	return nil
+}
+
+func validateArchiveCompression(cfg *v1alpha2.ImageSetConfiguration) error {
+	if cfg.ArchiveCompression == nil {
+		return nil
+	}
+	var maxLevel int
+	switch cfg.ArchiveCompression.Algorithm {
+	case v1alpha2.CompressionZstd:
+		maxLevel = 22
+	case v1alpha2.CompressionGzip:
+		maxLevel = 9
+	default:
+		return fmt.Errorf("archiveCompression algorithm %q: must be %s or %s",
+			cfg.ArchiveCompression.Algorithm, v1alpha2.CompressionZstd, v1alpha2.CompressionGzip)
+	}
+	if level := cfg.ArchiveCompression.Level; level < 0 || level > maxLevel {
+		return fmt.Errorf("archiveCompression level %d: must be between 1 and %d for %s",
+			level, maxLevel, cfg.ArchiveCompression.Algorithm)
+	}
+	return nil
+}
+
+func validateArtifacts(cfg *v1alpha2.ImageSetConfiguration) error {
+	seen := map[string]bool{}
+	for _, artifact := range cfg.Mirror.Artifacts {
+		if artifact.Name == "" {
+			return fmt.Errorf("artifacts cannot contain empty names")
+		}
+		if seen[artifact.Name] {
+			return fmt.Errorf("artifact %q: duplicate found in configuration", artifact.Name)
+		}
+		seen[artifact.Name] = true
+	}
+	return nil
+}
+
+// namespaceRegexp matches the namespaces of a repository path,
+// lowercase path components separated by slashes.
+var namespaceRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[._-][a-z0-9]+)*(?:/[a-z0-9]+(?:[._-][a-z0-9]+)*)*$`)
+
+func validateNamespaceMappings(cfg *v1alpha2.ImageSetConfiguration) error {
+	seen := map[string]bool{}
+	for _, m := range cfg.Mirror.NamespaceMappings {
+		if m.Source == "" {
+			return fmt.Errorf("namespace mapping: source must be set")
+		}
+		if _, err := path.Match(m.Source, ""); err != nil {
+			return fmt.Errorf("namespace mapping %q: invalid source: %v", m.Source, err)
+		}
+		if seen[m.Source] {
+			return fmt.Errorf("namespace mapping %q: duplicate found in configuration", m.Source)
+		}
+		seen[m.Source] = true
+		if !namespaceRegexp.MatchString(m.Namespace) {
+			return fmt.Errorf("namespace mapping %q: invalid namespace %q", m.Source, m.Namespace)
+		}
+	}
+	return nil
+}
+
+func validateBlockedImages(cfg *v1alpha2.ImageSetConfiguration) error {
+	for i, b := range cfg.Mirror.BlockedImages {
+		if b.Name == "" && !b.IsPattern() {
+			return fmt.Errorf("blocked image %d: name, registry, namespace, repository, tags or digests must be set", i)
+		}
+		if b.Name != "" {
+			if _, err := regexp.Compile(b.Name); err != nil {
+				return fmt.Errorf("blocked image %d: invalid name %q: %v", i, b.Name, err)
+			}
+		}
+		globs := []struct{ field, pattern string }{
+			{"registry", b.Registry},
+			{"namespace", b.Namespace},
+			{"repository", b.Repository},
+		}
+		for _, tag := range b.Tags {
+			globs = append(globs, struct{ field, pattern string }{"tag", tag})
+		}
+		for _, g := range globs {
+			if _, err := path.Match(g.pattern, ""); err != nil {
+				return fmt.Errorf("blocked image %d: invalid %s %q: %v", i, g.field, g.pattern, err)
+			}
+		}
+		for _, d := range b.Digests {
+			if _, err := digest.Parse(d); err != nil {
+				return fmt.Errorf("blocked image %d: invalid digest %q: %v", i, d, err)
+			}
+		}
 	}
 	return nil
 }
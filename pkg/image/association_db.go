@@ -0,0 +1,465 @@
+package image
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// AssociationIterator iterates over image Associations
+// image by image.
+type AssociationIterator interface {
+	// ForEach calls fn with the Associations of each image
+	// until fn returns an error.
+	ForEach(fn func(imageName string, assocs Associations) error) error
+}
+
+var (
+	// imagesBucket holds a bucket per image, holding
+	// the JSON encoded Associations of the image keyed by name.
+	imagesBucket = []byte("images")
+	// stagingBucket and visitedBucket hold the Associations
+	// being converted by ConvertToAssociationDB and DecodeStreamed.
+	stagingBucket = []byte("staging")
+	visitedBucket = []byte("visited")
+	visitedMark   = []byte{1}
+)
+
+// AssociationDB is a set of image Associations mapped to their images,
+// like AssociationSet, stored in a bbolt database on disk instead of in
+// memory, for metadata with tens of thousands of images. The database is
+// a working copy of Associations recorded in metadata, the metadata stays
+// the record: OpenAssociationDB creates it empty and Close removes it.
+type AssociationDB struct {
+	db *bolt.DB
+}
+
+// OpenAssociationDB creates an empty AssociationDB at path,
+// replacing any database left there by a previous run.
+func OpenAssociationDB(path string) (*AssociationDB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	// The database is removed on close, so it is not synced to disk.
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, NoSync: true, NoFreelistSync: true})
+	if err != nil {
+		return nil, fmt.Errorf("error opening image associations database: %v", err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucket(imagesBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating image associations database: %v", err)
+	}
+	return &AssociationDB{db: db}, nil
+}
+
+// stagingBatchSize is the number of Associations staged, grouped
+// or copied per transaction, so that the pages written by a
+// transaction are never those of the whole database.
+const stagingBatchSize = 1000
+
+// ConvertToAssociationDB will return an AssociationDB at path from a slice of
+// Associations, grouping them like ConvertToAssociationSet.
+func ConvertToAssociationDB(path string, assocs []v1alpha2.Association) (*AssociationDB, error) {
+	var errs []error
+	for _, a := range assocs {
+		if err := a.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	as, err := openStagingDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := as.stage(assocs); err != nil {
+		as.Close()
+		return nil, err
+	}
+	if err := as.group(); err != nil {
+		as.Close()
+		return nil, err
+	}
+	return as, nil
+}
+
+// openStagingDB creates an empty AssociationDB at path
+// with the buckets staging Associations to group.
+func openStagingDB(path string) (*AssociationDB, error) {
+	as, err := OpenAssociationDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := as.db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucket(stagingBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(visitedBucket)
+		return err
+	}); err != nil {
+		as.Close()
+		return nil, err
+	}
+	return as, nil
+}
+
+// stage adds assocs to the staging bucket.
+func (as *AssociationDB) stage(assocs []v1alpha2.Association) error {
+	return as.db.Update(func(tx *bolt.Tx) error {
+		staging := tx.Bucket(stagingBucket)
+		// The association name itself is not unique because images can
+		// share child manifests so using the name and path/image as the
+		// key for unique combination.
+		for _, a := range assocs {
+			if err := putAssociation(staging, stagingKey(a.Name, a.Path), a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// group moves the staged Associations to the bucket of their image,
+// the child manifests with the index manifest referencing them, and
+// removes the staging buckets. The Associations are moved batch by batch,
+// each batch committed in its own transaction.
+func (as *AssociationDB) group() error {
+	// Process all index manifests and their child image manifests
+	if err := as.forEachStaged(func(tx *bolt.Tx, value v1alpha2.Association) error {
+		if len(value.ManifestDigests) == 0 {
+			return nil
+		}
+		staging := tx.Bucket(stagingBucket)
+		visited := tx.Bucket(visitedBucket)
+		children := make([]v1alpha2.Association, 0, len(value.ManifestDigests))
+		for _, digest := range value.ManifestDigests {
+			klog.V(4).Infof("image %s: processing child manifest %s", value.Name, digest)
+			data := staging.Get(stagingKey(digest, value.Path))
+			if data == nil {
+				return fmt.Errorf("invalid associations: association for %q is missing", digest)
+			}
+			var child v1alpha2.Association
+			if err := json.Unmarshal(data, &child); err != nil {
+				return err
+			}
+			children = append(children, child)
+			if err := visited.Put([]byte(child.Name), visitedMark); err != nil {
+				return err
+			}
+		}
+		if err := addAssociations(tx, value.Name, append([]v1alpha2.Association{value}, children...)...); err != nil {
+			return err
+		}
+		return visited.Put([]byte(value.Name), visitedMark)
+	}); err != nil {
+		return err
+	}
+
+	// Process image manifests with no parent
+	if err := as.forEachStaged(func(tx *bolt.Tx, value v1alpha2.Association) error {
+		if tx.Bucket(visitedBucket).Get([]byte(value.Name)) != nil {
+			return nil
+		}
+		return addAssociations(tx, value.Name, value)
+	}); err != nil {
+		return err
+	}
+
+	return as.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(stagingBucket); err != nil {
+			return err
+		}
+		return tx.DeleteBucket(visitedBucket)
+	})
+}
+
+// forEachStaged calls fn with each staged Association, in
+// transactions of stagingBatchSize Associations.
+func (as *AssociationDB) forEachStaged(fn func(tx *bolt.Tx, value v1alpha2.Association) error) error {
+	var last []byte
+	for done := false; !done; {
+		if err := as.db.Update(func(tx *bolt.Tx) error {
+			c := tx.Bucket(stagingBucket).Cursor()
+			k, data := c.First()
+			if last != nil {
+				if k, data = c.Seek(last); k != nil && bytes.Equal(k, last) {
+					k, data = c.Next()
+				}
+			}
+			for n := 0; k != nil && n < stagingBatchSize; k, data = c.Next() {
+				var value v1alpha2.Association
+				if err := json.Unmarshal(data, &value); err != nil {
+					return err
+				}
+				if err := fn(tx, value); err != nil {
+					return err
+				}
+				last = append(last[:0], k...)
+				n++
+			}
+			done = k == nil
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add stores a key-value pair in the database.
+func (as *AssociationDB) Add(key string, values ...v1alpha2.Association) error {
+	return as.db.Update(func(tx *bolt.Tx) error {
+		return addAssociations(tx, key, values...)
+	})
+}
+
+// Search will return all Associations for the specified key
+func (as *AssociationDB) Search(key string) (values []v1alpha2.Association, found bool, err error) {
+	err = as.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(imagesBucket).Bucket([]byte(key))
+		if b == nil {
+			return nil
+		}
+		found = true
+		return b.ForEach(func(_, data []byte) error {
+			var value v1alpha2.Association
+			if err := json.Unmarshal(data, &value); err != nil {
+				return err
+			}
+			values = append(values, value)
+			return nil
+		})
+	})
+	return values, found, err
+}
+
+// SetContainsKey checks if the database contains a key
+func (as *AssociationDB) SetContainsKey(key string) (found bool, err error) {
+	err = as.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(imagesBucket).Bucket([]byte(key)) != nil
+		return nil
+	})
+	return found, err
+}
+
+// ContainsKey checks if the Associations of setKey contain the specified key
+func (as *AssociationDB) ContainsKey(setKey, key string) (found bool, err error) {
+	err = as.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(imagesBucket).Bucket([]byte(setKey)); b != nil {
+			found = b.Get([]byte(key)) != nil
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Len returns the number of keys of the database.
+func (as *AssociationDB) Len() (n int, err error) {
+	err = as.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(imagesBucket).ForEach(func(_, _ []byte) error {
+			n++
+			return nil
+		})
+	})
+	return n, err
+}
+
+// ForEach calls fn with the Associations of each key, reading them
+// from disk key by key. fn must not modify the database.
+func (as *AssociationDB) ForEach(fn func(imageName string, assocs Associations) error) error {
+	return as.db.View(func(tx *bolt.Tx) error {
+		images := tx.Bucket(imagesBucket)
+		return images.ForEach(func(key, _ []byte) error {
+			assocs := Associations{}
+			if err := images.Bucket(key).ForEach(func(name, data []byte) error {
+				var value v1alpha2.Association
+				if err := json.Unmarshal(data, &value); err != nil {
+					return err
+				}
+				assocs[string(name)] = value
+				return nil
+			}); err != nil {
+				return err
+			}
+			return fn(string(key), assocs)
+		})
+	})
+}
+
+// Prune will return an AssociationDB at path containing the provided keys,
+// copied from the database key by key, in transactions of stagingBatchSize keys.
+func (as *AssociationDB) Prune(path string, keepKey []string) (*AssociationDB, error) {
+	pruned, err := OpenAssociationDB(path)
+	if err != nil {
+		return nil, err
+	}
+	for start := 0; start < len(keepKey); start += stagingBatchSize {
+		batch := keepKey[start:min(start+stagingBatchSize, len(keepKey))]
+		if err := as.db.View(func(src *bolt.Tx) error {
+			return pruned.db.Update(func(dst *bolt.Tx) error {
+				for _, key := range batch {
+					b := src.Bucket(imagesBucket).Bucket([]byte(key))
+					if b == nil {
+						return fmt.Errorf("key %s does not exist in provided associations", key)
+					}
+					kept, err := dst.Bucket(imagesBucket).CreateBucketIfNotExists([]byte(key))
+					if err != nil {
+						return fmt.Errorf("image %q: %v", key, err)
+					}
+					if err := b.ForEach(kept.Put); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}); err != nil {
+			pruned.Close()
+			return nil, err
+		}
+	}
+	return pruned, nil
+}
+
+// Keys returns the keys of the database, in order.
+func (as *AssociationDB) Keys() (keys []string, err error) {
+	err = as.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(imagesBucket).ForEach(func(key, _ []byte) error {
+			keys = append(keys, string(key))
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// UpdateValues replaces each Association of the database by the Association
+// fn returns for it, under the same key. Keys are updated in transactions
+// of stagingBatchSize keys.
+func (as *AssociationDB) UpdateValues(fn func(imageName string, value v1alpha2.Association) v1alpha2.Association) error {
+	keys, err := as.Keys()
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(keys); start += stagingBatchSize {
+		batch := keys[start:min(start+stagingBatchSize, len(keys))]
+		if err := as.db.Update(func(tx *bolt.Tx) error {
+			images := tx.Bucket(imagesBucket)
+			for _, key := range batch {
+				var values []v1alpha2.Association
+				if err := images.Bucket([]byte(key)).ForEach(func(_, data []byte) error {
+					var value v1alpha2.Association
+					if err := json.Unmarshal(data, &value); err != nil {
+						return err
+					}
+					values = append(values, fn(key, value))
+					return nil
+				}); err != nil {
+					return err
+				}
+				if err := images.DeleteBucket([]byte(key)); err != nil {
+					return err
+				}
+				if err := addAssociations(tx, key, values...); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Merge adds the Associations of in to the database.
+func (as *AssociationDB) Merge(in AssociationSet) error {
+	return as.db.Update(func(tx *bolt.Tx) error {
+		for imageName, assocs := range in {
+			for _, value := range assocs {
+				if err := addAssociations(tx, imageName, value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Validate the database and all contained Associations
+func (as *AssociationDB) Validate() error {
+	var errs []error
+	if err := as.ForEach(func(imageName string, assocs Associations) error {
+		errs = append(errs, validateAssociations(imageName, assocs)...)
+		return nil
+	}); err != nil {
+		return err
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Close closes and removes the database. Closing
+// a nil AssociationDB does nothing.
+func (as *AssociationDB) Close() error {
+	if as == nil {
+		return nil
+	}
+	path := as.db.Path()
+	if err := as.db.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func addAssociations(tx *bolt.Tx, key string, values ...v1alpha2.Association) error {
+	b, err := tx.Bucket(imagesBucket).CreateBucketIfNotExists([]byte(key))
+	if err != nil {
+		return fmt.Errorf("image %q: %v", key, err)
+	}
+	for _, value := range values {
+		if err := putAssociation(b, []byte(value.Name), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putAssociation(b *bolt.Bucket, key []byte, value v1alpha2.Association) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, data)
+}
+
+func stagingKey(name, path string) []byte {
+	return []byte(name + "\x00" + path)
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("error decoding image associations: %v", err)
+	}
+	if tok != delim {
+		return fmt.Errorf("error decoding image associations: expected %v, found %v", delim, tok)
+	}
+	return nil
+}
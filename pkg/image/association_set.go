@@ -103,6 +103,17 @@ func (as AssociationSet) ContainsKey(setKey, key string) (found bool) {
 	return
 }
 
+// ForEach calls fn with the Associations of each key
+// until fn returns an error.
+func (as AssociationSet) ForEach(fn func(imageName string, assocs Associations) error) error {
+	for imageName, assocs := range as {
+		if err := fn(imageName, assocs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Merge Associations into the receiver.
 func (as AssociationSet) Merge(in AssociationSet) {
 	for imageName, assocs := range in {
@@ -162,43 +173,50 @@ func (as *AssociationSet) UpdatePath() error {
 func (as AssociationSet) Validate() error {
 	var errs []error
 	for imageName, assocs := range as {
-		for _, assoc := range assocs {
-			if len(assoc.ManifestDigests) != 0 {
-				for _, digest := range assoc.ManifestDigests {
-					if _, found := assocs[digest]; !found {
-						errs = append(errs, fmt.Errorf("image %q: digest %s not found", imageName, digest))
-						continue
-					}
+		errs = append(errs, validateAssociations(imageName, assocs)...)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateAssociations validates the Associations of the image imageName.
+func validateAssociations(imageName string, assocs Associations) (errs []error) {
+	for _, assoc := range assocs {
+		if len(assoc.ManifestDigests) != 0 {
+			for _, digest := range assoc.ManifestDigests {
+				if _, found := assocs[digest]; !found {
+					errs = append(errs, fmt.Errorf("image %q: digest %s not found", imageName, digest))
+					continue
 				}
 			}
-			if err := assoc.Validate(); err != nil {
-				errs = append(errs, err)
-			}
+		}
+		if err := assoc.Validate(); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	return utilerrors.NewAggregate(errs)
+	return errs
 }
 
-// GetDigests will return all layer and manifest digests in the AssociationSet
-func (as *AssociationSet) GetDigests() []string {
+// GetDigests will return all layer and manifest digests of the Associations
+func GetDigests(as AssociationIterator) ([]string, error) {
 	var digests []string
-	for _, assocs := range *as {
+	err := as.ForEach(func(_ string, assocs Associations) error {
 		for _, assoc := range assocs {
 			digests = append(digests, assoc.LayerDigests...)
 			digests = append(digests, assoc.ManifestDigests...)
 			digests = append(digests, assoc.ID)
 		}
-	}
-	return digests
+		return nil
+	})
+	return digests, err
 }
 
 // AssocPathsForBlobs returns a map with the first association path found
 // for each layer digest in the Association Set. This can be used
 // to pull layers to reform images. As defined in the Association spec,
 // the path can be a local or remote reference.
-func AssocPathsForBlobs(as AssociationSet) map[string]string {
+func AssocPathsForBlobs(as AssociationIterator) (map[string]string, error) {
 	reposByBlob := map[string]string{}
-	for _, assocs := range as {
+	err := as.ForEach(func(_ string, assocs Associations) error {
 		for _, assoc := range assocs {
 			for _, dgst := range assoc.LayerDigests {
 				if _, found := reposByBlob[dgst]; found {
@@ -207,8 +225,9 @@ func AssocPathsForBlobs(as AssociationSet) map[string]string {
 				reposByBlob[dgst] = assoc.Path
 			}
 		}
-	}
-	return reposByBlob
+		return nil
+	})
+	return reposByBlob, err
 }
 
 // Prune will return a pruned AssociationSet containing provided keys
@@ -83,7 +83,8 @@ func TestSearch(t *testing.T) {
 
 func TestGetDigests(t *testing.T) {
 	asSet := makeTestAssocationSet()
-	digests := asSet.GetDigests()
+	digests, err := GetDigests(asSet)
+	require.NoError(t, err)
 	require.Len(t, digests, 2)
 }
 
@@ -110,7 +111,8 @@ func TestAdd(t *testing.T) {
 
 func TestReposForBlobs(t *testing.T) {
 	asSet := makeTestAssocationSet()
-	ref := AssocPathsForBlobs(asSet)
+	ref, err := AssocPathsForBlobs(asSet)
+	require.NoError(t, err)
 	exp := map[string]string{
 		"test-layer": "test",
 	}
@@ -0,0 +1,383 @@
+package image
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// JSON paths of the Association arrays of the metadata.
+const (
+	PastAssociationsField     = "pastAssociations"
+	MirroredAssociationsField = "pastMirror.associations"
+)
+
+// StreamedAssociations maps the JSON paths of the Association arrays
+// of a document to the AssociationDB they were streamed into.
+type StreamedAssociations map[string]*AssociationDB
+
+// Close closes and removes all databases.
+func (s StreamedAssociations) Close() error {
+	var errs []error
+	for _, db := range s {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// Iterators returns the databases as the iterators
+// of their paths, to be encoded with EncodeStreamed.
+func (s StreamedAssociations) Iterators() map[string]AssociationIterator {
+	iters := make(map[string]AssociationIterator, len(s))
+	for field, db := range s {
+		if db != nil {
+			iters[field] = db
+		}
+	}
+	return iters
+}
+
+// DecodeStreamed reads the JSON document of r and streams the Association
+// arrays at the paths of dbPaths into an AssociationDB at the mapped path,
+// batch by batch, grouping them like ConvertToAssociationSet, so that they
+// are never all held in memory. Arrays at a path mapped to "" are discarded.
+// The rest of the document is returned, to be decoded by the caller. Each
+// path mapped to a database path gets a database, empty when the document
+// has no array at the path.
+func DecodeStreamed(r io.Reader, dbPaths map[string]string) ([]byte, StreamedAssociations, error) {
+	s := &streamDecoder{
+		dec:   json.NewDecoder(bufio.NewReader(r)),
+		paths: dbPaths,
+		dbs:   StreamedAssociations{},
+	}
+	for field, path := range dbPaths {
+		if path == "" {
+			continue
+		}
+		db, err := openStagingDB(path)
+		if err != nil {
+			s.dbs.Close()
+			return nil, nil, err
+		}
+		s.dbs[field] = db
+	}
+	var rest bytes.Buffer
+	if err := s.value(&rest, ""); err != nil {
+		s.dbs.Close()
+		return nil, nil, err
+	}
+	if len(s.errs) != 0 {
+		s.dbs.Close()
+		return nil, nil, utilerrors.NewAggregate(s.errs)
+	}
+	for _, db := range s.dbs {
+		if err := db.group(); err != nil {
+			s.dbs.Close()
+			return nil, nil, err
+		}
+	}
+	return rest.Bytes(), s.dbs, nil
+}
+
+// streamDecoder copies a JSON document but the
+// Association arrays it streams into databases.
+type streamDecoder struct {
+	dec   *json.Decoder
+	paths map[string]string
+	dbs   StreamedAssociations
+	errs  []error
+}
+
+// value copies the value at path. Objects holding a streamed
+// array are walked field by field, other values are copied whole.
+func (s *streamDecoder) value(buf *bytes.Buffer, path string) error {
+	if !holdsField(s.paths, path) {
+		var raw json.RawMessage
+		if err := s.dec.Decode(&raw); err != nil {
+			return fmt.Errorf("error decoding image associations: %v", err)
+		}
+		buf.Write(raw)
+		return nil
+	}
+	tok, err := s.dec.Token()
+	if err != nil {
+		return fmt.Errorf("error decoding image associations: %v", err)
+	}
+	switch tok {
+	case nil:
+		buf.WriteString("null")
+		return nil
+	case json.Delim('{'):
+	default:
+		return fmt.Errorf("error decoding image associations: expected object at %q, found %v", path, tok)
+	}
+	buf.WriteByte('{')
+	first := true
+	for s.dec.More() {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return fmt.Errorf("error decoding image associations: %v", err)
+		}
+		key, _ := tok.(string)
+		field := joinField(path, key)
+		if _, ok := s.paths[field]; ok {
+			if err := s.array(field); err != nil {
+				return err
+			}
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		name, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		if err := s.value(buf, field); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(s.dec, '}'); err != nil {
+		return err
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// array stages the Associations of the array at path
+// in its database, batch by batch, or discards them.
+func (s *streamDecoder) array(path string) error {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return fmt.Errorf("error decoding image associations: %v", err)
+	}
+	switch tok {
+	case nil:
+		return nil
+	case json.Delim('['):
+	default:
+		return fmt.Errorf("error decoding image associations: expected array at %q, found %v", path, tok)
+	}
+	db := s.dbs[path]
+	batch := make([]v1alpha2.Association, 0, stagingBatchSize)
+	for s.dec.More() {
+		var a v1alpha2.Association
+		if err := s.dec.Decode(&a); err != nil {
+			return fmt.Errorf("error decoding image associations: %v", err)
+		}
+		if db == nil {
+			continue
+		}
+		if err := a.Validate(); err != nil {
+			s.errs = append(s.errs, err)
+			continue
+		}
+		if batch = append(batch, a); len(batch) == stagingBatchSize {
+			if err := db.stage(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if db != nil {
+		if err := db.stage(batch); err != nil {
+			return err
+		}
+	}
+	return expectDelim(s.dec, ']')
+}
+
+// EncodeStreamed writes v to w as JSON, with the Associations of each
+// AssociationIterator of assocs written as the array at its path, read
+// from the iterator image by image instead of encoded from v. Values of
+// v at the paths are replaced, and empty arrays are omitted. The object
+// holding each path must be present in the encoding of v.
+func EncodeStreamed(w io.Writer, v interface{}, assocs map[string]AssociationIterator) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	e := &streamEncoder{
+		dec:    json.NewDecoder(bytes.NewReader(data)),
+		w:      bw,
+		assocs: assocs,
+	}
+	if err := e.value(""); err != nil {
+		return err
+	}
+	if len(e.errs) != 0 {
+		return utilerrors.NewAggregate(e.errs)
+	}
+	return bw.Flush()
+}
+
+// streamEncoder copies a JSON document and
+// appends the Association arrays it streams.
+type streamEncoder struct {
+	dec    *json.Decoder
+	w      *bufio.Writer
+	assocs map[string]AssociationIterator
+	errs   []error
+}
+
+// value copies the value at path. Objects holding a streamed
+// array are walked field by field, other values are copied whole.
+func (e *streamEncoder) value(path string) error {
+	if !holdsField(e.assocs, path) {
+		var raw json.RawMessage
+		if err := e.dec.Decode(&raw); err != nil {
+			return err
+		}
+		_, err := e.w.Write(raw)
+		return err
+	}
+	if err := expectDelim(e.dec, '{'); err != nil {
+		return err
+	}
+	e.w.WriteByte('{')
+	first := true
+	for e.dec.More() {
+		tok, err := e.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		field := joinField(path, key)
+		if _, ok := e.assocs[field]; ok {
+			// Replaced by the streamed array.
+			var skipped json.RawMessage
+			if err := e.dec.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+		if !first {
+			e.w.WriteByte(',')
+		}
+		first = false
+		if err := writeKey(e.w, key); err != nil {
+			return err
+		}
+		if err := e.value(field); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(e.dec, '}'); err != nil {
+		return err
+	}
+
+	// Sorted for a stable encoding.
+	fields := make([]string, 0, len(e.assocs))
+	for field := range e.assocs {
+		if parentField(field) == path {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		written, err := e.array(field, !first)
+		if err != nil {
+			return err
+		}
+		first = first && !written
+	}
+	return e.w.WriteByte('}')
+}
+
+// array writes the Associations of the iterator of the field as an array,
+// preceded by a comma when sep is set. Nothing is written for no Associations.
+func (e *streamEncoder) array(field string, sep bool) (written bool, err error) {
+	iter := e.assocs[field]
+	if iter == nil {
+		return false, nil
+	}
+	err = iter.ForEach(func(_ string, assocs Associations) error {
+		names := make([]string, 0, len(assocs))
+		for name := range assocs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			a := assocs[name]
+			if err := a.Validate(); err != nil {
+				e.errs = append(e.errs, err)
+				continue
+			}
+			data, err := json.Marshal(a)
+			if err != nil {
+				return err
+			}
+			switch {
+			case written:
+				e.w.WriteByte(',')
+			default:
+				if sep {
+					e.w.WriteByte(',')
+				}
+				if err := writeKey(e.w, field[strings.LastIndex(field, ".")+1:]); err != nil {
+					return err
+				}
+				e.w.WriteByte('[')
+				written = true
+			}
+			if _, err := e.w.Write(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return written, err
+	}
+	if written {
+		err = e.w.WriteByte(']')
+	}
+	return written, err
+}
+
+func writeKey(w *bufio.Writer, key string) error {
+	name, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	w.Write(name)
+	return w.WriteByte(':')
+}
+
+// holdsField returns true if a field of fields is nested in the value at path.
+func holdsField[T any](fields map[string]T, path string) bool {
+	for field := range fields {
+		if path == "" || strings.HasPrefix(field, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func joinField(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func parentField(field string) string {
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		return field[:i]
+	}
+	return ""
+}
@@ -0,0 +1,239 @@
+package image
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	dockercfg "github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+// RegistryAuthFileEnv is the environment variable of the auth file
+// read by podman and skopeo, preferred to the docker config file.
+const RegistryAuthFileEnv = "REGISTRY_AUTH_FILE"
+
+// AuthFile is a docker or podman auth file merged into the
+// credentials of a run.
+type AuthFile struct {
+	Path string
+	// Source names where the auth file comes from in conflict reports,
+	// e.g. the default auth file, --authfile or the pull secret of a catalog.
+	Source string
+}
+
+// AuthConflict is a registry with different credentials in two
+// merged auth files.
+type AuthConflict struct {
+	Registry string
+	// Source is the auth file whose credentials are used.
+	Source string
+	// Overridden is the auth file whose credentials are ignored.
+	Overridden string
+}
+
+func (c AuthConflict) String() string {
+	return fmt.Sprintf("credentials for %s from %s override the ones from %s", c.Registry, c.Source, c.Overridden)
+}
+
+// DefaultAuthFile returns the auth file used when none is set: the file
+// of REGISTRY_AUTH_FILE, the docker config file, or the podman auth file,
+// in that order. It returns an empty path if none exists.
+func DefaultAuthFile() (string, error) {
+	if path := os.Getenv(RegistryAuthFileEnv); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("%s: %v", RegistryAuthFileEnv, err)
+		}
+		return path, nil
+	}
+	candidates := []string{filepath.Join(dockercfg.Dir(), dockercfg.ConfigFileName)}
+	// Without XDG_RUNTIME_DIR the podman auth file would be
+	// relative to the current directory.
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, "containers/auth.json"))
+	}
+	for _, path := range candidates {
+		switch _, err := os.Stat(path); {
+		case err == nil:
+			return path, nil
+		case !errors.Is(err, os.ErrNotExist):
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// MergeAuthFiles merges the registry credentials of files, each file
+// taking precedence over the ones before it. It returns the merged
+// credentials keyed by registry, and the registries whose credentials
+// differ between files, sorted by registry. The credentials of the
+// credential store and helpers of a file are read through them, and
+// merged as if they were in the file.
+func MergeAuthFiles(files []AuthFile) (map[string]types.AuthConfig, []AuthConflict, error) {
+	auths := map[string]types.AuthConfig{}
+	sources := map[string]string{}
+	var conflicts []AuthConflict
+	for _, file := range files {
+		f, err := os.Open(filepath.Clean(file.Path))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading auth file %s of %s: %v", file.Path, file.Source, err)
+		}
+		cfg, err := dockercfg.LoadFromReader(f)
+		f.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing auth file %s of %s: %v", file.Path, file.Source, err)
+		}
+		fileAuths, err := fileCredentials(cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading the credentials of auth file %s of %s: %v", file.Path, file.Source, err)
+		}
+		keys := make([]string, 0, len(fileAuths))
+		for key := range fileAuths {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			auth := fileAuths[key]
+			registry := authKey(key)
+			if prev, ok := auths[registry]; ok && !sameCredentials(prev, auth) {
+				conflicts = append(conflicts, AuthConflict{Registry: registry, Source: file.Source, Overridden: sources[registry]})
+			}
+			auths[registry] = auth
+			sources[registry] = file.Source
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Registry < conflicts[j].Registry
+	})
+	return auths, conflicts, nil
+}
+
+// MergeProviderCredentials merges the credentials the configured credential
+// providers return for hosts over auths, the providers taking precedence as
+// they do in Keychain. It returns the hosts whose credentials differ from the
+// ones of auths, sorted by host.
+func MergeProviderCredentials(ctx context.Context, auths map[string]types.AuthConfig, hosts []string) ([]AuthConflict, error) {
+	providers := getCredentialProviders()
+	var conflicts []AuthConflict
+	seen := map[string]bool{}
+	for _, host := range hosts {
+		host = normalizeRegistryHost(host)
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		for _, p := range providers {
+			cfg, found, err := p.Credentials(ctx, host)
+			if err != nil {
+				return nil, fmt.Errorf("credential provider %q: %v", p.Name(), err)
+			}
+			if !found {
+				continue
+			}
+			auth := types.AuthConfig{
+				Username:      cfg.Username,
+				Password:      cfg.Password,
+				IdentityToken: cfg.IdentityToken,
+				RegistryToken: cfg.RegistryToken,
+			}
+			source := fmt.Sprintf("credential provider %q", p.Name())
+			if prev, ok := auths[host]; ok && !sameCredentials(prev, auth) {
+				conflicts = append(conflicts, AuthConflict{Registry: host, Source: source, Overridden: "the merged auth files"})
+			}
+			auths[host] = auth
+			break
+		}
+	}
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Registry < conflicts[j].Registry
+	})
+	return conflicts, nil
+}
+
+// fileCredentials returns the credentials of cfg keyed by registry: the
+// inline credentials, or the ones of its credential store, overridden by
+// the ones of its registry credential helpers. A credential helper
+// failing is an error, the run would otherwise lack its credentials.
+func fileCredentials(cfg *configfile.ConfigFile) (map[string]types.AuthConfig, error) {
+	auths, err := cfg.GetCredentialsStore("").GetAll()
+	if err != nil {
+		if cfg.CredentialsStore != "" {
+			return nil, fmt.Errorf("credential store %s: %v", cfg.CredentialsStore, err)
+		}
+		return nil, err
+	}
+	for registry, helper := range cfg.CredentialHelpers {
+		auth, err := cfg.GetAuthConfig(registry)
+		if err != nil {
+			return nil, fmt.Errorf("credential helper %s of %s: %v", helper, registry, err)
+		}
+		auths[registry] = auth
+	}
+	for registry, auth := range auths {
+		if auth.Username == "" && auth.Password == "" && auth.IdentityToken == "" && auth.RegistryToken == "" {
+			delete(auths, registry)
+		}
+	}
+	return auths, nil
+}
+
+// authKey returns the registry, or registry and repository, of an entry
+// of an auth file without the scheme and API version of legacy entries,
+// with the Docker Hub aliases mapped to docker.io.
+func authKey(key string) string {
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	key = strings.TrimSuffix(key, "/")
+	key = strings.TrimSuffix(key, "/v1")
+	host, repo, _ := strings.Cut(key, "/")
+	host = normalizeRegistryHost(host)
+	if repo == "" {
+		return host
+	}
+	return host + "/" + repo
+}
+
+func sameCredentials(a, b types.AuthConfig) bool {
+	return a.Username == b.Username && a.Password == b.Password &&
+		a.IdentityToken == b.IdentityToken && a.RegistryToken == b.RegistryToken
+}
+
+// WriteAuthFile writes auths to path as an auth file readable by docker,
+// podman and the containers libraries. Docker Hub is written under its
+// legacy key.
+func WriteAuthFile(path string, auths map[string]types.AuthConfig) error {
+	type authEntry struct {
+		Auth          string `json:"auth,omitempty"`
+		IdentityToken string `json:"identitytoken,omitempty"`
+		RegistryToken string `json:"registrytoken,omitempty"`
+	}
+	file := struct {
+		Auths map[string]authEntry `json:"auths"`
+	}{Auths: make(map[string]authEntry, len(auths))}
+	for registry, auth := range auths {
+		entry := authEntry{IdentityToken: auth.IdentityToken, RegistryToken: auth.RegistryToken}
+		if auth.Username != "" || auth.Password != "" {
+			entry.Auth = base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		}
+		if registry == "docker.io" {
+			registry = authn.DefaultAuthKey
+		}
+		file.Auths[registry] = entry
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
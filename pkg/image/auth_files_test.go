@@ -0,0 +1,193 @@
+package image
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	dockercfg "github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestAuthFile writes an auth file with the user:password
+// credentials of auths, keyed by registry.
+func writeTestAuthFile(t *testing.T, auths map[string]string) string {
+	t.Helper()
+	data := `{"auths": {`
+	i := 0
+	for registry, creds := range auths {
+		if i > 0 {
+			data += ","
+		}
+		data += fmt.Sprintf("%q: {%q: %q}", registry, "auth", base64.StdEncoding.EncodeToString([]byte(creds)))
+		i++
+	}
+	data += "}}"
+	path := filepath.Join(t.TempDir(), "auth.json")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+	return path
+}
+
+func TestMergeAuthFiles(t *testing.T) {
+	global := writeTestAuthFile(t, map[string]string{
+		"https://index.docker.io/v1/": "org:org-password",
+		"quay.io":                     "org:org-password",
+		"registry.redhat.io":          "org:org-password",
+	})
+	run := writeTestAuthFile(t, map[string]string{
+		"quay.io":            "run:run-password",
+		"registry.redhat.io": "org:org-password",
+	})
+	catalog := writeTestAuthFile(t, map[string]string{
+		"quay.io":       "catalog:catalog-password",
+		"quay.io/team":  "team:team-password",
+		"docker.io":     "catalog:catalog-password",
+		"registry.io/a": "a:a-password",
+	})
+
+	auths, conflicts, err := MergeAuthFiles([]AuthFile{
+		{Path: global, Source: "default"},
+		{Path: run, Source: "--authfile"},
+		{Path: catalog, Source: "catalog"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]types.AuthConfig{
+		"docker.io":          {Username: "catalog", Password: "catalog-password", ServerAddress: "docker.io"},
+		"quay.io":            {Username: "catalog", Password: "catalog-password", ServerAddress: "quay.io"},
+		"quay.io/team":       {Username: "team", Password: "team-password", ServerAddress: "quay.io/team"},
+		"registry.io/a":      {Username: "a", Password: "a-password", ServerAddress: "registry.io/a"},
+		"registry.redhat.io": {Username: "org", Password: "org-password", ServerAddress: "registry.redhat.io"},
+	}, auths)
+	require.Equal(t, []AuthConflict{
+		{Registry: "docker.io", Source: "catalog", Overridden: "default"},
+		{Registry: "quay.io", Source: "--authfile", Overridden: "default"},
+		{Registry: "quay.io", Source: "catalog", Overridden: "--authfile"},
+	}, conflicts)
+	require.Equal(t, "credentials for quay.io from catalog override the ones from --authfile", conflicts[2].String())
+
+	t.Run("Valid/CredentialHelpers", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the test credential helper is a shell script")
+		}
+		// The helper returns the credentials of any registry it is asked for,
+		// and lists the registry of the credential store.
+		bin := t.TempDir()
+		helper := `#!/bin/sh
+case "$1" in
+get) read server; printf '{"ServerURL":"%s","Username":"helper","Secret":"helper-password"}' "$server" ;;
+list) printf '{"registry.store.io":"helper"}' ;;
+*) exit 1 ;;
+esac
+`
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "docker-credential-test"), []byte(helper), 0700))
+		t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+		helpers := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(helpers, []byte(`{"credsStore": "test", "credHelpers": {"registry.helper.io": "test"}}`), 0600))
+
+		auths, _, err := MergeAuthFiles([]AuthFile{
+			{Path: helpers, Source: "default"},
+			{Path: run, Source: "--authfile"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "helper", auths["registry.store.io"].Username)
+		require.Equal(t, "helper-password", auths["registry.helper.io"].Password)
+		require.Equal(t, "run", auths["quay.io"].Username)
+	})
+	t.Run("Invalid/CredentialHelper", func(t *testing.T) {
+		helpers := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(helpers, []byte(`{"credHelpers": {"registry.helper.io": "oc-mirror-missing"}}`), 0600))
+		_, _, err := MergeAuthFiles([]AuthFile{{Path: helpers, Source: "default"}})
+		require.ErrorContains(t, err, "credential helper oc-mirror-missing of registry.helper.io")
+	})
+	t.Run("Invalid/MissingFile", func(t *testing.T) {
+		_, _, err := MergeAuthFiles([]AuthFile{{Path: filepath.Join(t.TempDir(), "missing.json"), Source: "--authfile"}})
+		require.ErrorContains(t, err, "error reading auth file")
+	})
+}
+
+func TestWriteAuthFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth", "config.json")
+	require.NoError(t, WriteAuthFile(path, map[string]types.AuthConfig{
+		"docker.io": {Username: "user", Password: "password"},
+		"quay.io":   {IdentityToken: "token"},
+	}))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	p := NewFileCredentialProvider(path)
+	cfg, found, err := p.Credentials(context.Background(), "docker.io")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, authn.AuthConfig{Username: "user", Password: "password"}, cfg)
+	cfg, found, err = p.Credentials(context.Background(), "quay.io")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, authn.AuthConfig{IdentityToken: "token"}, cfg)
+}
+
+func TestDefaultAuthFile(t *testing.T) {
+	t.Run("Valid/RegistryAuthFile", func(t *testing.T) {
+		path := writeTestAuthFile(t, map[string]string{"quay.io": "user:password"})
+		t.Setenv(RegistryAuthFileEnv, path)
+		found, err := DefaultAuthFile()
+		require.NoError(t, err)
+		require.Equal(t, path, found)
+	})
+	t.Run("Invalid/RegistryAuthFile", func(t *testing.T) {
+		t.Setenv(RegistryAuthFileEnv, filepath.Join(t.TempDir(), "missing.json"))
+		_, err := DefaultAuthFile()
+		require.ErrorContains(t, err, RegistryAuthFileEnv)
+	})
+	t.Run("Valid/None", func(t *testing.T) {
+		t.Setenv(RegistryAuthFileEnv, "")
+		prev := dockercfg.Dir()
+		dockercfg.SetDir(t.TempDir())
+		t.Cleanup(func() { dockercfg.SetDir(prev) })
+		t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+		found, err := DefaultAuthFile()
+		require.NoError(t, err)
+		require.Empty(t, found)
+	})
+	t.Run("Valid/NoRuntimeDir", func(t *testing.T) {
+		t.Setenv(RegistryAuthFileEnv, "")
+		prev := dockercfg.Dir()
+		dockercfg.SetDir(t.TempDir())
+		t.Cleanup(func() { dockercfg.SetDir(prev) })
+		t.Setenv("XDG_RUNTIME_DIR", "")
+		// An auth file relative to the current directory is not read.
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "containers"), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "containers", "auth.json"), []byte(`{"auths": {}}`), 0600))
+		require.NoError(t, os.Chdir(dir))
+		t.Cleanup(func() { require.NoError(t, os.Chdir(wd)) })
+		found, err := DefaultAuthFile()
+		require.NoError(t, err)
+		require.Empty(t, found)
+	})
+}
+
+func TestMergeProviderCredentials(t *testing.T) {
+	SetCredentialProviders(staticProvider{host: "quay.io", cfg: authn.AuthConfig{Username: "provider", Password: "token"}})
+	defer SetCredentialProviders()
+
+	auths := map[string]types.AuthConfig{
+		"quay.io":            {Username: "file", Password: "file-password"},
+		"registry.redhat.io": {Username: "rh", Password: "rh-password"},
+	}
+	conflicts, err := MergeProviderCredentials(context.Background(), auths, []string{"quay.io", "registry.redhat.io", "quay.io"})
+	require.NoError(t, err)
+	require.Equal(t, []AuthConflict{{Registry: "quay.io", Source: `credential provider "static"`, Overridden: "the merged auth files"}}, conflicts)
+	require.Equal(t, map[string]types.AuthConfig{
+		"quay.io":            {Username: "provider", Password: "token"},
+		"registry.redhat.io": {Username: "rh", Password: "rh-password"},
+	}, auths)
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,6 +23,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/image"
 )
 
 const (
@@ -30,13 +33,45 @@ const (
 	c_ISUID = 04000 // Set uid
 	c_ISGID = 02000 // Set gid
 	c_ISVTX = 01000 // Save text (sticky bit)
+
+	// SourceDateEpochEnv is the environment variable of the reproducible
+	// builds specification, the Unix time of the files of the layers built.
+	SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
 )
 
+// SourceDateEpoch returns the time set by SOURCE_DATE_EPOCH, and
+// false if it is not set or is not a number of seconds.
+func SourceDateEpoch() (time.Time, bool) {
+	value := os.Getenv(SourceDateEpochEnv)
+	if value == "" {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		klog.Warningf("ignoring invalid %s %q: %v", SourceDateEpochEnv, value, err)
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0).UTC(), true
+}
+
+// layerModTime returns the modification time of the files of the layers
+// built, so layers built from the same files have the same digest: the
+// time of SOURCE_DATE_EPOCH if set, or else the Unix epoch.
+func layerModTime() time.Time {
+	if t, ok := SourceDateEpoch(); ok {
+		return t
+	}
+	return time.Unix(0, 0).UTC()
+}
+
 // ImageBuilder use an OCI workspace to add layers and change configuration to images.
 type ImageBuilder struct {
 	NameOpts   []name.Option
 	RemoteOpts []remote.Option
 	Logger     klog.Logger
+	// OCIMediaTypes converts the images built
+	// from Docker v2 schema 2 images to OCI media types.
+	OCIMediaTypes bool
 }
 
 // ErrInvalidReference is returned the target reference is a digest.
@@ -129,6 +164,8 @@ func (b *ImageBuilder) Run(ctx context.Context, targetRef string, layoutPath lay
 	// if child manifests are docker V2 schema
 	if v2format {
 		resultIdx = mutate.IndexMediaType(resultIdx, types.DockerManifestList)
+	} else if b.OCIMediaTypes {
+		resultIdx = mutate.IndexMediaType(resultIdx, types.OCIImageIndex)
 	}
 	// get the hashes from the original manifest since we need to remove them
 	originalHashes := []v1.Hash{}
@@ -274,6 +311,14 @@ func (b *ImageBuilder) processImageIndex(ctx context.Context, idx v1.ImageIndex,
 			}
 		}
 
+		if *v2format && b.OCIMediaTypes {
+			img, err = image.ConvertImageToOCI(img)
+			if err != nil {
+				return nil, err
+			}
+			*v2format = false
+		}
+
 		desc, err := partial.Descriptor(img)
 		if err != nil {
 			return nil, err
@@ -359,9 +404,13 @@ func LayerFromPath(targetPath, path string) (v1.Layer, error) {
 // LayerFromPath will write the contents of the path(s) the target
 // directory specifying the target UID/GID and build a v1.Layer.
 // Use gid = -1 , uid = -1 if you don't want to override.
+// The layer is reproducible: the files are added in lexical order
+// with the modification time of layerModTime and no access or
+// change time.
 func LayerFromPathWithUidGid(targetPath, path string, uid int, gid int) (v1.Layer, error) {
 	var b bytes.Buffer
 	tw := tar.NewWriter(&b)
+	modTime := layerModTime()
 
 	pathInfo, err := os.Stat(path)
 	if err != nil {
@@ -372,7 +421,7 @@ func LayerFromPathWithUidGid(targetPath, path string, uid int, gid int) (v1.Laye
 		if !info.IsDir() {
 			hdr.Size = info.Size()
 		}
-		hdr.ChangeTime = time.Now()
+		hdr.ModTime = modTime
 		if info.Mode().IsDir() {
 			hdr.Typeflag = tar.TypeDir
 		} else if info.Mode().IsRegular() {
@@ -401,6 +450,7 @@ func LayerFromPathWithUidGid(targetPath, path string, uid int, gid int) (v1.Laye
 	}
 
 	if pathInfo.IsDir() {
+		// filepath.Walk visits the files in lexical order.
 		err := filepath.Walk(path, func(fp string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
@@ -1,13 +1,16 @@
 package builder
 
 import (
+	"archive/tar"
 	"context"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
@@ -368,6 +371,66 @@ func TestLayoutFromPath(t *testing.T) {
 	}
 }
 
+func TestLayerFromPathReproducible(t *testing.T) {
+	tmpdir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpdir, "configs", "foo"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "configs", "foo", "catalog.json"), []byte("{}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpdir, "configs", "index.json"), []byte("{}\n"), 0644))
+
+	layerDigest := func(t *testing.T) v1.Hash {
+		layer, err := LayerFromPath("/", tmpdir)
+		require.NoError(t, err)
+		digest, err := layer.Digest()
+		require.NoError(t, err)
+		return digest
+	}
+	modTimes := func(t *testing.T) map[string]time.Time {
+		layer, err := LayerFromPath("/", tmpdir)
+		require.NoError(t, err)
+		rc, err := layer.Uncompressed()
+		require.NoError(t, err)
+		defer rc.Close()
+		times := map[string]time.Time{}
+		tr := tar.NewReader(rc)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			times[hdr.Name] = hdr.ModTime.UTC()
+		}
+		return times
+	}
+
+	t.Run("Valid/SameFiles", func(t *testing.T) {
+		digest := layerDigest(t)
+		// Touching the files does not change the layer.
+		now := time.Now()
+		require.NoError(t, os.Chtimes(filepath.Join(tmpdir, "configs", "index.json"), now, now))
+		require.Equal(t, digest, layerDigest(t))
+		for name, modTime := range modTimes(t) {
+			require.Equal(t, time.Unix(0, 0).UTC(), modTime, name)
+		}
+	})
+	t.Run("Valid/SourceDateEpoch", func(t *testing.T) {
+		digest := layerDigest(t)
+		t.Setenv(SourceDateEpochEnv, "1700000000")
+		require.NotEqual(t, digest, layerDigest(t))
+		for name, modTime := range modTimes(t) {
+			require.Equal(t, time.Unix(1700000000, 0).UTC(), modTime, name)
+		}
+	})
+	t.Run("Invalid/SourceDateEpoch", func(t *testing.T) {
+		t.Setenv(SourceDateEpochEnv, "yesterday")
+		_, ok := SourceDateEpoch()
+		require.False(t, ok)
+		for name, modTime := range modTimes(t) {
+			require.Equal(t, time.Unix(0, 0).UTC(), modTime, name)
+		}
+	})
+}
+
 func pinToDigest(unpinnedImage string) (string, error) {
 	ref, err := reference.Parse(unpinnedImage)
 	if err != nil {
@@ -1,6 +1,10 @@
 package image
 
 import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/openshift/library-go/pkg/image/reference"
@@ -670,6 +674,156 @@ func TestConvertToAssociationSet(t *testing.T) {
 	}
 }
 
+func TestConvertToAssociationDB(t *testing.T) {
+	input := []v1alpha2.Association{
+		{
+			Name:            "imgname:latest",
+			Path:            "index_manifest",
+			TagSymlink:      "latest",
+			ID:              "sha256:d15a206e4ee462e82ab722ed84dfa514ab9ed8d85100d591c04314ae7c2162ee",
+			Type:            v1alpha2.TypeGeneric,
+			ManifestDigests: []string{"sha256:bab3a6153010b614c8764548f0dbe34c4a7dce4ea278a94713c3e9a936bb74e6"},
+		},
+		{
+			Name:         "sha256:bab3a6153010b614c8764548f0dbe34c4a7dce4ea278a94713c3e9a936bb74e6",
+			Path:         "index_manifest",
+			ID:           "sha256:bab3a6153010b614c8764548f0dbe34c4a7dce4ea278a94713c3e9a936bb74e6",
+			Type:         v1alpha2.TypeGeneric,
+			LayerDigests: []string{"sha256:52278dd8e57993669c5b72a9620e89bebdc098f2af2379caaa8945f7403f77a2"},
+		},
+		{
+			Name:         "other@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+			Path:         "single_manifest",
+			TagSymlink:   "latest",
+			ID:           "sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19",
+			Type:         v1alpha2.TypeGeneric,
+			LayerDigests: []string{"sha256:e8614d09b7bebabd9d8a450f44e88a8807c98a438a2ddd63146865286b132d1b"},
+		},
+	}
+	expSet, err := ConvertToAssociationSet(input)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "associations.db")
+	db, err := ConvertToAssociationDB(path, input)
+	require.NoError(t, err)
+
+	dbSet := AssociationSet{}
+	require.NoError(t, db.ForEach(func(imageName string, assocs Associations) error {
+		dbSet[imageName] = assocs
+		return nil
+	}))
+	require.Equal(t, expSet, dbSet)
+
+	n, err := db.Len()
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	found, err := db.ContainsKey("imgname:latest", "sha256:bab3a6153010b614c8764548f0dbe34c4a7dce4ea278a94713c3e9a936bb74e6")
+	require.NoError(t, err)
+	require.True(t, found)
+	values, found, err := db.Search("other@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, []v1alpha2.Association{input[2]}, values)
+
+	prunedPath := filepath.Join(t.TempDir(), "pruned.db")
+	pruned, err := db.Prune(prunedPath, []string{"imgname:latest"})
+	require.NoError(t, err)
+	require.Equal(t, AssociationSet{"imgname:latest": expSet["imgname:latest"]}, associationDBToSet(t, pruned))
+	require.NoError(t, pruned.Merge(AssociationSet{"other@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19": expSet["other@sha256:d31c6ea5c50be93d6eb94d2b508f0208e84a308c011c6454ebf291d48b37df19"]}))
+	require.Equal(t, expSet, associationDBToSet(t, pruned))
+	require.NoError(t, pruned.Validate())
+	require.NoError(t, pruned.Close())
+	_, err = db.Prune(prunedPath, []string{"missing"})
+	require.EqualError(t, err, "key missing does not exist in provided associations")
+	require.NoFileExists(t, prunedPath)
+
+	require.NoError(t, db.Close())
+	require.NoFileExists(t, path)
+
+	_, err = ConvertToAssociationDB(path, input[:1])
+	require.EqualError(t, err, "invalid associations: association for \"sha256:bab3a6153010b614c8764548f0dbe34c4a7dce4ea278a94713c3e9a936bb74e6\" is missing")
+	require.NoFileExists(t, path)
+}
+
+func TestDecodeStreamed(t *testing.T) {
+	input := []v1alpha2.Association{
+		{
+			Name:            "imgname:latest",
+			Path:            "index_manifest",
+			TagSymlink:      "latest",
+			ID:              "sha256:d15a206e4ee462e82ab722ed84dfa514ab9ed8d85100d591c04314ae7c2162ee",
+			Type:            v1alpha2.TypeGeneric,
+			ManifestDigests: []string{"sha256:bab3a6153010b614c8764548f0dbe34c4a7dce4ea278a94713c3e9a936bb74e6"},
+		},
+		{
+			Name:         "sha256:bab3a6153010b614c8764548f0dbe34c4a7dce4ea278a94713c3e9a936bb74e6",
+			Path:         "index_manifest",
+			ID:           "sha256:bab3a6153010b614c8764548f0dbe34c4a7dce4ea278a94713c3e9a936bb74e6",
+			Type:         v1alpha2.TypeGeneric,
+			LayerDigests: []string{"sha256:52278dd8e57993669c5b72a9620e89bebdc098f2af2379caaa8945f7403f77a2"},
+		},
+	}
+	expSet, err := ConvertToAssociationSet(input)
+	require.NoError(t, err)
+
+	meta := v1alpha2.NewMetadata()
+	meta.PastMirror.Sequence = 1
+	meta.PastMirror.Associations = input
+	meta.PastAssociations = input
+	data, err := json.Marshal(meta)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	pastPath := filepath.Join(dir, "past.db")
+	rest, dbs, err := DecodeStreamed(bytes.NewReader(data), map[string]string{
+		PastAssociationsField:     pastPath,
+		MirroredAssociationsField: "",
+	})
+	require.NoError(t, err)
+	require.Len(t, dbs, 1)
+	require.Equal(t, expSet, associationDBToSet(t, dbs[PastAssociationsField]))
+	var restMeta v1alpha2.Metadata
+	require.NoError(t, json.Unmarshal(rest, &restMeta))
+	require.Empty(t, restMeta.PastAssociations)
+	require.Empty(t, restMeta.PastMirror.Associations)
+	require.Equal(t, 1, restMeta.PastMirror.Sequence)
+
+	// Encoding the rest with the streamed associations gives back the metadata.
+	var encoded bytes.Buffer
+	require.NoError(t, EncodeStreamed(&encoded, restMeta, map[string]AssociationIterator{
+		PastAssociationsField:     dbs[PastAssociationsField],
+		MirroredAssociationsField: expSet,
+	}))
+	var got v1alpha2.Metadata
+	require.NoError(t, json.Unmarshal(encoded.Bytes(), &got))
+	require.ElementsMatch(t, input, got.PastAssociations)
+	require.ElementsMatch(t, input, got.PastMirror.Associations)
+	require.Equal(t, 1, got.PastMirror.Sequence)
+
+	// Empty associations are omitted.
+	encoded.Reset()
+	require.NoError(t, EncodeStreamed(&encoded, restMeta, map[string]AssociationIterator{
+		PastAssociationsField: AssociationSet{},
+	}))
+	require.NotContains(t, encoded.String(), PastAssociationsField)
+	require.NoError(t, json.Unmarshal(encoded.Bytes(), &got))
+	require.NoError(t, dbs.Close())
+	require.NoFileExists(t, pastPath)
+
+	_, _, err = DecodeStreamed(strings.NewReader(`{"pastAssociations":[{"name":"imgname:latest"`), map[string]string{PastAssociationsField: pastPath})
+	require.ErrorContains(t, err, "error decoding image associations")
+	require.NoFileExists(t, pastPath)
+}
+
+func associationDBToSet(t *testing.T, db *AssociationDB) AssociationSet {
+	set := AssociationSet{}
+	require.NoError(t, db.ForEach(func(imageName string, assocs Associations) error {
+		set[imageName] = assocs
+		return nil
+	}))
+	return set
+}
+
 func TestConvertToTypedMapping(t *testing.T) {
 	type spec struct {
 		desc       string
@@ -0,0 +1,153 @@
+package image
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/distribution/distribution/v3/registry/client/auth"
+	"github.com/docker/docker/api/types/registry"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+	"github.com/openshift/oc/pkg/cli/image/manifest/dockercredentials"
+	"k8s.io/klog/v2"
+)
+
+// CredentialProvider supplies credentials for a registry host.
+// Providers are consulted in order before the docker and podman
+// credential files, so they can be used to source short-lived tokens
+// from systems such as Vault or AWS ECR.
+type CredentialProvider interface {
+	// Name returns a short identifier used in logs and error messages.
+	Name() string
+	// Credentials returns the credentials for the registry host.
+	// The boolean is false when the provider has no credentials for
+	// that host, in which case the next provider is consulted.
+	Credentials(ctx context.Context, registry string) (authn.AuthConfig, bool, error)
+}
+
+var (
+	providersMu         sync.RWMutex
+	credentialProviders []CredentialProvider
+	authFile            string
+)
+
+// SetCredentialProviders replaces the credential providers used by
+// Keychain and NewContext. Passing no providers restores the default
+// behavior of only reading the docker and podman credential files.
+func SetCredentialProviders(providers ...CredentialProvider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	credentialProviders = providers
+}
+
+func getCredentialProviders() []CredentialProvider {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return credentialProviders
+}
+
+// SetAuthFile sets the auth file read by Keychain and NewContext instead
+// of the docker and podman credential files, such as the merge of several
+// auth files written by WriteAuthFile. Passing an empty path restores the
+// default auth file.
+func SetAuthFile(path string) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	authFile = path
+}
+
+func getAuthFile() string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	return authFile
+}
+
+// Keychain returns an authn.Keychain that resolves credentials from the
+// configured providers, then the auth file set by SetAuthFile, and falls
+// back to authn.DefaultKeychain.
+func Keychain() authn.Keychain {
+	providers := getCredentialProviders()
+	if path := getAuthFile(); path != "" {
+		providers = append(append([]CredentialProvider{}, providers...), NewFileCredentialProvider(path))
+	}
+	return &providerKeychain{
+		providers: providers,
+		fallback:  authn.DefaultKeychain,
+	}
+}
+
+type providerKeychain struct {
+	providers []CredentialProvider
+	fallback  authn.Keychain
+}
+
+// Resolve implements authn.Keychain.
+func (k *providerKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	cfg, found, err := lookupCredentials(context.Background(), k.providers, resource.RegistryStr())
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		return authn.FromConfig(cfg), nil
+	}
+	return k.fallback.Resolve(resource)
+}
+
+// providerCredentialStoreFactory adapts the configured providers to the
+// credential store factory used by the oc registry client.
+type providerCredentialStoreFactory struct {
+	providers []CredentialProvider
+	fallback  registryclient.CredentialStoreFactory
+}
+
+// CredentialStoreFor implements registryclient.CredentialStoreFactory.
+func (f *providerCredentialStoreFactory) CredentialStoreFor(image string) auth.CredentialStore {
+	ref, err := reference.ParseNormalizedNamed(image)
+	if err == nil {
+		cfg, found, err := lookupCredentials(context.Background(), f.providers, reference.Domain(ref))
+		switch {
+		case err != nil:
+			klog.Warningf("unable to resolve credentials for %s: %v", image, err)
+		case found:
+			return dockercredentials.NewDynamicCredentialStore(&registry.AuthConfig{
+				Username:      cfg.Username,
+				Password:      cfg.Password,
+				IdentityToken: cfg.IdentityToken,
+			})
+		}
+	}
+	if f.fallback == nil {
+		return registryclient.NoCredentials
+	}
+	return f.fallback.CredentialStoreFor(image)
+}
+
+// lookupCredentials returns the credentials from the first provider
+// that has an entry for the registry host.
+func lookupCredentials(ctx context.Context, providers []CredentialProvider, registryHost string) (authn.AuthConfig, bool, error) {
+	registryHost = normalizeRegistryHost(registryHost)
+	for _, p := range providers {
+		cfg, found, err := p.Credentials(ctx, registryHost)
+		if err != nil {
+			return authn.AuthConfig{}, false, fmt.Errorf("credential provider %q: %v", p.Name(), err)
+		}
+		if found {
+			klog.V(2).Infof("using credentials from provider %q for %s", p.Name(), registryHost)
+			return cfg, true, nil
+		}
+	}
+	return authn.AuthConfig{}, false, nil
+}
+
+// normalizeRegistryHost maps the Docker Hub aliases to a single host
+// so providers only need to match one name.
+func normalizeRegistryHost(host string) string {
+	switch host {
+	case "docker.io", "registry-1.docker.io", "index.docker.io":
+		return "docker.io"
+	}
+	return strings.ToLower(host)
+}
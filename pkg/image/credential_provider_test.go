@@ -0,0 +1,193 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/stretchr/testify/require"
+)
+
+type staticProvider struct {
+	host string
+	cfg  authn.AuthConfig
+}
+
+func (p staticProvider) Name() string { return "static" }
+
+func (p staticProvider) Credentials(_ context.Context, registry string) (authn.AuthConfig, bool, error) {
+	if registry != p.host {
+		return authn.AuthConfig{}, false, nil
+	}
+	return p.cfg, true, nil
+}
+
+func TestParseCredentialProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected string
+		err      string
+	}{{
+		name:     "Valid/Env",
+		spec:     "env",
+		expected: "env",
+	}, {
+		name:     "Valid/File",
+		spec:     "file=/tmp/auth.json",
+		expected: "file",
+	}, {
+		name:     "Valid/ECR",
+		spec:     "ecr",
+		expected: "ecr",
+	}, {
+		name:     "Valid/Exec",
+		spec:     "exec=docker-credential-vault",
+		expected: "exec",
+	}, {
+		name: "Invalid/FileWithoutPath",
+		spec: "file",
+		err:  `credential provider "file" requires a path`,
+	}, {
+		name: "Invalid/Unknown",
+		spec: "vault",
+		err:  `unknown credential provider "vault"`,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := ParseCredentialProvider(test.spec)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, p.Name())
+		})
+	}
+}
+
+func TestKeychain(t *testing.T) {
+	SetCredentialProviders(staticProvider{host: "quay.io", cfg: authn.AuthConfig{Username: "user", Password: "pass"}})
+	defer SetCredentialProviders()
+
+	repo, err := name.NewRepository("quay.io/ns/repo")
+	require.NoError(t, err)
+	authr, err := Keychain().Resolve(repo)
+	require.NoError(t, err)
+	cfg, err := authr.Authorization()
+	require.NoError(t, err)
+	require.Equal(t, "user", cfg.Username)
+	require.Equal(t, "pass", cfg.Password)
+}
+
+func TestEnvCredentialProvider(t *testing.T) {
+	env := map[string]string{
+		"OC_MIRROR_AUTH_QUAY_IO":        "user:pass",
+		"OC_MIRROR_AUTH_LOCALHOST_5000": "invalid",
+	}
+	p := &envCredentialProvider{lookupEnv: func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}}
+
+	cfg, found, err := p.Credentials(context.Background(), "quay.io")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, authn.AuthConfig{Username: "user", Password: "pass"}, cfg)
+
+	_, found, err = p.Credentials(context.Background(), "registry.redhat.io")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	_, _, err = p.Credentials(context.Background(), "localhost:5000")
+	require.EqualError(t, err, "environment variable OC_MIRROR_AUTH_LOCALHOST_5000 must be of the form user:password")
+}
+
+func TestFileCredentialProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	// "dXNlcjpwYXNz" is base64 for "user:pass"
+	require.NoError(t, os.WriteFile(path, []byte(`{"auths":{"quay.io":{"auth":"dXNlcjpwYXNz"}}}`), 0600))
+
+	p := NewFileCredentialProvider(path)
+	cfg, found, err := p.Credentials(context.Background(), "quay.io")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "user", cfg.Username)
+	require.Equal(t, "pass", cfg.Password)
+
+	_, found, err = p.Credentials(context.Background(), "registry.redhat.io")
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestECRCredentialProvider(t *testing.T) {
+	now := time.Now()
+	calls := 0
+	p := &ecrCredentialProvider{
+		run: func(_ context.Context, _ string, name string, args ...string) ([]byte, error) {
+			calls++
+			require.Equal(t, "aws", name)
+			require.Equal(t, []string{"ecr", "get-login-password", "--region", "us-east-1"}, args)
+			return []byte("token\n"), nil
+		},
+		now:    func() time.Time { return now },
+		tokens: map[string]ecrToken{},
+	}
+	host := "123456789012.dkr.ecr.us-east-1.amazonaws.com"
+
+	_, found, err := p.Credentials(context.Background(), "quay.io")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	cfg, found, err := p.Credentials(context.Background(), host)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, authn.AuthConfig{Username: "AWS", Password: "token"}, cfg)
+
+	// Cached until the token is close to expiry
+	_, _, err = p.Credentials(context.Background(), host)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	now = now.Add(ecrTokenLifetime)
+	_, _, err = p.Credentials(context.Background(), host)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
+
+func TestExecCredentialProvider(t *testing.T) {
+	p := &execCredentialProvider{
+		command: "docker-credential-test",
+		run: func(_ context.Context, stdin string, name string, args ...string) ([]byte, error) {
+			require.Equal(t, "docker-credential-test", name)
+			require.Equal(t, []string{"get"}, args)
+			switch stdin {
+			case "quay.io":
+				return []byte(`{"ServerURL":"quay.io","Username":"user","Secret":"pass"}`), nil
+			case "registry.redhat.io":
+				return []byte(`{"ServerURL":"registry.redhat.io","Username":"<token>","Secret":"token"}`), nil
+			default:
+				return nil, errors.New(credentialsNotFound)
+			}
+		},
+	}
+
+	cfg, found, err := p.Credentials(context.Background(), "quay.io")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, authn.AuthConfig{Username: "user", Password: "pass"}, cfg)
+
+	cfg, found, err = p.Credentials(context.Background(), "registry.redhat.io")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, authn.AuthConfig{IdentityToken: "token"}, cfg)
+
+	_, found, err = p.Credentials(context.Background(), "localhost:5000")
+	require.NoError(t, err)
+	require.False(t, found)
+}
@@ -0,0 +1,274 @@
+package image
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	dockercfg "github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+const (
+	// EnvCredentialPrefix is the prefix of the environment variables read by
+	// the env credential provider. The registry host is upper-cased and any
+	// character that is not a letter or digit is replaced by an underscore,
+	// e.g. OC_MIRROR_AUTH_QUAY_IO="user:password".
+	EnvCredentialPrefix = "OC_MIRROR_AUTH_"
+
+	// ecrTokenLifetime is how long a token returned by
+	// `aws ecr get-login-password` is cached. ECR tokens are valid for
+	// 12 hours, refresh them an hour early.
+	ecrTokenLifetime = 11 * time.Hour
+
+	// credentialsNotFound is the message returned by docker credential
+	// helpers when they have no entry for a server.
+	credentialsNotFound = "credentials not found in native keychain"
+)
+
+var ecrHostRegexp = regexp.MustCompile(`^[0-9]{12}\.dkr\.ecr(-fips)?\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+
+// commandRunner runs a command with the provided stdin and returns its stdout.
+type commandRunner func(ctx context.Context, stdin string, name string, args ...string) ([]byte, error)
+
+func runCommand(ctx context.Context, stdin string, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		return nil, fmt.Errorf("%s: %v: %s", name, err, msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// ParseCredentialProvider creates a builtin provider from a
+// specification of the form <type>[=<argument>]. Supported types are:
+//
+//	env               read OC_MIRROR_AUTH_<HOST> environment variables
+//	file=<path>       read a docker or podman auth file
+//	ecr               obtain AWS ECR tokens with the aws CLI
+//	exec=<command>    run a docker credential helper compatible plugin
+func ParseCredentialProvider(spec string) (CredentialProvider, error) {
+	typ, arg, _ := strings.Cut(spec, "=")
+	switch typ {
+	case "env":
+		return NewEnvCredentialProvider(), nil
+	case "file":
+		if arg == "" {
+			return nil, fmt.Errorf("credential provider %q requires a path", typ)
+		}
+		return NewFileCredentialProvider(arg), nil
+	case "ecr":
+		return NewECRCredentialProvider(), nil
+	case "exec":
+		if arg == "" {
+			return nil, fmt.Errorf("credential provider %q requires a command", typ)
+		}
+		return NewExecCredentialProvider(arg), nil
+	default:
+		return nil, fmt.Errorf("unknown credential provider %q", typ)
+	}
+}
+
+// envCredentialProvider reads "user:password" pairs from environment variables.
+type envCredentialProvider struct {
+	lookupEnv func(string) (string, bool)
+}
+
+// NewEnvCredentialProvider returns a provider reading credentials from
+// OC_MIRROR_AUTH_<HOST> environment variables.
+func NewEnvCredentialProvider() CredentialProvider {
+	return &envCredentialProvider{lookupEnv: os.LookupEnv}
+}
+
+func (p *envCredentialProvider) Name() string { return "env" }
+
+func (p *envCredentialProvider) Credentials(_ context.Context, registry string) (authn.AuthConfig, bool, error) {
+	key := EnvCredentialKey(registry)
+	value, ok := p.lookupEnv(key)
+	if !ok || value == "" {
+		return authn.AuthConfig{}, false, nil
+	}
+	user, password, found := strings.Cut(value, ":")
+	if !found {
+		return authn.AuthConfig{}, false, fmt.Errorf("environment variable %s must be of the form user:password", key)
+	}
+	return authn.AuthConfig{Username: user, Password: password}, true, nil
+}
+
+// EnvCredentialKey returns the environment variable name read by the
+// env credential provider for a registry host.
+func EnvCredentialKey(registry string) string {
+	key := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, registry)
+	return EnvCredentialPrefix + key
+}
+
+// fileCredentialProvider reads credentials from a docker config formatted file.
+type fileCredentialProvider struct {
+	path string
+
+	once sync.Once
+	cfg  *configfile.ConfigFile
+	err  error
+}
+
+// NewFileCredentialProvider returns a provider reading credentials from
+// a docker or podman auth file at path. The file is loaded on first use.
+func NewFileCredentialProvider(path string) CredentialProvider {
+	return &fileCredentialProvider{path: path}
+}
+
+func (p *fileCredentialProvider) Name() string { return "file" }
+
+func (p *fileCredentialProvider) Credentials(_ context.Context, registry string) (authn.AuthConfig, bool, error) {
+	p.once.Do(func() {
+		f, err := os.Open(p.path)
+		if err != nil {
+			p.err = err
+			return
+		}
+		defer f.Close()
+		p.cfg, p.err = dockercfg.LoadFromReader(f)
+	})
+	if p.err != nil {
+		return authn.AuthConfig{}, false, p.err
+	}
+
+	key := registry
+	if key == "docker.io" {
+		key = authn.DefaultAuthKey
+	}
+	cfg, err := p.cfg.GetAuthConfig(key)
+	if err != nil {
+		return authn.AuthConfig{}, false, err
+	}
+	if cfg.Username == "" && cfg.Password == "" && cfg.IdentityToken == "" && cfg.RegistryToken == "" {
+		return authn.AuthConfig{}, false, nil
+	}
+	return authn.AuthConfig{
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		IdentityToken: cfg.IdentityToken,
+		RegistryToken: cfg.RegistryToken,
+	}, true, nil
+}
+
+type ecrToken struct {
+	password string
+	expires  time.Time
+}
+
+// ecrCredentialProvider obtains and refreshes AWS ECR authorization tokens.
+type ecrCredentialProvider struct {
+	run commandRunner
+	now func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]ecrToken
+}
+
+// NewECRCredentialProvider returns a provider for AWS ECR registries.
+// Tokens are obtained with `aws ecr get-login-password`, so the usual
+// AWS CLI environment (profiles, instance roles, etc.) applies, and are
+// refreshed before they expire during long running mirrors.
+func NewECRCredentialProvider() CredentialProvider {
+	return &ecrCredentialProvider{
+		run:    runCommand,
+		now:    time.Now,
+		tokens: map[string]ecrToken{},
+	}
+}
+
+func (p *ecrCredentialProvider) Name() string { return "ecr" }
+
+func (p *ecrCredentialProvider) Credentials(ctx context.Context, registry string) (authn.AuthConfig, bool, error) {
+	match := ecrHostRegexp.FindStringSubmatch(registry)
+	if match == nil {
+		return authn.AuthConfig{}, false, nil
+	}
+	region := match[2]
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if token, ok := p.tokens[registry]; ok && p.now().Before(token.expires) {
+		return authn.AuthConfig{Username: "AWS", Password: token.password}, true, nil
+	}
+
+	out, err := p.run(ctx, "", "aws", "ecr", "get-login-password", "--region", region)
+	if err != nil {
+		return authn.AuthConfig{}, false, err
+	}
+	password := strings.TrimSpace(string(out))
+	if password == "" {
+		return authn.AuthConfig{}, false, fmt.Errorf("empty token returned for %s", registry)
+	}
+	p.tokens[registry] = ecrToken{password: password, expires: p.now().Add(ecrTokenLifetime)}
+	return authn.AuthConfig{Username: "AWS", Password: password}, true, nil
+}
+
+// execCredentialProvider runs a plugin implementing the docker
+// credential helper protocol: the plugin is invoked with the "get"
+// argument, receives the registry host on stdin and writes a JSON
+// object with the ServerURL, Username and Secret fields on stdout.
+type execCredentialProvider struct {
+	command string
+	run     commandRunner
+}
+
+// NewExecCredentialProvider returns a provider which runs command to
+// obtain credentials, e.g. a wrapper around Vault or a cloud keychain.
+func NewExecCredentialProvider(command string) CredentialProvider {
+	return &execCredentialProvider{command: command, run: runCommand}
+}
+
+func (p *execCredentialProvider) Name() string { return "exec" }
+
+func (p *execCredentialProvider) Credentials(ctx context.Context, registry string) (authn.AuthConfig, bool, error) {
+	out, err := p.run(ctx, registry, p.command, "get")
+	if err != nil {
+		if strings.Contains(err.Error(), credentialsNotFound) {
+			return authn.AuthConfig{}, false, nil
+		}
+		return authn.AuthConfig{}, false, err
+	}
+	var resp struct {
+		ServerURL string
+		Username  string
+		Secret    string
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return authn.AuthConfig{}, false, fmt.Errorf("error parsing output of %s: %v", p.command, err)
+	}
+	if resp.Secret == "" {
+		return authn.AuthConfig{}, false, nil
+	}
+	// Follow the credential helper convention of returning identity
+	// tokens with the "<token>" user name.
+	if resp.Username == "<token>" {
+		return authn.AuthConfig{IdentityToken: resp.Secret}, true, nil
+	}
+	return authn.AuthConfig{Username: resp.Username, Password: resp.Secret}, true, nil
+}
@@ -1,24 +1,21 @@
 package image
 
 import (
-	"errors"
-	"os"
-	"path/filepath"
-
-	dockercfg "github.com/docker/cli/cli/config"
 	"github.com/openshift/library-go/pkg/image/registryclient"
 	"github.com/openshift/oc/pkg/cli/image/manifest/dockercredentials"
 	"k8s.io/client-go/rest"
+
+	"github.com/openshift/oc-mirror/pkg/network"
 )
 
 // NewContext creates a context for the registryClient of `oc mirror`
 func NewContext(skipVerification bool) (*registryclient.Context, error) {
 	userAgent := rest.DefaultKubernetesUserAgent()
-	rt, err := rest.TransportFor(&rest.Config{UserAgent: userAgent})
+	rt, err := rest.TransportFor(&rest.Config{Transport: network.AuditManifests(network.ChunkUploads(network.CountTransfers(network.RouteRegistries(network.NewTransport(false))))), UserAgent: userAgent})
 	if err != nil {
 		return nil, err
 	}
-	insecureRT, err := rest.TransportFor(&rest.Config{TLSClientConfig: rest.TLSClientConfig{Insecure: true}, UserAgent: userAgent})
+	insecureRT, err := rest.TransportFor(&rest.Config{Transport: network.AuditManifests(network.ChunkUploads(network.CountTransfers(network.RouteRegistries(network.NewTransport(true))))), UserAgent: userAgent})
 	if err != nil {
 		return nil, err
 	}
@@ -26,25 +23,24 @@ func NewContext(skipVerification bool) (*registryclient.Context, error) {
 	ctx := registryclient.NewContext(rt, insecureRT)
 
 	// Set default options
-	var registryConfig string
-	dockerConfigJSON := filepath.Join(dockercfg.Dir(), dockercfg.ConfigFileName)
-	switch _, err := os.Stat(dockerConfigJSON); {
-	case err == nil:
-		registryConfig = dockerConfigJSON
-	case errors.Is(err, os.ErrNotExist):
-		podmanConfig := filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "containers/auth.json")
-		if _, err := os.Stat(podmanConfig); err == nil {
-			registryConfig = podmanConfig
-		} else if !os.IsNotExist(err) {
+	registryConfig := getAuthFile()
+	if len(registryConfig) == 0 {
+		if registryConfig, err = DefaultAuthFile(); err != nil {
 			return nil, err
 		}
 	}
 
+	var creds registryclient.CredentialStoreFactory
 	if len(registryConfig) != 0 {
-		creds, err := dockercredentials.NewCredentialStoreFactory(registryConfig)
+		creds, err = dockercredentials.NewCredentialStoreFactory(registryConfig)
 		if err != nil {
 			return nil, err
 		}
+	}
+	if providers := getCredentialProviders(); len(providers) != 0 {
+		creds = &providerCredentialStoreFactory{providers: providers, fallback: creds}
+	}
+	if creds != nil {
 		ctx.WithCredentialsFactory(creds)
 	}
 	ctx.Retries = 3
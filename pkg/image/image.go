@@ -6,7 +6,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	gcr "github.com/google/go-containerregistry/pkg/v1/layout"
@@ -65,7 +64,7 @@ func GetTagsFromImage(image string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	tags, err := remote.List(repo, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	tags, err := remote.List(repo, remote.WithAuthFromKeychain(Keychain()))
 	return tags, err
 }
 
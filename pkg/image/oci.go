@@ -0,0 +1,113 @@
+package image
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// ociMediaTypes maps the Docker media types to their OCI equivalent.
+var ociMediaTypes = map[types.MediaType]types.MediaType{
+	types.DockerManifestSchema2:   types.OCIManifestSchema1,
+	types.DockerManifestList:      types.OCIImageIndex,
+	types.DockerConfigJSON:        types.OCIConfigJSON,
+	types.DockerLayer:             types.OCILayer,
+	types.DockerForeignLayer:      types.OCIRestrictedLayer,
+	types.DockerUncompressedLayer: types.OCIUncompressedLayer,
+}
+
+// OCIMediaType returns the OCI media type equivalent to mt,
+// or mt if it is not a Docker media type.
+func OCIMediaType(mt types.MediaType) types.MediaType {
+	if oci, ok := ociMediaTypes[mt]; ok {
+		return oci
+	}
+	return mt
+}
+
+// ConvertImageToOCI returns img with OCI media types. The layers are
+// unchanged, so only the digests of the manifest and the config change.
+func ConvertImageToOCI(img v1.Image) (v1.Image, error) {
+	mt, err := img.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	if mt == types.OCIManifestSchema1 {
+		return img, nil
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	additions := make([]mutate.Addendum, 0, len(layers))
+	for _, layer := range layers {
+		lmt, err := layer.MediaType()
+		if err != nil {
+			return nil, err
+		}
+		additions = append(additions, mutate.Addendum{Layer: layer, MediaType: OCIMediaType(lmt)})
+	}
+	base := mutate.ConfigMediaType(mutate.MediaType(empty.Image, types.OCIManifestSchema1), types.OCIConfigJSON)
+	oci, err := mutate.Append(base, additions...)
+	if err != nil {
+		return nil, err
+	}
+	// Restore the history and the configuration of the image.
+	return mutate.ConfigFile(oci, cfg)
+}
+
+// ConvertManifestToOCI rewrites the raw image manifest or manifest list data
+// with OCI media types. The configs and the layers are unchanged. The
+// descriptors of the manifests of a list are replaced by the descriptors
+// returned by child, the descriptors of the converted child manifests.
+// Manifests that already use OCI media types are returned unchanged.
+func ConvertManifestToOCI(data []byte, child func(v1.Hash) (v1.Descriptor, error)) ([]byte, error) {
+	var meta struct {
+		MediaType types.MediaType `json:"mediaType"`
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	switch meta.MediaType {
+	case types.OCIManifestSchema1, types.OCIImageIndex:
+		return data, nil
+	case types.DockerManifestSchema2:
+		m, err := v1.ParseManifest(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		m.MediaType = types.OCIManifestSchema1
+		m.Config.MediaType = OCIMediaType(m.Config.MediaType)
+		for i := range m.Layers {
+			m.Layers[i].MediaType = OCIMediaType(m.Layers[i].MediaType)
+		}
+		return json.Marshal(m)
+	case types.DockerManifestList:
+		idx, err := v1.ParseIndexManifest(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		idx.MediaType = types.OCIImageIndex
+		for i, desc := range idx.Manifests {
+			converted, err := child(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			idx.Manifests[i].MediaType = converted.MediaType
+			idx.Manifests[i].Digest = converted.Digest
+			idx.Manifests[i].Size = converted.Size
+		}
+		return json.Marshal(idx)
+	default:
+		return nil, fmt.Errorf("manifest media type %q cannot be converted to OCI", meta.MediaType)
+	}
+}
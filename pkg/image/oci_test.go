@@ -0,0 +1,110 @@
+package image
+
+import (
+	"bytes"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertImageToOCI(t *testing.T) {
+	img, err := random.Image(64, 2)
+	require.NoError(t, err)
+	require.Equal(t, types.DockerManifestSchema2, mustMediaType(t, img))
+
+	oci, err := ConvertImageToOCI(img)
+	require.NoError(t, err)
+	m, err := oci.Manifest()
+	require.NoError(t, err)
+	require.Equal(t, types.OCIManifestSchema1, m.MediaType)
+	require.Equal(t, types.OCIConfigJSON, m.Config.MediaType)
+	require.Len(t, m.Layers, 2)
+
+	origManifest, err := img.Manifest()
+	require.NoError(t, err)
+	for i, layer := range m.Layers {
+		require.Equal(t, types.OCILayer, layer.MediaType)
+		require.Equal(t, origManifest.Layers[i].Digest, layer.Digest)
+	}
+	origCfg, err := img.ConfigFile()
+	require.NoError(t, err)
+	cfg, err := oci.ConfigFile()
+	require.NoError(t, err)
+	require.Equal(t, origCfg.RootFS, cfg.RootFS)
+
+	// OCI images are unchanged.
+	same, err := ConvertImageToOCI(oci)
+	require.NoError(t, err)
+	require.Equal(t, oci, same)
+}
+
+func TestConvertManifestToOCI(t *testing.T) {
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	ociImg, err := ConvertImageToOCI(img)
+	require.NoError(t, err)
+	ociDesc := mustDescriptor(t, ociImg)
+
+	t.Run("Valid/Manifest", func(t *testing.T) {
+		data, err := img.RawManifest()
+		require.NoError(t, err)
+		out, err := ConvertManifestToOCI(data, nil)
+		require.NoError(t, err)
+		m, err := v1.ParseManifest(bytes.NewReader(out))
+		require.NoError(t, err)
+		require.Equal(t, types.OCIManifestSchema1, m.MediaType)
+		require.Equal(t, types.OCIConfigJSON, m.Config.MediaType)
+		require.Equal(t, types.OCILayer, m.Layers[0].MediaType)
+	})
+
+	t.Run("Valid/ManifestList", func(t *testing.T) {
+		idx := mutate.IndexMediaType(mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: img}), types.DockerManifestList)
+		data, err := idx.RawManifest()
+		require.NoError(t, err)
+		var children []v1.Hash
+		out, err := ConvertManifestToOCI(data, func(h v1.Hash) (v1.Descriptor, error) {
+			children = append(children, h)
+			return ociDesc, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []v1.Hash{mustDescriptor(t, img).Digest}, children)
+		im, err := v1.ParseIndexManifest(bytes.NewReader(out))
+		require.NoError(t, err)
+		require.Equal(t, types.OCIImageIndex, im.MediaType)
+		require.Equal(t, ociDesc.Digest, im.Manifests[0].Digest)
+		require.Equal(t, ociDesc.Size, im.Manifests[0].Size)
+		require.Equal(t, types.OCIManifestSchema1, im.Manifests[0].MediaType)
+	})
+
+	t.Run("Valid/OCIManifest", func(t *testing.T) {
+		data, err := ociImg.RawManifest()
+		require.NoError(t, err)
+		out, err := ConvertManifestToOCI(data, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, out)
+	})
+
+	t.Run("Invalid/Schema1", func(t *testing.T) {
+		_, err := ConvertManifestToOCI([]byte(`{"schemaVersion":1,"mediaType":"application/vnd.docker.distribution.manifest.v1+prettyjws"}`), nil)
+		require.EqualError(t, err, `manifest media type "application/vnd.docker.distribution.manifest.v1+prettyjws" cannot be converted to OCI`)
+	})
+}
+
+func mustMediaType(t *testing.T, img v1.Image) types.MediaType {
+	mt, err := img.MediaType()
+	require.NoError(t, err)
+	return mt
+}
+
+func mustDescriptor(t *testing.T, img v1.Image) v1.Descriptor {
+	m, err := img.RawManifest()
+	require.NoError(t, err)
+	hash, size, err := v1.SHA256(bytes.NewReader(m))
+	require.NoError(t, err)
+	return v1.Descriptor{MediaType: mustMediaType(t, img), Digest: hash, Size: size}
+}
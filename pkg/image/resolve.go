@@ -11,6 +11,8 @@ import (
 	"github.com/containers/image/v5/types"
 	imgreference "github.com/openshift/library-go/pkg/image/reference"
 	"k8s.io/klog"
+
+	"github.com/openshift/oc-mirror/pkg/network"
 )
 
 func ResolveToPin(ctx context.Context, sourceCtx *types.SystemContext, unresolvedImage string) (string, error) {
@@ -21,9 +23,22 @@ func ResolveToPin(ctx context.Context, sourceCtx *types.SystemContext, unresolve
 		return "", err
 	}
 	ref = ref.DockerClientDefaults()
-	srcRef, err := alltransports.ParseImageName("docker://" + ref.String())
+	// The manifest of images of a mirrored registry is read from the mirror.
+	readRef := ref
+	if mirror := network.RegistryMirror(ref.Registry); mirror != "" {
+		readRef.Registry = mirror
+	}
+	srcRef, err := alltransports.ParseImageName("docker://" + readRef.String())
 	if err != nil {
-		return "", fmt.Errorf("invalid source name %s: %v", ref.String(), err)
+		return "", fmt.Errorf("invalid source name %s: %v", readRef.String(), err)
+	}
+
+	// Registries set as insecure or plain HTTP in the network package are
+	// not verified whatever the source context, which also allows HTTP.
+	if sourceCtx != nil && (network.IsInsecureRegistry(readRef.Registry) || network.IsPlainHTTPRegistry(readRef.Registry)) {
+		insecureCtx := *sourceCtx
+		insecureCtx.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+		sourceCtx = &insecureCtx
 	}
 
 	img, err := srcRef.NewImageSource(ctx, sourceCtx)
@@ -45,6 +60,8 @@ func ResolveToPin(ctx context.Context, sourceCtx *types.SystemContext, unresolve
 	return ref.String(), nil
 }
 
+// NewSystemContext returns the context of the containers/image requests of a run,
+// reading the credentials of the auth file set by SetAuthFile.
 func NewSystemContext(skipTLS bool, registriesConfigPath string) *types.SystemContext {
 	skipTLSVerify := types.OptionalBoolFalse
 	if skipTLS {
@@ -57,6 +74,8 @@ func NewSystemContext(skipTLS bool, registriesConfigPath string) *types.SystemCo
 		VariantChoice:               "",
 		BigFilesTemporaryDir:        "", //*globalArgs.cache + "/tmp",
 		DockerInsecureSkipTLSVerify: skipTLSVerify,
+		// The auth file set by SetAuthFile, if any, replaces the default one.
+		AuthFilePath: getAuthFile(),
 	}
 	if registriesConfigPath != "" {
 		ctx.SystemRegistriesConfPath = registriesConfigPath
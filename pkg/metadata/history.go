@@ -0,0 +1,129 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// ErrSequenceNotRecorded is returned when the workspace
+// holds no record of a sequence.
+var ErrSequenceNotRecorded = errors.New("sequence not recorded in the workspace")
+
+// sequenceAssociationsField is the JSON path of the associations of every
+// image mirrored as of a sequence, the PastAssociations of the metadata,
+// in the record of the sequence. They are streamed to and from the
+// record by WriteSequenceRecord and ReadSequenceRecord.
+const sequenceAssociationsField = "associations"
+
+// SequenceRecord records the images mirrored as of a sequence in
+// the workspace, so the content of two sequences can be compared.
+type SequenceRecord struct {
+	// Sequence is the sequence of the mirror operation.
+	Sequence int `json:"sequence"`
+	// Timestamp is the Unix time of the mirror operation.
+	Timestamp int `json:"timestamp"`
+	// BlobSizes are the sizes of the blobs transported by the
+	// sequence keyed by digest, unset when mirroring to mirror.
+	BlobSizes map[string]int64 `json:"blobSizes,omitempty"`
+}
+
+// NewSequenceRecord returns the SequenceRecord of meta
+// with the sizes of the blobs transported by the sequence.
+func NewSequenceRecord(meta v1alpha2.Metadata, blobSizes map[string]int64) SequenceRecord {
+	return SequenceRecord{
+		Sequence:  meta.PastMirror.Sequence,
+		Timestamp: meta.PastMirror.Timestamp,
+		BlobSizes: blobSizes,
+	}
+}
+
+// sequenceRecordPath returns the path of the record of seq in workspace.
+func sequenceRecordPath(workspace string, seq int) string {
+	return filepath.Join(workspace, config.HistoryDir, fmt.Sprintf("seq%d.json", seq))
+}
+
+// WriteSequenceRecord writes rec with the associations of assocs to the
+// history of workspace, replacing the record of a sequence created again.
+// The associations are streamed from assocs instead of held in memory.
+func WriteSequenceRecord(workspace string, rec SequenceRecord, assocs image.AssociationIterator) error {
+	fpath := sequenceRecordPath(workspace, rec.Sequence)
+	if err := os.MkdirAll(filepath.Dir(fpath), 0750); err != nil {
+		return err
+	}
+	tmp := fpath + ".tmp"
+	f, err := os.OpenFile(filepath.Clean(tmp), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := image.EncodeStreamed(f, rec, map[string]image.AssociationIterator{sequenceAssociationsField: assocs}); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, fpath)
+}
+
+// ReadSequenceRecord reads the record of seq from the history of workspace,
+// streaming its associations into an AssociationDB at dbPath. The associations
+// are skipped for an empty dbPath, and no database is returned.
+func ReadSequenceRecord(workspace string, seq int, dbPath string) (SequenceRecord, *image.AssociationDB, error) {
+	var rec SequenceRecord
+	f, err := os.Open(sequenceRecordPath(workspace, seq))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return rec, nil, fmt.Errorf("sequence %d: %w", seq, ErrSequenceNotRecorded)
+		}
+		return rec, nil, err
+	}
+	defer f.Close()
+	data, assocs, err := image.DecodeStreamed(f, map[string]string{sequenceAssociationsField: dbPath})
+	if err != nil {
+		return rec, nil, fmt.Errorf("error reading the record of sequence %d: %v", seq, err)
+	}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		assocs.Close()
+		return rec, nil, fmt.Errorf("error reading the record of sequence %d: %v", seq, err)
+	}
+	return rec, assocs[sequenceAssociationsField], nil
+}
+
+// ListSequenceRecords returns the sequences recorded in the history
+// of workspace, sorted.
+func ListSequenceRecords(workspace string) ([]int, error) {
+	entries, err := os.ReadDir(filepath.Join(workspace, config.HistoryDir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var seqs []int
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "seq") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		seq, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "seq"), ".json"))
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Ints(seqs)
+	return seqs, nil
+}
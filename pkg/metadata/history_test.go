@@ -0,0 +1,54 @@
+package metadata
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+func TestSequenceRecord(t *testing.T) {
+	workspace := t.TempDir()
+	meta := v1alpha2.NewMetadata()
+	meta.PastMirror.Sequence = 2
+	meta.PastMirror.Timestamp = 1700000000
+	assocs, err := image.ConvertToAssociationSet([]v1alpha2.Association{
+		{Name: "quay.io/foo/bar:v1", Path: "foo/bar", ID: "sha256:1111", Type: v1alpha2.TypeGeneric, LayerDigests: []string{"sha256:aaaa"}},
+	})
+	require.NoError(t, err)
+	rec := NewSequenceRecord(meta, map[string]int64{"sha256:aaaa": 42})
+	require.NoError(t, WriteSequenceRecord(workspace, rec, assocs))
+
+	dbPath := filepath.Join(t.TempDir(), "record.db")
+	read, db, err := ReadSequenceRecord(workspace, 2, dbPath)
+	require.NoError(t, err)
+	require.Equal(t, rec, read)
+	values, found, err := db.Search("quay.io/foo/bar:v1")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, []v1alpha2.Association{assocs["quay.io/foo/bar:v1"]["quay.io/foo/bar:v1"]}, values)
+	require.NoError(t, db.Close())
+
+	read, db, err = ReadSequenceRecord(workspace, 2, "")
+	require.NoError(t, err)
+	require.Equal(t, rec, read)
+	require.Nil(t, db)
+
+	_, _, err = ReadSequenceRecord(workspace, 1, "")
+	require.True(t, errors.Is(err, ErrSequenceNotRecorded))
+	require.EqualError(t, err, "sequence 1: sequence not recorded in the workspace")
+
+	meta.PastMirror.Sequence = 10
+	require.NoError(t, WriteSequenceRecord(workspace, NewSequenceRecord(meta, nil), image.AssociationSet{}))
+	seqs, err := ListSequenceRecords(workspace)
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 10}, seqs)
+
+	seqs, err = ListSequenceRecords(t.TempDir())
+	require.NoError(t, err)
+	require.Empty(t, seqs)
+}
@@ -13,7 +13,6 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
-	"github.com/openshift/oc-mirror/pkg/config"
 )
 
 var _ Backend = &localDirBackend{}
@@ -67,22 +66,20 @@ func (b *localDirBackend) ReadMetadata(_ context.Context, meta *v1alpha2.Metadat
 		return err
 	}
 
-	typeMeta, err := getTypeMeta(data)
-	if err != nil {
-		return err
-	}
+	return loadMetadata(data, meta)
+}
 
-	switch typeMeta.GroupVersionKind() {
-	case v1alpha2.GroupVersion.WithKind(v1alpha2.MetadataKind):
-		*meta, err = config.LoadMetadata(data)
-	default:
-		return fmt.Errorf("config GVK not recognized: %s", typeMeta.GroupVersionKind())
-	}
+// OpenMetadata opens the provided metadata on disk for reading.
+func (b *localDirBackend) OpenMetadata(_ context.Context, path string) (io.ReadCloser, error) {
+	f, err := b.fs.Open(path)
 	if err != nil {
-		return err
+		// Non-existent metadata is allowed.
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrMetadataNotExist
+		}
+		return nil, err
 	}
-
-	return nil
+	return f, nil
 }
 
 // WriteMetadata writes the provided metadata to disk.
@@ -117,6 +114,10 @@ func (b *localDirBackend) ReadObject(_ context.Context, fpath string, obj interf
 // In this implementation, key is a file path.
 func (b *localDirBackend) WriteObject(ctx context.Context, fpath string, obj interface{}) error {
 
+	if r, ok := obj.(io.Reader); ok {
+		return b.writeReader(ctx, fpath, r)
+	}
+
 	w, err := b.GetWriter(ctx, fpath)
 	if err != nil {
 		return err
@@ -129,8 +130,6 @@ func (b *localDirBackend) WriteObject(ctx context.Context, fpath string, obj int
 		data = v
 	case string:
 		data = []byte(v)
-	case io.Reader:
-		data, err = io.ReadAll(v)
 	default:
 		data, err = json.Marshal(obj)
 	}
@@ -142,6 +141,27 @@ func (b *localDirBackend) WriteObject(ctx context.Context, fpath string, obj int
 	return err
 }
 
+// writeReader streams r to fpath through a temporary file renamed
+// once complete, so that a failed write leaves fpath unchanged.
+func (b *localDirBackend) writeReader(ctx context.Context, fpath string, r io.Reader) error {
+	tmp := fpath + ".tmp"
+	w, err := b.GetWriter(ctx, tmp)
+	if err != nil {
+		return err
+	}
+	f := w.(io.WriteCloser)
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		b.fs.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		b.fs.Remove(tmp)
+		return err
+	}
+	return b.fs.Rename(tmp, fpath)
+}
+
 // GetWriter returns an os.File as a writer.
 // In this implementation, key is a file path.
 func (b *localDirBackend) GetWriter(_ context.Context, fpath string) (io.Writer, error) {
@@ -151,7 +171,7 @@ func (b *localDirBackend) GetWriter(_ context.Context, fpath string) (io.Writer,
 		return nil, fmt.Errorf("error creating object child path: %v", err)
 	}
 
-	w, err := b.fs.OpenFile(fpath, os.O_WRONLY|os.O_CREATE, 0640)
+	w, err := b.fs.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
 	if err != nil {
 		return nil, fmt.Errorf("error opening object file: %v", err)
 	}
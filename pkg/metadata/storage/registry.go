@@ -1,9 +1,8 @@
 package storage
 
 import (
+	"archive/tar"
 	"context"
-	"crypto/tls"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -16,15 +15,19 @@ import (
 	"time"
 
 	"github.com/distribution/distribution/v3/registry/client/auth"
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/mholt/archiver/v3"
 	"k8s.io/klog/v2"
 
 	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/image"
+	"github.com/openshift/oc-mirror/pkg/network"
 	"github.com/openshift/oc/pkg/cli/image/imagesource"
 )
 
@@ -77,6 +80,18 @@ func (b *registryBackend) ReadMetadata(ctx context.Context, meta *v1alpha2.Metad
 	return b.localDirBackend.ReadMetadata(ctx, meta, path)
 }
 
+// OpenMetadata unpacks the metadata image and opens it on disk for reading.
+func (b *registryBackend) OpenMetadata(ctx context.Context, path string) (io.ReadCloser, error) {
+	klog.V(1).Infof("Checking for existing metadata image at %s", b.src)
+	if err := b.exists(ctx); err != nil {
+		return nil, err
+	}
+	if err := b.unpack(ctx, path); err != nil {
+		return nil, err
+	}
+	return b.localDirBackend.OpenMetadata(ctx, path)
+}
+
 // WriteMetadata writes the provided metadata to disk anf registry.
 func (b *registryBackend) WriteMetadata(ctx context.Context, meta *v1alpha2.Metadata, path string) error {
 	return b.WriteObject(ctx, path, meta)
@@ -90,28 +105,13 @@ func (b *registryBackend) ReadObject(ctx context.Context, fpath string, obj inte
 
 // WriteObject writes the provided object to disk and registry.
 // In this implementation, key is a file path.
-func (b *registryBackend) WriteObject(ctx context.Context, fpath string, obj interface{}) (err error) {
-	var data []byte
-	switch v := obj.(type) {
-	case []byte:
-		data = v
-	case string:
-		data = []byte(v)
-	case io.Reader:
-		data, err = io.ReadAll(v)
-	default:
-		data, err = json.Marshal(obj)
-	}
-	if err != nil {
-		return err
-	}
-
+func (b *registryBackend) WriteObject(ctx context.Context, fpath string, obj interface{}) error {
 	// Write metadata to disk for packing into archive
 	if err := b.localDirBackend.WriteObject(ctx, fpath, obj); err != nil {
 		return err
 	}
 	klog.V(1).Infof("Pushing metadata to registry at %s", b.src)
-	return b.pushImage(ctx, data, fpath)
+	return b.pushFile(ctx, fpath)
 }
 
 // GetWriter returns an os.File as a writer.
@@ -196,14 +196,54 @@ func (b *registryBackend) CheckConfig(storage v1alpha2.StorageConfig) error {
 	return nil
 }
 
-// pushImage will push a v1.Image with provided contents
-func (b *registryBackend) pushImage(ctx context.Context, data []byte, fpath string) error {
-	opts := b.getOpts(ctx)
-	contents := map[string][]byte{
-		fpath: data,
+// pushFile will push a v1.Image with the file at fpath on disk as its
+// contents, streamed from disk instead of read in memory.
+func (b *registryBackend) pushFile(ctx context.Context, fpath string) error {
+	info, err := b.localDirBackend.fs.Stat(fpath)
+	if err != nil {
+		return err
 	}
-	i, _ := crane.Image(contents)
-	return crane.Push(i, b.src.Ref.Exact(), opts...)
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		f, err := b.localDirBackend.fs.Open(fpath)
+		if err != nil {
+			return nil, err
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			defer f.Close()
+			tw := tar.NewWriter(pw)
+			if err := tw.WriteHeader(&tar.Header{Name: fpath, Size: info.Size()}); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := io.Copy(tw, f); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			pw.CloseWithError(tw.Close())
+		}()
+		return pr, nil
+	})
+	if err != nil {
+		return err
+	}
+	i, err := mutate.AppendLayers(empty.Image, layer)
+	if err != nil {
+		return err
+	}
+	return crane.Push(i, b.src.Ref.Exact(), b.getOpts(ctx)...)
+}
+
+// CheckHealth checks that the registry of the metadata image responds to a v2 endpoint.
+func (b *registryBackend) CheckHealth(ctx context.Context) error {
+	reg, err := url.Parse("https://" + b.src.Ref.Registry)
+	if err != nil {
+		return err
+	}
+	if err := b.ping(ctx, *reg); err != nil {
+		return fmt.Errorf("registry %s is unreachable: %w", b.src.Ref.Registry, err)
+	}
+	return nil
 }
 
 // exists checks if the image exists
@@ -236,7 +276,7 @@ func (b *registryBackend) exists(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
-		err = remote.CheckPushPermission(ref, authn.DefaultKeychain, b.createRT())
+		err = remote.CheckPushPermission(ref, image.Keychain(), b.createRT())
 		if err != nil {
 			return err
 		}
@@ -249,8 +289,8 @@ func (b *registryBackend) exists(ctx context.Context) error {
 }
 
 func (b *registryBackend) createRT() http.RoundTripper {
-	return &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
+	return network.AuditManifests(network.ChunkUploads(network.RouteRegistries(&http.Transport{
+		Proxy: network.Proxy,
 		DialContext: (&net.Dialer{
 			// By default we wrap the transport in retries, so reduce the
 			// default dial timeout to 5s to avoid 5x 30s of connection
@@ -263,17 +303,15 @@ func (b *registryBackend) createRT() http.RoundTripper {
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: b.insecure,
-		},
-	}
+		TLSClientConfig:       network.TLSConfig(b.insecure),
+	})))
 }
 
 // TODO: Get default auth will need to update if user
 // can specify custom locations
 func (b *registryBackend) getOpts(ctx context.Context) []crane.Option {
 	options := []crane.Option{
-		crane.WithAuthFromKeychain(authn.DefaultKeychain),
+		crane.WithAuthFromKeychain(image.Keychain()),
 		crane.WithContext(ctx),
 		crane.WithTransport(b.createRT()),
 	}
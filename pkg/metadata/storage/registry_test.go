@@ -121,3 +121,25 @@ func TestRegistryBackend(t *testing.T) {
 		})
 	}
 }
+
+func TestRegistryBackendCheckHealth(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	cfg := v1alpha2.RegistryConfig{
+		ImageURL: u.Host + "/metadata:latest",
+		SkipTLS:  true,
+	}
+	backend, err := NewRegistryBackend(&cfg, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, CheckHealth(context.Background(), backend))
+
+	server.Close()
+	require.ErrorContains(t, CheckHealth(context.Background(), backend), "is unreachable")
+
+	// Local backends are always reachable.
+	local, err := NewLocalBackend(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, CheckHealth(context.Background(), local))
+}
@@ -43,6 +43,22 @@ type Committer interface {
 	Commit(context.Context) error
 }
 
+// HealthChecker is a Backend whose underlying storage can be unreachable,
+// like a registry, and that can check it is reachable before writing to it.
+type HealthChecker interface {
+	// CheckHealth returns an error if the storage of the Backend is unreachable.
+	CheckHealth(context.Context) error
+}
+
+// CheckHealth checks that the storage of b is reachable,
+// Backends not implementing HealthChecker being always reachable.
+func CheckHealth(ctx context.Context, b Backend) error {
+	if hc, ok := b.(HealthChecker); ok {
+		return hc.CheckHealth(ctx)
+	}
+	return nil
+}
+
 var backends = []Backend{
 	&localDirBackend{},
 	&registryBackend{},
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// MetadataOpener is a Backend that opens its metadata for streaming reads,
+// instead of reading it whole like ReadMetadata.
+type MetadataOpener interface {
+	// OpenMetadata opens the metadata at path for reading, returning
+	// ErrMetadataNotExist when no metadata is found.
+	OpenMetadata(context.Context, string) (io.ReadCloser, error)
+}
+
+// OpenMetadata opens the metadata at path of b for reading,
+// with Open for Backends not implementing MetadataOpener.
+func OpenMetadata(ctx context.Context, b Backend, path string) (io.ReadCloser, error) {
+	if o, ok := b.(MetadataOpener); ok {
+		return o.OpenMetadata(ctx, path)
+	}
+	return b.Open(ctx, path)
+}
+
+// ReadMetadataStream reads the metadata at path of b into meta, streaming the
+// Association arrays at the paths of dbPaths into AssociationDBs, like
+// image.DecodeStreamed, instead of decoding them in memory. Closing the
+// returned databases removes them.
+func ReadMetadataStream(ctx context.Context, b Backend, meta *v1alpha2.Metadata, path string, dbPaths map[string]string) (image.StreamedAssociations, error) {
+	r, err := OpenMetadata(ctx, b, path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, assocs, err := image.DecodeStreamed(r, dbPaths)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadMetadata(data, meta); err != nil {
+		assocs.Close()
+		return nil, err
+	}
+	return assocs, nil
+}
+
+// ReadMetadataWithoutAssociations reads the metadata at path of b into meta,
+// discarding its Associations, for callers only needing its other fields.
+func ReadMetadataWithoutAssociations(ctx context.Context, b Backend, meta *v1alpha2.Metadata, path string) error {
+	_, err := ReadMetadataStream(ctx, b, meta, path, map[string]string{
+		image.PastAssociationsField:     "",
+		image.MirroredAssociationsField: "",
+	})
+	return err
+}
+
+// WriteMetadataStream writes meta to path of b, with the Associations of each
+// iterator of assocs streamed as the array at its path, like image.EncodeStreamed,
+// instead of encoded from meta in memory.
+func WriteMetadataStream(ctx context.Context, b Backend, meta *v1alpha2.Metadata, path string, assocs map[string]image.AssociationIterator) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(image.EncodeStreamed(pw, meta, assocs))
+	}()
+	err := b.WriteObject(ctx, path, pr)
+	// Unblocks the encoding if the write stopped reading it.
+	pr.Close()
+	return err
+}
+
+// CopyMetadata copies the metadata at path of src to
+// the same path of dst, streamed instead of read whole.
+func CopyMetadata(ctx context.Context, src, dst Backend, path string) error {
+	r, err := OpenMetadata(ctx, src, path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return dst.WriteObject(ctx, path, r)
+}
+
+// loadMetadata decodes the metadata of data into meta.
+func loadMetadata(data []byte, meta *v1alpha2.Metadata) error {
+	typeMeta, err := getTypeMeta(data)
+	if err != nil {
+		return err
+	}
+
+	switch typeMeta.GroupVersionKind() {
+	case v1alpha2.GroupVersion.WithKind(v1alpha2.MetadataKind):
+		*meta, err = config.LoadMetadata(data)
+	default:
+		return fmt.Errorf("config GVK not recognized: %s", typeMeta.GroupVersionKind())
+	}
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 
 	"github.com/containers/image/v5/types"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/pkg/image/containerdregistry"
 	"github.com/sirupsen/logrus"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -18,28 +19,27 @@ import (
 	"github.com/openshift/oc-mirror/pkg/config"
 	"github.com/openshift/oc-mirror/pkg/image"
 	"github.com/openshift/oc-mirror/pkg/metadata/storage"
+	"github.com/openshift/oc-mirror/pkg/network"
 	"github.com/openshift/oc-mirror/pkg/operator"
 )
 
-// SyncMetadata copies Metadata from one Backend to another
+// SyncMetadata copies Metadata from one Backend to another,
+// streamed instead of read in memory.
 func SyncMetadata(ctx context.Context, first storage.Backend, second storage.Backend) error {
-	var meta v1alpha2.Metadata
-	if err := first.ReadMetadata(ctx, &meta, config.MetadataBasePath); err != nil {
-		return fmt.Errorf("error reading metadata: %v", err)
-	}
-	// Add mirror as a new PastMirror
-	if err := second.WriteMetadata(ctx, &meta, config.MetadataBasePath); err != nil {
-		return fmt.Errorf("error writing metadata: %v", err)
+	if err := storage.CopyMetadata(ctx, first, second, config.MetadataBasePath); err != nil {
+		return fmt.Errorf("error copying metadata: %v", err)
 	}
 	return nil
 }
 
 // UpdateMetadata runs some reconciliation functions on Metadata to ensure its state is consistent
-// then uses the Backend to update the metadata storage medium.
-func UpdateMetadata(ctx context.Context, backend storage.Backend, meta *v1alpha2.Metadata, workspace string, skipTLSVerify, plainHTTP bool) error {
+// then uses the Backend to update the metadata storage medium. The Associations of each iterator
+// of assocs are streamed to the metadata as the array at its path, see storage.WriteMetadataStream.
+func UpdateMetadata(ctx context.Context, backend storage.Backend, meta *v1alpha2.Metadata, assocs map[string]image.AssociationIterator, workspace string, skipTLSVerify, plainHTTP bool) error {
 	pastMeta := v1alpha2.NewMetadata()
 	pastReleases := map[string]string{}
-	merr := backend.ReadMetadata(ctx, &pastMeta, config.MetadataBasePath)
+	// Only the platforms of the past metadata are used, its associations are skipped.
+	merr := storage.ReadMetadataWithoutAssociations(ctx, backend, &pastMeta, config.MetadataBasePath)
 	if merr != nil && !errors.Is(merr, storage.ErrMetadataNotExist) {
 		return merr
 	} else if merr == nil {
@@ -67,6 +67,7 @@ func UpdateMetadata(ctx context.Context, backend storage.Backend, meta *v1alpha2
 		containerdregistry.WithCacheDir(cacheDir),
 		containerdregistry.SkipTLSVerify(skipTLSVerify),
 		containerdregistry.WithPlainHTTP(plainHTTP),
+		containerdregistry.WithRootCAs(network.RootCAs()),
 		// The containerd registry impl is somewhat verbose, even on the happy path,
 		// so discard all logger logs. Any important failures will be returned from
 		// registry methods and eventually logged as fatal errors.
@@ -113,7 +114,7 @@ func UpdateMetadata(ctx context.Context, backend storage.Backend, meta *v1alpha2
 	}
 
 	// Add mirror as a new PastMirror
-	if err := backend.WriteMetadata(ctx, meta, config.MetadataBasePath); err != nil {
+	if err := storage.WriteMetadataStream(ctx, backend, meta, config.MetadataBasePath, assocs); err != nil {
 		return fmt.Errorf("error writing metadata: %v", err)
 	}
 
@@ -129,7 +130,8 @@ func resolveOperatorMetadata(ctx context.Context, ctlg v1alpha2.Operator, reg *c
 
 	// Stick to Catalog here because we
 	// are referencing the source
-	if !image.IsImagePinned(ctlg.Catalog) {
+	// Catalogs read from a directory have no image to pin.
+	if !image.IsImagePinned(ctlg.Catalog) && !ctlg.IsFBCDir() {
 		if ctlg.IsFBCOCI() {
 			ref, err := image.ParseReference(ctlg.Catalog)
 			if err != nil {
@@ -148,25 +150,47 @@ func resolveOperatorMetadata(ctx context.Context, ctlg v1alpha2.Operator, reg *c
 	}
 
 	var ic v1alpha2.IncludeConfig
-	// Only collect the information
-	// for heads only work flows for conversions from ranges
-	// or full catalogs to heads only.
-	if ctlg.IsHeadsOnly() {
-
-		if ctlg.IsFBCOCI() {
-			ctlgName = v1alpha2.OCITransportPrefix + "//" + ctlgName
-		}
-		// Determine the location of the created FBC
-		tir, err := image.ParseReference(ctlgName)
-		if err != nil {
-			return v1alpha2.OperatorMetadata{}, err
+	hasSelectedBundles := false
+	for _, pkg := range ctlg.Packages {
+		if len(pkg.SelectedBundles) != 0 {
+			hasSelectedBundles = true
 		}
-		ctlgRef := tir.Ref
-		ctlgLoc, err := operator.GenerateCatalogDir(ctlgRef)
+	}
+	if !ctlg.IsHeadsOnly() && !hasSelectedBundles {
+		return operatorMeta, nil
+	}
+
+	if ctlg.IsFBCOCI() {
+		ctlgName = v1alpha2.OCITransportPrefix + "//" + ctlgName
+	}
+	// Determine the location of the created FBC
+	tir, err := image.ParseReference(ctlgName)
+	if err != nil {
+		return v1alpha2.OperatorMetadata{}, err
+	}
+	ctlgLoc, err := operator.GenerateCatalogDir(tir.Ref)
+	if err != nil {
+		return v1alpha2.OperatorMetadata{}, err
+	}
+	indexDir := filepath.Join(workspace, config.CatalogsDir, ctlgLoc, config.IndexDir)
+	dc, err := declcfg.LoadFS(ctx, os.DirFS(indexDir))
+	if err != nil {
+		return operatorMeta, fmt.Errorf("error loading catalog index: %v", err)
+	}
+
+	// Record the dependency closure of the selected bundles,
+	// so the next runs mirror it even if the catalog drops it.
+	if hasSelectedBundles {
+		operatorMeta.PinnedBundles, err = operator.PinnedBundles(*dc, ctlg.IncludeConfig)
 		if err != nil {
-			return v1alpha2.OperatorMetadata{}, err
+			return operatorMeta, fmt.Errorf("error resolving pinned bundles of catalog %q: %v", ctlgName, err)
 		}
+	}
 
+	// Only collect the information
+	// for heads only work flows for conversions from ranges
+	// or full catalogs to heads only.
+	if ctlg.IsHeadsOnly() {
 		icLoc := filepath.Join(workspace, config.CatalogsDir, ctlgLoc, config.IncludeConfigFile)
 		includeFile, err := os.Open(icLoc)
 		if err != nil {
@@ -178,6 +202,11 @@ func resolveOperatorMetadata(ctx context.Context, ctlg v1alpha2.Operator, reg *c
 			return operatorMeta, fmt.Errorf("error decoding include config file: %v", err)
 		}
 
+		// Record the channel heads of the created FBC for diffs by channel head movement.
+		operatorMeta.ChannelHeads, err = operator.ChannelHeads(*dc)
+		if err != nil {
+			return operatorMeta, fmt.Errorf("error resolving channel heads of catalog %q: %v", ctlgName, err)
+		}
 	}
 
 	operatorMeta.IncludeConfig = ic
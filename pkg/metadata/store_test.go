@@ -15,6 +15,7 @@ func TestUpdateMetadata_Catalogs(t *testing.T) {
 		name     string
 		config   v1alpha2.ImageSetConfiguration
 		expIC    v1alpha2.IncludeConfig
+		expHeads []v1alpha2.ChannelHead
 		expError string
 	}
 
@@ -95,6 +96,12 @@ func TestUpdateMetadata_Catalogs(t *testing.T) {
 					},
 				},
 			},
+			expHeads: []v1alpha2.ChannelHead{
+				{Package: "bar", Channel: "alpha", Bundle: "bar.v0.1.0"},
+				{Package: "bar", Channel: "stable", Bundle: "bar.v1.0.0"},
+				{Package: "baz", Channel: "stable", Bundle: "baz.v1.1.0"},
+				{Package: "foo", Channel: "beta", Bundle: "foo.v0.3.2"},
+			},
 		},
 	}
 
@@ -109,12 +116,14 @@ func TestUpdateMetadata_Catalogs(t *testing.T) {
 			}
 			backend, err := storage.ByConfig("", cfg)
 			require.NoError(t, err)
-			err = UpdateMetadata(context.TODO(), backend, &inputMeta, "testdata", true, true)
+			err = UpdateMetadata(context.TODO(), backend, &inputMeta, nil, "testdata", true, true)
 			if c.expError != "" {
 				require.EqualError(t, err, c.expError)
 			} else {
 				require.NoError(t, err)
-				require.Equal(t, c.expIC, inputMeta.PastMirror.Operators[len(inputMeta.PastMirror.Operators)-1].IncludeConfig)
+				operatorMeta := inputMeta.PastMirror.Operators[len(inputMeta.PastMirror.Operators)-1]
+				require.Equal(t, c.expIC, operatorMeta.IncludeConfig)
+				require.Equal(t, c.expHeads, operatorMeta.ChannelHeads)
 			}
 		})
 	}
@@ -186,7 +195,7 @@ func TestUpdateMetadata_OCPReleases(t *testing.T) {
 			}
 			backend, err := storage.ByConfig("", cfg)
 			require.NoError(t, err)
-			err = UpdateMetadata(context.TODO(), backend, &inputMeta, "testdata", true, true)
+			err = UpdateMetadata(context.TODO(), backend, &inputMeta, nil, "testdata", true, true)
 			if c.expError != "" {
 				require.EqualError(t, err, c.expError)
 			} else {
@@ -0,0 +1,4 @@
+// Package mirrorlib provides a Go API to plan, create, publish and delete
+// imagesets from an ImageSetConfiguration, for tools that embed oc-mirror
+// instead of running the oc-mirror command.
+package mirrorlib
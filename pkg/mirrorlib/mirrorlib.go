@@ -0,0 +1,160 @@
+package mirrorlib
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+	"github.com/openshift/oc-mirror/pkg/cli"
+	"github.com/openshift/oc-mirror/pkg/cli/mirror"
+	"github.com/openshift/oc-mirror/pkg/config"
+	"github.com/openshift/oc-mirror/pkg/image"
+)
+
+// Options configures the mirroring operations. The zero value of each field
+// matches the default of the corresponding oc-mirror flag.
+type Options struct {
+	// Destination is where images are mirrored to, either
+	// docker://<registry>[/<namespace>] or file://<directory>.
+	Destination string
+	// From is the path to an imageset archive, used by Publish.
+	From string
+	// Workspace is the directory holding the working files of oc-mirror.
+	// Defaults to oc-mirror-workspace, in the directory of a file:// destination.
+	Workspace string
+	// Profile is the name of an ImageSetConfiguration profile merged onto the base configuration.
+	Profile string
+
+	SourceSkipTLS   bool // Disable TLS validation for source registry
+	DestSkipTLS     bool // Disable TLS validation for destination registry
+	SourcePlainHTTP bool // Use plain HTTP for source registry
+	DestPlainHTTP   bool // Use plain HTTP for destination registry
+	DryRun          bool // Print actions without mirroring images
+	SkipPruning     bool // Disable pruning of images no longer in the imageset
+	SkipMissing     bool // Skip images that are not found
+	ContinueOnError bool // Keep going when an error occurs, if possible
+	SkipPreflight   bool // Skip the registry readiness checks run before publishing
+	MaxPerRegistry  int  // Number of concurrent requests allowed per registry, defaults to 6
+	MaxNestedPaths  int  // Number of nested paths, for destination registries that limit them
+
+	// Out and ErrOut receive the output of the operations, they default
+	// to the standard output and error of the process.
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+// Plan returns the images the ImageSetConfiguration resolves to, mapped to the
+// destination, without mirroring them.
+func Plan(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, opts Options) (image.TypedImageMapping, error) {
+	o, err := newMirrorOptions(opts, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	return o.Plan(ctx)
+}
+
+// Create mirrors the images of the ImageSetConfiguration to the destination:
+// into an imageset archive for a file:// destination or directly to the
+// registry for a docker:// destination.
+func Create(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, opts Options) error {
+	if len(opts.From) > 0 {
+		return errors.New("From cannot be set when creating an imageset, use Publish instead")
+	}
+	o, err := newMirrorOptions(opts, &cfg)
+	if err != nil {
+		return err
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	return o.Mirror(ctx)
+}
+
+// Publish mirrors the imageset archive found at From to the docker:// destination.
+func Publish(ctx context.Context, opts Options) error {
+	if len(opts.From) == 0 {
+		return errors.New("must specify the path to an imageset archive with From")
+	}
+	if !strings.HasPrefix(opts.Destination, "docker://") {
+		return errors.New("must specify a docker:// destination to publish to")
+	}
+	o, err := newMirrorOptions(opts, nil)
+	if err != nil {
+		return err
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	return o.Mirror(ctx)
+}
+
+// Delete removes the images mirrored for the ImageSetConfiguration, and
+// the associated metadata, from the docker:// destination. The
+// ImageSetConfiguration must set the storageConfig the metadata is kept in.
+func Delete(ctx context.Context, cfg v1alpha2.ImageSetConfiguration, opts Options) error {
+	if !strings.HasPrefix(opts.Destination, "docker://") {
+		return errors.New("must specify a docker:// destination to delete from")
+	}
+	o, err := newMirrorOptions(opts, &cfg)
+	if err != nil {
+		return err
+	}
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	return o.DeleteImageSet(ctx)
+}
+
+// newMirrorOptions translates opts to the options of the oc-mirror command.
+func newMirrorOptions(opts Options, cfg *v1alpha2.ImageSetConfiguration) (*mirror.MirrorOptions, error) {
+	if len(opts.Destination) == 0 {
+		return nil, errors.New("must specify a destination")
+	}
+	ro := &cli.RootOptions{
+		IOStreams: genericclioptions.IOStreams{
+			In:     os.Stdin,
+			Out:    opts.Out,
+			ErrOut: opts.ErrOut,
+		},
+		Dir: opts.Workspace,
+	}
+	if ro.Out == nil {
+		ro.Out = os.Stdout
+	}
+	if ro.ErrOut == nil {
+		ro.ErrOut = os.Stderr
+	}
+	if ro.Dir == "" {
+		ro.Dir = config.DefaultWorkspaceName
+	}
+
+	o := mirror.NewMirrorOptions(ro)
+	o.ImageSetConfig = cfg
+	o.From = opts.From
+	o.Profile = opts.Profile
+	o.SourceSkipTLS = opts.SourceSkipTLS
+	o.DestSkipTLS = opts.DestSkipTLS
+	o.SourcePlainHTTP = opts.SourcePlainHTTP
+	o.DestPlainHTTP = opts.DestPlainHTTP
+	o.DryRun = opts.DryRun
+	o.SkipPruning = opts.SkipPruning
+	o.SkipMissing = opts.SkipMissing
+	o.ContinueOnError = opts.ContinueOnError
+	o.SkipPreflight = opts.SkipPreflight
+	o.MaxNestedPaths = opts.MaxNestedPaths
+	if opts.MaxPerRegistry > 0 {
+		o.MaxPerRegistry = opts.MaxPerRegistry
+	}
+
+	// The destination is parsed as the argument of the oc-mirror command.
+	if err := o.Complete(&cobra.Command{}, []string{opts.Destination}); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
@@ -0,0 +1,93 @@
+package mirrorlib
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func TestNewMirrorOptions(t *testing.T) {
+	type spec struct {
+		name      string
+		opts      Options
+		expDir    string
+		expOutput string
+		expMirror string
+		expNs     string
+		expMax    int
+		expError  string
+	}
+
+	specs := []spec{
+		{
+			name:      "Valid/FileDestination",
+			opts:      Options{Destination: "file://archives"},
+			expDir:    filepath.Join("archives", "oc-mirror-workspace"),
+			expOutput: "archives",
+			expMax:    6,
+		},
+		{
+			name:      "Valid/RegistryDestination",
+			opts:      Options{Destination: "docker://reg.com/ns", Workspace: "ws", MaxPerRegistry: 2},
+			expDir:    "ws",
+			expMirror: "reg.com",
+			expNs:     "ns",
+			expMax:    2,
+		},
+		{
+			name:     "Invalid/NoDestination",
+			opts:     Options{},
+			expError: "must specify a destination",
+		},
+		{
+			name:     "Invalid/UnknownScheme",
+			opts:     Options{Destination: "s3://bucket"},
+			expError: `unknown destination scheme "s3"`,
+		},
+	}
+
+	for _, s := range specs {
+		t.Run(s.name, func(t *testing.T) {
+			cfg := &v1alpha2.ImageSetConfiguration{}
+			o, err := newMirrorOptions(s.opts, cfg)
+			if s.expError != "" {
+				require.EqualError(t, err, s.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, s.expDir, o.Dir)
+			require.Equal(t, s.expOutput, o.OutputDir)
+			require.Equal(t, s.expMirror, o.ToMirror)
+			require.Equal(t, s.expNs, o.UserNamespace)
+			require.Equal(t, s.expMax, o.MaxPerRegistry)
+			require.Same(t, cfg, o.ImageSetConfig)
+			require.True(t, o.RebuildCatalogs)
+		})
+	}
+}
+
+func TestWorkflowArguments(t *testing.T) {
+	ctx := context.Background()
+	cfg := v1alpha2.ImageSetConfiguration{}
+
+	t.Run("Invalid/CreateWithFrom", func(t *testing.T) {
+		err := Create(ctx, cfg, Options{Destination: "file://archives", From: "mirror_seq1_000000.tar"})
+		require.EqualError(t, err, "From cannot be set when creating an imageset, use Publish instead")
+	})
+	t.Run("Invalid/PublishWithoutFrom", func(t *testing.T) {
+		err := Publish(ctx, Options{Destination: "docker://reg.com"})
+		require.EqualError(t, err, "must specify the path to an imageset archive with From")
+	})
+	t.Run("Invalid/PublishToFile", func(t *testing.T) {
+		err := Publish(ctx, Options{Destination: "file://archives", From: "mirror_seq1_000000.tar"})
+		require.EqualError(t, err, "must specify a docker:// destination to publish to")
+	})
+	t.Run("Invalid/DeleteFromFile", func(t *testing.T) {
+		err := Delete(ctx, cfg, Options{Destination: "file://archives"})
+		require.EqualError(t, err, "must specify a docker:// destination to delete from")
+	})
+}
@@ -0,0 +1,194 @@
+package network
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// The actions of the manifest changes reported to the manifest auditor.
+const (
+	// ManifestPush is the push of a manifest by digest.
+	ManifestPush = "push"
+	// ManifestTag is the push of a manifest by tag, creating or updating the tag.
+	ManifestTag = "tag"
+	// ManifestDelete is the deletion of a manifest or of a tag.
+	ManifestDelete = "delete"
+	// ManifestSkip is a manifest not pushed as it already exists, reported with AuditManifest.
+	ManifestSkip = "skip"
+)
+
+// ManifestChange is a change of a manifest of a registry made
+// through the round trippers returned by AuditManifests.
+type ManifestChange struct {
+	Action     string // ManifestPush, ManifestTag, ManifestDelete or ManifestSkip
+	Registry   string // Host of the registry, before any mirror set by SetRegistries
+	Repository string
+	Reference  string // Tag or digest of the request
+	// DigestBefore is the digest the reference resolved to before the change,
+	// empty when it did not exist or the registry did not return it.
+	DigestBefore string
+	// DigestAfter is the digest of the manifest pushed, empty for deletions.
+	DigestAfter string
+}
+
+// manifestAuditor is called with every change made by the round trippers
+// returned by AuditManifests, nil when the changes are not audited.
+var manifestAuditor func(ManifestChange)
+
+// SetManifestAuditor sets the function called with every successful change of
+// a manifest made through the round trippers returned by AuditManifests,
+// replacing any previous one. A nil auditor stops the auditing. The auditor
+// may be called concurrently.
+func SetManifestAuditor(auditor func(ManifestChange)) {
+	mu.Lock()
+	defer mu.Unlock()
+	manifestAuditor = auditor
+}
+
+// AuditManifest reports change, not made through the round trippers
+// returned by AuditManifests, to the auditor set by SetManifestAuditor.
+func AuditManifest(change ManifestChange) {
+	if auditor := getManifestAuditor(); auditor != nil {
+		auditor(change)
+	}
+}
+
+func getManifestAuditor() func(ManifestChange) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return manifestAuditor
+}
+
+// AuditManifests returns a round tripper reporting the manifests pushed and
+// deleted through next to the auditor set by SetManifestAuditor. The digest
+// tags resolve to before they are pushed or deleted is read first with a HEAD
+// request, sent through next with the credentials of the change.
+func AuditManifests(next http.RoundTripper) http.RoundTripper {
+	return &auditRoundTripper{next: next}
+}
+
+type auditRoundTripper struct {
+	next http.RoundTripper
+}
+
+// manifestMediaTypes are the media types accepted when resolving the digest of a tag.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.v1+prettyjws",
+}
+
+// manifestPath returns the repository and reference of the manifest
+// of the registry API path, or false when it is not a manifest path.
+func manifestPath(path string) (repository, reference string, ok bool) {
+	repoPath, ok := strings.CutPrefix(path, "/v2/")
+	if !ok {
+		return "", "", false
+	}
+	i := strings.LastIndex(repoPath, "/manifests/")
+	if i <= 0 {
+		return "", "", false
+	}
+	reference = repoPath[i+len("/manifests/"):]
+	if reference == "" || strings.Contains(reference, "/") {
+		return "", "", false
+	}
+	return repoPath[:i], reference, true
+}
+
+func (rt *auditRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	auditor := getManifestAuditor()
+	if auditor == nil || (req.Method != http.MethodPut && req.Method != http.MethodDelete) {
+		return rt.next.RoundTrip(req)
+	}
+	repository, reference, ok := manifestPath(req.URL.Path)
+	if !ok {
+		return rt.next.RoundTrip(req)
+	}
+
+	change := ManifestChange{
+		Action:     ManifestPush,
+		Registry:   req.URL.Host,
+		Repository: repository,
+		Reference:  reference,
+	}
+	byDigest := digest.Digest(reference).Validate() == nil
+	switch {
+	case req.Method == http.MethodDelete:
+		change.Action = ManifestDelete
+		if byDigest {
+			change.DigestBefore = reference
+		} else {
+			change.DigestBefore = rt.resolve(req)
+		}
+	case !byDigest:
+		change.Action = ManifestTag
+		change.DigestBefore = rt.resolve(req)
+	}
+
+	var hasher hash.Hash
+	if req.Method == http.MethodPut && req.Body != nil && req.Body != http.NoBody {
+		// The request is cloned as a round tripper must not modify it.
+		hasher = sha256.New()
+		hashed := req.Clone(req.Context())
+		hashed.Body = &hashingReadCloser{ReadCloser: req.Body, w: hasher}
+		req = hashed
+	}
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, err
+	}
+	if hasher != nil {
+		change.DigestAfter = resp.Header.Get("Docker-Content-Digest")
+		if change.DigestAfter == "" {
+			change.DigestAfter = "sha256:" + hex.EncodeToString(hasher.Sum(nil))
+		}
+	}
+	auditor(change)
+	return resp, nil
+}
+
+// resolve returns the digest of the manifest of the URL of req,
+// or an empty string when it does not exist or cannot be read.
+func (rt *auditRoundTripper) resolve(req *http.Request) string {
+	head, err := http.NewRequestWithContext(req.Context(), http.MethodHead, req.URL.String(), nil)
+	if err != nil {
+		return ""
+	}
+	head.Header.Set("Accept", strings.Join(manifestMediaTypes, ","))
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		head.Header.Set("Authorization", auth)
+	}
+	if ua := req.Header.Get("User-Agent"); ua != "" {
+		head.Header.Set("User-Agent", ua)
+	}
+	resp, err := rt.next.RoundTrip(head)
+	if err != nil {
+		return ""
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	return resp.Header.Get("Docker-Content-Digest")
+}
+
+type hashingReadCloser struct {
+	io.ReadCloser
+	w io.Writer
+}
+
+func (r *hashingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.w.Write(p[:n])
+	return n, err
+}
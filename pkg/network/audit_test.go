@@ -0,0 +1,99 @@
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditManifests(t *testing.T) {
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var (
+		mu      sync.Mutex
+		changes []ManifestChange
+	)
+	SetManifestAuditor(func(c ManifestChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, c)
+	})
+	t.Cleanup(func() { SetManifestAuditor(nil) })
+	opt := remote.WithTransport(AuditManifests(http.DefaultTransport))
+
+	tag, err := name.NewTag(u.Host + "/test/audit:latest")
+	require.NoError(t, err)
+	first, err := random.Image(100, 1)
+	require.NoError(t, err)
+	firstDigest, err := first.Digest()
+	require.NoError(t, err)
+	second, err := random.Image(100, 1)
+	require.NoError(t, err)
+	secondDigest, err := second.Digest()
+	require.NoError(t, err)
+
+	require.NoError(t, remote.Write(tag, first, opt))
+	require.NoError(t, remote.Write(tag, second, opt))
+	require.NoError(t, remote.Delete(tag.Context().Digest(secondDigest.String()), opt))
+
+	require.Equal(t, []ManifestChange{
+		{
+			Action:      ManifestTag,
+			Registry:    u.Host,
+			Repository:  "test/audit",
+			Reference:   "latest",
+			DigestAfter: firstDigest.String(),
+		},
+		{
+			Action:       ManifestTag,
+			Registry:     u.Host,
+			Repository:   "test/audit",
+			Reference:    "latest",
+			DigestBefore: firstDigest.String(),
+			DigestAfter:  secondDigest.String(),
+		},
+		{
+			Action:       ManifestDelete,
+			Registry:     u.Host,
+			Repository:   "test/audit",
+			Reference:    secondDigest.String(),
+			DigestBefore: secondDigest.String(),
+		},
+	}, changes)
+}
+
+func TestManifestPath(t *testing.T) {
+	type spec struct {
+		name        string
+		path        string
+		expRepo     string
+		expRef      string
+		expManifest bool
+	}
+	cases := []spec{
+		{name: "Valid/Tag", path: "/v2/ns/repo/manifests/latest", expRepo: "ns/repo", expRef: "latest", expManifest: true},
+		{name: "Valid/Digest", path: "/v2/repo/manifests/sha256:abc", expRepo: "repo", expRef: "sha256:abc", expManifest: true},
+		{name: "Invalid/Blob", path: "/v2/repo/blobs/sha256:abc"},
+		{name: "Invalid/NoReference", path: "/v2/repo/manifests/"},
+		{name: "Invalid/NotAPI", path: "/api/v1/repository/manifests/latest"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			repo, ref, ok := manifestPath(c.path)
+			require.Equal(t, c.expManifest, ok)
+			require.Equal(t, c.expRepo, repo)
+			require.Equal(t, c.expRef, ref)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+var (
+	mu         sync.RWMutex
+	proxyFunc  func(*url.URL) (*url.URL, error)
+	trustedCAs []byte
+	// insecureHosts holds the hosts whose certificates are not verified.
+	insecureHosts map[string]bool
+)
+
+// Configure sets the proxy and the trusted certificate authorities of the
+// HTTP clients created afterwards from the proxy of the imageset configuration.
+// Environment variables referenced in the proxy URLs are expanded, and proxy
+// settings that are not set fall back to the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY environment variables. The environment variables are updated with
+// the configured settings so the clients created by libraries reading the
+// environment use the same proxy.
+func Configure(cfg *v1alpha2.Proxy) error {
+	if cfg == nil {
+		cfg = &v1alpha2.Proxy{}
+	}
+	var pemCerts []byte
+	if cfg.TrustedCA != "" {
+		data, err := os.ReadFile(cfg.TrustedCA)
+		if err != nil {
+			return fmt.Errorf("error reading trusted CA bundle: %v", err)
+		}
+		if !x509.NewCertPool().AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates found in trusted CA bundle %s", cfg.TrustedCA)
+		}
+		pemCerts = data
+	}
+
+	proxyCfg := httpproxy.FromEnvironment()
+	settings := []struct {
+		value string
+		env   string
+		dst   *string
+	}{
+		{cfg.HTTPProxy, "HTTP_PROXY", &proxyCfg.HTTPProxy},
+		{cfg.HTTPSProxy, "HTTPS_PROXY", &proxyCfg.HTTPSProxy},
+		{cfg.NoProxy, "NO_PROXY", &proxyCfg.NoProxy},
+	}
+	for _, setting := range settings {
+		if setting.value == "" {
+			continue
+		}
+		value := os.ExpandEnv(setting.value)
+		if err := os.Setenv(setting.env, value); err != nil {
+			return err
+		}
+		*setting.dst = value
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	proxyFunc = proxyCfg.ProxyFunc()
+	trustedCAs = pemCerts
+	return nil
+}
+
+// Proxy returns the URL of the proxy to use for req, as expected by
+// http.Transport. It uses the environment until Configure is called.
+func Proxy(req *http.Request) (*url.URL, error) {
+	mu.RLock()
+	f := proxyFunc
+	mu.RUnlock()
+	if f == nil {
+		return http.ProxyFromEnvironment(req)
+	}
+	return f(req.URL)
+}
+
+// SetInsecureRegistries sets the registry hosts whose TLS certificates are not
+// verified by the HTTP clients created afterwards, while the certificates of
+// every other host are verified. Hosts are matched by name, ports are ignored.
+// IP addresses are rejected as they are not sent by the clients in the TLS
+// handshake. It replaces any configuration set by SetRegistries.
+func SetInsecureRegistries(hosts []string) error {
+	registries := make([]Registry, 0, len(hosts))
+	for _, host := range hosts {
+		if registryHostname(host) == "" {
+			continue
+		}
+		registries = append(registries, Registry{Location: host, Insecure: true})
+	}
+	return SetRegistries(registries)
+}
+
+// IsInsecureRegistry returns whether the certificates of the
+// registry host are not verified, as set by SetInsecureRegistries.
+func IsInsecureRegistry(host string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return insecureHosts[registryHostname(host)]
+}
+
+// registryHostname returns the lower-cased name of a registry host,
+// with the port and any repository path removed.
+func registryHostname(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host, _, _ = strings.Cut(host, "/")
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		return hostname
+	}
+	return host
+}
+
+// RootCAs returns the system certificate authorities with the trusted
+// ones added. It returns nil, which stands for the system certificate
+// authorities, when no trusted certificate authority is configured.
+func RootCAs() *x509.CertPool {
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(trustedCAs) == 0 {
+		return nil
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		klog.Warningf("unable to load the system certificate authorities, only trusting the configured ones: %v", err)
+		pool = x509.NewCertPool()
+	}
+	pool.AppendCertsFromPEM(trustedCAs)
+	return pool
+}
+
+// TLSConfig returns the TLS configuration of the HTTP clients.
+// When insecure is false, the certificates of the hosts set by
+// SetInsecureRegistries are still not verified.
+func TLSConfig(insecure bool) *tls.Config {
+	cfg := &tls.Config{
+		RootCAs:            RootCAs(),
+		InsecureSkipVerify: insecure,
+		MinVersion:         tls.VersionTLS12,
+	}
+	mu.RLock()
+	hasInsecureHosts := len(insecureHosts) != 0
+	mu.RUnlock()
+	if !insecure && hasInsecureHosts {
+		// The default verification is disabled so it
+		// can be skipped per host in VerifyConnection.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = verifyConnection(cfg.RootCAs)
+	}
+	return cfg
+}
+
+// verifyConnection returns a tls.Config VerifyConnection callback verifying
+// the certificates of the hosts not set by SetInsecureRegistries as the
+// default verification does.
+func verifyConnection(roots *x509.CertPool) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if cs.ServerName == "" {
+			// Hosts addressed by IP address send no server name,
+			// so the certificate cannot be matched to the host.
+			return fmt.Errorf("tls: cannot verify the certificate of a host addressed by IP address when insecure registries are set")
+		}
+		if IsInsecureRegistry(cs.ServerName) {
+			return nil
+		}
+		if len(cs.PeerCertificates) == 0 {
+			return fmt.Errorf("tls: no certificate presented by %s", cs.ServerName)
+		}
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			DNSName:       cs.ServerName,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
+
+// NewTransport returns an HTTP transport using Proxy and TLSConfig,
+// with the default timeouts.
+func NewTransport(insecure bool) *http.Transport {
+	return utilnet.SetTransportDefaults(&http.Transport{
+		Proxy:           Proxy,
+		TLSClientConfig: TLSConfig(insecure),
+	})
+}
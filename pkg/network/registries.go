@@ -0,0 +1,159 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Registry is the configuration of a registry host, read from the
+// [[registry]] tables of a registries configuration file in the style
+// of containers-registries.conf:
+//
+//	[[registry]]
+//	location = "registry.internal.example.com:5000"
+//	insecure = true
+//	plain-http = false
+//	mirror-of = ["quay.io"]
+type Registry struct {
+	// Location is the host of the registry, with an optional port.
+	Location string `toml:"location"`
+	// Insecure disables the verification of the TLS certificates of the registry.
+	Insecure bool `toml:"insecure"`
+	// PlainHTTP sends the requests to the registry over HTTP instead of HTTPS.
+	PlainHTTP bool `toml:"plain-http"`
+	// MirrorOf lists the registry hosts whose requests are sent to the registry instead.
+	MirrorOf []string `toml:"mirror-of"`
+}
+
+type registriesConfig struct {
+	Registries []Registry `toml:"registry"`
+}
+
+var (
+	// plainHTTPHosts holds the hosts the requests are sent to over HTTP.
+	plainHTTPHosts map[string]bool
+	// mirrorHosts holds the location of the mirror of registry hosts.
+	mirrorHosts map[string]string
+)
+
+// LoadRegistries reads the registries of the registries configuration file at path.
+func LoadRegistries(path string) ([]Registry, error) {
+	var cfg registriesConfig
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error reading registries configuration %s: %v", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) != 0 {
+		return nil, fmt.Errorf("unknown keys in registries configuration %s: %v", path, undecoded)
+	}
+	return cfg.Registries, nil
+}
+
+// SetRegistries sets the configuration of the registries used by the HTTP clients
+// created afterwards, replacing any previous configuration. Hosts are matched by
+// name, ports are ignored. The certificates of insecure registries are not verified,
+// the requests to plain HTTP registries are sent over HTTP, and the requests to the
+// registries listed by MirrorOf are sent to the location of their mirror by the
+// round trippers returned by RouteRegistries.
+func SetRegistries(registries []Registry) error {
+	insecure := map[string]bool{}
+	plainHTTP := map[string]bool{}
+	mirrors := map[string]string{}
+	for _, reg := range registries {
+		hostname := registryHostname(reg.Location)
+		if hostname == "" {
+			return fmt.Errorf("registry location must be set")
+		}
+		if reg.Insecure {
+			if net.ParseIP(strings.Trim(hostname, "[]")) != nil {
+				return fmt.Errorf("insecure registry %q must be a host name, not an IP address", reg.Location)
+			}
+			insecure[hostname] = true
+		}
+		if reg.PlainHTTP {
+			plainHTTP[hostname] = true
+		}
+		for _, source := range reg.MirrorOf {
+			sourceHostname := registryHostname(source)
+			switch location, found := mirrors[sourceHostname]; {
+			case sourceHostname == "":
+				return fmt.Errorf("mirror-of of registry %q must not be empty", reg.Location)
+			case sourceHostname == hostname:
+				return fmt.Errorf("registry %q cannot be a mirror of itself", reg.Location)
+			case found && location != registryHost(reg.Location):
+				return fmt.Errorf("registry %q is a mirror of both %q and %q", source, location, reg.Location)
+			}
+			mirrors[sourceHostname] = registryHost(reg.Location)
+		}
+	}
+	for source, location := range mirrors {
+		if _, found := mirrors[registryHostname(location)]; found {
+			return fmt.Errorf("mirror %q of registry %q cannot be mirrored itself", location, source)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	insecureHosts = insecure
+	plainHTTPHosts = plainHTTP
+	mirrorHosts = mirrors
+	return nil
+}
+
+// IsPlainHTTPRegistry returns whether the requests to
+// the registry host are sent over HTTP, as set by SetRegistries.
+func IsPlainHTTPRegistry(host string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return plainHTTPHosts[registryHostname(host)]
+}
+
+// RegistryMirror returns the location of the mirror of the registry host,
+// or an empty string when it has no mirror, as set by SetRegistries.
+func RegistryMirror(host string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return mirrorHosts[registryHostname(host)]
+}
+
+// registryHost returns the lower-cased host of a registry location,
+// with its port and any repository path removed.
+func registryHost(location string) string {
+	host, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(location)), "/")
+	return host
+}
+
+// RouteRegistries returns a round tripper sending the requests of next to
+// the mirror of their registry host, over HTTP for plain HTTP registries.
+func RouteRegistries(next http.RoundTripper) http.RoundTripper {
+	return &registryRoundTripper{next: next}
+}
+
+type registryRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt *registryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if mirror := RegistryMirror(host); mirror != "" {
+		host = mirror
+	}
+	scheme := req.URL.Scheme
+	if scheme == "https" && IsPlainHTTPRegistry(host) {
+		scheme = "http"
+	}
+	if host == req.URL.Host && scheme == req.URL.Scheme {
+		return rt.next.RoundTrip(req)
+	}
+
+	// The request is cloned as a round tripper must not modify it.
+	routed := req.Clone(req.Context())
+	routed.URL.Host = host
+	routed.URL.Scheme = scheme
+	routed.Host = ""
+	return rt.next.RoundTrip(routed)
+}
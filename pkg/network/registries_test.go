@@ -0,0 +1,136 @@
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRegistries(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+		return path
+	}
+
+	registries, err := LoadRegistries(write("registries.conf", `
+[[registry]]
+location = "registry.internal.example.com:5000"
+insecure = true
+mirror-of = ["quay.io"]
+
+[[registry]]
+location = "localhost:5000"
+plain-http = true
+`))
+	require.NoError(t, err)
+	require.Equal(t, []Registry{
+		{Location: "registry.internal.example.com:5000", Insecure: true, MirrorOf: []string{"quay.io"}},
+		{Location: "localhost:5000", PlainHTTP: true},
+	}, registries)
+
+	path := write("unknown.conf", "[[registry]]\nlocation = \"quay.io\"\nblocked = true\n")
+	_, err = LoadRegistries(path)
+	require.EqualError(t, err, "unknown keys in registries configuration "+path+": [registry.blocked]")
+}
+
+func TestSetRegistries(t *testing.T) {
+	type spec struct {
+		name       string
+		registries []Registry
+		expError   string
+	}
+
+	cases := []spec{
+		{
+			name: "Valid/Registries",
+			registries: []Registry{
+				{Location: "registry.internal.example.com:5000", Insecure: true, MirrorOf: []string{"quay.io", "registry.redhat.io"}},
+				{Location: "10.0.0.5:5000", PlainHTTP: true},
+			},
+		},
+		{
+			name:       "Invalid/NoLocation",
+			registries: []Registry{{Insecure: true}},
+			expError:   "registry location must be set",
+		},
+		{
+			name:       "Invalid/InsecureIPAddress",
+			registries: []Registry{{Location: "10.0.0.5:5000", Insecure: true}},
+			expError:   `insecure registry "10.0.0.5:5000" must be a host name, not an IP address`,
+		},
+		{
+			name:       "Invalid/MirrorOfItself",
+			registries: []Registry{{Location: "quay.io", MirrorOf: []string{"QUAY.io:443"}}},
+			expError:   `registry "quay.io" cannot be a mirror of itself`,
+		},
+		{
+			name: "Invalid/TwoMirrors",
+			registries: []Registry{
+				{Location: "mirror1.example.com", MirrorOf: []string{"quay.io"}},
+				{Location: "mirror2.example.com", MirrorOf: []string{"quay.io"}},
+			},
+			expError: `registry "quay.io" is a mirror of both "mirror1.example.com" and "mirror2.example.com"`,
+		},
+		{
+			name: "Invalid/MirroredMirror",
+			registries: []Registry{
+				{Location: "mirror1.example.com", MirrorOf: []string{"quay.io"}},
+				{Location: "mirror2.example.com", MirrorOf: []string{"mirror1.example.com"}},
+			},
+			expError: `mirror "mirror1.example.com" of registry "quay.io" cannot be mirrored itself`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reset(t)
+			err := SetRegistries(c.registries)
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, IsInsecureRegistry("registry.internal.example.com"))
+			require.True(t, IsPlainHTTPRegistry("10.0.0.5"))
+			require.False(t, IsPlainHTTPRegistry("registry.internal.example.com"))
+			require.Equal(t, "registry.internal.example.com:5000", RegistryMirror("registry.redhat.io:443"))
+			require.Empty(t, RegistryMirror("docker.io"))
+		})
+	}
+}
+
+func TestRouteRegistries(t *testing.T) {
+	reset(t)
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Host+r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+	mirror := server.Listener.Addr().String()
+
+	require.NoError(t, SetRegistries([]Registry{
+		{Location: mirror, PlainHTTP: true, MirrorOf: []string{"quay.io"}},
+	}))
+	client := &http.Client{Transport: RouteRegistries(NewTransport(false))}
+
+	t.Run("Valid/MirroredPlainHTTP", func(t *testing.T) {
+		resp, err := client.Get("https://quay.io/v2/ns/image/manifests/latest")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, []string{mirror + "/v2/ns/image/manifests/latest"}, paths)
+	})
+
+	t.Run("Valid/PlainHTTP", func(t *testing.T) {
+		paths = nil
+		resp, err := client.Get("https://" + mirror + "/v2/")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, []string{mirror + "/v2/"}, paths)
+	})
+}
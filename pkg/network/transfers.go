@@ -0,0 +1,58 @@
+package network
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+var (
+	// bytesReceived and bytesSent count the bytes of the bodies of the
+	// responses and requests of the round trippers returned by CountTransfers.
+	bytesReceived atomic.Int64
+	bytesSent     atomic.Int64
+)
+
+// TransferredBytes returns the bytes received and sent in the bodies of
+// the responses and requests of the round trippers returned by CountTransfers.
+func TransferredBytes() (received, sent int64) {
+	return bytesReceived.Load(), bytesSent.Load()
+}
+
+// CountTransfers returns a round tripper counting the bytes of the
+// bodies of the requests and responses of next, as they are read.
+func CountTransfers(next http.RoundTripper) http.RoundTripper {
+	return &countingRoundTripper{next: next}
+}
+
+type countingRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		// The request is cloned as a round tripper must not modify it.
+		counted := req.Clone(req.Context())
+		counted.Body = &countingReadCloser{ReadCloser: req.Body, count: &bytesSent}
+		req = counted
+	}
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Body != nil {
+		resp.Body = &countingReadCloser{ReadCloser: resp.Body, count: &bytesReceived}
+	}
+	return resp, nil
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,38 @@
+package network
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountTransfers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Write([]byte(strings.Repeat("r", 2*len(body))))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: CountTransfers(http.DefaultTransport)}
+	received, sent := TransferredBytes()
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("12345"))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Len(t, body, 10)
+
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	nowReceived, nowSent := TransferredBytes()
+	require.Equal(t, int64(10), nowReceived-received)
+	require.Equal(t, int64(5), nowSent-sent)
+}
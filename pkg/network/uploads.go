@@ -0,0 +1,155 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	// uploadChunkSize is the maximum size of the body of the blob upload
+	// requests of the round trippers returned by ChunkUploads, 0 for no limit.
+	uploadChunkSize int64
+	// uploadSlots holds a token per blob upload in progress through the round
+	// trippers returned by ChunkUploads, nil for no limit.
+	uploadSlots chan struct{}
+)
+
+// SetUploadLimits sets the limits of the blob uploads of the round trippers
+// returned by ChunkUploads, replacing any previous limits: the maximum size
+// of the body of an upload request, and the maximum number of blob uploads
+// in progress at the same time. 0 means no limit.
+func SetUploadLimits(chunkSize int64, maxUploads int) error {
+	if chunkSize < 0 {
+		return fmt.Errorf("upload chunk size must not be negative")
+	}
+	if maxUploads < 0 {
+		return fmt.Errorf("maximum number of concurrent uploads must not be negative")
+	}
+	var slots chan struct{}
+	if maxUploads > 0 {
+		slots = make(chan struct{}, maxUploads)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	uploadChunkSize = chunkSize
+	uploadSlots = slots
+	return nil
+}
+
+func uploadLimits() (int64, chan struct{}) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return uploadChunkSize, uploadSlots
+}
+
+// ChunkUploads returns a round tripper applying the limits set by
+// SetUploadLimits to the blob uploads sent through next. The blob upload
+// requests larger than the chunk size are sent as successive chunks of the
+// chunk size at most, each sent to the upload location returned for the
+// previous one, as registries limiting the size of the requests expect.
+func ChunkUploads(next http.RoundTripper) http.RoundTripper {
+	return &uploadRoundTripper{next: next}
+}
+
+type uploadRoundTripper struct {
+	next http.RoundTripper
+}
+
+// isBlobUpload returns whether req sends the data of a blob upload.
+func isBlobUpload(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody || !strings.Contains(req.URL.Path, "/blobs/uploads/") {
+		return false
+	}
+	return req.Method == http.MethodPatch || (req.Method == http.MethodPut && req.ContentLength != 0)
+}
+
+func (rt *uploadRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isBlobUpload(req) {
+		return rt.next.RoundTrip(req)
+	}
+	chunkSize, slots := uploadLimits()
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-req.Context().Done():
+			req.Body.Close()
+			return nil, req.Context().Err()
+		}
+	}
+	// Monolithic uploads are completed by their PUT, they cannot be chunked.
+	if chunkSize == 0 || req.Method != http.MethodPatch || (req.ContentLength > 0 && req.ContentLength <= chunkSize) {
+		return rt.next.RoundTrip(req)
+	}
+	return rt.sendChunks(req, chunkSize)
+}
+
+// sendChunks sends the body of the blob upload request req in chunks of
+// chunkSize bytes at most, returning the response to the last chunk.
+func (rt *uploadRoundTripper) sendChunks(req *http.Request, chunkSize int64) (*http.Response, error) {
+	defer req.Body.Close()
+	// A request already sending a chunk starts at its offset.
+	var offset int64
+	if contentRange := req.Header.Get("Content-Range"); contentRange != "" {
+		start, _, _ := strings.Cut(strings.TrimPrefix(contentRange, "bytes="), "-")
+		var err error
+		if offset, err = strconv.ParseInt(start, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid Content-Range %q of blob upload: %v", contentRange, err)
+		}
+	}
+
+	location := req.URL
+	chunk, err := readChunk(req.Body, chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		// The next chunk is read first, the response to the last chunk is returned.
+		next, err := readChunk(req.Body, chunkSize)
+		if err != nil {
+			return nil, err
+		}
+		chunkReq := req.Clone(req.Context())
+		chunkReq.URL = location
+		chunkReq.Host = ""
+		chunkReq.Body = io.NopCloser(bytes.NewReader(chunk))
+		chunkReq.GetBody = nil
+		chunkReq.ContentLength = int64(len(chunk))
+		if len(chunk) > 0 {
+			chunkReq.Header.Set("Content-Range", fmt.Sprintf("%d-%d", offset, offset+int64(len(chunk))-1))
+		} else {
+			chunkReq.Header.Del("Content-Range")
+		}
+		resp, err := rt.next.RoundTrip(chunkReq)
+		if err != nil {
+			return nil, err
+		}
+		// Registries answer 202 Accepted, some 204 No Content.
+		if len(next) == 0 || resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return resp, nil
+		}
+		nextLocation, err := resp.Location()
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("blob upload chunk of %s returned no location: %v", location.Redacted(), err)
+		}
+		location = location.ResolveReference(nextLocation)
+		offset += int64(len(chunk))
+		chunk = next
+	}
+}
+
+// readChunk reads up to chunkSize bytes of r.
+func readChunk(r io.Reader, chunkSize int64) ([]byte, error) {
+	chunk, err := io.ReadAll(io.LimitReader(r, chunkSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading blob upload: %v", err)
+	}
+	return chunk, nil
+}
@@ -0,0 +1,106 @@
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkUploads(t *testing.T) {
+	type spec struct {
+		name       string
+		chunkSize  int64
+		maxUploads int
+	}
+	cases := []spec{
+		{name: "Valid/NoLimits"},
+		{name: "Valid/ChunkSize", chunkSize: 100},
+		{name: "Valid/ChunkSizeAndMaxUploads", chunkSize: 100, maxUploads: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.NoError(t, SetUploadLimits(c.chunkSize, c.maxUploads))
+			t.Cleanup(func() { require.NoError(t, SetUploadLimits(0, 0)) })
+
+			var (
+				mu                 sync.Mutex
+				patches, largest   int64
+				inProgress, maxNow int
+			)
+			reg := registry.New()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPatch {
+					mu.Lock()
+					patches++
+					if r.ContentLength > largest {
+						largest = r.ContentLength
+					}
+					inProgress++
+					if inProgress > maxNow {
+						maxNow = inProgress
+					}
+					mu.Unlock()
+					defer func() {
+						mu.Lock()
+						inProgress--
+						mu.Unlock()
+					}()
+				}
+				reg.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			u, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			ref, err := name.ParseReference(u.Host + "/test/chunks:latest")
+			require.NoError(t, err)
+			img, err := random.Image(1024, 3)
+			require.NoError(t, err)
+
+			rt := ChunkUploads(http.DefaultTransport)
+			require.NoError(t, remote.Write(ref, img, remote.WithTransport(rt), remote.WithJobs(4)))
+
+			got, err := remote.Image(ref, remote.WithTransport(rt))
+			require.NoError(t, err)
+			wantDigest, err := img.Digest()
+			require.NoError(t, err)
+			gotDigest, err := got.Digest()
+			require.NoError(t, err)
+			require.Equal(t, wantDigest, gotDigest)
+			layers, err := got.Layers()
+			require.NoError(t, err)
+			for _, l := range layers {
+				rc, err := l.Compressed()
+				require.NoError(t, err)
+				require.NoError(t, rc.Close())
+			}
+
+			require.NotZero(t, patches)
+			if c.chunkSize > 0 {
+				require.LessOrEqual(t, largest, c.chunkSize)
+				require.Greater(t, patches, int64(len(layers)))
+			}
+			if c.maxUploads > 0 {
+				require.LessOrEqual(t, maxNow, c.maxUploads)
+			}
+		})
+	}
+}
+
+func TestSetUploadLimits(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetUploadLimits(0, 0)) })
+	require.Error(t, SetUploadLimits(-1, 0))
+	require.Error(t, SetUploadLimits(0, -1))
+	require.NoError(t, SetUploadLimits(5<<20, 2))
+	chunkSize, slots := uploadLimits()
+	require.Equal(t, int64(5<<20), chunkSize)
+	require.Equal(t, 2, cap(slots))
+}
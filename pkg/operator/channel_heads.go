@@ -0,0 +1,98 @@
+package operator
+
+import (
+	"sort"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// ChannelHeads returns the head bundle of each channel
+// in the declarative config, sorted by package and channel.
+func ChannelHeads(dc declcfg.DeclarativeConfig) ([]v1alpha2.ChannelHead, error) {
+	inputModel, err := declcfg.ConvertToModel(dc)
+	if err != nil {
+		return nil, err
+	}
+	var heads []v1alpha2.ChannelHead
+	for _, mpkg := range inputModel {
+		for _, ch := range mpkg.Channels {
+			head, err := ch.Head()
+			if err != nil {
+				return nil, err
+			}
+			heads = append(heads, v1alpha2.ChannelHead{
+				Package: mpkg.Name,
+				Channel: ch.Name,
+				Bundle:  head.Name,
+			})
+		}
+	}
+	sort.Slice(heads, func(i, j int) bool {
+		if heads[i].Package != heads[j].Package {
+			return heads[i].Package < heads[j].Package
+		}
+		return heads[i].Channel < heads[j].Channel
+	})
+	return heads, nil
+}
+
+// IncludeConfigFromHeads returns an IncludeConfig starting each channel of the declarative config
+// at the channel head recorded in prev, so the diff holds the bundles added since the last mirror
+// whatever the digest of the catalog. New channels, and channels whose recorded head is no longer
+// in the catalog, start at their current head. Packages and channels with versions set in the
+// current IncludeConfig are kept as configured.
+func IncludeConfigFromHeads(dc declcfg.DeclarativeConfig, prev []v1alpha2.ChannelHead, curr v1alpha2.IncludeConfig) (ic v1alpha2.IncludeConfig, err error) {
+	inputModel, err := declcfg.ConvertToModel(dc)
+	if err != nil {
+		return ic, err
+	}
+
+	prevHeads := make(map[string]map[string]string, len(prev))
+	for _, head := range prev {
+		if prevHeads[head.Package] == nil {
+			prevHeads[head.Package] = map[string]string{}
+		}
+		prevHeads[head.Package][head.Channel] = head.Bundle
+	}
+
+	currPackages := make(map[string]v1alpha2.IncludePackage, len(curr.Packages))
+	for _, pkg := range curr.Packages {
+		currPackages[pkg.Name] = pkg
+	}
+
+	for _, mpkg := range inputModel {
+		currPkg, found := currPackages[mpkg.Name]
+		if found && includePackageVersionsSet(currPkg) {
+			ic.Packages = append(ic.Packages, currPkg)
+			continue
+		}
+
+		currChannels := make(map[string]v1alpha2.IncludeChannel, len(currPkg.Channels))
+		for _, ch := range currPkg.Channels {
+			currChannels[ch.Name] = ch
+		}
+
+		icPkg := v1alpha2.IncludePackage{Name: mpkg.Name}
+		for _, ch := range mpkg.Channels {
+			newCh, found := currChannels[ch.Name]
+			if !found || !includeChannelVersionsSet(newCh) {
+				newCh = v1alpha2.IncludeChannel{Name: ch.Name}
+				if prevBundle, found := ch.Bundles[prevHeads[mpkg.Name][ch.Name]]; found {
+					newCh.IncludeBundle = v1alpha2.IncludeBundle{MinVersion: prevBundle.Version.String()}
+				} else {
+					newCh.IncludeBundle, err = getHeadBundle(*ch)
+					if err != nil {
+						return ic, err
+					}
+				}
+			}
+			icPkg.Channels = append(icPkg.Channels, newCh)
+		}
+		sortChannels(icPkg.Channels)
+		ic.Packages = append(ic.Packages, icPkg)
+	}
+	sortPackages(ic.Packages)
+	return ic, nil
+}
@@ -0,0 +1,140 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// headsCatalog holds a foo package whose stable channel moved from
+// foo.v0.1.0 to foo.v0.3.0 and a new bar package.
+var headsCatalog = declcfg.DeclarativeConfig{
+	Packages: []declcfg.Package{
+		{Schema: "olm.package", Name: "bar", DefaultChannel: "stable"},
+		{Schema: "olm.package", Name: "foo", DefaultChannel: "stable"},
+	},
+	Channels: []declcfg.Channel{
+		{Schema: "olm.channel", Name: "stable", Package: "bar", Entries: []declcfg.ChannelEntry{
+			{Name: "bar.v0.1.0"},
+			{Name: "bar.v0.2.0", Replaces: "bar.v0.1.0"},
+		}},
+		{Schema: "olm.channel", Name: "stable", Package: "foo", Entries: []declcfg.ChannelEntry{
+			{Name: "foo.v0.1.0"},
+			{Name: "foo.v0.2.0", Replaces: "foo.v0.1.0"},
+			{Name: "foo.v0.3.0", Replaces: "foo.v0.2.0"},
+		}},
+		{Schema: "olm.channel", Name: "fast", Package: "foo", Entries: []declcfg.ChannelEntry{
+			{Name: "foo.v0.3.0"},
+		}},
+	},
+	Bundles: []declcfg.Bundle{
+		headsBundle("bar", "0.1.0"),
+		headsBundle("bar", "0.2.0"),
+		headsBundle("foo", "0.1.0"),
+		headsBundle("foo", "0.2.0"),
+		headsBundle("foo", "0.3.0"),
+	},
+}
+
+func headsBundle(pkg, version string) declcfg.Bundle {
+	return declcfg.Bundle{
+		Schema:     "olm.bundle",
+		Name:       pkg + ".v" + version,
+		Package:    pkg,
+		Image:      "reg/" + pkg + ":v" + version,
+		Properties: []property.Property{property.MustBuildPackage(pkg, version)},
+	}
+}
+
+func TestChannelHeads(t *testing.T) {
+	heads, err := ChannelHeads(headsCatalog)
+	require.NoError(t, err)
+	require.Equal(t, []v1alpha2.ChannelHead{
+		{Package: "bar", Channel: "stable", Bundle: "bar.v0.2.0"},
+		{Package: "foo", Channel: "fast", Bundle: "foo.v0.3.0"},
+		{Package: "foo", Channel: "stable", Bundle: "foo.v0.3.0"},
+	}, heads)
+}
+
+func TestIncludeConfigFromHeads(t *testing.T) {
+	type spec struct {
+		name string
+		prev []v1alpha2.ChannelHead
+		curr v1alpha2.IncludeConfig
+		exp  v1alpha2.IncludeConfig
+	}
+
+	cases := []spec{
+		{
+			name: "Valid/MovedHead",
+			prev: []v1alpha2.ChannelHead{
+				{Package: "foo", Channel: "stable", Bundle: "foo.v0.1.0"},
+			},
+			exp: v1alpha2.IncludeConfig{
+				Packages: []v1alpha2.IncludePackage{
+					{Name: "bar", Channels: []v1alpha2.IncludeChannel{
+						{Name: "stable", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.2.0"}},
+					}},
+					{Name: "foo", Channels: []v1alpha2.IncludeChannel{
+						{Name: "fast", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.3.0"}},
+						{Name: "stable", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.1.0"}},
+					}},
+				},
+			},
+		},
+		{
+			name: "Valid/RemovedHead",
+			prev: []v1alpha2.ChannelHead{
+				{Package: "foo", Channel: "stable", Bundle: "foo.v0.0.9"},
+				{Package: "bar", Channel: "stable", Bundle: "bar.v0.1.0"},
+			},
+			exp: v1alpha2.IncludeConfig{
+				Packages: []v1alpha2.IncludePackage{
+					{Name: "bar", Channels: []v1alpha2.IncludeChannel{
+						{Name: "stable", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.1.0"}},
+					}},
+					{Name: "foo", Channels: []v1alpha2.IncludeChannel{
+						{Name: "fast", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.3.0"}},
+						{Name: "stable", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.3.0"}},
+					}},
+				},
+			},
+		},
+		{
+			name: "Valid/ConfiguredVersionsKept",
+			prev: []v1alpha2.ChannelHead{
+				{Package: "foo", Channel: "stable", Bundle: "foo.v0.1.0"},
+				{Package: "bar", Channel: "stable", Bundle: "bar.v0.1.0"},
+			},
+			curr: v1alpha2.IncludeConfig{
+				Packages: []v1alpha2.IncludePackage{
+					{Name: "bar", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.2.0"}},
+					{Name: "foo", Channels: []v1alpha2.IncludeChannel{
+						{Name: "fast", IncludeBundle: v1alpha2.IncludeBundle{MaxVersion: "0.3.0"}},
+					}},
+				},
+			},
+			exp: v1alpha2.IncludeConfig{
+				Packages: []v1alpha2.IncludePackage{
+					{Name: "bar", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.2.0"}},
+					{Name: "foo", Channels: []v1alpha2.IncludeChannel{
+						{Name: "fast", IncludeBundle: v1alpha2.IncludeBundle{MaxVersion: "0.3.0"}},
+						{Name: "stable", IncludeBundle: v1alpha2.IncludeBundle{MinVersion: "0.1.0"}},
+					}},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ic, err := IncludeConfigFromHeads(headsCatalog, c.prev, c.curr)
+			require.NoError(t, err)
+			require.Equal(t, c.exp, ic)
+		})
+	}
+}
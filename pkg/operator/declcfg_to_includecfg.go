@@ -401,6 +401,8 @@ func includePackageVersionsSet(pkg v1alpha2.IncludePackage) bool {
 		return true
 	case pkg.MaxVersion != "":
 		return true
+	case len(pkg.SelectedBundles) != 0:
+		return true
 	}
 	return false
 }
@@ -0,0 +1,239 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/model"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// Dependency is a package version range or a GVK required by a bundle
+// of a declarative config that no bundle of the declarative config provides.
+type Dependency struct {
+	// Package and Bundle are the package and the name of the requiring bundle.
+	Package string
+	Bundle  string
+	// Requirement describes the required package version range or GVK.
+	Requirement string
+	// Provider is the latest bundle of the catalog providing
+	// the dependency, empty if the catalog does not provide it.
+	Provider string
+
+	provider *model.Bundle
+}
+
+func (d Dependency) String() string {
+	s := fmt.Sprintf("bundle %s of package %s requires %s", d.Bundle, d.Package, d.Requirement)
+	if d.Provider != "" {
+		s += fmt.Sprintf(", provided by bundle %s of package %s", d.Provider, d.provider.Package.Name)
+	}
+	return s
+}
+
+// MissingDependencies returns the olm.package.required and olm.gvk.required
+// dependencies of the bundles of dc that no bundle of dc satisfies, with the
+// latest bundle of catalog satisfying each of them.
+func MissingDependencies(dc, catalog declcfg.DeclarativeConfig) ([]Dependency, error) {
+	catalogModel, err := declcfg.ConvertToModel(catalog)
+	if err != nil {
+		return nil, fmt.Errorf("error converting catalog to model: %v", err)
+	}
+	return missingDependencies(dc, catalogModel)
+}
+
+// IncludeDependencies adds to dc the latest bundles of catalog providing the
+// missing dependencies of the bundles of dc, then the ones providing the missing
+// dependencies of the bundles added, and so on. Each bundle is added as the only
+// entry of its channel, so the upgrade graphs of the channels of dc are unchanged:
+// dependencies provided by a channel of dc are not included and stay missing.
+// It returns the dependencies included and the dependencies still missing.
+func IncludeDependencies(dc *declcfg.DeclarativeConfig, catalog declcfg.DeclarativeConfig) (included, missing []Dependency, err error) {
+	catalogModel, err := declcfg.ConvertToModel(catalog)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error converting catalog to model: %v", err)
+	}
+	for {
+		missing, err = missingDependencies(*dc, catalogModel)
+		if err != nil {
+			return included, missing, err
+		}
+		var added bool
+		var stillMissing []Dependency
+		for _, dep := range missing {
+			if dep.provider != nil && addBundle(dc, catalog, dep.provider) {
+				included = append(included, dep)
+				added = true
+				continue
+			}
+			stillMissing = append(stillMissing, dep)
+		}
+		if !added {
+			return included, stillMissing, nil
+		}
+	}
+}
+
+func missingDependencies(dc declcfg.DeclarativeConfig, catalog model.Model) ([]Dependency, error) {
+	dcModel, err := declcfg.ConvertToModel(dc)
+	if err != nil {
+		return nil, fmt.Errorf("error converting declarative config to model: %v", err)
+	}
+	bundles := sortedBundles(dcModel)
+	providedGVKs := map[property.GVK]bool{}
+	versions := map[string][]semver.Version{}
+	for _, b := range bundles {
+		for _, gvk := range b.PropertiesP.GVKs {
+			providedGVKs[gvk] = true
+		}
+		versions[b.Package.Name] = append(versions[b.Package.Name], b.Version)
+	}
+
+	var missing []Dependency
+	seen := map[string]bool{}
+	add := func(b *model.Bundle, requirement string, provides func(*model.Bundle) bool) {
+		// Bundles are listed once per channel.
+		key := b.Name + "/" + requirement
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		dep := Dependency{Package: b.Package.Name, Bundle: b.Name, Requirement: requirement}
+		if dep.provider = latestProvider(catalog, provides); dep.provider != nil {
+			dep.Provider = dep.provider.Name
+		}
+		missing = append(missing, dep)
+	}
+	for _, b := range bundles {
+		for _, req := range b.PropertiesP.GVKsRequired {
+			gvk := property.GVK{Group: req.Group, Version: req.Version, Kind: req.Kind}
+			if providedGVKs[gvk] {
+				continue
+			}
+			add(b, fmt.Sprintf("GVK %s/%s %s", gvk.Group, gvk.Version, gvk.Kind), func(p *model.Bundle) bool {
+				for _, provided := range p.PropertiesP.GVKs {
+					if provided == gvk {
+						return true
+					}
+				}
+				return false
+			})
+		}
+		for _, req := range b.PropertiesP.PackagesRequired {
+			inRange := func(semver.Version) bool { return true }
+			requirement := fmt.Sprintf("package %s", req.PackageName)
+			if req.VersionRange != "" {
+				if inRange, err = semver.ParseRange(req.VersionRange); err != nil {
+					return nil, fmt.Errorf("bundle %s: invalid version range %q of package %s: %v", b.Name, req.VersionRange, req.PackageName, err)
+				}
+				requirement += " " + req.VersionRange
+			}
+			satisfied := false
+			for _, v := range versions[req.PackageName] {
+				if inRange(v) {
+					satisfied = true
+					break
+				}
+			}
+			if satisfied {
+				continue
+			}
+			pkgName := req.PackageName
+			add(b, requirement, func(p *model.Bundle) bool {
+				return p.Package.Name == pkgName && inRange(p.Version)
+			})
+		}
+	}
+	return missing, nil
+}
+
+// latestProvider returns the bundle of m with the highest version for
+// which provides is true, in the default channel of its package when
+// the bundle is in several channels. It returns nil if there is none.
+func latestProvider(m model.Model, provides func(*model.Bundle) bool) *model.Bundle {
+	var latest *model.Bundle
+	for _, b := range sortedBundles(m) {
+		if !provides(b) {
+			continue
+		}
+		switch {
+		case latest == nil, b.Version.GT(latest.Version):
+			latest = b
+		case b.Version.EQ(latest.Version) && b.Channel == b.Package.DefaultChannel && latest.Channel != latest.Package.DefaultChannel:
+			latest = b
+		}
+	}
+	return latest
+}
+
+// sortedBundles returns the bundles of m, once per channel,
+// sorted by package, channel and name.
+func sortedBundles(m model.Model) []*model.Bundle {
+	var bundles []*model.Bundle
+	for _, pkg := range m {
+		for _, ch := range pkg.Channels {
+			for _, b := range ch.Bundles {
+				bundles = append(bundles, b)
+			}
+		}
+	}
+	sort.Slice(bundles, func(i, j int) bool {
+		if bundles[i].Package.Name != bundles[j].Package.Name {
+			return bundles[i].Package.Name < bundles[j].Package.Name
+		}
+		if bundles[i].Channel.Name != bundles[j].Channel.Name {
+			return bundles[i].Channel.Name < bundles[j].Channel.Name
+		}
+		return bundles[i].Name < bundles[j].Name
+	})
+	return bundles
+}
+
+// addBundle adds the bundle b of catalog to dc as the only entry of its
+// channel, and its package if dc does not have it, with that channel as
+// default channel. It returns false if dc already has the channel of b.
+func addBundle(dc *declcfg.DeclarativeConfig, catalog declcfg.DeclarativeConfig, b *model.Bundle) bool {
+	for _, ch := range dc.Channels {
+		if ch.Package == b.Package.Name && ch.Name == b.Channel.Name {
+			return false
+		}
+	}
+
+	hasPackage := false
+	for _, pkg := range dc.Packages {
+		if pkg.Name == b.Package.Name {
+			hasPackage = true
+			break
+		}
+	}
+	if !hasPackage {
+		for _, pkg := range catalog.Packages {
+			if pkg.Name == b.Package.Name {
+				pkg.DefaultChannel = b.Channel.Name
+				dc.Packages = append(dc.Packages, pkg)
+				break
+			}
+		}
+	}
+	dc.Channels = append(dc.Channels, declcfg.Channel{
+		Schema:  declcfg.SchemaChannel,
+		Package: b.Package.Name,
+		Name:    b.Channel.Name,
+		Entries: []declcfg.ChannelEntry{{Name: b.Name}},
+	})
+
+	for _, bundle := range dc.Bundles {
+		if bundle.Package == b.Package.Name && bundle.Name == b.Name {
+			return true
+		}
+	}
+	for _, bundle := range catalog.Bundles {
+		if bundle.Package == b.Package.Name && bundle.Name == b.Name {
+			dc.Bundles = append(dc.Bundles, bundle)
+			break
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/require"
+)
+
+// depsCatalog holds an app package requiring the etcd package and the
+// EtcdCluster GVK, provided by the etcd and etcd-operator packages.
+var depsCatalog = declcfg.DeclarativeConfig{
+	Packages: []declcfg.Package{
+		{Schema: "olm.package", Name: "app", DefaultChannel: "stable"},
+		{Schema: "olm.package", Name: "etcd", DefaultChannel: "stable"},
+		{Schema: "olm.package", Name: "etcd-operator", DefaultChannel: "alpha"},
+	},
+	Channels: []declcfg.Channel{
+		{Schema: "olm.channel", Name: "stable", Package: "app", Entries: []declcfg.ChannelEntry{
+			{Name: "app.v1.0.0"},
+		}},
+		{Schema: "olm.channel", Name: "stable", Package: "etcd", Entries: []declcfg.ChannelEntry{
+			{Name: "etcd.v0.9.0"},
+			{Name: "etcd.v0.9.4", Replaces: "etcd.v0.9.0"},
+			{Name: "etcd.v1.0.0", Replaces: "etcd.v0.9.4"},
+		}},
+		{Schema: "olm.channel", Name: "alpha", Package: "etcd-operator", Entries: []declcfg.ChannelEntry{
+			{Name: "etcd-operator.v0.1.0"},
+			{Name: "etcd-operator.v0.2.0", Replaces: "etcd-operator.v0.1.0"},
+		}},
+	},
+	Bundles: []declcfg.Bundle{
+		depsBundle("app", "1.0.0",
+			property.MustBuildPackageRequired("etcd", "<1.0.0"),
+			property.MustBuildGVKRequired("etcd.database.coreos.com", "v1beta2", "EtcdCluster"),
+		),
+		depsBundle("etcd", "0.9.0"),
+		depsBundle("etcd", "0.9.4"),
+		depsBundle("etcd", "1.0.0"),
+		depsBundle("etcd-operator", "0.1.0", property.MustBuildGVK("etcd.database.coreos.com", "v1beta2", "EtcdCluster")),
+		depsBundle("etcd-operator", "0.2.0",
+			property.MustBuildGVK("etcd.database.coreos.com", "v1beta2", "EtcdCluster"),
+			property.MustBuildGVKRequired("etcd.database.coreos.com", "v1beta2", "EtcdBackup"),
+		),
+	},
+}
+
+func depsBundle(pkg, version string, props ...property.Property) declcfg.Bundle {
+	b := headsBundle(pkg, version)
+	b.Properties = append(b.Properties, props...)
+	return b
+}
+
+// depsFiltered holds the app package only, as filtered by an imageset configuration.
+func depsFiltered() declcfg.DeclarativeConfig {
+	return declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{depsCatalog.Packages[0]},
+		Channels: []declcfg.Channel{depsCatalog.Channels[0]},
+		Bundles:  []declcfg.Bundle{depsCatalog.Bundles[0]},
+	}
+}
+
+func TestMissingDependencies(t *testing.T) {
+	missing, err := MissingDependencies(depsFiltered(), depsCatalog)
+	require.NoError(t, err)
+	require.Len(t, missing, 2)
+	require.Equal(t, "bundle app.v1.0.0 of package app requires GVK etcd.database.coreos.com/v1beta2 EtcdCluster, "+
+		"provided by bundle etcd-operator.v0.2.0 of package etcd-operator", missing[0].String())
+	require.Equal(t, "bundle app.v1.0.0 of package app requires package etcd <1.0.0, "+
+		"provided by bundle etcd.v0.9.4 of package etcd", missing[1].String())
+
+	// The full catalog misses the GVK required by etcd-operator.v0.2.0 only.
+	missing, err = MissingDependencies(depsCatalog, declcfg.DeclarativeConfig{})
+	require.NoError(t, err)
+	require.Len(t, missing, 1)
+	require.Equal(t, "bundle etcd-operator.v0.2.0 of package etcd-operator requires GVK etcd.database.coreos.com/v1beta2 EtcdBackup", missing[0].String())
+}
+
+func TestIncludeDependencies(t *testing.T) {
+	t.Run("Valid/NewPackages", func(t *testing.T) {
+		dc := depsFiltered()
+		included, missing, err := IncludeDependencies(&dc, depsCatalog)
+		require.NoError(t, err)
+		require.Len(t, included, 2)
+		require.Equal(t, "etcd-operator.v0.2.0", included[0].Provider)
+		require.Equal(t, "etcd.v0.9.4", included[1].Provider)
+		// The dependencies of the bundles included are resolved too.
+		require.Len(t, missing, 1)
+		require.Equal(t, "etcd-operator.v0.2.0", missing[0].Bundle)
+		require.Empty(t, missing[0].Provider)
+
+		heads, err := ChannelHeads(dc)
+		require.NoError(t, err)
+		require.Len(t, heads, 3)
+		require.Equal(t, "etcd.v0.9.4", heads[1].Bundle)
+		require.Equal(t, "etcd-operator.v0.2.0", heads[2].Bundle)
+	})
+
+	t.Run("Valid/ChannelAlreadyMirrored", func(t *testing.T) {
+		// The head of etcd stable is mirrored, but app requires an older version.
+		dc := depsFiltered()
+		dc.Packages = append(dc.Packages, depsCatalog.Packages[1])
+		dc.Channels = append(dc.Channels, declcfg.Channel{Schema: "olm.channel", Name: "stable", Package: "etcd", Entries: []declcfg.ChannelEntry{{Name: "etcd.v1.0.0"}}})
+		dc.Bundles = append(dc.Bundles, depsCatalog.Bundles[3])
+		included, missing, err := IncludeDependencies(&dc, depsCatalog)
+		require.NoError(t, err)
+		require.Len(t, included, 1)
+		require.Equal(t, "etcd-operator.v0.2.0", included[0].Provider)
+		require.Len(t, missing, 2)
+		require.Equal(t, "package etcd <1.0.0", missing[0].Requirement)
+		require.Equal(t, "etcd.v0.9.4", missing[0].Provider)
+	})
+}
@@ -23,6 +23,9 @@ type Diff struct {
 
 	OldRefs []string
 	NewRefs []string
+	// NewConfig is the declarative config of NewRefs, when already
+	// rendered by the caller. Run renders NewRefs when nil.
+	NewConfig *declcfg.DeclarativeConfig
 	// SkipDependencies directs Run() to not include dependencies
 	// of bundles included in the diff if true.
 	SkipDependencies bool
@@ -61,13 +64,17 @@ func (diffIn Diff) Run(ctx context.Context) (*declcfg.DeclarativeConfig, error)
 		}
 	}
 
-	newRender := action.Render{Refs: diffIn.NewRefs, Registry: diffIn.Registry, AllowedRefMask: mask}
-	newCfg, err := newRender.Run(ctx)
-	if err != nil {
-		if errors.Is(err, action.ErrNotAllowed) {
-			return nil, fmt.Errorf("%w (diff does not permit direct bundle references)", err)
+	newCfg := diffIn.NewConfig
+	if newCfg == nil {
+		newRender := action.Render{Refs: diffIn.NewRefs, Registry: diffIn.Registry, AllowedRefMask: mask}
+		var err error
+		newCfg, err = newRender.Run(ctx)
+		if err != nil {
+			if errors.Is(err, action.ErrNotAllowed) {
+				return nil, fmt.Errorf("%w (diff does not permit direct bundle references)", err)
+			}
+			return nil, fmt.Errorf("error rendering new refs: %v", err)
 		}
-		return nil, fmt.Errorf("error rendering new refs: %v", err)
 	}
 	newModel, err := declcfg.ConvertToModel(*newCfg)
 	if err != nil {
@@ -0,0 +1,89 @@
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/oc-mirror/pkg/config"
+)
+
+// Labels recording the provenance of the catalog images rebuilt by oc-mirror.
+const (
+	// SourceCatalogLabel is the catalog the rebuilt catalog is filtered from.
+	SourceCatalogLabel = "io.openshift.oc-mirror.catalog.source"
+	// SourceDigestLabel is the digest of the source catalog.
+	SourceDigestLabel = "io.openshift.oc-mirror.catalog.source-digest"
+	// FilterDigestLabel is the digest of the imageset configuration
+	// filtering the source catalog.
+	FilterDigestLabel = "io.openshift.oc-mirror.catalog.filter-digest"
+	// VersionLabel is the version of oc-mirror that built the catalog.
+	VersionLabel = "io.openshift.oc-mirror.version"
+	// BuildTimestampLabel is the time the catalog was built at, in RFC 3339 format.
+	BuildTimestampLabel = "io.openshift.oc-mirror.build-timestamp"
+)
+
+// Provenance records where a rebuilt catalog image comes from.
+type Provenance struct {
+	SourceCatalog  string `json:"sourceCatalog"`
+	SourceDigest   string `json:"sourceDigest"`
+	FilterDigest   string `json:"filterDigest"`
+	Version        string `json:"version,omitempty"`
+	BuildTimestamp string `json:"buildTimestamp,omitempty"`
+}
+
+// Labels returns the image labels recording p.
+func (p Provenance) Labels() map[string]string {
+	labels := map[string]string{
+		SourceCatalogLabel:  p.SourceCatalog,
+		SourceDigestLabel:   p.SourceDigest,
+		FilterDigestLabel:   p.FilterDigest,
+		VersionLabel:        p.Version,
+		BuildTimestampLabel: p.BuildTimestamp,
+	}
+	for key, value := range labels {
+		if value == "" {
+			delete(labels, key)
+		}
+	}
+	return labels
+}
+
+// ProvenanceFromLabels returns the Provenance recorded in the labels
+// of a catalog image, and false if the image was not rebuilt by oc-mirror.
+func ProvenanceFromLabels(labels map[string]string) (Provenance, bool) {
+	p := Provenance{
+		SourceCatalog:  labels[SourceCatalogLabel],
+		SourceDigest:   labels[SourceDigestLabel],
+		FilterDigest:   labels[FilterDigestLabel],
+		Version:        labels[VersionLabel],
+		BuildTimestamp: labels[BuildTimestampLabel],
+	}
+	return p, p != Provenance{}
+}
+
+// WriteProvenance writes p to the catalog directory catalogDir.
+func WriteProvenance(catalogDir string, p Provenance) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(catalogDir, 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(catalogDir, config.CatalogProvenanceFile), data, 0600)
+}
+
+// ReadProvenance reads the Provenance of the catalog directory catalogDir.
+func ReadProvenance(catalogDir string) (Provenance, error) {
+	var p Provenance
+	data, err := os.ReadFile(filepath.Join(catalogDir, config.CatalogProvenanceFile))
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("error reading catalog provenance: %v", err)
+	}
+	return p, nil
+}
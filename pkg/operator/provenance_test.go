@@ -0,0 +1,44 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenance(t *testing.T) {
+	p := Provenance{
+		SourceCatalog:  "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+		SourceDigest:   "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+		FilterDigest:   "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+		Version:        "v4.17.0",
+		BuildTimestamp: "2024-05-01T10:00:00Z",
+	}
+
+	t.Run("Valid/Labels", func(t *testing.T) {
+		labels := p.Labels()
+		require.Len(t, labels, 5)
+		actual, found := ProvenanceFromLabels(labels)
+		require.True(t, found)
+		require.Equal(t, p, actual)
+	})
+	t.Run("Valid/PartialLabels", func(t *testing.T) {
+		labels := Provenance{Version: "v4.17.0"}.Labels()
+		require.Equal(t, map[string]string{VersionLabel: "v4.17.0"}, labels)
+	})
+	t.Run("Invalid/NoLabels", func(t *testing.T) {
+		_, found := ProvenanceFromLabels(map[string]string{"vendor": "Red Hat"})
+		require.False(t, found)
+	})
+	t.Run("Valid/File", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, WriteProvenance(dir, p))
+		actual, err := ReadProvenance(dir)
+		require.NoError(t, err)
+		require.Equal(t, p, actual)
+	})
+	t.Run("Invalid/NoFile", func(t *testing.T) {
+		_, err := ReadProvenance(t.TempDir())
+		require.Error(t, err)
+	})
+}
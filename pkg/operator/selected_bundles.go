@@ -0,0 +1,317 @@
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/model"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+// selectedBundles returns the names of the selected bundles
+// of the packages of ic, keyed by package.
+func selectedBundles(ic v1alpha2.IncludeConfig) map[string]map[string]bool {
+	selected := map[string]map[string]bool{}
+	for _, pkg := range ic.Packages {
+		if len(pkg.SelectedBundles) == 0 {
+			continue
+		}
+		selected[pkg.Name] = map[string]bool{}
+		for _, b := range pkg.SelectedBundles {
+			selected[pkg.Name][b.Name] = true
+		}
+	}
+	return selected
+}
+
+// SelectBundles removes from dc the bundles of the packages of ic selecting
+// bundles by name that are not selected. The entries of the channels of these
+// packages are chained again in version order, so each channel keeps a single
+// head, and the channels left without bundles are removed. It returns an
+// error naming the selected bundles dc does not have.
+func SelectBundles(dc *declcfg.DeclarativeConfig, ic v1alpha2.IncludeConfig) error {
+	selected := selectedBundles(ic)
+	if len(selected) == 0 {
+		return nil
+	}
+
+	found := map[string]bool{}
+	var bundles []declcfg.Bundle
+	for _, b := range dc.Bundles {
+		names, ok := selected[b.Package]
+		if ok && !names[b.Name] {
+			continue
+		}
+		if ok {
+			found[b.Package+"/"+b.Name] = true
+		}
+		bundles = append(bundles, b)
+	}
+	dc.Bundles = bundles
+
+	var missing []string
+	for pkgName, names := range selected {
+		for name := range names {
+			if !found[pkgName+"/"+name] {
+				missing = append(missing, fmt.Sprintf("bundle %s of package %s", name, pkgName))
+			}
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("selected bundles not found in the catalog: %s", strings.Join(missing, ", "))
+	}
+
+	versions, err := bundleVersions(*dc, func(pkgName string) bool {
+		_, ok := selected[pkgName]
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+	var channels []declcfg.Channel
+	remaining := map[string][]string{}
+	for _, ch := range dc.Channels {
+		names, ok := selected[ch.Package]
+		if ok {
+			var entries []declcfg.ChannelEntry
+			for _, e := range ch.Entries {
+				if names[e.Name] {
+					entries = append(entries, e)
+				}
+			}
+			if len(entries) == 0 {
+				continue
+			}
+			ch.Entries = entries
+			chainEntries(&ch, versions)
+		}
+		remaining[ch.Package] = append(remaining[ch.Package], ch.Name)
+		channels = append(channels, ch)
+	}
+	dc.Channels = channels
+
+	// The default channel is replaced when none of its bundles is selected.
+	for i, pkg := range dc.Packages {
+		if _, ok := selected[pkg.Name]; !ok {
+			continue
+		}
+		chNames := remaining[pkg.Name]
+		sort.Strings(chNames)
+		if len(chNames) != 0 && !containsString(chNames, pkg.DefaultChannel) {
+			dc.Packages[i].DefaultChannel = chNames[0]
+		}
+	}
+	return nil
+}
+
+// PinnedBundles returns the selected bundles of the packages of ic in dc, and
+// the bundles of dc satisfying their olm.package.required and olm.gvk.required
+// dependencies, then the ones satisfying the dependencies of these bundles,
+// and so on. Each dependency is satisfied by the latest bundle of dc providing
+// it, as IncludeDependencies includes them. The bundles are recorded in the
+// default channel of their package when they are in several channels.
+func PinnedBundles(dc declcfg.DeclarativeConfig, ic v1alpha2.IncludeConfig) ([]v1alpha2.PinnedBundle, error) {
+	selected := selectedBundles(ic)
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	m, err := declcfg.ConvertToModel(dc)
+	if err != nil {
+		return nil, fmt.Errorf("error converting declarative config to model: %v", err)
+	}
+
+	pinned := map[string]v1alpha2.PinnedBundle{}
+	var queue []*model.Bundle
+	pin := func(b *model.Bundle, isSelected bool) {
+		key := b.Package.Name + "/" + b.Name
+		if current, ok := pinned[key]; ok {
+			// A bundle is listed once per channel.
+			if b.Channel == b.Package.DefaultChannel {
+				current.Channel = b.Channel.Name
+				pinned[key] = current
+			}
+			return
+		}
+		pinned[key] = v1alpha2.PinnedBundle{
+			Package:  b.Package.Name,
+			Channel:  b.Channel.Name,
+			Name:     b.Name,
+			Image:    b.Image,
+			Selected: isSelected,
+		}
+		queue = append(queue, b)
+	}
+	for _, b := range sortedBundles(m) {
+		if selected[b.Package.Name][b.Name] {
+			pin(b, true)
+		}
+	}
+
+	for len(queue) != 0 {
+		b := queue[0]
+		queue = queue[1:]
+		for _, req := range b.PropertiesP.GVKsRequired {
+			gvk := property.GVK{Group: req.Group, Version: req.Version, Kind: req.Kind}
+			provider := latestProvider(m, func(p *model.Bundle) bool {
+				for _, provided := range p.PropertiesP.GVKs {
+					if provided == gvk {
+						return true
+					}
+				}
+				return false
+			})
+			if provider != nil {
+				pin(provider, false)
+			}
+		}
+		for _, req := range b.PropertiesP.PackagesRequired {
+			inRange := func(semver.Version) bool { return true }
+			if req.VersionRange != "" {
+				if inRange, err = semver.ParseRange(req.VersionRange); err != nil {
+					return nil, fmt.Errorf("bundle %s: invalid version range %q of package %s: %v", b.Name, req.VersionRange, req.PackageName, err)
+				}
+			}
+			pkgName := req.PackageName
+			provider := latestProvider(m, func(p *model.Bundle) bool {
+				return p.Package.Name == pkgName && inRange(p.Version)
+			})
+			if provider != nil {
+				pin(provider, false)
+			}
+		}
+	}
+
+	pins := make([]v1alpha2.PinnedBundle, 0, len(pinned))
+	for _, p := range pinned {
+		pins = append(pins, p)
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		if pins[i].Package != pins[j].Package {
+			return pins[i].Package < pins[j].Package
+		}
+		return pins[i].Name < pins[j].Name
+	})
+	return pins, nil
+}
+
+// SameSelection returns whether pins were recorded for the selected bundles of ic.
+func SameSelection(pins []v1alpha2.PinnedBundle, ic v1alpha2.IncludeConfig) bool {
+	selected := selectedBundles(ic)
+	count := 0
+	for _, p := range pins {
+		if !p.Selected {
+			continue
+		}
+		if !selected[p.Package][p.Name] {
+			return false
+		}
+		count++
+	}
+	total := 0
+	for _, names := range selected {
+		total += len(names)
+	}
+	return count == total
+}
+
+// AddPinnedBundle adds to dc the bundle b of the pinned bundle pin, in the
+// channel it was pinned in, and the package and channel if dc does not have
+// them. The entries of the channel are chained again in version order. It
+// returns false if dc already has the bundle.
+func AddPinnedBundle(dc *declcfg.DeclarativeConfig, pin v1alpha2.PinnedBundle, b declcfg.Bundle) (bool, error) {
+	for _, bundle := range dc.Bundles {
+		if bundle.Package == pin.Package && bundle.Name == pin.Name {
+			return false, nil
+		}
+	}
+	dc.Bundles = append(dc.Bundles, b)
+
+	hasPackage := false
+	for _, pkg := range dc.Packages {
+		if pkg.Name == pin.Package {
+			hasPackage = true
+			break
+		}
+	}
+	if !hasPackage {
+		dc.Packages = append(dc.Packages, declcfg.Package{
+			Schema:         declcfg.SchemaPackage,
+			Name:           pin.Package,
+			DefaultChannel: pin.Channel,
+		})
+	}
+
+	versions, err := bundleVersions(*dc, func(pkgName string) bool { return pkgName == pin.Package })
+	if err != nil {
+		return false, err
+	}
+	for i, ch := range dc.Channels {
+		if ch.Package == pin.Package && ch.Name == pin.Channel {
+			entries := append([]declcfg.ChannelEntry{}, ch.Entries...)
+			dc.Channels[i].Entries = append(entries, declcfg.ChannelEntry{Name: pin.Name})
+			chainEntries(&dc.Channels[i], versions)
+			return true, nil
+		}
+	}
+	dc.Channels = append(dc.Channels, declcfg.Channel{
+		Schema:  declcfg.SchemaChannel,
+		Package: pin.Package,
+		Name:    pin.Channel,
+		Entries: []declcfg.ChannelEntry{{Name: pin.Name}},
+	})
+	return true, nil
+}
+
+// bundleVersions returns the versions of the bundles of dc of the
+// packages for which inPackages is true, keyed by package and bundle name.
+func bundleVersions(dc declcfg.DeclarativeConfig, inPackages func(string) bool) (map[string]semver.Version, error) {
+	versions := map[string]semver.Version{}
+	for _, b := range dc.Bundles {
+		if !inPackages(b.Package) {
+			continue
+		}
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing properties of bundle %s: %v", b.Name, err)
+		}
+		if len(props.Packages) != 1 {
+			return nil, fmt.Errorf("bundle %s must have exactly one package property", b.Name)
+		}
+		version, err := semver.Parse(props.Packages[0].Version)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing version of bundle %s: %v", b.Name, err)
+		}
+		versions[b.Package+"/"+b.Name] = version
+	}
+	return versions, nil
+}
+
+// chainEntries orders the entries of ch by the versions of their bundles
+// and makes each entry replace the previous one, so the channel has a
+// single head. The skips and skip ranges of the entries are kept.
+func chainEntries(ch *declcfg.Channel, versions map[string]semver.Version) {
+	sort.SliceStable(ch.Entries, func(i, j int) bool {
+		return versions[ch.Package+"/"+ch.Entries[i].Name].LT(versions[ch.Package+"/"+ch.Entries[j].Name])
+	})
+	for i := range ch.Entries {
+		ch.Entries[i].Replaces = ""
+		if i > 0 {
+			ch.Entries[i].Replaces = ch.Entries[i-1].Name
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
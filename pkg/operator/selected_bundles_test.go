@@ -0,0 +1,131 @@
+package operator
+
+import (
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/oc-mirror/pkg/api/v1alpha2"
+)
+
+func selectIncludeConfig(pkg string, names ...string) v1alpha2.IncludeConfig {
+	icPkg := v1alpha2.IncludePackage{Name: pkg}
+	for _, name := range names {
+		icPkg.SelectedBundles = append(icPkg.SelectedBundles, v1alpha2.SelectedBundle{Name: name})
+	}
+	return v1alpha2.IncludeConfig{Packages: []v1alpha2.IncludePackage{icPkg}}
+}
+
+func TestSelectBundles(t *testing.T) {
+	type spec struct {
+		name        string
+		ic          v1alpha2.IncludeConfig
+		expChannels []declcfg.Channel
+		expBundles  []string
+		expError    string
+	}
+	cases := []spec{
+		{
+			name: "Valid/ChainedAgain",
+			ic:   selectIncludeConfig("etcd", "etcd.v0.9.0", "etcd.v1.0.0"),
+			expChannels: []declcfg.Channel{
+				depsCatalog.Channels[0],
+				{Schema: "olm.channel", Name: "stable", Package: "etcd", Entries: []declcfg.ChannelEntry{
+					{Name: "etcd.v0.9.0"},
+					{Name: "etcd.v1.0.0", Replaces: "etcd.v0.9.0"},
+				}},
+				depsCatalog.Channels[2],
+			},
+			expBundles: []string{"app.v1.0.0", "etcd.v0.9.0", "etcd.v1.0.0", "etcd-operator.v0.1.0", "etcd-operator.v0.2.0"},
+		},
+		{
+			name:     "Invalid/NotInCatalog",
+			ic:       selectIncludeConfig("etcd", "etcd.v0.9.0", "etcd.v0.8.0"),
+			expError: "selected bundles not found in the catalog: bundle etcd.v0.8.0 of package etcd",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dc := depsCatalog
+			err := SelectBundles(&dc, c.ic)
+			if c.expError != "" {
+				require.EqualError(t, err, c.expError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expChannels, dc.Channels)
+			var names []string
+			for _, b := range dc.Bundles {
+				names = append(names, b.Name)
+			}
+			require.Equal(t, c.expBundles, names)
+			_, err = declcfg.ConvertToModel(dc)
+			require.NoError(t, err)
+		})
+	}
+
+	// The catalog is not modified.
+	require.Len(t, depsCatalog.Bundles, 6)
+	require.Len(t, depsCatalog.Channels[1].Entries, 3)
+}
+
+func TestSelectBundlesDefaultChannel(t *testing.T) {
+	dc := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{{Schema: "olm.package", Name: "foo", DefaultChannel: "stable"}},
+		Channels: []declcfg.Channel{
+			{Schema: "olm.channel", Name: "stable", Package: "foo", Entries: []declcfg.ChannelEntry{{Name: "foo.v2.0.0"}}},
+			{Schema: "olm.channel", Name: "legacy", Package: "foo", Entries: []declcfg.ChannelEntry{{Name: "foo.v1.0.0"}}},
+		},
+		Bundles: []declcfg.Bundle{headsBundle("foo", "1.0.0"), headsBundle("foo", "2.0.0")},
+	}
+	require.NoError(t, SelectBundles(&dc, selectIncludeConfig("foo", "foo.v1.0.0")))
+	require.Equal(t, "legacy", dc.Packages[0].DefaultChannel)
+	require.Len(t, dc.Channels, 1)
+}
+
+func TestPinnedBundles(t *testing.T) {
+	pins, err := PinnedBundles(depsCatalog, selectIncludeConfig("app", "app.v1.0.0"))
+	require.NoError(t, err)
+	// Each dependency is pinned to its latest provider, the EtcdBackup GVK no bundle provides is left out.
+	require.Equal(t, []v1alpha2.PinnedBundle{
+		{Package: "app", Channel: "stable", Name: "app.v1.0.0", Image: "reg/app:v1.0.0", Selected: true},
+		{Package: "etcd", Channel: "stable", Name: "etcd.v0.9.4", Image: "reg/etcd:v0.9.4"},
+		{Package: "etcd-operator", Channel: "alpha", Name: "etcd-operator.v0.2.0", Image: "reg/etcd-operator:v0.2.0"},
+	}, pins)
+
+	require.True(t, SameSelection(pins, selectIncludeConfig("app", "app.v1.0.0")))
+	require.False(t, SameSelection(pins, selectIncludeConfig("etcd", "etcd.v0.9.4")))
+	require.False(t, SameSelection(pins, selectIncludeConfig("app")))
+
+	pins, err = PinnedBundles(depsCatalog, v1alpha2.IncludeConfig{})
+	require.NoError(t, err)
+	require.Empty(t, pins)
+}
+
+func TestAddPinnedBundle(t *testing.T) {
+	dc := depsFiltered()
+	dc.Packages = append(dc.Packages, depsCatalog.Packages[1])
+	dc.Channels = append(dc.Channels, declcfg.Channel{Schema: "olm.channel", Name: "stable", Package: "etcd", Entries: []declcfg.ChannelEntry{{Name: "etcd.v1.0.0"}}})
+	dc.Bundles = append(dc.Bundles, depsCatalog.Bundles[3])
+
+	// A bundle dropped from the channel is chained again before the head.
+	pin := v1alpha2.PinnedBundle{Package: "etcd", Channel: "stable", Name: "etcd.v0.9.4", Image: "reg/etcd:v0.9.4"}
+	added, err := AddPinnedBundle(&dc, pin, depsCatalog.Bundles[2])
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Equal(t, []declcfg.ChannelEntry{{Name: "etcd.v0.9.4"}, {Name: "etcd.v1.0.0", Replaces: "etcd.v0.9.4"}}, dc.Channels[1].Entries)
+
+	added, err = AddPinnedBundle(&dc, pin, depsCatalog.Bundles[2])
+	require.NoError(t, err)
+	require.False(t, added)
+
+	// A bundle of a package dc does not have adds the package and channel.
+	pin = v1alpha2.PinnedBundle{Package: "etcd-operator", Channel: "alpha", Name: "etcd-operator.v0.1.0", Image: "reg/etcd-operator:v0.1.0"}
+	added, err = AddPinnedBundle(&dc, pin, depsCatalog.Bundles[4])
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Equal(t, declcfg.Package{Schema: "olm.package", Name: "etcd-operator", DefaultChannel: "alpha"}, dc.Packages[2])
+	_, err = declcfg.ConvertToModel(dc)
+	require.NoError(t, err)
+}
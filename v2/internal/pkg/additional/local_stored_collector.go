@@ -2,6 +2,7 @@ package additional
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -48,6 +49,9 @@ func (o LocalStorageCollector) destinationRegistry() string {
 func (o LocalStorageCollector) AdditionalImagesCollector(ctx context.Context) ([]v2alpha1.CopyImageSchema, error) {
 
 	var allImages []v2alpha1.CopyImageSchema
+	// the images which could not be collected are left out,
+	// their errors are returned along with the collected images
+	var errs []error
 
 	o.Log.Debug(collectorPrefix+"setting copy option o.Opts.MultiArch=%s when collecting releases image", o.Opts.MultiArch)
 	for _, img := range o.Config.ImageSetConfigurationSpec.Mirror.AdditionalImages {
@@ -85,7 +89,8 @@ func (o LocalStorageCollector) AdditionalImagesCollector(ctx context.Context) ([
 			imgSpec, err := image.ParseRef(img.Name)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
-				return nil, err
+				errs = append(errs, image.NewCollectionError(img.Name, err))
+				continue
 			}
 
 			if imgSpec.Transport == dockerProtocol {
@@ -116,19 +121,22 @@ func (o LocalStorageCollector) AdditionalImagesCollector(ctx context.Context) ([
 		}
 		if tmpSrc == "" || tmpDest == "" {
 			o.Log.Error(collectorPrefix+"unable to determine src %s or dst %s for %s", tmpSrc, tmpDest, img.Name)
-			return allImages, fmt.Errorf("unable to determine src %s or dst %s for %s", tmpSrc, tmpDest, img.Name)
+			errs = append(errs, image.NewCollectionError(img.Name, fmt.Errorf("unable to determine src %s or dst %s for %s", tmpSrc, tmpDest, img.Name)))
+			continue
 		}
 		srcSpec, err := image.ParseRef(tmpSrc) // makes sure this ref is valid, and adds transport if needed
 		if err != nil {
 			o.Log.Error(errMsg, err.Error())
-			return nil, err
+			errs = append(errs, image.NewCollectionError(img.Name, err))
+			continue
 		}
 		src = srcSpec.ReferenceWithTransport
 
 		destSpec, err := image.ParseRef(tmpDest) // makes sure this ref is valid, and adds transport if needed
 		if err != nil {
 			o.Log.Error(errMsg, err.Error())
-			return nil, err
+			errs = append(errs, image.NewCollectionError(img.Name, err))
+			continue
 		}
 		dest = destSpec.ReferenceWithTransport
 
@@ -137,5 +145,5 @@ func (o LocalStorageCollector) AdditionalImagesCollector(ctx context.Context) ([
 
 		allImages = append(allImages, v2alpha1.CopyImageSchema{Source: src, Destination: dest, Origin: origin, Type: v2alpha1.TypeGeneric})
 	}
-	return allImages, nil
+	return allImages, errors.Join(errs...)
 }
@@ -63,6 +63,13 @@ type Mirror struct {
 	// Samples defines the configuration for Sample content types.
 	// This is currently not implemented.
 	Samples []SampleImages `json:"samples,omitempty"`
+	// Architectures, when set, restricts the operator and additional images
+	// copied from manifest lists to the instances of these architectures.
+	// The manifest lists are mirrored unchanged, referencing instances that
+	// are not copied, so that their digests and signatures remain valid.
+	// These sparse manifest lists cannot be pulled on nodes of the other
+	// architectures, nor copied again with all their instances.
+	Architectures []string `json:"architectures,omitempty"`
 }
 
 // Delete defines the configuration for content types within the imageset.
@@ -84,8 +91,13 @@ type Delete struct {
 // Platform defines the configuration for OpenShift and OKD platform types.
 type Platform struct {
 	// Graph defines whether Cincinnati graph data will
-	// downloaded and publish
+	// downloaded and publish. The graph data only holds the
+	// channels, and their versions, selected in Channels
 	Graph bool `json:"graph,omitempty"`
+	// GraphImage defines the destination repository and tag
+	// of the graph image, when Graph is true.
+	// Defaults to openshift/graph-image:latest
+	GraphImage GraphImage `json:"graphImage,omitempty"`
 	// Channels defines the configuration for individual
 	// OCP and OKD channels
 	Channels []ReleaseChannel `json:"channels,omitempty"`
@@ -100,11 +112,24 @@ type Platform struct {
 	// will be used to extract the kubeVirtContainer image
 	// from the release payload file 0000_50_installer_coreos-bootimages
 	KubeVirtContainer bool `json:"kubeVirtContainer,omitempty"`
+	// The osImages flag when set to true (default false)
+	// will also mirror the RHCOS and OS extension images of the
+	// release payload (rhel-coreos*, machine-os-*) to a dedicated
+	// repository, tagged by release, for clusters layering RHCOS
+	OSImages bool `json:"osImages,omitempty"`
+	// The supportImages flag when set to true (default false)
+	// will also mirror the troubleshooting images of the release
+	// payload (must-gather, support-tools, network-tools) to a
+	// dedicated repository, tagged by release, for day-2 support
+	SupportImages bool `json:"supportImages,omitempty"`
 }
 
 func (p Platform) DeepCopy() Platform {
 	platformCopy := Platform{
-		Graph: p.Graph,
+		Graph:         p.Graph,
+		GraphImage:    p.GraphImage,
+		OSImages:      p.OSImages,
+		SupportImages: p.SupportImages,
 	}
 
 	platformCopy.Channels = make([]ReleaseChannel, len(p.Channels))
@@ -116,6 +141,16 @@ func (p Platform) DeepCopy() Platform {
 	return platformCopy
 }
 
+// GraphImage defines the name of the graph image in the
+// destination registry
+type GraphImage struct {
+	// Repository of the graph image, relative to the
+	// destination registry (e.g. mynamespace/graph-data)
+	Repository string `json:"repository,omitempty"`
+	// Tag of the graph image
+	Tag string `json:"tag,omitempty"`
+}
+
 // ReleaseChannel defines the configuration for individual
 // OCP and OKD channels
 type ReleaseChannel struct {
@@ -179,8 +214,13 @@ type Operator struct {
 	// of bundles included in the diff if true.
 	SkipDependencies bool `json:"skipDependencies,omitempty"`
 	// path on disk for a template to use to complete catalogSource custom resource
-	// generated by oc-mirror
+	// generated by oc-mirror. The template can use the variables {{ .CatalogImage }},
+	// {{ .CatalogSourceName }}, {{ .TargetNamespace }} and {{ .SourceCatalog }}
 	TargetCatalogSourceTemplate string `json:"targetCatalogSourceTemplate,omitempty"`
+	// namespace of the catalogSource custom resource generated by oc-mirror,
+	// also available to the template as {{ .TargetNamespace }}.
+	// Defaults to openshift-marketplace
+	TargetCatalogSourceNamespace string `json:"targetCatalogSourceNamespace,omitempty"`
 }
 
 // GetUniqueName determines the catalog name that will
@@ -20,6 +20,8 @@ const (
 	TypeGeneric
 	TypeKubeVirtContainer
 	TypeHelmImage
+	TypeOCPOSImage
+	TypeOCPSupportImage
 )
 
 // ImageTypeString defines the string
@@ -33,6 +35,8 @@ var imageTypeStrings = map[ImageType]string{
 	TypeOperatorRelatedImage: "operatorRelatedImage",
 	TypeGeneric:              "generic",
 	TypeHelmImage:            "helmImage",
+	TypeOCPOSImage:           "ocpOSImage",
+	TypeOCPSupportImage:      "ocpSupportImage",
 }
 
 var imageStringsType = map[string]ImageType{
@@ -44,10 +48,12 @@ var imageStringsType = map[string]ImageType{
 	"operatorRelatedImage": TypeOperatorRelatedImage,
 	"generic":              TypeGeneric,
 	"helmImage":            TypeHelmImage,
+	"ocpOSImage":           TypeOCPOSImage,
+	"ocpSupportImage":      TypeOCPSupportImage,
 }
 
 func (it ImageType) IsRelease() bool {
-	return it == TypeOCPRelease || it == TypeOCPReleaseContent || it == TypeCincinnatiGraph || it == TypeKubeVirtContainer
+	return it == TypeOCPRelease || it == TypeOCPReleaseContent || it == TypeCincinnatiGraph || it == TypeKubeVirtContainer || it == TypeOCPOSImage || it == TypeOCPSupportImage
 }
 
 func (it ImageType) IsOperator() bool {
@@ -248,12 +248,19 @@ type DeleteImageList struct {
 	Kind       string       `json:"kind"`
 	APIVersion string       `json:"apiVersion"`
 	Items      []DeleteItem `json:"items"`
+	// Signature: HMAC-SHA256 of the list, keyed by the delete signing key
+	// of the workspace it was generated in
+	Signature string `json:"signature,omitempty"`
 }
 
 type DeleteItem struct {
 	ImageName      string    `json:"imageName"`
 	ImageReference string    `json:"imageReference"`
 	Type           ImageType `json:"type"`
+	// Digest: digest of the image in the remote registry when the list was generated
+	Digest string `json:"digest,omitempty"`
+	// Reason: why the image is deleted
+	Reason string `json:"reason,omitempty"`
 }
 
 type CatalogFilterResult struct {
@@ -10,6 +10,13 @@ import (
 // release payloads.
 const DefaultPlatformArchitecture = "amd64"
 
+// DefaultGraphImageRepository and DefaultGraphImageTag
+// define the name of the graph image when not configured.
+const (
+	DefaultGraphImageRepository = "openshift/graph-image"
+	DefaultGraphImageTag        = "latest"
+)
+
 // PlatformType defines the content type for platforms
 type PlatformType int
 
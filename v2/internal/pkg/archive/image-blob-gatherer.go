@@ -2,12 +2,16 @@ package archive
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
 	"github.com/containers/image/v5/manifest"
 	"github.com/containers/image/v5/transports/alltransports"
 	"github.com/containers/image/v5/types"
+	"github.com/docker/distribution/registry/api/errcode"
+	v2 "github.com/docker/distribution/registry/api/v2"
+	"github.com/opencontainers/go-digest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 )
 
@@ -68,13 +72,22 @@ func (o *ImageBlobGatherer) GatherBlobs(ctx context.Context, imgRef string) (blo
 		if err != nil {
 			return nil, err
 		}
+		selected, err := o.selectedInstances(manifestList)
+		if err != nil {
+			return nil, err
+		}
 		instances := manifestList.Instances()
 		for _, digest := range instances {
-			blobs[digest.String()] = ""
 			singleArchManifest, singleArchMime, err := img.GetManifest(ctx, &digest)
 			if err != nil {
+				// the instances of other architectures are not in the cache
+				// when the manifest list was filtered by architecture
+				if !selected[digest] && isManifestUnknown(err) {
+					continue
+				}
 				return nil, err
 			}
+			blobs[digest.String()] = ""
 			singleArchBlobs, err := o.getBlobsOfManifest(singleArchManifest, singleArchMime)
 			if err != nil {
 				return nil, err
@@ -99,6 +112,31 @@ func (o *ImageBlobGatherer) GatherBlobs(ctx context.Context, imgRef string) (blo
 	return blobs, nil
 }
 
+// isManifestUnknown - returns true when err is the manifest unknown
+// error code returned by the registry
+func isManifestUnknown(err error) bool {
+	var ec errcode.ErrorCoder
+	return errors.As(err, &ec) && ec.ErrorCode() == v2.ErrorCodeManifestUnknown
+}
+
+// selectedInstances - returns the instances of list copied with the
+// architectures filter, or all the instances when there is no filter
+func (o *ImageBlobGatherer) selectedInstances(list manifest.List) (map[digest.Digest]bool, error) {
+	instances := list.Instances()
+	if len(o.opts.Architectures) > 0 {
+		var err error
+		instances, err = mirror.InstancesForArchitectures(list, o.opts.Architectures)
+		if err != nil {
+			return nil, err
+		}
+	}
+	selected := map[digest.Digest]bool{}
+	for _, d := range instances {
+		selected[d] = true
+	}
+	return selected, nil
+}
+
 func (o *ImageBlobGatherer) getBlobsOfManifest(manifestBytes []byte, mimeType string) ([]string, error) {
 	blobs := []string{}
 	singleArchManifest, err := manifest.FromBlob(manifestBytes, mimeType)
@@ -6,10 +6,21 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	"golang.org/x/exp/maps"
 )
 
+// copyOptionsFor - returns the copy options for img: the architectures
+// filter only applies to the operator bundles, their related images and the additional images
+func copyOptionsFor(img v2alpha1.CopyImageSchema, opts mirror.CopyOptions) *mirror.CopyOptions {
+	if img.Type != v2alpha1.TypeOperatorBundle && img.Type != v2alpha1.TypeOperatorRelatedImage && !img.Type.IsAdditionalImage() {
+		opts.Architectures = nil
+	}
+	return &opts
+}
+
 func saveErrors(logger clog.PluggableLoggerInterface, logsDir string, errArray []mirrorErrorSchema) (string, error) {
 	if len(errArray) > 0 {
 		timestamp := time.Now().Format("20060102_150405")
@@ -26,6 +37,12 @@ func saveErrors(logger clog.PluggableLoggerInterface, logsDir string, errArray [
 			logger.Error(workerPrefix + errorMsg)
 			fmt.Fprintln(file, errorMsg)
 		}
+
+		// the same errors, for tools processing the outcome of the run
+		reportFile, err := SaveFailureReport(logsDir, "mirroring_errors", failures(errArray))
+		if err != nil {
+			logger.Warn(workerPrefix+"failed to write the failure report %s: %s", reportFile, err.Error())
+		}
 		return filename, nil
 	}
 	return "", nil
@@ -14,6 +14,7 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/progress"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/spinners"
 	"github.com/vbauerster/mpb/v8"
 	"github.com/vbauerster/mpb/v8/decor"
@@ -71,7 +72,16 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 	o.Log.Info(emoji.Pushpin+" images to %s %d ", opts.Function, len(collectorSchema.AllImages))
 
 	total := len(collectorSchema.AllImages)
-	p := mpb.New(mpb.PopCompletedMode())
+	tracker := progress.New(total)
+	opts.Progress = tracker
+	interactive := isInteractive(opts.Global.LogFormat)
+	progressOpts := []mpb.ContainerOption{mpb.PopCompletedMode()}
+	if !interactive {
+		// spinners would corrupt the structured output or fill the
+		// logs of CI jobs, summary lines are logged periodically instead
+		progressOpts = append(progressOpts, mpb.WithOutput(nil))
+	}
+	p := mpb.New(progressOpts...)
 	results := make(chan GoroutineResult, total)
 	progressCh := make(chan int, total)
 	semaphore := make(chan struct{}, o.MaxGoroutines)
@@ -133,7 +143,15 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 							triggered = true
 							timeoutCtx, _ := opts.Global.CommandTimeoutContext()
 
-							err = o.Mirror.Run(timeoutCtx, img.Source, img.Destination, mirror.Mode(opts.Function), &opts)
+							imgStart := time.Now()
+							err = o.Mirror.Run(timeoutCtx, img.Source, img.Destination, mirror.Mode(opts.Function), copyOptionsFor(img, opts))
+							clog.LogEvent(o.Log, clog.Event{
+								Image:    img.Origin,
+								Type:     img.Type.String(),
+								Phase:    opts.Function,
+								Duration: time.Since(imgStart),
+								Err:      err,
+							})
 
 							switch {
 							case err == nil:
@@ -158,9 +176,12 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 		wg.Wait()
 	}()
 
-	overallProgress := newOverallProgress(p, total)
+	overallProgress := newOverallProgress(p, total, tracker)
 
 	go runOverallProgress(overallProgress, cancelCtx, progressCh)
+	if !interactive {
+		go runProgressSummary(cancelCtx, o.Log, tracker, progress.SummaryInterval)
+	}
 
 	completed := 0
 	for completed < len(collectorSchema.AllImages) {
@@ -170,16 +191,18 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 			copiedImages.AllImages = append(copiedImages.AllImages, res.img)
 			incrementTotals(res.imgType, &copiedImages)
 		} else {
+			err.timestamp = time.Now()
 			m.Lock()
 			errArray = append(errArray, *err)
 			m.Unlock()
 
-			if res.imgType.IsRelease() {
+			if res.imgType.IsRelease() && !opts.Global.ContinueOnError {
 				cancel()
 				break
 			}
 		}
 
+		tracker.ImageDone(res.img.Source)
 		completed++
 		progressCh <- 1
 	}
@@ -187,6 +210,8 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 
 	p.Wait()
 
+	o.Log.Info(emoji.Hourglass+" %s", tracker.Snapshot().String())
+
 	logResults(o.Log, opts.Function, &copiedImages, &collectorSchema)
 
 	if len(errArray) > 0 {
@@ -276,40 +301,9 @@ func newSpinner(img v2alpha1.CopyImageSchema, localStorage string, p *mpb.Progre
 	)
 }
 
-func newOverallProgress(p *mpb.Progress, total int) *mpb.Bar {
-	return p.AddBar(int64(total),
-		mpb.PrependDecorators(
-			decor.CountersNoUnit("%d / %d"),
-			decor.Name(" ("),
-			decor.Elapsed(decor.ET_STYLE_GO),
-			decor.Name(")"),
-		),
-		mpb.AppendDecorators(
-			decor.Percentage(),
-		),
-		mpb.BarPriority(total+1),
-	)
-}
-
-func runOverallProgress(overallProgress *mpb.Bar, cancelCtx context.Context, progressCh chan int) {
-	var progress int
-
-	for {
-		select {
-		case <-cancelCtx.Done():
-			overallProgress.Abort(false)
-			return
-		case <-progressCh:
-			progress++
-			overallProgress.SetCurrent(int64(progress))
-		}
-	}
-
-}
-
 func incrementTotals(imgType v2alpha1.ImageType, copiedImages *v2alpha1.CollectorSchema) {
 	switch imgType {
-	case v2alpha1.TypeCincinnatiGraph, v2alpha1.TypeOCPRelease, v2alpha1.TypeOCPReleaseContent:
+	case v2alpha1.TypeCincinnatiGraph, v2alpha1.TypeOCPRelease, v2alpha1.TypeOCPReleaseContent, v2alpha1.TypeOCPOSImage, v2alpha1.TypeOCPSupportImage:
 		copiedImages.TotalReleaseImages++
 	case v2alpha1.TypeGeneric:
 		copiedImages.TotalAdditionalImages++
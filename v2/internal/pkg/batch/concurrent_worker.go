@@ -115,14 +115,14 @@ func (o *ConcurrentBatch) Worker(ctx context.Context, collectorSchema v2alpha1.C
 				// OCPBUGS-43489
 				// Ensure local cache images get deleted when --force-delete-cache flag is used
 				// This reverts OCPBUGS-44448 (the root cause was a problem is in the DeleteDestination)
-				err := o.Mirror.Run(ctx, img.Source, img.Destination, mirror.Mode(opts.Function), &opts)
+				err := o.Mirror.Run(ctx, img.Source, img.Destination, mirror.Mode(opts.Function), copyOptionsFor(img, opts))
 				mu.Lock()
 				switch {
 				case err == nil:
 					o.CopiedImages.AllImages = append(o.CopiedImages.AllImages, img)
 					spinner.Increment()
 					switch img.Type {
-					case v2alpha1.TypeCincinnatiGraph, v2alpha1.TypeOCPRelease, v2alpha1.TypeOCPReleaseContent:
+					case v2alpha1.TypeCincinnatiGraph, v2alpha1.TypeOCPRelease, v2alpha1.TypeOCPReleaseContent, v2alpha1.TypeOCPOSImage, v2alpha1.TypeOCPSupportImage:
 						o.CopiedImages.TotalReleaseImages++
 					case v2alpha1.TypeGeneric:
 						o.CopiedImages.TotalAdditionalImages++
@@ -136,6 +136,9 @@ func (o *ConcurrentBatch) Worker(ctx context.Context, collectorSchema v2alpha1.C
 					bundles := collectorSchema.CopyImageSchemaMap.BundlesByImage[img.Origin]
 					errArray = append(errArray, mirrorErrorSchema{image: img, err: err, operators: operators, bundles: bundles})
 					spinner.Abort(false)
+				case img.Type.IsRelease() && opts.Global.ContinueOnError:
+					errArray = append(errArray, mirrorErrorSchema{image: img, err: err})
+					spinner.Abort(false)
 				case img.Type.IsRelease():
 					// error on release image, save the errArray and immediately return `UnsafeError` to caller
 					currentMirrorError := mirrorErrorSchema{image: img, err: err}
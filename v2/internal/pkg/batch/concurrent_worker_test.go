@@ -2,8 +2,10 @@ package batch
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -166,6 +168,34 @@ func TestConcurrentWorker(t *testing.T) {
 
 		assert.GreaterOrEqual(t, len(relatedImages), len(copiedImages.AllImages))
 	})
+	t.Run("Testing channel d2m Worker - 1 err release with continue-on-error: should copy the other images and report the failure", func(t *testing.T) {
+		reportDir := t.TempDir()
+		continueOpts := d2mopts
+		continueOpts.Global = &mirror.GlobalOptions{ContinueOnError: true}
+		mirrorMock := new(MirrorMock)
+		mirrorMock.On("Run", mock.Anything, "docker://registry/name/namespace/sometestimage-b@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", mock.Anything, mock.Anything, mock.Anything).Return(errcode.Error{Code: errcode.ErrorCodeManifestUnknown, Message: "Manifest Unknown"})
+		mirrorMock.On("Run", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		w := New(ChannelConcurrentWorker, log, reportDir, mirrorMock, uint(1))
+
+		copiedImages, err := w.Worker(context.Background(), collectedImages, continueOpts)
+		if _, ok := err.(SafeError); !ok {
+			t.Fatalf("expected error type SafeError, but was %v", err)
+		}
+		assert.Equal(t, len(relatedImages)-1, len(copiedImages.AllImages))
+
+		reports, err := filepath.Glob(filepath.Join(reportDir, "mirroring_errors_*.json"))
+		assert.NoError(t, err)
+		assert.Len(t, reports, 1)
+		data, err := os.ReadFile(reports[0])
+		assert.NoError(t, err)
+		var report []Failure
+		assert.NoError(t, json.Unmarshal(data, &report))
+		assert.Len(t, report, 1)
+		assert.Equal(t, relatedImages[1].Origin, report[0].Image)
+		assert.Equal(t, v2alpha1.TypeOCPReleaseContent.String(), report[0].Type)
+		assert.Contains(t, report[0].Error, "Manifest Unknown")
+		assert.False(t, report[0].Timestamp.IsZero())
+	})
 	t.Run("Testing d2m Worker - 2 errors: should return safe error", func(t *testing.T) {
 		mirrorMock := new(MirrorMock)
 		mirrorMock.On("Run", mock.Anything, "docker://registry/name/namespace/sometestimage-f@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", mock.Anything, mock.Anything, mock.Anything).Return(errcode.Error{Code: errcode.ErrorCodeUnauthorized, Message: "unauthorized"})
@@ -476,6 +506,29 @@ func TestShouldSkipImage(t *testing.T) {
 	}
 }
 
+func TestCopyOptionsFor(t *testing.T) {
+	opts := mirror.CopyOptions{Architectures: []string{"amd64"}}
+	testCases := []struct {
+		imgType  v2alpha1.ImageType
+		expected []string
+	}{
+		{imgType: v2alpha1.TypeOperatorBundle, expected: []string{"amd64"}},
+		{imgType: v2alpha1.TypeOperatorRelatedImage, expected: []string{"amd64"}},
+		{imgType: v2alpha1.TypeGeneric, expected: []string{"amd64"}},
+		{imgType: v2alpha1.TypeOperatorCatalog},
+		{imgType: v2alpha1.TypeOCPRelease},
+		{imgType: v2alpha1.TypeOCPReleaseContent},
+		{imgType: v2alpha1.TypeHelmImage},
+	}
+	for _, testCase := range testCases {
+		t.Run("Testing copyOptionsFor "+testCase.imgType.String()+" : should filter the architectures of operator and additional images only", func(t *testing.T) {
+			res := copyOptionsFor(v2alpha1.CopyImageSchema{Type: testCase.imgType}, opts)
+			assert.Equal(t, testCase.expected, res.Architectures)
+		})
+	}
+	assert.Equal(t, []string{"amd64"}, opts.Architectures)
+}
+
 type MirrorMock struct {
 	mock.Mock
 }
@@ -0,0 +1,77 @@
+package batch
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/progress"
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+	"golang.org/x/term"
+)
+
+// isInteractive - progress bars are only rendered when stdout is a terminal
+// and the logs are human oriented, otherwise summary lines are logged
+func isInteractive(logFormat string) bool {
+	return logFormat != clog.JSONFormat && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func newOverallProgress(p *mpb.Progress, total int, tracker *progress.Tracker) *mpb.Bar {
+	return p.AddBar(int64(total),
+		mpb.PrependDecorators(
+			decor.CountersNoUnit("%d / %d"),
+			decor.Any(func(decor.Statistics) string {
+				s := tracker.Snapshot()
+				return " " + progress.FormatBytes(s.BytesCopied) + " (" + progress.FormatBytes(int64(s.BytesPerSec)) + "/s)"
+			}),
+			decor.Name(" ("),
+			decor.Elapsed(decor.ET_STYLE_GO),
+			decor.Name(")"),
+		),
+		mpb.AppendDecorators(
+			decor.Percentage(),
+			decor.Any(func(decor.Statistics) string {
+				s := tracker.Snapshot()
+				if !s.ETAKnown {
+					return ""
+				}
+				return " ETA " + s.ETA.Round(time.Second).String()
+			}),
+		),
+		mpb.BarPriority(total+1),
+	)
+}
+
+func runOverallProgress(overallProgress *mpb.Bar, cancelCtx context.Context, progressCh chan int) {
+	var current int
+
+	for {
+		select {
+		case <-cancelCtx.Done():
+			overallProgress.Abort(false)
+			return
+		case <-progressCh:
+			current++
+			overallProgress.SetCurrent(int64(current))
+		}
+	}
+
+}
+
+// runProgressSummary - logs a summary line every interval until ctx is done
+func runProgressSummary(ctx context.Context, log clog.PluggableLoggerInterface, tracker *progress.Tracker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			log.Info(emoji.Hourglass+" %s", tracker.Snapshot().String())
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Failure - an image that could not be mirrored, as recorded in the JSON failure report
+type Failure struct {
+	Image     string    `json:"image"`
+	Type      string    `json:"type"`
+	Error     string    `json:"error"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SaveFailureReport - writes failures as a JSON array in a timestamped file
+// of logsDir named after prefix, and returns the name of the file
+func SaveFailureReport(logsDir, prefix string, failures []Failure) (string, error) {
+	filename := fmt.Sprintf("%s_%s.json", prefix, time.Now().Format("20060102_150405"))
+	data, err := json.MarshalIndent(failures, "", "  ")
+	if err != nil {
+		return filename, err
+	}
+	return filename, os.WriteFile(filepath.Join(logsDir, filename), data, 0644)
+}
+
+// failures - returns the failure report entries of errArray
+func failures(errArray []mirrorErrorSchema) []Failure {
+	report := make([]Failure, 0, len(errArray))
+	for _, e := range errArray {
+		timestamp := e.timestamp
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		report = append(report, Failure{
+			Image:     e.image.Origin,
+			Type:      e.image.Type.String(),
+			Error:     e.err.Error(),
+			Timestamp: timestamp.UTC(),
+		})
+	}
+	return report
+}
@@ -1,6 +1,8 @@
 package batch
 
 import (
+	"time"
+
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 )
@@ -17,6 +19,7 @@ type mirrorErrorSchema struct {
 	err       error
 	operators map[string]struct{}
 	bundles   StringMap
+	timestamp time.Time
 }
 
 func (e mirrorErrorSchema) Error() string {
@@ -0,0 +1,143 @@
+package blobstore
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	// registryBlobsDir - content addressed blob store of the local cache registry
+	registryBlobsDir = "docker/registry/v2/blobs"
+	// ociBlobsDir - blob directory of an OCI layout
+	ociBlobsDir  = "blobs"
+	blobDataFile = "data"
+)
+
+// Stats - the outcome of a compaction
+type Stats struct {
+	Linked  int   // blobs replaced by a hardlink to the store
+	Added   int   // blobs added to the store
+	Skipped int   // blobs that could not be hardlinked (different filesystem, size mismatch)
+	Saved   int64 // bytes reclaimed
+}
+
+// Store - the blob store of the local cache registry, where each blob is
+// kept once under its digest. Blobs of OCI layouts found elsewhere (the
+// workspace copies of catalogs and releases) are hardlinked to it, so that
+// identical content takes disk space only once.
+type Store struct {
+	root string
+}
+
+// New - returns the store of the local cache registry rooted at cacheDir
+func New(cacheDir string) *Store {
+	return &Store{root: filepath.Join(cacheDir, registryBlobsDir)}
+}
+
+// path - returns the location of the blob d in the store, using the
+// layout of the distribution filesystem driver
+func (s *Store) path(d digest.Digest) string {
+	return filepath.Join(s.root, d.Algorithm().String(), d.Encoded()[:2], d.Encoded(), blobDataFile)
+}
+
+// Compact - hardlinks the blobs of every OCI layout found under dirs to the store,
+// adding the blobs the store does not hold yet
+func (s *Store) Compact(dirs ...string) (Stats, error) {
+	var stats Stats
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			dgst, ok := ociBlobDigest(path)
+			if !ok {
+				return nil
+			}
+			return s.link(path, dgst, &stats)
+		})
+		if err != nil {
+			return stats, fmt.Errorf("compacting %s: %w", dir, err)
+		}
+	}
+	return stats, nil
+}
+
+// link - replaces the blob at path with a hardlink to the store
+func (s *Store) link(path string, dgst digest.Digest, stats *Stats) error {
+	blob, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	target := s.path(dgst)
+	stored, err := os.Stat(target)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.Link(path, target); err != nil {
+			if isCrossDevice(err) {
+				stats.Skipped++
+				return nil
+			}
+			return err
+		}
+		stats.Added++
+		return nil
+	case err != nil:
+		return err
+	case os.SameFile(blob, stored):
+		return nil
+	case blob.Size() != stored.Size():
+		// a truncated or corrupted copy, leave it alone
+		stats.Skipped++
+		return nil
+	}
+
+	// link next to the blob then rename over it, so that the blob is never missing
+	tmp := path + ".link"
+	if err := os.Link(target, tmp); err != nil {
+		if isCrossDevice(err) {
+			stats.Skipped++
+			return nil
+		}
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	stats.Linked++
+	stats.Saved += blob.Size()
+	return nil
+}
+
+// ociBlobDigest - returns the digest of the OCI layout blob at path
+// (<layout>/blobs/<algorithm>/<encoded>)
+func ociBlobDigest(path string) (digest.Digest, bool) {
+	algDir := filepath.Dir(path)
+	if filepath.Base(filepath.Dir(algDir)) != ociBlobsDir {
+		return "", false
+	}
+	dgst := digest.NewDigestFromEncoded(digest.Algorithm(filepath.Base(algDir)), filepath.Base(path))
+	if dgst.Validate() != nil {
+		return "", false
+	}
+	return dgst, true
+}
+
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
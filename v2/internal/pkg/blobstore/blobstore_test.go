@@ -0,0 +1,82 @@
+package blobstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeOCIBlob(t *testing.T, layout string, content []byte) string {
+	t.Helper()
+	dgst := digest.FromBytes(content)
+	path := filepath.Join(layout, ociBlobsDir, dgst.Algorithm().String(), dgst.Encoded())
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	return path
+}
+
+func TestCompact(t *testing.T) {
+	t.Run("Testing Compact : should hardlink duplicate blobs to the store", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		cacheDir := filepath.Join(tmpDir, "cache")
+		workingDir := filepath.Join(tmpDir, "working-dir")
+		store := New(cacheDir)
+
+		shared := []byte("shared layer")
+		stored := store.path(digest.FromBytes(shared))
+		require.NoError(t, os.MkdirAll(filepath.Dir(stored), 0755))
+		require.NoError(t, os.WriteFile(stored, shared, 0644))
+
+		first := writeOCIBlob(t, filepath.Join(workingDir, "operator-images", "redhat-operator-index"), shared)
+		second := writeOCIBlob(t, filepath.Join(workingDir, "operator-images", "certified-operator-index"), shared)
+		unique := writeOCIBlob(t, filepath.Join(workingDir, "operator-images", "certified-operator-index"), []byte("unique"))
+		require.NoError(t, os.WriteFile(filepath.Join(workingDir, "operator-images", "certified-operator-index", "index.json"), []byte("{}"), 0644))
+
+		stats, err := store.Compact(workingDir, filepath.Join(tmpDir, "missing"))
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Linked: 2, Added: 1, Saved: int64(2 * len(shared))}, stats)
+
+		storedInfo, err := os.Stat(stored)
+		require.NoError(t, err)
+		for _, path := range []string{first, second} {
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.True(t, os.SameFile(storedInfo, info), path)
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, shared, content)
+		}
+		uniqueInfo, err := os.Stat(unique)
+		require.NoError(t, err)
+		addedInfo, err := os.Stat(store.path(digest.FromBytes([]byte("unique"))))
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(uniqueInfo, addedInfo))
+
+		// a second pass has nothing left to do
+		stats, err = store.Compact(workingDir)
+		require.NoError(t, err)
+		assert.Equal(t, Stats{}, stats)
+	})
+
+	t.Run("Testing Compact : should skip blobs whose size differs from the store", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		store := New(filepath.Join(tmpDir, "cache"))
+
+		content := []byte("layer")
+		stored := store.path(digest.FromBytes(content))
+		require.NoError(t, os.MkdirAll(filepath.Dir(stored), 0755))
+		require.NoError(t, os.WriteFile(stored, content[:2], 0644))
+		path := writeOCIBlob(t, filepath.Join(tmpDir, "layout"), content)
+
+		stats, err := store.Compact(filepath.Join(tmpDir, "layout"))
+		require.NoError(t, err)
+		assert.Equal(t, Stats{Skipped: 1}, stats)
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+	})
+}
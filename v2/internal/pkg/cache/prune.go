@@ -0,0 +1,324 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/registry/storage"
+	"github.com/distribution/distribution/v3/registry/storage/driver"
+	"github.com/distribution/distribution/v3/registry/storage/driver/filesystem"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+)
+
+// ErrNoRuns - returned by Prune when no mirroring run is recorded in the local cache,
+// as every image would be removed
+var ErrNoRuns = errors.New("no mirroring run recorded in the local cache")
+
+// Policy - which mirroring runs keep their images in the local cache.
+// The most recent run is always kept.
+type Policy struct {
+	KeepRuns int           // number of most recent runs kept, 0 keeps every run
+	MaxAge   time.Duration // runs older than MaxAge are dropped, 0 disables the limit
+	MaxSize  int64         // runs are dropped, oldest first, until the kept blobs fit in MaxSize bytes, 0 disables the limit
+}
+
+// Enabled - returns true when the policy limits the runs kept
+func (p Policy) Enabled() bool {
+	return p.KeepRuns > 0 || p.MaxAge > 0 || p.MaxSize > 0
+}
+
+// Stats - the outcome of a prune
+type Stats struct {
+	RunsKept         int
+	RunsDropped      int
+	ManifestsRemoved int
+	BlobsRemoved     int
+	BytesFreed       int64
+	BytesKept        int64
+}
+
+// marks - the manifests (per repository) and the blobs of the images kept
+type marks struct {
+	manifests map[string]map[digest.Digest]struct{}
+	blobs     map[digest.Digest]struct{}
+}
+
+// Prune - removes from the local cache rooted at storageDir the manifests and
+// blobs that are not used by the mirroring runs kept by policy
+func Prune(ctx context.Context, storageDir string, policy Policy) (Stats, error) {
+	var stats Stats
+	runs, err := readRuns(storageDir)
+	if err != nil {
+		return stats, err
+	}
+	if len(runs) == 0 {
+		return stats, ErrNoRuns
+	}
+
+	storageDriver := filesystem.New(filesystem.DriverParameters{RootDirectory: storageDir, MaxThreads: 100})
+	reg, err := storage.NewRegistry(ctx, storageDriver, storage.EnableDelete)
+	if err != nil {
+		return stats, err
+	}
+	sizes, err := blobSizes(ctx, reg)
+	if err != nil {
+		return stats, err
+	}
+
+	kept := policy.keptRuns(runs, time.Now())
+	var m marks
+	for {
+		m, err = mark(ctx, reg, runs[:kept])
+		if err != nil {
+			return stats, err
+		}
+		stats.BytesKept = m.size(sizes)
+		if policy.MaxSize == 0 || stats.BytesKept <= policy.MaxSize || kept == 1 {
+			break
+		}
+		kept--
+	}
+
+	if err := sweep(ctx, storageDriver, reg, m, sizes, &stats); err != nil {
+		return stats, err
+	}
+
+	// the dropped runs do not protect any image anymore
+	for _, run := range runs[kept:] {
+		if err := os.Remove(run.file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return stats, err
+		}
+	}
+	stats.RunsKept, stats.RunsDropped = kept, len(runs)-kept
+	return stats, nil
+}
+
+// keptRuns - returns the number of most recent runs kept by the policy, not
+// considering MaxSize
+func (p Policy) keptRuns(runs []Run, now time.Time) int {
+	kept := len(runs)
+	if p.KeepRuns > 0 && p.KeepRuns < kept {
+		kept = p.KeepRuns
+	}
+	if p.MaxAge > 0 {
+		for i := 1; i < kept; i++ {
+			if now.Sub(runs[i].Timestamp) > p.MaxAge {
+				kept = i
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// size - returns the size of the blobs marked
+func (m marks) size(sizes map[digest.Digest]int64) int64 {
+	var size int64
+	for dgst := range m.blobs {
+		size += sizes[dgst]
+	}
+	return size
+}
+
+// mark - marks the manifests and blobs of the images used by runs
+func mark(ctx context.Context, reg distribution.Namespace, runs []Run) (marks, error) {
+	m := marks{
+		manifests: map[string]map[digest.Digest]struct{}{},
+		blobs:     map[digest.Digest]struct{}{},
+	}
+	for _, run := range runs {
+		for _, img := range run.Images {
+			named, err := reference.WithName(img.Repository)
+			if err != nil {
+				return m, fmt.Errorf("invalid repository %q in run record: %w", img.Repository, err)
+			}
+			repo, err := reg.Repository(ctx, named)
+			if err != nil {
+				return m, err
+			}
+			dgst := digest.Digest(img.Digest)
+			if dgst == "" {
+				desc, err := repo.Tags(ctx).Get(ctx, img.Tag)
+				if err != nil {
+					// the image is no longer in the cache
+					continue
+				}
+				dgst = desc.Digest
+			}
+			manifests, err := repo.Manifests(ctx)
+			if err != nil {
+				return m, err
+			}
+			if err := m.markManifest(ctx, img.Repository, manifests, dgst); err != nil {
+				return m, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// markManifest - marks the manifest dgst of repository, its blobs and the manifests
+// it references (the instances of a manifest list)
+func (m marks) markManifest(ctx context.Context, repository string, manifests distribution.ManifestService, dgst digest.Digest) error {
+	if _, found := m.manifests[repository][dgst]; found {
+		return nil
+	}
+	manifest, err := manifests.Get(ctx, dgst)
+	if err != nil {
+		var unknown distribution.ErrManifestUnknownRevision
+		if errors.As(err, &unknown) {
+			return nil
+		}
+		return fmt.Errorf("reading manifest %s of %s: %w", dgst, repository, err)
+	}
+	if m.manifests[repository] == nil {
+		m.manifests[repository] = map[digest.Digest]struct{}{}
+	}
+	m.manifests[repository][dgst] = struct{}{}
+	m.blobs[dgst] = struct{}{}
+
+	for _, ref := range manifest.References() {
+		m.blobs[ref.Digest] = struct{}{}
+		if exists, _ := manifests.Exists(ctx, ref.Digest); exists {
+			if err := m.markManifest(ctx, repository, manifests, ref.Digest); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sweep - removes the manifests, repository layer links and blobs not marked
+func sweep(ctx context.Context, storageDriver driver.StorageDriver, reg distribution.Namespace, m marks, sizes map[digest.Digest]int64, stats *Stats) error {
+	repositories, ok := reg.(distribution.RepositoryEnumerator)
+	if !ok {
+		return errors.New("unable to enumerate the repositories of the local cache")
+	}
+	vacuum := storage.NewVacuum(ctx, storageDriver)
+
+	err := repositories.Enumerate(ctx, func(repoName string) error {
+		named, err := reference.WithName(repoName)
+		if err != nil {
+			return err
+		}
+		repo, err := reg.Repository(ctx, named)
+		if err != nil {
+			return err
+		}
+		manifests, err := repo.Manifests(ctx)
+		if err != nil {
+			return err
+		}
+		enumerator, ok := manifests.(distribution.ManifestEnumerator)
+		if !ok {
+			return fmt.Errorf("unable to enumerate the manifests of %s", repoName)
+		}
+		var unmarked []digest.Digest
+		if err := enumerator.Enumerate(ctx, func(dgst digest.Digest) error {
+			if _, found := m.manifests[repoName][dgst]; !found {
+				unmarked = append(unmarked, dgst)
+			}
+			return nil
+		}); err != nil && !isPathNotFound(err) {
+			return err
+		}
+		for _, dgst := range unmarked {
+			if err := removeManifest(ctx, vacuum, repo, repoName, dgst); err != nil {
+				return err
+			}
+			stats.ManifestsRemoved++
+		}
+
+		layers, ok := repo.Blobs(ctx).(distribution.ManifestEnumerator)
+		if !ok {
+			return fmt.Errorf("unable to enumerate the layers of %s", repoName)
+		}
+		var unlinked []digest.Digest
+		if err := layers.Enumerate(ctx, func(dgst digest.Digest) error {
+			if _, found := m.blobs[dgst]; !found {
+				unlinked = append(unlinked, dgst)
+			}
+			return nil
+		}); err != nil && !isPathNotFound(err) {
+			return err
+		}
+		for _, dgst := range unlinked {
+			if err := vacuum.RemoveLayer(repoName, dgst); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && !isPathNotFound(err) {
+		return err
+	}
+
+	var unmarked []digest.Digest
+	if err := reg.Blobs().Enumerate(ctx, func(dgst digest.Digest) error {
+		if _, found := m.blobs[dgst]; !found {
+			unmarked = append(unmarked, dgst)
+		}
+		return nil
+	}); err != nil && !isPathNotFound(err) {
+		return err
+	}
+	for _, dgst := range unmarked {
+		if err := vacuum.RemoveBlob(dgst.String()); err != nil {
+			return err
+		}
+		stats.BlobsRemoved++
+		stats.BytesFreed += sizes[dgst]
+	}
+	return nil
+}
+
+// removeManifest - removes the manifest dgst of repo, and the tags pointing to it
+func removeManifest(ctx context.Context, vacuum storage.Vacuum, repo distribution.Repository, repoName string, dgst digest.Digest) error {
+	tagService := repo.Tags(ctx)
+	current, err := tagService.Lookup(ctx, distribution.Descriptor{Digest: dgst})
+	if err != nil && !isPathNotFound(err) {
+		return err
+	}
+	for _, tag := range current {
+		if err := tagService.Untag(ctx, tag); err != nil {
+			return err
+		}
+	}
+	// the tag indexes of the remaining tags may reference the manifest too
+	tags, err := tagService.All(ctx)
+	if err != nil {
+		var unknown distribution.ErrRepositoryUnknown
+		if !errors.As(err, &unknown) && !isPathNotFound(err) {
+			return err
+		}
+	}
+	return vacuum.RemoveManifest(repoName, dgst, tags)
+}
+
+// blobSizes - returns the size of every blob of the local cache
+func blobSizes(ctx context.Context, reg distribution.Namespace) (map[digest.Digest]int64, error) {
+	sizes := map[digest.Digest]int64{}
+	statter := reg.BlobStatter()
+	err := reg.Blobs().Enumerate(ctx, func(dgst digest.Digest) error {
+		desc, err := statter.Stat(ctx, dgst)
+		if err != nil {
+			return err
+		}
+		sizes[dgst] = desc.Size
+		return nil
+	})
+	if err != nil && !isPathNotFound(err) {
+		return nil, err
+	}
+	return sizes, nil
+}
+
+func isPathNotFound(err error) bool {
+	var notFound driver.PathNotFoundError
+	return errors.As(err, &notFound)
+}
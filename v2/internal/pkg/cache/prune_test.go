@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/ocischema"
+	"github.com/distribution/distribution/v3/registry/storage"
+	"github.com/distribution/distribution/v3/registry/storage/driver/filesystem"
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrune(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Testing Prune : should remove the blobs of the dropped runs only", func(t *testing.T) {
+		storageDir := t.TempDir()
+		reg := newTestRegistry(t, storageDir)
+
+		shared := []byte("shared layer")
+		oldImage, oldBlobs := putTestImage(t, reg, "ocp/release", "old", shared, []byte("old layer"))
+		newImage, newBlobs := putTestImage(t, reg, "ocp/release", "new", shared, []byte("new layer"))
+
+		assert.NoError(t, RecordRun(storageDir, []Image{oldImage}))
+		time.Sleep(time.Millisecond)
+		assert.NoError(t, RecordRun(storageDir, []Image{newImage}))
+
+		stats, err := Prune(ctx, storageDir, Policy{KeepRuns: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, stats.RunsKept)
+		assert.Equal(t, 1, stats.RunsDropped)
+		assert.Equal(t, 1, stats.ManifestsRemoved)
+		// the old manifest, its config and its own layer
+		assert.Equal(t, 3, stats.BlobsRemoved)
+		assert.Greater(t, stats.BytesFreed, int64(0))
+
+		reg = newTestRegistry(t, storageDir)
+		for _, dgst := range newBlobs {
+			_, err := reg.BlobStatter().Stat(ctx, dgst)
+			assert.NoError(t, err, "blob %s of the kept run should remain", dgst)
+		}
+		// the layer shared with the new image is kept
+		for _, dgst := range []digest.Digest{oldBlobs[0], oldBlobs[1], oldBlobs[3]} {
+			_, err := reg.BlobStatter().Stat(ctx, dgst)
+			assert.ErrorIs(t, err, distribution.ErrBlobUnknown, "blob %s of the dropped run should be removed", dgst)
+		}
+
+		runs, err := readRuns(storageDir)
+		assert.NoError(t, err)
+		assert.Len(t, runs, 1)
+		assert.Equal(t, []Image{newImage}, runs[0].Images)
+	})
+
+	t.Run("Testing Prune : should fail when no run is recorded", func(t *testing.T) {
+		_, err := Prune(ctx, t.TempDir(), Policy{KeepRuns: 1})
+		assert.ErrorIs(t, err, ErrNoRuns)
+	})
+}
+
+func TestKeptRuns(t *testing.T) {
+	now := time.Now()
+	runs := []Run{
+		{Timestamp: now.Add(-time.Hour)},
+		{Timestamp: now.Add(-48 * time.Hour)},
+		{Timestamp: now.Add(-72 * time.Hour)},
+		{Timestamp: now.Add(-96 * time.Hour)},
+	}
+
+	type testCase struct {
+		caseName string
+		policy   Policy
+		expected int
+	}
+	testCases := []testCase{
+		{caseName: "Testing keptRuns : no limit should keep every run", policy: Policy{}, expected: 4},
+		{caseName: "Testing keptRuns : should keep the most recent runs", policy: Policy{KeepRuns: 2}, expected: 2},
+		{caseName: "Testing keptRuns : should drop the runs older than MaxAge", policy: Policy{MaxAge: 50 * time.Hour}, expected: 2},
+		{caseName: "Testing keptRuns : should always keep the most recent run", policy: Policy{MaxAge: time.Minute}, expected: 1},
+		{caseName: "Testing keptRuns : should apply the strictest limit", policy: Policy{KeepRuns: 3, MaxAge: 50 * time.Hour}, expected: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.policy.keptRuns(runs, now))
+		})
+	}
+}
+
+func TestParseImage(t *testing.T) {
+	t.Run("Testing ParseImage : should parse tags and digests", func(t *testing.T) {
+		img, err := ParseImage("localhost:55000/ocp/release:4.14.1")
+		assert.NoError(t, err)
+		assert.Equal(t, Image{Repository: "ocp/release", Tag: "4.14.1"}, img)
+
+		dgst := "sha256:4b0e3c2e4fe7b4d1b1e4ec8d2f7c6ae3e7c1f5e0a4d9f3c2b1a0e9d8c7b6a5f4"
+		img, err = ParseImage("localhost:55000/ocp/release@" + dgst)
+		assert.NoError(t, err)
+		assert.Equal(t, Image{Repository: "ocp/release", Digest: dgst}, img)
+	})
+	t.Run("Testing ParseImage : should fail without tag nor digest", func(t *testing.T) {
+		_, err := ParseImage("localhost:55000/ocp/release")
+		assert.Error(t, err)
+	})
+}
+
+func newTestRegistry(t *testing.T, storageDir string) distribution.Namespace {
+	storageDriver := filesystem.New(filesystem.DriverParameters{RootDirectory: storageDir, MaxThreads: 100})
+	reg, err := storage.NewRegistry(context.Background(), storageDriver, storage.EnableDelete)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+// putTestImage - pushes an OCI image made of layers to the registry, and returns
+// its cache image and its blobs (manifest, config and layers)
+func putTestImage(t *testing.T, reg distribution.Namespace, repoName, tag string, layers ...[]byte) (Image, []digest.Digest) {
+	ctx := context.Background()
+	named, err := reference.WithName(repoName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := reg.Repository(ctx, named)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs := repo.Blobs(ctx)
+	builder := ocischema.NewManifestBuilder(blobs, []byte(`{"architecture":"amd64","os":"linux","tag":"`+tag+`"}`), nil)
+	var layerDigests []digest.Digest
+	for _, layer := range layers {
+		desc, err := blobs.Put(ctx, v1.MediaTypeImageLayer, layer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := builder.AppendReference(desc); err != nil {
+			t.Fatal(err)
+		}
+		layerDigests = append(layerDigests, desc.Digest)
+	}
+	manifest, err := builder.Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dgst, err := manifests.Put(ctx, manifest, distribution.WithTag(tag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Tags(ctx).Tag(ctx, tag, distribution.Descriptor{Digest: dgst}); err != nil {
+		t.Fatal(err)
+	}
+	// the config is the first reference of an OCI manifest
+	config := manifest.References()[0].Digest
+	return Image{Repository: repoName, Tag: tag}, append([]digest.Digest{dgst, config}, layerDigests...)
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+)
+
+const (
+	// runsDir - directory of the local cache where the mirroring runs are recorded
+	runsDir       = "runs"
+	runFilePrefix = "run-"
+	runFileSuffix = ".json"
+)
+
+// Image - an image of the local cache, as recorded for a mirroring run
+type Image struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+}
+
+// Run - the images of the local cache used by a mirroring run
+type Run struct {
+	Timestamp time.Time `json:"timestamp"`
+	Images    []Image   `json:"images"`
+
+	file string // the record of the run
+}
+
+// ParseImage - returns the cache image of ref, a reference to the local cache
+// registry (host:port/repository[:tag][@digest])
+func ParseImage(ref string) (Image, error) {
+	named, err := reference.ParseNamed(ref)
+	if err != nil {
+		return Image{}, err
+	}
+	img := Image{Repository: reference.Path(named)}
+	if tagged, ok := named.(reference.Tagged); ok {
+		img.Tag = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		img.Digest = digested.Digest().String()
+	}
+	if img.Tag == "" && img.Digest == "" {
+		return Image{}, fmt.Errorf("image %s has neither tag nor digest", ref)
+	}
+	return img, nil
+}
+
+// RecordRun - records the images of the local cache rooted at storageDir used
+// by a mirroring run, so that Prune keeps them
+func RecordRun(storageDir string, images []Image) error {
+	dir := filepath.Join(storageDir, runsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	run := Run{Timestamp: time.Now().UTC(), Images: images}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := runFilePrefix + run.Timestamp.Format("20060102T150405.000000000Z") + runFileSuffix
+	return os.WriteFile(filepath.Join(dir, name), data, 0644)
+}
+
+// readRuns - returns the runs recorded in the local cache rooted at storageDir,
+// most recent first
+func readRuns(storageDir string) ([]Run, error) {
+	dir := filepath.Join(storageDir, runsDir)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var runs []Run
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), runFilePrefix) || !strings.HasSuffix(entry.Name(), runFileSuffix) {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		run := Run{file: file}
+		if err := json.Unmarshal(data, &run); err != nil {
+			return nil, fmt.Errorf("reading run record %s: %w", entry.Name(), err)
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Timestamp.After(runs[j].Timestamp)
+	})
+	return runs, nil
+}
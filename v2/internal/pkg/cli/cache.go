@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/cache"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/progress"
+)
+
+const gbToBytes int64 = 1024 * 1024 * 1024
+
+// CachePruneSchema - the options of the 'cache prune' sub command
+type CachePruneSchema struct {
+	Log      clog.PluggableLoggerInterface
+	CacheDir string
+	KeepRuns int
+	MaxAge   time.Duration
+	MaxSize  int64
+}
+
+// NewCacheCommand - setup the 'cache' sub command and its 'prune' sub command
+func NewCacheCommand(log clog.PluggableLoggerInterface) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manages the local cache of oc-mirror",
+	}
+	cmd.AddCommand(newCachePruneCommand(log))
+	return cmd
+}
+
+func newCachePruneCommand(log clog.PluggableLoggerInterface) *cobra.Command {
+	ex := &CachePruneSchema{Log: log}
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Removes from the local cache the images not used by the most recent mirroring runs",
+		Example: `
+# Keep the images of the 3 most recent mirroring runs
+oc-mirror cache prune --keep-runs 3 --v2
+
+# Keep the images of the mirroring runs of the last 2 weeks, within 100GB
+oc-mirror cache prune --keep-runs 0 --max-age 336h --max-size 100 --v2
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := ex.Validate(); err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			if err := ex.Run(cmd.Context()); err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&ex.CacheDir, "cache-dir", "", "oc-mirror cache directory location. Default is $HOME")
+	cmd.Flags().IntVar(&ex.KeepRuns, "keep-runs", 3, "Number of most recent mirroring runs whose images are kept, 0 disables the limit")
+	cmd.Flags().DurationVar(&ex.MaxAge, "max-age", 0, "Drop the images of the mirroring runs older than this duration (e.g. 720h), 0 disables the limit")
+	cmd.Flags().Int64Var(&ex.MaxSize, "max-size", 0, "Drop the images of the oldest mirroring runs until the cache fits in this size (GB), 0 disables the limit")
+	var v2 bool
+	cmd.Flags().BoolVar(&v2, "v2", false, "Redirect the flow to oc-mirror v2 - This is Tech Preview, it is still under development and it is not production ready.")
+	// nolint: errcheck
+	cmd.Flags().MarkHidden("v2")
+	return cmd
+}
+
+// Validate - checks the prune policy
+func (o *CachePruneSchema) Validate() error {
+	switch {
+	case o.KeepRuns < 0:
+		return fmt.Errorf("--keep-runs must not be negative")
+	case o.MaxAge < 0:
+		return fmt.Errorf("--max-age must not be negative")
+	case o.MaxSize < 0:
+		return fmt.Errorf("--max-size must not be negative")
+	}
+	if !o.policy().Enabled() {
+		return fmt.Errorf("at least one of --keep-runs, --max-age or --max-size must be set")
+	}
+	return nil
+}
+
+// Run - prunes the local cache
+func (o *CachePruneSchema) Run(ctx context.Context) error {
+	cacheDir := o.CacheDir
+	if cacheDir == "" {
+		cacheDir = os.Getenv(cacheEnvVar)
+	}
+	if cacheDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to setup default cache directory: %w", err)
+		}
+		cacheDir = homeDir
+	}
+	return pruneCache(ctx, o.Log, filepath.Join(cacheDir, cacheRelativePath), o.policy())
+}
+
+func (o *CachePruneSchema) policy() cache.Policy {
+	return cache.Policy{KeepRuns: o.KeepRuns, MaxAge: o.MaxAge, MaxSize: o.MaxSize * gbToBytes}
+}
+
+// pruneCache - removes the images of the local cache rooted at storageDir that
+// are not used by the mirroring runs kept by policy
+func pruneCache(ctx context.Context, log clog.PluggableLoggerInterface, storageDir string, policy cache.Policy) error {
+	log.Info(emoji.Broom + " Pruning the local cache...")
+	// the distribution storage logs every deletion
+	logrusOut := logrus.StandardLogger().Out
+	logrus.SetOutput(io.Discard)
+	defer logrus.SetOutput(logrusOut)
+
+	stats, err := cache.Prune(ctx, storageDir, policy)
+	if errors.Is(err, cache.ErrNoRuns) {
+		log.Warn("no mirroring run recorded in the cache %s, nothing pruned", storageDir)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to prune the cache: %v", err)
+	}
+	log.Info(emoji.Broom+" Pruned the cache: %d runs kept, %d runs dropped, %d manifests and %d blobs removed, %s freed, %s kept",
+		stats.RunsKept, stats.RunsDropped, stats.ManifestsRemoved, stats.BlobsRemoved, progress.FormatBytes(stats.BytesFreed), progress.FormatBytes(stats.BytesKept))
+	return nil
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/cache"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func TestCachePruneValidate(t *testing.T) {
+	type testCase struct {
+		caseName string
+		opts     CachePruneSchema
+		expError string
+	}
+	testCases := []testCase{
+		{caseName: "Testing Validate : keep runs should pass", opts: CachePruneSchema{KeepRuns: 3}},
+		{caseName: "Testing Validate : max age and max size should pass", opts: CachePruneSchema{MaxAge: time.Hour, MaxSize: 10}},
+		{caseName: "Testing Validate : no limit should fail", opts: CachePruneSchema{}, expError: "at least one of --keep-runs, --max-age or --max-size must be set"},
+		{caseName: "Testing Validate : negative keep runs should fail", opts: CachePruneSchema{KeepRuns: -1}, expError: "--keep-runs must not be negative"},
+		{caseName: "Testing Validate : negative max size should fail", opts: CachePruneSchema{KeepRuns: 1, MaxSize: -1}, expError: "--max-size must not be negative"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.expError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expError)
+			}
+		})
+	}
+}
+
+func TestRecordCacheRun(t *testing.T) {
+	t.Run("Testing recordCacheRun : should record the images of the local cache only", func(t *testing.T) {
+		storageDir := t.TempDir()
+		ex := &ExecutorSchema{
+			Log:              clog.New("trace"),
+			Opts:             &mirror.CopyOptions{LocalStorageFQDN: "localhost:55000"},
+			LocalStorageDisk: storageDir,
+		}
+		ex.recordCacheRun([]v2alpha1.CopyImageSchema{
+			{Source: "docker://quay.io/ocp/release:4.14.1", Destination: "docker://localhost:55000/ocp/release:4.14.1"},
+			{Source: "docker://localhost:55000/ubi8/ubi@sha256:4b0e3c2e4fe7b4d1b1e4ec8d2f7c6ae3e7c1f5e0a4d9f3c2b1a0e9d8c7b6a5f4", Destination: "docker://mirror.example.com/ubi8/ubi"},
+			{Source: "docker://quay.io/other/image:latest", Destination: "docker://mirror.example.com/other/image:latest"},
+		})
+
+		files, err := filepath.Glob(filepath.Join(storageDir, "runs", "run-*.json"))
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+		data, err := os.ReadFile(files[0])
+		assert.NoError(t, err)
+		var run cache.Run
+		assert.NoError(t, json.Unmarshal(data, &run))
+		assert.Equal(t, []cache.Image{
+			{Repository: "ocp/release", Tag: "4.14.1"},
+			{Repository: "ubi8/ubi", Digest: "sha256:4b0e3c2e4fe7b4d1b1e4ec8d2f7c6ae3e7c1f5e0a4d9f3c2b1a0e9d8c7b6a5f4"},
+		}, run.Images)
+	})
+}
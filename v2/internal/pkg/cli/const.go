@@ -27,6 +27,7 @@ const (
 	helmDir                       string = "helm"
 	helmChartDir                  string = "charts"
 	helmIndexesDir                string = "indexes"
+	imageScanDir                  string = "image-scan"
 	maxParallelLayerDownloads     uint   = 10
 	limitOverallParallelDownloads uint   = 200
 )
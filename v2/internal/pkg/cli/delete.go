@@ -112,7 +112,7 @@ func NewDeleteCommand(log clog.PluggableLoggerInterface) *cobra.Command {
 	cmd.PersistentFlags().StringVar(&opts.Global.CacheDir, "cache-dir", "", "oc-mirror cache directory location. Default is $HOME")
 	cmd.Flags().StringVar(&opts.Global.LogLevel, "loglevel", "info", "Log level one of (info, debug, trace, error)")
 	cmd.Flags().StringVar(&opts.Global.DeleteID, "delete-id", "", "Used to differentiate between versions for files created by the delete functionality")
-	cmd.Flags().StringVar(&opts.Global.DeleteYaml, "delete-yaml-file", "", "If set will use the generated or updated yaml file to delete contents")
+	cmd.Flags().StringVar(&opts.Global.DeleteYaml, "delete-yaml-file", "", "If set will use the yaml file generated with --generate to delete contents, it must be used with the workspace it was generated in (--workspace, defaults to the workspace of the delete folder of the file)")
 	cmd.Flags().BoolVar(&opts.Global.ForceCacheDelete, "force-cache-delete", false, "Used to force delete  the local cache manifests and blobs")
 	cmd.Flags().Uint16VarP(&opts.Global.Port, "port", "p", 55000, "HTTP port used by oc-mirror's local storage instance")
 	cmd.Flags().BoolVar(&opts.Global.V2, "v2", ex.Opts.Global.V2, "Redirect the flow to oc-mirror v2 - This is Tech Preview, it is still under development and it is not production ready.")
@@ -150,6 +150,9 @@ func (o DeleteSchema) ValidateDelete(args []string) error {
 		if len(o.Opts.Global.DeleteYaml) == 0 {
 			return fmt.Errorf("the --delete-yaml-file flag is mandatory when not using the --generate flag")
 		}
+		if len(o.Opts.Global.WorkingDir) > 0 && !strings.Contains(o.Opts.Global.WorkingDir, fileProtocol) {
+			return fmt.Errorf("--workspace flag must have a file:// protocol prefix")
+		}
 	}
 	if o.V1Tags && !o.Opts.Global.DeleteGenerate {
 		return fmt.Errorf("the --delete-v1-images flag can only be used alongside the --generate flag")
@@ -223,7 +226,8 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 
 	// logic to check mode and  WorkingDir
 	// always good to check - but this should have been detected in validate
-	if o.Opts.Global.DeleteGenerate {
+	// the workspace is optional without --generate, it then holds the delete signing key
+	if o.Opts.Global.DeleteGenerate || len(o.Opts.Global.WorkingDir) > 0 {
 		if strings.Contains(o.Opts.Global.WorkingDir, fileProtocol) {
 			wd := strings.Split(o.Opts.Global.WorkingDir, fileProtocol)
 			o.Opts.Global.WorkingDir = filepath.Join(wd[1], workingDir)
@@ -299,7 +303,7 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 	}
 	// instantiate delete module
 	bg := archive.NewImageBlobGatherer(o.Opts)
-	o.Delete = delete.New(o.Log, *o.Opts, o.Batch, bg, o.Config, o.Manifest, o.LocalStorageDisk)
+	o.Delete = delete.New(o.Log, *o.Opts, o.Batch, bg, o.Config, o.Manifest, o.LocalStorageDisk, o.ParallelImages)
 
 	return nil
 }
@@ -319,7 +323,7 @@ func (o *DeleteSchema) RunDelete(cmd *cobra.Command) error {
 			return err
 		}
 
-		err = o.Delete.WriteDeleteMetaData(collectorSchema.AllImages)
+		err = o.Delete.WriteDeleteMetaData(cmd.Context(), collectorSchema.AllImages)
 		if err != nil {
 			return err
 		}
@@ -330,7 +334,7 @@ func (o *DeleteSchema) RunDelete(cmd *cobra.Command) error {
 			return err
 		}
 
-		err = o.Delete.DeleteRegistryImages(deleteList)
+		err = o.Delete.DeleteRegistryImages(cmd.Context(), deleteList)
 		if err != nil {
 			return err
 		}
@@ -10,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"slices"
@@ -33,6 +34,8 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/archive"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/batch"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/blobstore"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/cache"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/clusterresources"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/customsort"
@@ -45,7 +48,9 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/progress"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/scan"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/spinners"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/version"
 	"github.com/spf13/cobra"
@@ -201,7 +206,7 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 			}
 			err = ex.Complete(args)
 			if err != nil {
-				log.Error(" %v ", err)
+				ex.Log.Error(" %v ", err)
 				os.Exit(1)
 			}
 			defer ex.logFile.Close()
@@ -210,22 +215,25 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 			// prepare internal storage
 			err = ex.setupLocalStorage()
 			if err != nil {
-				log.Error(" %v ", err)
+				ex.Log.Error(" %v ", err)
 				os.Exit(1)
 			}
 
 			err = ex.Run(cmd, args)
 			if err != nil {
-				log.Error("%v ", err)
+				ex.Log.Error("%v ", err)
 				os.Exit(1)
 			}
 		},
 	}
 	cmd.AddCommand(version.NewVersionCommand(log))
 	cmd.AddCommand(NewDeleteCommand(log))
+	cmd.AddCommand(NewCacheCommand(log))
+	cmd.AddCommand(NewPlanCommand(log))
 	cmd.PersistentFlags().StringVarP(&opts.Global.ConfigPath, "config", "c", "", "Path to imageset configuration file")
 	cmd.PersistentFlags().StringVar(&opts.Global.CacheDir, "cache-dir", "", "oc-mirror cache directory location. Default is $HOME")
 	cmd.Flags().StringVar(&opts.Global.LogLevel, "log-level", "info", "Log level one of (info, debug, trace, error)")
+	cmd.Flags().StringVar(&opts.Global.LogFormat, "log-format", clog.TextFormat, "Log format one of (text, json). json emits one structured record per line, including an event per mirrored image")
 	cmd.Flags().StringVar(&opts.Global.WorkingDir, "workspace", "", "oc-mirror workspace where resources and internal artifacts are generated")
 	cmd.Flags().StringVar(&opts.Global.From, "from", "", "Local storage directory for disk to mirror workflow")
 	cmd.Flags().Uint16VarP(&opts.Global.Port, "port", "p", 55000, "HTTP port used by oc-mirror's local storage instance")
@@ -240,6 +248,13 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 	cmd.Flags().DurationVar(&opts.Global.CommandTimeout, "image-timeout", 10*time.Minute, "Timeout for mirroring an image. Defaults to 10mn")
 	cmd.Flags().UintVar(&ex.ParallelImageLayers, "parallel-layers", 10, "Indicates the number of image layers mirrored in parallel. Defaults to 10")
 	cmd.Flags().UintVar(&ex.ParallelImages, "parallel-images", 8, "Indicates the number of images mirrored in parallel. Defaults to 8")
+	cmd.Flags().BoolVar(&opts.Global.ContinueOnError, "continue-on-error", false, "Keep going when images fail to be collected or mirrored: the failures are recorded in a JSON report of the logs directory, and the archive holds the images that succeeded")
+	cmd.Flags().BoolVar(&opts.Global.CompactCache, "compact-cache", false, "Store each blob of the cache and the workspace once, using hardlinks. Requires the cache and the workspace to be on the same filesystem")
+	cmd.Flags().IntVar(&opts.Global.CacheKeepRuns, "cache-keep-runs", 0, "After mirroring, prune the cache down to the images of this number of most recent mirroring runs. 0 disables the limit")
+	cmd.Flags().DurationVar(&opts.Global.CacheMaxAge, "cache-max-age", 0, "After mirroring, prune from the cache the images of the mirroring runs older than this duration (e.g. 720h). 0 disables the limit")
+	cmd.Flags().Int64Var(&opts.Global.CacheMaxSize, "cache-max-size", 0, "After mirroring, prune from the cache the images of the oldest mirroring runs until it fits in this size (GB). 0 disables the limit")
+	cmd.Flags().StringVar(&opts.Global.ImageScanHook, "image-scan-hook", "", "Executable run on each image mirrored to disk before the archive is built, with the OCI layout of the image and its reference as arguments. "+
+		"Exiting with 1 leaves the image out of the archive, any other non-zero exit code fails the mirroring")
 	cmd.Flags().StringVar(&opts.RootlessStoragePath, "rootless-storage-path", "", "Override the default container rootless storage path (usually in etc/containers/storage.conf)")
 	// nolint: errcheck
 	cmd.Flags().AddFlagSet(&flagSharedOpts)
@@ -349,6 +364,17 @@ func (o ExecutorSchema) Validate(dest []string) error {
 	if !slices.Contains([]string{"info", "debug", "trace", "error"}, o.Opts.Global.LogLevel) {
 		return fmt.Errorf("log-level has an invalid value %s , it should be one of (info,debug,trace, error)", o.Opts.Global.LogLevel)
 	}
+	if o.Opts.Global.LogFormat != "" && !slices.Contains([]string{clog.TextFormat, clog.JSONFormat}, o.Opts.Global.LogFormat) {
+		return fmt.Errorf("log-format has an invalid value %s , it should be one of (text, json)", o.Opts.Global.LogFormat)
+	}
+	if o.Opts.Global.ImageScanHook != "" {
+		if !strings.Contains(dest[0], fileProtocol) || o.Opts.Global.From != "" {
+			return fmt.Errorf("--image-scan-hook is only supported when mirroring to disk")
+		}
+		if _, err := exec.LookPath(o.Opts.Global.ImageScanHook); err != nil {
+			return fmt.Errorf("--image-scan-hook: %w", err)
+		}
+	}
 	if os.Getenv(cacheEnvVar) != "" && o.Opts.Global.CacheDir != "" {
 		return fmt.Errorf("either OC_MIRROR_CACHE or --cache-dir can be used but not both")
 	}
@@ -377,7 +403,6 @@ func (o *ExecutorSchema) Complete(args []string) error {
 	// update all dependant modules
 	mc := mirror.NewMirrorCopy()
 	md := mirror.NewMirrorDelete()
-	o.Manifest = manifest.New(o.Log)
 	o.Mirror = mirror.New(mc, md)
 	o.Config = cfg.(v2alpha1.ImageSetConfiguration)
 
@@ -420,6 +445,7 @@ func (o *ExecutorSchema) Complete(args []string) error {
 	if err != nil {
 		return err
 	}
+	o.Manifest = manifest.New(o.Log)
 
 	o.Log.Info(emoji.TwistedRighwardsArrows+" workflow mode: %s ", o.Opts.Mode)
 
@@ -433,6 +459,11 @@ func (o *ExecutorSchema) Complete(args []string) error {
 
 	// make sure we always get multi-arch images
 	o.Opts.MultiArch = "all"
+	// unless the imageset restricts the architectures of the operator and additional images
+	o.Opts.Architectures = o.Config.Mirror.Architectures
+	if len(o.Opts.Architectures) > 0 {
+		o.Log.Warn("operator and additional images are mirrored for architectures %s only: their manifest lists are kept sparse, referencing the instances of other architectures that are not mirrored", strings.Join(o.Opts.Architectures, ", "))
+	}
 	// for the moment, mirroring doesn't verify signatures. Expected in CLID-26
 	o.Opts.RemoveSignatures = true
 
@@ -516,6 +547,13 @@ func (o *ExecutorSchema) Run(cmd *cobra.Command, args []string) error {
 		err = o.RunMirrorToMirror(cmd, args)
 	}
 
+	if err == nil && o.Opts.Global.CompactCache && !o.Opts.IsDryRun {
+		err = o.compactCache()
+	}
+	if policy := o.cachePolicy(); err == nil && policy.Enabled() && !o.Opts.IsDryRun {
+		err = pruneCache(cmd.Context(), o.Log, o.LocalStorageDisk, policy)
+	}
+
 	o.Log.Info(emoji.WavingHandSign + " Goodbye, thank you for using oc-mirror")
 
 	if err != nil {
@@ -556,6 +594,17 @@ http:
       #htpasswd:
       #realm: basic-realm
       #path: /etc/registry
+{{- if .Architectures }}
+validation:
+  manifests:
+    indexes:
+      platforms: list
+      platformlist:
+{{- range .Architectures }}
+        - architecture: {{ . }}
+          os: linux
+{{- end }}
+{{- end }}
 `
 
 	var buff bytes.Buffer
@@ -564,6 +613,10 @@ http:
 		LocalStoragePort int
 		LogLevel         string
 		LogAccessOff     bool
+		// Architectures are the only architectures whose images must be in the
+		// cache when a manifest list is pushed: the manifest lists filtered by
+		// mirror.architectures reference instances that are never copied
+		Architectures []string
 	}
 
 	rc := RegistryConfig{
@@ -571,6 +624,7 @@ http:
 		LocalStoragePort: int(o.Opts.Global.Port),
 		LogLevel:         o.Opts.Global.LogLevel,
 		LogAccessOff:     true,
+		Architectures:    o.Opts.Architectures,
 	}
 
 	if o.Opts.Global.LogLevel == "debug" || o.Opts.Global.LogLevel == "trace" {
@@ -801,6 +855,14 @@ func (o *ExecutorSchema) RunMirrorToDisk(cmd *cobra.Command, args []string) erro
 				return err
 			}
 		}
+		o.recordCacheRun(copiedSchema.AllImages)
+
+		if o.Opts.Global.ImageScanHook != "" {
+			copiedSchema.AllImages, err = o.scanImages(cmd.Context(), copiedSchema.AllImages)
+			if err != nil {
+				return err
+			}
+		}
 
 		// prepare tar.gz when mirror to disk
 		// first stop the registry
@@ -872,6 +934,7 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 		} else {
 			copiedSchema = cs
 		}
+		o.recordCacheRun(copiedSchema.AllImages)
 
 		//create IDMS/ITMS
 		forceRepositoryScope := o.Opts.Global.MaxNestedPaths > 0
@@ -973,6 +1036,7 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 		} else {
 			copiedSchema = cs
 		}
+		o.recordCacheRun(copiedSchema.AllImages)
 
 		// create IDMS/ITMS
 		forceRepositoryScope := o.Opts.Global.MaxNestedPaths > 0
@@ -1057,6 +1121,9 @@ func (o *ExecutorSchema) setupLogsLevelAndDir() error {
 	o.logFile = l
 	mw := io.MultiWriter(os.Stdout, o.logFile)
 	log.SetOutput(mw)
+	if o.Opts.Global.LogFormat == clog.JSONFormat {
+		o.Log = clog.NewJSON(o.Opts.Global.LogLevel, mw)
+	}
 	return nil
 }
 
@@ -1067,14 +1134,37 @@ func (o *ExecutorSchema) CollectAll(ctx context.Context) (v2alpha1.CollectorSche
 
 	var collectorSchema v2alpha1.CollectorSchema
 	var allRelatedImages []v2alpha1.CopyImageSchema
+	var failures []batch.Failure
+
+	// collectorFailed - the collectors return the images they collected along
+	// with the errors of the items they could not collect. With --continue-on-error,
+	// records these failures and carries on with the collected images,
+	// otherwise aborts the collection
+	collectorFailed := func(imageType v2alpha1.ImageType, err error) error {
+		if !o.Opts.Global.ContinueOnError {
+			o.closeAll()
+			return err
+		}
+		o.Log.Warn(collecAllPrefix+"unable to collect some %s images, continuing without them: %v", imageType, err)
+		timestamp := time.Now().UTC()
+		collErrs, others := image.SplitCollectionErrors(err)
+		for _, other := range others {
+			failures = append(failures, batch.Failure{Type: imageType.String(), Error: other.Error(), Timestamp: timestamp})
+		}
+		for _, collErr := range collErrs {
+			failures = append(failures, batch.Failure{Image: collErr.Image, Type: imageType.String(), Error: collErr.Error(), Timestamp: timestamp})
+		}
+		return nil
+	}
 
 	o.Log.Info(emoji.SleuthOrSpy + "  going to discover the necessary images...")
 	o.Log.Info(emoji.LeftPointingMagnifyingGlass + " collecting release images...")
 	// collect releases
 	releaseImgs, err := o.Release.ReleaseImageCollector(ctx)
 	if err != nil {
-		o.closeAll()
-		return v2alpha1.CollectorSchema{}, err
+		if err := collectorFailed(v2alpha1.TypeOCPRelease, err); err != nil {
+			return v2alpha1.CollectorSchema{}, err
+		}
 	}
 	// exclude blocked images
 	releaseImgs = excludeImages(releaseImgs, o.Config.Mirror.BlockedImages)
@@ -1087,8 +1177,9 @@ func (o *ExecutorSchema) CollectAll(ctx context.Context) (v2alpha1.CollectorSche
 	// collect operators
 	operatorImgs, err := o.Operator.OperatorImageCollector(ctx)
 	if err != nil {
-		o.closeAll()
-		return v2alpha1.CollectorSchema{}, err
+		if err := collectorFailed(v2alpha1.TypeOperatorCatalog, err); err != nil {
+			return v2alpha1.CollectorSchema{}, err
+		}
 	}
 	oImgs := operatorImgs.AllImages
 	// exclude blocked images
@@ -1103,8 +1194,9 @@ func (o *ExecutorSchema) CollectAll(ctx context.Context) (v2alpha1.CollectorSche
 	// collect additionalImages
 	aImgs, err := o.AdditionalImages.AdditionalImagesCollector(ctx)
 	if err != nil {
-		o.closeAll()
-		return v2alpha1.CollectorSchema{}, err
+		if err := collectorFailed(v2alpha1.TypeGeneric, err); err != nil {
+			return v2alpha1.CollectorSchema{}, err
+		}
 	}
 	// exclude blocked images
 	aImgs = excludeImages(aImgs, o.Config.Mirror.BlockedImages)
@@ -1115,8 +1207,9 @@ func (o *ExecutorSchema) CollectAll(ctx context.Context) (v2alpha1.CollectorSche
 	o.Log.Info(emoji.LeftPointingMagnifyingGlass + " collecting helm images...")
 	hImgs, err := o.HelmCollector.HelmImageCollector(ctx)
 	if err != nil {
-		o.closeAll()
-		return v2alpha1.CollectorSchema{}, err
+		if err := collectorFailed(v2alpha1.TypeHelmImage, err); err != nil {
+			return v2alpha1.CollectorSchema{}, err
+		}
 	}
 	// exclude blocked images
 	hImgs = excludeImages(hImgs, o.Config.Mirror.BlockedImages)
@@ -1137,6 +1230,15 @@ func (o *ExecutorSchema) CollectAll(ctx context.Context) (v2alpha1.CollectorSche
 
 	collectorSchema.AllImages = allRelatedImages
 
+	if len(failures) > 0 {
+		filename, err := batch.SaveFailureReport(o.LogsDir, "collection_errors", failures)
+		if err != nil {
+			o.Log.Warn(collecAllPrefix+"unable to write the failure report %s: %v", filename, err)
+		} else {
+			o.Log.Warn(collecAllPrefix+"%d images or collectors failed, see %s", len(failures), filepath.Join(o.LogsDir, filename))
+		}
+	}
+
 	endTime := time.Now()
 	execTime := endTime.Sub(startTime)
 	o.Log.Debug("collection time     : %v", execTime)
@@ -1206,6 +1308,80 @@ func (o *ExecutorSchema) RebuildCatalogs(ctx context.Context, operatorImgs v2alp
 
 }
 
+// compactCache - hardlinks the blobs of the OCI layouts of the workspace
+// (catalogs, releases) to the blob store of the local cache, so that content
+// present in both is stored once on disk
+func (o *ExecutorSchema) compactCache() error {
+	stats, err := blobstore.New(o.LocalStorageDisk).Compact(o.Opts.Global.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("unable to compact the cache: %v", err)
+	}
+	o.Log.Info(emoji.Package+" Compacted the cache: %d blobs hardlinked, %s reclaimed", stats.Linked, progress.FormatBytes(stats.Saved))
+	if stats.Skipped > 0 {
+		o.Log.Warn("%d blobs could not be hardlinked to the cache, check that the cache and the workspace are on the same filesystem", stats.Skipped)
+	}
+	return nil
+}
+
+// cachePolicy - the automatic garbage collection policy of the local cache
+func (o *ExecutorSchema) cachePolicy() cache.Policy {
+	return cache.Policy{
+		KeepRuns: o.Opts.Global.CacheKeepRuns,
+		MaxAge:   o.Opts.Global.CacheMaxAge,
+		MaxSize:  o.Opts.Global.CacheMaxSize * gbToBytes,
+	}
+}
+
+// recordCacheRun - records the images of the local cache used by this run,
+// so that pruning the cache keeps them
+func (o *ExecutorSchema) recordCacheRun(images []v2alpha1.CopyImageSchema) {
+	cachePrefix := dockerProtocol + o.Opts.LocalStorageFQDN + "/"
+	var cached []cache.Image
+	for _, img := range images {
+		for _, ref := range []string{img.Source, img.Destination} {
+			if !strings.HasPrefix(ref, cachePrefix) {
+				continue
+			}
+			cacheImg, err := cache.ParseImage(strings.TrimPrefix(ref, dockerProtocol))
+			if err != nil {
+				o.Log.Debug("not recording %s in the cache run: %v", ref, err)
+				continue
+			}
+			cached = append(cached, cacheImg)
+		}
+	}
+	if err := cache.RecordRun(o.LocalStorageDisk, cached); err != nil {
+		o.Log.Warn("unable to record the images of this run in the cache, they may be pruned: %v", err)
+	}
+}
+
+// scanImages - runs the image scan hook on each image of the cache, and returns
+// the images it did not veto. The vetoes are recorded in a JSON report of the
+// logs directory.
+func (o *ExecutorSchema) scanImages(ctx context.Context, images []v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, error) {
+	o.Log.Info(emoji.LeftPointingMagnifyingGlass+" Running the image scan hook on %d images...", len(images))
+	scanner := scan.Scanner{
+		Log:     o.Log,
+		Mirror:  o.Mirror,
+		Opts:    *o.Opts,
+		Hook:    scan.ExecHook{Command: o.Opts.Global.ImageScanHook},
+		WorkDir: filepath.Join(o.Opts.Global.WorkingDir, imageScanDir),
+	}
+	kept, vetoes, err := scanner.Scan(ctx, images)
+	if err != nil {
+		return nil, err
+	}
+	if len(vetoes) > 0 {
+		filename, err := scan.SaveVetoReport(o.LogsDir, vetoes)
+		if err != nil {
+			o.Log.Warn("unable to write the image scan veto report %s: %v", filename, err)
+		} else {
+			o.Log.Warn("%d images vetoed by the image scan hook are left out of the archive, see %s", len(vetoes), filepath.Join(o.LogsDir, filename))
+		}
+	}
+	return kept, nil
+}
+
 // closeAll - utility to close any open files
 func (o *ExecutorSchema) closeAll() {
 	// close registry log file
@@ -3,26 +3,41 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	ggcrregistry "github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/otiai10/copy"
 
 	"github.com/distribution/distribution/v3/configuration"
 	"github.com/distribution/distribution/v3/registry"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/archive"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/batch"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // TestExecutorMirroring - test both mirrorToDisk
@@ -110,6 +125,7 @@ func TestExecutorMirroring(t *testing.T) {
 			localStorageInterruptChannel: fakeStorageInterruptChan,
 			MakeDir:                      MakeDir{},
 			LogsDir:                      "/tmp/",
+			LocalStorageDisk:             testFolder,
 			ClusterResources:             cr,
 		}
 
@@ -146,6 +162,7 @@ func TestExecutorMirroring(t *testing.T) {
 			localStorageInterruptChannel: fakeStorageInterruptChan,
 			MakeDir:                      MakeDir{},
 			LogsDir:                      "/tmp/",
+			LocalStorageDisk:             testFolder,
 			ClusterResources:             cr,
 		}
 
@@ -185,6 +202,7 @@ func TestExecutorMirroring(t *testing.T) {
 			ClusterResources:             cr,
 			MakeDir:                      MakeDir{},
 			LogsDir:                      "/tmp/",
+			LocalStorageDisk:             testFolder,
 		}
 
 		res := &cobra.Command{}
@@ -222,6 +240,7 @@ func TestExecutorMirroring(t *testing.T) {
 			ClusterResources:             cr,
 			MakeDir:                      MakeDir{},
 			LogsDir:                      "/tmp/",
+			LocalStorageDisk:             testFolder,
 		}
 
 		res := &cobra.Command{}
@@ -258,6 +277,7 @@ func TestExecutorMirroring(t *testing.T) {
 			ClusterResources:             cr,
 			MakeDir:                      MakeDir{},
 			LogsDir:                      "/tmp/",
+			LocalStorageDisk:             testFolder,
 		}
 
 		res := &cobra.Command{}
@@ -354,6 +374,7 @@ func TestRunMirrorToMirror(t *testing.T) {
 			Batch:               batch,
 			MakeDir:             MakeDir{},
 			LogsDir:             "/tmp/",
+			LocalStorageDisk:    testFolder,
 			ClusterResources:    cr,
 			LocalStorageService: *reg,
 		}
@@ -385,6 +406,7 @@ func TestRunMirrorToMirror(t *testing.T) {
 			Batch:               batch,
 			MakeDir:             MakeDir{},
 			LogsDir:             "/tmp/",
+			LocalStorageDisk:    testFolder,
 			ClusterResources:    cr,
 			LocalStorageService: *reg,
 		}
@@ -417,6 +439,7 @@ func TestRunMirrorToMirror(t *testing.T) {
 			Batch:               batch,
 			MakeDir:             MakeDir{},
 			LogsDir:             "/tmp/",
+			LocalStorageDisk:    testFolder,
 			ClusterResources:    cr,
 			LocalStorageService: *reg,
 		}
@@ -573,6 +596,17 @@ func TestExecutorValidate(t *testing.T) {
 		opts.Global.WorkingDir = "" //reset
 		assert.Equal(t, "when destination is docker://, either --from (assumes disk to mirror workflow) or --workspace (assumes mirror to mirror workflow) need to be provided", ex.Validate([]string{"docker://test"}).Error())
 
+		// the image scan hook runs when mirroring to disk only
+		opts.Global.ImageScanHook = "true"
+		assert.NoError(t, ex.Validate([]string{"file://test"}))
+		opts.Global.WorkingDir = "file://test"
+		assert.Equal(t, "--image-scan-hook is only supported when mirroring to disk", ex.Validate([]string{"docker://test"}).Error())
+
+		// the image scan hook must be an executable
+		opts.Global.WorkingDir = "" //reset
+		opts.Global.ImageScanHook = "/nonexistent/scan-hook"
+		assert.ErrorContains(t, ex.Validate([]string{"file://test"}), "--image-scan-hook:")
+		opts.Global.ImageScanHook = "" //reset
 	})
 }
 
@@ -695,6 +729,100 @@ func TestExecutorSetupLocalStorage(t *testing.T) {
 	})
 }
 
+// TestExecutorLocalStorageArchitectures - the local cache (distribution)
+// registry accepts the manifest lists filtered by mirror.architectures
+func TestExecutorLocalStorageArchitectures(t *testing.T) {
+	ctx := context.Background()
+
+	// source registry holding a manifest list for amd64 and arm64
+	src := httptest.NewServer(ggcrregistry.New())
+	defer src.Close()
+	srcURL, err := url.Parse(src.URL)
+	assert.NoError(t, err)
+	index := mutate.IndexMediaType(empty.Index, ocispec.MediaTypeImageIndex)
+	for _, arch := range []string{"amd64", "arm64"} {
+		img, err := random.Image(1024, 1)
+		assert.NoError(t, err)
+		index = mutate.AppendManifests(index, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: v1.Descriptor{Platform: &v1.Platform{Architecture: arch, OS: "linux"}},
+		})
+	}
+	indexRef, err := name.ParseReference(srcURL.Host + "/test/multi-arch:latest")
+	assert.NoError(t, err)
+	assert.NoError(t, remote.WriteIndex(indexRef, index))
+
+	copyToCache := func(t *testing.T, cacheArchitectures []string) (*mirror.CopyOptions, string, error) {
+		global := &mirror.GlobalOptions{SecurePolicy: false, LogLevel: "info"}
+		_, sharedOpts := mirror.SharedImageFlags()
+		_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+		srcFlags, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+		destFlags, destOpts := mirror.ImageDestFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "dest-", "dcreds")
+		_, retryOpts := mirror.RetryFlags()
+		_ = srcFlags.Set("src-tls-verify", "false")
+		_ = destFlags.Set("dest-tls-verify", "false")
+
+		listener, err := net.Listen("tcp", "localhost:0")
+		assert.NoError(t, err)
+		global.Port = uint16(listener.Addr().(*net.TCPAddr).Port)
+		listener.Close()
+
+		ex := &ExecutorSchema{
+			Log: clog.New("error"),
+			Opts: &mirror.CopyOptions{
+				Global:        global,
+				Architectures: cacheArchitectures,
+			},
+			LocalStorageDisk: t.TempDir(),
+		}
+		config, err := ex.setupLocalRegistryConfig()
+		assert.NoError(t, err)
+		reg, err := registry.NewRegistry(ctx, config)
+		assert.NoError(t, err)
+		go func() { _ = reg.ListenAndServe() }()
+		t.Cleanup(func() { _ = reg.Shutdown(ctx) })
+		cache := fmt.Sprintf("localhost:%d", global.Port)
+		assert.Eventually(t, func() bool {
+			conn, err := net.Dial("tcp", cache)
+			if err == nil {
+				conn.Close()
+			}
+			return err == nil
+		}, 30*time.Second, 50*time.Millisecond)
+
+		opts := &mirror.CopyOptions{
+			Global:              global,
+			DeprecatedTLSVerify: deprecatedTLSVerifyOpt,
+			SrcImage:            srcOpts,
+			DestImage:           destOpts,
+			RetryOpts:           retryOpts,
+			Mode:                mirror.MirrorToDisk,
+			MultiArch:           "all",
+			RemoveSignatures:    true,
+			Architectures:       []string{"amd64"},
+		}
+		dest := "docker://" + cache + "/test/multi-arch:latest"
+		err = mirror.New(mirror.NewMirrorCopy(), mirror.NewMirrorDelete()).Run(ctx, "docker://"+indexRef.String(), dest, "copy", opts)
+		return opts, dest, err
+	}
+
+	t.Run("Testing Executor : local storage should accept manifest lists filtered by architecture", func(t *testing.T) {
+		opts, dest, err := copyToCache(t, []string{"amd64"})
+		assert.NoError(t, err)
+
+		// the blobs of the arm64 instance, not in the cache, are skipped
+		blobs, err := archive.NewImageBlobGatherer(opts).GatherBlobs(ctx, dest)
+		assert.NoError(t, err)
+		// index, amd64 manifest, config and layer
+		assert.Len(t, blobs, 4)
+	})
+
+	t.Run("Testing Executor : local storage should reject sparse manifest lists without architectures", func(t *testing.T) {
+		_, _, err := copyToCache(t, nil)
+		assert.ErrorContains(t, err, "blob unknown")
+	})
+}
+
 // TestExecutorSetupWorkingDir
 func TestExecutorSetupWorkingDir(t *testing.T) {
 	workingDir := t.TempDir()
@@ -841,6 +969,47 @@ func TestExecutorCollectAll(t *testing.T) {
 		assert.Equal(t, "forced error additionalImages collector", err.Error())
 
 	})
+
+	t.Run("Testing Executor : collect all should keep the images of partially failed collectors", func(t *testing.T) {
+		log := clog.New("trace")
+		global := &mirror.GlobalOptions{
+			SecurePolicy:    false,
+			Force:           true,
+			ContinueOnError: true,
+		}
+		opts := &mirror.CopyOptions{
+			Global: global,
+			Mode:   mirror.MirrorToDisk,
+		}
+		cfg, _ := config.ReadConfig(common.TestFolder+"isc.yaml", v2alpha1.ImageSetConfigurationKind)
+		collector := &Collector{Log: log, Config: cfg.(v2alpha1.ImageSetConfiguration), Opts: *opts}
+		partialCollector := &Collector{Log: log, Config: cfg.(v2alpha1.ImageSetConfiguration), Opts: *opts, Partial: true}
+		logsDir := t.TempDir()
+		ex := &ExecutorSchema{
+			Log:              log,
+			LogsDir:          logsDir,
+			Opts:             opts,
+			MakeDir:          MockMakeDir{},
+			Operator:         collector,
+			Release:          collector,
+			AdditionalImages: partialCollector,
+			HelmCollector:    collector,
+		}
+
+		collected, err := ex.CollectAll(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, collected.TotalAdditionalImages)
+
+		reports, err := filepath.Glob(filepath.Join(logsDir, "collection_errors_*.json"))
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		data, err := os.ReadFile(reports[0])
+		require.NoError(t, err)
+		var failures []batch.Failure
+		require.NoError(t, json.Unmarshal(data, &failures))
+		require.Len(t, failures, 1)
+		assert.Equal(t, "registry/name/namespace/sometestimage-b:v1", failures[0].Image)
+	})
 }
 
 func TestExcludeImages(t *testing.T) {
@@ -918,7 +1087,9 @@ type Collector struct {
 	Config v2alpha1.ImageSetConfiguration
 	Opts   mirror.CopyOptions
 	Fail   bool
-	Name   string
+	// Partial - the collector fails to collect one image and returns the others
+	Partial bool
+	Name    string
 }
 
 type Batch struct {
@@ -1053,6 +1224,12 @@ func (o *Collector) AdditionalImagesCollector(ctx context.Context) ([]v2alpha1.C
 	if o.Fail {
 		return []v2alpha1.CopyImageSchema{}, fmt.Errorf("forced error additionalImages collector")
 	}
+	if o.Partial {
+		collected := []v2alpha1.CopyImageSchema{
+			{Source: "docker://registry/name/namespace/sometestimage-a@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", Destination: "oci:test"},
+		}
+		return collected, image.NewCollectionError("registry/name/namespace/sometestimage-b:v1", fmt.Errorf("forced error"))
+	}
 	test := []v2alpha1.CopyImageSchema{
 		{Source: "docker://registry/name/namespace/sometestimage-a@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", Destination: "oci:test"},
 		{Source: "docker://registry/name/namespace/sometestimage-b@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", Destination: "oci:test"},
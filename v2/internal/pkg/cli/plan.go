@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	imgmanifest "github.com/containers/image/v5/manifest"
+	"github.com/containers/image/v5/transports/alltransports"
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+const (
+	planOutputTable = "table"
+	planOutputJSON  = "json"
+)
+
+// PlanSchema - the options of the 'plan' sub command
+type PlanSchema struct {
+	ExecutorSchema
+	Output string
+}
+
+// PlannedImage - an image resolved by the collectors, with the size of its blobs
+type PlannedImage struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Type        string `json:"type"`
+	Size        int64  `json:"size"`
+	Cached      bool   `json:"cached"`
+	Error       string `json:"error,omitempty"`
+}
+
+// PlanReport - the images resolved by the collectors and the estimated sizes of the mirroring.
+// The blobs shared by several images are counted once in the estimated sizes
+type PlanReport struct {
+	Images                []PlannedImage `json:"images"`
+	EstimatedDownloadSize int64          `json:"estimatedDownloadSize"`
+	EstimatedArchiveSize  int64          `json:"estimatedArchiveSize"`
+}
+
+// NewPlanCommand - setup the 'plan' sub command, which runs the collectors
+// of a mirror to disk without copying any image
+func NewPlanCommand(log clog.PluggableLoggerInterface) *cobra.Command {
+	global := &mirror.GlobalOptions{
+		SecurePolicy: false,
+	}
+
+	flagSharedOpts, sharedOpts := mirror.SharedImageFlags()
+	flagDepTLS, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	flagSrcOpts, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+	flagDestOpts, destOpts := mirror.ImageDestFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "dest-", "dcreds")
+	flagRetryOpts, retryOpts := mirror.RetryFlags()
+
+	opts := &mirror.CopyOptions{
+		Global:              global,
+		DeprecatedTLSVerify: deprecatedTLSVerifyOpt,
+		SrcImage:            srcOpts,
+		DestImage:           destOpts,
+		RetryOpts:           retryOpts,
+		IsDryRun:            true,
+		ParallelLayerImages: maxParallelLayerDownloads,
+		Function:            string(mirror.CopyMode),
+	}
+
+	ex := &PlanSchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:     log,
+			Opts:    opts,
+			MakeDir: MakeDir{},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Lists the images a mirror to disk would copy, with their sizes, without copying any image",
+		Example: `
+# List the images of the imageset configuration and the estimated size of the archive
+oc-mirror plan -c ./isc.yaml file:///home/<user>/oc-mirror/mirror1 --v2
+
+# Same, as JSON
+oc-mirror plan -c ./isc.yaml file:///home/<user>/oc-mirror/mirror1 --output json --log-level error --v2
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := ex.ValidatePlan(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			err = ex.Complete(args)
+			if err != nil {
+				log.Error(" %v ", err)
+				os.Exit(1)
+			}
+			defer ex.logFile.Close()
+
+			err = ex.setupLocalStorage()
+			if err != nil {
+				log.Error(" %v ", err)
+				os.Exit(1)
+			}
+
+			err = ex.RunPlan(cmd.Context(), cmd.OutOrStdout())
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&opts.Global.ConfigPath, "config", "c", "", "Path to imageset configuration file")
+	cmd.Flags().StringVar(&opts.Global.CacheDir, "cache-dir", "", "oc-mirror cache directory location. Default is $HOME")
+	cmd.Flags().StringVar(&opts.Global.LogLevel, "log-level", "info", "Log level one of (info, debug, trace, error)")
+	cmd.Flags().Uint16VarP(&opts.Global.Port, "port", "p", 55000, "HTTP port used by oc-mirror's local storage instance")
+	cmd.Flags().StringVar(&opts.Global.SinceString, "since", "", "Include all new content since specified date (format yyyy-MM-dd). When not provided, new content since previous mirroring is planned")
+	cmd.Flags().StringVarP(&ex.Output, "output", "o", planOutputTable, "Output format one of (table, json)")
+	cmd.Flags().BoolVar(&opts.Global.V2, "v2", opts.Global.V2, "Redirect the flow to oc-mirror v2 - This is Tech Preview, it is still under development and it is not production ready.")
+	cmd.Flags().AddFlagSet(&flagSharedOpts)
+	cmd.Flags().AddFlagSet(&flagRetryOpts)
+	cmd.Flags().AddFlagSet(&flagDepTLS)
+	cmd.Flags().AddFlagSet(&flagSrcOpts)
+	cmd.Flags().AddFlagSet(&flagDestOpts)
+	HideFlags(cmd)
+
+	ex.Opts.Stdout = cmd.OutOrStdout()
+
+	return cmd
+}
+
+// ValidatePlan - cobra validation
+func (o PlanSchema) ValidatePlan(args []string) error {
+	if len(args) != 1 || !strings.HasPrefix(args[0], fileProtocol) {
+		return fmt.Errorf("the plan command needs the mirror to disk destination, with a file:// prefix")
+	}
+	if !slices.Contains([]string{planOutputTable, planOutputJSON}, o.Output) {
+		return fmt.Errorf("output has an invalid value %s , it should be one of (table, json)", o.Output)
+	}
+	return o.Validate(args)
+}
+
+// RunPlan - collects the images and writes the plan to out
+func (o *PlanSchema) RunPlan(ctx context.Context, out io.Writer) error {
+	o.Log.Debug(startMessage, o.Opts.Global.Port)
+	go startLocalRegistry(&o.LocalStorageService, o.localStorageInterruptChannel)
+	defer o.closeAll()
+
+	collectorSchema, err := o.CollectAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	o.Log.Info(emoji.LeftPointingMagnifyingGlass+" reading the manifests of %d images...", len(collectorSchema.AllImages))
+	report, err := o.plan(ctx, collectorSchema.AllImages)
+	if err != nil {
+		return err
+	}
+
+	if o.Output == planOutputJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return writePlanTable(out, report)
+}
+
+// plan - reads the size of the blobs of images from their manifests,
+// and checks which images are already in the local cache
+func (o *PlanSchema) plan(ctx context.Context, images []v2alpha1.CopyImageSchema) (PlanReport, error) {
+	sysCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return PlanReport{}, err
+	}
+
+	report := PlanReport{Images: make([]PlannedImage, 0, len(images))}
+	downloadBlobs := map[digest.Digest]int64{}
+	archiveBlobs := map[digest.Digest]int64{}
+	for _, img := range images {
+		planned := PlannedImage{
+			Source:      img.Source,
+			Destination: img.Destination,
+			Type:        img.Type.String(),
+		}
+		exists, err := o.Mirror.Check(ctx, img.Destination, o.Opts, false)
+		if err != nil {
+			o.Log.Debug("unable to check existence of %s in local cache: %v", img.Destination, err)
+		}
+		planned.Cached = err == nil && exists
+
+		blobs, err := imageBlobs(ctx, sysCtx, img.Source)
+		if err != nil {
+			o.Log.Warn("unable to read the manifest of %s: %v", img.Source, err)
+			planned.Error = err.Error()
+		}
+		for d, size := range blobs {
+			planned.Size += size
+			archiveBlobs[d] = size
+			if !planned.Cached {
+				downloadBlobs[d] = size
+			}
+		}
+		report.Images = append(report.Images, planned)
+	}
+	report.EstimatedDownloadSize = sumBlobs(downloadBlobs)
+	report.EstimatedArchiveSize = sumBlobs(archiveBlobs)
+	return report, nil
+}
+
+// imageBlobs - returns the size of the manifests, configs and layers of imgRef, keyed by digest.
+// Every manifest of a manifest list is counted, so the size is an upper bound of the size mirrored
+var imageBlobs = func(ctx context.Context, sysCtx *types.SystemContext, imgRef string) (map[digest.Digest]int64, error) {
+	ref, err := alltransports.ParseImageName(imgRef)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source name %s: %v", imgRef, err)
+	}
+	src, err := ref.NewImageSource(ctx, sysCtx)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	blobs := map[digest.Digest]int64{}
+	if err := addManifestBlobs(ctx, src, nil, blobs); err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+func addManifestBlobs(ctx context.Context, src types.ImageSource, instance *digest.Digest, blobs map[digest.Digest]int64) error {
+	raw, mimeType, err := src.GetManifest(ctx, instance)
+	if err != nil {
+		return err
+	}
+	blobs[digest.FromBytes(raw)] = int64(len(raw))
+
+	if imgmanifest.MIMETypeIsMultiImage(mimeType) {
+		list, err := imgmanifest.ListFromBlob(raw, mimeType)
+		if err != nil {
+			return err
+		}
+		for _, d := range list.Instances() {
+			if err := addManifestBlobs(ctx, src, &d, blobs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	m, err := imgmanifest.FromBlob(raw, mimeType)
+	if err != nil {
+		return err
+	}
+	config := m.ConfigInfo()
+	blobs[config.Digest] = config.Size
+	for _, layer := range m.LayerInfos() {
+		blobs[layer.Digest] = layer.Size
+	}
+	return nil
+}
+
+func sumBlobs(blobs map[digest.Digest]int64) int64 {
+	var total int64
+	for _, size := range blobs {
+		total += size
+	}
+	return total
+}
+
+func writePlanTable(out io.Writer, report PlanReport) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tSIZE\tCACHED\tSOURCE")
+	for _, img := range report.Images {
+		size := formatSize(img.Size)
+		if img.Error != "" {
+			size = "unknown"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", img.Type, size, img.Cached, img.Source)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "images:\t%d\n", len(report.Images))
+	fmt.Fprintf(w, "estimated download size:\t%s\n", formatSize(report.EstimatedDownloadSize))
+	fmt.Fprintf(w, "estimated archive size:\t%s\n", formatSize(report.EstimatedArchiveSize))
+	return w.Flush()
+}
+
+// formatSize - returns size in the largest unit under which it is at least 1
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
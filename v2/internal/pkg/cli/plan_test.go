@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func TestPlan(t *testing.T) {
+	log := clog.New("trace")
+
+	global := &mirror.GlobalOptions{}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+
+	images := []v2alpha1.CopyImageSchema{
+		{Source: "docker://quay.io/test/a:v1", Destination: "docker://localhost:55000/test/a:v1", Type: v2alpha1.TypeOCPReleaseContent},
+		{Source: "docker://quay.io/test/b:v1", Destination: "docker://localhost:55000/test/b:v1", Type: v2alpha1.TypeGeneric},
+		{Source: "docker://quay.io/test/missing:v1", Destination: "docker://localhost:55000/test/missing:v1", Type: v2alpha1.TypeGeneric},
+	}
+
+	// a and b share the layer sha256:shared
+	blobsByImage := map[string]map[digest.Digest]int64{
+		"docker://quay.io/test/a:v1": {"sha256:a": 100, "sha256:shared": 1000},
+		"docker://quay.io/test/b:v1": {"sha256:b": 10, "sha256:shared": 1000},
+	}
+	defaultImageBlobs := imageBlobs
+	defer func() { imageBlobs = defaultImageBlobs }()
+	imageBlobs = func(ctx context.Context, sysCtx *types.SystemContext, imgRef string) (map[digest.Digest]int64, error) {
+		blobs, found := blobsByImage[imgRef]
+		if !found {
+			return nil, fmt.Errorf("manifest unknown")
+		}
+		return blobs, nil
+	}
+
+	newPlan := func(m Mirror) *PlanSchema {
+		return &PlanSchema{
+			ExecutorSchema: ExecutorSchema{
+				Log:    log,
+				Opts:   &mirror.CopyOptions{Global: global, SrcImage: srcOpts, Mode: mirror.MirrorToDisk},
+				Mirror: m,
+			},
+			Output: planOutputTable,
+		}
+	}
+
+	t.Run("Testing Plan : should count shared blobs once", func(t *testing.T) {
+		report, err := newPlan(Mirror{Fail: true}).plan(context.Background(), images)
+		assert.NoError(t, err)
+		assert.Equal(t, []PlannedImage{
+			{Source: images[0].Source, Destination: images[0].Destination, Type: v2alpha1.TypeOCPReleaseContent.String(), Size: 1100},
+			{Source: images[1].Source, Destination: images[1].Destination, Type: v2alpha1.TypeGeneric.String(), Size: 1010},
+			{Source: images[2].Source, Destination: images[2].Destination, Type: v2alpha1.TypeGeneric.String(), Error: "manifest unknown"},
+		}, report.Images)
+		assert.Equal(t, int64(1110), report.EstimatedDownloadSize)
+		assert.Equal(t, int64(1110), report.EstimatedArchiveSize)
+	})
+
+	t.Run("Testing Plan : should not download cached images", func(t *testing.T) {
+		report, err := newPlan(Mirror{Fail: false}).plan(context.Background(), images)
+		assert.NoError(t, err)
+		assert.True(t, report.Images[0].Cached)
+		assert.Equal(t, int64(0), report.EstimatedDownloadSize)
+		assert.Equal(t, int64(1110), report.EstimatedArchiveSize)
+	})
+
+	t.Run("Testing Plan : should write the table", func(t *testing.T) {
+		report, err := newPlan(Mirror{Fail: true}).plan(context.Background(), images)
+		assert.NoError(t, err)
+		var out bytes.Buffer
+		assert.NoError(t, writePlanTable(&out, report))
+		assert.Contains(t, out.String(), "ocpReleaseContent  1.1 KiB  false   docker://quay.io/test/a:v1")
+		assert.Contains(t, out.String(), "unknown")
+		assert.Contains(t, out.String(), "estimated archive size:   1.1 KiB")
+	})
+
+	t.Run("Testing ValidatePlan : should fail", func(t *testing.T) {
+		p := newPlan(Mirror{})
+		assert.EqualError(t, p.ValidatePlan([]string{"docker://localhost:5000"}), "the plan command needs the mirror to disk destination, with a file:// prefix")
+		p.Output = "yaml"
+		assert.EqualError(t, p.ValidatePlan([]string{"file:///tmp/test"}), "output has an invalid value yaml , it should be one of (table, json)")
+	})
+}
+
+func TestFormatSize(t *testing.T) {
+	t.Run("Testing formatSize : should pick the unit", func(t *testing.T) {
+		assert.Equal(t, "512 B", formatSize(512))
+		assert.Equal(t, "1.5 KiB", formatSize(1536))
+		assert.Equal(t, "2.0 GiB", formatSize(2*1024*1024*1024))
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"text/template"
 	"unicode"
 
 	confv1 "github.com/openshift/api/config/v1"
@@ -30,8 +31,24 @@ import (
 
 const (
 	hashTruncLen int = 12
+	// defaultCatalogSourceNamespace is the namespace of the generated
+	// CatalogSources, unless targetCatalogSourceNamespace is set
+	defaultCatalogSourceNamespace = "openshift-marketplace"
 )
 
+// catalogSourceTemplateData holds the variables available to a
+// targetCatalogSourceTemplate, e.g. {{ .CatalogImage }}
+type catalogSourceTemplateData struct {
+	// CatalogImage is the catalog image in the destination registry
+	CatalogImage string
+	// CatalogSourceName is the name generated for the CatalogSource
+	CatalogSourceName string
+	// TargetNamespace is the namespace of the CatalogSource
+	TargetNamespace string
+	// SourceCatalog is the catalog as set in the ImageSetConfiguration
+	SourceCatalog string
+}
+
 func New(log clog.PluggableLoggerInterface,
 	workingDir string,
 	conf v2alpha1.ImageSetConfiguration,
@@ -202,8 +219,7 @@ func (o *ClusterResourcesGenerator) CatalogSourceGenerator(allRelatedImages []v2
 				firstCatalog = false
 			}
 			// check if ImageSetConfig contains a CatalogSourceTemplate for this catalog, and use it
-			template := o.getCSTemplate(copyImage.Origin)
-			err := o.generateCatalogSource(copyImage.Destination, template)
+			err := o.generateCatalogSource(copyImage.Destination, o.getCatalogOperator(copyImage.Origin))
 			if err != nil {
 				return err
 			}
@@ -238,16 +254,17 @@ func (o *ClusterResourcesGenerator) ClusterCatalogGenerator(allRelatedImages []v
 	return nil
 }
 
-func (o *ClusterResourcesGenerator) getCSTemplate(catalogRef string) string {
+// getCatalogOperator returns the operator of the ImageSetConfig for catalogRef
+func (o *ClusterResourcesGenerator) getCatalogOperator(catalogRef string) v2alpha1.Operator {
 	for _, op := range o.Config.ImageSetConfigurationSpec.Mirror.Operators {
 		if strings.Contains(catalogRef, op.Catalog) {
-			return op.TargetCatalogSourceTemplate
+			return op
 		}
 	}
-	return ""
+	return v2alpha1.Operator{}
 }
 
-func (o *ClusterResourcesGenerator) generateCatalogSource(catalogRef string, catalogSourceTemplateFile string) error {
+func (o *ClusterResourcesGenerator) generateCatalogSource(catalogRef string, op v2alpha1.Operator) error {
 
 	catalogSpec, err := image.ParseRef(catalogRef)
 	if err != nil {
@@ -284,16 +301,27 @@ func (o *ClusterResourcesGenerator) generateCatalogSource(catalogRef string, cat
 		return fmt.Errorf("error creating catalog source name: %s", strings.Join(errs, ", "))
 	}
 
+	namespace := op.TargetCatalogSourceNamespace
+	if namespace == "" {
+		namespace = defaultCatalogSourceNamespace
+	}
+
 	var obj ofv1alpha1.CatalogSource
 	generateWithoutTemplate := false
-	if catalogSourceTemplateFile != "" {
-		obj, err = catalogSourceContentFromTemplate(catalogSourceTemplateFile, catalogSourceName, catalogSpec.Reference)
+	if op.TargetCatalogSourceTemplate != "" {
+		data := catalogSourceTemplateData{
+			CatalogImage:      catalogSpec.Reference,
+			CatalogSourceName: catalogSourceName,
+			TargetNamespace:   namespace,
+			SourceCatalog:     op.Catalog,
+		}
+		obj, err = catalogSourceContentFromTemplate(op.TargetCatalogSourceTemplate, data)
 		if err != nil {
 			generateWithoutTemplate = true
 			o.Log.Error("error generating catalog source from template. Fall back to generating catalog source without template: %v", err)
 		}
 	}
-	if generateWithoutTemplate || catalogSourceTemplateFile == "" {
+	if generateWithoutTemplate || op.TargetCatalogSourceTemplate == "" {
 		obj = ofv1alpha1.CatalogSource{
 			TypeMeta: metav1.TypeMeta{
 				APIVersion: ofv1alpha1.GroupName + "/" + ofv1alpha1.GroupVersion,
@@ -301,7 +329,7 @@ func (o *ClusterResourcesGenerator) generateCatalogSource(catalogRef string, cat
 			},
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      catalogSourceName,
-				Namespace: "openshift-marketplace",
+				Namespace: namespace,
 			},
 			Spec: ofv1alpha1.CatalogSourceSpec{
 				SourceType: "grpc",
@@ -346,7 +374,9 @@ func (o *ClusterResourcesGenerator) generateCatalogSource(catalogRef string, cat
 	return err
 }
 
-func catalogSourceContentFromTemplate(templateFile, catalogSourceName, image string) (ofv1alpha1.CatalogSource, error) {
+// catalogSourceContentFromTemplate renders templateFile, a CatalogSource
+// that may use the variables of catalogSourceTemplateData, for a catalog
+func catalogSourceContentFromTemplate(templateFile string, data catalogSourceTemplateData) (ofv1alpha1.CatalogSource, error) {
 	// Initializing catalogSource `obj` from template
 	var obj ofv1alpha1.CatalogSource
 	_, err := os.Stat(templateFile)
@@ -360,7 +390,15 @@ func catalogSourceContentFromTemplate(templateFile, catalogSourceName, image str
 	if err != nil {
 		return obj, fmt.Errorf("error during CatalogSource generation using template: error reading targetCatalogSourceTemplate file %s: %v", templateFile, err)
 	}
-	err = yaml.Unmarshal(bytesRead, &obj)
+	tmpl, err := template.New(filepath.Base(templateFile)).Option("missingkey=error").Parse(string(bytesRead))
+	if err != nil {
+		return obj, fmt.Errorf("error during CatalogSource generation using template: error parsing targetCatalogSourceTemplate file %s: %v", templateFile, err)
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return obj, fmt.Errorf("error during CatalogSource generation using template: error rendering targetCatalogSourceTemplate file %s: %v", templateFile, err)
+	}
+	err = yaml.Unmarshal(rendered.Bytes(), &obj)
 	if err != nil {
 		return obj, fmt.Errorf("error during CatalogSource generation using template: %s is not a valid catalog source template and could not be unmarshaled: %v", templateFile, err)
 	}
@@ -379,10 +417,10 @@ func catalogSourceContentFromTemplate(templateFile, catalogSourceName, image str
 		return ofv1alpha1.CatalogSource{}, fmt.Errorf("error during CatalogSource generation using template: catalog template should not have a configMap specified")
 	}
 	// fill obj with the values for this catalog
-	obj.Name = catalogSourceName
-	obj.Namespace = "openshift-marketplace"
+	obj.Name = data.CatalogSourceName
+	obj.Namespace = data.TargetNamespace
 	obj.Spec.SourceType = "grpc"
-	obj.Spec.Image = image
+	obj.Spec.Image = data.CatalogImage
 
 	//verify that the resulting obj is a valid CatalogSource object
 	_, err = yaml.Marshal(obj)
@@ -700,6 +738,10 @@ func imageTypeToCategory(imageType v2alpha1.ImageType) mirrorCategory {
 		return releaseCategory
 	case v2alpha1.TypeOCPReleaseContent:
 		return releaseCategory
+	case v2alpha1.TypeOCPOSImage:
+		return releaseCategory
+	case v2alpha1.TypeOCPSupportImage:
+		return releaseCategory
 	case v2alpha1.TypeOperatorBundle:
 		return operatorCategory
 	case v2alpha1.TypeOperatorCatalog:
@@ -761,6 +761,78 @@ func TestCatalogSourceGenerator(t *testing.T) {
 
 	})
 
+	t.Run("Testing GenerateCatalogSource with template variables and target namespace: should pass", func(t *testing.T) {
+
+		cr := &ClusterResourcesGenerator{
+			Log:              log,
+			WorkingDir:       workingDir,
+			LocalStorageFQDN: "localhost:55000",
+			Config: v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog:                      "registry.redhat.io/redhat/redhat-operator-index:v4.15",
+								TargetCatalogSourceTemplate:  common.TestFolder + "catalog-source_template_vars.yaml",
+								TargetCatalogSourceNamespace: "site-catalogs",
+							},
+						},
+					},
+				},
+			},
+		}
+		err := cr.CatalogSourceGenerator(imageList)
+		if err != nil {
+			t.Fatalf("should not fail")
+		}
+
+		csFiles, err := os.ReadDir(filepath.Join(workingDir, clusterResourcesDir))
+		if err != nil {
+			t.Fatalf("ls output folder should not fail")
+		}
+		if len(csFiles) != 1 {
+			t.Fatalf("output folder should contain 1 catalogSource yaml file")
+		}
+		bytes, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, csFiles[0].Name()))
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		var actualCS ofv1alpha1.CatalogSource
+		err = yaml.Unmarshal(bytes, &actualCS)
+		if err != nil {
+			t.Fatalf("failed to unmarshal catalogsource: %v", err)
+		}
+		catalogSourceName := strings.TrimSuffix(csFiles[0].Name(), ".yaml")
+		expectedCS := ofv1alpha1.CatalogSource{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: ofv1alpha1.GroupName + "/" + ofv1alpha1.GroupVersion,
+				Kind:       "CatalogSource",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        catalogSourceName,
+				Namespace:   "site-catalogs",
+				Annotations: map[string]string{"mirror.example.com/source": "registry.redhat.io/redhat/redhat-operator-index:v4.15"},
+			},
+			Spec: ofv1alpha1.CatalogSourceSpec{
+				SourceType:  "grpc",
+				Image:       "myregistry/mynamespace/redhat/redhat-operator-index:v4.15",
+				DisplayName: "Mirrored " + catalogSourceName,
+			},
+		}
+
+		assert.Equal(t, expectedCS, actualCS, "contents of catalogSource file incorrect")
+	})
+
+	t.Run("Testing catalogSourceContentFromTemplate with unknown variable: should fail", func(t *testing.T) {
+		templateFile := filepath.Join(t.TempDir(), "catalog-source_template.yaml")
+		err := os.WriteFile(templateFile, []byte("apiVersion: operators.coreos.com/v1alpha1\nkind: CatalogSource\nspec:\n  image: {{ .Unknown }}\n"), 0600)
+		if err != nil {
+			t.Fatalf("failed to write template: %v", err)
+		}
+		_, err = catalogSourceContentFromTemplate(templateFile, catalogSourceTemplateData{CatalogImage: "myregistry/catalog:v1"})
+		assert.ErrorContains(t, err, "error rendering targetCatalogSourceTemplate file")
+	})
+
 	templateFailCases := []ClusterResourcesGenerator{
 		{
 			Log:              log,
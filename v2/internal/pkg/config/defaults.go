@@ -8,6 +8,7 @@ import (
 // when applicable
 func Complete(cfg *v2alpha1.ImageSetConfiguration) {
 	completeReleaseArchitectures(cfg)
+	completeGraphImage(cfg)
 }
 
 func completeReleaseArchitectures(cfg *v2alpha1.ImageSetConfiguration) {
@@ -16,6 +17,18 @@ func completeReleaseArchitectures(cfg *v2alpha1.ImageSetConfiguration) {
 	}
 }
 
+func completeGraphImage(cfg *v2alpha1.ImageSetConfiguration) {
+	if !cfg.Mirror.Platform.Graph {
+		return
+	}
+	if cfg.Mirror.Platform.GraphImage.Repository == "" {
+		cfg.Mirror.Platform.GraphImage.Repository = v2alpha1.DefaultGraphImageRepository
+	}
+	if cfg.Mirror.Platform.GraphImage.Tag == "" {
+		cfg.Mirror.Platform.GraphImage.Tag = v2alpha1.DefaultGraphImageTag
+	}
+}
+
 // Complete set default values in the DeleteImageSetConfiguration
 // when applicable
 func CompleteDelete(cfg *v2alpha1.DeleteImageSetConfiguration) {
@@ -53,6 +53,29 @@ func TestComplete(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Valid/DefaultGraphImage",
+			config: v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Graph:      true,
+							GraphImage: v2alpha1.GraphImage{Tag: "v1"},
+						},
+					},
+				},
+			},
+			expConfig: v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Graph:      true,
+							GraphImage: v2alpha1.GraphImage{Repository: v2alpha1.DefaultGraphImageRepository, Tag: "v1"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, c := range cases {
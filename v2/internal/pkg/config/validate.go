@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/distribution/reference"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 )
@@ -12,7 +16,13 @@ import (
 type validationFunc func(cfg *v2alpha1.ImageSetConfiguration) []error
 type validationDeleteFunc func(cfg *v2alpha1.DeleteImageSetConfiguration) error
 
-var validationChecks = []validationFunc{validateOperatorOptions, validateReleaseChannels}
+var validationChecks = []validationFunc{validateOperatorOptions, validateReleaseChannels, validateArchitectures, validateGraphImage}
+
+// imageArchitectures are the architectures manifest lists can be filtered on
+// tagRegexp matches valid image tags
+var tagRegexp = regexp.MustCompile(`^` + reference.TagRegexp.String() + `$`)
+
+var imageArchitectures = map[string]bool{"amd64": true, "arm64": true, "ppc64le": true, "s390x": true}
 var validationDeleteChecks = []validationDeleteFunc{validateOperatorOptionsDelete, validateReleaseChannelsDelete}
 
 // Validate will check an ImagesetConfiguration for input errors.
@@ -42,6 +52,11 @@ func validateOperatorOptions(cfg *v2alpha1.ImageSetConfiguration) []error {
 		if filterErrs := validateOperatorFiltering(ctlg); len(filterErrs) > 0 {
 			errs = append(errs, filterErrs...)
 		}
+		if ns := ctlg.TargetCatalogSourceNamespace; ns != "" {
+			if nsErrs := validation.IsDNS1123Label(ns); len(nsErrs) > 0 {
+				errs = append(errs, fmt.Errorf("catalog %q: targetCatalogSourceNamespace %q: %s", ctlgName, ns, strings.Join(nsErrs, ", ")))
+			}
+		}
 
 		seen[ctlgName] = true
 	}
@@ -108,6 +123,41 @@ func validateReleaseChannels(cfg *v2alpha1.ImageSetConfiguration) []error {
 	return nil
 }
 
+func validateArchitectures(cfg *v2alpha1.ImageSetConfiguration) []error {
+	errs := []error{}
+	seen := map[string]bool{}
+	for _, arch := range cfg.Mirror.Architectures {
+		switch {
+		case !imageArchitectures[arch]:
+			errs = append(errs, fmt.Errorf("architecture %q: must be one of amd64, arm64, ppc64le, s390x", arch))
+		case seen[arch]:
+			errs = append(errs, fmt.Errorf("architecture %q: duplicate found in configuration", arch))
+		}
+		seen[arch] = true
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateGraphImage(cfg *v2alpha1.ImageSetConfiguration) []error {
+	graphImage := cfg.Mirror.Platform.GraphImage
+	errs := []error{}
+	if graphImage.Repository != "" {
+		if _, err := reference.WithName(graphImage.Repository); err != nil {
+			errs = append(errs, fmt.Errorf("graph image repository %q: %v", graphImage.Repository, err))
+		}
+	}
+	if graphImage.Tag != "" && !tagRegexp.MatchString(graphImage.Tag) {
+		errs = append(errs, fmt.Errorf("graph image tag %q: invalid tag format", graphImage.Tag))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 // ValidateDelete will check an DeleteImagesetConfiguration for input errors.
 func ValidateDelete(cfg *v2alpha1.DeleteImageSetConfiguration) error {
 	var errs []error
@@ -275,6 +275,70 @@ func TestValidate(t *testing.T) {
 			},
 			expError: "invalid configuration: release channel \"channel\": duplicate found in configuration",
 		},
+		{
+			name: "Valid/Architectures",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Architectures: []string{"amd64", "arm64"},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/Architectures",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Architectures: []string{"amd64", "x86_64", "amd64"},
+					},
+				},
+			},
+			expError: "invalid configuration: [architecture \"x86_64\": must be one of amd64, arm64, ppc64le, s390x, architecture \"amd64\": duplicate found in configuration]",
+		},
+		{
+			name: "Invalid/TargetCatalogSourceNamespace",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog:                      "test-catalog:latest",
+								TargetCatalogSourceNamespace: "Site_Catalogs",
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: catalog \"test-catalog:latest\": targetCatalogSourceNamespace \"Site_Catalogs\": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')",
+		},
+		{
+			name: "Valid/GraphImage",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Graph:      true,
+							GraphImage: v2alpha1.GraphImage{Repository: "site/ocp/graph-data", Tag: "v1.0"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/GraphImage",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Graph:      true,
+							GraphImage: v2alpha1.GraphImage{Repository: "Site/Graph", Tag: ":v1"},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [graph image repository \"Site/Graph\": invalid reference format, graph image tag \":v1\": invalid tag format]",
+		},
 	}
 
 	for _, c := range cases {
@@ -12,6 +12,8 @@ func (a ByTypePriority) Less(i, j int) bool {
 	priority := map[string]int{
 		v2alpha1.TypeOCPReleaseContent.String():    1,
 		v2alpha1.TypeKubeVirtContainer.String():    2,
+		v2alpha1.TypeOCPOSImage.String():           2,
+		v2alpha1.TypeOCPSupportImage.String():      2,
 		v2alpha1.TypeOCPRelease.String():           3,
 		v2alpha1.TypeCincinnatiGraph.String():      4,
 		v2alpha1.TypeOperatorRelatedImage.String(): 5,
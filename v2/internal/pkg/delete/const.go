@@ -4,6 +4,8 @@ const (
 	deleteDir                   string = "/delete"
 	deleteImagesYaml            string = "delete/delete-images.yaml"
 	discYaml                    string = "delete/delete-imageset-config.yaml"
+	deleteKeyFile               string = "delete-images.key"
+	signaturePrefix             string = "hmac-sha256:"
 	dockerProtocol              string = "docker://"
 	operatorImageExtractDir     string = "hold-operator"
 	ociProtocol                 string = "oci://"
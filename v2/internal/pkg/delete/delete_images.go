@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/containers/image/v5/types"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/archive"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/batch"
@@ -18,6 +19,7 @@ import (
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"golang.org/x/sync/errgroup"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
@@ -31,10 +33,12 @@ type DeleteImages struct {
 	Manifest         manifest.ManifestInterface
 	LocalStorageDisk string
 	LocalStorageFQDN string
+	// ParallelImages - the number of images looked up in the remote registry in parallel
+	ParallelImages uint
 }
 
 // WriteDeleteMetaData
-func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema) error {
+func (o DeleteImages) WriteDeleteMetaData(ctx context.Context, images []v2alpha1.CopyImageSchema) error {
 	o.Log.Info(emoji.PageFacingUp + " Generating delete file...")
 	o.Log.Info("%s file created", o.Opts.Global.WorkingDir+deleteDir)
 
@@ -52,8 +56,14 @@ func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema) err
 		o.Log.Error("%v ", err)
 	}
 
+	destCtx, err := o.Opts.DestImage.NewSystemContext()
+	if err != nil {
+		return err
+	}
+
 	duplicates := []string{}
 	var items []v2alpha1.DeleteItem
+	var refs []string
 	for _, img := range images {
 		if slices.Contains(duplicates, img.Origin) {
 			o.Log.Debug("duplicate image found %s", img.Origin)
@@ -63,10 +73,19 @@ func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema) err
 				ImageName:      img.Origin,
 				ImageReference: img.Destination,
 				Type:           img.Type,
+				Reason:         deleteReason(img),
 			}
 			items = append(items, item)
+			refs = append(refs, img.Destination)
 		}
 	}
+	digests, err := o.remoteDigests(ctx, destCtx, refs)
+	if err != nil {
+		return err
+	}
+	for i := range items {
+		items[i].Digest = digests[i]
+	}
 
 	// sort the items
 	sort.SliceStable(items, func(i, j int) bool {
@@ -78,6 +97,16 @@ func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema) err
 		APIVersion: "mirror.openshift.io/v2alpha1",
 		Items:      items,
 	}
+	// sign the list with the key of the workspace, so that it can't be
+	// modified between the generate and the delete stages
+	key, err := signingKey(o.Opts.Global.WorkingDir, true)
+	if err != nil {
+		return err
+	}
+	deleteImageList.Signature, err = sign(deleteImageList, key)
+	if err != nil {
+		return err
+	}
 	ymlData, err := yaml.Marshal(deleteImageList)
 	if err != nil {
 		o.Log.Error(deleteImagesErrMsg, err)
@@ -113,7 +142,7 @@ func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema) err
 }
 
 // DeleteRegistryImages - deletes both remote and local registries
-func (o DeleteImages) DeleteRegistryImages(deleteImageList v2alpha1.DeleteImageList) error {
+func (o DeleteImages) DeleteRegistryImages(ctx context.Context, deleteImageList v2alpha1.DeleteImageList) error {
 	o.Log.Debug("deleting images from remote registry")
 	collectorSchema := v2alpha1.CollectorSchema{AllImages: []v2alpha1.CopyImageSchema{}}
 
@@ -151,6 +180,10 @@ func (o DeleteImages) DeleteRegistryImages(deleteImageList v2alpha1.DeleteImageL
 			assembleName = name[1] + "/openshift/release"
 		case v2alpha1.TypeOCPRelease:
 			assembleName = name[1] + "/openshift/release-images"
+		case v2alpha1.TypeOCPOSImage:
+			assembleName = name[1] + "/openshift/os-images"
+		case v2alpha1.TypeOCPSupportImage:
+			assembleName = name[1] + "/openshift/support-images"
 		}
 		// check the assembled name against the reference name
 		if assembleName != imgSpecRef.Name {
@@ -188,7 +221,11 @@ func (o DeleteImages) DeleteRegistryImages(deleteImageList v2alpha1.DeleteImageL
 
 	o.Opts.Stdout = io.Discard
 	if !o.Opts.Global.DeleteGenerate && len(o.Opts.Global.DeleteDestination) > 0 {
-		if _, err := o.Batch.Worker(context.Background(), collectorSchema, o.Opts); err != nil {
+		// nothing is deleted when an image was pushed again since the delete yaml was generated
+		if err := o.verifyDigests(ctx, deleteImageList); err != nil {
+			return err
+		}
+		if _, err := o.Batch.Worker(ctx, collectorSchema, o.Opts); err != nil {
 			if _, ok := err.(batch.UnsafeError); ok {
 				return err
 			} else {
@@ -229,5 +266,102 @@ func (o DeleteImages) ReadDeleteMetaData() (v2alpha1.DeleteImageList, error) {
 	if err != nil {
 		return list, err
 	}
+	key, err := signingKey(o.workingDir(fileName), false)
+	if err != nil {
+		return list, err
+	}
+	if err := verify(list, key, fileName); err != nil {
+		return list, err
+	}
 	return list, nil
 }
+
+// workingDir returns the working dir holding the signing key of the delete
+// yaml file fileName, the one of --workspace when set, or else the working dir
+// of the delete folder of fileName
+func (o DeleteImages) workingDir(fileName string) string {
+	if len(o.Opts.Global.WorkingDir) > 0 {
+		return o.Opts.Global.WorkingDir
+	}
+	return filepath.Dir(filepath.Dir(fileName))
+}
+
+// verifyDigests checks that the images of deleteImageList still have the digest
+// recorded when the delete yaml file was generated, the images without a
+// recorded or remote digest are not checked
+func (o DeleteImages) verifyDigests(ctx context.Context, deleteImageList v2alpha1.DeleteImageList) error {
+	destCtx, err := o.Opts.DestImage.NewSystemContext()
+	if err != nil {
+		return err
+	}
+	var items []v2alpha1.DeleteItem
+	var refs []string
+	for _, img := range deleteImageList.Items {
+		if len(img.Digest) > 0 {
+			items = append(items, img)
+			refs = append(refs, img.ImageReference)
+		}
+	}
+	digests, err := o.remoteDigests(ctx, destCtx, refs)
+	if err != nil {
+		return err
+	}
+	var changed []string
+	for i, img := range items {
+		if len(digests[i]) > 0 && digests[i] != img.Digest {
+			o.Log.Error("image %s has digest %s, %s was recorded in the delete yaml file", img.ImageReference, digests[i], img.Digest)
+			changed = append(changed, img.ImageReference)
+		}
+	}
+	if len(changed) > 0 {
+		return fmt.Errorf("%d images changed in the remote registry since the delete yaml file was generated (please generate it again with --generate)", len(changed))
+	}
+	return nil
+}
+
+// remoteDigests returns the digests of the images refs in the remote registry,
+// looking up at most ParallelImages images in parallel
+func (o DeleteImages) remoteDigests(ctx context.Context, destCtx *types.SystemContext, refs []string) ([]string, error) {
+	digests := make([]string, len(refs))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(int(o.ParallelImages), 1))
+	for i, ref := range refs {
+		g.Go(func() error {
+			digest, err := o.remoteDigest(gctx, destCtx, ref)
+			digests[i] = digest
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return digests, nil
+}
+
+// remoteDigest returns the digest of the image ref in the remote registry,
+// or an empty digest when it can't be found. It only fails when ctx is done.
+func (o DeleteImages) remoteDigest(ctx context.Context, destCtx *types.SystemContext, ref string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	imgSpec, err := image.ParseRef(ref)
+	if err != nil {
+		o.Log.Warn(deleteImagesErrMsg, err)
+		return "", nil
+	}
+	if imgSpec.IsImageByDigest() {
+		return imgSpec.Algorithm + ":" + imgSpec.Digest, nil
+	}
+	digest, err := o.Manifest.GetDigest(ctx, destCtx, imgSpec.ReferenceWithTransport)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		o.Log.Warn("unable to get the digest of %s : %v", ref, err)
+		return "", nil
+	}
+	if len(digest) == 0 {
+		return "", nil
+	}
+	return "sha256:" + digest, nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/containers/image/v5/types"
@@ -12,16 +14,19 @@ import (
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	mirror "github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
 )
 
 // TestAllDeleteImages
 func TestAllDeleteImages(t *testing.T) {
 	log := clog.New("trace")
 
+	workingDir := signedDeleteYaml(t, "")
+
 	global := &mirror.GlobalOptions{
 		SecurePolicy:      false,
 		Quiet:             false,
-		WorkingDir:        common.TestFolder,
+		WorkingDir:        workingDir,
 		DeleteDestination: "docker://localhost:5000/myregistry",
 	}
 
@@ -63,10 +68,9 @@ func TestAllDeleteImages(t *testing.T) {
 		},
 	}
 
-	di := New(log, opts, &mockBatch{}, &mockBlobs{}, isc, &mockManifest{}, "/tmp")
+	di := New(log, opts, &mockBatch{}, &mockBlobs{}, isc, &mockManifest{}, "/tmp", 8)
 
 	t.Run("Testing ReadDeleteData : should pass", func(t *testing.T) {
-		opts.Global.WorkingDir = common.TestFolder
 		data, err := di.ReadDeleteMetaData()
 		if err != nil {
 			t.Fatal("should not fail")
@@ -75,12 +79,11 @@ func TestAllDeleteImages(t *testing.T) {
 	})
 
 	t.Run("Testing DeleteRegistryImages : should pass", func(t *testing.T) {
-		opts.Global.WorkingDir = common.TestFolder
 		imgs, err := di.ReadDeleteMetaData()
 		if err != nil {
 			t.Fatal("should not fail")
 		}
-		err = di.DeleteRegistryImages(imgs)
+		err = di.DeleteRegistryImages(context.Background(), imgs)
 		if err != nil {
 			t.Fatal("should not fail")
 		}
@@ -89,21 +92,108 @@ func TestAllDeleteImages(t *testing.T) {
 	t.Run("Testing DeleteCacheBlobs : should pass", func(t *testing.T) {
 		testFolder := t.TempDir()
 		defer os.RemoveAll(testFolder)
-		opts.Global.WorkingDir = common.TestFolder
 		opts.Global.ForceCacheDelete = true
-		deleteDI := New(log, opts, &mockBatch{}, &mockBlobs{}, v2alpha1.ImageSetConfiguration{}, &mockManifest{}, "/tmp")
+		deleteDI := New(log, opts, &mockBatch{}, &mockBlobs{}, v2alpha1.ImageSetConfiguration{}, &mockManifest{}, "/tmp", 8)
 		imgs, err := di.ReadDeleteMetaData()
 		if err != nil {
 			t.Fatal("should not fail")
 		}
 
-		err = deleteDI.DeleteRegistryImages(imgs)
+		err = deleteDI.DeleteRegistryImages(context.Background(), imgs)
 		if err != nil {
 			t.Fatal("should not fail")
 		}
+		opts.Global.ForceCacheDelete = false
+	})
+
+	t.Run("Testing ReadDeleteData without workspace : should pass", func(t *testing.T) {
+		opts.Global.WorkingDir = ""
+		opts.Global.DeleteYaml = filepath.Join(workingDir, deleteImagesYaml)
+		_, err := di.ReadDeleteMetaData()
+		assert.NoError(t, err)
+		opts.Global.WorkingDir = workingDir
+		opts.Global.DeleteYaml = ""
+	})
+
+	t.Run("Testing ReadDeleteData unsigned : should fail", func(t *testing.T) {
+		opts.Global.DeleteYaml = common.TestFolder + "delete/delete-images.yaml"
+		_, err := di.ReadDeleteMetaData()
+		assert.ErrorContains(t, err, "is not signed")
+		opts.Global.DeleteYaml = ""
+	})
+
+	t.Run("Testing DeleteRegistryImages same digest : should pass", func(t *testing.T) {
+		opts.Global.WorkingDir = signedDeleteYaml(t, "sha256:"+testDigest)
+		deleteDI := New(log, opts, &mockBatch{}, &mockBlobs{}, isc, &mockManifest{Digest: testDigest}, "/tmp", 8)
+		imgs, err := deleteDI.ReadDeleteMetaData()
+		if err != nil {
+			t.Fatalf("should not fail %v", err)
+		}
+		assert.NoError(t, deleteDI.DeleteRegistryImages(context.Background(), imgs))
+	})
+
+	t.Run("Testing DeleteRegistryImages digest changed : should fail", func(t *testing.T) {
+		opts.Global.WorkingDir = signedDeleteYaml(t, "sha256:"+testDigest)
+		deleteDI := New(log, opts, &mockBatch{}, &mockBlobs{}, isc, &mockManifest{Digest: strings.Repeat("0", 64)}, "/tmp", 8)
+		imgs, err := deleteDI.ReadDeleteMetaData()
+		if err != nil {
+			t.Fatalf("should not fail %v", err)
+		}
+		err = deleteDI.DeleteRegistryImages(context.Background(), imgs)
+		assert.ErrorContains(t, err, "1 images changed in the remote registry")
+	})
+
+	t.Run("Testing DeleteRegistryImages cancelled : should fail", func(t *testing.T) {
+		opts.Global.WorkingDir = signedDeleteYaml(t, "sha256:"+testDigest)
+		deleteDI := New(log, opts, &mockBatch{}, &mockBlobs{}, isc, &mockManifest{Digest: testDigest}, "/tmp", 8)
+		imgs, err := deleteDI.ReadDeleteMetaData()
+		if err != nil {
+			t.Fatalf("should not fail %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = deleteDI.DeleteRegistryImages(ctx, imgs)
+		assert.ErrorIs(t, err, context.Canceled)
 	})
 }
 
+const testDigest = "c4b775cbe8eec55de2c163919c6008599e2aebe789ed93ada9a307e800e3f1e2"
+
+// signedDeleteYaml copies the delete yaml file of the test folder, recording
+// digest for its images, to the delete folder of a new working dir signing it
+// with the key of that working dir, and returns the working dir
+func signedDeleteYaml(t *testing.T, digest string) string {
+	workingDir := t.TempDir()
+	data, err := os.ReadFile(common.TestFolder + "delete/delete-images.yaml")
+	if err != nil {
+		t.Fatalf("should not fail %v", err)
+	}
+	var list v2alpha1.DeleteImageList
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		t.Fatalf("should not fail %v", err)
+	}
+	for i := range list.Items {
+		list.Items[i].Digest = digest
+	}
+	key, err := signingKey(workingDir, true)
+	if err != nil {
+		t.Fatalf("should not fail %v", err)
+	}
+	if list.Signature, err = sign(list, key); err != nil {
+		t.Fatalf("should not fail %v", err)
+	}
+	if data, err = yaml.Marshal(list); err != nil {
+		t.Fatalf("should not fail %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(workingDir, deleteDir), 0755); err != nil {
+		t.Fatalf("should not fail %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workingDir, deleteImagesYaml), data, 0600); err != nil {
+		t.Fatalf("should not fail %v", err)
+	}
+	return workingDir
+}
+
 // TestWriteMetaData
 func TestWriteMetaData(t *testing.T) {
 	log := clog.New("trace")
@@ -136,7 +226,7 @@ func TestWriteMetaData(t *testing.T) {
 	}
 
 	cfg := v2alpha1.ImageSetConfiguration{}
-	di := New(log, opts, &mockBatch{}, &mockBlobs{}, cfg, &mockManifest{}, "/tmp")
+	di := New(log, opts, &mockBatch{}, &mockBlobs{}, cfg, &mockManifest{}, "/tmp", 8)
 
 	t.Run("Testing ReadDeleteData : should pass", func(t *testing.T) {
 		cpImages := []v2alpha1.CopyImageSchema{
@@ -144,12 +234,44 @@ func TestWriteMetaData(t *testing.T) {
 				Source:      "docker://localhost:55000/openshift-release-dev/ocp-v4.0-art-dev@sha256:c4b775cbe8eec55de2c163919c6008599e2aebe789ed93ada9a307e800e3f1e2",
 				Destination: "docker://localhost:55000/openshift-release-dev/ocp-v4.0-art-dev@sha256:c4b775cbe8eec55de2c163919c6008599e2aebe789ed93ada9a307e800e3f1e2",
 				Origin:      "test",
+				Type:        v2alpha1.TypeGeneric,
 			},
 		}
-		err := di.WriteDeleteMetaData(cpImages)
+		err := di.WriteDeleteMetaData(context.Background(), cpImages)
+		if err != nil {
+			t.Fatalf("should not fail %v", err)
+		}
+	})
+
+	t.Run("Testing ReadDeleteData signed : should pass", func(t *testing.T) {
+		list, err := di.ReadDeleteMetaData()
+		if err != nil {
+			t.Fatalf("should not fail %v", err)
+		}
+		assert.Equal(t, "sha256:c4b775cbe8eec55de2c163919c6008599e2aebe789ed93ada9a307e800e3f1e2", list.Items[0].Digest)
+		assert.Equal(t, "image listed in delete.additionalImages", list.Items[0].Reason)
+	})
+
+	t.Run("Testing ReadDeleteData tampered : should fail", func(t *testing.T) {
+		fileName := filepath.Join(tempDir, deleteImagesYaml)
+		data, err := os.ReadFile(fileName)
 		if err != nil {
 			t.Fatalf("should not fail %v", err)
 		}
+		tampered := strings.ReplaceAll(string(data), "ocp-v4.0-art-dev", "other")
+		if err := os.WriteFile(fileName, []byte(tampered), 0600); err != nil {
+			t.Fatalf("should not fail %v", err)
+		}
+		_, err = di.ReadDeleteMetaData()
+		assert.ErrorContains(t, err, "was modified since it was generated")
+	})
+
+	t.Run("Testing ReadDeleteData without key : should fail", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(tempDir, deleteKeyFile)); err != nil {
+			t.Fatalf("should not fail %v", err)
+		}
+		_, err := di.ReadDeleteMetaData()
+		assert.ErrorContains(t, err, "delete signing key")
 	})
 }
 
@@ -163,7 +285,9 @@ type mockBlobs struct {
 	Fail bool
 }
 
-type mockManifest struct{}
+type mockManifest struct {
+	Digest string
+}
 
 func (o mockBatch) Worker(ctx context.Context, collectorSchema v2alpha1.CollectorSchema, opts mirror.CopyOptions) (v2alpha1.CollectorSchema, error) {
 	copiedImages := v2alpha1.CollectorSchema{
@@ -211,5 +335,5 @@ func (o mockManifest) ConvertIndexToSingleManifest(dir string, oci *v2alpha1.OCI
 }
 
 func (o mockManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
-	return "", nil
+	return o.Digest, nil
 }
@@ -1,11 +1,13 @@
 package delete
 
 import (
+	"context"
+
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 )
 
 type DeleteInterface interface {
-	WriteDeleteMetaData([]v2alpha1.CopyImageSchema) error
+	WriteDeleteMetaData(context.Context, []v2alpha1.CopyImageSchema) error
 	ReadDeleteMetaData() (v2alpha1.DeleteImageList, error)
-	DeleteRegistryImages(images v2alpha1.DeleteImageList) error
+	DeleteRegistryImages(ctx context.Context, images v2alpha1.DeleteImageList) error
 }
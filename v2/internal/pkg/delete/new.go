@@ -16,6 +16,7 @@ func New(log clog.PluggableLoggerInterface,
 	config v2alpha1.ImageSetConfiguration,
 	manifest manifest.ManifestInterface,
 	localStorageDisk string,
+	parallelImages uint,
 ) DeleteInterface {
 	return &DeleteImages{
 		Log:              log,
@@ -26,5 +27,6 @@ func New(log clog.PluggableLoggerInterface,
 		Manifest:         manifest,
 		LocalStorageDisk: localStorageDisk,
 		LocalStorageFQDN: opts.LocalStorageFQDN,
+		ParallelImages:   parallelImages,
 	}
 }
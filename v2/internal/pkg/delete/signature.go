@@ -0,0 +1,94 @@
+package delete
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// signingKey returns the random key signing the delete yaml files of the
+// working dir workingDir, creating it on first use. The key is kept out of the
+// delete folder, so that sharing the delete folder for review doesn't share
+// the key: a delete yaml file can only be verified with the working dir it was
+// generated in.
+func signingKey(workingDir string, create bool) ([]byte, error) {
+	keyFile := filepath.Join(workingDir, deleteKeyFile)
+	data, err := os.ReadFile(keyFile)
+	switch {
+	case err == nil:
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid delete signing key %s: %w", keyFile, err)
+		}
+		return key, nil
+	case !os.IsNotExist(err):
+		return nil, err
+	case !create:
+		return nil, fmt.Errorf("delete signing key %s not found, the delete yaml file must be used with the --workspace it was generated in", keyFile)
+	}
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyFile, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// sign returns the signature of list, computed without its signature field
+func sign(list v2alpha1.DeleteImageList, key []byte) (string, error) {
+	list.Signature = ""
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verify checks the signature of list, read from the file fileName
+func verify(list v2alpha1.DeleteImageList, key []byte, fileName string) error {
+	if len(list.Signature) == 0 {
+		// delete yaml files generated by earlier releases are not signed
+		return fmt.Errorf("delete yaml file %s is not signed (please generate it again with --generate, using working-dir/%s of its workspace as --config)", fileName, discYaml)
+	}
+	expected, err := sign(list, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(list.Signature)) {
+		return fmt.Errorf("delete yaml file %s was modified since it was generated (please generate it again with --generate)", fileName)
+	}
+	return nil
+}
+
+// deleteReason explains why img is part of the delete yaml file
+func deleteReason(img v2alpha1.CopyImageSchema) string {
+	switch {
+	case img.Type == v2alpha1.TypeOCPRelease:
+		return "release image selected by delete.platform"
+	case img.Type.IsRelease():
+		return "release component image selected by delete.platform"
+	case img.Type.IsOperatorCatalog():
+		return "operator catalog selected by delete.operators"
+	case img.Type == v2alpha1.TypeOperatorBundle:
+		return "operator bundle selected by delete.operators"
+	case img.Type.IsOperator():
+		return "operator related image selected by delete.operators"
+	case img.Type.IsAdditionalImage():
+		return "image listed in delete.additionalImages"
+	case img.Type.IsHelmImage():
+		return "helm chart image selected by delete.helm"
+	}
+	return "image selected by the delete imageset configuration"
+}
@@ -489,6 +489,7 @@ func parseJSONPath(input interface{}, parser *jsonpath.JSONPath, template string
 
 func prepareM2DCopyBatch(images []v2alpha1.RelatedImage) ([]v2alpha1.CopyImageSchema, error) {
 	var result []v2alpha1.CopyImageSchema
+	var errs []error
 	for _, img := range images {
 		var src string
 		var dest string
@@ -496,7 +497,8 @@ func prepareM2DCopyBatch(images []v2alpha1.RelatedImage) ([]v2alpha1.CopyImageSc
 		imgSpec, err := image.ParseRef(img.Image)
 		if err != nil {
 			lsc.Log.Error("%s", err.Error())
-			return nil, err
+			errs = append(errs, image.NewCollectionError(img.Image, err))
+			continue
 		}
 		src = imgSpec.ReferenceWithTransport
 
@@ -514,11 +516,12 @@ func prepareM2DCopyBatch(images []v2alpha1.RelatedImage) ([]v2alpha1.CopyImageSc
 		lsc.Log.Debug("destination %s", dest)
 		result = append(result, v2alpha1.CopyImageSchema{Origin: img.Image, Source: src, Destination: dest, Type: img.Type})
 	}
-	return result, nil
+	return result, errors.Join(errs...)
 }
 
 func prepareD2MCopyBatch(images []v2alpha1.RelatedImage, generateV1TagsFromDigests bool) ([]v2alpha1.CopyImageSchema, error) {
 	var result []v2alpha1.CopyImageSchema
+	var errs []error
 	for _, img := range images {
 		var src string
 		var dest string
@@ -526,7 +529,8 @@ func prepareD2MCopyBatch(images []v2alpha1.RelatedImage, generateV1TagsFromDiges
 		imgSpec, err := image.ParseRef(img.Image)
 		if err != nil {
 			lsc.Log.Error("%s", err.Error())
-			return nil, err
+			errs = append(errs, image.NewCollectionError(img.Image, err))
+			continue
 		}
 		if imgSpec.IsImageByDigestOnly() {
 			tag := fmt.Sprintf("%s-%s", imgSpec.Algorithm, imgSpec.Digest)
@@ -544,7 +548,8 @@ func prepareD2MCopyBatch(images []v2alpha1.RelatedImage, generateV1TagsFromDiges
 			dest = strings.Join([]string{lsc.Opts.Destination, imgSpec.PathComponent}, "/") + ":" + imgSpec.Tag
 		}
 		if src == "" || dest == "" {
-			return result, fmt.Errorf("unable to determine src %s or dst %s for %s", src, dest, img.Name)
+			errs = append(errs, image.NewCollectionError(img.Image, fmt.Errorf("unable to determine src %s or dst %s for %s", src, dest, img.Name)))
+			continue
 		}
 
 		lsc.Log.Debug("source %s", src)
@@ -552,7 +557,7 @@ func prepareD2MCopyBatch(images []v2alpha1.RelatedImage, generateV1TagsFromDiges
 		result = append(result, v2alpha1.CopyImageSchema{Origin: img.Image, Source: src, Destination: dest, Type: img.Type})
 
 	}
-	return result, nil
+	return result, errors.Join(errs...)
 }
 
 func destinationRegistry() string {
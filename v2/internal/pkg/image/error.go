@@ -0,0 +1,57 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+)
+
+// This specific error type is returned by the collectors when
+// an image of the ImageSetConfiguration cannot be collected
+type CollectionError struct {
+	// Image is the source image reference, as in the ImageSetConfiguration
+	Image string
+	err   error
+}
+
+func NewCollectionError(img string, err error) *CollectionError {
+	return &CollectionError{Image: img, err: err}
+}
+
+func (e *CollectionError) Error() string {
+	return fmt.Sprintf("image %s: %v", e.Image, e.err)
+}
+
+func (e *CollectionError) Unwrap() error {
+	return e.err
+}
+
+// CollectionErrors returns the collection errors of err,
+// joined errors included
+func CollectionErrors(err error) []*CollectionError {
+	collErrs, _ := SplitCollectionErrors(err)
+	return collErrs
+}
+
+// SplitCollectionErrors splits err, joined errors included, into the
+// collection errors of the images which could not be collected and
+// the other errors, which are not tied to an image
+func SplitCollectionErrors(err error) ([]*CollectionError, []error) {
+	if err == nil {
+		return nil, nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var collErrs []*CollectionError
+		var others []error
+		for _, e := range joined.Unwrap() {
+			c, o := SplitCollectionErrors(e)
+			collErrs = append(collErrs, c...)
+			others = append(others, o...)
+		}
+		return collErrs, others
+	}
+	var collErr *CollectionError
+	if errors.As(err, &collErr) {
+		return []*CollectionError{collErr}, nil
+	}
+	return nil, []error{err}
+}
@@ -0,0 +1,28 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectionErrors(t *testing.T) {
+	catalogErr := NewCollectionError("registry.redhat.io/redhat/redhat-operator-index:v4.17", errors.New("manifest unknown"))
+	require.EqualError(t, catalogErr, "image registry.redhat.io/redhat/redhat-operator-index:v4.17: manifest unknown")
+
+	imageErr := NewCollectionError("quay.io/foo/bar:v1", errors.New("invalid reference"))
+	err := errors.Join(catalogErr, fmt.Errorf("collecting: %w", imageErr), errors.New("no image"))
+
+	collErrs := CollectionErrors(err)
+	require.Len(t, collErrs, 2)
+	require.Equal(t, "registry.redhat.io/redhat/redhat-operator-index:v4.17", collErrs[0].Image)
+	require.Equal(t, "quay.io/foo/bar:v1", collErrs[1].Image)
+	require.Empty(t, CollectionErrors(errors.New("no image")))
+
+	collErrs, others := SplitCollectionErrors(err)
+	require.Len(t, collErrs, 2)
+	require.Len(t, others, 1)
+	require.EqualError(t, others[0], "no image")
+}
@@ -6,8 +6,10 @@ import (
 	"compress/gzip"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net"
 	"net/http"
 	"os"
@@ -244,6 +246,7 @@ func (b *ImageBuilder) ProcessImageIndex(ctx context.Context, idx v1.ImageIndex,
 	if err != nil {
 		return nil, err
 	}
+	skipped := 0
 	for _, manifest := range idxManifest.Manifests {
 		currentHash := *manifest.Digest.DeepCopy()
 		switch manifest.MediaType {
@@ -274,6 +277,18 @@ func (b *ImageBuilder) ProcessImageIndex(ctx context.Context, idx v1.ImageIndex,
 			return nil, err
 		}
 
+		// The images of the architectures excluded by mirror.architectures
+		// are not in the layout: leave them out, so that the rebuilt
+		// manifest list only references the architectures mirrored
+		if _, err := img.RawManifest(); errors.Is(err, fs.ErrNotExist) {
+			b.Logger.Debug("image %q: skipping manifest %s for %v, not mirrored", targetRef, currentHash, manifest.Platform)
+			resultIdx = mutate.RemoveManifests(resultIdx, match.Digests(currentHash))
+			skipped++
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
 		// Add new layers to image.
 		// Ensure they have the right media type.
 		var mt types.MediaType
@@ -341,6 +356,9 @@ func (b *ImageBuilder) ProcessImageIndex(ctx context.Context, idx v1.ImageIndex,
 		modifiedIndex := mutate.AppendManifests(mutate.RemoveManifests(resultIdx, match.Digests(currentHash)), add)
 		resultIdx = modifiedIndex
 	}
+	if skipped > 0 && skipped == len(idxManifest.Manifests) {
+		return nil, fmt.Errorf("image %q: none of the manifests of the list is in the layout", targetRef)
+	}
 	return resultIdx, nil
 }
 
@@ -9,7 +9,11 @@ import (
 	"testing"
 
 	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
@@ -242,3 +246,68 @@ func TestProcessImageIndex(t *testing.T) {
 
 	})
 }
+
+func TestProcessImageIndexSparse(t *testing.T) {
+	t.Run("Testing ProcessImageIndex with architectures not mirrored: should pass", func(t *testing.T) {
+		ex := &ImageBuilder{Logger: clog.New("debug")}
+
+		amd64, err := random.Image(64, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arm64, err := random.Image(64, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := mutate.AppendManifests(empty.Index,
+			mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+			mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+		)
+		dir := t.TempDir()
+		lp, err := layout.Write(dir, empty.Index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := lp.AppendIndex(list); err != nil {
+			t.Fatal(err)
+		}
+		// arm64 was not mirrored: its manifest is not in the layout
+		arm64Digest, err := arm64.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Remove(filepath.Join(dir, "blobs", arm64Digest.Algorithm, arm64Digest.Hex)); err != nil {
+			t.Fatal(err)
+		}
+
+		idx, err := lp.ImageIndex()
+		if err != nil {
+			t.Fatal(err)
+		}
+		v2format := false
+		resultIdx, err := ex.ProcessImageIndex(context.Background(), idx, &v2format, []string{"serve", "/configs"}, "localhost:5000/catalog:latest")
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest, err := resultIdx.IndexManifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(manifest.Manifests) != 1 || manifest.Manifests[0].Platform.Architecture != "amd64" {
+			t.Fatalf("expected the amd64 manifest only, got %v", manifest.Manifests)
+		}
+
+		// none of the architectures was mirrored
+		amd64Digest, err := amd64.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Remove(filepath.Join(dir, "blobs", amd64Digest.Algorithm, amd64Digest.Hex)); err != nil {
+			t.Fatal(err)
+		}
+		_, err = ex.ProcessImageIndex(context.Background(), idx, &v2format, []string{"serve", "/configs"}, "localhost:5000/catalog:latest")
+		if err == nil {
+			t.Fatalf("should fail")
+		}
+	})
+}
@@ -32,6 +32,8 @@ const (
 	operatorCatalogFilteredImageDir = "filtered-catalog-image"
 	operatorCatalogImageDir         = "catalog-image"
 	operatorCatalogConfigDir        = "catalog-config"
+
+	dockerProtocol = "docker://"
 )
 
 type GCRCatalogBuilder struct {
@@ -96,6 +98,9 @@ func (c GCRCatalogBuilder) RebuildCatalog(ctx context.Context, catalogCopyRef v2
 		return fmt.Errorf("error creating OCI layout: %v", err)
 	}
 
+	// The rebuilt catalog is served without --cache-dir: opm builds
+	// its cache from /configs at startup, so no pre-built cache is
+	// shipped that could go stale or need an integrity check
 	configCMD := []string{"serve", "/configs"}
 
 	var srcCache string
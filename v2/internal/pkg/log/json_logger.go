@@ -0,0 +1,173 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// TextFormat - human oriented output (default)
+	TextFormat = "text"
+	// JSONFormat - one JSON object per line, suitable for log aggregators
+	JSONFormat = "json"
+)
+
+// Event - a structured record describing the outcome of
+// processing a single image
+type Event struct {
+	Image    string
+	Type     string
+	Phase    string
+	Duration time.Duration
+	Err      error
+}
+
+// EventLoggerInterface - implemented by loggers that are able
+// to emit structured events
+type EventLoggerInterface interface {
+	Event(e Event)
+}
+
+// LogEvent - emits the event as a structured record when the logger
+// supports it, and as a debug message otherwise
+func LogEvent(log PluggableLoggerInterface, e Event) {
+	if el, ok := log.(EventLoggerInterface); ok {
+		el.Event(e)
+		return
+	}
+	if e.Err != nil {
+		log.Debug("%s %s (%s) failed after %v: %v", e.Phase, e.Image, e.Type, e.Duration, e.Err)
+		return
+	}
+	log.Debug("%s %s (%s) completed in %v", e.Phase, e.Image, e.Type, e.Duration)
+}
+
+// JSONLogger - writes every message and event as a single line JSON object
+type JSONLogger struct {
+	level string
+	out   io.Writer
+	mu    sync.Mutex
+}
+
+type jsonRecord struct {
+	Time     string `json:"time"`
+	Level    string `json:"level"`
+	Msg      string `json:"msg,omitempty"`
+	Image    string `json:"image,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Phase    string `json:"phase,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// NewJSON - returns a new JSONLogger instance writing to out
+func NewJSON(level string, out io.Writer) PluggableLoggerInterface {
+	return &JSONLogger{level: level, out: out}
+}
+
+// Error
+func (c *JSONLogger) Error(msg string, val ...interface{}) {
+	c.write(jsonRecord{Level: "error", Msg: cleanMessage(msg, val...)})
+}
+
+// Info
+func (c *JSONLogger) Info(msg string, val ...interface{}) {
+	if c.enabled("info") {
+		c.write(jsonRecord{Level: "info", Msg: cleanMessage(msg, val...)})
+	}
+}
+
+// Debug
+func (c *JSONLogger) Debug(msg string, val ...interface{}) {
+	if c.enabled("debug") {
+		c.write(jsonRecord{Level: "debug", Msg: cleanMessage(msg, val...)})
+	}
+}
+
+// Trace
+func (c *JSONLogger) Trace(msg string, val ...interface{}) {
+	if c.enabled("trace") {
+		c.write(jsonRecord{Level: "trace", Msg: cleanMessage(msg, val...)})
+	}
+}
+
+// Warn
+func (c *JSONLogger) Warn(msg string, val ...interface{}) {
+	if c.enabled("warn") {
+		c.write(jsonRecord{Level: "warn", Msg: cleanMessage(msg, val...)})
+	}
+}
+
+// Event - emits a structured event, failed events are logged
+// at error level
+func (c *JSONLogger) Event(e Event) {
+	rec := jsonRecord{
+		Level:    "info",
+		Image:    e.Image,
+		Type:     e.Type,
+		Phase:    e.Phase,
+		Duration: e.Duration.String(),
+	}
+	if e.Err != nil {
+		rec.Level = "error"
+		rec.Error = e.Err.Error()
+	} else if !c.enabled("info") {
+		return
+	}
+	c.write(rec)
+}
+
+// Level - ovveride log level
+func (c *JSONLogger) Level(level string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.level = level
+}
+
+func (c *JSONLogger) GetLevel() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.level
+}
+
+// enabled - follows the same level semantics as the simple logger
+func (c *JSONLogger) enabled(level string) bool {
+	current := c.GetLevel()
+	switch level {
+	case "warn":
+		return current != "error"
+	case "info":
+		return current == "info" || current == "debug" || current == "trace"
+	case "debug":
+		return current == "debug" || current == "trace"
+	case "trace":
+		return current == "trace"
+	}
+	return true
+}
+
+func (c *JSONLogger) write(rec jsonRecord) {
+	rec.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// nolint: errcheck
+	c.out.Write(append(b, '\n'))
+}
+
+// cleanMessage - formats the message and strips the leading
+// emojis and padding used for the human oriented output
+func cleanMessage(msg string, val ...interface{}) string {
+	formatted := fmt.Sprintf(msg, val...)
+	return strings.TrimSpace(strings.TrimLeftFunc(formatted, func(r rune) bool {
+		return r > unicode.MaxASCII || unicode.IsSpace(r)
+	}))
+}
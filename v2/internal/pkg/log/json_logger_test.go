@@ -0,0 +1,67 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLogger(t *testing.T) {
+
+	t.Run("Testing JSONLogger messages : should pass", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := NewJSON("info", out)
+		log.Info("\U0001F680 Start copying the images...")
+		log.Debug("not shown at info level")
+		log.Warn("warning %d", 1)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 2)
+
+		var rec map[string]string
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+		assert.Equal(t, "info", rec["level"])
+		assert.Equal(t, "Start copying the images...", rec["msg"])
+		assert.NotEmpty(t, rec["time"])
+
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+		assert.Equal(t, "warn", rec["level"])
+		assert.Equal(t, "warning 1", rec["msg"])
+	})
+
+	t.Run("Testing JSONLogger events : should pass", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := NewJSON("info", out)
+		LogEvent(log, Event{Image: "quay.io/a/b:1", Type: "generic", Phase: "copy", Duration: time.Second})
+		LogEvent(log, Event{Image: "quay.io/a/c:1", Type: "generic", Phase: "copy", Duration: time.Second, Err: errors.New("boom")})
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 2)
+
+		var rec map[string]string
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+		assert.Equal(t, map[string]string{
+			"time":     rec["time"],
+			"level":    "info",
+			"image":    "quay.io/a/b:1",
+			"type":     "generic",
+			"phase":    "copy",
+			"duration": "1s",
+		}, rec)
+
+		rec = map[string]string{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+		assert.Equal(t, "error", rec["level"])
+		assert.Equal(t, "boom", rec["error"])
+	})
+
+	t.Run("Testing LogEvent with PluggableLogger : should pass", func(t *testing.T) {
+		// falls back to a debug message
+		LogEvent(New("debug"), Event{Image: "quay.io/a/b:1", Type: "generic", Phase: "copy", Duration: time.Second})
+	})
+}
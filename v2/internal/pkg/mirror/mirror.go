@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/containers/common/pkg/retry"
@@ -15,6 +16,8 @@ import (
 	"github.com/containers/image/v5/transports/alltransports"
 	"github.com/containers/image/v5/types"
 	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/progress"
 )
 
 type Mode string
@@ -189,6 +192,24 @@ func (o *Mirror) copy(ctx context.Context, src, dest string, opts *CopyOptions)
 		co.ReportWriter = opts.Stdout
 	}
 
+	if len(opts.Architectures) > 0 && imageListSelection == copy.CopyAllImages {
+		instances, err := o.architectureInstances(ctx, srcRef, sourceCtx, opts)
+		if err != nil {
+			return err
+		}
+		if instances != nil {
+			co.ImageListSelection = copy.CopySpecificImages
+			co.Instances = instances
+		}
+	}
+
+	if opts.Progress != nil {
+		progressCh, closeProgress := opts.Progress.Channel(src)
+		defer closeProgress()
+		co.Progress = progressCh
+		co.ProgressInterval = progress.UpdateInterval
+	}
+
 	return retry.IfNecessary(ctx, func() error {
 
 		//manifestBytes, err := copy.Image(ctx, policyContext, destRef, srcRef, &copy.Options{
@@ -209,6 +230,56 @@ func (o *Mirror) copy(ctx context.Context, src, dest string, opts *CopyOptions)
 	}, opts.RetryOpts)
 }
 
+// architectureInstances - returns the instances of the manifest list of srcRef
+// matching opts.Architectures, or nil when srcRef is not a manifest list
+func (o *Mirror) architectureInstances(ctx context.Context, srcRef types.ImageReference, sourceCtx *types.SystemContext, opts *CopyOptions) ([]digest.Digest, error) {
+	var instances []digest.Digest
+	err := retry.IfNecessary(ctx, func() error {
+		src, err := srcRef.NewImageSource(ctx, sourceCtx)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		manifestBytes, mime, err := src.GetManifest(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if !manifest.MIMETypeIsMultiImage(mime) {
+			return nil
+		}
+		list, err := manifest.ListFromBlob(manifestBytes, mime)
+		if err != nil {
+			return err
+		}
+		instances, err = InstancesForArchitectures(list, opts.Architectures)
+		if err != nil {
+			return err
+		}
+		if len(instances) == 0 {
+			return fmt.Errorf("%s has no instance for architectures %s", srcRef.StringWithinTransport(), strings.Join(opts.Architectures, ", "))
+		}
+		return nil
+	}, opts.RetryOpts)
+	return instances, err
+}
+
+// InstancesForArchitectures - returns the instances of list whose platform
+// architecture is one of architectures. Instances without a platform, such
+// as attestations, are not selected
+func InstancesForArchitectures(list manifest.List, architectures []string) ([]digest.Digest, error) {
+	var instances []digest.Digest
+	for _, d := range list.Instances() {
+		instance, err := list.Instance(d)
+		if err != nil {
+			return nil, err
+		}
+		if instance.ReadOnly.Platform != nil && slices.Contains(architectures, instance.ReadOnly.Platform.Architecture) {
+			instances = append(instances, d)
+		}
+	}
+	return instances, nil
+}
+
 // check exists - checks if image exists
 func (o *Mirror) Check(ctx context.Context, image string, opts *CopyOptions, asCopySrc bool) (bool, error) {
 
@@ -9,9 +9,11 @@ import (
 	"testing"
 
 	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/manifest"
 	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/types"
 	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/opencontainers/go-digest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
 	"github.com/stretchr/testify/assert"
 )
@@ -242,6 +244,35 @@ func TestMirrorParseMultiArch(t *testing.T) {
 	assert.Equal(t, "unknown multi-arch option \"other\". Choose one of the supported options: 'system', 'all', or 'index-only'", err.Error())
 }
 
+func TestInstancesForArchitectures(t *testing.T) {
+	index := []byte(`{
+		"schemaVersion": 2,
+		"mediaType": "application/vnd.oci.image.index.v1+json",
+		"manifests": [
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 1, "digest": "sha256:1111111111111111111111111111111111111111111111111111111111111111", "platform": {"architecture": "amd64", "os": "linux"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 1, "digest": "sha256:2222222222222222222222222222222222222222222222222222222222222222", "platform": {"architecture": "arm64", "os": "linux"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 1, "digest": "sha256:3333333333333333333333333333333333333333333333333333333333333333", "platform": {"architecture": "s390x", "os": "linux"}},
+			{"mediaType": "application/vnd.oci.image.manifest.v1+json", "size": 1, "digest": "sha256:4444444444444444444444444444444444444444444444444444444444444444"}
+		]
+	}`)
+	list, err := manifest.ListFromBlob(index, manifest.GuessMIMEType(index))
+	assert.NoError(t, err)
+
+	t.Run("Testing InstancesForArchitectures : should return the instances of the architectures", func(t *testing.T) {
+		instances, err := InstancesForArchitectures(list, []string{"amd64", "s390x"})
+		assert.NoError(t, err)
+		assert.Equal(t, []digest.Digest{
+			"sha256:1111111111111111111111111111111111111111111111111111111111111111",
+			"sha256:3333333333333333333333333333333333333333333333333333333333333333",
+		}, instances)
+	})
+	t.Run("Testing InstancesForArchitectures : should return no instance", func(t *testing.T) {
+		instances, err := InstancesForArchitectures(list, []string{"ppc64le"})
+		assert.NoError(t, err)
+		assert.Empty(t, instances)
+	})
+}
+
 // mock
 
 type mockMirrorCopy struct{}
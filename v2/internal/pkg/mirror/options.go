@@ -16,6 +16,7 @@ import (
 	"github.com/containers/image/v5/types"
 	"github.com/google/uuid"
 	imgspecv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/progress"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -30,6 +31,7 @@ type ErrorShouldDisplayUsage struct {
 
 type GlobalOptions struct {
 	LogLevel           string        // one of info, debug, trace
+	LogFormat          string        // one of text, json
 	PolicyPath         string        // Path to a signature verification policy file
 	SecurePolicy       bool          // Use an "allow everything" signature verification policy
 	RegistriesDirPath  string        // Path to a "registries.d" registry configuration directory
@@ -56,6 +58,12 @@ type GlobalOptions struct {
 	DeleteID           string        // This flag is used to append to the artifacts created by the delete functionality
 	DeleteYaml         string        // This flag will use the contents of the indicated yaml as basis to delete the local cache and remote registry
 	CacheDir           string        // Path to the cache directory
+	CompactCache       bool          // Hardlink the blobs of the workspace OCI layouts to the cache, so that each blob is stored once
+	ContinueOnError    bool          // Record the images that fail to be collected or copied and keep going with the others
+	CacheKeepRuns      int           // Number of most recent mirroring runs whose images are kept in the cache, 0 disables the limit
+	CacheMaxAge        time.Duration // Mirroring runs older than this lose their images from the cache, 0 disables the limit
+	CacheMaxSize       int64         // Size in GB the cache is pruned to, dropping the oldest mirroring runs first, 0 disables the limit
+	ImageScanHook      string        // Executable run on each image mirrored to disk, which can veto the image from the archive
 }
 
 type CopyOptions struct {
@@ -88,7 +96,9 @@ type CopyOptions struct {
 	ParallelLayerImages      uint
 	Function                 string // copy or delete (default is copy)
 	LocalStorageFQDN         string
-	RootlessStoragePath      string // used to override the container rootlesss storage path (usually set in /etc/containers/storage.conf)
+	RootlessStoragePath      string            // used to override the container rootlesss storage path (usually set in /etc/containers/storage.conf)
+	Progress                 *progress.Tracker // when set, the bytes transferred by each copy are reported to the tracker
+	Architectures            []string          // when set, only the instances of these architectures are copied from manifest lists
 }
 
 // deprecatedTLSVerifyOption represents a deprecated --tls-verify option,
@@ -2,6 +2,7 @@ package operator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"path"
@@ -133,8 +134,11 @@ func (o OperatorCollector) catalogDigest(ctx context.Context, catalog v2alpha1.O
 	return catalogDigest, nil
 }
 
+// prepareD2MCopyBatch - returns the copies of images from the cache to the destination,
+// and the errors of the images whose copy could not be prepared
 func (o OperatorCollector) prepareD2MCopyBatch(images map[string][]v2alpha1.RelatedImage) ([]v2alpha1.CopyImageSchema, error) {
 	var result []v2alpha1.CopyImageSchema
+	var errs []error
 	var alreadyIncluded map[string]struct{} = make(map[string]struct{})
 	for _, relatedImgs := range images {
 		for _, img := range relatedImgs {
@@ -190,7 +194,8 @@ func (o OperatorCollector) prepareD2MCopyBatch(images map[string][]v2alpha1.Rela
 						hasher.Reset()
 						_, err = hasher.Write([]byte(imgSpec.Reference))
 						if err != nil {
-							return result, fmt.Errorf("couldn't generate v1 tag for image (%s), skipping ", imgSpec.ReferenceWithTransport)
+							errs = append(errs, image.NewCollectionError(img.Image, fmt.Errorf("couldn't generate v1 tag for image (%s), skipping ", imgSpec.ReferenceWithTransport)))
+							continue
 						}
 						dest = dest + ":" + fmt.Sprintf("%x", hasher.Sum32())
 					}
@@ -206,7 +211,8 @@ func (o OperatorCollector) prepareD2MCopyBatch(images map[string][]v2alpha1.Rela
 				dest = dest + ":" + imgSpec.Tag
 			}
 			if src == "" || dest == "" {
-				return result, fmt.Errorf("unable to determine src %s or dst %s for %s", src, dest, img.Image)
+				errs = append(errs, image.NewCollectionError(img.Image, fmt.Errorf("unable to determine src %s or dst %s for %s", src, dest, img.Image)))
+				continue
 			}
 
 			o.Log.Debug("source %s", src)
@@ -221,7 +227,7 @@ func (o OperatorCollector) prepareD2MCopyBatch(images map[string][]v2alpha1.Rela
 			}
 		}
 	}
-	return result, nil
+	return result, errors.Join(errs...)
 }
 
 func (o OperatorCollector) prepareM2DCopyBatch(images map[string][]v2alpha1.RelatedImage) ([]v2alpha1.CopyImageSchema, error) {
@@ -17,4 +17,5 @@ const (
 	errorSemver                string = " semver %v "
 	filteredCatalogDir                = "filtered-operator"
 	digestIncorrectMessage     string = "the digests seem to be incorrect for %s: %s "
+	maxParallelCatalogs               = 4 // number of catalogs collected in parallel
 )
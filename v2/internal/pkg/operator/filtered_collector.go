@@ -12,6 +12,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/containers/image/v5/types"
 	"github.com/opencontainers/go-digest"
@@ -24,6 +25,7 @@ import (
 	"github.com/otiai10/copy"
 	"github.com/vbauerster/mpb/v8"
 	"github.com/vbauerster/mpb/v8/decor"
+	"golang.org/x/sync/errgroup"
 )
 
 type FilterCollector struct {
@@ -36,468 +38,539 @@ type FilterCollector struct {
 // once unmarshalled, the links to manifests are inspected
 func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.CollectorSchema, error) {
 
-	var (
-		allImages       []v2alpha1.CopyImageSchema
-		label           string
-		catalogImageDir string
-		catalogName     string
-		rebuiltTag      string
-	)
+	var allImages []v2alpha1.CopyImageSchema
 	o.Log.Debug(collectorPrefix+"setting copy option o.Opts.MultiArch=%s when collecting operator images", o.Opts.MultiArch)
 
 	relatedImages := make(map[string][]v2alpha1.RelatedImage)
 	collectorSchema := v2alpha1.CollectorSchema{}
 	copyImageSchemaMap := &v2alpha1.CopyImageSchemaMap{OperatorsByImage: make(map[string]map[string]struct{}), BundlesByImage: make(map[string]map[string]string)}
 
+	// catalogs are collected in parallel, the ImageSetConfiguration entries
+	// of a same catalog share its working directory and are collected in turn
+	catalogLocks := make(map[string]*sync.Mutex)
 	for _, op := range o.Config.Mirror.Operators {
-		var catalogImage string
-		// download the operator index image
-		o.Log.Debug(collectorPrefix+"copying operator image %s", op.Catalog)
-
-		// prepare spinner
-		p := mpb.New()
-		spinner := p.AddSpinner(
-			1, mpb.BarFillerMiddleware(spinners.PositionSpinnerLeft),
-			mpb.BarWidth(3),
-			mpb.PrependDecorators(
-				decor.OnComplete(spinners.EmptyDecorator(), emoji.SpinnerCheckMark),
-				decor.OnAbort(spinners.EmptyDecorator(), emoji.SpinnerCrossMark),
-			),
-			mpb.AppendDecorators(
-				decor.Name("("),
-				decor.Elapsed(decor.ET_STYLE_GO),
-				decor.Name(") Collecting catalog "+op.Catalog+" "),
-			),
-			mpb.BarFillerClearOnComplete(),
-			spinners.BarFillerClearOnAbort(),
-		)
-		// CLID-47 double check that targetCatalog is valid
-		if op.TargetCatalog != "" && !v2alpha1.IsValidPathComponent(op.TargetCatalog) {
-			o.Log.Error(collectorPrefix+"invalid targetCatalog %s", op.TargetCatalog)
+		if _, ok := catalogLocks[op.Catalog]; !ok {
+			catalogLocks[op.Catalog] = &sync.Mutex{}
+		}
+	}
+	results := make([]*catalogCollectResult, len(o.Config.Mirror.Operators))
+	errs := make([]error, len(o.Config.Mirror.Operators))
+	p := mpb.New()
+	var wg errgroup.Group
+	wg.SetLimit(maxParallelCatalogs)
+	for i, op := range o.Config.Mirror.Operators {
+		wg.Go(func() error {
+			lock := catalogLocks[op.Catalog]
+			lock.Lock()
+			defer lock.Unlock()
+			results[i], errs[i] = o.collectCatalog(ctx, p, op)
+			if errs[i] != nil {
+				errs[i] = image.NewCollectionError(op.Catalog, errs[i])
+			}
+			return nil
+		})
+	}
+	// errors are gathered per catalog, the group never fails
+	_ = wg.Wait()
+	p.Wait()
+	// the images of the catalogs which could not be collected are left out,
+	// their errors are returned along with the images of the other catalogs
+	collectErrs := errors.Join(errs...)
+
+	// results are merged in the order of the ImageSetConfiguration
+	for _, res := range results {
+		if res == nil {
+			// skipped or failed catalog
+			continue
+		}
+		if res.filterResult != nil {
+			if collectorSchema.CatalogToFBCMap == nil {
+				collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
+			}
+			collectorSchema.CatalogToFBCMap[res.catalogRef] = *res.filterResult
+		}
+		maps.Copy(relatedImages, res.relatedImages)
+		mergeCopyImageSchemaMap(copyImageSchemaMap, res.copyImageSchemaMap)
+	}
+
+	o.Log.Debug(collectorPrefix+"related images length %d ", len(relatedImages))
+	var count = 0
+	if o.Opts.Global.LogLevel == "debug" {
+		for _, v := range relatedImages {
+			count = count + len(v)
+		}
+	}
+	o.Log.Debug(collectorPrefix+"images to copy (before duplicates) %d ", count)
+	var err error
+	// check the mode, the images which could not be prepared
+	// are returned as errors along with the others
+	switch {
+	case o.Opts.IsMirrorToDisk():
+		allImages, err = o.prepareM2DCopyBatch(relatedImages)
+	case o.Opts.IsMirrorToMirror():
+		allImages, err = o.dispatchImagesForM2M(relatedImages)
+	case o.Opts.IsDiskToMirror() || o.Opts.Mode == string(mirror.DeleteMode):
+		allImages, err = o.prepareD2MCopyBatch(relatedImages)
+	}
+	if err != nil {
+		o.Log.Error(errMsg, err.Error())
+		collectErrs = errors.Join(collectErrs, err)
+	}
+
+	collectorSchema.AllImages = allImages
+	collectorSchema.CopyImageSchemaMap = *copyImageSchemaMap
+
+	return collectorSchema, collectErrs
+}
+
+// catalogCollectResult - the outcome of the collection of one catalog of the ImageSetConfiguration
+type catalogCollectResult struct {
+	catalogRef         string
+	filterResult       *v2alpha1.CatalogFilterResult
+	relatedImages      map[string][]v2alpha1.RelatedImage
+	copyImageSchemaMap v2alpha1.CopyImageSchemaMap
+}
+
+// collectCatalog - downloads (or reuses) the catalog of op, filters it
+// and collects its related images, along with the catalog image itself.
+// It returns a nil result when the catalog is skipped
+func (o *FilterCollector) collectCatalog(ctx context.Context, p *mpb.Progress, op v2alpha1.Operator) (*catalogCollectResult, error) {
+	var (
+		catalogImage    string
+		label           string
+		catalogImageDir string
+		catalogName     string
+		rebuiltTag      string
+	)
+	res := &catalogCollectResult{
+		copyImageSchemaMap: v2alpha1.CopyImageSchemaMap{OperatorsByImage: make(map[string]map[string]struct{}), BundlesByImage: make(map[string]map[string]string)},
+	}
+	// download the operator index image
+	o.Log.Debug(collectorPrefix+"copying operator image %s", op.Catalog)
+
+	// prepare spinner
+	spinner := p.AddSpinner(
+		1, mpb.BarFillerMiddleware(spinners.PositionSpinnerLeft),
+		mpb.BarWidth(3),
+		mpb.PrependDecorators(
+			decor.OnComplete(spinners.EmptyDecorator(), emoji.SpinnerCheckMark),
+			decor.OnAbort(spinners.EmptyDecorator(), emoji.SpinnerCrossMark),
+		),
+		mpb.AppendDecorators(
+			decor.Name("("),
+			decor.Elapsed(decor.ET_STYLE_GO),
+			decor.Name(") Collecting catalog "+op.Catalog+" "),
+		),
+		mpb.BarFillerClearOnComplete(),
+		spinners.BarFillerClearOnAbort(),
+	)
+	// CLID-47 double check that targetCatalog is valid
+	if op.TargetCatalog != "" && !v2alpha1.IsValidPathComponent(op.TargetCatalog) {
+		o.Log.Error(collectorPrefix+"invalid targetCatalog %s", op.TargetCatalog)
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, fmt.Errorf(collectorPrefix+"invalid targetCatalog %s", op.TargetCatalog)
+	}
+	// CLID-27 ensure we pick up oci:// (on disk) catalogs
+	imgSpec, err := image.ParseRef(op.Catalog)
+	if err != nil {
+		o.Log.Error(errMsg, err.Error())
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, err
+	}
+	res.catalogRef = imgSpec.ReferenceWithTransport
+	//OCPBUGS-36214: For diskToMirror (and delete), access to the source registry is not guaranteed
+	catalogDigest := ""
+	if o.Opts.Mode == mirror.DiskToMirror || o.Opts.Mode == string(mirror.DeleteMode) {
+		d, err := o.catalogDigest(ctx, op)
+		if err != nil {
+			o.Log.Error(errMsg, err.Error())
 			spinner.Abort(true)
 			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"invalid targetCatalog %s", op.TargetCatalog)
+			return nil, err
 		}
-		// CLID-27 ensure we pick up oci:// (on disk) catalogs
-		imgSpec, err := image.ParseRef(op.Catalog)
+		catalogDigest = d
+	} else {
+		sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
 		if err != nil {
-			o.Log.Error(errMsg, err.Error())
 			spinner.Abort(true)
 			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, err
+			return nil, err
 		}
-		//OCPBUGS-36214: For diskToMirror (and delete), access to the source registry is not guaranteed
-		catalogDigest := ""
-		if o.Opts.Mode == mirror.DiskToMirror || o.Opts.Mode == string(mirror.DeleteMode) {
-			d, err := o.catalogDigest(ctx, op)
-			if err != nil {
-				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
-			}
-			catalogDigest = d
-		} else {
-			sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
-			if err != nil {
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
-			}
-			d, err := o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport)
-			// OCPBUGS-36548 (manifest unknown)
-			if err != nil {
-				spinner.Abort(true)
-				spinner.Wait()
-				o.Log.Warn(collectorPrefix+"catalog %s : SKIPPING", err.Error())
-				continue
-			}
-			catalogDigest = d
+		d, err := o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport)
+		// OCPBUGS-36548 (manifest unknown)
+		if err != nil {
+			spinner.Abort(true)
+			spinner.Wait()
+			o.Log.Warn(collectorPrefix+"catalog %s : SKIPPING", err.Error())
+			return nil, nil
 		}
+		catalogDigest = d
+	}
+
+	imageIndex := filepath.Join(imgSpec.ComponentName(), catalogDigest)
+	imageIndexDir := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, imageIndex)
+	configsDir := filepath.Join(imageIndexDir, operatorCatalogConfigDir)
+	catalogImageDir = filepath.Join(imageIndexDir, operatorCatalogImageDir)
+	filteredCatalogsDir := filepath.Join(imageIndexDir, operatorCatalogFilteredDir)
+
+	err = createFolders([]string{configsDir, catalogImageDir, filteredCatalogsDir})
+	if err != nil {
+		o.Log.Error(errMsg, err.Error())
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, err
+	}
 
-		imageIndex := filepath.Join(imgSpec.ComponentName(), catalogDigest)
-		imageIndexDir := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, imageIndex)
-		configsDir := filepath.Join(imageIndexDir, operatorCatalogConfigDir)
-		catalogImageDir = filepath.Join(imageIndexDir, operatorCatalogImageDir)
-		filteredCatalogsDir := filepath.Join(imageIndexDir, operatorCatalogFilteredDir)
+	var filteredDC *declcfg.DeclarativeConfig
+	var isAlreadyFiltered bool
 
-		err = createFolders([]string{configsDir, catalogImageDir, filteredCatalogsDir})
+	filterDigest, err := digestOfFilter(op)
+	if err != nil {
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, err
+	}
+	rebuiltTag = filterDigest
+	var srcFilteredCatalog string
+	filterPath := filepath.Join(filteredCatalogsDir, filterDigest, "digest")
+	filteredImageDigest, err := os.ReadFile(filterPath)
+	if err == nil && len(filterDigest) > 0 {
+		srcFilteredCatalog, err = o.cachedCatalog(op, filterDigest)
 		if err != nil {
 			o.Log.Error(errMsg, err.Error())
 			spinner.Abort(true)
 			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, err
+			return nil, err
 		}
+		isAlreadyFiltered = o.isAlreadyFiltered(ctx, srcFilteredCatalog, string(filteredImageDigest))
+	}
 
-		var filteredDC *declcfg.DeclarativeConfig
-		var isAlreadyFiltered bool
-
-		filterDigest, err := digestOfFilter(op)
+	if isAlreadyFiltered {
+		filterConfigDir := filepath.Join(filteredCatalogsDir, filterDigest, operatorCatalogConfigDir)
+		filteredDC, err = o.ctlgHandler.getDeclarativeConfig(filterConfigDir)
 		if err != nil {
+			o.Log.Error(errMsg, err.Error())
 			spinner.Abort(true)
 			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, err
+			return nil, err
 		}
-		rebuiltTag = filterDigest
-		var srcFilteredCatalog string
-		filterPath := filepath.Join(filteredCatalogsDir, filterDigest, "digest")
-		filteredImageDigest, err := os.ReadFile(filterPath)
-		if err == nil && len(filterDigest) > 0 {
-			srcFilteredCatalog, err = o.cachedCatalog(op, filterDigest)
+		if len(op.TargetCatalog) > 0 {
+			catalogName = op.TargetCatalog
+		} else {
+			catalogName = path.Base(imgSpec.Reference)
+		}
+		if imgSpec.Transport == ociProtocol {
+			// ensure correct oci format and directory lookup
+			sourceOCIDir, err := filepath.Abs(imgSpec.Reference)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, err
 			}
-			isAlreadyFiltered = o.isAlreadyFiltered(ctx, srcFilteredCatalog, string(filteredImageDigest))
+			catalogImage = ociProtocol + sourceOCIDir
+		} else {
+			catalogImage = op.Catalog
 		}
-
-		if isAlreadyFiltered {
-			filterConfigDir := filepath.Join(filteredCatalogsDir, filterDigest, operatorCatalogConfigDir)
-			filteredDC, err = o.ctlgHandler.getDeclarativeConfig(filterConfigDir)
-			if err != nil {
-				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+		catalogDigest = string(filteredImageDigest)
+		result := v2alpha1.CatalogFilterResult{
+			OperatorFilter:     op,
+			FilteredConfigPath: filterConfigDir,
+			ToRebuild:          false,
+		}
+		res.filterResult = &result
+
+	} else {
+		toRebuild := true
+		if imgSpec.Transport == ociProtocol {
+			if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); errors.Is(err, os.ErrNotExist) {
+				// delete the existing directory and untarred cache contents
+				os.RemoveAll(catalogImageDir)
+				os.RemoveAll(configsDir)
+				// copy all contents to the working dir
+				err := copy.Copy(imgSpec.PathComponent, catalogImageDir)
+				if err != nil {
+					o.Log.Error(errMsg, err.Error())
+					spinner.Abort(true)
+					spinner.Wait()
+					return nil, err
+				}
 			}
+
 			if len(op.TargetCatalog) > 0 {
 				catalogName = op.TargetCatalog
 			} else {
 				catalogName = path.Base(imgSpec.Reference)
 			}
-			if imgSpec.Transport == ociProtocol {
-				// ensure correct oci format and directory lookup
-				sourceOCIDir, err := filepath.Abs(imgSpec.Reference)
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
-					return v2alpha1.CollectorSchema{}, err
-				}
-				catalogImage = ociProtocol + sourceOCIDir
-			} else {
-				catalogImage = op.Catalog
-			}
-			catalogDigest = string(filteredImageDigest)
-			if collectorSchema.CatalogToFBCMap == nil {
-				collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
-			}
-			result := v2alpha1.CatalogFilterResult{
-				OperatorFilter:     op,
-				FilteredConfigPath: filterConfigDir,
-				ToRebuild:          false,
-			}
-			collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
-
 		} else {
-			toRebuild := true
-			if imgSpec.Transport == ociProtocol {
-				if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); errors.Is(err, os.ErrNotExist) {
-					// delete the existing directory and untarred cache contents
-					os.RemoveAll(catalogImageDir)
-					os.RemoveAll(configsDir)
-					// copy all contents to the working dir
-					err := copy.Copy(imgSpec.PathComponent, catalogImageDir)
-					if err != nil {
-						o.Log.Error(errMsg, err.Error())
-						spinner.Abort(true)
-						spinner.Wait()
-						return v2alpha1.CollectorSchema{}, err
-					}
-				}
-
-				if len(op.TargetCatalog) > 0 {
-					catalogName = op.TargetCatalog
-				} else {
-					catalogName = path.Base(imgSpec.Reference)
-				}
-			} else {
-				src := dockerProtocol + op.Catalog
-				dest := ociProtocolTrimmed + catalogImageDir
+			src := dockerProtocol + op.Catalog
+			dest := ociProtocolTrimmed + catalogImageDir
 
-				optsCopy := o.Opts
-				optsCopy.Stdout = io.Discard
+			optsCopy := o.Opts
+			optsCopy.Stdout = io.Discard
 
-				err = o.Mirror.Run(ctx, src, dest, "copy", &optsCopy)
+			err = o.Mirror.Run(ctx, src, dest, "copy", &optsCopy)
 
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
-				}
-			}
-
-			// it's in oci format so we can go directly to the index.json file
-			oci, err := o.Manifest.GetImageIndex(catalogImageDir)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
 			}
+		}
 
-			if isMultiManifestIndex(*oci) && imgSpec.Transport == ociProtocol {
-				err = o.Manifest.ConvertIndexToSingleManifest(catalogImageDir, oci)
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
-
-				oci, err = o.Manifest.GetImageIndex(catalogImageDir)
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
-
-				sourceOCIDir, err := filepath.Abs(imgSpec.Reference)
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
-					return v2alpha1.CollectorSchema{}, err
-				}
-				catalogImage = ociProtocol + sourceOCIDir
-			} else {
-				catalogImage = op.Catalog
-			}
+		// it's in oci format so we can go directly to the index.json file
+		oci, err := o.Manifest.GetImageIndex(catalogImageDir)
+		if err != nil {
+			o.Log.Error(errMsg, err.Error())
+			spinner.Abort(true)
+			spinner.Wait()
+			return nil, err
+		}
 
-			if len(oci.Manifests) == 0 {
-				o.Log.Error(collectorPrefix+"no manifests found for %s ", op.Catalog)
+		if isMultiManifestIndex(*oci) && imgSpec.Transport == ociProtocol {
+			err = o.Manifest.ConvertIndexToSingleManifest(catalogImageDir, oci)
+			if err != nil {
+				o.Log.Error(errMsg, err.Error())
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"no manifests found for %s ", op.Catalog)
+				return nil, err
 			}
 
-			validDigest, err := digest.Parse(oci.Manifests[0].Digest)
+			oci, err = o.Manifest.GetImageIndex(catalogImageDir)
 			if err != nil {
-				o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
+				o.Log.Error(errMsg, err.Error())
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
+				return nil, err
 			}
 
-			manifest := validDigest.Encoded()
-			o.Log.Debug(collectorPrefix+"manifest %s", manifest)
-			// read the operator image manifest
-			manifestDir := filepath.Join(catalogImageDir, blobsDir, manifest)
-			oci, err = o.Manifest.GetImageManifest(manifestDir)
+			sourceOCIDir, err := filepath.Abs(imgSpec.Reference)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, err
 			}
+			catalogImage = ociProtocol + sourceOCIDir
+		} else {
+			catalogImage = op.Catalog
+		}
 
-			// we need to check if oci returns multi manifests
-			// (from manifest list) also oci.Config will be nil
-			// we are only interested in the first manifest as all
-			// architecture "configs" will be exactly the same
-			if len(oci.Manifests) > 1 && oci.Config.Size == 0 {
-				subDigest, err := digest.Parse(oci.Manifests[0].Digest)
-				if err != nil {
-					o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
-				}
-				manifestDir := filepath.Join(catalogImageDir, blobsDir, subDigest.Encoded())
-				oci, err = o.Manifest.GetImageManifest(manifestDir)
-				if err != nil {
-					o.Log.Error(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
+		if len(oci.Manifests) == 0 {
+			o.Log.Error(collectorPrefix+"no manifests found for %s ", op.Catalog)
+			spinner.Abort(true)
+			spinner.Wait()
+			return nil, fmt.Errorf(collectorPrefix+"no manifests found for %s ", op.Catalog)
+		}
+
+		validDigest, err := digest.Parse(oci.Manifests[0].Digest)
+		if err != nil {
+			o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
+			spinner.Abort(true)
+			spinner.Wait()
+			return nil, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
+		}
+
+		manifest := validDigest.Encoded()
+		o.Log.Debug(collectorPrefix+"manifest %s", manifest)
+		// read the operator image manifest
+		manifestDir := filepath.Join(catalogImageDir, blobsDir, manifest)
+		oci, err = o.Manifest.GetImageManifest(manifestDir)
+		if err != nil {
+			o.Log.Error(errMsg, err.Error())
+			spinner.Abort(true)
+			spinner.Wait()
+			return nil, err
+		}
+
+		// we need to check if oci returns multi manifests
+		// (from manifest list) also oci.Config will be nil
+		// we are only interested in the first manifest as all
+		// architecture "configs" will be exactly the same
+		// (the first one mirrored, when mirror.architectures is set)
+		if len(oci.Manifests) > 1 && oci.Config.Size == 0 {
+			subManifest := oci.Manifests[0]
+			for _, m := range oci.Manifests {
+				if d, err := digest.Parse(m.Digest); err == nil {
+					if _, err := os.Stat(filepath.Join(catalogImageDir, blobsDir, d.Encoded())); err == nil {
+						subManifest = m
+						break
+					}
 				}
 			}
-
-			// read the config digest to get the detailed manifest
-			// looking for the lable to search for a specific folder
-			configDigest, err := digest.Parse(oci.Config.Digest)
+			subDigest, err := digest.Parse(subManifest.Digest)
 			if err != nil {
 				o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
+				return nil, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
 			}
-			catalogDir := filepath.Join(catalogImageDir, blobsDir, configDigest.Encoded())
-			ocs, err := o.Manifest.GetOperatorConfig(catalogDir)
+			manifestDir := filepath.Join(catalogImageDir, blobsDir, subDigest.Encoded())
+			oci, err = o.Manifest.GetImageManifest(manifestDir)
 			if err != nil {
-				o.Log.Error(errMsg, err.Error())
+				o.Log.Error(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, fmt.Errorf(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
 			}
+		}
 
-			label = ocs.Config.Labels.OperatorsOperatorframeworkIoIndexConfigsV1
-			o.Log.Debug(collectorPrefix+"label %s", label)
+		// read the config digest to get the detailed manifest
+		// looking for the lable to search for a specific folder
+		configDigest, err := digest.Parse(oci.Config.Digest)
+		if err != nil {
+			o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
+			spinner.Abort(true)
+			spinner.Wait()
+			return nil, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
+		}
+		catalogDir := filepath.Join(catalogImageDir, blobsDir, configDigest.Encoded())
+		ocs, err := o.Manifest.GetOperatorConfig(catalogDir)
+		if err != nil {
+			o.Log.Error(errMsg, err.Error())
+			spinner.Abort(true)
+			spinner.Wait()
+			return nil, err
+		}
 
-			// untar all the blobs for the operator
-			// if the layer with "label (from previous step) is found to a specific folder"
-			fromDir := strings.Join([]string{catalogImageDir, blobsDir}, "/")
-			err = o.Manifest.ExtractLayersOCI(fromDir, configsDir, label, oci)
+		label = ocs.Config.Labels.OperatorsOperatorframeworkIoIndexConfigsV1
+		o.Log.Debug(collectorPrefix+"label %s", label)
+
+		// untar all the blobs for the operator
+		// if the layer with "label (from previous step) is found to a specific folder"
+		fromDir := strings.Join([]string{catalogImageDir, blobsDir}, "/")
+		err = o.Manifest.ExtractLayersOCI(fromDir, configsDir, label, oci)
+		if err != nil {
+			spinner.Abort(true)
+			spinner.Wait()
+			return nil, err
+		}
+
+		originalDC, err := o.ctlgHandler.getDeclarativeConfig(filepath.Join(configsDir, label))
+		if err != nil {
+			spinner.Abort(true)
+			spinner.Wait()
+			return nil, err
+		}
+
+		if !isFullCatalog(op) {
+
+			var filteredDigestPath string
+			var filterDigest string
+
+			filteredDC, err = filterCatalog(ctx, *originalDC, op)
 			if err != nil {
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, err
 			}
 
-			originalDC, err := o.ctlgHandler.getDeclarativeConfig(filepath.Join(configsDir, label))
+			filterDigest, err = digestOfFilter(op)
 			if err != nil {
+				o.Log.Error(errMsg, err.Error())
 				spinner.Abort(true)
 				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				return nil, err
 			}
 
-			if !isFullCatalog(op) {
-
-				var filteredDigestPath string
-				var filterDigest string
+			if filterDigest != "" {
+				filteredDigestPath = filepath.Join(filteredCatalogsDir, filterDigest, operatorCatalogConfigDir)
 
-				filteredDC, err = filterCatalog(ctx, *originalDC, op)
-				if err != nil {
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
-
-				filterDigest, err = digestOfFilter(op)
+				err = createFolders([]string{filteredDigestPath})
 				if err != nil {
 					o.Log.Error(errMsg, err.Error())
 					spinner.Abort(true)
 					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
-
-				if filterDigest != "" {
-					filteredDigestPath = filepath.Join(filteredCatalogsDir, filterDigest, operatorCatalogConfigDir)
-
-					err = createFolders([]string{filteredDigestPath})
-					if err != nil {
-						o.Log.Error(errMsg, err.Error())
-						spinner.Abort(true)
-						spinner.Wait()
-						return v2alpha1.CollectorSchema{}, err
-					}
+					return nil, err
 				}
+			}
 
-				err = saveDeclarativeConfig(*filteredDC, filteredDigestPath)
-				if err != nil {
-					spinner.Abort(true)
-					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
-				}
+			err = saveDeclarativeConfig(*filteredDC, filteredDigestPath)
+			if err != nil {
+				spinner.Abort(true)
+				spinner.Wait()
+				return nil, err
+			}
 
-				if collectorSchema.CatalogToFBCMap == nil {
-					collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
-				}
-				result := v2alpha1.CatalogFilterResult{
-					OperatorFilter:     op,
-					FilteredConfigPath: filteredDigestPath,
-					ToRebuild:          toRebuild,
-				}
-				collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
+			result := v2alpha1.CatalogFilterResult{
+				OperatorFilter:     op,
+				FilteredConfigPath: filteredDigestPath,
+				ToRebuild:          toRebuild,
+			}
+			res.filterResult = &result
 
-			} else {
-				rebuiltTag = ""
-				toRebuild = false
-				filteredDC = originalDC
-				if collectorSchema.CatalogToFBCMap == nil {
-					collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
-				}
-				result := v2alpha1.CatalogFilterResult{
-					OperatorFilter:     op,
-					FilteredConfigPath: "", // this value is not relevant: no rebuilding required
-					ToRebuild:          toRebuild,
-				}
-				collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
+		} else {
+			rebuiltTag = ""
+			toRebuild = false
+			filteredDC = originalDC
+			result := v2alpha1.CatalogFilterResult{
+				OperatorFilter:     op,
+				FilteredConfigPath: "", // this value is not relevant: no rebuilding required
+				ToRebuild:          toRebuild,
 			}
+			res.filterResult = &result
 		}
+	}
 
-		ri, err := o.ctlgHandler.getRelatedImagesFromCatalog(filteredDC, copyImageSchemaMap)
-		if err != nil {
-			spinner.Abort(true)
-			spinner.Wait()
-			return v2alpha1.CollectorSchema{}, err
-		}
+	ri, err := o.ctlgHandler.getRelatedImagesFromCatalog(filteredDC, &res.copyImageSchemaMap)
+	if err != nil {
+		spinner.Abort(true)
+		spinner.Wait()
+		return nil, err
+	}
 
-		//OCPBUGS-45059
-		//TODO remove me when the migration from oc-mirror v1 to v2 ends
-		if imgSpec.Transport == ociProtocol && o.isDeleteOfV1CatalogFromDisk() {
-			addOriginFromOperatorCatalogOnDisk(&ri)
-		}
+	//OCPBUGS-45059
+	//TODO remove me when the migration from oc-mirror v1 to v2 ends
+	if imgSpec.Transport == ociProtocol && o.isDeleteOfV1CatalogFromDisk() {
+		addOriginFromOperatorCatalogOnDisk(&ri)
+	}
 
-		maps.Copy(relatedImages, ri)
-
-		var targetTag string
-		var targetCatalog string
-		if len(op.TargetTag) > 0 {
-			targetTag = op.TargetTag
-		} else if imgSpec.Transport == ociProtocol {
-			// for this case only, img.ParseRef(in its current state)
-			// will not be able to determine the digest.
-			// this leaves the oci imgSpec with no tag nor digest as it
-			// goes to prepareM2DCopyBatch/prepareD2MCopyBath. This is
-			// why we set the digest read from manifest in targetTag
-			targetTag = "latest"
-		}
+	res.relatedImages = ri
+
+	var targetTag string
+	var targetCatalog string
+	if len(op.TargetTag) > 0 {
+		targetTag = op.TargetTag
+	} else if imgSpec.Transport == ociProtocol {
+		// for this case only, img.ParseRef(in its current state)
+		// will not be able to determine the digest.
+		// this leaves the oci imgSpec with no tag nor digest as it
+		// goes to prepareM2DCopyBatch/prepareD2MCopyBath. This is
+		// why we set the digest read from manifest in targetTag
+		targetTag = "latest"
+	}
 
-		if len(op.TargetCatalog) > 0 {
-			targetCatalog = op.TargetCatalog
+	if len(op.TargetCatalog) > 0 {
+		targetCatalog = op.TargetCatalog
 
-		}
+	}
 
-		componentName := imgSpec.ComponentName() + "." + catalogDigest
-
-		relatedImages[componentName] = []v2alpha1.RelatedImage{
-			{
-				Name:          catalogName,
-				Image:         catalogImage,
-				Type:          v2alpha1.TypeOperatorCatalog,
-				TargetTag:     targetTag,
-				TargetCatalog: targetCatalog,
-				RebuiltTag:    rebuiltTag,
-			},
-		}
-		spinner.Increment()
-		p.Wait()
+	componentName := imgSpec.ComponentName() + "." + catalogDigest
+
+	res.relatedImages[componentName] = []v2alpha1.RelatedImage{
+		{
+			Name:          catalogName,
+			Image:         catalogImage,
+			Type:          v2alpha1.TypeOperatorCatalog,
+			TargetTag:     targetTag,
+			TargetCatalog: targetCatalog,
+			RebuiltTag:    rebuiltTag,
+		},
 	}
+	spinner.Increment()
+	return res, nil
+}
 
-	o.Log.Debug(collectorPrefix+"related images length %d ", len(relatedImages))
-	var count = 0
-	if o.Opts.Global.LogLevel == "debug" {
-		for _, v := range relatedImages {
-			count = count + len(v)
+// mergeCopyImageSchemaMap - adds the operators and bundles of src to dst
+func mergeCopyImageSchemaMap(dst *v2alpha1.CopyImageSchemaMap, src v2alpha1.CopyImageSchemaMap) {
+	for img, operators := range src.OperatorsByImage {
+		if dst.OperatorsByImage[img] == nil {
+			dst.OperatorsByImage[img] = make(map[string]struct{})
 		}
+		maps.Copy(dst.OperatorsByImage[img], operators)
 	}
-	o.Log.Debug(collectorPrefix+"images to copy (before duplicates) %d ", count)
-	var err error
-	// check the mode
-	switch {
-	case o.Opts.IsMirrorToDisk():
-		allImages, err = o.prepareM2DCopyBatch(relatedImages)
-		if err != nil {
-			o.Log.Error(errMsg, err.Error())
-			return v2alpha1.CollectorSchema{}, err
+	for img, bundles := range src.BundlesByImage {
+		if dst.BundlesByImage[img] == nil {
+			dst.BundlesByImage[img] = make(map[string]string)
 		}
-	case o.Opts.IsMirrorToMirror():
-		allImages, err = o.dispatchImagesForM2M(relatedImages)
-		if err != nil {
-			o.Log.Error(errMsg, err.Error())
-			return v2alpha1.CollectorSchema{}, err
-		}
-	case o.Opts.IsDiskToMirror() || o.Opts.Mode == string(mirror.DeleteMode):
-		allImages, err = o.prepareD2MCopyBatch(relatedImages)
-		if err != nil {
-			o.Log.Error(errMsg, err.Error())
-			return v2alpha1.CollectorSchema{}, err
-		}
-
+		maps.Copy(dst.BundlesByImage[img], bundles)
 	}
-
-	collectorSchema.AllImages = allImages
-	collectorSchema.CopyImageSchemaMap = *copyImageSchemaMap
-
-	return collectorSchema, nil
 }
 
 func isFullCatalog(catalog v2alpha1.Operator) bool {
@@ -522,6 +595,7 @@ func digestOfFilter(catalog v2alpha1.Operator) (string, error) {
 	c.TargetCatalog = ""
 	c.TargetTag = ""
 	c.TargetCatalogSourceTemplate = ""
+	c.TargetCatalogSourceNamespace = ""
 	pkgs, err := json.Marshal(c)
 	if err != nil {
 		return "", err
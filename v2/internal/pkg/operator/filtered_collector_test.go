@@ -646,3 +646,42 @@ func (ex *FilterCollector) withConfig(cfg v2alpha1.ImageSetConfiguration) *Filte
 	ex.Config = cfg
 	return ex
 }
+
+func TestFilterCollectorErrorsPerCatalog(t *testing.T) {
+	log := clog.New("trace")
+	tempDir := t.TempDir()
+	manifest := &MockManifest{Log: log}
+
+	t.Run("Testing OperatorImageCollector - invalid catalogs: should report every failing catalog", func(t *testing.T) {
+		cfg := v2alpha1.ImageSetConfiguration{
+			ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+				Mirror: v2alpha1.Mirror{
+					Operators: []v2alpha1.Operator{
+						{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.17", TargetCatalog: "invalid:catalog"},
+						{Catalog: "registry.redhat.io/redhat/certified-operators:v4.17", TargetCatalog: "invalid@catalog"},
+					},
+				},
+			},
+		}
+		ex := setupFilterCollector_MirrorToDisk(tempDir, log, manifest).withConfig(cfg)
+		_, err := ex.OperatorImageCollector(context.Background())
+		assert.ErrorContains(t, err, "image registry.redhat.io/redhat/redhat-operator-index:v4.17: "+collectorPrefix+"invalid targetCatalog invalid:catalog")
+		assert.ErrorContains(t, err, "image registry.redhat.io/redhat/certified-operators:v4.17: "+collectorPrefix+"invalid targetCatalog invalid@catalog")
+	})
+}
+
+func TestMergeCopyImageSchemaMap(t *testing.T) {
+	t.Run("Testing mergeCopyImageSchemaMap : should add the operators and bundles of each catalog", func(t *testing.T) {
+		dst := &v2alpha1.CopyImageSchemaMap{
+			OperatorsByImage: map[string]map[string]struct{}{"img1": {"op1": {}}},
+			BundlesByImage:   map[string]map[string]string{"img1": {"bundle1": "op1.v1"}},
+		}
+		src := v2alpha1.CopyImageSchemaMap{
+			OperatorsByImage: map[string]map[string]struct{}{"img1": {"op2": {}}, "img2": {"op2": {}}},
+			BundlesByImage:   map[string]map[string]string{"img2": {"bundle2": "op2.v1"}},
+		}
+		mergeCopyImageSchemaMap(dst, src)
+		assert.Equal(t, map[string]map[string]struct{}{"img1": {"op1": {}, "op2": {}}, "img2": {"op2": {}}}, dst.OperatorsByImage)
+		assert.Equal(t, map[string]map[string]string{"img1": {"bundle1": "op1.v1"}, "img2": {"bundle2": "op2.v1"}}, dst.BundlesByImage)
+	})
+}
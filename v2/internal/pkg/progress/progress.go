@@ -0,0 +1,162 @@
+package progress
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containers/image/v5/types"
+)
+
+const (
+	// UpdateInterval - how often the copy layer reports the bytes transferred
+	UpdateInterval = 500 * time.Millisecond
+	// SummaryInterval - how often a summary line is logged in non-interactive mode
+	SummaryInterval = 30 * time.Second
+)
+
+// Snapshot - a point in time view of the overall progress
+type Snapshot struct {
+	ImagesDone    int
+	ImagesTotal   int
+	BytesCopied   int64
+	BytesSkipped  int64
+	Elapsed       time.Duration
+	BytesPerSec   float64
+	ETA           time.Duration
+	ETAKnown      bool
+	ImagesRunning int
+}
+
+// Tracker - accumulates the bytes copied per image and in total
+// it is safe for concurrent use by the batch workers
+type Tracker struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	start   time.Time
+	total   int
+	done    int
+	copied  int64
+	skipped int64
+	images  map[string]int64
+}
+
+// New - returns a tracker for a batch of total images
+func New(total int) *Tracker {
+	return newTracker(total, time.Now)
+}
+
+func newTracker(total int, now func() time.Time) *Tracker {
+	return &Tracker{
+		now:    now,
+		start:  now(),
+		total:  total,
+		images: map[string]int64{},
+	}
+}
+
+// Channel - returns a channel suitable for copy.Options.Progress, the events
+// received are accounted against image. The returned function must be called
+// once the copy has returned, it closes the channel and waits for all
+// pending events to be processed
+func (t *Tracker) Channel(image string) (chan types.ProgressProperties, func()) {
+	ch := make(chan types.ProgressProperties)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for p := range ch {
+			t.Observe(image, p)
+		}
+	}()
+	return ch, func() {
+		close(ch)
+		wg.Wait()
+	}
+}
+
+// Observe - accounts a single progress event from the copy layer
+func (t *Tracker) Observe(image string, p types.ProgressProperties) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch p.Event {
+	case types.ProgressEventRead, types.ProgressEventDone:
+		t.copied += int64(p.OffsetUpdate)
+		t.images[image] += int64(p.OffsetUpdate)
+	case types.ProgressEventSkipped:
+		if p.Artifact.Size > 0 {
+			t.skipped += p.Artifact.Size
+		}
+	case types.ProgressEventNewArtifact:
+		if _, ok := t.images[image]; !ok {
+			t.images[image] = 0
+		}
+	}
+}
+
+// ImageDone - marks image as processed (successfully or not)
+func (t *Tracker) ImageDone(image string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.done++
+	delete(t.images, image)
+}
+
+// ImageBytes - returns the bytes copied so far for an image in progress
+func (t *Tracker) ImageBytes(image string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.images[image]
+}
+
+// Snapshot - returns the current progress, the ETA is estimated from
+// the average time spent per image as the total size of a batch is
+// not known before the manifests are fetched
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := Snapshot{
+		ImagesDone:    t.done,
+		ImagesTotal:   t.total,
+		BytesCopied:   t.copied,
+		BytesSkipped:  t.skipped,
+		Elapsed:       t.now().Sub(t.start),
+		ImagesRunning: len(t.images),
+	}
+	if secs := s.Elapsed.Seconds(); secs > 0 {
+		s.BytesPerSec = float64(s.BytesCopied) / secs
+	}
+	if s.ImagesDone > 0 && s.ImagesDone <= s.ImagesTotal {
+		perImage := s.Elapsed / time.Duration(s.ImagesDone)
+		s.ETA = perImage * time.Duration(s.ImagesTotal-s.ImagesDone)
+		s.ETAKnown = true
+	}
+	return s
+}
+
+// String - formats the snapshot as used by the summary lines
+// e.g. "12 / 40 images, 1.2 GiB copied (35.0 MiB/s), 300.0 MiB already present, ETA 2m10s"
+func (s Snapshot) String() string {
+	msg := fmt.Sprintf("%d / %d images, %s copied (%s/s)", s.ImagesDone, s.ImagesTotal, FormatBytes(s.BytesCopied), FormatBytes(int64(s.BytesPerSec)))
+	if s.BytesSkipped > 0 {
+		msg += fmt.Sprintf(", %s already present", FormatBytes(s.BytesSkipped))
+	}
+	if s.ETAKnown {
+		msg += ", ETA " + s.ETA.Round(time.Second).String()
+	}
+	return msg
+}
+
+// FormatBytes - formats a size using binary units
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,52 @@
+package progress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	t.Run("Testing Tracker : should account bytes per image and in total", func(t *testing.T) {
+		now := time.Unix(0, 0)
+		tracker := newTracker(4, func() time.Time { return now })
+
+		ch, done := tracker.Channel("docker://quay.io/a:1")
+		ch <- types.ProgressProperties{Event: types.ProgressEventNewArtifact}
+		ch <- types.ProgressProperties{Event: types.ProgressEventRead, OffsetUpdate: 1024}
+		ch <- types.ProgressProperties{Event: types.ProgressEventDone, OffsetUpdate: 1024}
+		ch <- types.ProgressProperties{Event: types.ProgressEventSkipped, Artifact: types.BlobInfo{Size: 4096}}
+		done()
+
+		assert.Equal(t, int64(2048), tracker.ImageBytes("docker://quay.io/a:1"))
+
+		now = now.Add(10 * time.Second)
+		tracker.ImageDone("docker://quay.io/a:1")
+
+		s := tracker.Snapshot()
+		assert.Equal(t, 1, s.ImagesDone)
+		assert.Equal(t, 0, s.ImagesRunning)
+		assert.Equal(t, int64(2048), s.BytesCopied)
+		assert.Equal(t, int64(4096), s.BytesSkipped)
+		assert.InDelta(t, 204.8, s.BytesPerSec, 0.01)
+		assert.True(t, s.ETAKnown)
+		assert.Equal(t, 30*time.Second, s.ETA)
+		assert.Equal(t, "1 / 4 images, 2.0 KiB copied (204 B/s), 4.0 KiB already present, ETA 30s", s.String())
+	})
+
+	t.Run("Testing Tracker : should not estimate ETA before the first image completes", func(t *testing.T) {
+		tracker := New(2)
+		s := tracker.Snapshot()
+		assert.False(t, s.ETAKnown)
+		assert.Equal(t, "0 / 2 images, 0 B copied (0 B/s)", s.String())
+	})
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KiB", FormatBytes(1536))
+	assert.Equal(t, "3.0 MiB", FormatBytes(3*1024*1024))
+	assert.Equal(t, "2.0 GiB", FormatBytes(2*1024*1024*1024))
+}
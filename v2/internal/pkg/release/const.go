@@ -7,7 +7,6 @@ const (
 	graphPreparationDir            = "graph-preparation"
 	buildGraphDataDir              = "/var/lib/cincinnati-graph-data"
 	graphDataMountPath             = "/var/lib/cincinnati/graph-data"
-	graphImageName                 = "openshift/graph-image"
 	indexJson                      = "manifest.json"
 	operatorImageExtractDir        = "hold-operator"
 	workingDir                     = "working-dir"
@@ -32,4 +31,15 @@ const (
 	logFile                        = "release.log"
 	releaseImagePathComponents     = "openshift/release-images"
 	releaseComponentPathComponents = "openshift/release"
+	osImagePathComponents          = "openshift/os-images"
+	supportImagePathComponents     = "openshift/support-images"
 )
+
+// osImageNamePrefixes - prefixes of the names of the release payload components
+// holding the RHCOS image (rhel-coreos, machine-os-content) and its extensions
+// (rhel-coreos-extensions, machine-os-images)
+var osImageNamePrefixes = []string{"rhel-coreos", "machine-os-"}
+
+// supportImageNames - names of the release payload components
+// used to troubleshoot a cluster
+var supportImageNames = []string{"must-gather", "support-tools", "network-tools"}
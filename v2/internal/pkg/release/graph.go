@@ -28,6 +28,12 @@ func (o *LocalStorageCollector) CreateGraphImage(ctx context.Context, url string
 		return "", err
 	}
 
+	// keep only the channels (and versions) of the ImageSetConfiguration
+	body, err = filterGraphData(body, o.Config.Mirror.Platform.Channels)
+	if err != nil {
+		return "", err
+	}
+
 	// save graph data in a container layer modifying UID and GID to root.
 	archiveDestination := filepath.Join(o.Opts.Global.WorkingDir, graphArchive)
 	graphLayer, err := imagebuilder.LayerFromGzipByteArray(body, archiveDestination, buildGraphDataDir, 0644, 0, 0)
@@ -52,7 +58,7 @@ func (o *LocalStorageCollector) CreateGraphImage(ctx context.Context, url string
 	cmd := []string{"/bin/bash", "-c", fmt.Sprintf("exec cp -rp %s/* %s", buildGraphDataDir, graphDataMountPath)}
 
 	// update a ubi9 image with this new graphLayer and new cmd
-	graphImageRef := filepath.Join(o.destinationRegistry(), graphImageName) + ":latest"
+	graphImageRef := filepath.Join(o.destinationRegistry(), o.graphImageRef())
 	_, err = o.ImageBuilder.BuildAndPush(ctx, graphImageRef, layoutPath, cmd, graphLayer)
 	if err != nil {
 		return "", err
@@ -60,6 +66,13 @@ func (o *LocalStorageCollector) CreateGraphImage(ctx context.Context, url string
 	return dockerProtocol + graphImageRef, nil
 }
 
+// graphImageRef returns the repository and tag of the graph image,
+// relative to the registry it is pushed to, as completed by the
+// configuration defaults
+func (o LocalStorageCollector) graphImageRef() string {
+	return o.Config.Mirror.Platform.GraphImage.Repository + ":" + o.Config.Mirror.Platform.GraphImage.Tag
+}
+
 func (o *LocalStorageCollector) graphImageInWorkingDir(ctx context.Context) (string, error) {
 	layoutDir := filepath.Join(o.Opts.Global.WorkingDir, graphPreparationDir)
 	graphImageRef := ociProtocol + layoutDir
@@ -0,0 +1,144 @@
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// graphChannelsDir is the directory of the graph data holding one file per channel
+const graphChannelsDir = "channels"
+
+// graphChannel is a channel file of the graph data
+type graphChannel struct {
+	Name     string   `json:"name"`
+	Versions []string `json:"versions"`
+}
+
+// filterGraphData returns the graph data tarball (gzipped) content, keeping only
+// the channels of the ImageSetConfiguration, and in each channel only the versions
+// between its minVersion and maxVersion.
+// The other files of the graph data (blocked edges, signatures...) are kept.
+func filterGraphData(content []byte, channels []v2alpha1.ReleaseChannel) ([]byte, error) {
+	if len(channels) == 0 {
+		return content, nil
+	}
+	selected := make(map[string]v2alpha1.ReleaseChannel, len(channels))
+	for _, ch := range channels {
+		selected[ch.Name] = ch
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+
+	var filtered bytes.Buffer
+	gzipWriter := gzip.NewWriter(&filtered)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		if name, isChannel := graphChannelName(header); isChannel {
+			ch, found := selected[name]
+			if !found {
+				continue
+			}
+			data, err = filterGraphChannel(data, ch)
+			if err != nil {
+				return nil, fmt.Errorf("filtering graph data channel %s: %w", name, err)
+			}
+			header.Size = int64(len(data))
+		}
+
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return filtered.Bytes(), nil
+}
+
+// graphChannelName returns the name of the channel described by the tar entry,
+// if it is a channel file of the graph data
+func graphChannelName(header *tar.Header) (string, bool) {
+	if header.Typeflag != tar.TypeReg {
+		return "", false
+	}
+	name := strings.TrimPrefix(path.Clean(header.Name), "./")
+	dir, file := path.Split(name)
+	if path.Clean(dir) != graphChannelsDir || path.Ext(file) != ".yaml" {
+		return "", false
+	}
+	return strings.TrimSuffix(file, ".yaml"), true
+}
+
+// filterGraphChannel keeps the versions of the channel file data that are
+// between the minVersion and maxVersion of ch
+func filterGraphChannel(data []byte, ch v2alpha1.ReleaseChannel) ([]byte, error) {
+	if ch.MinVersion == "" && ch.MaxVersion == "" {
+		return data, nil
+	}
+	var minVersion, maxVersion *semver.Version
+	if ch.MinVersion != "" {
+		v, err := semver.Parse(ch.MinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minVersion %q: %w", ch.MinVersion, err)
+		}
+		minVersion = &v
+	}
+	if ch.MaxVersion != "" {
+		v, err := semver.Parse(ch.MaxVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxVersion %q: %w", ch.MaxVersion, err)
+		}
+		maxVersion = &v
+	}
+
+	var channel graphChannel
+	if err := yaml.Unmarshal(data, &channel); err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for _, version := range channel.Versions {
+		v, err := semver.Parse(version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %w", version, err)
+		}
+		if (minVersion != nil && v.LT(*minVersion)) || (maxVersion != nil && v.GT(*maxVersion)) {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	channel.Versions = versions
+	return yaml.Marshal(channel)
+}
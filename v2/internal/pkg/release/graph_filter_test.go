@@ -0,0 +1,113 @@
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+func TestFilterGraphData(t *testing.T) {
+	graphData := map[string]string{
+		"channels/stable-4.14.yaml":      "name: stable-4.14\nversions:\n- 4.13.19\n- 4.14.1\n- 4.14.2\n- 4.14.3\n",
+		"channels/fast-4.14.yaml":        "name: fast-4.14\nversions:\n- 4.14.1\n",
+		"./channels/candidate-4.14.yaml": "name: candidate-4.14\nversions:\n- 4.14.1\n",
+		"blocked-edges/4.14.2-bug.yaml":  "to: 4.14.2\nfrom: .*\n",
+		"version":                        "1.0.0\n",
+	}
+
+	t.Run("Testing filterGraphData : should keep the selected channels and versions only", func(t *testing.T) {
+		channels := []v2alpha1.ReleaseChannel{
+			{Name: "stable-4.14", MinVersion: "4.14.1", MaxVersion: "4.14.2"},
+			{Name: "candidate-4.14"},
+		}
+		filtered, err := filterGraphData(graphTarball(t, graphData), channels)
+		assert.NoError(t, err)
+
+		files := readGraphTarball(t, filtered)
+		assert.ElementsMatch(t, []string{
+			"channels/stable-4.14.yaml",
+			"./channels/candidate-4.14.yaml",
+			"blocked-edges/4.14.2-bug.yaml",
+			"version",
+		}, keys(files))
+		assert.Equal(t, graphData["./channels/candidate-4.14.yaml"], files["./channels/candidate-4.14.yaml"])
+		assert.Equal(t, graphData["version"], files["version"])
+
+		var stable graphChannel
+		assert.NoError(t, yaml.Unmarshal([]byte(files["channels/stable-4.14.yaml"]), &stable))
+		assert.Equal(t, graphChannel{Name: "stable-4.14", Versions: []string{"4.14.1", "4.14.2"}}, stable)
+	})
+
+	t.Run("Testing filterGraphData : no channel should keep the whole graph data", func(t *testing.T) {
+		content := graphTarball(t, graphData)
+		filtered, err := filterGraphData(content, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, content, filtered)
+	})
+
+	t.Run("Testing filterGraphData : invalid minVersion should fail", func(t *testing.T) {
+		channels := []v2alpha1.ReleaseChannel{{Name: "stable-4.14", MinVersion: "4.14"}}
+		_, err := filterGraphData(graphTarball(t, graphData), channels)
+		assert.ErrorContains(t, err, "filtering graph data channel stable-4.14: invalid minVersion")
+	})
+}
+
+func graphTarball(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readGraphTarball(t *testing.T, content []byte) map[string]string {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	files := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(data)
+	}
+	return files
+}
+
+func keys(files map[string]string) []string {
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
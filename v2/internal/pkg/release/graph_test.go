@@ -47,7 +47,8 @@ func TestCreateGraphImage(t *testing.T) {
 		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
 			Mirror: v2alpha1.Mirror{
 				Platform: v2alpha1.Platform{
-					Graph: true,
+					Graph:      true,
+					GraphImage: v2alpha1.GraphImage{Repository: v2alpha1.DefaultGraphImageRepository, Tag: v2alpha1.DefaultGraphImageTag},
 					Channels: []v2alpha1.ReleaseChannel{
 						{
 							Name: "stable-4.7",
@@ -54,108 +54,22 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 	o.Opts.MultiArch = "system"
 	o.Log.Debug(collectorPrefix+"setting copy option o.Opts.MultiArch=%s when collecting releases image", o.Opts.MultiArch)
 	var allImages []v2alpha1.CopyImageSchema
-	var imageIndexDir string
+	// the release images which could not be collected are left out,
+	// their errors are returned along with the collected images
+	var errs []error
 	if o.Opts.IsMirrorToDisk() || o.Opts.IsMirrorToMirror() {
 		releases, err := o.Cincinnati.GetReleaseReferenceImages(ctx)
 		if err != nil {
 			return allImages, err
 		}
 
-		// all errors will be probogated to the caller
-		// no redundant logging to console
 		for _, value := range releases {
-			hld := strings.Split(value.Source, "/")
-			releaseRepoAndTag := hld[len(hld)-1]
-			imageIndexDir = strings.Replace(releaseRepoAndTag, ":", "/", -1)
-			releaseTag := releaseRepoAndTag[strings.Index(releaseRepoAndTag, ":")+1:]
-			cacheDir := filepath.Join(o.Opts.Global.WorkingDir, releaseImageExtractDir, imageIndexDir)
-			dir := filepath.Join(o.Opts.Global.WorkingDir, releaseImageDir, imageIndexDir)
-
-			src := dockerProtocol + value.Source
-			dest := ociProtocolTrimmed + dir
-
-			if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
-				o.Log.Debug(collectorPrefix+"copying  release image %s ", value.Source)
-				err := os.MkdirAll(dir, 0755)
-				if err != nil {
-					//o.Log.Error(errMsg, err.Error())
-					return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
-				}
-
-				optsCopy := o.Opts
-				optsCopy.Stdout = io.Discard
-
-				err = o.Mirror.Run(ctx, src, dest, "copy", &optsCopy)
-				if err != nil {
-					//o.Log.Error(errMsg, err.Error())
-					return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
-				}
-				o.Log.Debug(collectorPrefix+"copied release index image %s ", value.Source)
-			} else {
-				o.Log.Debug(collectorPrefix+"release-images index directory alredy exists %s", dir)
-			}
-
-			oci, err := o.Manifest.GetImageIndex(dir)
-			if err != nil {
-				//o.Log.Error(errMsg, err.Error())
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
-			}
-
-			//read the link to the manifest
-			if len(oci.Manifests) == 0 {
-				//o.Log.Error(errMsg, "image index not found ")
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, "image index not found ")
-			}
-			validDigest, err := digest.Parse(oci.Manifests[0].Digest)
-			if err != nil {
-				//o.Log.Error(errMsg, err.Error())
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(collectorPrefix+"invalid digest for image index %s: %s", oci.Manifests[0].Digest, err.Error())
-			}
-
-			manifest := validDigest.Encoded()
-			o.Log.Debug(collectorPrefix+"image manifest digest %s", manifest)
-
-			manifestDir := filepath.Join(dir, blobsDir, manifest)
-			mfst, err := o.Manifest.GetImageManifest(manifestDir)
-			if err != nil {
-				//o.Log.Error(errMsg, err.Error())
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
-			}
-			o.Log.Debug(collectorPrefix+"config digest %s ", oci.Config.Digest)
-
-			fromDir := strings.Join([]string{dir, blobsDir}, "/")
-			err = o.Manifest.ExtractLayersOCI(fromDir, cacheDir, releaseManifests, mfst)
-			if err != nil {
-				//o.Log.Error(errMsg, err.Error())
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
-			}
-			o.Log.Debug("extracted layer %s ", cacheDir)
-
-			// overkill but its used for consistency
-			releaseDir := strings.Join([]string{cacheDir, releaseImageExtractFullPath}, "/")
-			allRelatedImages, err := o.Manifest.GetReleaseSchema(releaseDir)
-			if err != nil {
-				//o.Log.Error(errMsg, err.Error())
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
-			}
-
-			if o.Config.Mirror.Platform.KubeVirtContainer {
-				ki, err := o.getKubeVirtImage(cacheDir)
-				if err != nil {
-					// log to console as warning
-					o.Log.Warn("%v", err)
-				} else {
-					allRelatedImages = append(allRelatedImages, ki)
-				}
-			}
-
-			//add the release image itself
-			allRelatedImages = append(allRelatedImages, v2alpha1.RelatedImage{Image: value.Source, Name: value.Source, Type: v2alpha1.TypeOCPRelease})
-			tmpAllImages, err := o.prepareM2DCopyBatch(allRelatedImages, releaseTag)
+			releaseImgs, err := o.collectRelease(ctx, value)
 			if err != nil {
-				return []v2alpha1.CopyImageSchema{}, err
+				errs = append(errs, image.NewCollectionError(value.Source, err))
+				continue
 			}
-			allImages = append(allImages, tmpAllImages...)
+			allImages = append(allImages, releaseImgs...)
 		}
 
 		if o.Config.Mirror.Platform.Graph {
@@ -183,10 +97,12 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 		for _, releaseImg := range releaseImages {
 			releaseRef, err := image.ParseRef(releaseImg.Image)
 			if err != nil {
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
+				errs = append(errs, image.NewCollectionError(releaseImg.Image, fmt.Errorf(errMsg, err.Error())))
+				continue
 			}
 			if releaseRef.Tag == "" && len(releaseRef.Digest) == 0 {
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, "release image "+releaseImg.Image+" doesn't have a tag or digest")
+				errs = append(errs, image.NewCollectionError(releaseImg.Image, fmt.Errorf(errMsg, "release image "+releaseImg.Image+" doesn't have a tag or digest")))
+				continue
 			}
 			tag := releaseRef.Tag
 			if releaseRef.Tag == "" && len(releaseRef.Digest) > 0 {
@@ -194,7 +110,8 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 			}
 			monoReleaseSlice, err := o.prepareD2MCopyBatch([]v2alpha1.RelatedImage{releaseImg}, tag)
 			if err != nil {
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
+				errs = append(errs, image.NewCollectionError(releaseImg.Image, fmt.Errorf(errMsg, err.Error())))
+				continue
 			}
 			allImages = append(allImages, monoReleaseSlice...)
 		}
@@ -207,8 +124,9 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 			imageReferencesFile := filepath.Join(releaseDir, releaseManifests, imageReferences)
 			releaseRelatedImages, err := o.Manifest.GetReleaseSchema(imageReferencesFile)
 			if err != nil {
-				//o.Log.Error(errMsg, err.Error())
-				return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
+				// the release folders are named after the release tags only
+				errs = append(errs, fmt.Errorf(errMsg, err.Error()))
+				continue
 			}
 
 			if o.Config.Mirror.Platform.KubeVirtContainer {
@@ -222,10 +140,18 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 				}
 			}
 
+			if o.Config.Mirror.Platform.OSImages {
+				releaseRelatedImages = append(releaseRelatedImages, o.getOSImages(releaseRelatedImages)...)
+			}
+
+			if o.Config.Mirror.Platform.SupportImages {
+				releaseRelatedImages = append(releaseRelatedImages, o.getSupportImages(releaseRelatedImages)...)
+			}
+
 			releaseCopyImages, err := o.prepareD2MCopyBatch(releaseRelatedImages, releaseTag)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
-				return []v2alpha1.CopyImageSchema{}, err
+				errs = append(errs, err)
 			}
 			allImages = append(allImages, releaseCopyImages...)
 		}
@@ -233,11 +159,11 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 		if o.Config.Mirror.Platform.Graph {
 			o.Log.Debug("adding graph data image")
 			graphRelatedImage := v2alpha1.RelatedImage{
-				Name: graphImageName,
-				// Supposing that the mirror to disk saved the image with the latest tag
+				Name: o.Config.Mirror.Platform.GraphImage.Repository,
+				// Supposing that the mirror to disk saved the image with the configured tag
 				// If this supposition is false, then we need to implement a mechanism to save
 				// the digest of the graph image and use it here
-				Image: dockerProtocol + filepath.Join(o.LocalStorageFQDN, graphImageName) + ":latest",
+				Image: dockerProtocol + filepath.Join(o.LocalStorageFQDN, o.graphImageRef()),
 				Type:  v2alpha1.TypeCincinnatiGraph,
 			}
 			// OCPBUGS-38037: Check the graph image is in the cache before adding it
@@ -260,18 +186,18 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 				// of the graph image.
 				graphImageSlice := []v2alpha1.RelatedImage{graphRelatedImage}
 				graphCopySlice, err := o.prepareD2MCopyBatch(graphImageSlice, "")
-				if err != nil {
+				switch {
+				case err != nil:
 					o.Log.Error(errMsg, err.Error())
-					return []v2alpha1.CopyImageSchema{}, err
-				}
+					errs = append(errs, image.NewCollectionError(graphRelatedImage.Image, err))
 				// if there is no error, we are certain that the slice only contains 1 element
 				// but double checking...
-				if len(graphCopySlice) != 1 {
-					//o.Log.Error(errMsg, "error while calculating the destination reference for the graph image")
-					return []v2alpha1.CopyImageSchema{}, fmt.Errorf(collectorPrefix + "error while calculating the destination reference for the graph image")
+				case len(graphCopySlice) != 1:
+					errs = append(errs, image.NewCollectionError(graphRelatedImage.Image, fmt.Errorf(collectorPrefix+"error while calculating the destination reference for the graph image")))
+				default:
+					o.GraphDataImage = graphCopySlice[0].Destination
+					allImages = append(allImages, graphCopySlice...)
 				}
-				o.GraphDataImage = graphCopySlice[0].Destination
-				allImages = append(allImages, graphCopySlice...)
 			}
 		}
 	}
@@ -290,7 +216,112 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 	})
 	allImages = slices.Compact(allImages)
 
-	return allImages, nil
+	return allImages, errors.Join(errs...)
+}
+
+// collectRelease - copies the release image of value to the working directory
+// and returns the copies of the images of the release
+func (o *LocalStorageCollector) collectRelease(ctx context.Context, value v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, error) {
+	hld := strings.Split(value.Source, "/")
+	releaseRepoAndTag := hld[len(hld)-1]
+	imageIndexDir := strings.Replace(releaseRepoAndTag, ":", "/", -1)
+	releaseTag := releaseRepoAndTag[strings.Index(releaseRepoAndTag, ":")+1:]
+	cacheDir := filepath.Join(o.Opts.Global.WorkingDir, releaseImageExtractDir, imageIndexDir)
+	dir := filepath.Join(o.Opts.Global.WorkingDir, releaseImageDir, imageIndexDir)
+
+	src := dockerProtocol + value.Source
+	dest := ociProtocolTrimmed + dir
+
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		o.Log.Debug(collectorPrefix+"copying  release image %s ", value.Source)
+		err := os.MkdirAll(dir, 0755)
+		if err != nil {
+			//o.Log.Error(errMsg, err.Error())
+			return nil, fmt.Errorf(errMsg, err.Error())
+		}
+
+		optsCopy := o.Opts
+		optsCopy.Stdout = io.Discard
+
+		err = o.Mirror.Run(ctx, src, dest, "copy", &optsCopy)
+		if err != nil {
+			//o.Log.Error(errMsg, err.Error())
+			return nil, fmt.Errorf(errMsg, err.Error())
+		}
+		o.Log.Debug(collectorPrefix+"copied release index image %s ", value.Source)
+	} else {
+		o.Log.Debug(collectorPrefix+"release-images index directory alredy exists %s", dir)
+	}
+
+	oci, err := o.Manifest.GetImageIndex(dir)
+	if err != nil {
+		//o.Log.Error(errMsg, err.Error())
+		return nil, fmt.Errorf(errMsg, err.Error())
+	}
+
+	//read the link to the manifest
+	if len(oci.Manifests) == 0 {
+		//o.Log.Error(errMsg, "image index not found ")
+		return nil, fmt.Errorf(errMsg, "image index not found ")
+	}
+	validDigest, err := digest.Parse(oci.Manifests[0].Digest)
+	if err != nil {
+		//o.Log.Error(errMsg, err.Error())
+		return nil, fmt.Errorf(collectorPrefix+"invalid digest for image index %s: %s", oci.Manifests[0].Digest, err.Error())
+	}
+
+	manifest := validDigest.Encoded()
+	o.Log.Debug(collectorPrefix+"image manifest digest %s", manifest)
+
+	manifestDir := filepath.Join(dir, blobsDir, manifest)
+	mfst, err := o.Manifest.GetImageManifest(manifestDir)
+	if err != nil {
+		//o.Log.Error(errMsg, err.Error())
+		return nil, fmt.Errorf(errMsg, err.Error())
+	}
+	o.Log.Debug(collectorPrefix+"config digest %s ", oci.Config.Digest)
+
+	fromDir := strings.Join([]string{dir, blobsDir}, "/")
+	err = o.Manifest.ExtractLayersOCI(fromDir, cacheDir, releaseManifests, mfst)
+	if err != nil {
+		//o.Log.Error(errMsg, err.Error())
+		return nil, fmt.Errorf(errMsg, err.Error())
+	}
+	o.Log.Debug("extracted layer %s ", cacheDir)
+
+	// overkill but its used for consistency
+	releaseDir := strings.Join([]string{cacheDir, releaseImageExtractFullPath}, "/")
+	allRelatedImages, err := o.Manifest.GetReleaseSchema(releaseDir)
+	if err != nil {
+		//o.Log.Error(errMsg, err.Error())
+		return nil, fmt.Errorf(errMsg, err.Error())
+	}
+
+	if o.Config.Mirror.Platform.KubeVirtContainer {
+		ki, err := o.getKubeVirtImage(cacheDir)
+		if err != nil {
+			// log to console as warning
+			o.Log.Warn("%v", err)
+		} else {
+			allRelatedImages = append(allRelatedImages, ki)
+		}
+	}
+
+	if o.Config.Mirror.Platform.OSImages {
+		allRelatedImages = append(allRelatedImages, o.getOSImages(allRelatedImages)...)
+	}
+
+	if o.Config.Mirror.Platform.SupportImages {
+		allRelatedImages = append(allRelatedImages, o.getSupportImages(allRelatedImages)...)
+	}
+
+	//add the release image itself
+	allRelatedImages = append(allRelatedImages, v2alpha1.RelatedImage{Image: value.Source, Name: value.Source, Type: v2alpha1.TypeOCPRelease})
+	tmpAllImages, err := o.prepareM2DCopyBatch(allRelatedImages, releaseTag)
+	if err != nil {
+		return nil, err
+	}
+	return tmpAllImages, nil
 }
 
 func (o LocalStorageCollector) prepareM2DCopyBatch(images []v2alpha1.RelatedImage, releaseTag string) ([]v2alpha1.CopyImageSchema, error) {
@@ -385,7 +416,7 @@ func (o LocalStorageCollector) identifyReleases(ctx context.Context) ([]v2alpha1
 // by the collector.
 func (o *LocalStorageCollector) GraphImage() (string, error) {
 	if o.GraphDataImage == "" {
-		sourceGraphDataImage := filepath.Join(o.LocalStorageFQDN, graphImageName) + ":latest"
+		sourceGraphDataImage := filepath.Join(o.LocalStorageFQDN, o.graphImageRef())
 		graphRelatedImage := []v2alpha1.RelatedImage{
 			{
 				Name:  "release",
@@ -480,16 +511,72 @@ func (o LocalStorageCollector) getKubeVirtImage(releaseArtifactsDir string) (v2a
 	return kubeVirtImage, nil
 }
 
+// getOSImages - returns the RHCOS and OS extension images (rhel-coreos*, machine-os-*)
+// of the release payload, to be mirrored to their own repository in addition to the
+// release content, so that clusters layering RHCOS can pull them by release tag
+func (o LocalStorageCollector) getOSImages(releaseImages []v2alpha1.RelatedImage) []v2alpha1.RelatedImage {
+	var osImages []v2alpha1.RelatedImage
+	for _, img := range releaseImages {
+		if img.Type != v2alpha1.TypeOCPReleaseContent || !isOSImage(img.Name) {
+			continue
+		}
+		o.Log.Debug(collectorPrefix+"osImages set to true [ including : %s (%s) ]", img.Name, img.Image)
+		osImages = append(osImages, v2alpha1.RelatedImage{
+			Image: img.Image,
+			Name:  img.Name,
+			Type:  v2alpha1.TypeOCPOSImage,
+		})
+	}
+	if len(osImages) == 0 {
+		o.Log.Warn(collectorPrefix + "osImages set to true but no OS image found in the release payload")
+	}
+	return osImages
+}
+
+// isOSImage - returns true for the names of the release payload components
+// holding the RHCOS image and its extensions
+func isOSImage(name string) bool {
+	for _, prefix := range osImageNamePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getSupportImages - returns the troubleshooting images (must-gather, support-tools, network-tools)
+// of the release payload, to be mirrored to their own repository in addition to the release
+// content, so that they can be pulled by release tag in the disconnected network
+func (o LocalStorageCollector) getSupportImages(releaseImages []v2alpha1.RelatedImage) []v2alpha1.RelatedImage {
+	var supportImages []v2alpha1.RelatedImage
+	for _, name := range supportImageNames {
+		idx := slices.IndexFunc(releaseImages, func(img v2alpha1.RelatedImage) bool {
+			return img.Type == v2alpha1.TypeOCPReleaseContent && img.Name == name
+		})
+		if idx < 0 {
+			o.Log.Warn(collectorPrefix+"supportImages set to true but %s not found in the release payload", name)
+			continue
+		}
+		o.Log.Debug(collectorPrefix+"supportImages set to true [ including : %s (%s) ]", name, releaseImages[idx].Image)
+		supportImages = append(supportImages, v2alpha1.RelatedImage{
+			Image: releaseImages[idx].Image,
+			Name:  name,
+			Type:  v2alpha1.TypeOCPSupportImage,
+		})
+	}
+	return supportImages
+}
+
 func (o LocalStorageCollector) handleGraphImage(ctx context.Context) (v2alpha1.CopyImageSchema, error) {
 	o.Log.Debug(collectorPrefix + "processing graph data image")
 	if updateURLOverride := os.Getenv("UPDATE_URL_OVERRIDE"); len(updateURLOverride) != 0 {
 		// OCPBUGS-38037: this indicates that the official cincinnati API is not reacheable
 		// and that graph image cannot be rebuilt on top the complete graph in tar.gz format
 
-		graphImgRef := dockerProtocol + filepath.Join(o.destinationRegistry(), graphImageName) + ":latest"
+		graphImgRef := dockerProtocol + filepath.Join(o.destinationRegistry(), o.graphImageRef())
 
 		// 1. check if graph image is already in cache
-		cachedImageRef := dockerProtocol + filepath.Join(o.LocalStorageFQDN, graphImageName) + ":latest"
+		cachedImageRef := dockerProtocol + filepath.Join(o.LocalStorageFQDN, o.graphImageRef())
 		alreadyInCache, err := o.imageExists(ctx, cachedImageRef)
 		if err != nil {
 			o.Log.Warn("graph image not found in cache: %v", err)
@@ -545,6 +632,10 @@ func preparePathComponents(imgSpec image.ImageSpec, imgType v2alpha1.ImageType,
 		pathComponents = imgSpec.PathComponent
 	case imgType == v2alpha1.TypeOCPReleaseContent && imgName != "":
 		pathComponents = releaseComponentPathComponents
+	case imgType == v2alpha1.TypeOCPOSImage:
+		pathComponents = osImagePathComponents
+	case imgType == v2alpha1.TypeOCPSupportImage:
+		pathComponents = supportImagePathComponents
 	case imgSpec.IsImageByDigestOnly():
 		pathComponents = imgSpec.PathComponent
 	}
@@ -564,7 +655,7 @@ func prepareTag(imgSpec image.ImageSpec, imgType v2alpha1.ImageType, releaseTag,
 		} else {
 			tag = imgSpec.Tag
 		}
-	case imgType == v2alpha1.TypeOCPReleaseContent && imgName != "":
+	case (imgType == v2alpha1.TypeOCPReleaseContent || imgType == v2alpha1.TypeOCPOSImage || imgType == v2alpha1.TypeOCPSupportImage) && imgName != "":
 		tag = releaseTag + "-" + imgName
 	case imgSpec.IsImageByDigestOnly():
 		tag = fmt.Sprintf("%s-%s", imgSpec.Algorithm, imgSpec.Digest)
@@ -263,7 +263,18 @@ func TestGraphImage(t *testing.T) {
 		if err != nil {
 			t.Fatalf("should pass")
 		}
-		assert.Equal(t, ex.Opts.Destination+"/"+graphImageName+":latest", res)
+		assert.Equal(t, ex.Opts.Destination+"/openshift/graph-image:latest", res)
+	})
+
+	t.Run("Testing GraphImage : should use the configured repository and tag", func(t *testing.T) {
+		ex := setupCollector_DiskToMirror(tempDir, log)
+		ex.Config.Mirror.Platform.GraphImage = v2alpha1.GraphImage{Repository: "site/ocp/graph-data", Tag: "v1"}
+
+		res, err := ex.GraphImage()
+		if err != nil {
+			t.Fatalf("should pass")
+		}
+		assert.Equal(t, ex.Opts.Destination+"/site/ocp/graph-data:v1", res)
 	})
 
 }
@@ -464,6 +475,7 @@ func TestHandleGraphImage(t *testing.T) {
 				ImageBuilder:     &mockImageBuilder{},
 				LogsDir:          "/tmp/",
 			}
+			ex.Config.Mirror.Platform.GraphImage = v2alpha1.GraphImage{Repository: v2alpha1.DefaultGraphImageRepository, Tag: v2alpha1.DefaultGraphImageTag}
 			graphImage, err := ex.handleGraphImage(context.Background())
 			if testCase.expectedError && err == nil {
 				t.Error("expecting test to fail with error, but no error returned")
@@ -519,6 +531,7 @@ func setupCollector_DiskToMirror(tempDir string, log clog.PluggableLoggerInterfa
 						},
 					},
 					Graph:             true,
+					GraphImage:        v2alpha1.GraphImage{Repository: v2alpha1.DefaultGraphImageRepository, Tag: v2alpha1.DefaultGraphImageTag},
 					KubeVirtContainer: true,
 				},
 			},
@@ -587,6 +600,7 @@ func setupCollector_MirrorToDisk(tempDir string, log clog.PluggableLoggerInterfa
 						},
 					},
 					Graph:             true,
+					GraphImage:        v2alpha1.GraphImage{Repository: v2alpha1.DefaultGraphImageRepository, Tag: v2alpha1.DefaultGraphImageTag},
 					KubeVirtContainer: true,
 				},
 				Operators: []v2alpha1.Operator{
@@ -800,3 +814,82 @@ func (o *ManifestMock) GetDigest(ctx context.Context, sourceCtx *types.SystemCon
 	args := o.Called(ctx, sourceCtx, imgRef)
 	return args.String(0), args.Error(1)
 }
+
+func TestOSImages(t *testing.T) {
+	log := clog.New("trace")
+
+	tempDir := t.TempDir()
+	ex := setupCollector_DiskToMirror(tempDir, log)
+
+	releaseImages := []v2alpha1.RelatedImage{
+		{Name: "rhel-coreos", Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:1111111111111111111111111111111111111111111111111111111111111111", Type: v2alpha1.TypeOCPReleaseContent},
+		{Name: "rhel-coreos-extensions", Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:2222222222222222222222222222222222222222222222222222222222222222", Type: v2alpha1.TypeOCPReleaseContent},
+		{Name: "machine-os-images", Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:3333333333333333333333333333333333333333333333333333333333333333", Type: v2alpha1.TypeOCPReleaseContent},
+		{Name: "cli", Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:4444444444444444444444444444444444444444444444444444444444444444", Type: v2alpha1.TypeOCPReleaseContent},
+	}
+
+	t.Run("Testing getOSImages : should return the OS images only", func(t *testing.T) {
+		osImages := ex.getOSImages(releaseImages)
+		assert.Len(t, osImages, 3)
+		for i, img := range osImages {
+			assert.Equal(t, releaseImages[i].Name, img.Name)
+			assert.Equal(t, releaseImages[i].Image, img.Image)
+			assert.Equal(t, v2alpha1.TypeOCPOSImage, img.Type)
+		}
+	})
+
+	t.Run("Testing getOSImages : should return nothing when the payload has no OS image", func(t *testing.T) {
+		assert.Empty(t, ex.getOSImages(releaseImages[3:]))
+	})
+
+	t.Run("Testing prepareD2MCopyBatch : should tag OS images by release in their own repository", func(t *testing.T) {
+		res, err := ex.prepareD2MCopyBatch(ex.getOSImages(releaseImages[1:2]), "4.16.0-x86_64")
+		assert.NoError(t, err)
+		assert.Equal(t, []v2alpha1.CopyImageSchema{
+			{
+				Origin:      releaseImages[1].Image,
+				Source:      "docker://" + ex.LocalStorageFQDN + "/openshift/os-images:4.16.0-x86_64-rhel-coreos-extensions",
+				Destination: ex.Opts.Destination + "/openshift/os-images:4.16.0-x86_64-rhel-coreos-extensions",
+				Type:        v2alpha1.TypeOCPOSImage,
+			},
+		}, res)
+	})
+}
+
+func TestSupportImages(t *testing.T) {
+	log := clog.New("trace")
+
+	tempDir := t.TempDir()
+	ex := setupCollector_DiskToMirror(tempDir, log)
+
+	releaseImages := []v2alpha1.RelatedImage{
+		{Name: "cli", Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:1111111111111111111111111111111111111111111111111111111111111111", Type: v2alpha1.TypeOCPReleaseContent},
+		{Name: "network-tools", Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:2222222222222222222222222222222222222222222222222222222222222222", Type: v2alpha1.TypeOCPReleaseContent},
+		{Name: "must-gather", Image: "quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:3333333333333333333333333333333333333333333333333333333333333333", Type: v2alpha1.TypeOCPReleaseContent},
+	}
+
+	t.Run("Testing getSupportImages : should return the support images found in the payload", func(t *testing.T) {
+		supportImages := ex.getSupportImages(releaseImages)
+		assert.Equal(t, []v2alpha1.RelatedImage{
+			{Name: "must-gather", Image: releaseImages[2].Image, Type: v2alpha1.TypeOCPSupportImage},
+			{Name: "network-tools", Image: releaseImages[1].Image, Type: v2alpha1.TypeOCPSupportImage},
+		}, supportImages)
+	})
+
+	t.Run("Testing getSupportImages : should return nothing when the payload has no support image", func(t *testing.T) {
+		assert.Empty(t, ex.getSupportImages(releaseImages[:1]))
+	})
+
+	t.Run("Testing prepareD2MCopyBatch : should tag support images by release in their own repository", func(t *testing.T) {
+		res, err := ex.prepareD2MCopyBatch(ex.getSupportImages(releaseImages[2:]), "4.16.0-x86_64")
+		assert.NoError(t, err)
+		assert.Equal(t, []v2alpha1.CopyImageSchema{
+			{
+				Origin:      releaseImages[2].Image,
+				Source:      "docker://" + ex.LocalStorageFQDN + "/openshift/support-images:4.16.0-x86_64-must-gather",
+				Destination: ex.Opts.Destination + "/openshift/support-images:4.16.0-x86_64-must-gather",
+				Type:        v2alpha1.TypeOCPSupportImage,
+			},
+		}, res)
+	})
+}
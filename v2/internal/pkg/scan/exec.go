@@ -0,0 +1,51 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// vetoExitCode - exit code of an exec hook vetoing an image
+	vetoExitCode = 1
+
+	envImageOrigin = "OC_MIRROR_IMAGE_ORIGIN"
+	envImageType   = "OC_MIRROR_IMAGE_TYPE"
+	envImageLayout = "OC_MIRROR_IMAGE_LAYOUT"
+)
+
+// ExecHook - a hook running an executable on each image, with the OCI layout
+// of the image and its original reference as arguments. The executable exits
+// with 0 to keep the image and with 1 to veto it, its output being the reason
+// of the veto. Any other exit code fails the hook.
+type ExecHook struct {
+	Command string
+}
+
+// Scan - runs the executable of the hook on img
+func (h ExecHook) Scan(ctx context.Context, img Image) (Verdict, error) {
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.Command, img.LayoutPath, img.Origin)
+	cmd.Env = append(os.Environ(),
+		envImageOrigin+"="+img.Origin,
+		envImageType+"="+img.Type,
+		envImageLayout+"="+img.LayoutPath,
+	)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return Verdict{}, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == vetoExitCode:
+		return Verdict{Veto: true, Reason: strings.TrimSpace(output.String())}, nil
+	default:
+		return Verdict{}, fmt.Errorf("image scan hook %s failed on %s: %w: %s", h.Command, img.Origin, err, strings.TrimSpace(output.String()))
+	}
+}
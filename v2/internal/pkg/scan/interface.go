@@ -0,0 +1,22 @@
+package scan
+
+import "context"
+
+// Image - an image collected to disk, exported to an OCI layout for the hooks
+type Image struct {
+	Origin     string // original reference of the image
+	Type       string // content type of the image (ocpRelease, operatorBundle, generic...)
+	LayoutPath string // OCI layout holding the image
+}
+
+// Verdict - the result of a hook run on an image
+type Verdict struct {
+	Veto   bool   // if set, the image is left out of the archive
+	Reason string // why the image was vetoed, as reported by the hook
+}
+
+// HookInterface - a hook run on each image collected to disk before the archive
+// is built, such as a vulnerability scanner or a policy check
+type HookInterface interface {
+	Scan(ctx context.Context, img Image) (Verdict, error)
+}
@@ -0,0 +1,96 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+const (
+	ociProtocolTrimmed = "oci:"
+	vetoReportPrefix   = "image_scan_vetoes"
+)
+
+// Veto - an image left out of the archive by a hook, as recorded in the JSON veto report
+type Veto struct {
+	Image     string    `json:"image"`
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Scanner - runs a hook on each image copied to the local cache, exporting
+// the image from the cache to a temporary OCI layout first
+type Scanner struct {
+	Log     clog.PluggableLoggerInterface
+	Mirror  mirror.MirrorInterface
+	Opts    mirror.CopyOptions
+	Hook    HookInterface
+	WorkDir string // directory of the temporary OCI layouts
+}
+
+// Scan - runs the hook on images, and returns the images it did not veto
+// along with the vetoes. The images are read from their destination, the
+// local cache when mirroring to disk.
+func (s Scanner) Scan(ctx context.Context, images []v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, []Veto, error) {
+	if err := os.MkdirAll(s.WorkDir, 0755); err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(s.WorkDir)
+
+	opts := s.Opts
+	opts.Stdout = io.Discard
+	opts.Function = string(mirror.CopyMode)
+
+	var kept []v2alpha1.CopyImageSchema
+	var vetoes []Veto
+	for i, img := range images {
+		layout := filepath.Join(s.WorkDir, strconv.Itoa(i))
+		if err := s.Mirror.Run(ctx, img.Destination, ociProtocolTrimmed+layout, mirror.CopyMode, &opts); err != nil {
+			return nil, nil, fmt.Errorf("unable to export %s for the image scan hook: %w", img.Origin, err)
+		}
+		verdict, err := s.Hook.Scan(ctx, Image{
+			Origin:     img.Origin,
+			Type:       img.Type.String(),
+			LayoutPath: layout,
+		})
+		if rmErr := os.RemoveAll(layout); rmErr != nil {
+			s.Log.Warn("unable to remove the OCI layout %s: %v", layout, rmErr)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if verdict.Veto {
+			s.Log.Warn("image %s vetoed by the image scan hook: %s", img.Origin, verdict.Reason)
+			vetoes = append(vetoes, Veto{
+				Image:     img.Origin,
+				Type:      img.Type.String(),
+				Reason:    verdict.Reason,
+				Timestamp: time.Now().UTC(),
+			})
+			continue
+		}
+		kept = append(kept, img)
+	}
+	return kept, vetoes, nil
+}
+
+// SaveVetoReport - writes vetoes as a JSON array in a timestamped file
+// of logsDir, and returns the name of the file
+func SaveVetoReport(logsDir string, vetoes []Veto) (string, error) {
+	filename := fmt.Sprintf("%s_%s.json", vetoReportPrefix, time.Now().Format("20060102_150405"))
+	data, err := json.MarshalIndent(vetoes, "", "  ")
+	if err != nil {
+		return filename, err
+	}
+	return filename, os.WriteFile(filepath.Join(logsDir, filename), data, 0644)
+}
@@ -0,0 +1,135 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+// hookScript - vetoes the images whose reference contains "vulnerable",
+// fails on the ones containing "broken" and keeps the others
+const hookScript = `#!/bin/sh
+test -f "$1/index.json" || exit 2
+test "$2" = "$OC_MIRROR_IMAGE_ORIGIN" || exit 2
+case "$2" in
+*vulnerable*) echo "CVE-2024-0001 found"; exit 1;;
+*broken*) echo "scanner crashed"; exit 3;;
+esac
+`
+
+type mockMirror struct {
+	Fail bool
+}
+
+// Run - writes an empty OCI layout at dest
+func (o mockMirror) Run(ctx context.Context, src, dest string, mode mirror.Mode, opts *mirror.CopyOptions) error {
+	if o.Fail {
+		return fmt.Errorf("forced mirror run fail")
+	}
+	layout := strings.TrimPrefix(dest, ociProtocolTrimmed)
+	if err := os.MkdirAll(layout, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(layout, "index.json"), []byte(`{"schemaVersion":2,"manifests":[]}`), 0644)
+}
+
+func (o mockMirror) Check(ctx context.Context, image string, opts *mirror.CopyOptions, asCopySrc bool) (bool, error) {
+	return true, nil
+}
+
+func newTestHook(t *testing.T) ExecHook {
+	hook := filepath.Join(t.TempDir(), "hook.sh")
+	assert.NoError(t, os.WriteFile(hook, []byte(hookScript), 0755))
+	return ExecHook{Command: hook}
+}
+
+func testImage(origin string) v2alpha1.CopyImageSchema {
+	return v2alpha1.CopyImageSchema{
+		Source:      "docker://" + origin,
+		Destination: "docker://localhost:55000/" + origin,
+		Origin:      "docker://" + origin,
+		Type:        v2alpha1.TypeGeneric,
+	}
+}
+
+func TestExecHook(t *testing.T) {
+	ctx := context.Background()
+	hook := newTestHook(t)
+	layout := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(layout, "index.json"), []byte("{}"), 0644))
+
+	t.Run("Testing ExecHook : should keep the image when the hook exits with 0", func(t *testing.T) {
+		verdict, err := hook.Scan(ctx, Image{Origin: "quay.io/ns/safe:v1", Type: "generic", LayoutPath: layout})
+		assert.NoError(t, err)
+		assert.False(t, verdict.Veto)
+	})
+
+	t.Run("Testing ExecHook : should veto the image when the hook exits with 1", func(t *testing.T) {
+		verdict, err := hook.Scan(ctx, Image{Origin: "quay.io/ns/vulnerable:v1", Type: "generic", LayoutPath: layout})
+		assert.NoError(t, err)
+		assert.True(t, verdict.Veto)
+		assert.Equal(t, "CVE-2024-0001 found", verdict.Reason)
+	})
+
+	t.Run("Testing ExecHook : should fail when the hook exits with another code", func(t *testing.T) {
+		_, err := hook.Scan(ctx, Image{Origin: "quay.io/ns/broken:v1", Type: "generic", LayoutPath: layout})
+		assert.ErrorContains(t, err, "scanner crashed")
+	})
+}
+
+func TestScanner(t *testing.T) {
+	ctx := context.Background()
+	log := clog.New("debug")
+
+	t.Run("Testing Scan : should leave the vetoed images out", func(t *testing.T) {
+		workDir := filepath.Join(t.TempDir(), "image-scan")
+		scanner := Scanner{Log: log, Mirror: mockMirror{}, Hook: newTestHook(t), WorkDir: workDir}
+		images := []v2alpha1.CopyImageSchema{
+			testImage("quay.io/ns/safe:v1"),
+			testImage("quay.io/ns/vulnerable:v1"),
+			testImage("quay.io/ns/other:v1"),
+		}
+		kept, vetoes, err := scanner.Scan(ctx, images)
+		assert.NoError(t, err)
+		assert.Equal(t, []v2alpha1.CopyImageSchema{images[0], images[2]}, kept)
+		assert.Len(t, vetoes, 1)
+		assert.Equal(t, "docker://quay.io/ns/vulnerable:v1", vetoes[0].Image)
+		assert.Equal(t, "generic", vetoes[0].Type)
+		assert.Equal(t, "CVE-2024-0001 found", vetoes[0].Reason)
+		assert.NoDirExists(t, workDir)
+	})
+
+	t.Run("Testing Scan : should fail when the hook fails", func(t *testing.T) {
+		scanner := Scanner{Log: log, Mirror: mockMirror{}, Hook: newTestHook(t), WorkDir: t.TempDir()}
+		_, _, err := scanner.Scan(ctx, []v2alpha1.CopyImageSchema{testImage("quay.io/ns/broken:v1")})
+		assert.Error(t, err)
+	})
+
+	t.Run("Testing Scan : should fail when the image cannot be exported", func(t *testing.T) {
+		scanner := Scanner{Log: log, Mirror: mockMirror{Fail: true}, Hook: newTestHook(t), WorkDir: t.TempDir()}
+		_, _, err := scanner.Scan(ctx, []v2alpha1.CopyImageSchema{testImage("quay.io/ns/safe:v1")})
+		assert.ErrorContains(t, err, "unable to export docker://quay.io/ns/safe:v1")
+	})
+}
+
+func TestSaveVetoReport(t *testing.T) {
+	logsDir := t.TempDir()
+	filename, err := SaveVetoReport(logsDir, []Veto{{Image: "quay.io/ns/vulnerable:v1", Type: "generic", Reason: "CVE-2024-0001 found"}})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(filename, vetoReportPrefix))
+	data, err := os.ReadFile(filepath.Join(logsDir, filename))
+	assert.NoError(t, err)
+	var vetoes []Veto
+	assert.NoError(t, json.Unmarshal(data, &vetoes))
+	assert.Equal(t, "CVE-2024-0001 found", vetoes[0].Reason)
+}